@@ -14,23 +14,36 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/tdmdh/fit-up-server/internal/auth/handlers"
 	authMiddleware "github.com/tdmdh/fit-up-server/internal/auth/middleware"
+	authProviders "github.com/tdmdh/fit-up-server/internal/auth/providers"
 	authRepo "github.com/tdmdh/fit-up-server/internal/auth/repository"
 	authService "github.com/tdmdh/fit-up-server/internal/auth/services"
+	billingHandlers "github.com/tdmdh/fit-up-server/internal/billing/handlers"
+	billingProviders "github.com/tdmdh/fit-up-server/internal/billing/providers"
+	billingRepo "github.com/tdmdh/fit-up-server/internal/billing/repository"
+	billingService "github.com/tdmdh/fit-up-server/internal/billing/services"
 	foodTrackerHandlers "github.com/tdmdh/fit-up-server/internal/food-tracker/handlers"
 	foodTrackerRepo "github.com/tdmdh/fit-up-server/internal/food-tracker/repository"
 	foodTrackerService "github.com/tdmdh/fit-up-server/internal/food-tracker/services"
+	jobsHandlers "github.com/tdmdh/fit-up-server/internal/jobs/handlers"
+	jobsRepo "github.com/tdmdh/fit-up-server/internal/jobs/repository"
+	jobsService "github.com/tdmdh/fit-up-server/internal/jobs/services"
 	messageHandlers "github.com/tdmdh/fit-up-server/internal/message/handlers"
 	"github.com/tdmdh/fit-up-server/internal/message/pool"
 	messageRepo "github.com/tdmdh/fit-up-server/internal/message/repository"
 	messageService "github.com/tdmdh/fit-up-server/internal/message/services"
 	mindfulnessHandlers "github.com/tdmdh/fit-up-server/internal/mindfulness/handlers"
 	mindfulnessRepo "github.com/tdmdh/fit-up-server/internal/mindfulness/repository"
+	privacyHandlers "github.com/tdmdh/fit-up-server/internal/privacy/handlers"
+	privacyRepo "github.com/tdmdh/fit-up-server/internal/privacy/repository"
+	privacyService "github.com/tdmdh/fit-up-server/internal/privacy/services"
 	schemaHandlers "github.com/tdmdh/fit-up-server/internal/schema/handlers"
 	schemaRepo "github.com/tdmdh/fit-up-server/internal/schema/repository"
 	schemaService "github.com/tdmdh/fit-up-server/internal/schema/services"
 	"github.com/tdmdh/fit-up-server/shared/config"
 	"github.com/tdmdh/fit-up-server/shared/database"
+	"github.com/tdmdh/fit-up-server/shared/geoip"
 	sharedMiddleware "github.com/tdmdh/fit-up-server/shared/middleware"
+	"github.com/tdmdh/fit-up-server/shared/ratelimit"
 )
 
 func main() {
@@ -52,11 +65,44 @@ func main() {
 	}
 	defer database.Close(db)
 
+	log.Println("🚦 Initializing rate limiting...")
+	var rateLimitStore ratelimit.Store
+	switch cfg.RateLimit.Store {
+	case "postgres":
+		pgStore := ratelimit.NewPostgresStore(db)
+		go pgStore.RunCleanup(ctx, 10*time.Minute)
+		rateLimitStore = pgStore
+	case "memory":
+		rateLimitStore = ratelimit.NewMemoryStore()
+	default:
+		log.Fatalf("❌ Unknown RATE_LIMIT_STORE %q (expected postgres or memory)", cfg.RateLimit.Store)
+	}
+	if err := authMiddleware.ConfigureRateLimiting(cfg.RateLimit, rateLimitStore); err != nil {
+		log.Fatalf("❌ Invalid rate limit configuration: %v", err)
+	}
+
 	log.Println("🔐 Initializing authentication module...")
 	userStore := authRepo.NewStore(db)
 	authSvc := authService.NewAuthService(userStore)
-	oauthService := authService.NewOAuthService(userStore, &cfg)
-	authHandler := handlers.NewAuthHandler(userStore, authSvc, oauthService)
+	oauthProviders, err := authProviders.NewRegistry(cfg.OAuthConfig, nil)
+	if err != nil {
+		log.Fatalf("❌ Invalid OAuth provider configuration: %v", err)
+	}
+	oauthService := authService.NewOAuthService(userStore, oauthProviders)
+	twoFactorService := authService.NewTwoFactorService(userStore, &cfg)
+
+	var geoDB *geoip.DB
+	if cfg.GeoIPFile != "" {
+		geoDB, err = geoip.Open(cfg.GeoIPFile)
+		if err != nil {
+			log.Printf("⚠️  GeoIP file not loaded, new-country login alerts are disabled: %v", err)
+		} else {
+			log.Printf("🌍 Loaded %d GeoIP ranges", geoDB.Len())
+		}
+	}
+	loginSecurityService := authService.NewLoginSecurityService(userStore, geoDB, &cfg)
+
+	authHandler := handlers.NewAuthHandler(userStore, authSvc, oauthService, twoFactorService, loginSecurityService)
 
 	log.Println("💪 Initializing workout/fitness module...")
 	schemaStore := schemaRepo.NewStore(db)
@@ -69,6 +115,14 @@ func main() {
 	planGenerationService := schemaService.NewPlanGenerationService(schemaStore)
 	coachService := schemaService.NewCoachService(schemaStore)
 	invitationService := schemaService.NewInvitationService(schemaStore.CoachInvitations())
+	invitationService.SetCoachLookup(userStore)
+	adminService := schemaService.NewAdminService(userStore, schemaStore)
+	coachApplicationService := schemaService.NewCoachApplicationService(schemaStore, userStore)
+	coachAlertService := schemaService.NewCoachAlertService(schemaStore.CoachAlerts())
+	checkInService := schemaService.NewCheckInService(schemaStore)
+	progressPhotoService := schemaService.NewProgressPhotoService(schemaStore, cfg.ProgressPhotoDir)
+	templateLibraryService := schemaService.NewTemplateLibraryService(schemaStore)
+	coachTeamService := schemaService.NewCoachTeamService(schemaStore)
 
 	schemaRoutes := schemaHandlers.NewSchemaRoutes(
 		schemaStore,
@@ -78,8 +132,29 @@ func main() {
 		planGenerationService,
 		coachService,
 		invitationService,
+		adminService,
+		coachApplicationService,
+		coachAlertService,
+		checkInService,
+		progressPhotoService,
+		templateLibraryService,
+		coachTeamService,
 	)
 
+	log.Println("💳 Initializing coaching billing...")
+	paymentProvider, err := billingProviders.New(cfg.Billing)
+	if err != nil {
+		log.Fatalf("❌ Invalid billing configuration: %v", err)
+	}
+	if cfg.Billing.WebhookSecret == "" {
+		log.Println("⚠️  BILLING_WEBHOOK_SECRET is not set, payment webhooks will be rejected")
+	}
+	billingStore := billingRepo.NewStore(db)
+	packageService := billingService.NewPackageService(billingStore)
+	subscriptionService := billingService.NewSubscriptionService(billingStore, paymentProvider)
+	billingHandler := billingHandlers.NewBillingHandler(packageService, subscriptionService)
+	coachService.SetCoachingAccess(subscriptionService)
+
 	log.Println("💬 Initializing message service with WebSocket support...")
 	messageStore := messageRepo.NewStore(db)
 
@@ -90,20 +165,43 @@ func main() {
 	go hub.Run(hubCtx)
 
 	msgService := messageService.NewMessagesService(messageStore)
+	msgService.SetCoachingAccess(subscriptionService)
+	msgService.SetTeamAccess(coachTeamService)
 
 	realtimeService := messageService.NewRealtimeService(
 		hub,
 		msgService.Messages(),
 		msgService.Conversations(),
 		msgService.ReadStatus(),
+		msgService.Presence(),
 	)
 
 	msgService.SetRealtimeService(realtimeService)
+	msgService.SetWorkoutPlanSource(coachService)
+	coachApplicationService.SetNotifier(realtimeService)
+	coachAlertService.SetNotifier(realtimeService)
+	checkInService.SetNotifier(realtimeService)
+
+	scheduledDispatcher := messageService.NewScheduledMessageDispatcher(messageStore, msgService.Messages(), realtimeService)
+	go scheduledDispatcher.Run(hubCtx)
+
+	coachAlertWorker := schemaService.NewCoachAlertWorker(coachAlertService)
+	go coachAlertWorker.Run(hubCtx)
+
+	checkInWorker := schemaService.NewCheckInWorker(checkInService)
+	go checkInWorker.Run(hubCtx)
+
+	invitationEmailWorker := schemaService.NewInvitationEmailWorker(invitationService)
+	go invitationEmailWorker.Run(hubCtx)
+
+	lapseWorker := billingService.NewLapseWorker(subscriptionService)
+	go lapseWorker.Run(hubCtx)
 
 	msgAuthMiddleware := sharedMiddleware.NewAuthMiddleware(schemaStore, userStore)
 
 	messageHandler := messageHandlers.NewMessageHandler(msgService, msgAuthMiddleware)
 	conversationHandler := messageHandlers.NewConversationHandler(msgService, msgAuthMiddleware)
+	presenceHandler := messageHandlers.NewPresenceHandler(msgService, msgAuthMiddleware)
 	wsHandler := messageHandlers.NewWebSocketHandler(realtimeService, msgAuthMiddleware)
 
 	log.Println("🍽️  Initializing food tracker service...")
@@ -119,13 +217,35 @@ func main() {
 	mindfulnessStore := mindfulnessRepo.NewStore(db)
 	mindfulnessHandler := mindfulnessHandlers.NewMindfulnessHandler(mindfulnessStore)
 
+	log.Println("🔏 Initializing privacy/data export service...")
+	privacyStore := privacyRepo.NewStore(db)
+	exportService := privacyService.NewExportService(privacyStore, &cfg)
+	accountDeletionService := privacyService.NewAccountDeletionService(privacyStore, &cfg)
+	privacyHandler := privacyHandlers.NewPrivacyHandler(exportService, accountDeletionService)
+
+	exportWorker := privacyService.NewExportWorker(exportService)
+	go exportWorker.Run(hubCtx)
+	deletionWorker := privacyService.NewDeletionWorker(accountDeletionService)
+	go deletionWorker.Run(hubCtx)
+
+	log.Println("⏰ Initializing job scheduler...")
+	jobStore := jobsRepo.NewStore(db)
+	scheduler := jobsService.NewScheduler(jobStore, jobsRepo.NewLeaderLock(db, jobsRepo.SchedulerLockKey))
+	for _, job := range jobsService.MaintenanceJobs(userStore, schemaStore.CoachInvitations(), adminService, jobStore) {
+		if err := scheduler.Register(job); err != nil {
+			log.Fatalf("❌ Invalid job %s: %v", job.Name, err)
+		}
+	}
+	jobsHandler := jobsHandlers.NewJobsHandler(scheduler)
+	go scheduler.Run(hubCtx)
+
 	r := chi.NewRouter()
 
 	r.Use(authMiddleware.CORS())
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(authMiddleware.RealIP())
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -144,12 +264,18 @@ func main() {
 
 		schemaRoutes.RegisterRoutes(r)
 
-		messageHandlers.SetupMessageRoutes(r, messageHandler, conversationHandler, msgAuthMiddleware)
+		messageHandlers.SetupMessageRoutes(r, messageHandler, conversationHandler, presenceHandler, msgAuthMiddleware)
 
 		foodTrackerHandler.RegisterRoutes(r)
 
 		// Register mindfulness routes
 		mindfulnessHandler.RegisterRoutes(r, authMW)
+
+		privacyHandler.RegisterRoutes(r, authMW)
+
+		billingHandler.RegisterRoutes(r, authMW)
+
+		jobsHandler.RegisterRoutes(r, authMW)
 	})
 
 	messageHandlers.SetupWebSocketRoutes(r, wsHandler)
@@ -175,11 +301,21 @@ func main() {
 		log.Printf("📍 Fitness: http://localhost%s/api/v1/fitness-profile/*", addr)
 		log.Printf("📍 Plans: http://localhost%s/api/v1/plans/*", addr)
 		log.Printf("📍 Coach: http://localhost%s/api/v1/coach/*", addr)
+		log.Printf("📍 Coach Alerts: http://localhost%s/api/v1/coach/alerts/*", addr)
+		log.Printf("📍 Check-ins: http://localhost%s/api/v1/check-ins/*", addr)
+		log.Printf("📍 Progress Photos: http://localhost%s/api/v1/progress-photos/*", addr)
 		log.Printf("📍 Templates: http://localhost%s/api/v1/templates/*", addr)
+		log.Printf("📍 Template Library: http://localhost%s/api/v1/template-library/*", addr)
 		log.Printf("📍 Messages: http://localhost%s/api/v1/messages/*", addr)
 		log.Printf("📍 Conversations: http://localhost%s/api/v1/conversations/*", addr)
+		log.Printf("📍 Presence: http://localhost%s/api/v1/presence/*", addr)
+		log.Printf("📍 Workout Plan Cards: http://localhost%s/api/v1/workout-plans/*", addr)
+		log.Printf("📍 Scheduled Messages: http://localhost%s/api/v1/scheduled-messages/*", addr)
 		log.Printf("📍 Food Tracker: http://localhost%s/api/v1/food-tracker/*", addr)
 		log.Printf("📍 Mindfulness: http://localhost%s/api/v1/mindfulness/*", addr)
+		log.Printf("📍 Data Exports: http://localhost%s/api/v1/privacy/exports/*", addr)
+		log.Printf("📍 Billing: http://localhost%s/api/v1/billing/*", addr)
+		log.Printf("📍 Jobs: http://localhost%s/api/v1/admin/jobs/*", addr)
 		log.Printf("📍 WebSocket: ws://localhost%s/ws", addr)
 		log.Println("================================================================================")
 		log.Println("Press Ctrl+C to stop the server")
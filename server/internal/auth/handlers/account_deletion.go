@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/middleware"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/auth/utils"
+)
+
+// handleDeleteAccount schedules the account for deletion after the grace period.
+func (h *AuthHandler) handleDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return
+	}
+
+	var payload types.DeleteAccountRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &payload); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	scheduledFor, err := h.authService.RequestAccountDeletion(r.Context(), userID, payload.Password)
+	if err != nil {
+		switch err {
+		case types.ErrPasswordRequired:
+			utils.WriteError(w, http.StatusBadRequest, err)
+		case types.ErrInvalidCredentials:
+			utils.WriteError(w, http.StatusUnauthorized, err)
+		case types.ErrUserNotFound, types.ErrAccountDeleted:
+			utils.WriteError(w, http.StatusNotFound, types.ErrUserNotFound)
+		default:
+			log.Printf("Account deletion request for %s failed: %v", userID, err)
+			utils.WriteError(w, http.StatusInternalServerError, types.ErrInternalServerError)
+		}
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, types.AccountDeletionResponse{
+		Message:      "Your account is scheduled for deletion. Sign in again before the scheduled time to cancel.",
+		ScheduledFor: scheduledFor,
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/middleware"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/auth/utils"
+)
+
+// writeEmailLinkError maps magic-link and email-change errors to status codes.
+func writeEmailLinkError(w http.ResponseWriter, err error) {
+	switch err {
+	case types.ErrEmailLinkInvalid, types.ErrInvalidCodeVerifier, types.ErrInvalidCodeChallenge,
+		types.ErrEmailUnchanged, types.ErrPasswordRequired:
+		utils.WriteError(w, http.StatusBadRequest, err)
+	case types.ErrInvalidCredentials:
+		utils.WriteError(w, http.StatusUnauthorized, err)
+	case types.ErrUserNotFound, types.ErrAccountDeleted:
+		utils.WriteError(w, http.StatusNotFound, types.ErrUserNotFound)
+	case types.ErrUserAlreadyExists:
+		utils.WriteError(w, http.StatusConflict, err)
+	default:
+		log.Printf("Email link request failed: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, types.ErrInternalServerError)
+	}
+}
+
+func (h *AuthHandler) handleRequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var payload types.MagicLinkRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.authService.RequestMagicLink(r.Context(), payload.Email, payload.CodeChallenge, payload.CodeChallengeMethod); err != nil {
+		writeEmailLinkError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, "If an account with that email exists, we have sent a sign-in link")
+}
+
+// handleVerifyMagicLink exchanges a sign-in link for tokens, or for a 2FA
+// challenge when the account has two-factor authentication enabled.
+func (h *AuthHandler) handleVerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	var payload types.VerifyMagicLinkRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.authService.VerifyMagicLink(r.Context(), payload.Token, payload.CodeVerifier)
+	if err != nil {
+		writeEmailLinkError(w, err)
+		return
+	}
+
+	h.completeLogin(w, r, user, types.TwoFactorMethodMagicLink)
+}
+
+func (h *AuthHandler) handleRequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return
+	}
+
+	var payload types.EmailChangeRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.authService.RequestEmailChange(r.Context(), userID, payload.NewEmail, payload.Password); err != nil {
+		writeEmailLinkError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusAccepted, "Check your new email address for a confirmation link")
+}
+
+func (h *AuthHandler) handleConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var payload types.EmailTokenRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.authService.ConfirmEmailChange(r.Context(), payload.Token)
+	if err != nil {
+		writeEmailLinkError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Email address updated",
+		"user":    user,
+	})
+}
+
+// handleRevertEmailChange is called by the undo link sent to the previous address.
+func (h *AuthHandler) handleRevertEmailChange(w http.ResponseWriter, r *http.Request) {
+	var payload types.EmailTokenRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.authService.RevertEmailChange(r.Context(), payload.Token); err != nil {
+		writeEmailLinkError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "Your email address has been restored and all sessions signed out. Check your email to choose a new password.",
+	})
+}
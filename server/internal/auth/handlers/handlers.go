@@ -10,16 +10,20 @@ import (
 )
 
 type AuthHandler struct {
-	store        repository.UserStore
-	authService  repository.AuthService
-	oauthService repository.OAuthService
+	store            repository.UserStore
+	authService      repository.AuthService
+	oauthService     repository.OAuthService
+	twoFactorService repository.TwoFactorService
+	loginSecurity    repository.LoginSecurityService
 }
 
-func NewAuthHandler(store repository.UserStore, authService repository.AuthService, oauthService repository.OAuthService) *AuthHandler {
+func NewAuthHandler(store repository.UserStore, authService repository.AuthService, oauthService repository.OAuthService, twoFactorService repository.TwoFactorService, loginSecurity repository.LoginSecurityService) *AuthHandler {
 	return &AuthHandler{
-		store:        store,
-		authService:  authService,
-		oauthService: oauthService,
+		store:            store,
+		authService:      authService,
+		oauthService:     oauthService,
+		twoFactorService: twoFactorService,
+		loginSecurity:    loginSecurity,
 	}
 }
 
@@ -32,11 +36,14 @@ func (h *AuthHandler) RegisterRoutes(router chi.Router) {
 	})
 
 	router.With(middleware.LoginRateLimit()).Post("/login", h.handleLogin)
+	router.With(middleware.LoginRateLimit()).Post("/2fa/verify", h.handleVerifyTwoFactor)
 	router.With(middleware.RegisterRateLimit()).Post("/register", h.handleRegister)
 	router.Route("/oauth", func(r chi.Router) {
+		r.Get("/providers", h.handleListOAuthProviders)
 		r.Post("/mobile/{provider}/callback", h.handleOAuthMobileCallback)
 		r.Post("/{provider}", h.handleOAuthAuthorize)
 		r.Get("/callback/{provider}", h.handleOAuthCallback)
+		r.Post("/callback/{provider}", h.handleOAuthCallback)
 	})
 	router.With(middleware.PasswordResetRateLimit()).Post("/forgot-password", h.handleForgotPassword)
 	router.With(middleware.PasswordResetRateLimit()).Post("/reset-password", h.handleResetPassword)
@@ -47,12 +54,19 @@ func (h *AuthHandler) RegisterRoutes(router chi.Router) {
 	router.Post("/logout", h.handleLogout)
 	router.Post("/verify-email", h.handleVerifyEmail)
 	router.With(middleware.EmailVerificationRateLimit()).Post("/verify-email/resend", h.handleResendVerificationEmail)
+	router.Post("/security/not-me", h.handleReportUnrecognizedLogin)
+	router.With(middleware.MagicLinkRateLimit()).Post("/magic-link", h.handleRequestMagicLink)
+	router.With(middleware.EmailLinkRedeemRateLimit()).Post("/magic-link/verify", h.handleVerifyMagicLink)
+	router.With(middleware.EmailLinkRedeemRateLimit()).Post("/email-change/confirm", h.handleConfirmEmailChange)
+	router.With(middleware.EmailLinkRedeemRateLimit()).Post("/email-change/revert", h.handleRevertEmailChange)
 
 	router.Group(func(r chi.Router) {
 		r.Use(middleware.JWTAuthMiddleware(h.store))
 		r.Post("/change-password", h.handleChangePassword)
 		r.Put("/update-role", h.handleUpdateRole)
 		r.Put("/profile", h.handleUpdateProfile)
+		r.Delete("/account", h.handleDeleteAccount)
+		r.With(middleware.EmailChangeRateLimit()).Post("/email-change", h.handleRequestEmailChange)
 		r.Get("/stats", h.handleGetUserStats)
 		r.Get("/today-workout", h.handleGetTodayWorkout)
 		r.Post("/workout-complete", h.handleWorkoutCompletion)
@@ -74,5 +88,17 @@ func (h *AuthHandler) RegisterRoutes(router chi.Router) {
 		r.Post("/link/{provider}", h.handleLinkAccount)
 		r.Delete("/unlink/{provider}", h.handleUnlinkAccount)
 		r.Get("/linked-accounts", h.handleGetLinkedAccounts)
+
+		// Sessions (one per signed-in device)
+		r.Get("/sessions", h.handleListSessions)
+		r.Post("/sessions/revoke-others", h.handleRevokeOtherSessions)
+		r.Delete("/sessions/{sessionId}", h.handleRevokeSession)
+
+		// Two-factor authentication
+		r.Get("/2fa", h.handleGetTwoFactorStatus)
+		r.Post("/2fa/setup", h.handleSetupTwoFactor)
+		r.Post("/2fa/confirm", h.handleConfirmTwoFactor)
+		r.Post("/2fa/disable", h.handleDisableTwoFactor)
+		r.Post("/2fa/recovery-codes", h.handleRegenerateRecoveryCodes)
 	})
 }
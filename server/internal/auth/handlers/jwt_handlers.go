@@ -75,10 +75,12 @@ func (h *AuthHandler) handleRefreshToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tokenPair, err := h.authService.RotateTokens(r.Context(), payload.RefreshToken)
+	tokenPair, err := h.authService.RotateTokens(r.Context(), payload.RefreshToken, deviceInfoFromRequest(r))
 	if err != nil {
-		if err == types.ErrRefreshTokenNotFound || err == types.ErrRefreshTokenExpired {
+		if err == types.ErrRefreshTokenNotFound || err == types.ErrRefreshTokenExpired || err == types.ErrRefreshTokenReused {
 			utils.WriteError(w, http.StatusUnauthorized, err)
+		} else if err == types.ErrAccountDisabled || err == types.ErrPasswordResetRequired {
+			utils.WriteError(w, http.StatusForbidden, err)
 		} else {
 			utils.WriteError(w, http.StatusInternalServerError, err)
 		}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/tdmdh/fit-up-server/internal/auth/services"
 	"github.com/tdmdh/fit-up-server/internal/auth/types"
@@ -29,24 +32,37 @@ func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if lockedUntil, err := h.loginSecurity.CheckLockout(r.Context(), u.ID); err != nil {
+		if err == types.ErrAccountLocked {
+			writeAccountLocked(w, lockedUntil)
+			return
+		}
+		log.Printf("Failed to check lockout for %s: %v", u.ID, err)
+	}
+
 	if !service.ComparePasswords(u.PasswordHash, []byte(payload.Password)) {
+		lockedUntil, err := h.loginSecurity.RecordFailedLogin(r.Context(), u.ID)
+		if err != nil {
+			log.Printf("Failed to record failed login for %s: %v", u.ID, err)
+		} else if !lockedUntil.IsZero() {
+			writeAccountLocked(w, lockedUntil)
+			return
+		}
+
 		utils.WriteError(w, http.StatusUnauthorized, types.ErrInvalidCredentials)
 		return
 	}
 
-	tokenPair, err := h.authService.GenerateTokenPair(r.Context(), u)
-	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
-		return
-	}
+	h.completeLogin(w, r, u, types.TwoFactorMethodPassword)
+}
 
-	response := types.LoginResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		TokenType:    "Bearer",
-		ExpiresAt:    tokenPair.ExpiresIn,
-		User:         u,
+// writeAccountLocked responds 423 with a Retry-After for the remaining lockout.
+func writeAccountLocked(w http.ResponseWriter, lockedUntil time.Time) {
+	retryAfter := int(time.Until(lockedUntil).Round(time.Second) / time.Second)
+	if retryAfter < 1 {
+		retryAfter = 1
 	}
 
-	utils.WriteJSON(w, http.StatusOK, response)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	utils.WriteError(w, http.StatusLocked, types.ErrAccountLocked)
 }
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/auth/utils"
+)
+
+// handleReportUnrecognizedLogin is called by the "this wasn't me" page linked
+// from new-login alert emails.
+func (h *AuthHandler) handleReportUnrecognizedLogin(w http.ResponseWriter, r *http.Request) {
+	var payload types.ReportUnrecognizedLoginRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.loginSecurity.ReportUnrecognizedLogin(r.Context(), payload.Token); err != nil {
+		if err == types.ErrSecurityAlertTokenInvalid {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "All sessions have been signed out. Reset your password to sign in again.",
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/tdmdh/fit-up-server/internal/auth/middleware"
 	"github.com/tdmdh/fit-up-server/internal/auth/repository"
 	"github.com/tdmdh/fit-up-server/internal/auth/types"
@@ -33,6 +34,10 @@ func (h *AuthHandler) handleOAuthAuthorize(w http.ResponseWriter, r *http.Reques
 
 	authURL, err := h.oauthService.GetAuthorizationURL(r.Context(), provider, req.RedirectURI)
 	if err != nil {
+		if err == types.ErrProviderNotSupported {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -42,10 +47,20 @@ func (h *AuthHandler) handleOAuthAuthorize(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// handleListOAuthProviders lists the enabled providers so clients can show
+// buttons for partner identity providers without a release.
+func (h *AuthHandler) handleListOAuthProviders(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"providers": h.oauthService.ListProviders(),
+	})
+}
+
+// handleOAuthCallback accepts the code in the query string, or as a form
+// post for providers using response_mode=form_post such as Apple.
 func (h *AuthHandler) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 	provider := chi.URLParam(r, "provider")
-	code := r.URL.Query().Get("code")
-	state := r.URL.Query().Get("state")
+	code := r.FormValue("code")
+	state := r.FormValue("state")
 
 	userInfo, err := h.oauthService.HandleCallback(r.Context(), provider, code, state)
 	if err != nil {
@@ -53,25 +68,17 @@ func (h *AuthHandler) handleOAuthCallback(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	user, err := h.createOrGetOAuthUser(r.Context(), userInfo, provider)
-	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
-		return
+	if userInfo.Name == "" {
+		userInfo.Name = appleUserName(r.FormValue("user"))
 	}
 
-	tokenPair, err := h.authService.GenerateTokenPair(r.Context(), user)
+	user, err := h.createOrGetOAuthUser(r.Context(), userInfo, provider)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		writeOAuthUserError(w, err)
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, types.LoginResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		TokenType:    "Bearer",
-		ExpiresAt:    tokenPair.ExpiresIn,
-		User:         user,
-	})
+	h.completeLogin(w, r, user, types.TwoFactorMethodOAuth)
 }
 
 func (h *AuthHandler) handleOAuthMobileCallback(w http.ResponseWriter, r *http.Request) {
@@ -94,32 +101,32 @@ func (h *AuthHandler) handleOAuthMobileCallback(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	user, err := h.createOrGetOAuthUser(r.Context(), userInfo, provider)
-	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
-		return
+	if userInfo.Name == "" {
+		userInfo.Name = strings.TrimSpace(payload.Name)
 	}
 
-	tokenPair, err := h.authService.GenerateTokenPair(r.Context(), user)
+	user, err := h.createOrGetOAuthUser(r.Context(), userInfo, provider)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		writeOAuthUserError(w, err)
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, types.LoginResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		TokenType:    "Bearer",
-		ExpiresAt:    tokenPair.ExpiresIn,
-		User:         user,
-	})
+	h.completeLogin(w, r, user, types.TwoFactorMethodOAuth)
 }
 
-func (h *AuthHandler) createOrGetOAuthUser(ctx context.Context, userInfo *types.OAuthUserInfo, provider string) (*types.User, error) {
-	if userInfo.Email == "" {
-		return nil, fmt.Errorf("provider did not return an email address")
+// writeOAuthUserError reports why a provider identity couldn't be signed in.
+func writeOAuthUserError(w http.ResponseWriter, err error) {
+	if err == types.ErrAccountNotLinked {
+		utils.WriteError(w, http.StatusConflict, types.AuthError{
+			Code:    types.ErrAccountNotLinked.Code,
+			Message: "An account with this email already exists; sign in and link this provider from your settings",
+		})
+		return
 	}
+	utils.WriteError(w, http.StatusInternalServerError, err)
+}
 
+func (h *AuthHandler) createOrGetOAuthUser(ctx context.Context, userInfo *types.OAuthUserInfo, provider string) (*types.User, error) {
 	if oauthStore, ok := h.store.(repository.OAuthStore); ok {
 		account, err := oauthStore.GetAccountByProvider(ctx, provider, userInfo.ID)
 		if err == nil {
@@ -127,8 +134,18 @@ func (h *AuthHandler) createOrGetOAuthUser(ctx context.Context, userInfo *types.
 		}
 	}
 
+	if userInfo.Email == "" {
+		return nil, fmt.Errorf("provider did not return an email address")
+	}
+
 	existingUser, err := h.store.GetUserByEmail(ctx, userInfo.Email)
 	if err == nil {
+		// Only a verified address proves the provider identity belongs to this
+		// user; otherwise anyone could claim an account at a lax provider
+		if !userInfo.EmailVerified {
+			return nil, types.ErrAccountNotLinked
+		}
+
 		if oauthStore, ok := h.store.(repository.OAuthStore); ok {
 			account := &types.Account{
 				UserID:            existingUser.ID,
@@ -155,12 +172,13 @@ func (h *AuthHandler) createOrGetOAuthUser(ctx context.Context, userInfo *types.
 	}
 
 	newUser := &types.User{
+		ID:            uuid.New().String(),
 		Username:      userInfo.Username,
 		Name:          userInfo.Name,
 		Email:         userInfo.Email,
 		EmailVerified: verifiedAt,
 		Image:         userInfo.AvatarURL,
-		Role:          types.RoleUser,
+		Role:          types.RoleClient,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
@@ -210,6 +228,10 @@ func (h *AuthHandler) handleLinkAccount(w http.ResponseWriter, r *http.Request)
 
 	err = h.oauthService.LinkAccount(r.Context(), claims.UserID, provider, userInfo)
 	if err != nil {
+		if err == types.ErrAccountAlreadyLinked {
+			utils.WriteError(w, http.StatusConflict, err)
+			return
+		}
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -232,6 +254,10 @@ func (h *AuthHandler) handleUnlinkAccount(w http.ResponseWriter, r *http.Request
 
 	err := h.oauthService.UnlinkAccount(r.Context(), claims.UserID, provider)
 	if err != nil {
+		if err == types.ErrAccountNotLinked {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -268,3 +294,22 @@ func (h *AuthHandler) handleGetLinkedAccounts(w http.ResponseWriter, r *http.Req
 		"linked_accounts": safeAccounts,
 	})
 }
+
+// appleUserName reads the name Apple posts as a JSON "user" form field, which
+// it only sends the first time a user signs in.
+func appleUserName(raw string) string {
+	if raw == "" {
+		return ""
+	}
+
+	var user struct {
+		Name struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(raw), &user); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(user.Name.FirstName + " " + user.Name.LastName)
+}
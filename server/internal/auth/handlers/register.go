@@ -72,13 +72,6 @@ func (h *AuthHandler) handleRegister(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Password hashed successfully, creating user object")
 
-	role := types.RoleClient
-	if payload.Role != "" {
-		role = payload.Role
-	}
-
-	log.Printf("Assigned role: %s", role)
-
 	user := &types.User{
 		ID:                 uuid.New().String(),
 		Username:           payload.Username,
@@ -86,7 +79,7 @@ func (h *AuthHandler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		Name:               payload.Name,
 		PasswordHash:       hashedPassword,
 		IsTwoFactorEnabled: false,
-		Role:               role,
+		Role:               types.RoleClient,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
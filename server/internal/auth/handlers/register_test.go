@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterRejectsCoachRole(t *testing.T) {
+	h := &AuthHandler{}
+	body := `{"username":"newcoach","email":"coach@example.com","password":"password123","role":"coach"}`
+
+	rec := httptest.NewRecorder()
+	h.handleRegister(rec, httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("registering as a coach returned %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/auth/middleware"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/auth/utils"
+)
+
+const deviceNameHeader = "X-Device-Name"
+
+func (h *AuthHandler) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(r.Context(), claims.UserID, claims.JTI)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, types.SessionListResponse{Sessions: sessions})
+}
+
+func (h *AuthHandler) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionId")
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if err == types.ErrSessionNotFound {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, "Session signed out")
+}
+
+func (h *AuthHandler) handleRevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaimsFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(r.Context(), claims.UserID, claims.JTI)
+	if err != nil {
+		if err == types.ErrSessionNotFound {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"message":          "Signed out of all other sessions",
+		"revoked_sessions": revoked,
+	})
+}
+
+// deviceInfoFromRequest collects what we record about the client a token is
+// issued to. Apps can name themselves with the X-Device-Name header; otherwise
+// a name is derived from the user agent.
+func deviceInfoFromRequest(r *http.Request) types.DeviceInfo {
+	userAgent := r.UserAgent()
+
+	deviceName := strings.TrimSpace(r.Header.Get(deviceNameHeader))
+	if len(deviceName) > 100 {
+		deviceName = deviceName[:100]
+	}
+	if deviceName == "" {
+		deviceName = deviceNameFromUserAgent(userAgent)
+	}
+
+	return types.DeviceInfo{
+		DeviceName: deviceName,
+		UserAgent:  userAgent,
+		IPAddress:  requestIP(r),
+	}
+}
+
+// requestIP returns the client address as a bare IP, or "" if it can't be parsed.
+func requestIP(r *http.Request) string {
+	candidate := middleware.GetClientIP(r)
+	if net.ParseIP(candidate) == nil {
+		return ""
+	}
+	return candidate
+}
+
+func deviceNameFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	platform := ""
+	switch {
+	case strings.Contains(ua, "iphone"):
+		platform = "iPhone"
+	case strings.Contains(ua, "ipad"):
+		platform = "iPad"
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	client := ""
+	switch {
+	case strings.Contains(ua, "dart"), strings.Contains(ua, "okhttp"), strings.Contains(ua, "cfnetwork"):
+		client = "Fit-Up app"
+	case strings.Contains(ua, "edg/"):
+		client = "Edge"
+	case strings.Contains(ua, "firefox"):
+		client = "Firefox"
+	case strings.Contains(ua, "chrome"):
+		client = "Chrome"
+	case strings.Contains(ua, "safari"):
+		client = "Safari"
+	}
+
+	switch {
+	case client != "" && platform != "":
+		return client + " on " + platform
+	case client != "":
+		return client
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
@@ -89,6 +89,13 @@ func (h *AuthHandler) handleGetTemplateByID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Templates shared with coaching clients are only available through the template library
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if !template.IsPublic && template.UserID != userID {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("template not found"))
+		return
+	}
+
 	utils.WriteJSON(w, http.StatusOK, template)
 }
 
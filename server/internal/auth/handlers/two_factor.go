@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/middleware"
+	"github.com/tdmdh/fit-up-server/internal/auth/services"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/auth/utils"
+)
+
+// completeLogin finishes a login whose first factor already succeeded. Accounts
+// with two-factor enabled get a challenge instead of tokens.
+func (h *AuthHandler) completeLogin(w http.ResponseWriter, r *http.Request, user *types.User, method types.TwoFactorMethod) {
+	if err := service.CheckAccountStatus(user); err != nil {
+		utils.WriteError(w, http.StatusForbidden, err)
+		return
+	}
+
+	if user.IsTwoFactorEnabled {
+		challenge, err := h.twoFactorService.CreateChallenge(r.Context(), user.ID, method)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		utils.WriteJSON(w, http.StatusOK, challenge)
+		return
+	}
+
+	h.writeTokenPair(w, r, user)
+}
+
+func (h *AuthHandler) writeTokenPair(w http.ResponseWriter, r *http.Request, user *types.User) {
+	device := deviceInfoFromRequest(r)
+
+	tokenPair, err := h.authService.GenerateTokenPair(r.Context(), user, device)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.loginSecurity.RecordSuccessfulLogin(r.Context(), user, device)
+
+	// Signing in during the grace period cancels a pending account deletion
+	if user.DeletionScheduledFor != nil {
+		if _, err := h.authService.CancelAccountDeletion(r.Context(), user.ID); err != nil {
+			log.Printf("Failed to cancel account deletion for %s: %v", user.ID, err)
+		} else {
+			user.DeletionScheduledFor = nil
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, types.LoginResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    tokenPair.ExpiresIn,
+		User:         user,
+	})
+}
+
+func (h *AuthHandler) handleVerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var payload types.TwoFactorVerifyRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, err := h.twoFactorService.VerifyChallenge(r.Context(), payload.ChallengeToken, payload.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	user, err := h.store.GetUserByID(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrInvalidChallenge)
+		return
+	}
+
+	if err := service.CheckAccountStatus(user); err != nil {
+		utils.WriteError(w, http.StatusForbidden, err)
+		return
+	}
+
+	h.writeTokenPair(w, r, user)
+}
+
+func (h *AuthHandler) handleGetTwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return
+	}
+
+	status, err := h.twoFactorService.GetStatus(r.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, status)
+}
+
+func (h *AuthHandler) handleSetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return
+	}
+
+	user, err := h.store.GetUserByID(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, types.ErrUserNotFound)
+		return
+	}
+
+	setup, err := h.twoFactorService.BeginSetup(r.Context(), user)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, setup)
+}
+
+func (h *AuthHandler) handleConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, payload, ok := parseTwoFactorCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.ConfirmSetup(r.Context(), userID, payload.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, types.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) handleDisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, payload, ok := parseTwoFactorCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(r.Context(), userID, payload.Code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, "Two-factor authentication disabled")
+}
+
+func (h *AuthHandler) handleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, payload, ok := parseTwoFactorCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), userID, payload.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, types.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func parseTwoFactorCodeRequest(w http.ResponseWriter, r *http.Request) (string, *types.TwoFactorCodeRequest, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, types.ErrUnauthorized)
+		return "", nil, false
+	}
+
+	var payload types.TwoFactorCodeRequest
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	return userID, &payload, true
+}
+
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch err {
+	case types.ErrInvalidTwoFactorCode, types.ErrInvalidChallenge:
+		utils.WriteError(w, http.StatusUnauthorized, err)
+	case types.ErrTwoFactorAlreadyEnabled:
+		utils.WriteError(w, http.StatusConflict, err)
+	case types.ErrTwoFactorNotEnabled, types.ErrTwoFactorSetupRequired:
+		utils.WriteError(w, http.StatusBadRequest, err)
+	default:
+		log.Printf("Two-factor error: %v", err)
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
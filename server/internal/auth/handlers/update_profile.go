@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/tdmdh/fit-up-server/internal/auth/middleware"
 	"github.com/tdmdh/fit-up-server/internal/auth/types"
@@ -34,6 +35,12 @@ func (h *AuthHandler) handleUpdateProfile(w http.ResponseWriter, r *http.Request
 	if req.Image != nil {
 		log.Printf("📸 Image: base64 string (length: %d)", len(*req.Image))
 	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			utils.WriteError(w, http.StatusBadRequest, types.ErrInvalidInput)
+			return
+		}
+	}
 
 	if err := h.store.UpdateUser(r.Context(), userID, &req); err != nil {
 		if err == types.ErrUserNotFound {
@@ -22,7 +22,12 @@ func (h *AuthHandler) handleUpdateRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Role != types.RoleUser && req.Role != types.RoleCoach {
+	if req.Role == types.RoleCoach {
+		utils.WriteError(w, http.StatusForbidden, types.ErrCoachApplicationRequired)
+		return
+	}
+
+	if req.Role != types.RoleUser {
 		utils.WriteError(w, http.StatusBadRequest, types.ErrInvalidInput)
 		return
 	}
@@ -33,6 +38,10 @@ func (h *AuthHandler) handleUpdateRole(w http.ResponseWriter, r *http.Request) {
 			utils.WriteError(w, http.StatusNotFound, err)
 			return
 		}
+		if err == types.ErrCoachApplicationRequired {
+			utils.WriteError(w, http.StatusForbidden, err)
+			return
+		}
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
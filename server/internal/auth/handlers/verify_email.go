@@ -30,24 +30,9 @@ func (h *AuthHandler) handleVerifyEmail(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tokenPair, err := h.authService.GenerateTokenPair(r.Context(), user)
-	if err != nil {
-		utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
-			"message": "Email verified successfully",
-			"user":    user,
-		})
-		return
-	}
-
-	response := types.AuthResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    tokenPair.ExpiresIn,
-		User:         user,
-	}
-
-	utils.WriteJSON(w, http.StatusOK, response)
+	// Verifying signs the user in, so it goes through the same checks as any
+	// other login
+	h.completeLogin(w, r, user, types.TwoFactorMethodEmailVerification)
 }
 
 func (h *AuthHandler) handleResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
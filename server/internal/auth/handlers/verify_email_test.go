@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/repository"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+// fakeVerifyAuthService only verifies tokens; issuing tokens panics through the
+// embedded interface, so a test fails if verification skips the login checks.
+type fakeVerifyAuthService struct {
+	repository.AuthService
+	user *types.User
+}
+
+func (f *fakeVerifyAuthService) VerifyEmail(ctx context.Context, token string) (*types.User, error) {
+	return f.user, nil
+}
+
+type fakeTwoFactorService struct {
+	repository.TwoFactorService
+	method types.TwoFactorMethod
+}
+
+func (f *fakeTwoFactorService) CreateChallenge(ctx context.Context, userID string, method types.TwoFactorMethod) (*types.TwoFactorChallengeResponse, error) {
+	f.method = method
+	return &types.TwoFactorChallengeResponse{TwoFactorRequired: true, ChallengeToken: "challenge", ExpiresAt: time.Now().Add(5 * time.Minute)}, nil
+}
+
+func verifyEmail(h *AuthHandler) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.handleVerifyEmail(rec, httptest.NewRequest(http.MethodPost, "/verify-email", strings.NewReader(`{"token":"abc"}`)))
+	return rec
+}
+
+func TestVerifyEmailDoesNotSignInBlockedAccounts(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		user types.User
+	}{
+		{"suspended", types.User{ID: "u1", SuspendedAt: &now}},
+		{"deleted", types.User{ID: "u1", DeletedAt: &now}},
+		{"password reset required", types.User{ID: "u1", PasswordResetRequired: true}},
+	}
+
+	for _, tt := range tests {
+		user := tt.user
+		h := &AuthHandler{authService: &fakeVerifyAuthService{user: &user}}
+
+		if rec := verifyEmail(h); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestVerifyEmailRequiresTheSecondFactor(t *testing.T) {
+	twoFactor := &fakeTwoFactorService{}
+	h := &AuthHandler{
+		authService:      &fakeVerifyAuthService{user: &types.User{ID: "u1", IsTwoFactorEnabled: true}},
+		twoFactorService: twoFactor,
+	}
+
+	rec := verifyEmail(h)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["two_factor_required"] != true || body["access_token"] != nil {
+		t.Errorf("expected a two-factor challenge without tokens, got %v", body)
+	}
+	if twoFactor.method != types.TwoFactorMethodEmailVerification {
+		t.Errorf("challenge method = %q, want %q", twoFactor.method, types.TwoFactorMethodEmailVerification)
+	}
+}
@@ -22,7 +22,7 @@ func CORS() func(http.Handler) http.Handler {
 
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
 			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-CSRF-Token, X-Requested-With")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-Response-Time, X-RateLimit-Remaining")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-Response-Time, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 			w.Header().Set("Access-Control-Max-Age", "86400")
 			if r.Method == "OPTIONS" {
@@ -1,102 +1,137 @@
 package middleware
 
 import (
-	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/tdmdh/fit-up-server/internal/auth/types"
 	"github.com/tdmdh/fit-up-server/internal/auth/utils"
+	"github.com/tdmdh/fit-up-server/shared/config"
+	"github.com/tdmdh/fit-up-server/shared/ratelimit"
 )
 
-type RateLimiter struct {
-	requests map[string][]time.Time
-	mutex    sync.RWMutex
-	limit    int
-	window   time.Duration
-}
+const (
+	PolicyLogin             = "login"
+	PolicyRegister          = "register"
+	PolicyPasswordReset     = "password_reset"
+	PolicyTokenRefresh      = "token_refresh"
+	PolicyEmailVerification = "email_verification"
+	PolicyMagicLink         = "magic_link"
+	PolicyEmailChange       = "email_change"
+	PolicyEmailLinkRedeem   = "email_link_redeem"
+)
 
-func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		requests: make(map[string][]time.Time),
-		limit:    limit,
-		window:   window,
-	}
+// DefaultRateLimitPolicies apply unless RATE_LIMIT_POLICIES overrides them.
+var DefaultRateLimitPolicies = []ratelimit.Policy{
+	{Name: PolicyLogin, Limit: 5, Window: 15 * time.Minute, Scope: ratelimit.ScopeIP},
+	{Name: PolicyRegister, Limit: 3, Window: time.Hour, Scope: ratelimit.ScopeIP},
+	{Name: PolicyPasswordReset, Limit: 3, Window: time.Hour, Scope: ratelimit.ScopeIP},
+	{Name: PolicyTokenRefresh, Limit: 10, Window: time.Minute, Scope: ratelimit.ScopeUser},
+	{Name: PolicyEmailVerification, Limit: 3, Window: time.Hour, Scope: ratelimit.ScopeIP},
+	{Name: PolicyMagicLink, Limit: 5, Window: 15 * time.Minute, Scope: ratelimit.ScopeIP},
+	{Name: PolicyEmailChange, Limit: 5, Window: time.Hour, Scope: ratelimit.ScopeUser},
+	{Name: PolicyEmailLinkRedeem, Limit: 10, Window: 15 * time.Minute, Scope: ratelimit.ScopeIP},
 }
 
-func (rl *RateLimiter) Allow(key string) bool {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
-
-	now := time.Now()
+var (
+	rateLimitMutex sync.RWMutex
+	rateLimiter    = ratelimit.NewLimiter(ratelimit.NewMemoryStore(), DefaultRateLimitPolicies)
+	ipResolver     = &ratelimit.IPResolver{}
+)
 
-	if requests, exists := rl.requests[key]; exists {
-		var validRequests []time.Time
-		for _, reqTime := range requests {
-			if now.Sub(reqTime) < rl.window {
-				validRequests = append(validRequests, reqTime)
-			}
-		}
-		rl.requests[key] = validRequests
+// ConfigureRateLimiting installs the shared counter store, the trusted proxy
+// list and any policy overrides. Until it is called, limits are kept in memory
+// with the default policies and forwarding headers are ignored.
+func ConfigureRateLimiting(cfg config.RateLimitConfig, store ratelimit.Store) error {
+	resolver, err := ratelimit.NewIPResolver(strings.Split(cfg.TrustedProxies, ","))
+	if err != nil {
+		return err
 	}
 
-	if len(rl.requests[key]) >= rl.limit {
-		return false
+	overrides, err := ratelimit.ParsePolicies(cfg.Policies)
+	if err != nil {
+		return err
 	}
 
-	rl.requests[key] = append(rl.requests[key], now)
-	return true
+	rateLimitMutex.Lock()
+	defer rateLimitMutex.Unlock()
+	rateLimiter = ratelimit.NewLimiter(store, ratelimit.MergePolicies(DefaultRateLimitPolicies, overrides))
+	ipResolver = resolver
+	return nil
 }
 
+func currentRateLimiting() (*ratelimit.Limiter, *ratelimit.IPResolver) {
+	rateLimitMutex.RLock()
+	defer rateLimitMutex.RUnlock()
+	return rateLimiter, ipResolver
+}
+
+// GetClientIP returns the client IP, honouring X-Forwarded-For and X-Real-IP
+// only when the request arrived through a trusted proxy.
 func GetClientIP(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		return xff
-	}
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-	return r.RemoteAddr
+	_, resolver := currentRateLimiting()
+	return resolver.ClientIP(r)
 }
 
-func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
+// RealIP rewrites r.RemoteAddr to the resolved client IP so request logging
+// shows the real client. Unlike chi's RealIP it ignores headers from untrusted peers.
+func RealIP() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			clientIP := GetClientIP(r)
-
-			if !limiter.Allow(clientIP) {
-				utils.WriteError(w, http.StatusTooManyRequests, types.AuthError{
-					Code:    "RATE_LIMIT_EXCEEDED",
-					Message: fmt.Sprintf("Rate limit exceeded. Too many requests from %s", clientIP),
-				})
-				return
+			if ip := GetClientIP(r); net.ParseIP(ip) != nil {
+				r.RemoteAddr = ip
 			}
-
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-func UserRateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
+// RateLimit enforces the named policy. User-scoped policies count per
+// authenticated user and fall back to the client IP for anonymous requests.
+func RateLimit(policyName string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if userID, exists := GetUserIDFromContext(r.Context()); exists {
-				if !limiter.Allow(fmt.Sprintf("user:%s", userID)) {
-					utils.WriteError(w, http.StatusTooManyRequests, types.AuthError{
+			limiter, resolver := currentRateLimiting()
+
+			policy, ok := limiter.Policy(policyName)
+			if !ok {
+				log.Printf("Rate limit policy %q is not configured; allowing request", policyName)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			subject := "ip:" + resolver.ClientIP(r)
+			perUser := false
+			if policy.Scope == ratelimit.ScopeUser {
+				if userID, exists := GetUserIDFromContext(r.Context()); exists && userID != "" {
+					subject = "user:" + userID
+					perUser = true
+				}
+			}
+
+			decision, err := limiter.Allow(r.Context(), policyName, subject)
+			if err != nil {
+				log.Printf("Rate limit check for %s failed, allowing request: %v", policyName, err)
+			}
+			ratelimit.SetHeaders(w, decision)
+
+			if !decision.Allowed {
+				rateLimitErr := types.AuthError{
+					Code:    "RATE_LIMIT_EXCEEDED",
+					Message: "Rate limit exceeded, please try again later",
+				}
+				if perUser {
+					rateLimitErr = types.AuthError{
 						Code:    "USER_RATE_LIMIT_EXCEEDED",
 						Message: "Rate limit exceeded for this user",
-					})
-					return
-				}
-			} else {
-				clientIP := GetClientIP(r)
-				if !limiter.Allow(clientIP) {
-					utils.WriteError(w, http.StatusTooManyRequests, types.AuthError{
-						Code:    "RATE_LIMIT_EXCEEDED",
-						Message: "Rate limit exceeded",
-					})
-					return
+					}
 				}
+				utils.WriteError(w, http.StatusTooManyRequests, rateLimitErr)
+				return
 			}
 
 			next.ServeHTTP(w, r)
@@ -104,30 +139,35 @@ func UserRateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handl
 	}
 }
 
-var (
-	LoginRateLimiter            = NewRateLimiter(5, 15*time.Minute)
-	RegisterRateLimiter         = NewRateLimiter(3, time.Hour)
-	PasswordResetRateLimiter    = NewRateLimiter(3, time.Hour)
-	TokenRefreshRateLimiter     = NewRateLimiter(10, time.Minute)
-	EmailVerificationRateLimiter = NewRateLimiter(3, time.Hour)
-)
-
 func LoginRateLimit() func(http.Handler) http.Handler {
-	return RateLimitMiddleware(LoginRateLimiter)
+	return RateLimit(PolicyLogin)
 }
 
 func RegisterRateLimit() func(http.Handler) http.Handler {
-	return RateLimitMiddleware(RegisterRateLimiter)
+	return RateLimit(PolicyRegister)
 }
 
 func PasswordResetRateLimit() func(http.Handler) http.Handler {
-	return RateLimitMiddleware(PasswordResetRateLimiter)
+	return RateLimit(PolicyPasswordReset)
 }
 
 func TokenRefreshRateLimit() func(http.Handler) http.Handler {
-	return UserRateLimitMiddleware(TokenRefreshRateLimiter)
+	return RateLimit(PolicyTokenRefresh)
 }
 
 func EmailVerificationRateLimit() func(http.Handler) http.Handler {
-	return RateLimitMiddleware(EmailVerificationRateLimiter)
+	return RateLimit(PolicyEmailVerification)
+}
+
+func MagicLinkRateLimit() func(http.Handler) http.Handler {
+	return RateLimit(PolicyMagicLink)
+}
+
+func EmailChangeRateLimit() func(http.Handler) http.Handler {
+	return RateLimit(PolicyEmailChange)
+}
+
+// EmailLinkRedeemRateLimit limits guessing at emailed tokens and PKCE verifiers.
+func EmailLinkRedeemRateLimit() func(http.Handler) http.Handler {
+	return RateLimit(PolicyEmailLinkRedeem)
 }
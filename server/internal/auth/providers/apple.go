@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	appleIssuer = "https://appleid.apple.com"
+	// Apple allows up to six months; a short-lived secret per request avoids rotation.
+	appleClientSecretTTL = 5 * time.Minute
+)
+
+// appleSecretSigner mints the ES256 client secret JWT Sign in with Apple
+// requires in place of a static secret.
+type appleSecretSigner struct {
+	teamID string
+	keyID  string
+	key    *ecdsa.PrivateKey
+	now    func() time.Time
+}
+
+// newAppleSecretSigner parses the .p8 key. Literal "\n" sequences are turned
+// into newlines so the key can be passed in a single-line environment variable.
+func newAppleSecretSigner(teamID, keyID, privateKeyPEM string) (*appleSecretSigner, error) {
+	if teamID == "" || keyID == "" || privateKeyPEM == "" {
+		return nil, fmt.Errorf("apple sign-in needs APPLE_TEAM_ID, APPLE_KEY_ID and APPLE_PRIVATE_KEY")
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(strings.ReplaceAll(privateKeyPEM, `\n`, "\n")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid APPLE_PRIVATE_KEY: %w", err)
+	}
+
+	return &appleSecretSigner{teamID: teamID, keyID: keyID, key: key, now: time.Now}, nil
+}
+
+func (s *appleSecretSigner) clientSecret(clientID string) (string, error) {
+	now := s.now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Issuer:    s.teamID,
+		Subject:   clientID,
+		Audience:  jwt.ClaimStrings{appleIssuer},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(appleClientSecretTTL)),
+	})
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.key)
+}
+
+// newAppleProvider builds Sign in with Apple on top of the generic OIDC
+// provider. Apple posts the callback as a form when name or email is requested,
+// and doesn't take PKCE verifiers.
+func newAppleProvider(clientID, redirectURI string, signer *appleSecretSigner, client *http.Client) Provider {
+	return NewOIDCProvider(OIDCConfig{
+		Name:        "apple",
+		DisplayName: "Apple",
+		Issuer:      appleIssuer,
+		ClientID:    clientID,
+		RedirectURI: redirectURI,
+		Scopes:      []string{"name", "email"},
+		AuthParams:  url.Values{"response_mode": {"form_post"}},
+		ClientSecretFunc: func() (string, error) {
+			return signer.clientSecret(clientID)
+		},
+		DisablePKCE: true,
+	}, client)
+}
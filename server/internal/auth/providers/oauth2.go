@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+// oauth2Provider covers plain OAuth 2.0 providers without ID tokens. The user
+// is read from a provider-specific profile endpoint.
+type oauth2Provider struct {
+	name         string
+	displayName  string
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	authURL      string
+	tokenURL     string
+	userInfoURL  string
+	scopes       []string
+	pkce         bool
+	authParams   url.Values
+	parseUser    func(raw json.RawMessage) (*types.OAuthUserInfo, error)
+	client       *http.Client
+}
+
+func (p *oauth2Provider) Name() string        { return p.name }
+func (p *oauth2Provider) DisplayName() string { return p.displayName }
+func (p *oauth2Provider) SupportsPKCE() bool  { return p.pkce }
+
+func (p *oauth2Provider) AuthCodeURL(_ context.Context, req AuthRequest) (string, error) {
+	return buildAuthURL(p.authURL, p.clientID, p.scopes, p.authParams, req, p.pkce, false)
+}
+
+func (p *oauth2Provider) Exchange(ctx context.Context, code, codeVerifier, redirectURI string) (*Token, error) {
+	if redirectURI == "" {
+		redirectURI = p.redirectURI
+	}
+	return exchangeCode(ctx, p.client, p.tokenURL, p.clientID, p.clientSecret, code, codeVerifier, redirectURI)
+}
+
+func (p *oauth2Provider) Identity(ctx context.Context, token *Token, _ string) (*types.OAuthUserInfo, error) {
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%s did not return an access token", p.name)
+	}
+
+	var raw json.RawMessage
+	if err := getJSON(ctx, p.client, p.userInfoURL, token.AccessToken, &raw); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	userInfo, err := p.parseUser(raw)
+	if err != nil {
+		return nil, err
+	}
+	if userInfo.ID == "" {
+		return nil, fmt.Errorf("%s did not return a user ID", p.name)
+	}
+	return userInfo, nil
+}
+
+func newGitHubProvider(clientID, clientSecret, redirectURI string, client *http.Client) *oauth2Provider {
+	return &oauth2Provider{
+		name:         "github",
+		displayName:  "GitHub",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURI:  redirectURI,
+		authURL:      "https://github.com/login/oauth/authorize",
+		tokenURL:     "https://github.com/login/oauth/access_token",
+		userInfoURL:  "https://api.github.com/user",
+		scopes:       []string{"user:email"},
+		pkce:         true,
+		authParams:   url.Values{"allow_signup": {"true"}},
+		parseUser:    parseGitHubUser,
+		client:       client,
+	}
+}
+
+func parseGitHubUser(raw json.RawMessage) (*types.OAuthUserInfo, error) {
+	var githubUser struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := json.Unmarshal(raw, &githubUser); err != nil {
+		return nil, err
+	}
+
+	return &types.OAuthUserInfo{
+		ID:        fmt.Sprintf("%d", githubUser.ID),
+		Email:     githubUser.Email,
+		Name:      githubUser.Name,
+		Username:  githubUser.Login,
+		AvatarURL: githubUser.AvatarURL,
+		// GitHub only exposes a verified primary address on the profile
+		EmailVerified: githubUser.Email != "",
+	}, nil
+}
+
+func newFacebookProvider(clientID, clientSecret, redirectURI string, client *http.Client) *oauth2Provider {
+	return &oauth2Provider{
+		name:         "facebook",
+		displayName:  "Facebook",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURI:  redirectURI,
+		authURL:      "https://www.facebook.com/v19.0/dialog/oauth",
+		tokenURL:     "https://graph.facebook.com/v19.0/oauth/access_token",
+		userInfoURL:  "https://graph.facebook.com/v19.0/me?fields=id,name,email,picture",
+		scopes:       []string{"email", "public_profile"},
+		pkce:         true,
+		parseUser:    parseFacebookUser,
+		client:       client,
+	}
+}
+
+func parseFacebookUser(raw json.RawMessage) (*types.OAuthUserInfo, error) {
+	var facebookUser struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		Picture struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	if err := json.Unmarshal(raw, &facebookUser); err != nil {
+		return nil, err
+	}
+
+	return &types.OAuthUserInfo{
+		ID:        facebookUser.ID,
+		Email:     facebookUser.Email,
+		Name:      facebookUser.Name,
+		AvatarURL: facebookUser.Picture.Data.URL,
+		// Facebook only returns confirmed addresses
+		EmailVerified: facebookUser.Email != "",
+	}, nil
+}
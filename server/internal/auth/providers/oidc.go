@@ -0,0 +1,305 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+const (
+	discoveryTTL  = 24 * time.Hour
+	idTokenLeeway = time.Minute
+)
+
+// allowedSigningAlgs are the ID token algorithms we verify. Symmetric and
+// "none" algorithms are never accepted, whatever discovery advertises.
+var allowedSigningAlgs = map[string]bool{
+	"RS256": true, "RS384": true, "RS512": true,
+	"PS256": true, "PS384": true, "PS512": true,
+	"ES256": true, "ES384": true,
+}
+
+// OIDCConfig describes an OpenID Connect provider. Endpoints and signing keys
+// are found through discovery on first use.
+type OIDCConfig struct {
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+	// AuthParams are added to every authorization URL.
+	AuthParams url.Values
+	// ClientSecretFunc, when set, mints the client secret for each token request.
+	ClientSecretFunc func() (string, error)
+	// ExtraIssuers are also accepted in the iss claim.
+	ExtraIssuers []string
+	DisablePKCE  bool
+}
+
+type discoveryDocument struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserInfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	SigningAlgs           []string `json:"id_token_signing_alg_values_supported"`
+}
+
+type oidcProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *discoveryDocument
+	discoveredAt time.Time
+	keys         *keySet
+}
+
+// NewOIDCProvider creates a provider for any OpenID Connect issuer. A nil
+// client uses a default one with a 30 second timeout.
+func NewOIDCProvider(cfg OIDCConfig, client *http.Client) Provider {
+	if client == nil {
+		client = defaultHTTPClient()
+	}
+	return &oidcProvider{cfg: cfg, client: client}
+}
+
+func (p *oidcProvider) Name() string        { return p.cfg.Name }
+func (p *oidcProvider) DisplayName() string { return p.cfg.DisplayName }
+func (p *oidcProvider) SupportsPKCE() bool  { return !p.cfg.DisablePKCE }
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	doc, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return buildAuthURL(doc.AuthorizationEndpoint, p.cfg.ClientID, p.cfg.Scopes, p.cfg.AuthParams, req, p.SupportsPKCE(), true)
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier, redirectURI string) (*Token, error) {
+	doc, _, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := p.cfg.ClientSecret
+	if p.cfg.ClientSecretFunc != nil {
+		if secret, err = p.cfg.ClientSecretFunc(); err != nil {
+			return nil, fmt.Errorf("failed to create client secret: %w", err)
+		}
+	}
+
+	if redirectURI == "" {
+		redirectURI = p.cfg.RedirectURI
+	}
+	if !p.SupportsPKCE() {
+		codeVerifier = ""
+	}
+	return exchangeCode(ctx, p.client, doc.TokenEndpoint, p.cfg.ClientID, secret, code, codeVerifier, redirectURI)
+}
+
+func (p *oidcProvider) Identity(ctx context.Context, token *Token, nonce string) (*types.OAuthUserInfo, error) {
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%s did not return an ID token", p.cfg.Name)
+	}
+
+	claims, err := p.verifyIDToken(ctx, token.IDToken, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	userInfo := claims.userInfo()
+
+	// Some providers keep profile claims out of the ID token
+	if (userInfo.Email == "" || userInfo.Name == "") && token.AccessToken != "" {
+		if doc, _, err := p.discover(ctx); err == nil && doc.UserInfoEndpoint != "" {
+			var extra idTokenClaims
+			if err := getJSON(ctx, p.client, doc.UserInfoEndpoint, token.AccessToken, &extra); err == nil && extra.Subject == claims.Subject {
+				mergeUserInfo(userInfo, extra.userInfo())
+			}
+		}
+	}
+
+	return userInfo, nil
+}
+
+// verifyIDToken checks the signature against the provider's JWKS and the
+// issuer, audience, expiry and nonce claims.
+func (p *oidcProvider) verifyIDToken(ctx context.Context, raw, nonce string) (*idTokenClaims, error) {
+	doc, keys, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(signingAlgs(doc.SigningAlgs)),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+	)
+
+	var claims idTokenClaims
+	_, err = parser.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.acceptsIssuer(claims.Issuer) {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("token was issued to %q", claims.AuthorizedParty)
+	}
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, fmt.Errorf("nonce mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+
+	return &claims, nil
+}
+
+func (p *oidcProvider) acceptsIssuer(issuer string) bool {
+	if sameIssuer(issuer, p.cfg.Issuer) {
+		return true
+	}
+	for _, extra := range p.cfg.ExtraIssuers {
+		if issuer == extra {
+			return true
+		}
+	}
+	return false
+}
+
+// discover loads and caches the discovery document and key set.
+func (p *oidcProvider) discover(ctx context.Context) (*discoveryDocument, *keySet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, p.keys, nil
+	}
+
+	wellKnown := strings.TrimRight(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	var doc discoveryDocument
+	if err := getJSON(ctx, p.client, wellKnown, "", &doc); err != nil {
+		if p.discovery != nil {
+			// Keep serving the last good document while the provider is unreachable
+			return p.discovery, p.keys, nil
+		}
+		return nil, nil, fmt.Errorf("OpenID Connect discovery for %s failed: %w", p.cfg.Name, err)
+	}
+
+	if !sameIssuer(doc.Issuer, p.cfg.Issuer) {
+		return nil, nil, fmt.Errorf("discovery for %s returned issuer %q", p.cfg.Name, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("discovery for %s is missing required endpoints", p.cfg.Name)
+	}
+
+	if p.keys == nil || p.discovery == nil || p.discovery.JWKSURI != doc.JWKSURI {
+		p.keys = newKeySet(doc.JWKSURI, p.client)
+	}
+	p.discovery = &doc
+	p.discoveredAt = time.Now()
+	return p.discovery, p.keys, nil
+}
+
+func sameIssuer(a, b string) bool {
+	return strings.TrimRight(a, "/") == strings.TrimRight(b, "/")
+}
+
+// signingAlgs narrows the advertised algorithms to the ones we accept.
+// OpenID Connect requires RS256 when nothing is advertised.
+func signingAlgs(advertised []string) []string {
+	var algs []string
+	for _, alg := range advertised {
+		if allowedSigningAlgs[alg] {
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		algs = []string{"RS256"}
+	}
+	return algs
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string   `json:"nonce"`
+	AuthorizedParty   string   `json:"azp"`
+	Email             string   `json:"email"`
+	EmailVerified     flexBool `json:"email_verified"`
+	Name              string   `json:"name"`
+	GivenName         string   `json:"given_name"`
+	FamilyName        string   `json:"family_name"`
+	PreferredUsername string   `json:"preferred_username"`
+	Picture           string   `json:"picture"`
+}
+
+func (c *idTokenClaims) userInfo() *types.OAuthUserInfo {
+	name := c.Name
+	if name == "" {
+		name = strings.TrimSpace(c.GivenName + " " + c.FamilyName)
+	}
+
+	return &types.OAuthUserInfo{
+		ID:            c.Subject,
+		Email:         c.Email,
+		Name:          name,
+		Username:      c.PreferredUsername,
+		AvatarURL:     c.Picture,
+		EmailVerified: bool(c.EmailVerified),
+	}
+}
+
+// mergeUserInfo fills fields the ID token left empty.
+func mergeUserInfo(dst, src *types.OAuthUserInfo) {
+	if dst.Email == "" && src.Email != "" {
+		dst.Email = src.Email
+		dst.EmailVerified = src.EmailVerified
+	}
+	if dst.Name == "" {
+		dst.Name = src.Name
+	}
+	if dst.Username == "" {
+		dst.Username = src.Username
+	}
+	if dst.AvatarURL == "" {
+		dst.AvatarURL = src.AvatarURL
+	}
+}
+
+// flexBool accepts booleans sent as JSON strings, as Apple does for email_verified.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case bool:
+		*b = flexBool(value)
+	case string:
+		*b = flexBool(value == "true")
+	default:
+		*b = false
+	}
+	return nil
+}
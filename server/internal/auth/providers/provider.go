@@ -0,0 +1,166 @@
+// Package providers implements the OAuth and OpenID Connect identity
+// providers users can sign in with, and the registry that builds them from config.
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+// maxResponseSize caps provider responses; discovery documents and key sets are small.
+const maxResponseSize = 1 << 20
+
+// Provider is one configured identity provider. Web and mobile sign-in use
+// separate instances because they are registered with different client IDs.
+type Provider interface {
+	Name() string
+	DisplayName() string
+	// SupportsPKCE reports whether authorization codes are bound to a code verifier.
+	SupportsPKCE() bool
+	// AuthCodeURL returns the page the user is sent to to sign in.
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	// Exchange trades an authorization code for tokens. An empty redirectURI
+	// falls back to the one configured for the provider.
+	Exchange(ctx context.Context, code, codeVerifier, redirectURI string) (*Token, error)
+	// Identity returns the signed-in user. For OpenID Connect providers the ID
+	// token is verified and, when nonce is not empty, must carry that nonce.
+	Identity(ctx context.Context, token *Token, nonce string) (*types.OAuthUserInfo, error)
+}
+
+// AuthRequest holds the per-attempt values bound into the authorization URL.
+type AuthRequest struct {
+	State         string
+	RedirectURI   string
+	CodeChallenge string // S256; only sent when the provider supports PKCE
+	Nonce         string // only sent to OpenID Connect providers
+}
+
+// Token is the token endpoint response.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+func buildAuthURL(endpoint, clientID string, scopes []string, extra url.Values, req AuthRequest, pkce, oidc bool) (string, error) {
+	if endpoint == "" {
+		return "", fmt.Errorf("authorization endpoint is not configured")
+	}
+
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", req.RedirectURI)
+	params.Set("scope", strings.Join(scopes, " "))
+	params.Set("response_type", "code")
+	params.Set("state", req.State)
+	if pkce && req.CodeChallenge != "" {
+		params.Set("code_challenge", req.CodeChallenge)
+		params.Set("code_challenge_method", "S256")
+	}
+	if oidc && req.Nonce != "" {
+		params.Set("nonce", req.Nonce)
+	}
+	for key, values := range extra {
+		for _, v := range values {
+			params.Add(key, v)
+		}
+	}
+
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+	return endpoint + separator + params.Encode(), nil
+}
+
+// exchangeCode runs the authorization_code grant against tokenURL.
+func exchangeCode(ctx context.Context, client *http.Client, tokenURL, clientID, clientSecret, code, codeVerifier, redirectURI string) (*Token, error) {
+	data := url.Values{}
+	data.Set("client_id", clientID)
+	data.Set("code", code)
+	data.Set("grant_type", "authorization_code")
+	if redirectURI != "" {
+		data.Set("redirect_uri", redirectURI)
+	}
+	if clientSecret != "" {
+		data.Set("client_secret", clientSecret)
+	}
+	if codeVerifier != "" {
+		data.Set("code_verifier", codeVerifier)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResponse struct {
+		Token
+		Error     string `json:"error"`
+		ErrorDesc string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("token exchange failed with status: %d", resp.StatusCode)
+	}
+
+	if tokenResponse.Error != "" {
+		return nil, fmt.Errorf("OAuth error: %s - %s", tokenResponse.Error, tokenResponse.ErrorDesc)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange failed with status: %d", resp.StatusCode)
+	}
+
+	return &tokenResponse.Token, nil
+}
+
+// getJSON fetches url and decodes the JSON body into out. A non-empty
+// accessToken is sent as a bearer token.
+func getJSON(ctx context.Context, client *http.Client, rawURL, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s failed with status: %d", rawURL, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(out)
+}
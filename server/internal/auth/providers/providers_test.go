@@ -0,0 +1,270 @@
+package providers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tdmdh/fit-up-server/internal/auth/providers/oidctest"
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+const testRedirectURI = "https://api.example.com/api/v1/auth/oauth/callback/acme-gym"
+
+func newStubRegistry(t *testing.T, stub *oidctest.Server) *Registry {
+	t.Helper()
+
+	registry, err := NewRegistry(config.OAuthConfig{
+		OIDCProviders: []config.OIDCProviderConfig{{
+			Name:         "acme-gym",
+			DisplayName:  "Acme Gym",
+			Issuer:       stub.Issuer(),
+			ClientID:     stub.ClientID,
+			ClientSecret: stub.ClientSecret,
+			RedirectURI:  testRedirectURI,
+			Scopes:       []string{"email", "profile"},
+		}},
+	}, stub.Client())
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return registry
+}
+
+// signIn follows the authorization URL to the stub and returns the code it redirects back with.
+func signIn(t *testing.T, stub *oidctest.Server, authURL, wantState string) string {
+	t.Helper()
+
+	client := stub.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if got := location.Query().Get("state"); got != wantState {
+		t.Fatalf("state = %q, want %q", got, wantState)
+	}
+	return location.Query().Get("code")
+}
+
+func pkcePair() (string, string) {
+	verifier := strings.Repeat("v", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestOIDCProviderEndToEnd(t *testing.T) {
+	stub := oidctest.NewServer("fitup-web", "s3cret")
+	defer stub.Close()
+
+	registry := newStubRegistry(t, stub)
+	provider, ok := registry.Web("acme-gym")
+	if !ok {
+		t.Fatal("acme-gym provider not registered")
+	}
+
+	ctx := context.Background()
+	verifier, challenge := pkcePair()
+	authURL, err := provider.AuthCodeURL(ctx, AuthRequest{
+		State:         "state-1",
+		RedirectURI:   testRedirectURI,
+		CodeChallenge: challenge,
+		Nonce:         "nonce-1",
+	})
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if !strings.Contains(authURL, "scope=openid+email+profile") {
+		t.Errorf("expected openid scope to be added, got %s", authURL)
+	}
+
+	code := signIn(t, stub, authURL, "state-1")
+
+	token, err := provider.Exchange(ctx, code, verifier, "")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	userInfo, err := provider.Identity(ctx, token, "nonce-1")
+	if err != nil {
+		t.Fatalf("Identity: %v", err)
+	}
+	if userInfo.ID != "stub-user-1" || userInfo.Email != "member@gym.example" || !userInfo.EmailVerified || userInfo.Name != "Stub Member" {
+		t.Errorf("unexpected user info: %+v", userInfo)
+	}
+
+	// Codes are single use at the provider
+	if _, err := provider.Exchange(ctx, code, verifier, ""); err == nil {
+		t.Error("expected a reused code to be rejected")
+	}
+}
+
+func TestOIDCProviderRejectsWrongCodeVerifier(t *testing.T) {
+	stub := oidctest.NewServer("fitup-web", "s3cret")
+	defer stub.Close()
+
+	provider, _ := newStubRegistry(t, stub).Web("acme-gym")
+	ctx := context.Background()
+
+	_, challenge := pkcePair()
+	authURL, err := provider.AuthCodeURL(ctx, AuthRequest{State: "s", RedirectURI: testRedirectURI, CodeChallenge: challenge})
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	code := signIn(t, stub, authURL, "s")
+	if _, err := provider.Exchange(ctx, code, strings.Repeat("x", 43), ""); err == nil {
+		t.Fatal("expected exchange with the wrong verifier to fail")
+	}
+}
+
+func TestOIDCProviderRejectsInvalidIDTokens(t *testing.T) {
+	stub := oidctest.NewServer("fitup-web", "s3cret")
+	defer stub.Close()
+
+	provider, _ := newStubRegistry(t, stub).Web("acme-gym")
+	ctx := context.Background()
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]func() string{
+		"wrong audience": func() string {
+			claims := stub.IDTokenClaims("n")
+			claims["aud"] = "someone-else"
+			return stub.SignIDToken(claims)
+		},
+		"wrong issuer": func() string {
+			claims := stub.IDTokenClaims("n")
+			claims["iss"] = "https://evil.example"
+			return stub.SignIDToken(claims)
+		},
+		"expired": func() string {
+			claims := stub.IDTokenClaims("n")
+			claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+			return stub.SignIDToken(claims)
+		},
+		"nonce mismatch": func() string {
+			return stub.SignIDToken(stub.IDTokenClaims("other-nonce"))
+		},
+		"untrusted key": func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, stub.IDTokenClaims("n"))
+			token.Header["kid"] = stub.KeyID
+			signed, _ := token.SignedString(otherKey)
+			return signed
+		},
+		"symmetric algorithm": func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, stub.IDTokenClaims("n"))
+			signed, _ := token.SignedString([]byte("s3cret"))
+			return signed
+		},
+		"unsigned": func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, stub.IDTokenClaims("n"))
+			signed, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return signed
+		},
+	}
+
+	if _, err := provider.Identity(ctx, &Token{IDToken: stub.SignIDToken(stub.IDTokenClaims("n"))}, "n"); err != nil {
+		t.Fatalf("expected a valid token to pass, got %v", err)
+	}
+
+	for name, build := range cases {
+		if _, err := provider.Identity(ctx, &Token{IDToken: build()}, "n"); err == nil {
+			t.Errorf("%s: expected the ID token to be rejected", name)
+		}
+	}
+}
+
+func TestAppleClientSecretIsSignedForTheClient(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Single-line form, as it would be passed in an environment variable
+	keyPEM := strings.ReplaceAll(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), "\n", `\n`)
+
+	signer, err := newAppleSecretSigner("TEAM123456", "KEY1234567", keyPEM)
+	if err != nil {
+		t.Fatalf("newAppleSecretSigner: %v", err)
+	}
+
+	secret, err := signer.clientSecret("com.example.fitup")
+	if err != nil {
+		t.Fatalf("clientSecret: %v", err)
+	}
+
+	var claims jwt.RegisteredClaims
+	token, err := jwt.ParseWithClaims(secret, &claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(appleIssuer))
+	if err != nil {
+		t.Fatalf("client secret does not verify: %v", err)
+	}
+	if token.Header["kid"] != "KEY1234567" || claims.Issuer != "TEAM123456" || claims.Subject != "com.example.fitup" {
+		t.Errorf("unexpected client secret: header=%v claims=%+v", token.Header, claims)
+	}
+}
+
+func TestRegistryConfiguration(t *testing.T) {
+	registry, err := NewRegistry(config.OAuthConfig{
+		GoogleClientID:       "google-web",
+		GitHubMobileClientID: "github-mobile",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	if _, ok := registry.Web("facebook"); ok {
+		t.Error("providers without a client ID must not be registered")
+	}
+	if _, ok := registry.Web("github"); ok {
+		t.Error("a mobile-only provider must not be offered to the web flow")
+	}
+	if p, ok := registry.Mobile("google"); !ok || p.Name() != "google" {
+		t.Error("mobile sign-in should fall back to the web client")
+	}
+
+	invalid := map[string]config.OAuthConfig{
+		"missing issuer": {OIDCProviders: []config.OIDCProviderConfig{{Name: "acme", ClientID: "x"}}},
+		"bad name":       {OIDCProviders: []config.OIDCProviderConfig{{Name: "Acme Gym", Issuer: "https://a", ClientID: "x"}}},
+		"builtin clash": {
+			GoogleClientID: "g",
+			OIDCProviders:  []config.OIDCProviderConfig{{Name: "google", Issuer: "https://a", ClientID: "x"}},
+		},
+		"apple without key": {AppleClientID: "com.example.web"},
+	}
+	for name, cfg := range invalid {
+		if _, err := NewRegistry(cfg, nil); err == nil {
+			t.Errorf("%s: expected a configuration error", name)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Registry holds the providers enabled by configuration. A provider is only
+// registered once its client ID is set.
+type Registry struct {
+	web    map[string]Provider
+	mobile map[string]Provider
+	order  []string
+}
+
+// NewRegistry builds the built-in providers and every OIDC provider listed in
+// cfg. A nil client uses a default one with a 30 second timeout.
+func NewRegistry(cfg config.OAuthConfig, client *http.Client) (*Registry, error) {
+	if client == nil {
+		client = defaultHTTPClient()
+	}
+
+	r := &Registry{
+		web:    make(map[string]Provider),
+		mobile: make(map[string]Provider),
+	}
+
+	google := func(clientID, clientSecret, redirectURI string) Provider {
+		return NewOIDCProvider(OIDCConfig{
+			Name:         "google",
+			DisplayName:  "Google",
+			Issuer:       "https://accounts.google.com",
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURI:  redirectURI,
+			Scopes:       []string{"openid", "email", "profile"},
+			AuthParams:   url.Values{"access_type": {"offline"}, "prompt": {"consent"}},
+			ExtraIssuers: []string{"accounts.google.com"},
+		}, client)
+	}
+	if cfg.GoogleClientID != "" {
+		r.add(r.web, google(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.GoogleRedirectURI))
+	}
+	if cfg.GoogleMobileClientID != "" {
+		r.add(r.mobile, google(cfg.GoogleMobileClientID, cfg.GoogleMobileClientSecret, cfg.GoogleMobileRedirectURI))
+	}
+
+	if cfg.GitHubClientID != "" {
+		r.add(r.web, newGitHubProvider(cfg.GitHubClientID, cfg.GitHubClientSecret, cfg.GitHubRedirectURI, client))
+	}
+	if cfg.GitHubMobileClientID != "" {
+		r.add(r.mobile, newGitHubProvider(cfg.GitHubMobileClientID, cfg.GitHubMobileClientSecret, cfg.GitHubMobileRedirectURI, client))
+	}
+
+	if cfg.FacebookClientID != "" {
+		r.add(r.web, newFacebookProvider(cfg.FacebookClientID, cfg.FacebookClientSecret, cfg.FacebookRedirectURI, client))
+	}
+	if cfg.FacebookMobileClientID != "" {
+		r.add(r.mobile, newFacebookProvider(cfg.FacebookMobileClientID, cfg.FacebookMobileClientSecret, cfg.FacebookMobileRedirectURI, client))
+	}
+
+	if cfg.AppleClientID != "" || cfg.AppleMobileClientID != "" {
+		signer, err := newAppleSecretSigner(cfg.AppleTeamID, cfg.AppleKeyID, cfg.ApplePrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.AppleClientID != "" {
+			r.add(r.web, newAppleProvider(cfg.AppleClientID, cfg.AppleRedirectURI, signer, client))
+		}
+		if cfg.AppleMobileClientID != "" {
+			r.add(r.mobile, newAppleProvider(cfg.AppleMobileClientID, "", signer, client))
+		}
+	}
+
+	for _, oidc := range cfg.OIDCProviders {
+		if err := r.addOIDC(oidc, client); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Registry) addOIDC(cfg config.OIDCProviderConfig, client *http.Client) error {
+	if !providerNamePattern.MatchString(cfg.Name) {
+		return fmt.Errorf("invalid OIDC provider name %q: use lowercase letters, digits and dashes", cfg.Name)
+	}
+	if r.web[cfg.Name] != nil || r.mobile[cfg.Name] != nil {
+		return fmt.Errorf("OIDC provider %q clashes with a provider that is already configured", cfg.Name)
+	}
+	if cfg.Issuer == "" {
+		return fmt.Errorf("OIDC provider %q has no issuer", cfg.Name)
+	}
+	if cfg.ClientID == "" && cfg.MobileClientID == "" {
+		return fmt.Errorf("OIDC provider %q has no client ID", cfg.Name)
+	}
+
+	scopes := cfg.Scopes
+	if !containsString(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	base := OIDCConfig{
+		Name:        cfg.Name,
+		DisplayName: cfg.DisplayName,
+		Issuer:      cfg.Issuer,
+		Scopes:      scopes,
+	}
+	if base.DisplayName == "" {
+		base.DisplayName = cfg.Name
+	}
+
+	if cfg.ClientID != "" {
+		web := base
+		web.ClientID = cfg.ClientID
+		web.ClientSecret = cfg.ClientSecret
+		web.RedirectURI = cfg.RedirectURI
+		r.add(r.web, NewOIDCProvider(web, client))
+	}
+	if cfg.MobileClientID != "" {
+		mobile := base
+		mobile.ClientID = cfg.MobileClientID
+		r.add(r.mobile, NewOIDCProvider(mobile, client))
+	}
+	return nil
+}
+
+func (r *Registry) add(set map[string]Provider, p Provider) {
+	if r.web[p.Name()] == nil && r.mobile[p.Name()] == nil {
+		r.order = append(r.order, p.Name())
+	}
+	set[p.Name()] = p
+}
+
+// Web returns the provider used by the browser redirect flow.
+func (r *Registry) Web(name string) (Provider, bool) {
+	p, ok := r.web[name]
+	return p, ok
+}
+
+// Mobile returns the provider registered for native apps, falling back to
+// the web client when no mobile client is configured.
+func (r *Registry) Mobile(name string) (Provider, bool) {
+	if p, ok := r.mobile[name]; ok {
+		return p, true
+	}
+	return r.Web(name)
+}
+
+// Providers lists the enabled providers in configuration order.
+func (r *Registry) Providers() []types.OAuthProviderInfo {
+	infos := make([]types.OAuthProviderInfo, 0, len(r.order))
+	for _, name := range r.order {
+		p := r.web[name]
+		if p == nil {
+			p = r.mobile[name]
+		}
+		infos = append(infos, types.OAuthProviderInfo{
+			Name:        name,
+			DisplayName: p.DisplayName(),
+			Web:         r.web[name] != nil,
+			Mobile:      r.mobile[name] != nil || r.web[name] != nil,
+		})
+	}
+	return infos
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
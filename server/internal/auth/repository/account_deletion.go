@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// ScheduleAccountDeletion starts the grace period. Requesting again keeps the
+// original schedule.
+func (s *Store) ScheduleAccountDeletion(ctx context.Context, userID string, scheduledFor time.Time) (time.Time, error) {
+	query := `
+		UPDATE users
+		SET deletion_requested_at = COALESCE(deletion_requested_at, NOW()),
+			deletion_scheduled_for = COALESCE(deletion_scheduled_for, $2),
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING deletion_scheduled_for
+	`
+
+	var scheduled time.Time
+	if err := s.db.QueryRow(ctx, query, userID, scheduledFor).Scan(&scheduled); err != nil {
+		return time.Time{}, err
+	}
+	return scheduled, nil
+}
+
+// CancelAccountDeletion clears a pending deletion and reports whether one existed.
+func (s *Store) CancelAccountDeletion(ctx context.Context, userID string) (bool, error) {
+	query := `
+		UPDATE users
+		SET deletion_requested_at = NULL, deletion_scheduled_for = NULL, updated_at = NOW()
+		WHERE id = $1 AND deletion_scheduled_for IS NOT NULL AND deleted_at IS NULL
+	`
+
+	tag, err := s.db.Exec(ctx, query, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+func (s *Store) ListUsers(ctx context.Context, filter types.AdminUserFilter) ([]types.User, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		n := len(args)
+		conditions = append(conditions, fmt.Sprintf("(username ILIKE $%d OR email ILIKE $%d OR name ILIKE $%d)", n, n, n))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	// Deleted accounts are tombstones kept for conversation history only
+	conditions = append(conditions, "deleted_at IS NULL")
+
+	switch filter.Status {
+	case types.UserStatusActive:
+		conditions = append(conditions, "suspended_at IS NULL")
+	case types.UserStatusSuspended:
+		conditions = append(conditions, "suspended_at IS NOT NULL")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM users "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	query := fmt.Sprintf(`
+		SELECT id, username, name, bio, email, email_verified, image, role, is_two_factor_enabled, suspended_at, password_reset_required, deletion_scheduled_for, deleted_at, created_at, updated_at
+		FROM users
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []types.User{}
+	for rows.Next() {
+		var user types.User
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Name,
+			&user.Bio,
+			&user.Email,
+			&user.EmailVerified,
+			&user.Image,
+			&user.Role,
+			&user.IsTwoFactorEnabled,
+			&user.SuspendedAt,
+			&user.PasswordResetRequired,
+			&user.DeletionScheduledFor,
+			&user.DeletedAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+func (s *Store) SetUserSuspended(ctx context.Context, userID string, suspended bool, reason string) error {
+	query := `
+		UPDATE users
+		SET suspended_at = CASE WHEN $2 THEN NOW() ELSE NULL END,
+			suspended_reason = CASE WHEN $2 THEN NULLIF($3, '') ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	tag, err := s.db.Exec(ctx, query, userID, suspended, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *Store) SetPasswordResetRequired(ctx context.Context, userID string, required bool) error {
+	query := `
+		UPDATE users
+		SET password_reset_required = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	tag, err := s.db.Exec(ctx, query, userID, required)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *Store) CreateAdminAuditEntry(ctx context.Context, entry *types.AdminAuditEntry) error {
+	details := entry.Details
+	if len(details) == 0 {
+		details = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO admin_audit_log (admin_id, action, target_user_id, details, ip_address)
+		VALUES ($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''))
+		RETURNING audit_id, created_at
+	`
+
+	return s.db.QueryRow(ctx, query,
+		entry.AdminID,
+		entry.Action,
+		entry.TargetUserID,
+		details,
+		entry.IPAddress,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// ListAdminAuditEntries returns the newest entries first, optionally limited to one target user.
+func (s *Store) ListAdminAuditEntries(ctx context.Context, targetUserID string, limit, offset int) ([]types.AdminAuditEntry, error) {
+	query := `
+		SELECT audit_id, admin_id, action, COALESCE(target_user_id, ''), details, COALESCE(ip_address, ''), created_at
+		FROM admin_audit_log
+		WHERE ($1 = '' OR target_user_id = $1)
+		ORDER BY created_at DESC, audit_id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.Query(ctx, query, targetUserID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []types.AdminAuditEntry{}
+	for rows.Next() {
+		var entry types.AdminAuditEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.AdminID,
+			&entry.Action,
+			&entry.TargetUserID,
+			&entry.Details,
+			&entry.IPAddress,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
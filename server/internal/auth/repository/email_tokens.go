@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+// CreateEmailToken stores a one-time link, replacing any earlier link sent to
+// the same address for the same purpose.
+func (s *Store) CreateEmailToken(ctx context.Context, token *types.EmailToken) error {
+	query := `
+		INSERT INTO verification_tokens (email, token, expires_at, purpose, user_id, new_email, code_challenge)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''))
+		ON CONFLICT (email, purpose)
+		DO UPDATE SET token = EXCLUDED.token,
+			expires_at = EXCLUDED.expires_at,
+			user_id = EXCLUDED.user_id,
+			new_email = EXCLUDED.new_email,
+			code_challenge = EXCLUDED.code_challenge,
+			consumed_at = NULL,
+			updated_at = NOW()
+		RETURNING id
+	`
+
+	return s.db.QueryRow(ctx, query,
+		token.Email,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.Purpose,
+		token.UserID,
+		token.NewEmail,
+		token.CodeChallenge,
+	).Scan(&token.ID)
+}
+
+// GetEmailToken returns an unused, unexpired link for the purpose.
+func (s *Store) GetEmailToken(ctx context.Context, tokenHash string, purpose types.EmailTokenPurpose) (*types.EmailToken, error) {
+	query := `
+		SELECT id, purpose, COALESCE(user_id, ''), email, COALESCE(new_email, ''), token,
+			COALESCE(code_challenge, ''), expires_at
+		FROM verification_tokens
+		WHERE token = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	var token types.EmailToken
+	err := s.db.QueryRow(ctx, query, tokenHash, purpose).Scan(
+		&token.ID,
+		&token.Purpose,
+		&token.UserID,
+		&token.Email,
+		&token.NewEmail,
+		&token.TokenHash,
+		&token.CodeChallenge,
+		&token.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, types.ErrEmailLinkInvalid
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ConsumeEmailToken marks the link used. It reports false when another request
+// redeemed it first or it expired in the meantime.
+func (s *Store) ConsumeEmailToken(ctx context.Context, tokenID string) (bool, error) {
+	query := `
+		UPDATE verification_tokens
+		SET consumed_at = NOW()
+		WHERE id = $1 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	tag, err := s.db.Exec(ctx, query, tokenID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UpdateUserEmail moves the account to newEmail if it still uses oldEmail and
+// drops sign-in and change links issued for the old address. The new address counts as
+// verified because the change is confirmed from it. It reports false when the
+// account's email no longer matches oldEmail.
+func (s *Store) UpdateUserEmail(ctx context.Context, userID, oldEmail, newEmail string) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET email = $3, email_verified = NOW(), updated_at = NOW()
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+	`, userID, oldEmail, newEmail)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false, types.ErrUserAlreadyExists
+		}
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM verification_tokens
+		WHERE email = $1 AND purpose IN ('email_verification', 'magic_link', 'email_change')
+	`, oldEmail)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit email change: %w", err)
+	}
+	return true, nil
+}
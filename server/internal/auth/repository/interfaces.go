@@ -19,6 +19,11 @@ type UserStore interface {
 	GetVerificationToken(ctx context.Context, token string) (*types.VerificationToken, error)
 	DeleteVerificationToken(ctx context.Context, token string) error
 	MarkEmailVerified(ctx context.Context, userID string, verifiedAt time.Time) error
+	CreateEmailToken(ctx context.Context, token *types.EmailToken) error
+	GetEmailToken(ctx context.Context, tokenHash string, purpose types.EmailTokenPurpose) (*types.EmailToken, error)
+	ConsumeEmailToken(ctx context.Context, tokenID string) (bool, error)
+	UpdateUserEmail(ctx context.Context, userID, oldEmail, newEmail string) (bool, error)
+	SetPasswordResetRequired(ctx context.Context, userID string, required bool) error
 	GetUserStats(ctx context.Context, userID string) (*types.UserStats, error)
 	GetTodayWorkout(ctx context.Context, userID string) (*types.TodayWorkout, error)
 	SaveWorkoutCompletion(ctx context.Context, userID string, completion *types.WorkoutCompletionRequest) (*types.WorkoutCompletionResponse, error)
@@ -41,6 +46,9 @@ type UserStore interface {
 	GetUserByPasswordResetToken(ctx context.Context, token string) (*types.User, error)
 	DeletePasswordResetToken(ctx context.Context, token string) error
 	MarkPasswordResetTokenAsUsed(ctx context.Context, token string) error
+
+	ScheduleAccountDeletion(ctx context.Context, userID string, scheduledFor time.Time) (time.Time, error)
+	CancelAccountDeletion(ctx context.Context, userID string) (bool, error)
 }
 
 type PasswordResetStore interface {
@@ -58,24 +66,98 @@ type AuthService interface {
 	GetUserByUsername(ctx context.Context, username string) (*types.User, error)
 	Logout(ctx context.Context, userID string) error
 	UpdateUserRole(ctx context.Context, userID string, role types.UserRole) error
-	GenerateTokenPair(ctx context.Context, user *types.User) (*types.TokenPair, error)
-	RotateTokens(ctx context.Context, refreshToken string) (*types.TokenPair, error)
+	GenerateTokenPair(ctx context.Context, user *types.User, device types.DeviceInfo) (*types.TokenPair, error)
+	RotateTokens(ctx context.Context, refreshToken string, device types.DeviceInfo) (*types.TokenPair, error)
+	ListSessions(ctx context.Context, userID, currentJTI string) ([]types.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentJTI string) (int64, error)
 	InitiateEmailVerification(ctx context.Context, user *types.User) error
 	ResendEmailVerification(ctx context.Context, email string) error
 	VerifyEmail(ctx context.Context, token string) (*types.User, error)
+	RequestAccountDeletion(ctx context.Context, userID, password string) (time.Time, error)
+	CancelAccountDeletion(ctx context.Context, userID string) (bool, error)
+	RequestMagicLink(ctx context.Context, email, codeChallenge, codeChallengeMethod string) error
+	VerifyMagicLink(ctx context.Context, token, codeVerifier string) (*types.User, error)
+	RequestEmailChange(ctx context.Context, userID, newEmail, password string) error
+	ConfirmEmailChange(ctx context.Context, token string) (*types.User, error)
+	RevertEmailChange(ctx context.Context, token string) error
 }
 
 type RefreshTokenStore interface {
-	CreateRefreshToken(ctx context.Context, userID, token string, expiresAt time.Time, accessTokenJTI string) error
+	CreateRefreshToken(ctx context.Context, token *types.RefreshToken) error
 	GetRefreshToken(ctx context.Context, token string) (*types.RefreshToken, error)
 	DeleteRefreshToken(ctx context.Context, token string) error
 	CleanupExpiredRefreshTokens(ctx context.Context) error
 	RevokeRefreshToken(ctx context.Context, token string) error
 	RevokeAllUserRefreshTokens(ctx context.Context, userID string) error
 	UpdateRefreshTokenLastUsed(ctx context.Context, token string) error
+	RevokeActiveRefreshToken(ctx context.Context, token string) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	ListActiveSessions(ctx context.Context, userID string) ([]types.Session, error)
+	GetSessionIDByAccessTokenJTI(ctx context.Context, jti string) (string, error)
+	RevokeSession(ctx context.Context, userID, familyID string) (bool, error)
+	RevokeOtherSessions(ctx context.Context, userID, keepFamilyID string) (int64, error)
+}
+
+// AdminStore backs the admin user-management endpoints.
+type AdminStore interface {
+	ListUsers(ctx context.Context, filter types.AdminUserFilter) ([]types.User, int, error)
+	SetUserSuspended(ctx context.Context, userID string, suspended bool, reason string) error
+	SetPasswordResetRequired(ctx context.Context, userID string, required bool) error
+	CreateAdminAuditEntry(ctx context.Context, entry *types.AdminAuditEntry) error
+	ListAdminAuditEntries(ctx context.Context, targetUserID string, limit, offset int) ([]types.AdminAuditEntry, error)
+}
+
+// LoginSecurityStore backs account lockout and new-login alerts.
+type LoginSecurityStore interface {
+	GetUserByID(ctx context.Context, id string) (*types.User, error)
+	RecordFailedLogin(ctx context.Context, userID string) (*types.LoginLockout, error)
+	LockAccount(ctx context.Context, userID string, until time.Time) error
+	GetLoginLockout(ctx context.Context, userID string) (*types.LoginLockout, error)
+	ClearLoginLockout(ctx context.Context, userID string) error
+	GetLoginFamiliarity(ctx context.Context, userID, deviceFingerprint, countryCode string) (*types.LoginFamiliarity, error)
+	CreateLoginEvent(ctx context.Context, event *types.LoginEvent) error
+	CreateSecurityAlertToken(ctx context.Context, tokenHash, userID string, loginEventID int64, expiresAt time.Time) error
+	ConsumeSecurityAlertToken(ctx context.Context, tokenHash string) (string, error)
+	RevokeAllUserRefreshTokens(ctx context.Context, userID string) error
+	SetPasswordResetRequired(ctx context.Context, userID string, required bool) error
+}
+
+type LoginSecurityService interface {
+	CheckLockout(ctx context.Context, userID string) (time.Time, error)
+	RecordFailedLogin(ctx context.Context, userID string) (time.Time, error)
+	RecordSuccessfulLogin(ctx context.Context, user *types.User, device types.DeviceInfo)
+	ReportUnrecognizedLogin(ctx context.Context, token string) error
+}
+
+type TwoFactorStore interface {
+	UpsertTwoFactorSecret(ctx context.Context, userID, encryptedSecret string) error
+	GetTwoFactorSettings(ctx context.Context, userID string) (*types.TwoFactorSettings, error)
+	ConfirmTwoFactor(ctx context.Context, userID string, step int64) error
+	UpdateTwoFactorLastUsedStep(ctx context.Context, userID string, step int64) (bool, error)
+	DisableTwoFactor(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error)
+	CreateTwoFactorChallenge(ctx context.Context, challenge *types.TwoFactorChallenge) error
+	GetTwoFactorChallenge(ctx context.Context, tokenHash string) (*types.TwoFactorChallenge, error)
+	IncrementTwoFactorChallengeAttempts(ctx context.Context, challengeID int64) (int, error)
+	ConsumeTwoFactorChallenge(ctx context.Context, challengeID int64) (bool, error)
+	CleanupExpiredTwoFactorChallenges(ctx context.Context, olderThan time.Time) error
+}
+
+type TwoFactorService interface {
+	GetStatus(ctx context.Context, userID string) (*types.TwoFactorStatusResponse, error)
+	BeginSetup(ctx context.Context, user *types.User) (*types.TwoFactorSetupResponse, error)
+	ConfirmSetup(ctx context.Context, userID, code string) ([]string, error)
+	Disable(ctx context.Context, userID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	CreateChallenge(ctx context.Context, userID string, method types.TwoFactorMethod) (*types.TwoFactorChallengeResponse, error)
+	VerifyChallenge(ctx context.Context, challengeToken, code string) (string, error)
 }
 
 type OAuthService interface {
+	ListProviders() []types.OAuthProviderInfo
 	GetAuthorizationURL(ctx context.Context, provider, redirectURL string) (string, error)
 	HandleCallback(ctx context.Context, provider, code, state string) (*types.OAuthUserInfo, error)
 	HandleMobileCallback(ctx context.Context, provider, code, codeVerifier, redirectURI string) (*types.OAuthUserInfo, error)
@@ -86,8 +168,7 @@ type OAuthService interface {
 
 type OAuthStore interface {
 	CreateOAuthState(ctx context.Context, state *types.OAuthState) error
-	GetOAuthState(ctx context.Context, state string) (*types.OAuthState, error)
-	DeleteOAuthState(ctx context.Context, state string) error
+	ConsumeOAuthState(ctx context.Context, state string) (*types.OAuthState, error)
 	CleanupExpiredOAuthStates(ctx context.Context) error
 
 	CreateAccount(ctx context.Context, account *types.Account) error
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+// RecordFailedLogin counts a failed password attempt. Counters and lockout
+// levels that have been quiet for a day start over.
+func (s *Store) RecordFailedLogin(ctx context.Context, userID string) (*types.LoginLockout, error) {
+	query := `
+		INSERT INTO login_lockouts (user_id, failed_attempts, last_failed_at, updated_at)
+		VALUES ($1, 1, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			failed_attempts = CASE
+				WHEN login_lockouts.last_failed_at < NOW() - INTERVAL '24 hours' THEN 1
+				ELSE login_lockouts.failed_attempts + 1
+			END,
+			lockout_level = CASE
+				WHEN login_lockouts.last_failed_at < NOW() - INTERVAL '24 hours' THEN 0
+				ELSE login_lockouts.lockout_level
+			END,
+			last_failed_at = NOW(),
+			updated_at = NOW()
+		RETURNING user_id, failed_attempts, lockout_level, locked_until, last_failed_at
+	`
+
+	var lockout types.LoginLockout
+	err := s.db.QueryRow(ctx, query, userID).Scan(
+		&lockout.UserID,
+		&lockout.FailedAttempts,
+		&lockout.LockoutLevel,
+		&lockout.LockedUntil,
+		&lockout.LastFailedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+// LockAccount starts a lockout, raises the lockout level and resets the
+// failure counter for the next round.
+func (s *Store) LockAccount(ctx context.Context, userID string, until time.Time) error {
+	query := `
+		UPDATE login_lockouts
+		SET locked_until = $2,
+			lockout_level = lockout_level + 1,
+			failed_attempts = 0,
+			updated_at = NOW()
+		WHERE user_id = $1
+	`
+
+	_, err := s.db.Exec(ctx, query, userID, until)
+	return err
+}
+
+// GetLoginLockout returns the user's lockout state; users without failures get a zero value.
+func (s *Store) GetLoginLockout(ctx context.Context, userID string) (*types.LoginLockout, error) {
+	query := `
+		SELECT user_id, failed_attempts, lockout_level, locked_until, last_failed_at
+		FROM login_lockouts
+		WHERE user_id = $1
+	`
+
+	lockout := types.LoginLockout{UserID: userID}
+	err := s.db.QueryRow(ctx, query, userID).Scan(
+		&lockout.UserID,
+		&lockout.FailedAttempts,
+		&lockout.LockoutLevel,
+		&lockout.LockedUntil,
+		&lockout.LastFailedAt,
+	)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+	return &lockout, nil
+}
+
+func (s *Store) ClearLoginLockout(ctx context.Context, userID string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM login_lockouts WHERE user_id = $1`, userID)
+	return err
+}
+
+func (s *Store) GetLoginFamiliarity(ctx context.Context, userID, deviceFingerprint, countryCode string) (*types.LoginFamiliarity, error) {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM login_events WHERE user_id = $1),
+			EXISTS (SELECT 1 FROM login_events WHERE user_id = $1 AND device_fingerprint = $2),
+			EXISTS (SELECT 1 FROM login_events WHERE user_id = $1 AND country_code = NULLIF($3, ''))
+	`
+
+	var familiarity types.LoginFamiliarity
+	err := s.db.QueryRow(ctx, query, userID, deviceFingerprint, countryCode).Scan(
+		&familiarity.HasHistory,
+		&familiarity.KnownDevice,
+		&familiarity.KnownCountry,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &familiarity, nil
+}
+
+func (s *Store) CreateLoginEvent(ctx context.Context, event *types.LoginEvent) error {
+	query := `
+		INSERT INTO login_events (user_id, device_fingerprint, device_name, user_agent, ip_address, country_code, new_device, new_country)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, '')::inet, NULLIF($6, ''), $7, $8)
+		RETURNING event_id, created_at
+	`
+
+	return s.db.QueryRow(ctx, query,
+		event.UserID,
+		event.DeviceFingerprint,
+		event.DeviceName,
+		event.UserAgent,
+		event.IPAddress,
+		event.CountryCode,
+		event.NewDevice,
+		event.NewCountry,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+func (s *Store) CreateSecurityAlertToken(ctx context.Context, tokenHash, userID string, loginEventID int64, expiresAt time.Time) error {
+	query := `
+		INSERT INTO security_alert_tokens (token_hash, user_id, login_event_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := s.db.Exec(ctx, query, tokenHash, userID, loginEventID, expiresAt)
+	return err
+}
+
+// ConsumeSecurityAlertToken marks an unexpired token used and returns its user.
+func (s *Store) ConsumeSecurityAlertToken(ctx context.Context, tokenHash string) (string, error) {
+	query := `
+		UPDATE security_alert_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+
+	var userID string
+	err := s.db.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", types.ErrSecurityAlertTokenInvalid
+		}
+		return "", err
+	}
+	return userID, nil
+}
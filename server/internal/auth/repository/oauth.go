@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+func (s *Store) CreateOAuthState(ctx context.Context, state *types.OAuthState) error {
+	query := `
+		INSERT INTO oauth_states (state, provider, redirect_url, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6)
+		RETURNING id, created_at
+	`
+
+	return s.db.QueryRow(ctx, query,
+		state.State,
+		state.Provider,
+		state.RedirectURL,
+		state.Nonce,
+		state.CodeVerifier,
+		state.ExpiresAt,
+	).Scan(&state.ID, &state.CreatedAt)
+}
+
+// ConsumeOAuthState deletes and returns an unexpired state, so each
+// authorization callback can be completed only once.
+func (s *Store) ConsumeOAuthState(ctx context.Context, state string) (*types.OAuthState, error) {
+	query := `
+		DELETE FROM oauth_states
+		WHERE state = $1 AND expires_at > NOW()
+		RETURNING id, state, provider, COALESCE(redirect_url, ''), COALESCE(nonce, ''),
+			COALESCE(code_verifier, ''), expires_at, created_at
+	`
+
+	var oauthState types.OAuthState
+	err := s.db.QueryRow(ctx, query, state).Scan(
+		&oauthState.ID,
+		&oauthState.State,
+		&oauthState.Provider,
+		&oauthState.RedirectURL,
+		&oauthState.Nonce,
+		&oauthState.CodeVerifier,
+		&oauthState.ExpiresAt,
+		&oauthState.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, types.ErrInvalidOAuthState
+		}
+		return nil, err
+	}
+
+	return &oauthState, nil
+}
+
+func (s *Store) CleanupExpiredOAuthStates(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM oauth_states WHERE expires_at < NOW()`)
+	return err
+}
+
+// CreateAccount links a provider identity to a user. Linking is idempotent;
+// an identity already linked to a different user, or a second identity from
+// the same provider, is rejected.
+func (s *Store) CreateAccount(ctx context.Context, account *types.Account) error {
+	query := `
+		INSERT INTO accounts (user_id, type, provider, provider_account_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, provider_account_id) DO UPDATE SET updated_at = NOW()
+			WHERE accounts.user_id = EXCLUDED.user_id
+		RETURNING id
+	`
+
+	err := s.db.QueryRow(ctx, query,
+		account.UserID,
+		account.Type,
+		account.Provider,
+		account.ProviderAccountID,
+	).Scan(&account.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if err == pgx.ErrNoRows || (errors.As(err, &pgErr) && pgErr.Code == "23505") {
+			return types.ErrAccountAlreadyLinked
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) GetAccountByProvider(ctx context.Context, provider, providerAccountID string) (*types.Account, error) {
+	query := `
+		SELECT id, user_id, type, provider, provider_account_id
+		FROM accounts
+		WHERE provider = $1 AND provider_account_id = $2
+	`
+
+	var account types.Account
+	err := s.db.QueryRow(ctx, query, provider, providerAccountID).Scan(
+		&account.ID,
+		&account.UserID,
+		&account.Type,
+		&account.Provider,
+		&account.ProviderAccountID,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, types.ErrAccountNotLinked
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (s *Store) GetAccountsByUserID(ctx context.Context, userID string) ([]*types.Account, error) {
+	query := `
+		SELECT id, user_id, type, provider, provider_account_id
+		FROM accounts
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []*types.Account{}
+	for rows.Next() {
+		var account types.Account
+		if err := rows.Scan(
+			&account.ID,
+			&account.UserID,
+			&account.Type,
+			&account.Provider,
+			&account.ProviderAccountID,
+		); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, &account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (s *Store) DeleteAccount(ctx context.Context, userID, provider string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM accounts WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrAccountNotLinked
+	}
+	return nil
+}
+
+func (s *Store) UpdateAccountTokens(ctx context.Context, accountID, accessToken, refreshToken string, expiresAt int) error {
+	query := `
+		UPDATE accounts
+		SET access_token = NULLIF($2, ''), refresh_token = NULLIF($3, ''), expires_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := s.db.Exec(ctx, query, accountID, accessToken, refreshToken, expiresAt)
+	return err
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
 type Store struct {
@@ -22,7 +23,7 @@ func NewStore(db *pgxpool.Pool) *Store {
 
 func (s *Store) GetUserByEmail(ctx context.Context, email string) (*types.User, error) {
 	query := `
-		SELECT id, username, name, bio, email, email_verified, image, password, role, is_two_factor_enabled, created_at, updated_at
+		SELECT id, username, name, bio, email, email_verified, image, password, role, is_two_factor_enabled, suspended_at, password_reset_required, deletion_scheduled_for, deleted_at, created_at, updated_at
 		FROM users 
 		WHERE email = $1
 	`
@@ -39,6 +40,10 @@ func (s *Store) GetUserByEmail(ctx context.Context, email string) (*types.User,
 		&user.PasswordHash,
 		&user.Role,
 		&user.IsTwoFactorEnabled,
+		&user.SuspendedAt,
+		&user.PasswordResetRequired,
+		&user.DeletionScheduledFor,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -55,7 +60,7 @@ func (s *Store) GetUserByEmail(ctx context.Context, email string) (*types.User,
 
 func (s *Store) GetUserByID(ctx context.Context, id string) (*types.User, error) {
 	query := `
-		SELECT id, username, name, bio, email, email_verified, image, password, role, is_two_factor_enabled, created_at, updated_at
+		SELECT id, username, name, bio, email, email_verified, image, password, role, is_two_factor_enabled, suspended_at, password_reset_required, deletion_scheduled_for, deleted_at, created_at, updated_at
 		FROM users 
 		WHERE id = $1
 	`
@@ -72,6 +77,10 @@ func (s *Store) GetUserByID(ctx context.Context, id string) (*types.User, error)
 		&user.PasswordHash,
 		&user.Role,
 		&user.IsTwoFactorEnabled,
+		&user.SuspendedAt,
+		&user.PasswordResetRequired,
+		&user.DeletionScheduledFor,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -88,7 +97,7 @@ func (s *Store) GetUserByID(ctx context.Context, id string) (*types.User, error)
 
 func (s *Store) GetUserByUsername(ctx context.Context, username string) (*types.User, error) {
 	query := `
-		SELECT id, username, name, bio, email, email_verified, image, password, role, is_two_factor_enabled, created_at, updated_at
+		SELECT id, username, name, bio, email, email_verified, image, password, role, is_two_factor_enabled, suspended_at, password_reset_required, deletion_scheduled_for, deleted_at, created_at, updated_at
 		FROM users 
 		WHERE username = $1
 	`
@@ -105,6 +114,10 @@ func (s *Store) GetUserByUsername(ctx context.Context, username string) (*types.
 		&user.PasswordHash,
 		&user.Role,
 		&user.IsTwoFactorEnabled,
+		&user.SuspendedAt,
+		&user.PasswordResetRequired,
+		&user.DeletionScheduledFor,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -147,6 +160,7 @@ func (s *Store) UpdateUser(ctx context.Context, id string, updates *types.Update
 		SET name = COALESCE($2, name), 
 		    bio = COALESCE($3, bio), 
 		    image = COALESCE($4, image),
+		    timezone = COALESCE($5, timezone),
 		    updated_at = NOW()
 		WHERE id = $1
 	`
@@ -158,7 +172,7 @@ func (s *Store) UpdateUser(ctx context.Context, id string, updates *types.Update
 		updates.Image != nil,
 	)
 
-	result, err := s.db.Exec(ctx, query, id, updates.Name, updates.Bio, updates.Image)
+	result, err := s.db.Exec(ctx, query, id, updates.Name, updates.Bio, updates.Image, updates.Timezone)
 	if err != nil {
 		log.Printf("❌ Database error: %v", err)
 		return err
@@ -177,7 +191,7 @@ func (s *Store) UpdateUser(ctx context.Context, id string, updates *types.Update
 func (s *Store) UpdateUserPassword(ctx context.Context, userID string, hashedPassword string) error {
 	query := `
 		UPDATE users 
-		SET password = $2, updated_at = NOW()
+		SET password = $2, password_reset_required = FALSE, updated_at = NOW()
 		WHERE id = $1
 	`
 
@@ -192,7 +206,7 @@ func (s *Store) UpdateUserRole(ctx context.Context, userID string, role types.Us
 		WHERE id = $1
 	`
 
-	_, err := s.db.Exec(ctx, query, userID, role)
+	_, err := database.Conn(ctx, s.db).Exec(ctx, query, userID, role)
 	return err
 }
 
@@ -234,7 +248,7 @@ func (s *Store) GetPasswordResetToken(ctx context.Context, token string) (*types
 
 func (s *Store) GetUserByPasswordResetToken(ctx context.Context, token string) (*types.User, error) {
 	query := `
-		SELECT u.id, u.username, u.name, u.bio, u.email, u.email_verified, u.image, u.password, u.role, u.is_two_factor_enabled, u.created_at, u.updated_at
+		SELECT u.id, u.username, u.name, u.bio, u.email, u.email_verified, u.image, u.password, u.role, u.is_two_factor_enabled, u.suspended_at, u.password_reset_required, u.deletion_scheduled_for, u.deleted_at, u.created_at, u.updated_at
 		FROM users u
 		INNER JOIN password_reset_tokens prt ON u.email = prt.email
 		WHERE prt.token = $1 AND prt.expires_at > NOW() AND prt.used = false
@@ -252,6 +266,10 @@ func (s *Store) GetUserByPasswordResetToken(ctx context.Context, token string) (
 		&user.PasswordHash,
 		&user.Role,
 		&user.IsTwoFactorEnabled,
+		&user.SuspendedAt,
+		&user.PasswordResetRequired,
+		&user.DeletionScheduledFor,
+		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -276,7 +294,7 @@ func (s *Store) CreateVerificationToken(ctx context.Context, email, token string
 	query := `
 		INSERT INTO verification_tokens (email, token, expires_at)
 		VALUES ($1, $2, $3)
-		ON CONFLICT (email)
+		ON CONFLICT (email, purpose)
 		DO UPDATE SET token = EXCLUDED.token, expires_at = EXCLUDED.expires_at, updated_at = NOW(), consumed_at = NULL
 	`
 
@@ -288,7 +306,7 @@ func (s *Store) GetVerificationToken(ctx context.Context, token string) (*types.
 	query := `
 		SELECT id, email, token, expires_at
 		FROM verification_tokens
-		WHERE token = $1 AND consumed_at IS NULL
+		WHERE token = $1 AND purpose = 'email_verification' AND consumed_at IS NULL
 	`
 
 	var verificationToken types.VerificationToken
@@ -345,19 +363,29 @@ func (s *Store) MarkPasswordResetTokenAsUsed(ctx context.Context, token string)
 	return err
 }
 
-func (s *Store) CreateRefreshToken(ctx context.Context, userID, token string, expiresAt time.Time, accessTokenJTI string) error {
+func (s *Store) CreateRefreshToken(ctx context.Context, token *types.RefreshToken) error {
 	query := `
-		INSERT INTO jwt_refresh_tokens (user_id, token_hash, access_token_jti, expires_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO jwt_refresh_tokens (user_id, token_hash, access_token_jti, expires_at, family_id, device_name, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, '')::inet)
 	`
 
-	_, err := s.db.Exec(ctx, query, userID, token, accessTokenJTI, expiresAt)
+	_, err := s.db.Exec(ctx, query,
+		token.UserID,
+		token.TokenHash,
+		token.AccessTokenJTI,
+		token.ExpiresAt,
+		token.FamilyID,
+		token.DeviceName,
+		token.UserAgent,
+		token.IPAddress,
+	)
 	return err
 }
 
 func (s *Store) GetRefreshToken(ctx context.Context, token string) (*types.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, token_hash, access_token_jti, expires_at, created_at, last_used_at, is_revoked, revoked_at
+		SELECT id, user_id, token_hash, access_token_jti, expires_at, created_at, last_used_at, is_revoked, revoked_at,
+			COALESCE(user_agent, ''), COALESCE(host(ip_address), ''), family_id, COALESCE(device_name, '')
 		FROM jwt_refresh_tokens 
 		WHERE token_hash = $1
 	`
@@ -373,6 +401,10 @@ func (s *Store) GetRefreshToken(ctx context.Context, token string) (*types.Refre
 		&refreshToken.LastUsedAt,
 		&refreshToken.IsRevoked,
 		&refreshToken.RevokedAt,
+		&refreshToken.UserAgent,
+		&refreshToken.IPAddress,
+		&refreshToken.FamilyID,
+		&refreshToken.DeviceName,
 	)
 
 	if err != nil {
@@ -430,6 +462,117 @@ func (s *Store) UpdateRefreshTokenLastUsed(ctx context.Context, token string) er
 	return err
 }
 
+// RevokeActiveRefreshToken revokes a token that is still active. It reports false
+// when the token was already revoked, which during rotation means it was reused.
+func (s *Store) RevokeActiveRefreshToken(ctx context.Context, token string) (bool, error) {
+	query := `
+		UPDATE jwt_refresh_tokens 
+		SET is_revoked = true, revoked_at = NOW() 
+		WHERE token_hash = $1 AND is_revoked = false
+	`
+
+	tag, err := s.db.Exec(ctx, query, token)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *Store) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE jwt_refresh_tokens 
+		SET is_revoked = true, revoked_at = NOW() 
+		WHERE family_id = $1 AND is_revoked = false
+	`
+
+	_, err := s.db.Exec(ctx, query, familyID)
+	return err
+}
+
+// ListActiveSessions returns one row per token family that still has a usable
+// refresh token, most recently used first.
+func (s *Store) ListActiveSessions(ctx context.Context, userID string) ([]types.Session, error) {
+	query := `
+		SELECT t.family_id, COALESCE(t.device_name, ''), COALESCE(t.user_agent, ''), COALESCE(host(t.ip_address), ''),
+			(SELECT MIN(f.created_at) FROM jwt_refresh_tokens f WHERE f.family_id = t.family_id),
+			COALESCE(t.last_used_at, t.created_at), t.expires_at
+		FROM jwt_refresh_tokens t
+		WHERE t.user_id = $1 AND t.is_revoked = false AND t.expires_at > NOW()
+		ORDER BY COALESCE(t.last_used_at, t.created_at) DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []types.Session{}
+	for rows.Next() {
+		var session types.Session
+		if err := rows.Scan(
+			&session.ID,
+			&session.DeviceName,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetSessionIDByAccessTokenJTI finds the token family an access token was issued with.
+func (s *Store) GetSessionIDByAccessTokenJTI(ctx context.Context, jti string) (string, error) {
+	var familyID string
+	err := s.db.QueryRow(ctx, `
+		SELECT family_id FROM jwt_refresh_tokens WHERE access_token_jti = $1
+	`, jti).Scan(&familyID)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", types.ErrSessionNotFound
+		}
+		return "", err
+	}
+	return familyID, nil
+}
+
+// RevokeSession revokes a user's token family and reports whether it had active tokens.
+func (s *Store) RevokeSession(ctx context.Context, userID, familyID string) (bool, error) {
+	query := `
+		UPDATE jwt_refresh_tokens 
+		SET is_revoked = true, revoked_at = NOW() 
+		WHERE user_id = $1 AND family_id = $2 AND is_revoked = false
+	`
+
+	tag, err := s.db.Exec(ctx, query, userID, familyID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeOtherSessions revokes every token family of the user except keepFamilyID.
+func (s *Store) RevokeOtherSessions(ctx context.Context, userID, keepFamilyID string) (int64, error) {
+	query := `
+		UPDATE jwt_refresh_tokens 
+		SET is_revoked = true, revoked_at = NOW() 
+		WHERE user_id = $1 AND family_id <> $2 AND is_revoked = false
+	`
+
+	tag, err := s.db.Exec(ctx, query, userID, keepFamilyID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 func (s *Store) GetUserStats(ctx context.Context, userID string) (*types.UserStats, error) {
 	stats := &types.UserStats{
 		UserID: userID,
@@ -2,12 +2,23 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/tdmdh/fit-up-server/internal/auth/types"
 )
 
+// Templates live in the shared template library (workout_templates and
+// workout_template_exercises). These methods keep the original name-based
+// format: all days are flattened into one exercise list, and "public" is the
+// only visibility besides private that they expose.
+
+const legacyTemplateColumns = `
+	template_id, user_id, name, description, visibility = 'public', created_at, updated_at
+`
+
 func (s *Store) GetUserTemplates(ctx context.Context, userID string, page, pageSize int) (*types.TemplateListResponse, error) {
 	offset := (page - 1) * pageSize
 
@@ -19,57 +30,23 @@ func (s *Store) GetUserTemplates(ctx context.Context, userID string, page, pageS
 	}
 
 	query := `
-		SELECT template_id, user_id, name, description, is_public, exercises, created_at, updated_at
+		SELECT ` + legacyTemplateColumns + `
 		FROM workout_templates
 		WHERE user_id = $1
 		ORDER BY updated_at DESC
 		LIMIT $2 OFFSET $3
 	`
-
-	rows, err := s.db.Query(ctx, query, userID, pageSize, offset)
+	templates, err := s.queryLegacyTemplates(ctx, query, userID, pageSize, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query templates: %w", err)
-	}
-	defer rows.Close()
-
-	var templates []types.WorkoutTemplate
-	for rows.Next() {
-		var template types.WorkoutTemplate
-		var exercisesJSON []byte
-
-		err := rows.Scan(
-			&template.TemplateID,
-			&template.UserID,
-			&template.Name,
-			&template.Description,
-			&template.IsPublic,
-			&exercisesJSON,
-			&template.CreatedAt,
-			&template.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan template: %w", err)
-		}
-
-		if err := json.Unmarshal(exercisesJSON, &template.Exercises); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal exercises: %w", err)
-		}
-
-		templates = append(templates, template)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating templates: %w", err)
+		return nil, err
 	}
 
-	hasMore := offset+len(templates) < totalCount
-
 	return &types.TemplateListResponse{
 		Templates:  templates,
 		TotalCount: totalCount,
 		Page:       page,
 		PageSize:   pageSize,
-		HasMore:    hasMore,
+		HasMore:    offset+len(templates) < totalCount,
 	}, nil
 }
 
@@ -78,144 +55,85 @@ func (s *Store) GetPublicTemplates(ctx context.Context, page, pageSize int) (*ty
 
 	// Get total count
 	var totalCount int
-	countQuery := `SELECT COUNT(*) FROM workout_templates WHERE is_public = TRUE`
+	countQuery := `SELECT COUNT(*) FROM workout_templates WHERE visibility = 'public'`
 	err := s.db.QueryRow(ctx, countQuery).Scan(&totalCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count public templates: %w", err)
 	}
 
 	query := `
-		SELECT template_id, user_id, name, description, is_public, exercises, created_at, updated_at
+		SELECT ` + legacyTemplateColumns + `
 		FROM workout_templates
-		WHERE is_public = TRUE
+		WHERE visibility = 'public'
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
-
-	rows, err := s.db.Query(ctx, query, pageSize, offset)
+	templates, err := s.queryLegacyTemplates(ctx, query, pageSize, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query public templates: %w", err)
-	}
-	defer rows.Close()
-
-	var templates []types.WorkoutTemplate
-	for rows.Next() {
-		var template types.WorkoutTemplate
-		var exercisesJSON []byte
-
-		err := rows.Scan(
-			&template.TemplateID,
-			&template.UserID,
-			&template.Name,
-			&template.Description,
-			&template.IsPublic,
-			&exercisesJSON,
-			&template.CreatedAt,
-			&template.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan template: %w", err)
-		}
-
-		if err := json.Unmarshal(exercisesJSON, &template.Exercises); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal exercises: %w", err)
-		}
-
-		templates = append(templates, template)
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating templates: %w", err)
-	}
-
-	hasMore := offset+len(templates) < totalCount
-
 	return &types.TemplateListResponse{
 		Templates:  templates,
 		TotalCount: totalCount,
 		Page:       page,
 		PageSize:   pageSize,
-		HasMore:    hasMore,
+		HasMore:    offset+len(templates) < totalCount,
 	}, nil
 }
 
 // GetTemplateByID retrieves a template by ID
 func (s *Store) GetTemplateByID(ctx context.Context, templateID int) (*types.WorkoutTemplate, error) {
-	query := `
-		SELECT template_id, user_id, name, description, is_public, exercises, created_at, updated_at
-		FROM workout_templates
-		WHERE template_id = $1
-	`
+	query := `SELECT ` + legacyTemplateColumns + ` FROM workout_templates WHERE template_id = $1`
 
-	var template types.WorkoutTemplate
-	var exercisesJSON []byte
-
-	err := s.db.QueryRow(ctx, query, templateID).Scan(
-		&template.TemplateID,
-		&template.UserID,
-		&template.Name,
-		&template.Description,
-		&template.IsPublic,
-		&exercisesJSON,
-		&template.CreatedAt,
-		&template.UpdatedAt,
-	)
+	templates, err := s.queryLegacyTemplates(ctx, query, templateID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get template: %w", err)
 	}
-
-	if err := json.Unmarshal(exercisesJSON, &template.Exercises); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal exercises: %w", err)
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("failed to get template: template %d does not exist", templateID)
 	}
 
-	return &template, nil
+	return &templates[0], nil
 }
 
-// CreateTemplate creates a new workout template
+// CreateTemplate creates a new workout template. Exercise names are matched
+// against the exercise library so the template can be used to build schemas.
 func (s *Store) CreateTemplate(ctx context.Context, userID string, req *types.CreateTemplateRequest) (*types.WorkoutTemplate, error) {
-	// Marshal exercises to JSON
-	exercisesJSON, err := json.Marshal(req.Exercises)
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal exercises: %w", err)
+		return nil, fmt.Errorf("failed to create template: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	query := `
-		INSERT INTO workout_templates (user_id, name, description, is_public, exercises)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING template_id, user_id, name, description, is_public, exercises, created_at, updated_at
-	`
+	visibility := "private"
+	if req.IsPublic {
+		visibility = "public"
+	}
 
-	var template types.WorkoutTemplate
-	var returnedExercisesJSON []byte
-
-	err = s.db.QueryRow(ctx, query,
-		userID,
-		req.Name,
-		req.Description,
-		req.IsPublic,
-		exercisesJSON,
-	).Scan(
-		&template.TemplateID,
-		&template.UserID,
-		&template.Name,
-		&template.Description,
-		&template.IsPublic,
-		&returnedExercisesJSON,
-		&template.CreatedAt,
-		&template.UpdatedAt,
-	)
+	var templateID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO workout_templates (user_id, name, description, visibility, source)
+		VALUES ($1, $2, $3, $4, 'custom')
+		RETURNING template_id
+	`, userID, req.Name, req.Description, visibility).Scan(&templateID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create template: %w", err)
 	}
 
-	if err := json.Unmarshal(returnedExercisesJSON, &template.Exercises); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal exercises: %w", err)
+	if err := insertLegacyTemplateExercises(ctx, tx, templateID, req.Exercises); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
 	}
 
-	return &template, nil
+	return s.GetTemplateByID(ctx, templateID)
 }
 
-// UpdateTemplate updates an existing template
+// UpdateTemplate updates an existing template. Turning is_public off makes a
+// public template private and leaves other visibilities alone; new exercises
+// replace all of the template's days.
 func (s *Store) UpdateTemplate(ctx context.Context, templateID int, userID string, req *types.UpdateTemplateRequest) (*types.WorkoutTemplate, error) {
 	// First, check if template exists and belongs to user
 	existingTemplate, err := s.GetTemplateByID(ctx, templateID)
@@ -227,85 +145,46 @@ func (s *Store) UpdateTemplate(ctx context.Context, templateID int, userID strin
 		return nil, fmt.Errorf("unauthorized: template does not belong to user")
 	}
 
-	// Build dynamic update query
-	updateFields := []string{}
-	args := []interface{}{}
-	argCounter := 1
-
-	if req.Name != nil {
-		updateFields = append(updateFields, fmt.Sprintf("name = $%d", argCounter))
-		args = append(args, *req.Name)
-		argCounter++
+	if req.Name == nil && req.Description == nil && req.IsPublic == nil && req.Exercises == nil {
+		return existingTemplate, nil // No updates to perform
 	}
 
-	if req.Description != nil {
-		updateFields = append(updateFields, fmt.Sprintf("description = $%d", argCounter))
-		args = append(args, *req.Description)
-		argCounter++
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	if req.IsPublic != nil {
-		updateFields = append(updateFields, fmt.Sprintf("is_public = $%d", argCounter))
-		args = append(args, *req.IsPublic)
-		argCounter++
+	_, err = tx.Exec(ctx, `
+		UPDATE workout_templates
+		SET name = COALESCE($2, name),
+			description = COALESCE($3, description),
+			visibility = CASE
+				WHEN $4::boolean IS NULL THEN visibility
+				WHEN $4::boolean THEN 'public'
+				WHEN visibility = 'public' THEN 'private'
+				ELSE visibility
+			END,
+			updated_at = NOW()
+		WHERE template_id = $1
+	`, templateID, req.Name, req.Description, req.IsPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
 	}
 
 	if req.Exercises != nil {
-		exercisesJSON, err := json.Marshal(req.Exercises)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal exercises: %w", err)
+		if _, err := tx.Exec(ctx, `DELETE FROM workout_template_exercises WHERE template_id = $1`, templateID); err != nil {
+			return nil, fmt.Errorf("failed to update template: %w", err)
 		}
-		updateFields = append(updateFields, fmt.Sprintf("exercises = $%d", argCounter))
-		args = append(args, exercisesJSON)
-		argCounter++
-	}
-
-	if len(updateFields) == 0 {
-		return existingTemplate, nil // No updates to perform
-	}
-
-	// Add template_id to args
-	args = append(args, templateID)
-
-	// Build SET clause
-	setClause := ""
-	for i, field := range updateFields {
-		if i > 0 {
-			setClause += ", "
+		if err := insertLegacyTemplateExercises(ctx, tx, templateID, req.Exercises); err != nil {
+			return nil, err
 		}
-		setClause += field
 	}
-
-	query := fmt.Sprintf(`
-		UPDATE workout_templates
-		SET %s
-		WHERE template_id = $%d
-		RETURNING template_id, user_id, name, description, is_public, exercises, created_at, updated_at
-	`, setClause, argCounter)
-
-	var template types.WorkoutTemplate
-	var exercisesJSON []byte
-
-	err = s.db.QueryRow(ctx, query, args...).Scan(
-		&template.TemplateID,
-		&template.UserID,
-		&template.Name,
-		&template.Description,
-		&template.IsPublic,
-		&exercisesJSON,
-		&template.CreatedAt,
-		&template.UpdatedAt,
-	)
-	if err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to update template: %w", err)
 	}
 
-	// Parse exercises JSON
-	if err := json.Unmarshal(exercisesJSON, &template.Exercises); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal exercises: %w", err)
-	}
-
-	return &template, nil
+	return s.GetTemplateByID(ctx, templateID)
 }
 
 // DeleteTemplate deletes a template
@@ -328,3 +207,114 @@ func (s *Store) DeleteTemplate(ctx context.Context, templateID int, userID strin
 
 	return nil
 }
+
+func insertLegacyTemplateExercises(ctx context.Context, tx pgx.Tx, templateID int, exercises []types.TemplateExercise) error {
+	for i, exercise := range exercises {
+		name := strings.TrimSpace(exercise.ExerciseName)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO workout_template_exercises
+				(template_id, day_of_week, position, exercise_id, exercise_name, sets, reps, target_weight, rest_seconds)
+			VALUES ($1, 1, $2,
+				(SELECT exercise_id FROM exercises WHERE LOWER(name) = LOWER($3) ORDER BY exercise_id LIMIT 1),
+				$3, $4, $5, $6, $7)
+		`, templateID, i+1, name, max(exercise.Sets, 1), strconv.Itoa(max(exercise.TargetReps, 0)),
+			max(exercise.TargetWeight, 0), max(exercise.RestSeconds, 0))
+		if err != nil {
+			return fmt.Errorf("failed to save template exercises: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) queryLegacyTemplates(ctx context.Context, query string, args ...interface{}) ([]types.WorkoutTemplate, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []types.WorkoutTemplate{}
+	for rows.Next() {
+		var template types.WorkoutTemplate
+		err := rows.Scan(
+			&template.TemplateID,
+			&template.UserID,
+			&template.Name,
+			&template.Description,
+			&template.IsPublic,
+			&template.CreatedAt,
+			&template.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		template.Exercises = []types.TemplateExercise{}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating templates: %w", err)
+	}
+
+	if err := s.loadLegacyTemplateExercises(ctx, templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// loadLegacyTemplateExercises flattens each template's days into one list,
+// in day and position order.
+func (s *Store) loadLegacyTemplateExercises(ctx context.Context, templates []types.WorkoutTemplate) error {
+	if len(templates) == 0 {
+		return nil
+	}
+
+	index := make(map[int]*types.WorkoutTemplate, len(templates))
+	ids := make([]int32, 0, len(templates))
+	for i := range templates {
+		index[templates[i].TemplateID] = &templates[i]
+		ids = append(ids, int32(templates[i].TemplateID))
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT te.template_id, COALESCE(e.name, te.exercise_name), te.sets, te.reps,
+			te.target_weight::float8, te.rest_seconds
+		FROM workout_template_exercises te
+		LEFT JOIN exercises e ON e.exercise_id = te.exercise_id
+		WHERE te.template_id = ANY($1::int[])
+		ORDER BY te.template_id, te.day_of_week, te.position
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query template exercises: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			templateID int
+			reps       string
+			exercise   types.TemplateExercise
+		)
+		if err := rows.Scan(&templateID, &exercise.ExerciseName, &exercise.Sets, &reps, &exercise.TargetWeight, &exercise.RestSeconds); err != nil {
+			return fmt.Errorf("failed to scan template exercise: %w", err)
+		}
+		exercise.TargetReps = legacyTargetReps(reps)
+		template := index[templateID]
+		template.Exercises = append(template.Exercises, exercise)
+	}
+	return rows.Err()
+}
+
+// legacyTargetReps reads the leading number of a rep scheme, so "8-12" is
+// shown as 8 and "AMRAP" as 0.
+func legacyTargetReps(reps string) int {
+	reps = strings.TrimSpace(reps)
+	end := 0
+	for end < len(reps) && reps[end] >= '0' && reps[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(reps[:end])
+	if err != nil {
+		return 0
+	}
+	return n
+}
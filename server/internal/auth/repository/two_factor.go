@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+func (s *Store) UpsertTwoFactorSecret(ctx context.Context, userID, encryptedSecret string) error {
+	query := `
+		INSERT INTO user_two_factor (user_id, encrypted_secret, confirmed_at, last_used_step)
+		VALUES ($1, $2, NULL, 0)
+		ON CONFLICT (user_id) DO UPDATE
+		SET encrypted_secret = EXCLUDED.encrypted_secret,
+			confirmed_at = NULL,
+			last_used_step = 0,
+			updated_at = NOW()
+	`
+
+	_, err := s.db.Exec(ctx, query, userID, encryptedSecret)
+	return err
+}
+
+func (s *Store) GetTwoFactorSettings(ctx context.Context, userID string) (*types.TwoFactorSettings, error) {
+	query := `
+		SELECT user_id, encrypted_secret, confirmed_at, last_used_step, created_at, updated_at
+		FROM user_two_factor
+		WHERE user_id = $1
+	`
+
+	var settings types.TwoFactorSettings
+	err := s.db.QueryRow(ctx, query, userID).Scan(
+		&settings.UserID,
+		&settings.EncryptedSecret,
+		&settings.ConfirmedAt,
+		&settings.LastUsedStep,
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, types.ErrTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// ConfirmTwoFactor marks the enrolment as confirmed and flips the user's flag so
+// the login flow starts asking for a second factor.
+func (s *Store) ConfirmTwoFactor(ctx context.Context, userID string, step int64) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_two_factor
+		SET confirmed_at = NOW(), last_used_step = $2, updated_at = NOW()
+		WHERE user_id = $1
+	`, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to confirm two-factor: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE users SET is_two_factor_enabled = TRUE, updated_at = NOW() WHERE id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor flag: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateTwoFactorLastUsedStep records a used time step. It reports false when the
+// step is not newer than the last accepted one, which means the code was replayed.
+func (s *Store) UpdateTwoFactorLastUsedStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `
+		UPDATE user_two_factor
+		SET last_used_step = $2, updated_at = NOW()
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+
+	tag, err := s.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *Store) DisableTwoFactor(ctx context.Context, userID string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_two_factor WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete two-factor secret: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM two_factor_challenges WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete challenges: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET is_two_factor_enabled = FALSE, updated_at = NOW() WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor flag: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReplaceRecoveryCodes discards every existing code so only the new set is valid.
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO two_factor_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)
+		`, userID, hash)
+		if err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseRecoveryCode burns a matching unused code and reports whether one existed.
+func (s *Store) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := `
+		UPDATE two_factor_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	tag, err := s.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *Store) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM two_factor_recovery_codes
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID).Scan(&count)
+	return count, err
+}
+
+func (s *Store) CreateTwoFactorChallenge(ctx context.Context, challenge *types.TwoFactorChallenge) error {
+	query := `
+		INSERT INTO two_factor_challenges (user_id, token_hash, method, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING challenge_id, attempts, created_at
+	`
+
+	return s.db.QueryRow(ctx, query,
+		challenge.UserID,
+		challenge.TokenHash,
+		challenge.Method,
+		challenge.ExpiresAt,
+	).Scan(&challenge.ID, &challenge.Attempts, &challenge.CreatedAt)
+}
+
+func (s *Store) GetTwoFactorChallenge(ctx context.Context, tokenHash string) (*types.TwoFactorChallenge, error) {
+	query := `
+		SELECT challenge_id, user_id, token_hash, method, attempts, expires_at, consumed_at, created_at
+		FROM two_factor_challenges
+		WHERE token_hash = $1
+	`
+
+	var challenge types.TwoFactorChallenge
+	err := s.db.QueryRow(ctx, query, tokenHash).Scan(
+		&challenge.ID,
+		&challenge.UserID,
+		&challenge.TokenHash,
+		&challenge.Method,
+		&challenge.Attempts,
+		&challenge.ExpiresAt,
+		&challenge.ConsumedAt,
+		&challenge.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, types.ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+func (s *Store) IncrementTwoFactorChallengeAttempts(ctx context.Context, challengeID int64) (int, error) {
+	var attempts int
+	err := s.db.QueryRow(ctx, `
+		UPDATE two_factor_challenges
+		SET attempts = attempts + 1
+		WHERE challenge_id = $1
+		RETURNING attempts
+	`, challengeID).Scan(&attempts)
+	return attempts, err
+}
+
+// ConsumeTwoFactorChallenge marks the challenge used. It reports false when a
+// concurrent request already consumed it.
+func (s *Store) ConsumeTwoFactorChallenge(ctx context.Context, challengeID int64) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE two_factor_challenges
+		SET consumed_at = NOW()
+		WHERE challenge_id = $1 AND consumed_at IS NULL
+	`, challengeID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *Store) CleanupExpiredTwoFactorChallenges(ctx context.Context, olderThan time.Time) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM two_factor_challenges WHERE expires_at < $1`, olderThan)
+	return err
+}
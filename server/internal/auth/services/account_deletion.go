@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+// AccountDeletionGracePeriod is how long a deletion request can be cancelled
+// by signing in again before the account is erased.
+const AccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+// RequestAccountDeletion schedules the account for deletion and signs it out
+// everywhere. Signing in again before the scheduled time cancels the request.
+func (s *AuthService) RequestAccountDeletion(ctx context.Context, userID, password string) (time.Time, error) {
+	user, err := s.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, types.ErrUserNotFound
+	}
+	if user.DeletedAt != nil {
+		return time.Time{}, types.ErrAccountDeleted
+	}
+
+	if user.PasswordHash != "" {
+		if password == "" {
+			return time.Time{}, types.ErrPasswordRequired
+		}
+		if !ComparePasswords(user.PasswordHash, []byte(password)) {
+			return time.Time{}, types.ErrInvalidCredentials
+		}
+	}
+
+	scheduledFor, err := s.userStore.ScheduleAccountDeletion(ctx, userID, time.Now().Add(AccountDeletionGracePeriod))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	if err := s.userStore.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
+		return time.Time{}, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	log.Printf("User %s requested account deletion, scheduled for %s", userID, scheduledFor.Format(time.RFC3339))
+
+	go func() {
+		if err := SendAccountDeletionScheduledEmail(user.Email, user.Name, scheduledFor); err != nil {
+			log.Printf("Failed to send account deletion email to %s: %v", userID, err)
+		}
+	}()
+
+	return scheduledFor, nil
+}
+
+func (s *AuthService) CancelAccountDeletion(ctx context.Context, userID string) (bool, error) {
+	cancelled, err := s.userStore.CancelAccountDeletion(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+	if cancelled {
+		log.Printf("Account deletion for user %s cancelled by sign-in", userID)
+	}
+	return cancelled, nil
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"fmt"
+	"html"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/resendlabs/resend-go"
 	"github.com/tdmdh/fit-up-server/shared/config"
@@ -194,3 +196,577 @@ func generatePasswordResetEmailHTML(resetURL, token string) string {
 		</html>
 	`, resetURL, resetURL, resetURL)
 }
+
+func SendCoachApplicationDecisionEmail(toEmail, name string, approved bool, reason string) error {
+	cfg := config.NewConfig()
+	if cfg.ResendAPIKey == "" {
+		return fmt.Errorf("resend api key is not configured")
+	}
+
+	subject := "Your coach application was not approved"
+	if approved {
+		subject = "Your coach application was approved"
+	}
+
+	client := resend.NewClient(cfg.ResendAPIKey)
+	params := &resend.SendEmailRequest{
+		From:    "noreply@lornian.com",
+		To:      []string{toEmail},
+		Subject: subject,
+		Html:    generateCoachApplicationDecisionEmailHTML(name, approved, reason),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}
+
+func generateCoachApplicationDecisionEmailHTML(name string, approved bool, reason string) string {
+	greeting := "Hello,"
+	if name != "" {
+		greeting = fmt.Sprintf("Hello %s,", html.EscapeString(name))
+	}
+
+	message := "Unfortunately we could not approve your application to become a coach at this time. You are welcome to apply again with updated credentials."
+	if approved {
+		message = "Good news: your application to become a coach has been approved. Sign in again to access your coach dashboard."
+	}
+
+	reasonBlock := ""
+	if reason != "" {
+		reasonBlock = fmt.Sprintf("<p><strong>Reviewer note:</strong> %s</p>", html.EscapeString(reason))
+	}
+
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #f8f9fa; padding: 20px; text-align: center; }
+				.content { padding: 20px; }
+				.footer { font-size: 12px; color: #666; margin-top: 20px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Coach Application Update</h1>
+				</div>
+				<div class="content">
+					<p>%s</p>
+					<p>%s</p>
+					%s
+				</div>
+				<div class="footer">
+					<p>This is an automated message, please do not reply to this email.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`, greeting, message, reasonBlock)
+}
+
+// NewLoginAlert describes an unfamiliar sign-in for the alert email.
+type NewLoginAlert struct {
+	DeviceName string
+	IPAddress  string
+	Country    string
+	Time       time.Time
+	NotMeURL   string
+}
+
+func SendNewLoginAlertEmail(toEmail, name string, alert NewLoginAlert) error {
+	cfg := config.NewConfig()
+	if cfg.ResendAPIKey == "" {
+		return fmt.Errorf("resend api key is not configured")
+	}
+
+	client := resend.NewClient(cfg.ResendAPIKey)
+	params := &resend.SendEmailRequest{
+		From:    "noreply@lornian.com",
+		To:      []string{toEmail},
+		Subject: "New sign-in to your account",
+		Html:    generateNewLoginAlertEmailHTML(name, alert),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}
+
+func generateNewLoginAlertEmailHTML(name string, alert NewLoginAlert) string {
+	greeting := "Hello,"
+	if name != "" {
+		greeting = fmt.Sprintf("Hello %s,", html.EscapeString(name))
+	}
+
+	valueOrUnknown := func(v string) string {
+		if v == "" {
+			return "Unknown"
+		}
+		return html.EscapeString(v)
+	}
+
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #f8f9fa; padding: 20px; text-align: center; }
+				.content { padding: 20px; }
+				.details td { padding: 4px 12px 4px 0; }
+				.button {
+					display: inline-block;
+					padding: 12px 24px;
+					background-color: #dc3545;
+					color: white;
+					text-decoration: none;
+					border-radius: 4px;
+					margin: 20px 0;
+				}
+				.footer { font-size: 12px; color: #666; margin-top: 20px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>New Sign-in Detected</h1>
+				</div>
+				<div class="content">
+					<p>%s</p>
+					<p>Your account was just signed in to from a device or location we haven't seen before.</p>
+					<table class="details">
+						<tr><td><strong>Device</strong></td><td>%s</td></tr>
+						<tr><td><strong>Location</strong></td><td>%s</td></tr>
+						<tr><td><strong>IP address</strong></td><td>%s</td></tr>
+						<tr><td><strong>Time</strong></td><td>%s</td></tr>
+					</table>
+					<p>If this was you, there's nothing you need to do.</p>
+					<p>If it wasn't, use the button below. We'll sign out every session and ask you to choose a new password.</p>
+					<a href="%s" class="button">This wasn't me</a>
+					<p>This link will expire in 7 days.</p>
+				</div>
+				<div class="footer">
+					<p>This is an automated message, please do not reply to this email.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`, greeting,
+		valueOrUnknown(alert.DeviceName),
+		valueOrUnknown(alert.Country),
+		valueOrUnknown(alert.IPAddress),
+		alert.Time.UTC().Format("2 Jan 2006 15:04 MST"),
+		html.EscapeString(alert.NotMeURL),
+	)
+}
+
+func SendAccountDeletionScheduledEmail(toEmail, name string, scheduledFor time.Time) error {
+	cfg := config.NewConfig()
+	if cfg.ResendAPIKey == "" {
+		return fmt.Errorf("resend api key is not configured")
+	}
+
+	client := resend.NewClient(cfg.ResendAPIKey)
+	params := &resend.SendEmailRequest{
+		From:    "noreply@lornian.com",
+		To:      []string{toEmail},
+		Subject: "Your account is scheduled for deletion",
+		Html:    generateAccountDeletionScheduledEmailHTML(name, scheduledFor),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}
+
+func generateAccountDeletionScheduledEmailHTML(name string, scheduledFor time.Time) string {
+	greeting := "Hello,"
+	if name != "" {
+		greeting = fmt.Sprintf("Hello %s,", html.EscapeString(name))
+	}
+
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #f8f9fa; padding: 20px; text-align: center; }
+				.content { padding: 20px; }
+				.footer { font-size: 12px; color: #666; margin-top: 20px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Account Deletion Scheduled</h1>
+				</div>
+				<div class="content">
+					<p>%s</p>
+					<p>We received a request to delete your account. You have been signed out on all devices.</p>
+					<p>Your account and its data will be permanently deleted on <strong>%s</strong>.</p>
+					<p>Changed your mind? Simply sign in again before then and the deletion will be cancelled.</p>
+					<p>Messages you sent to a coach will remain visible to them, attributed to "Deleted user".</p>
+				</div>
+				<div class="footer">
+					<p>This is an automated message, please do not reply to this email.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`, greeting, scheduledFor.UTC().Format("2 Jan 2006 15:04 MST"))
+}
+
+// buildFrontendLink points an emailed token at a page of the web app, which
+// also opens the mobile app through universal links.
+func buildFrontendLink(cfg config.Config, path, token string) string {
+	base := strings.TrimRight(strings.TrimSpace(cfg.FrontendURL), "/")
+	if base == "" {
+		base = "https://app.lornian.com"
+	}
+	return fmt.Sprintf("%s%s?token=%s", base, path, url.QueryEscape(token))
+}
+
+func SendMagicLinkEmail(toEmail, name, token string) error {
+	cfg := config.NewConfig()
+	if cfg.ResendAPIKey == "" {
+		return fmt.Errorf("resend api key is not configured")
+	}
+
+	client := resend.NewClient(cfg.ResendAPIKey)
+	params := &resend.SendEmailRequest{
+		From:    "noreply@lornian.com",
+		To:      []string{toEmail},
+		Subject: "Your sign-in link",
+		Html:    generateMagicLinkEmailHTML(name, buildFrontendLink(cfg, "/magic-link", token)),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}
+
+func generateMagicLinkEmailHTML(name, link string) string {
+	greeting := "Hello,"
+	if name != "" {
+		greeting = fmt.Sprintf("Hello %s,", html.EscapeString(name))
+	}
+
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #f8f9fa; padding: 20px; text-align: center; }
+				.content { padding: 20px; }
+				.button {
+					display: inline-block;
+					padding: 12px 24px;
+					background-color: #007bff;
+					color: white;
+					text-decoration: none;
+					border-radius: 4px;
+					margin: 20px 0;
+				}
+				.footer { font-size: 12px; color: #666; margin-top: 20px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Sign In</h1>
+				</div>
+				<div class="content">
+					<p>%s</p>
+					<p>Use the button below to sign in. Open it on the device where you requested it.</p>
+					<a href="%s" class="button">Sign in</a>
+					<p>This link can be used once and expires in 15 minutes.</p>
+					<p>If you didn't ask to sign in, you can safely ignore this email.</p>
+				</div>
+				<div class="footer">
+					<p>This is an automated message, please do not reply to this email.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`, greeting, html.EscapeString(link))
+}
+
+func SendEmailChangeConfirmationEmail(toEmail, name, token string) error {
+	cfg := config.NewConfig()
+	if cfg.ResendAPIKey == "" {
+		return fmt.Errorf("resend api key is not configured")
+	}
+
+	client := resend.NewClient(cfg.ResendAPIKey)
+	params := &resend.SendEmailRequest{
+		From:    "noreply@lornian.com",
+		To:      []string{toEmail},
+		Subject: "Confirm your new email address",
+		Html:    generateEmailChangeConfirmationEmailHTML(name, buildFrontendLink(cfg, "/email-change/confirm", token)),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}
+
+func generateEmailChangeConfirmationEmailHTML(name, link string) string {
+	greeting := "Hello,"
+	if name != "" {
+		greeting = fmt.Sprintf("Hello %s,", html.EscapeString(name))
+	}
+
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #f8f9fa; padding: 20px; text-align: center; }
+				.content { padding: 20px; }
+				.button {
+					display: inline-block;
+					padding: 12px 24px;
+					background-color: #007bff;
+					color: white;
+					text-decoration: none;
+					border-radius: 4px;
+					margin: 20px 0;
+				}
+				.footer { font-size: 12px; color: #666; margin-top: 20px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Confirm Your New Email</h1>
+				</div>
+				<div class="content">
+					<p>%s</p>
+					<p>We received a request to use this address for your account. Your account keeps its current address until you confirm.</p>
+					<a href="%s" class="button">Confirm new email</a>
+					<p>This link can be used once and expires in 1 hour.</p>
+					<p>If you didn't request this change, you can safely ignore this email.</p>
+				</div>
+				<div class="footer">
+					<p>This is an automated message, please do not reply to this email.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`, greeting, html.EscapeString(link))
+}
+
+// SendEmailChangedEmail tells the previous address about a completed change
+// and carries the link that undoes it.
+func SendEmailChangedEmail(toEmail, name, newEmail, token string) error {
+	cfg := config.NewConfig()
+	if cfg.ResendAPIKey == "" {
+		return fmt.Errorf("resend api key is not configured")
+	}
+
+	client := resend.NewClient(cfg.ResendAPIKey)
+	params := &resend.SendEmailRequest{
+		From:    "noreply@lornian.com",
+		To:      []string{toEmail},
+		Subject: "Your account email was changed",
+		Html:    generateEmailChangedEmailHTML(name, newEmail, buildFrontendLink(cfg, "/email-change/revert", token)),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}
+
+func generateEmailChangedEmailHTML(name, newEmail, link string) string {
+	greeting := "Hello,"
+	if name != "" {
+		greeting = fmt.Sprintf("Hello %s,", html.EscapeString(name))
+	}
+
+	return fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<style>
+				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { background-color: #f8f9fa; padding: 20px; text-align: center; }
+				.content { padding: 20px; }
+				.button {
+					display: inline-block;
+					padding: 12px 24px;
+					background-color: #dc3545;
+					color: white;
+					text-decoration: none;
+					border-radius: 4px;
+					margin: 20px 0;
+				}
+				.footer { font-size: 12px; color: #666; margin-top: 20px; }
+			</style>
+		</head>
+		<body>
+			<div class="container">
+				<div class="header">
+					<h1>Email Address Changed</h1>
+				</div>
+				<div class="content">
+					<p>%s</p>
+					<p>The email address on your account was changed to <strong>%s</strong>. We'll send account emails there from now on.</p>
+					<p>If this was you, there's nothing you need to do.</p>
+					<p>If it wasn't, use the button below. We'll restore this address, sign out every session and send you a link to choose a new password.</p>
+					<a href="%s" class="button">Undo this change</a>
+					<p>This link will expire in 7 days.</p>
+				</div>
+				<div class="footer">
+					<p>This is an automated message, please do not reply to this email.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`, greeting, html.EscapeString(newEmail), html.EscapeString(link))
+}
+
+// CoachInvitationEmail describes an invitation from a coach. FirstName and
+// CustomMessage may be empty.
+type CoachInvitationEmail struct {
+	CoachName     string
+	FirstName     string
+	CustomMessage string
+	Token         string
+	ExpiresAt     time.Time
+}
+
+func SendCoachInvitationEmail(toEmail string, invite CoachInvitationEmail) error {
+	cfg := config.NewConfig()
+	if cfg.ResendAPIKey == "" {
+		return fmt.Errorf("resend api key is not configured")
+	}
+
+	webLink, appLink := buildInvitationLinks(cfg, invite.Token, toEmail)
+	coachName := invite.CoachName
+	if coachName == "" {
+		coachName = "Your coach"
+	}
+
+	client := resend.NewClient(cfg.ResendAPIKey)
+	params := &resend.SendEmailRequest{
+		From:    "noreply@lornian.com",
+		To:      []string{toEmail},
+		Subject: fmt.Sprintf("%s invited you to train together", coachName),
+		Html:    generateCoachInvitationEmailHTML(coachName, invite, webLink, appLink),
+	}
+
+	_, err := client.Emails.Send(params)
+	return err
+}
+
+// buildInvitationLinks returns a web link and a mobile deep link for an
+// invitation. Both carry the invited email so the app can pre-fill sign-up
+// and accept the invitation with the token once the account exists.
+func buildInvitationLinks(cfg config.Config, token, email string) (string, string) {
+	webLink := buildFrontendLink(cfg, "/invite", token) + "&email=" + url.QueryEscape(email)
+
+	appBase := strings.TrimSpace(cfg.MobileInvitationURL)
+	if appBase == "" {
+		appBase = "fitup://invitations/accept"
+	}
+	separator := "?"
+	if strings.Contains(appBase, "?") {
+		separator = "&"
+	}
+	query := url.Values{"token": {token}, "email": {email}}
+	return webLink, appBase + separator + query.Encode()
+}
+
+func generateCoachInvitationEmailHTML(coachName string, invite CoachInvitationEmail, webLink, appLink string) string {
+	greeting := "Hello,"
+	if invite.FirstName != "" {
+		greeting = fmt.Sprintf("Hello %s,", html.EscapeString(invite.FirstName))
+	}
+
+	message := ""
+	if invite.CustomMessage != "" {
+		message = fmt.Sprintf(`<div class="message">%s</div>`,
+			strings.ReplaceAll(html.EscapeString(invite.CustomMessage), "\n", "<br>"))
+	}
+
+	return fmt.Sprintf(`
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<style>
+			body {
+				font-family: Arial, sans-serif;
+				background-color: #F8FAFC;
+				color: #334155;
+				margin: 0;
+				padding: 0;
+			}
+			.container {
+				max-width: 600px;
+				margin: 40px auto;
+				background: #FFFFFF;
+				padding: 32px;
+				border-radius: 8px;
+				box-shadow: 0 4px 12px rgba(0,0,0,0.05);
+			}
+			h1 {
+				color: #60A5FA;
+				margin-bottom: 16px;
+			}
+			p {
+				line-height: 1.6;
+			}
+			.message {
+				margin: 16px 0;
+				padding: 12px 16px;
+				border-left: 4px solid #60A5FA;
+				background-color: #EEF2FF;
+				color: #1E293B;
+			}
+			a.button {
+				display: inline-block;
+				margin-top: 24px;
+				padding: 12px 24px;
+				background-color: #60A5FA;
+				color: #FFFFFF;
+				text-decoration: none;
+				border-radius: 4px;
+				font-weight: bold;
+			}
+			a.button:hover {
+				background-color: #3B82F6;
+			}
+			.footer {
+				margin-top: 32px;
+				font-size: 14px;
+				color: #64748B;
+			}
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<h1>You're Invited</h1>
+			<p>%[1]s</p>
+			<p><strong>%[2]s</strong> has invited you to train with them on Fit-Up.</p>
+			%[3]s
+			<p>Accept the invitation to get your plans, check-ins and messages from your coach in one place. If you don't have an account yet, you can create one first; the invitation is accepted once you're signed in.</p>
+			<a href="%[4]s" class="button">Accept Invitation</a>
+			<p>Already have the app on this phone? <a href="%[5]s">Open the invitation in the app</a>.</p>
+			<p>If the button doesn't work, copy and paste this link into your browser:</p>
+			<p style="word-break: break-all; color: #60A5FA;">%[4]s</p>
+			<div class="footer">
+				<p>This invitation expires on %[6]s.</p>
+				<p>If you don't know this coach, you can safely ignore this email.</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`, greeting, html.EscapeString(coachName), message, html.EscapeString(webLink), html.EscapeString(appLink),
+		invite.ExpiresAt.UTC().Format("2 Jan 2006"))
+}
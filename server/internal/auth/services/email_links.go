@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+const (
+	magicLinkTTL         = 15 * time.Minute
+	emailChangeTTL       = time.Hour
+	emailChangeRevertTTL = 7 * 24 * time.Hour
+	revertResetTokenTTL  = time.Hour
+)
+
+// RequestMagicLink emails a one-time sign-in link. Unknown or blocked addresses
+// are ignored without an error so the endpoint can't be used to probe accounts.
+func (s *AuthService) RequestMagicLink(ctx context.Context, email, codeChallenge, codeChallengeMethod string) error {
+	if codeChallenge != "" || codeChallengeMethod != "" {
+		if codeChallengeMethod != "S256" || !validCodeChallenge(codeChallenge) {
+			return types.ErrInvalidCodeChallenge
+		}
+	}
+
+	user, err := s.userStore.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == types.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if err := CheckAccountStatus(user); err != nil {
+		log.Printf("Magic link not sent to user %s: %v", user.ID, err)
+		return nil
+	}
+
+	token, err := s.createEmailToken(ctx, &types.EmailToken{
+		Purpose:       types.EmailTokenMagicLink,
+		UserID:        user.ID,
+		Email:         user.Email,
+		CodeChallenge: codeChallenge,
+		ExpiresAt:     time.Now().Add(magicLinkTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sent in the background so response timing doesn't reveal whether the account exists
+	go func() {
+		if err := SendMagicLinkEmail(user.Email, user.Name, token); err != nil {
+			log.Printf("Failed to send magic link to %s: %v", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// VerifyMagicLink redeems a sign-in link. Links requested with a PKCE challenge
+// only work with the matching verifier, so a link opened on another device is useless.
+func (s *AuthService) VerifyMagicLink(ctx context.Context, token, codeVerifier string) (*types.User, error) {
+	emailToken, err := s.userStore.GetEmailToken(ctx, HashRefreshToken(token), types.EmailTokenMagicLink)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checked before consuming so a wrong verifier doesn't burn the real client's link
+	if emailToken.CodeChallenge != "" && !verifyPKCE(emailToken.CodeChallenge, codeVerifier) {
+		return nil, types.ErrInvalidCodeVerifier
+	}
+
+	if err := s.consumeEmailToken(ctx, emailToken); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, emailToken.UserID)
+	if err != nil {
+		return nil, types.ErrEmailLinkInvalid
+	}
+	if !strings.EqualFold(user.Email, emailToken.Email) {
+		return nil, types.ErrEmailLinkInvalid
+	}
+
+	// Following the link proves the user controls the address
+	if user.EmailVerified == nil {
+		verifiedAt := time.Now()
+		if err := s.userStore.MarkEmailVerified(ctx, user.ID, verifiedAt); err != nil {
+			return nil, err
+		}
+		user.EmailVerified = &verifiedAt
+	}
+
+	return user, nil
+}
+
+// RequestEmailChange sends a confirmation link to the new address. The
+// account keeps its current address until that link is followed.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID, newEmail, password string) error {
+	user, err := s.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return types.ErrUserNotFound
+	}
+	if user.DeletedAt != nil {
+		return types.ErrAccountDeleted
+	}
+
+	if user.PasswordHash != "" {
+		if password == "" {
+			return types.ErrPasswordRequired
+		}
+		if !ComparePasswords(user.PasswordHash, []byte(password)) {
+			return types.ErrInvalidCredentials
+		}
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if strings.EqualFold(user.Email, newEmail) {
+		return types.ErrEmailUnchanged
+	}
+
+	if _, err := s.userStore.GetUserByEmail(ctx, newEmail); err == nil {
+		return types.ErrUserAlreadyExists
+	} else if err != types.ErrUserNotFound {
+		return err
+	}
+
+	token, err := s.createEmailToken(ctx, &types.EmailToken{
+		Purpose:   types.EmailTokenEmailChange,
+		UserID:    user.ID,
+		Email:     user.Email,
+		NewEmail:  newEmail,
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := SendEmailChangeConfirmationEmail(newEmail, user.Name, token); err != nil {
+		return fmt.Errorf("failed to send email change confirmation: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange switches the account to the new address and emails the
+// old one a link that undoes the change.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, token string) (*types.User, error) {
+	emailToken, err := s.userStore.GetEmailToken(ctx, HashRefreshToken(token), types.EmailTokenEmailChange)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.consumeEmailToken(ctx, emailToken); err != nil {
+		return nil, err
+	}
+
+	changed, err := s.userStore.UpdateUserEmail(ctx, emailToken.UserID, emailToken.Email, emailToken.NewEmail)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, types.ErrEmailLinkInvalid
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, emailToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("User %s changed their email address", user.ID)
+
+	revertToken, err := s.createEmailToken(ctx, &types.EmailToken{
+		Purpose:   types.EmailTokenEmailChangeRevert,
+		UserID:    user.ID,
+		Email:     emailToken.Email,
+		NewEmail:  emailToken.NewEmail,
+		ExpiresAt: time.Now().Add(emailChangeRevertTTL),
+	})
+	if err != nil {
+		log.Printf("Failed to create email change revert link for %s: %v", user.ID, err)
+		return user, nil
+	}
+
+	go func() {
+		if err := SendEmailChangedEmail(emailToken.Email, user.Name, emailToken.NewEmail, revertToken); err != nil {
+			log.Printf("Failed to send email change notice to the old address of %s: %v", user.ID, err)
+		}
+	}()
+
+	return user, nil
+}
+
+// RevertEmailChange restores the address the revert link was sent to. Whoever
+// made the change is treated as an intruder: every session is revoked and the
+// owner must set a new password via the reset link sent to the restored address.
+func (s *AuthService) RevertEmailChange(ctx context.Context, token string) error {
+	emailToken, err := s.userStore.GetEmailToken(ctx, HashRefreshToken(token), types.EmailTokenEmailChangeRevert)
+	if err != nil {
+		return err
+	}
+
+	if err := s.consumeEmailToken(ctx, emailToken); err != nil {
+		return err
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, emailToken.UserID)
+	if err != nil {
+		return types.ErrEmailLinkInvalid
+	}
+
+	// Restored from whatever the address is now, so changing it again can't block the revert
+	if !strings.EqualFold(user.Email, emailToken.Email) {
+		changed, err := s.userStore.UpdateUserEmail(ctx, user.ID, user.Email, emailToken.Email)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			return types.ErrEmailLinkInvalid
+		}
+	}
+
+	if err := s.userStore.RevokeAllUserRefreshTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := s.userStore.SetPasswordResetRequired(ctx, user.ID, true); err != nil {
+		return fmt.Errorf("failed to require password reset: %w", err)
+	}
+
+	log.Printf("User %s reverted an email change; all sessions revoked", user.ID)
+
+	resetToken, err := CreatePasswordResetToken(emailToken.Email)
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	if err := s.userStore.CreatePasswordResetToken(ctx, emailToken.Email, resetToken.Token, time.Now().Add(revertResetTokenTTL)); err != nil {
+		return fmt.Errorf("failed to store password reset token: %w", err)
+	}
+	if err := SendPasswordResetEmail(emailToken.Email, resetToken.Token); err != nil {
+		log.Printf("Failed to send password reset email after revert for %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// createEmailToken stores the hash of a fresh token and returns the raw token for the link.
+func (s *AuthService) createEmailToken(ctx context.Context, emailToken *types.EmailToken) (string, error) {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s token: %w", emailToken.Purpose, err)
+	}
+
+	emailToken.TokenHash = HashRefreshToken(token)
+	if err := s.userStore.CreateEmailToken(ctx, emailToken); err != nil {
+		return "", fmt.Errorf("failed to store %s token: %w", emailToken.Purpose, err)
+	}
+	return token, nil
+}
+
+// consumeEmailToken enforces single use when the same link is redeemed concurrently.
+func (s *AuthService) consumeEmailToken(ctx context.Context, emailToken *types.EmailToken) error {
+	consumed, err := s.userStore.ConsumeEmailToken(ctx, emailToken.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return types.ErrEmailLinkInvalid
+	}
+	return nil
+}
+
+// validCodeChallenge accepts a base64url SHA-256 digest as RFC 7636 S256 produces.
+func validCodeChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// verifyPKCE checks an RFC 7636 code verifier against its S256 challenge.
+func verifyPKCE(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, c := range verifier {
+		if !isUnreservedChar(c) {
+			return false
+		}
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func isUnreservedChar(c rune) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
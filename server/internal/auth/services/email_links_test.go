@@ -0,0 +1,60 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+// RFC 7636 appendix B example.
+const (
+	rfcCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfcCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestVerifyPKCEMatchesRFCExample(t *testing.T) {
+	if !validCodeChallenge(rfcCodeChallenge) {
+		t.Fatal("expected the RFC challenge to be accepted")
+	}
+	if !verifyPKCE(rfcCodeChallenge, rfcCodeVerifier) {
+		t.Fatal("expected the RFC verifier to match its challenge")
+	}
+}
+
+func TestVerifyPKCERejectsBadVerifiers(t *testing.T) {
+	cases := map[string]string{
+		"empty":        "",
+		"wrong":        strings.Repeat("a", 43),
+		"too short":    rfcCodeVerifier[:42],
+		"too long":     strings.Repeat("a", 129),
+		"invalid char": rfcCodeVerifier[:42] + "+",
+	}
+
+	for name, verifier := range cases {
+		if verifyPKCE(rfcCodeChallenge, verifier) {
+			t.Errorf("%s: expected verifier %q to be rejected", name, verifier)
+		}
+	}
+}
+
+func TestValidCodeChallengeRejectsNonDigests(t *testing.T) {
+	for _, challenge := range []string{"", "plain-text-challenge", rfcCodeChallenge + "=", rfcCodeChallenge[:40]} {
+		if validCodeChallenge(challenge) {
+			t.Errorf("expected challenge %q to be rejected", challenge)
+		}
+	}
+}
+
+func TestBuildInvitationLinksEscapesTokenAndEmail(t *testing.T) {
+	cfg := config.Config{FrontendURL: "https://app.example.com/", MobileInvitationURL: "fitup://invitations/accept"}
+
+	webLink, appLink := buildInvitationLinks(cfg, "abc+/=", "sam+fit@example.com")
+
+	if want := "https://app.example.com/invite?token=abc%2B%2F%3D&email=sam%2Bfit%40example.com"; webLink != want {
+		t.Errorf("web link = %q, want %q", webLink, want)
+	}
+	if want := "fitup://invitations/accept?email=sam%2Bfit%40example.com&token=abc%2B%2F%3D"; appLink != want {
+		t.Errorf("app link = %q, want %q", appLink, want)
+	}
+}
@@ -285,7 +285,13 @@ func GenerateTokenPair(ctx context.Context, user *types.User, store repository.R
 
 	tokenHash := HashRefreshToken(refreshToken)
 	expiresAt := time.Now().Add(7 * 24 * time.Hour)
-	err = store.CreateRefreshToken(ctx, user.ID, tokenHash, expiresAt, claims.JTI)
+	err = store.CreateRefreshToken(ctx, &types.RefreshToken{
+		UserID:         user.ID,
+		TokenHash:      tokenHash,
+		AccessTokenJTI: claims.JTI,
+		ExpiresAt:      expiresAt,
+		FamilyID:       uuid.New().String(),
+	})
 	if err != nil {
 		return nil, err
 	}
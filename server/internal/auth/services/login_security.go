@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/repository"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/shared/config"
+	"github.com/tdmdh/fit-up-server/shared/geoip"
+)
+
+const (
+	maxFailedLoginAttempts = 5
+	securityAlertTokenTTL  = 7 * 24 * time.Hour
+)
+
+// lockoutDurations grow with each lockout in a row; the last entry is the cap.
+var lockoutDurations = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+func lockoutDuration(level int) time.Duration {
+	if level < 0 {
+		level = 0
+	}
+	if level >= len(lockoutDurations) {
+		level = len(lockoutDurations) - 1
+	}
+	return lockoutDurations[level]
+}
+
+// LoginSecurityService locks accounts after repeated password failures and
+// emails the owner when a login comes from an unfamiliar device or country.
+type LoginSecurityService struct {
+	store       repository.LoginSecurityStore
+	geo         *geoip.DB
+	frontendURL string
+}
+
+// NewLoginSecurityService creates the service. geo may be nil, in which case
+// only new devices are detected.
+func NewLoginSecurityService(store repository.LoginSecurityStore, geo *geoip.DB, cfg *config.Config) *LoginSecurityService {
+	frontendURL := strings.TrimRight(strings.TrimSpace(cfg.FrontendURL), "/")
+	if frontendURL == "" {
+		frontendURL = "https://app.lornian.com"
+	}
+
+	return &LoginSecurityService{
+		store:       store,
+		geo:         geo,
+		frontendURL: frontendURL,
+	}
+}
+
+// CheckLockout returns ErrAccountLocked and the unlock time while the account is locked.
+func (s *LoginSecurityService) CheckLockout(ctx context.Context, userID string) (time.Time, error) {
+	lockout, err := s.store.GetLoginLockout(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if lockout.LockedUntil != nil && time.Now().Before(*lockout.LockedUntil) {
+		return *lockout.LockedUntil, types.ErrAccountLocked
+	}
+	return time.Time{}, nil
+}
+
+// RecordFailedLogin counts a wrong password and locks the account once the
+// threshold is reached. It returns the unlock time when this attempt caused a lockout.
+func (s *LoginSecurityService) RecordFailedLogin(ctx context.Context, userID string) (time.Time, error) {
+	lockout, err := s.store.RecordFailedLogin(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if lockout.FailedAttempts < maxFailedLoginAttempts {
+		return time.Time{}, nil
+	}
+
+	until := time.Now().Add(lockoutDuration(lockout.LockoutLevel))
+	if err := s.store.LockAccount(ctx, userID, until); err != nil {
+		return time.Time{}, err
+	}
+
+	log.Printf("Account %s locked until %s after %d failed logins", userID, until.Format(time.RFC3339), lockout.FailedAttempts)
+	return until, nil
+}
+
+// RecordSuccessfulLogin clears failed attempts, stores the login and sends an
+// alert if it came from a new device or country. Failures are logged only so
+// they never block a valid login.
+func (s *LoginSecurityService) RecordSuccessfulLogin(ctx context.Context, user *types.User, device types.DeviceInfo) {
+	if err := s.store.ClearLoginLockout(ctx, user.ID); err != nil {
+		log.Printf("Failed to clear login lockout for %s: %v", user.ID, err)
+	}
+
+	event := &types.LoginEvent{
+		UserID:            user.ID,
+		DeviceFingerprint: deviceFingerprint(device),
+		DeviceName:        device.DeviceName,
+		UserAgent:         device.UserAgent,
+		IPAddress:         device.IPAddress,
+		CountryCode:       s.geo.Country(device.IPAddress),
+	}
+
+	familiarity, err := s.store.GetLoginFamiliarity(ctx, user.ID, event.DeviceFingerprint, event.CountryCode)
+	if err != nil {
+		log.Printf("Failed to compare login for %s against history: %v", user.ID, err)
+		return
+	}
+
+	// The first login sets the baseline; there is nothing to compare it with
+	if familiarity.HasHistory {
+		event.NewDevice = !familiarity.KnownDevice
+		event.NewCountry = event.CountryCode != "" && !familiarity.KnownCountry
+	}
+
+	if err := s.store.CreateLoginEvent(ctx, event); err != nil {
+		log.Printf("Failed to record login event for %s: %v", user.ID, err)
+		return
+	}
+
+	if event.NewDevice || event.NewCountry {
+		s.sendNewLoginAlert(ctx, user, event)
+	}
+}
+
+func (s *LoginSecurityService) sendNewLoginAlert(ctx context.Context, user *types.User, event *types.LoginEvent) {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		log.Printf("Failed to generate security alert token for %s: %v", user.ID, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(securityAlertTokenTTL)
+	if err := s.store.CreateSecurityAlertToken(ctx, HashRefreshToken(token), user.ID, event.ID, expiresAt); err != nil {
+		log.Printf("Failed to store security alert token for %s: %v", user.ID, err)
+		return
+	}
+
+	alert := NewLoginAlert{
+		DeviceName: event.DeviceName,
+		IPAddress:  event.IPAddress,
+		Country:    event.CountryCode,
+		Time:       event.CreatedAt,
+		NotMeURL:   fmt.Sprintf("%s/security/not-me?token=%s", s.frontendURL, url.QueryEscape(token)),
+	}
+
+	// Sent in the background so the login response isn't held up by the mail provider
+	go func() {
+		if err := SendNewLoginAlertEmail(user.Email, user.Name, alert); err != nil {
+			log.Printf("Failed to send new login alert to %s: %v", user.ID, err)
+		}
+	}()
+}
+
+// ReportUnrecognizedLogin handles the "this wasn't me" link: every session is
+// revoked and the account must choose a new password before signing in again.
+func (s *LoginSecurityService) ReportUnrecognizedLogin(ctx context.Context, token string) error {
+	userID, err := s.store.ConsumeSecurityAlertToken(ctx, HashRefreshToken(token))
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := s.store.SetPasswordResetRequired(ctx, userID, true); err != nil {
+		return fmt.Errorf("failed to require password reset: %w", err)
+	}
+
+	log.Printf("User %s reported an unrecognized login; all sessions revoked", userID)
+	return nil
+}
+
+// deviceFingerprint keys devices on their coarse name ("Chrome on macOS" or
+// the app-supplied device name) so browser updates don't look like new devices.
+func deviceFingerprint(device types.DeviceInfo) string {
+	name := strings.ToLower(strings.TrimSpace(device.DeviceName))
+	if name == "" {
+		name = strings.ToLower(strings.TrimSpace(device.UserAgent))
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
@@ -116,8 +116,13 @@ func (s *AuthService) Logout(ctx context.Context, userID string) error {
 	return nil
 }
 
+// UpdateUserRole is the self-service role change. Users may only step down to
+// the plain user role; becoming a coach goes through a reviewed coach application.
 func (s *AuthService) UpdateUserRole(ctx context.Context, userID string, role types.UserRole) error {
-	if role != types.RoleUser && role != types.RoleCoach {
+	if role == types.RoleCoach {
+		return types.ErrCoachApplicationRequired
+	}
+	if role != types.RoleUser {
 		return types.ErrInvalidInput
 	}
 
@@ -136,7 +141,13 @@ func (s *AuthService) UpdateUserRole(ctx context.Context, userID string, role ty
 	return nil
 }
 
-func (s *AuthService) GenerateTokenPair(ctx context.Context, user *types.User) (*types.TokenPair, error) {
+func (s *AuthService) GenerateTokenPair(ctx context.Context, user *types.User, device types.DeviceInfo) (*types.TokenPair, error) {
+	return s.issueTokenPair(ctx, user, uuid.New().String(), device)
+}
+
+// issueTokenPair creates an access token and a refresh token belonging to the
+// given token family. A new login starts a family; rotation keeps it.
+func (s *AuthService) issueTokenPair(ctx context.Context, user *types.User, familyID string, device types.DeviceInfo) (*types.TokenPair, error) {
 	if user == nil {
 		return nil, types.ErrUserNotFound
 	}
@@ -152,7 +163,16 @@ func (s *AuthService) GenerateTokenPair(ctx context.Context, user *types.User) (
 	}
 
 	refreshTokenExpiry := time.Now().Add(time.Duration(config.NewConfig().RefreshTokenExpirationInSeconds) * time.Second)
-	err = s.userStore.CreateRefreshToken(ctx, user.ID, refreshToken, refreshTokenExpiry, jti)
+	err = s.userStore.CreateRefreshToken(ctx, &types.RefreshToken{
+		UserID:         user.ID,
+		TokenHash:      refreshToken,
+		AccessTokenJTI: jti,
+		ExpiresAt:      refreshTokenExpiry,
+		FamilyID:       familyID,
+		DeviceName:     device.DeviceName,
+		UserAgent:      device.UserAgent,
+		IPAddress:      device.IPAddress,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
 	}
@@ -166,7 +186,10 @@ func (s *AuthService) GenerateTokenPair(ctx context.Context, user *types.User) (
 	}, nil
 }
 
-func (s *AuthService) RotateTokens(ctx context.Context, refreshToken string) (*types.TokenPair, error) {
+// RotateTokens exchanges a refresh token for a new pair in the same family.
+// Presenting a token that was already rotated means it leaked, so the whole
+// family is revoked and the legitimate client has to sign in again.
+func (s *AuthService) RotateTokens(ctx context.Context, refreshToken string, device types.DeviceInfo) (*types.TokenPair, error) {
 	if refreshToken == "" {
 		return nil, types.ErrRefreshTokenNotFound
 	}
@@ -177,7 +200,10 @@ func (s *AuthService) RotateTokens(ctx context.Context, refreshToken string) (*t
 	}
 
 	if storedRefreshToken.IsRevoked {
-		return nil, types.ErrRefreshTokenNotFound
+		if err := s.userStore.RevokeRefreshTokenFamily(ctx, storedRefreshToken.FamilyID); err != nil {
+			return nil, fmt.Errorf("failed to revoke token family: %w", err)
+		}
+		return nil, types.ErrRefreshTokenReused
 	}
 
 	if time.Now().After(storedRefreshToken.ExpiresAt) {
@@ -189,12 +215,83 @@ func (s *AuthService) RotateTokens(ctx context.Context, refreshToken string) (*t
 		return nil, err
 	}
 
-	err = s.userStore.RevokeRefreshToken(ctx, refreshToken)
+	if err := CheckAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.userStore.RevokeActiveRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to revoke old refresh token: %w", err)
 	}
+	if !revoked {
+		// Another request rotated the same token first
+		if err := s.userStore.RevokeRefreshTokenFamily(ctx, storedRefreshToken.FamilyID); err != nil {
+			return nil, fmt.Errorf("failed to revoke token family: %w", err)
+		}
+		return nil, types.ErrRefreshTokenReused
+	}
+
+	if device.DeviceName == "" {
+		device.DeviceName = storedRefreshToken.DeviceName
+	}
+
+	return s.issueTokenPair(ctx, user, storedRefreshToken.FamilyID, device)
+}
+
+// ListSessions returns the user's active sessions, flagging the one the
+// current access token belongs to.
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentJTI string) ([]types.Session, error) {
+	sessions, err := s.userStore.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentID, err := s.userStore.GetSessionIDByAccessTokenJTI(ctx, currentJTI)
+	if err != nil && err != types.ErrSessionNotFound {
+		return nil, err
+	}
 
-	return s.GenerateTokenPair(ctx, user)
+	for i := range sessions {
+		sessions[i].Current = currentID != "" && sessions[i].ID == currentID
+	}
+
+	return sessions, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	revoked, err := s.userStore.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return types.ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except the session the
+// current access token was issued with.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentJTI string) (int64, error) {
+	currentID, err := s.userStore.GetSessionIDByAccessTokenJTI(ctx, currentJTI)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.userStore.RevokeOtherSessions(ctx, userID, currentID)
+}
+
+// CheckAccountStatus reports whether the user may be issued tokens.
+func CheckAccountStatus(user *types.User) error {
+	if user.DeletedAt != nil {
+		return types.ErrAccountDeleted
+	}
+	if user.SuspendedAt != nil {
+		return types.ErrAccountDisabled
+	}
+	if user.PasswordResetRequired {
+		return types.ErrPasswordResetRequired
+	}
+	return nil
 }
 
 func ValidatePasswordResetToken(token *types.PasswordResetToken) bool {
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/repository"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+)
+
+// fakeRefreshStore keeps refresh tokens in memory. onGet runs once after the
+// next lookup, letting a test slip a second request in between the lookup
+// and the revoke.
+type fakeRefreshStore struct {
+	repository.UserStore
+
+	mu     sync.Mutex
+	users  map[string]*types.User
+	tokens map[string]*types.RefreshToken
+	onGet  func()
+}
+
+func newFakeRefreshStore() *fakeRefreshStore {
+	return &fakeRefreshStore{
+		users:  map[string]*types.User{"u1": {ID: "u1", Email: "sam@example.com", Role: types.RoleClient}},
+		tokens: make(map[string]*types.RefreshToken),
+	}
+}
+
+func (f *fakeRefreshStore) add(token, familyID string, expiresAt time.Time) {
+	f.tokens[token] = &types.RefreshToken{UserID: "u1", TokenHash: token, FamilyID: familyID, ExpiresAt: expiresAt, DeviceName: "Pixel 8"}
+}
+
+func (f *fakeRefreshStore) GetUserByID(ctx context.Context, id string) (*types.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, types.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeRefreshStore) GetRefreshToken(ctx context.Context, token string) (*types.RefreshToken, error) {
+	f.mu.Lock()
+	stored, ok := f.tokens[token]
+	var found types.RefreshToken
+	if ok {
+		found = *stored
+	}
+	hook := f.onGet
+	f.onGet = nil
+	f.mu.Unlock()
+
+	if !ok {
+		return nil, types.ErrRefreshTokenNotFound
+	}
+	if hook != nil {
+		hook()
+	}
+	return &found, nil
+}
+
+func (f *fakeRefreshStore) CreateRefreshToken(ctx context.Context, token *types.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := *token
+	f.tokens[token.TokenHash] = &stored
+	return nil
+}
+
+func (f *fakeRefreshStore) RevokeActiveRefreshToken(ctx context.Context, token string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.tokens[token]
+	if !ok || stored.IsRevoked {
+		return false, nil
+	}
+	stored.IsRevoked = true
+	return true, nil
+}
+
+func (f *fakeRefreshStore) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, stored := range f.tokens {
+		if stored.FamilyID == familyID {
+			stored.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeRefreshStore) revoked(token string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tokens[token].IsRevoked
+}
+
+func TestRotateTokensIssuesANewPairInTheSameFamily(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	store := newFakeRefreshStore()
+	store.add("first", "family-1", time.Now().Add(time.Hour))
+	service := NewAuthService(store)
+
+	pair, err := service.RotateTokens(context.Background(), "first", types.DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !store.revoked("first") {
+		t.Error("the rotated token should be revoked")
+	}
+	next := store.tokens[pair.RefreshToken]
+	if next == nil || next.IsRevoked || next.FamilyID != "family-1" || next.AccessTokenJTI == "" {
+		t.Errorf("unexpected new refresh token %+v", next)
+	}
+	if next.DeviceName != "Pixel 8" {
+		t.Errorf("device name = %q, want it carried over from the old token", next.DeviceName)
+	}
+}
+
+func TestRotateTokensRevokesTheFamilyWhenATokenIsReused(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	store := newFakeRefreshStore()
+	store.add("first", "family-1", time.Now().Add(time.Hour))
+	store.add("other-device", "family-2", time.Now().Add(time.Hour))
+	service := NewAuthService(store)
+
+	pair, err := service.RotateTokens(context.Background(), "first", types.DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A copy of the old token turns up again, e.g. stolen before rotation
+	if _, err := service.RotateTokens(context.Background(), "first", types.DeviceInfo{}); err != types.ErrRefreshTokenReused {
+		t.Fatalf("reusing a rotated token: err = %v, want %v", err, types.ErrRefreshTokenReused)
+	}
+
+	if !store.revoked(pair.RefreshToken) {
+		t.Error("the token issued by the legitimate rotation should be revoked with its family")
+	}
+	if store.revoked("other-device") {
+		t.Error("other sessions should be left alone")
+	}
+	if _, err := service.RotateTokens(context.Background(), pair.RefreshToken, types.DeviceInfo{}); err != types.ErrRefreshTokenReused {
+		t.Errorf("rotating a token from the revoked family: err = %v, want %v", err, types.ErrRefreshTokenReused)
+	}
+}
+
+func TestRotateTokensRevokesTheFamilyWhenARotationRaces(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	store := newFakeRefreshStore()
+	store.add("first", "family-1", time.Now().Add(time.Hour))
+	service := NewAuthService(store)
+
+	// The second request rotates the token after the first has looked it up
+	// but before it revokes it
+	var winner *types.TokenPair
+	store.onGet = func() {
+		var err error
+		winner, err = service.RotateTokens(context.Background(), "first", types.DeviceInfo{})
+		if err != nil {
+			t.Errorf("racing rotation: %v", err)
+		}
+	}
+
+	if _, err := service.RotateTokens(context.Background(), "first", types.DeviceInfo{}); err != types.ErrRefreshTokenReused {
+		t.Fatalf("losing rotation: err = %v, want %v", err, types.ErrRefreshTokenReused)
+	}
+	if winner == nil || !store.revoked(winner.RefreshToken) {
+		t.Error("the token issued to the winning rotation should be revoked with its family")
+	}
+
+	for token, stored := range store.tokens {
+		if !stored.IsRevoked {
+			t.Errorf("token %q survived the race", token)
+		}
+	}
+}
+
+func TestRotateTokensRejectsUnusableTokens(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	store := newFakeRefreshStore()
+	store.add("expired", "family-1", time.Now().Add(-time.Minute))
+	store.add("suspended", "family-2", time.Now().Add(time.Hour))
+	suspendedAt := time.Now()
+	store.users["u2"] = &types.User{ID: "u2", SuspendedAt: &suspendedAt}
+	store.tokens["suspended"].UserID = "u2"
+	service := NewAuthService(store)
+
+	tests := []struct {
+		token string
+		want  error
+	}{
+		{"", types.ErrRefreshTokenNotFound},
+		{"unknown", types.ErrRefreshTokenNotFound},
+		{"expired", types.ErrRefreshTokenExpired},
+		{"suspended", types.ErrAccountDisabled},
+	}
+
+	for _, tt := range tests {
+		if _, err := service.RotateTokens(context.Background(), tt.token, types.DeviceInfo{}); err != tt.want {
+			t.Errorf("token %q: err = %v, want %v", tt.token, err, tt.want)
+		}
+	}
+	if store.revoked("suspended") {
+		t.Error("a rejected token should not be rotated")
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B uses this ASCII seed for the SHA-1 vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateTOTPCodeMatchesRFCVectors(t *testing.T) {
+	// The RFC lists 8-digit codes; a 6-digit code is their last six digits.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		got, err := GenerateTOTPCode(rfcSecret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode(%d) error: %v", unix, err)
+		}
+		if got != want {
+			t.Errorf("GenerateTOTPCode(%d) = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidateTOTPCodeAllowsOneStepOfDrift(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	previous, _ := GenerateTOTPCode(rfcSecret, now.Add(-30*time.Second))
+	if step, ok := ValidateTOTPCode(rfcSecret, previous, now); !ok || step != now.Unix()/30-1 {
+		t.Fatalf("expected previous step to validate, got step=%d ok=%v", step, ok)
+	}
+
+	stale, _ := GenerateTOTPCode(rfcSecret, now.Add(-90*time.Second))
+	if _, ok := ValidateTOTPCode(rfcSecret, stale, now); ok {
+		t.Fatal("expected code from three steps ago to be rejected")
+	}
+
+	if _, ok := ValidateTOTPCode(rfcSecret, "12345", now); ok {
+		t.Fatal("expected short code to be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Fit-Up", "coach@example.com", "JBSWY3DPEHPK3PXP")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Fit-Up:coach@example.com?") {
+		t.Fatalf("unexpected label in %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=Fit-Up", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("expected %q in %s", part, uri)
+		}
+	}
+}
+
+func TestEncryptSecretRoundTrip(t *testing.T) {
+	sealed, err := encryptSecret("passphrase", "JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("encryptSecret error: %v", err)
+	}
+	if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") {
+		t.Fatal("ciphertext contains the plaintext secret")
+	}
+
+	opened, err := decryptSecret("passphrase", sealed)
+	if err != nil || opened != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("decryptSecret = %q, %v", opened, err)
+	}
+
+	if _, err := decryptSecret("other passphrase", sealed); err == nil {
+		t.Fatal("expected decryption with the wrong key to fail")
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/auth/repository"
+	"github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+const (
+	recoveryCodeCount     = 10
+	challengeTTL          = 5 * time.Minute
+	maxChallengeAttempts  = 5
+	recoveryCodeByteCount = 5
+)
+
+type TwoFactorService struct {
+	store  repository.TwoFactorStore
+	issuer string
+	key    string
+}
+
+func NewTwoFactorService(store repository.TwoFactorStore, cfg *config.Config) *TwoFactorService {
+	key := cfg.TwoFactorEncryptionKey
+	if key == "" {
+		key = cfg.JWTSecret
+	}
+
+	issuer := cfg.TwoFactorIssuer
+	if issuer == "" {
+		issuer = "Fit-Up"
+	}
+
+	return &TwoFactorService{
+		store:  store,
+		issuer: issuer,
+		key:    key,
+	}
+}
+
+func (s *TwoFactorService) GetStatus(ctx context.Context, userID string) (*types.TwoFactorStatusResponse, error) {
+	settings, err := s.store.GetTwoFactorSettings(ctx, userID)
+	if err != nil {
+		if err == types.ErrTwoFactorNotEnabled {
+			return &types.TwoFactorStatusResponse{Enabled: false}, nil
+		}
+		return nil, err
+	}
+
+	if settings.ConfirmedAt == nil {
+		return &types.TwoFactorStatusResponse{Enabled: false}, nil
+	}
+
+	remaining, err := s.store.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TwoFactorStatusResponse{
+		Enabled:                true,
+		ConfirmedAt:            settings.ConfirmedAt,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// BeginSetup stores a fresh unconfirmed secret. Calling it again before
+// confirming replaces the pending secret.
+func (s *TwoFactorService) BeginSetup(ctx context.Context, user *types.User) (*types.TwoFactorSetupResponse, error) {
+	if user.IsTwoFactorEnabled {
+		return nil, types.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := encryptSecret(s.key, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpsertTwoFactorSecret(ctx, user.ID, encrypted); err != nil {
+		return nil, fmt.Errorf("failed to store two-factor secret: %w", err)
+	}
+
+	return &types.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmSetup enables two-factor once the user proves their app produces
+// valid codes, and returns the one-time recovery codes.
+func (s *TwoFactorService) ConfirmSetup(ctx context.Context, userID, code string) ([]string, error) {
+	settings, err := s.store.GetTwoFactorSettings(ctx, userID)
+	if err != nil {
+		if err == types.ErrTwoFactorNotEnabled {
+			return nil, types.ErrTwoFactorSetupRequired
+		}
+		return nil, err
+	}
+
+	if settings.ConfirmedAt != nil {
+		return nil, types.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := decryptSecret(s.key, settings.EncryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := ValidateTOTPCode(secret, code, time.Now())
+	if !ok {
+		return nil, types.ErrInvalidTwoFactorCode
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.ConfirmTwoFactor(ctx, userID, step); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *TwoFactorService) Disable(ctx context.Context, userID, code string) error {
+	if err := s.verifyCode(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.store.DisableTwoFactor(ctx, userID)
+}
+
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if err := s.verifyCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// CreateChallenge issues the short-lived token a client exchanges, together with
+// a code, for a token pair. Only the token's hash is stored.
+func (s *TwoFactorService) CreateChallenge(ctx context.Context, userID string, method types.TwoFactorMethod) (*types.TwoFactorChallengeResponse, error) {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+
+	challenge := &types.TwoFactorChallenge{
+		UserID:    userID,
+		TokenHash: HashRefreshToken(token),
+		Method:    method,
+		ExpiresAt: time.Now().Add(challengeTTL),
+	}
+
+	if err := s.store.CreateTwoFactorChallenge(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("failed to create two-factor challenge: %w", err)
+	}
+
+	return &types.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresAt:         challenge.ExpiresAt,
+	}, nil
+}
+
+// VerifyChallenge checks a TOTP or recovery code against a pending challenge
+// and returns the user it was issued for. Each challenge allows a limited number
+// of attempts and can only be exchanged once.
+func (s *TwoFactorService) VerifyChallenge(ctx context.Context, challengeToken, code string) (string, error) {
+	challenge, err := s.store.GetTwoFactorChallenge(ctx, HashRefreshToken(challengeToken))
+	if err != nil {
+		return "", err
+	}
+
+	if challenge.ConsumedAt != nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= maxChallengeAttempts {
+		return "", types.ErrInvalidChallenge
+	}
+
+	if err := s.verifyCode(ctx, challenge.UserID, code); err != nil {
+		if err == types.ErrInvalidTwoFactorCode {
+			if _, incErr := s.store.IncrementTwoFactorChallengeAttempts(ctx, challenge.ID); incErr != nil {
+				return "", incErr
+			}
+		}
+		return "", err
+	}
+
+	consumed, err := s.store.ConsumeTwoFactorChallenge(ctx, challenge.ID)
+	if err != nil {
+		return "", err
+	}
+	if !consumed {
+		return "", types.ErrInvalidChallenge
+	}
+
+	return challenge.UserID, nil
+}
+
+// verifyCode accepts either a current TOTP code or an unused recovery code for
+// a user with confirmed two-factor.
+func (s *TwoFactorService) verifyCode(ctx context.Context, userID, code string) error {
+	settings, err := s.store.GetTwoFactorSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if settings.ConfirmedAt == nil {
+		return types.ErrTwoFactorNotEnabled
+	}
+
+	secret, err := decryptSecret(s.key, settings.EncryptedSecret)
+	if err != nil {
+		return err
+	}
+
+	if step, ok := ValidateTOTPCode(secret, code, time.Now()); ok {
+		fresh, err := s.store.UpdateTwoFactorLastUsedStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return types.ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.store.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return types.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := s.store.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code such as "k3md-7qxa", grouped for readability.
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, recoveryCodeByteCount)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+
+	encoded := strings.ToLower(totpEncoding.EncodeToString(raw))
+	return encoded[:4] + "-" + encoded[4:], nil
+}
+
+// hashRecoveryCode normalises user input so case, spaces and dashes don't matter.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(code)
+	normalized = strings.NewReplacer(" ", "", "-", "").Replace(normalized)
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// DeleteAccountRequest starts the deletion grace period. Accounts that have a
+// password must confirm it; OAuth-only accounts may omit it.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type AccountDeletionResponse struct {
+	Message      string    `json:"message"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
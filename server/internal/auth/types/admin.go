@@ -0,0 +1,50 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AdminAction string
+
+const (
+	AdminActionChangeRole         AdminAction = "change_role"
+	AdminActionSuspend            AdminAction = "suspend"
+	AdminActionReactivate         AdminAction = "reactivate"
+	AdminActionForcePasswordReset AdminAction = "force_password_reset"
+	AdminActionRevokeSessions     AdminAction = "revoke_sessions"
+	AdminActionApproveCoach       AdminAction = "approve_coach_application"
+	AdminActionRejectCoach        AdminAction = "reject_coach_application"
+)
+
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+)
+
+type AdminUserFilter struct {
+	Search   string
+	Role     UserRole
+	Status   UserStatus
+	Page     int
+	PageSize int
+}
+
+type AdminUserListResponse struct {
+	Users    []User `json:"users"`
+	Total    int    `json:"total"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
+
+type AdminAuditEntry struct {
+	ID           int64           `json:"id" db:"audit_id"`
+	AdminID      string          `json:"admin_id" db:"admin_id"`
+	Action       AdminAction     `json:"action" db:"action"`
+	TargetUserID string          `json:"target_user_id,omitempty" db:"target_user_id"`
+	Details      json.RawMessage `json:"details" db:"details"`
+	IPAddress    string          `json:"ip_address,omitempty" db:"ip_address"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,50 @@
+package types
+
+import "time"
+
+// EmailTokenPurpose tells apart the one-time links sent by email. They share
+// the verification_tokens table and each purpose allows one live token per address.
+type EmailTokenPurpose string
+
+const (
+	EmailTokenVerification      EmailTokenPurpose = "email_verification"
+	EmailTokenMagicLink         EmailTokenPurpose = "magic_link"
+	EmailTokenEmailChange       EmailTokenPurpose = "email_change"
+	EmailTokenEmailChangeRevert EmailTokenPurpose = "email_change_revert"
+)
+
+// EmailToken is a single-use emailed link. Only the token's hash is stored.
+type EmailToken struct {
+	ID            string            `json:"id" db:"id"`
+	Purpose       EmailTokenPurpose `json:"purpose" db:"purpose"`
+	UserID        string            `json:"user_id" db:"user_id"`
+	Email         string            `json:"email" db:"email"`
+	NewEmail      string            `json:"new_email,omitempty" db:"new_email"`
+	TokenHash     string            `json:"-" db:"token"`
+	CodeChallenge string            `json:"-" db:"code_challenge"`
+	ExpiresAt     time.Time         `json:"expires_at" db:"expires_at"`
+}
+
+// MagicLinkRequest asks for a sign-in link. Mobile clients send a PKCE S256
+// challenge so the link only works together with the verifier they kept.
+type MagicLinkRequest struct {
+	Email               string `json:"email" validate:"required,email"`
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+}
+
+type VerifyMagicLinkRequest struct {
+	Token        string `json:"token" validate:"required"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
+}
+
+// EmailChangeRequest starts an address change. Accounts that have a password
+// must confirm it; OAuth-only accounts may omit it.
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+type EmailTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,37 @@
+package types
+
+import "time"
+
+// LoginLockout tracks consecutive failed password attempts for one account.
+type LoginLockout struct {
+	UserID         string     `json:"user_id" db:"user_id"`
+	FailedAttempts int        `json:"failed_attempts" db:"failed_attempts"`
+	LockoutLevel   int        `json:"lockout_level" db:"lockout_level"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	LastFailedAt   *time.Time `json:"last_failed_at,omitempty" db:"last_failed_at"`
+}
+
+// LoginEvent records a successful sign-in and whether it looked unfamiliar.
+type LoginEvent struct {
+	ID                int64     `json:"id" db:"event_id"`
+	UserID            string    `json:"user_id" db:"user_id"`
+	DeviceFingerprint string    `json:"-" db:"device_fingerprint"`
+	DeviceName        string    `json:"device_name" db:"device_name"`
+	UserAgent         string    `json:"user_agent" db:"user_agent"`
+	IPAddress         string    `json:"ip_address,omitempty" db:"ip_address"`
+	CountryCode       string    `json:"country_code,omitempty" db:"country_code"`
+	NewDevice         bool      `json:"new_device" db:"new_device"`
+	NewCountry        bool      `json:"new_country" db:"new_country"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// LoginFamiliarity compares a login against the user's earlier logins.
+type LoginFamiliarity struct {
+	HasHistory   bool
+	KnownDevice  bool
+	KnownCountry bool
+}
+
+type ReportUnrecognizedLoginRequest struct {
+	Token string `json:"token" validate:"required"`
+}
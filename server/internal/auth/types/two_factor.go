@@ -0,0 +1,72 @@
+package types
+
+import "time"
+
+type TwoFactorMethod string
+
+const (
+	TwoFactorMethodPassword          TwoFactorMethod = "password"
+	TwoFactorMethodOAuth             TwoFactorMethod = "oauth"
+	TwoFactorMethodMagicLink         TwoFactorMethod = "magic_link"
+	TwoFactorMethodEmailVerification TwoFactorMethod = "email_verification"
+)
+
+type TwoFactorSettings struct {
+	UserID          string     `json:"user_id" db:"user_id"`
+	EncryptedSecret string     `json:"-" db:"encrypted_secret"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	LastUsedStep    int64      `json:"-" db:"last_used_step"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type TwoFactorChallenge struct {
+	ID         int64           `json:"id" db:"challenge_id"`
+	UserID     string          `json:"user_id" db:"user_id"`
+	TokenHash  string          `json:"-" db:"token_hash"`
+	Method     TwoFactorMethod `json:"method" db:"method"`
+	Attempts   int             `json:"attempts" db:"attempts"`
+	ExpiresAt  time.Time       `json:"expires_at" db:"expires_at"`
+	ConsumedAt *time.Time      `json:"consumed_at,omitempty" db:"consumed_at"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallengeResponse replaces the token pair when the password or
+// OAuth step succeeded but the account still needs its second factor.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+var (
+	ErrTwoFactorAlreadyEnabled = AuthError{Code: "TWO_FACTOR_ALREADY_ENABLED", Message: "Two-factor authentication is already enabled"}
+	ErrTwoFactorNotEnabled     = AuthError{Code: "TWO_FACTOR_NOT_ENABLED", Message: "Two-factor authentication is not enabled"}
+	ErrTwoFactorSetupRequired  = AuthError{Code: "TWO_FACTOR_SETUP_REQUIRED", Message: "Start two-factor setup before confirming it"}
+	ErrInvalidTwoFactorCode    = AuthError{Code: "INVALID_TWO_FACTOR_CODE", Message: "Invalid two-factor code"}
+	ErrInvalidChallenge        = AuthError{Code: "INVALID_TWO_FACTOR_CHALLENGE", Message: "Invalid or expired two-factor challenge"}
+)
@@ -14,19 +14,23 @@ const (
 )
 
 type User struct {
-	ID                 string     `json:"id" db:"id"`
-	Username           string     `json:"username" db:"username"`
-	Name               string     `json:"name" db:"name"`
-	Bio                string     `json:"bio" db:"bio"`
-	Email              string     `json:"email" db:"email"`
-	EmailVerified      *time.Time `json:"email_verified" db:"email_verified"`
-	Image              string     `json:"image" db:"image"`
-	Password           string     `json:"-" db:"password"`
-	PasswordHash       string     `json:"-" db:"password_hash"`
-	Role               UserRole   `json:"role" db:"role"`
-	IsTwoFactorEnabled bool       `json:"is_two_factor_enabled" db:"is_two_factor_enabled"`
-	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+	ID                    string     `json:"id" db:"id"`
+	Username              string     `json:"username" db:"username"`
+	Name                  string     `json:"name" db:"name"`
+	Bio                   string     `json:"bio" db:"bio"`
+	Email                 string     `json:"email" db:"email"`
+	EmailVerified         *time.Time `json:"email_verified" db:"email_verified"`
+	Image                 string     `json:"image" db:"image"`
+	Password              string     `json:"-" db:"password"`
+	PasswordHash          string     `json:"-" db:"password_hash"`
+	Role                  UserRole   `json:"role" db:"role"`
+	IsTwoFactorEnabled    bool       `json:"is_two_factor_enabled" db:"is_two_factor_enabled"`
+	SuspendedAt           *time.Time `json:"suspended_at,omitempty" db:"suspended_at"`
+	PasswordResetRequired bool       `json:"password_reset_required" db:"password_reset_required"`
+	DeletionScheduledFor  *time.Time `json:"deletion_scheduled_for,omitempty" db:"deletion_scheduled_for"`
+	DeletedAt             *time.Time `json:"-" db:"deleted_at"`
+	CreatedAt             time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type UserResponse struct {
@@ -68,20 +72,17 @@ type Account struct {
 	SessionState      string `json:"session_state" db:"session_state"`
 }
 
-type OAuthProvider struct {
-	Name         string   `json:"name"`
-	ClientID     string   `json:"client_id"`
-	ClientSecret string   `json:"-"`
-	RedirectURI  string   `json:"redirect_uri"`
-	AuthURL      string   `json:"auth_url"`
-	TokenURL     string   `json:"token_url"`
-	UserInfoURL  string   `json:"user_info_url"`
-	Scopes       []string `json:"scopes"`
-	SupportsPKCE bool     `json:"supports_pkce"`
+// OAuthProviderInfo describes an enabled sign-in provider for clients
+// rendering sign-in buttons.
+type OAuthProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Web         bool   `json:"web"`
+	Mobile      bool   `json:"mobile"`
 }
 
 type OAuthAuthRequest struct {
-	Provider    string `json:"provider" validate:"required,oneof=google github facebook"`
+	Provider    string `json:"provider" validate:"required"`
 	RedirectURL string `json:"redirect_url,omitempty"`
 }
 
@@ -90,11 +91,15 @@ type OAuthCallbackRequest struct {
 	State string `json:"state" validate:"required"`
 }
 
+// OAuthPKCECallbackRequest completes native sign-in. CodeVerifier is required
+// for providers that support PKCE. Name is what the app received from the
+// provider when the ID token carries none, as with Sign in with Apple.
 type OAuthPKCECallbackRequest struct {
 	Code         string `json:"code" validate:"required"`
-	CodeVerifier string `json:"code_verifier" validate:"required"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
 	State        string `json:"state,omitempty"`
 	RedirectURI  string `json:"redirect_uri,omitempty"`
+	Name         string `json:"name,omitempty"`
 }
 
 type OAuthUserInfo struct {
@@ -113,12 +118,14 @@ type LinkAccountRequest struct {
 }
 
 type OAuthState struct {
-	ID          string    `json:"id" db:"id"`
-	State       string    `json:"state" db:"state"`
-	Provider    string    `json:"provider" db:"provider"`
-	RedirectURL string    `json:"redirect_url" db:"redirect_url"`
-	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID           string    `json:"id" db:"id"`
+	State        string    `json:"state" db:"state"`
+	Provider     string    `json:"provider" db:"provider"`
+	RedirectURL  string    `json:"redirect_url" db:"redirect_url"`
+	Nonce        string    `json:"-" db:"nonce"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
 type LoginRequest struct {
@@ -126,12 +133,15 @@ type LoginRequest struct {
 	Password   string `json:"password" validate:"required,min=8"`
 }
 
+// RegisterRequest still accepts a role for older app versions, but every
+// signup is created as a client; coaches are promoted through a reviewed
+// coach application.
 type RegisterRequest struct {
 	Username string   `json:"username" validate:"required,min=3,max=50"`
 	Email    string   `json:"email" validate:"required,email"`
 	Password string   `json:"password" validate:"required,min=8"`
 	Name     string   `json:"name" validate:"max=100"`
-	Role     UserRole `json:"role" validate:"omitempty,oneof=user coach client"`
+	Role     UserRole `json:"role" validate:"omitempty,oneof=user client"`
 }
 
 type LoginResponse struct {
@@ -162,6 +172,7 @@ type UpdateUserRequest struct {
 	Name     *string `json:"name" validate:"omitempty,max=100"`
 	Bio      *string `json:"bio"`
 	Image    *string `json:"image"`
+	Timezone *string `json:"timezone"`
 }
 
 type ChangePasswordRequest struct {
@@ -170,7 +181,7 @@ type ChangePasswordRequest struct {
 }
 
 type UpdateRoleRequest struct {
-	Role UserRole `json:"role" validate:"required,oneof=user coach client"`
+	Role UserRole `json:"role" validate:"required,oneof=user coach"`
 }
 
 type ForgotPasswordRequest struct {
@@ -221,6 +232,32 @@ type RefreshToken struct {
 	RevokedAt      *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
 	UserAgent      string     `json:"user_agent,omitempty" db:"user_agent"`
 	IPAddress      string     `json:"ip_address,omitempty" db:"ip_address"`
+	FamilyID       string     `json:"family_id" db:"family_id"`
+	DeviceName     string     `json:"device_name,omitempty" db:"device_name"`
+}
+
+// DeviceInfo describes the client a refresh token is issued to.
+type DeviceInfo struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
+
+// Session is one signed-in device, i.e. a refresh-token family. Its ID stays
+// the same across token rotations.
+type Session struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionListResponse struct {
+	Sessions []Session `json:"sessions"`
 }
 
 type TokenPair struct {
@@ -283,11 +320,20 @@ var (
 	ErrEmailAlreadyVerified      = AuthError{Code: "EMAIL_ALREADY_VERIFIED", Message: "Email is already verified"}
 	ErrVerificationTokenNotFound = AuthError{Code: "VERIFICATION_TOKEN_NOT_FOUND", Message: "Verification token not found"}
 	ErrVerificationTokenExpired  = AuthError{Code: "VERIFICATION_TOKEN_EXPIRED", Message: "Verification token has expired"}
+	ErrEmailLinkInvalid          = AuthError{Code: "EMAIL_LINK_INVALID", Message: "This link is invalid, expired or has already been used"}
+	ErrInvalidCodeVerifier       = AuthError{Code: "INVALID_CODE_VERIFIER", Message: "Code verifier does not match the code challenge"}
+	ErrInvalidCodeChallenge      = AuthError{Code: "INVALID_CODE_CHALLENGE", Message: "Code challenge must be an S256 PKCE challenge"}
+	ErrEmailUnchanged            = AuthError{Code: "EMAIL_UNCHANGED", Message: "New email is the same as the current email"}
 
-	ErrAccountLocked        = AuthError{Code: "ACCOUNT_LOCKED", Message: "Account is temporarily locked"}
-	ErrAccountDisabled      = AuthError{Code: "ACCOUNT_DISABLED", Message: "Account has been disabled"}
-	ErrPasswordTooWeak      = AuthError{Code: "PASSWORD_TOO_WEAK", Message: "Password does not meet security requirements"}
-	ErrPasswordRecentlyUsed = AuthError{Code: "PASSWORD_RECENTLY_USED", Message: "Password was recently used"}
+	ErrAccountLocked         = AuthError{Code: "ACCOUNT_LOCKED", Message: "Account is temporarily locked"}
+	ErrAccountDisabled       = AuthError{Code: "ACCOUNT_DISABLED", Message: "Account has been disabled"}
+	ErrPasswordResetRequired = AuthError{Code: "PASSWORD_RESET_REQUIRED", Message: "Password must be reset before signing in; check your email for a reset link"}
+	ErrAccountDeleted        = AuthError{Code: "ACCOUNT_DELETED", Message: "Account has been deleted"}
+	ErrPasswordRequired      = AuthError{Code: "PASSWORD_REQUIRED", Message: "Enter your current password to continue"}
+	ErrPasswordTooWeak       = AuthError{Code: "PASSWORD_TOO_WEAK", Message: "Password does not meet security requirements"}
+	ErrPasswordRecentlyUsed  = AuthError{Code: "PASSWORD_RECENTLY_USED", Message: "Password was recently used"}
+
+	ErrSecurityAlertTokenInvalid = AuthError{Code: "SECURITY_ALERT_TOKEN_INVALID", Message: "This link is invalid, expired or has already been used"}
 
 	ErrTooManyAttempts    = AuthError{Code: "TOO_MANY_ATTEMPTS", Message: "Too many failed attempts, please try again later"}
 	ErrSuspiciousActivity = AuthError{Code: "SUSPICIOUS_ACTIVITY", Message: "Suspicious activity detected"}
@@ -303,6 +349,7 @@ var (
 	ErrRefreshTokenNotFound = AuthError{Code: "REFRESH_TOKEN_NOT_FOUND", Message: "Refresh token not found"}
 	ErrRefreshTokenExpired  = AuthError{Code: "REFRESH_TOKEN_EXPIRED", Message: "Refresh token has expired"}
 	ErrInvalidRefreshToken  = AuthError{Code: "INVALID_REFRESH_TOKEN", Message: "Invalid or expired refresh token"}
+	ErrRefreshTokenReused   = AuthError{Code: "REFRESH_TOKEN_REUSED", Message: "Refresh token was already used; the session has been signed out"}
 
 	ErrPasswordResetTokenNotFound = AuthError{Code: "PASSWORD_RESET_TOKEN_NOT_FOUND", Message: "Password reset token not found"}
 	ErrPasswordResetTokenExpired  = AuthError{Code: "PASSWORD_RESET_TOKEN_EXPIRED", Message: "Password reset token has expired"}
@@ -310,6 +357,8 @@ var (
 	ErrSamePassword               = AuthError{Code: "SAME_PASSWORD", Message: "New password cannot be the same as current password"}
 	ErrIncorrectCurrentPassword   = AuthError{Code: "INCORRECT_CURRENT_PASSWORD", Message: "Current password is incorrect"}
 
+	ErrCoachApplicationRequired = AuthError{Code: "COACH_APPLICATION_REQUIRED", Message: "Coach access requires an approved coach application"}
+
 	ErrInvalidInput         = AuthError{Code: "INVALID_INPUT", Message: "Invalid input provided"}
 	ErrMissingRequiredField = AuthError{Code: "MISSING_REQUIRED_FIELD", Message: "Required field is missing"}
 	ErrUsernameTaken        = AuthError{Code: "USERNAME_TAKEN", Message: "Username is already taken"}
@@ -320,6 +369,8 @@ var (
 	ErrProviderError        = AuthError{Code: "PROVIDER_ERROR", Message: "Authentication provider error"}
 	ErrProviderNotSupported = AuthError{Code: "PROVIDER_NOT_SUPPORTED", Message: "Authentication provider not supported"}
 	ErrAccountNotLinked     = AuthError{Code: "ACCOUNT_NOT_LINKED", Message: "Account is not linked to this provider"}
+	ErrAccountAlreadyLinked = AuthError{Code: "ACCOUNT_ALREADY_LINKED", Message: "This provider account is already linked to another user"}
+	ErrInvalidOAuthState    = AuthError{Code: "INVALID_OAUTH_STATE", Message: "Sign-in request is invalid or has expired, please try again"}
 
 	ErrInternalServerError = AuthError{Code: "INTERNAL_SERVER_ERROR", Message: "Internal server error"}
 	ErrServiceUnavailable  = AuthError{Code: "SERVICE_UNAVAILABLE", Message: "Service is currently unavailable"}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/tdmdh/fit-up-server/internal/billing/services"
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+const (
+	maxWebhookBody    = 1 << 20
+	defaultPageLimit  = 20
+	maxPageLimit      = 100
+	webhookSignHeader = "X-Billing-Signature"
+)
+
+type BillingHandler struct {
+	packages      services.PackageService
+	subscriptions services.SubscriptionService
+}
+
+func NewBillingHandler(packages services.PackageService, subscriptions services.SubscriptionService) *BillingHandler {
+	return &BillingHandler{
+		packages:      packages,
+		subscriptions: subscriptions,
+	}
+}
+
+func respondWithBillingError(w http.ResponseWriter, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case errors.Is(err, types.ErrPackageNotFound), errors.Is(err, types.ErrSubscriptionNotFound),
+		errors.Is(err, types.ErrUnknownProvider):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, types.ErrSubscriptionExists), errors.Is(err, types.ErrSubscriptionCancelled),
+		errors.Is(err, types.ErrPackageInactive):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, types.ErrSubscribeToSelf), errors.Is(err, types.ErrInvalidStatus),
+		errors.Is(err, types.ErrInvalidWebhookPayload):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrInvalidWebhookSignature):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	default:
+		log.Printf("Billing request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Billing request failed")
+	}
+}
+
+func parseID(r *http.Request, param string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// CreatePackage handles POST /billing/packages
+func (h *BillingHandler) CreatePackage(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || coachID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.CreatePackageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	pkg, err := h.packages.CreatePackage(r.Context(), coachID, &req)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, pkg)
+}
+
+// ListMyPackages handles GET /billing/packages, including archived packages.
+func (h *BillingHandler) ListMyPackages(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || coachID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	packages, err := h.packages.ListMyPackages(r.Context(), coachID)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"packages": packages,
+	})
+}
+
+// UpdatePackage handles PATCH /billing/packages/{packageID}
+func (h *BillingHandler) UpdatePackage(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || coachID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	packageID, ok := parseID(r, "packageID")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid package ID")
+		return
+	}
+
+	var req types.UpdatePackageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	pkg, err := h.packages.UpdatePackage(r.Context(), coachID, packageID, &req)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pkg)
+}
+
+// ArchivePackage handles DELETE /billing/packages/{packageID}
+func (h *BillingHandler) ArchivePackage(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || coachID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	packageID, ok := parseID(r, "packageID")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid package ID")
+		return
+	}
+
+	if err := h.packages.ArchivePackage(r.Context(), coachID, packageID); err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSubscribers handles GET /billing/subscribers?status=&page=&limit=
+func (h *BillingHandler) ListSubscribers(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || coachID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var status *types.SubscriptionStatus
+	if value := r.URL.Query().Get("status"); value != "" {
+		s := types.SubscriptionStatus(value)
+		status = &s
+	}
+
+	page, limit := parsePagination(r)
+	subs, err := h.subscriptions.ListSubscribers(r.Context(), coachID, status, limit, (page-1)*limit)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"subscriptions": subs,
+		"page":          page,
+		"limit":         limit,
+	})
+}
+
+// ListCoachPackages handles GET /billing/coaches/{coachID}/packages
+func (h *BillingHandler) ListCoachPackages(w http.ResponseWriter, r *http.Request) {
+	packages, err := h.packages.ListCoachPackages(r.Context(), chi.URLParam(r, "coachID"))
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"packages": packages,
+	})
+}
+
+// Subscribe handles POST /billing/subscriptions
+func (h *BillingHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.SubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	sub, err := h.subscriptions.Subscribe(r.Context(), userID, &req)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, sub)
+}
+
+// ListMySubscriptions handles GET /billing/subscriptions
+func (h *BillingHandler) ListMySubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	subs, err := h.subscriptions.ListMySubscriptions(r.Context(), userID)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"subscriptions": subs,
+	})
+}
+
+// GetSubscription handles GET /billing/subscriptions/{subscriptionID} for the
+// client and the coach.
+func (h *BillingHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	subscriptionID, ok := parseID(r, "subscriptionID")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	sub, err := h.subscriptions.GetSubscription(r.Context(), userID, subscriptionID)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, sub)
+}
+
+// CancelSubscription handles POST /billing/subscriptions/{subscriptionID}/cancel
+func (h *BillingHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	subscriptionID, ok := parseID(r, "subscriptionID")
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	sub, err := h.subscriptions.CancelSubscription(r.Context(), userID, subscriptionID)
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, sub)
+}
+
+// HandleWebhook handles POST /billing/webhooks/{provider}. The provider signs
+// the raw body; there is no user session.
+func (h *BillingHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook body")
+		return
+	}
+
+	err = h.subscriptions.HandleWebhook(r.Context(), chi.URLParam(r, "provider"), payload, r.Header.Get(webhookSignHeader))
+	if err != nil {
+		respondWithBillingError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parsePagination(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return page, limit
+}
+
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithJSON(w, code, map[string]string{"error": message})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+func (h *BillingHandler) RegisterRoutes(r chi.Router, authMW *middleware.AuthMiddleware) {
+	r.Route("/billing", func(r chi.Router) {
+		r.Post("/webhooks/{provider}", h.HandleWebhook)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authMW.RequireJWTAuth())
+
+			r.Get("/coaches/{coachID}/packages", h.ListCoachPackages)
+
+			r.Get("/subscriptions", h.ListMySubscriptions)
+			r.Post("/subscriptions", h.Subscribe)
+			r.Get("/subscriptions/{subscriptionID}", h.GetSubscription)
+			r.Post("/subscriptions/{subscriptionID}/cancel", h.CancelSubscription)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authMW.RequireJWTAuth())
+			r.Use(authMW.RequireCoachRole())
+
+			r.Get("/packages", h.ListMyPackages)
+			r.Post("/packages", h.CreatePackage)
+			r.Patch("/packages/{packageID}", h.UpdatePackage)
+			r.Delete("/packages/{packageID}", h.ArchivePackage)
+			r.Get("/subscribers", h.ListSubscribers)
+		})
+	})
+}
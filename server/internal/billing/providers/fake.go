@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+)
+
+// FakeProvider bills nothing. It records subscriptions in memory and accepts
+// webhooks signed with a shared secret, so the whole flow can be driven in
+// development and tests by posting events signed with Sign. Without a secret
+// every webhook is rejected.
+type FakeProvider struct {
+	secret []byte
+	now    func() time.Time
+
+	mu            sync.Mutex
+	subscriptions map[string]*types.ProviderSubscription
+	cancelled     map[string]bool
+}
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret:        []byte(secret),
+		now:           time.Now,
+		subscriptions: make(map[string]*types.ProviderSubscription),
+		cancelled:     make(map[string]bool),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateSubscription(ctx context.Context, req types.ProviderSubscriptionRequest) (*types.ProviderSubscription, error) {
+	now := p.now().UTC()
+	sub := &types.ProviderSubscription{
+		ID:                 "fake_sub_" + uuid.NewString(),
+		Status:             types.SubscriptionActive,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now.AddDate(0, 1, 0),
+	}
+	if req.TrialDays > 0 {
+		trialEnd := now.AddDate(0, 0, req.TrialDays)
+		sub.Status = types.SubscriptionTrialing
+		sub.TrialEndsAt = &trialEnd
+		sub.CurrentPeriodEnd = trialEnd
+	}
+
+	p.mu.Lock()
+	p.subscriptions[sub.ID] = sub
+	p.mu.Unlock()
+
+	copied := *sub
+	return &copied, nil
+}
+
+func (p *FakeProvider) CancelSubscription(ctx context.Context, providerSubscriptionID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.subscriptions[providerSubscriptionID]; !ok {
+		return fmt.Errorf("fake provider: %w", types.ErrSubscriptionNotFound)
+	}
+	p.cancelled[providerSubscriptionID] = true
+	return nil
+}
+
+// Cancelled reports whether CancelSubscription was called for the subscription.
+func (p *FakeProvider) Cancelled(providerSubscriptionID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cancelled[providerSubscriptionID]
+}
+
+// Sign returns the hex HMAC-SHA256 signature ParseWebhook expects for payload.
+func (p *FakeProvider) Sign(payload []byte) string {
+	return hex.EncodeToString(p.mac(payload))
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, signature string) (*types.WebhookEvent, error) {
+	if len(p.secret) == 0 {
+		return nil, types.ErrInvalidWebhookSignature
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.mac(payload)) {
+		return nil, types.ErrInvalidWebhookSignature
+	}
+
+	var event types.WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, types.ErrInvalidWebhookPayload
+	}
+	if event.ID == "" || event.ProviderSubscriptionID == "" {
+		return nil, types.ErrInvalidWebhookPayload
+	}
+	switch event.Type {
+	case types.EventPaymentSucceeded, types.EventPaymentFailed, types.EventSubscriptionCancelled:
+	default:
+		return nil, types.ErrInvalidWebhookPayload
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = p.now().UTC()
+	}
+	return &event, nil
+}
+
+func (p *FakeProvider) mac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
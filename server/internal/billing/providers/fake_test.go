@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+func TestFakeProviderCreateSubscription(t *testing.T) {
+	p := NewFakeProvider("secret")
+	now := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	paid, err := p.CreateSubscription(context.Background(), types.ProviderSubscriptionRequest{PriceCents: 4900})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid.Status != types.SubscriptionActive || paid.TrialEndsAt != nil || !paid.CurrentPeriodEnd.Equal(now.AddDate(0, 1, 0)) {
+		t.Errorf("unexpected paid subscription: %+v", paid)
+	}
+
+	trial, err := p.CreateSubscription(context.Background(), types.ProviderSubscriptionRequest{TrialDays: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trial.Status != types.SubscriptionTrialing || trial.TrialEndsAt == nil || !trial.CurrentPeriodEnd.Equal(now.AddDate(0, 0, 14)) {
+		t.Errorf("unexpected trial subscription: %+v", trial)
+	}
+
+	if err := p.CancelSubscription(context.Background(), trial.ID); err != nil || !p.Cancelled(trial.ID) {
+		t.Errorf("cancel failed: %v", err)
+	}
+	if err := p.CancelSubscription(context.Background(), "fake_sub_unknown"); !errors.Is(err, types.ErrSubscriptionNotFound) {
+		t.Errorf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+}
+
+func TestFakeProviderParseWebhook(t *testing.T) {
+	p := NewFakeProvider("secret")
+	payload := []byte(`{"id":"evt_1","type":"payment_failed","subscription_id":"fake_sub_1","occurred_at":"2024-05-02T10:00:00Z"}`)
+
+	event, err := p.ParseWebhook(payload, p.Sign(payload))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.ID != "evt_1" || event.Type != types.EventPaymentFailed || event.ProviderSubscriptionID != "fake_sub_1" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	tampered := []byte(`{"id":"evt_1","type":"payment_succeeded","subscription_id":"fake_sub_1"}`)
+	if _, err := p.ParseWebhook(tampered, p.Sign(payload)); err != types.ErrInvalidWebhookSignature {
+		t.Errorf("tampered payload: expected ErrInvalidWebhookSignature, got %v", err)
+	}
+	if _, err := NewFakeProvider("other").ParseWebhook(payload, p.Sign(payload)); err != types.ErrInvalidWebhookSignature {
+		t.Errorf("wrong secret: expected ErrInvalidWebhookSignature, got %v", err)
+	}
+	if _, err := NewFakeProvider("").ParseWebhook(payload, NewFakeProvider("").Sign(payload)); err != types.ErrInvalidWebhookSignature {
+		t.Errorf("missing secret: expected every webhook to be rejected, got %v", err)
+	}
+
+	unknown := []byte(`{"id":"evt_2","type":"refund","subscription_id":"fake_sub_1"}`)
+	if _, err := p.ParseWebhook(unknown, p.Sign(unknown)); err != types.ErrInvalidWebhookPayload {
+		t.Errorf("unknown type: expected ErrInvalidWebhookPayload, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if p, err := New(config.BillingConfig{Provider: "fake", WebhookSecret: "s"}); err != nil || p.Name() != "fake" {
+		t.Errorf("expected the fake provider, got %v, %v", p, err)
+	}
+	if _, err := New(config.BillingConfig{Provider: "stripe"}); !errors.Is(err, types.ErrUnknownProvider) {
+		t.Errorf("expected ErrUnknownProvider, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package providers implements the payment providers coaching subscriptions
+// are billed through.
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+// PaymentProvider charges clients for coaching packages. Charges happen on the
+// provider's side; the outcome reaches us as signed webhook events.
+type PaymentProvider interface {
+	Name() string
+	// CreateSubscription starts billing, beginning with the package's trial if it has one.
+	CreateSubscription(ctx context.Context, req types.ProviderSubscriptionRequest) (*types.ProviderSubscription, error)
+	// CancelSubscription stops renewal; the client keeps access until the
+	// current period ends.
+	CancelSubscription(ctx context.Context, providerSubscriptionID string) error
+	// ParseWebhook verifies the signature and decodes the event.
+	ParseWebhook(payload []byte, signature string) (*types.WebhookEvent, error)
+}
+
+// New returns the provider selected by cfg.Provider.
+func New(cfg config.BillingConfig) (PaymentProvider, error) {
+	switch cfg.Provider {
+	case "fake":
+		return NewFakeProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("%w %q", types.ErrUnknownProvider, cfg.Provider)
+	}
+}
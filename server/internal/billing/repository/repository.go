@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+)
+
+type BillingRepo interface {
+	CreatePackage(ctx context.Context, pkg *types.CoachingPackage) error
+	UpdatePackage(ctx context.Context, pkg *types.CoachingPackage) error
+	GetPackage(ctx context.Context, packageID int64) (*types.CoachingPackage, error)
+	ListPackages(ctx context.Context, coachID string, activeOnly bool) ([]types.CoachingPackage, error)
+
+	CreateSubscription(ctx context.Context, sub *types.Subscription) error
+	GetSubscription(ctx context.Context, subscriptionID int64) (*types.Subscription, error)
+	GetSubscriptionByProviderID(ctx context.Context, provider, providerSubscriptionID string) (*types.Subscription, error)
+	GetLatestSubscription(ctx context.Context, coachID, clientID string) (*types.Subscription, error)
+	ListClientSubscriptions(ctx context.Context, clientID string) ([]types.Subscription, error)
+	ListCoachSubscriptions(ctx context.Context, coachID string, status *types.SubscriptionStatus, limit, offset int) ([]types.Subscription, error)
+	UpdateSubscription(ctx context.Context, sub *types.Subscription) error
+
+	// ApplyWebhookEvent records the event and saves sub in one transaction. It
+	// returns false without changing anything when the event was already applied.
+	ApplyWebhookEvent(ctx context.Context, provider, eventID string, sub *types.Subscription) (bool, error)
+	CancelLapsedSubscriptions(ctx context.Context, now time.Time, grace time.Duration) (int, error)
+}
+
+type Store struct {
+	db *pgxpool.Pool
+}
+
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+const packageColumns = `
+	p.package_id, p.coach_id, p.name, p.description, p.price_cents, p.currency,
+	p.trial_days, p.features, p.active, p.created_at, p.updated_at
+`
+
+const subscriptionColumns = `
+	s.subscription_id, s.package_id, s.coach_id, s.client_id, s.status, s.provider,
+	s.provider_subscription_id, s.trial_ends_at, s.current_period_start, s.current_period_end,
+	s.cancel_at_period_end, s.cancelled_at, s.created_at, s.updated_at
+`
+
+func packageDest(pkg *types.CoachingPackage) []interface{} {
+	return []interface{}{
+		&pkg.PackageID,
+		&pkg.CoachID,
+		&pkg.Name,
+		&pkg.Description,
+		&pkg.PriceCents,
+		&pkg.Currency,
+		&pkg.TrialDays,
+		&pkg.Features,
+		&pkg.Active,
+		&pkg.CreatedAt,
+		&pkg.UpdatedAt,
+	}
+}
+
+func scanPackage(row pgx.Row) (*types.CoachingPackage, error) {
+	var pkg types.CoachingPackage
+	if err := row.Scan(packageDest(&pkg)...); err != nil {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// scanSubscription reads subscriptionColumns followed by packageColumns.
+func scanSubscription(row pgx.Row) (*types.Subscription, error) {
+	var sub types.Subscription
+	var pkg types.CoachingPackage
+	dest := []interface{}{
+		&sub.SubscriptionID,
+		&sub.PackageID,
+		&sub.CoachID,
+		&sub.ClientID,
+		&sub.Status,
+		&sub.Provider,
+		&sub.ProviderSubscriptionID,
+		&sub.TrialEndsAt,
+		&sub.CurrentPeriodStart,
+		&sub.CurrentPeriodEnd,
+		&sub.CancelAtPeriodEnd,
+		&sub.CancelledAt,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, packageDest(&pkg)...)...); err != nil {
+		return nil, err
+	}
+	sub.Package = &pkg
+	return &sub, nil
+}
+
+const subscriptionSelect = `
+	SELECT ` + subscriptionColumns + `, ` + packageColumns + `
+	FROM coaching_subscriptions s
+	JOIN coaching_packages p ON p.package_id = s.package_id
+`
+
+func (s *Store) CreatePackage(ctx context.Context, pkg *types.CoachingPackage) error {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO coaching_packages (coach_id, name, description, price_cents, currency, trial_days, features)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING package_id, active, created_at, updated_at`,
+		pkg.CoachID, pkg.Name, pkg.Description, pkg.PriceCents, pkg.Currency, pkg.TrialDays, pkg.Features,
+	).Scan(&pkg.PackageID, &pkg.Active, &pkg.CreatedAt, &pkg.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create coaching package: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) UpdatePackage(ctx context.Context, pkg *types.CoachingPackage) error {
+	err := s.db.QueryRow(ctx, `
+		UPDATE coaching_packages
+		SET name = $3, description = $4, price_cents = $5, trial_days = $6, features = $7, active = $8,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE package_id = $1 AND coach_id = $2
+		RETURNING updated_at`,
+		pkg.PackageID, pkg.CoachID, pkg.Name, pkg.Description, pkg.PriceCents, pkg.TrialDays, pkg.Features, pkg.Active,
+	).Scan(&pkg.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return types.ErrPackageNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update coaching package: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetPackage(ctx context.Context, packageID int64) (*types.CoachingPackage, error) {
+	pkg, err := scanPackage(s.db.QueryRow(ctx, `SELECT `+packageColumns+` FROM coaching_packages p WHERE p.package_id = $1`, packageID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrPackageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coaching package: %w", err)
+	}
+	return pkg, nil
+}
+
+func (s *Store) ListPackages(ctx context.Context, coachID string, activeOnly bool) ([]types.CoachingPackage, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+packageColumns+`
+		FROM coaching_packages p
+		WHERE p.coach_id = $1 AND (p.active OR NOT $2)
+		ORDER BY p.active DESC, p.price_cents, p.package_id`,
+		coachID, activeOnly,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list coaching packages: %w", err)
+	}
+	defer rows.Close()
+
+	packages := []types.CoachingPackage{}
+	for rows.Next() {
+		pkg, err := scanPackage(rows)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, *pkg)
+	}
+	return packages, rows.Err()
+}
+
+func (s *Store) CreateSubscription(ctx context.Context, sub *types.Subscription) error {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO coaching_subscriptions (
+			package_id, coach_id, client_id, status, provider, provider_subscription_id,
+			trial_ends_at, current_period_start, current_period_end
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING subscription_id, created_at, updated_at`,
+		sub.PackageID, sub.CoachID, sub.ClientID, sub.Status, sub.Provider, sub.ProviderSubscriptionID,
+		sub.TrialEndsAt, sub.CurrentPeriodStart, sub.CurrentPeriodEnd,
+	).Scan(&sub.SubscriptionID, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return types.ErrSubscriptionExists
+		}
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) getSubscription(ctx context.Context, where string, args ...interface{}) (*types.Subscription, error) {
+	sub, err := scanSubscription(s.db.QueryRow(ctx, subscriptionSelect+where, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (s *Store) GetSubscription(ctx context.Context, subscriptionID int64) (*types.Subscription, error) {
+	return s.getSubscription(ctx, `WHERE s.subscription_id = $1`, subscriptionID)
+}
+
+func (s *Store) GetSubscriptionByProviderID(ctx context.Context, provider, providerSubscriptionID string) (*types.Subscription, error) {
+	return s.getSubscription(ctx, `WHERE s.provider = $1 AND s.provider_subscription_id = $2`, provider, providerSubscriptionID)
+}
+
+// GetLatestSubscription returns the pair's live subscription, or their most
+// recent one when none is live.
+func (s *Store) GetLatestSubscription(ctx context.Context, coachID, clientID string) (*types.Subscription, error) {
+	return s.getSubscription(ctx, `
+		WHERE s.coach_id = $1 AND s.client_id = $2
+		ORDER BY (s.status <> 'cancelled') DESC, s.created_at DESC, s.subscription_id DESC
+		LIMIT 1`,
+		coachID, clientID,
+	)
+}
+
+func (s *Store) ListClientSubscriptions(ctx context.Context, clientID string) ([]types.Subscription, error) {
+	return s.querySubscriptions(ctx, subscriptionSelect+`
+		WHERE s.client_id = $1
+		ORDER BY s.created_at DESC, s.subscription_id DESC`,
+		clientID,
+	)
+}
+
+func (s *Store) ListCoachSubscriptions(ctx context.Context, coachID string, status *types.SubscriptionStatus, limit, offset int) ([]types.Subscription, error) {
+	return s.querySubscriptions(ctx, subscriptionSelect+`
+		WHERE s.coach_id = $1 AND ($2::text IS NULL OR s.status = $2)
+		ORDER BY s.created_at DESC, s.subscription_id DESC
+		LIMIT $3 OFFSET $4`,
+		coachID, status, limit, offset,
+	)
+}
+
+func (s *Store) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]types.Subscription, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []types.Subscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+const updateSubscriptionQuery = `
+	UPDATE coaching_subscriptions
+	SET status = $2, trial_ends_at = $3, current_period_start = $4, current_period_end = $5,
+		cancel_at_period_end = $6, cancelled_at = $7, updated_at = CURRENT_TIMESTAMP
+	WHERE subscription_id = $1
+`
+
+func updateSubscriptionArgs(sub *types.Subscription) []interface{} {
+	return []interface{}{
+		sub.SubscriptionID, sub.Status, sub.TrialEndsAt, sub.CurrentPeriodStart, sub.CurrentPeriodEnd,
+		sub.CancelAtPeriodEnd, sub.CancelledAt,
+	}
+}
+
+func (s *Store) UpdateSubscription(ctx context.Context, sub *types.Subscription) error {
+	tag, err := s.db.Exec(ctx, updateSubscriptionQuery, updateSubscriptionArgs(sub)...)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (s *Store) ApplyWebhookEvent(ctx context.Context, provider, eventID string, sub *types.Subscription) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO coaching_billing_events (provider, event_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`,
+		provider, eventID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, updateSubscriptionQuery, updateSubscriptionArgs(sub)...); err != nil {
+		return false, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CancelLapsedSubscriptions ends live subscriptions whose period is over:
+// right away when the client cancelled, otherwise once the grace for a
+// missing renewal has passed too.
+func (s *Store) CancelLapsedSubscriptions(ctx context.Context, now time.Time, grace time.Duration) (int, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE coaching_subscriptions
+		SET status = 'cancelled', cancelled_at = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE status <> 'cancelled'
+		  AND (
+			(cancel_at_period_end AND current_period_end <= $1)
+			OR current_period_end <= $2
+		  )`,
+		now, now.Add(-grace),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel lapsed subscriptions: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
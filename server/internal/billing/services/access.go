@@ -0,0 +1,80 @@
+package services
+
+import (
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+)
+
+// renewalGrace is how long a client keeps access after the paid period ends
+// while the provider retries the payment or its webhook is delayed.
+const renewalGrace = 3 * 24 * time.Hour
+
+// grantsAccess reports whether sub still entitles the client to its package
+// at now. A subscription cancelled by the client runs to the end of the period
+// it was paid for; any other live subscription gets the renewal grace on top.
+func grantsAccess(sub *types.Subscription, now time.Time) bool {
+	switch sub.Status {
+	case types.SubscriptionTrialing, types.SubscriptionActive, types.SubscriptionPastDue:
+	default:
+		return false
+	}
+
+	if sub.CancelAtPeriodEnd {
+		return now.Before(sub.CurrentPeriodEnd)
+	}
+	return now.Before(sub.CurrentPeriodEnd.Add(renewalGrace))
+}
+
+// allowsFeature applies grantsAccess and the package's feature list. A nil
+// subscription means the pair never used in-app billing, so nothing is restricted.
+func allowsFeature(sub *types.Subscription, feature types.PackageFeature, now time.Time) bool {
+	if sub == nil {
+		return true
+	}
+	if !grantsAccess(sub, now) {
+		return false
+	}
+	return sub.Package != nil && sub.Package.HasFeature(feature)
+}
+
+// applyWebhookEvent returns sub as it stands after event. Cancelled
+// subscriptions are final and are returned unchanged.
+func applyWebhookEvent(sub types.Subscription, event *types.WebhookEvent) types.Subscription {
+	if sub.Status == types.SubscriptionCancelled {
+		return sub
+	}
+
+	switch event.Type {
+	case types.EventPaymentSucceeded:
+		sub.Status = types.SubscriptionActive
+		if event.PeriodStart != nil && event.PeriodEnd != nil {
+			sub.CurrentPeriodStart = *event.PeriodStart
+			sub.CurrentPeriodEnd = *event.PeriodEnd
+		} else {
+			sub.CurrentPeriodStart = sub.CurrentPeriodEnd
+			sub.CurrentPeriodEnd = sub.CurrentPeriodEnd.AddDate(0, 1, 0)
+		}
+	case types.EventPaymentFailed:
+		sub.Status = types.SubscriptionPastDue
+	case types.EventSubscriptionCancelled:
+		cancelledAt := event.OccurredAt
+		sub.Status = types.SubscriptionCancelled
+		sub.CancelledAt = &cancelledAt
+	}
+	return sub
+}
+
+// normalizeFeatures drops duplicates and never returns nil, so the column
+// always holds a JSON array.
+func normalizeFeatures(features []types.PackageFeature) []types.PackageFeature {
+	seen := make(map[types.PackageFeature]bool, len(features))
+	out := make([]types.PackageFeature, 0, len(features))
+	for _, f := range features {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
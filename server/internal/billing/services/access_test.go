@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+)
+
+func TestAllowsFeature(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	pkg := &types.CoachingPackage{Features: []types.PackageFeature{types.FeatureMessaging}}
+	sub := func(status types.SubscriptionStatus, periodEnd time.Time, cancelAtPeriodEnd bool) *types.Subscription {
+		return &types.Subscription{Status: status, CurrentPeriodEnd: periodEnd, CancelAtPeriodEnd: cancelAtPeriodEnd, Package: pkg}
+	}
+	later, earlier := now.AddDate(0, 0, 5), now.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name    string
+		sub     *types.Subscription
+		feature types.PackageFeature
+		want    bool
+	}{
+		{"no in-app subscription", nil, types.FeatureCustomPlans, true},
+		{"active", sub(types.SubscriptionActive, later, false), types.FeatureMessaging, true},
+		{"trialing", sub(types.SubscriptionTrialing, later, false), types.FeatureMessaging, true},
+		{"feature not in package", sub(types.SubscriptionActive, later, false), types.FeatureCustomPlans, false},
+		{"past due within grace", sub(types.SubscriptionPastDue, earlier, false), types.FeatureMessaging, true},
+		{"past due after grace", sub(types.SubscriptionPastDue, now.Add(-renewalGrace), false), types.FeatureMessaging, false},
+		{"active but renewal missing", sub(types.SubscriptionActive, now.Add(-renewalGrace-time.Hour), false), types.FeatureMessaging, false},
+		{"cancelled by client, period running", sub(types.SubscriptionActive, later, true), types.FeatureMessaging, true},
+		{"cancelled by client, period over", sub(types.SubscriptionActive, earlier, true), types.FeatureMessaging, false},
+		{"cancelled", sub(types.SubscriptionCancelled, later, false), types.FeatureMessaging, false},
+	}
+
+	for _, tt := range tests {
+		if got := allowsFeature(tt.sub, tt.feature, now); got != tt.want {
+			t.Errorf("%s: allowsFeature = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyWebhookEvent(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	base := types.Subscription{Status: types.SubscriptionTrialing, CurrentPeriodStart: start, CurrentPeriodEnd: end}
+
+	failed := applyWebhookEvent(base, &types.WebhookEvent{Type: types.EventPaymentFailed})
+	if failed.Status != types.SubscriptionPastDue || !failed.CurrentPeriodEnd.Equal(end) {
+		t.Errorf("failed payment should only mark the subscription past due: %+v", failed)
+	}
+
+	renewed := applyWebhookEvent(failed, &types.WebhookEvent{Type: types.EventPaymentSucceeded})
+	if renewed.Status != types.SubscriptionActive || !renewed.CurrentPeriodStart.Equal(end) || !renewed.CurrentPeriodEnd.Equal(end.AddDate(0, 1, 0)) {
+		t.Errorf("payment without period should advance one month: %+v", renewed)
+	}
+
+	periodStart, periodEnd := end.AddDate(0, 0, 2), end.AddDate(0, 1, 2)
+	explicit := applyWebhookEvent(base, &types.WebhookEvent{Type: types.EventPaymentSucceeded, PeriodStart: &periodStart, PeriodEnd: &periodEnd})
+	if !explicit.CurrentPeriodStart.Equal(periodStart) || !explicit.CurrentPeriodEnd.Equal(periodEnd) {
+		t.Errorf("provider period should be used when given: %+v", explicit)
+	}
+
+	occurredAt := start.AddDate(0, 0, 3)
+	cancelled := applyWebhookEvent(base, &types.WebhookEvent{Type: types.EventSubscriptionCancelled, OccurredAt: occurredAt})
+	if cancelled.Status != types.SubscriptionCancelled || cancelled.CancelledAt == nil || !cancelled.CancelledAt.Equal(occurredAt) {
+		t.Errorf("unexpected cancelled subscription: %+v", cancelled)
+	}
+
+	// A late payment event must not bring a cancelled subscription back
+	if again := applyWebhookEvent(cancelled, &types.WebhookEvent{Type: types.EventPaymentSucceeded}); again.Status != types.SubscriptionCancelled {
+		t.Errorf("cancelled subscription was reactivated: %+v", again)
+	}
+}
+
+func TestNormalizeFeatures(t *testing.T) {
+	if got := normalizeFeatures(nil); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil slice, got %#v", got)
+	}
+
+	got := normalizeFeatures([]types.PackageFeature{types.FeatureMessaging, types.FeatureCheckIns, types.FeatureMessaging})
+	if len(got) != 2 || got[0] != types.FeatureMessaging || got[1] != types.FeatureCheckIns {
+		t.Errorf("unexpected features %v", got)
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/tdmdh/fit-up-server/internal/billing/repository"
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+)
+
+const defaultCurrency = "EUR"
+
+type PackageService interface {
+	CreatePackage(ctx context.Context, coachID string, req *types.CreatePackageRequest) (*types.CoachingPackage, error)
+	UpdatePackage(ctx context.Context, coachID string, packageID int64, req *types.UpdatePackageRequest) (*types.CoachingPackage, error)
+	// ArchivePackage stops offering a package. Existing subscriptions carry on.
+	ArchivePackage(ctx context.Context, coachID string, packageID int64) error
+	ListMyPackages(ctx context.Context, coachID string) ([]types.CoachingPackage, error)
+	// ListCoachPackages returns the packages a coach currently offers to clients.
+	ListCoachPackages(ctx context.Context, coachID string) ([]types.CoachingPackage, error)
+}
+
+type packageService struct {
+	repo      repository.BillingRepo
+	validator *validator.Validate
+}
+
+func NewPackageService(repo repository.BillingRepo) PackageService {
+	return &packageService{
+		repo:      repo,
+		validator: validator.New(),
+	}
+}
+
+func (s *packageService) CreatePackage(ctx context.Context, coachID string, req *types.CreatePackageRequest) (*types.CoachingPackage, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	pkg := &types.CoachingPackage{
+		CoachID:     coachID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		PriceCents:  req.PriceCents,
+		Currency:    currency,
+		TrialDays:   req.TrialDays,
+		Features:    normalizeFeatures(req.Features),
+	}
+	if err := s.repo.CreatePackage(ctx, pkg); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// ownPackage returns the coach's package; other coaches' packages are reported
+// as not found.
+func (s *packageService) ownPackage(ctx context.Context, coachID string, packageID int64) (*types.CoachingPackage, error) {
+	pkg, err := s.repo.GetPackage(ctx, packageID)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.CoachID != coachID {
+		return nil, types.ErrPackageNotFound
+	}
+	return pkg, nil
+}
+
+func (s *packageService) UpdatePackage(ctx context.Context, coachID string, packageID int64, req *types.UpdatePackageRequest) (*types.CoachingPackage, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	pkg, err := s.ownPackage(ctx, coachID, packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		pkg.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		pkg.Description = req.Description
+	}
+	if req.PriceCents != nil {
+		pkg.PriceCents = *req.PriceCents
+	}
+	if req.TrialDays != nil {
+		pkg.TrialDays = *req.TrialDays
+	}
+	if req.Features != nil {
+		pkg.Features = normalizeFeatures(*req.Features)
+	}
+	if req.Active != nil {
+		pkg.Active = *req.Active
+	}
+
+	if err := s.repo.UpdatePackage(ctx, pkg); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func (s *packageService) ArchivePackage(ctx context.Context, coachID string, packageID int64) error {
+	pkg, err := s.ownPackage(ctx, coachID, packageID)
+	if err != nil {
+		return err
+	}
+	if !pkg.Active {
+		return nil
+	}
+	pkg.Active = false
+	return s.repo.UpdatePackage(ctx, pkg)
+}
+
+func (s *packageService) ListMyPackages(ctx context.Context, coachID string) ([]types.CoachingPackage, error) {
+	return s.repo.ListPackages(ctx, coachID, false)
+}
+
+func (s *packageService) ListCoachPackages(ctx context.Context, coachID string) ([]types.CoachingPackage, error) {
+	return s.repo.ListPackages(ctx, coachID, true)
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/tdmdh/fit-up-server/internal/billing/providers"
+	"github.com/tdmdh/fit-up-server/internal/billing/repository"
+	"github.com/tdmdh/fit-up-server/internal/billing/types"
+)
+
+type SubscriptionService interface {
+	Subscribe(ctx context.Context, clientID string, req *types.SubscribeRequest) (*types.Subscription, error)
+	ListMySubscriptions(ctx context.Context, clientID string) ([]types.Subscription, error)
+	// GetSubscription returns a subscription to its client or its coach.
+	GetSubscription(ctx context.Context, userID string, subscriptionID int64) (*types.Subscription, error)
+	// CancelSubscription stops renewal. An unpaid subscription ends at once;
+	// otherwise the client keeps access until the paid period is over.
+	CancelSubscription(ctx context.Context, clientID string, subscriptionID int64) (*types.Subscription, error)
+	ListSubscribers(ctx context.Context, coachID string, status *types.SubscriptionStatus, limit, offset int) ([]types.Subscription, error)
+
+	HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error
+	CancelLapsed(ctx context.Context) (int, error)
+
+	// AllowsMessaging and AllowsCustomPlans are used by the message and schema
+	// modules. Pairs that never subscribed in the app are not restricted.
+	AllowsMessaging(ctx context.Context, coachID, clientID string) (bool, error)
+	AllowsCustomPlans(ctx context.Context, coachID, clientID string) (bool, error)
+}
+
+type subscriptionService struct {
+	repo      repository.BillingRepo
+	provider  providers.PaymentProvider
+	validator *validator.Validate
+	now       func() time.Time
+}
+
+func NewSubscriptionService(repo repository.BillingRepo, provider providers.PaymentProvider) SubscriptionService {
+	return &subscriptionService{
+		repo:      repo,
+		provider:  provider,
+		validator: validator.New(),
+		now:       time.Now,
+	}
+}
+
+func (s *subscriptionService) Subscribe(ctx context.Context, clientID string, req *types.SubscribeRequest) (*types.Subscription, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	pkg, err := s.repo.GetPackage(ctx, req.PackageID)
+	if err != nil {
+		return nil, err
+	}
+	if !pkg.Active {
+		return nil, types.ErrPackageInactive
+	}
+	if pkg.CoachID == clientID {
+		return nil, types.ErrSubscribeToSelf
+	}
+
+	existing, err := s.repo.GetLatestSubscription(ctx, pkg.CoachID, clientID)
+	if err != nil && !errors.Is(err, types.ErrSubscriptionNotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.Status != types.SubscriptionCancelled {
+		return nil, types.ErrSubscriptionExists
+	}
+
+	providerSub, err := s.provider.CreateSubscription(ctx, types.ProviderSubscriptionRequest{
+		ClientID:   clientID,
+		CoachID:    pkg.CoachID,
+		PackageID:  pkg.PackageID,
+		PriceCents: pkg.PriceCents,
+		Currency:   pkg.Currency,
+		TrialDays:  pkg.TrialDays,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription with %s: %w", s.provider.Name(), err)
+	}
+
+	sub := &types.Subscription{
+		PackageID:              pkg.PackageID,
+		CoachID:                pkg.CoachID,
+		ClientID:               clientID,
+		Status:                 providerSub.Status,
+		Provider:               s.provider.Name(),
+		ProviderSubscriptionID: providerSub.ID,
+		TrialEndsAt:            providerSub.TrialEndsAt,
+		CurrentPeriodStart:     providerSub.CurrentPeriodStart,
+		CurrentPeriodEnd:       providerSub.CurrentPeriodEnd,
+		Package:                pkg,
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		// Don't leave the client billed for a subscription we have no record of
+		if cancelErr := s.provider.CancelSubscription(ctx, providerSub.ID); cancelErr != nil {
+			log.Printf("Failed to cancel orphaned %s subscription %s: %v", s.provider.Name(), providerSub.ID, cancelErr)
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *subscriptionService) ListMySubscriptions(ctx context.Context, clientID string) ([]types.Subscription, error) {
+	return s.repo.ListClientSubscriptions(ctx, clientID)
+}
+
+func (s *subscriptionService) GetSubscription(ctx context.Context, userID string, subscriptionID int64) (*types.Subscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.ClientID != userID && sub.CoachID != userID {
+		return nil, types.ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+func (s *subscriptionService) CancelSubscription(ctx context.Context, clientID string, subscriptionID int64) (*types.Subscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.ClientID != clientID {
+		return nil, types.ErrSubscriptionNotFound
+	}
+	if sub.Status == types.SubscriptionCancelled {
+		return nil, types.ErrSubscriptionCancelled
+	}
+	if sub.CancelAtPeriodEnd {
+		return sub, nil
+	}
+
+	if err := s.provider.CancelSubscription(ctx, sub.ProviderSubscriptionID); err != nil {
+		return nil, fmt.Errorf("failed to cancel subscription with %s: %w", sub.Provider, err)
+	}
+
+	sub.CancelAtPeriodEnd = true
+	if sub.Status == types.SubscriptionPastDue {
+		now := s.now().UTC()
+		sub.Status = types.SubscriptionCancelled
+		sub.CancelledAt = &now
+	}
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *subscriptionService) ListSubscribers(ctx context.Context, coachID string, status *types.SubscriptionStatus, limit, offset int) ([]types.Subscription, error) {
+	if status != nil {
+		switch *status {
+		case types.SubscriptionTrialing, types.SubscriptionActive, types.SubscriptionPastDue, types.SubscriptionCancelled:
+		default:
+			return nil, types.ErrInvalidStatus
+		}
+	}
+	return s.repo.ListCoachSubscriptions(ctx, coachID, status, limit, offset)
+}
+
+// HandleWebhook applies a provider event. Events are recorded by ID, so a
+// redelivered event is acknowledged without being applied twice.
+func (s *subscriptionService) HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error {
+	if provider != s.provider.Name() {
+		return types.ErrUnknownProvider
+	}
+
+	event, err := s.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.repo.GetSubscriptionByProviderID(ctx, provider, event.ProviderSubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	updated := applyWebhookEvent(*sub, event)
+	applied, err := s.repo.ApplyWebhookEvent(ctx, provider, event.ID, &updated)
+	if err != nil {
+		return err
+	}
+	if applied && updated.Status != sub.Status {
+		log.Printf("Coaching subscription %d moved from %s to %s", sub.SubscriptionID, sub.Status, updated.Status)
+	}
+	return nil
+}
+
+func (s *subscriptionService) CancelLapsed(ctx context.Context) (int, error) {
+	return s.repo.CancelLapsedSubscriptions(ctx, s.now().UTC(), renewalGrace)
+}
+
+func (s *subscriptionService) AllowsMessaging(ctx context.Context, coachID, clientID string) (bool, error) {
+	return s.allows(ctx, coachID, clientID, types.FeatureMessaging)
+}
+
+func (s *subscriptionService) AllowsCustomPlans(ctx context.Context, coachID, clientID string) (bool, error) {
+	return s.allows(ctx, coachID, clientID, types.FeatureCustomPlans)
+}
+
+func (s *subscriptionService) allows(ctx context.Context, coachID, clientID string, feature types.PackageFeature) (bool, error) {
+	sub, err := s.repo.GetLatestSubscription(ctx, coachID, clientID)
+	if errors.Is(err, types.ErrSubscriptionNotFound) {
+		return allowsFeature(nil, feature, s.now()), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return allowsFeature(sub, feature, s.now()), nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const lapsePollInterval = 15 * time.Minute
+
+// LapseWorker cancels subscriptions that ran out, so their status matches the
+// access clients already lost.
+type LapseWorker struct {
+	service SubscriptionService
+}
+
+func NewLapseWorker(service SubscriptionService) *LapseWorker {
+	return &LapseWorker{service: service}
+}
+
+func (w *LapseWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(lapsePollInterval)
+	defer ticker.Stop()
+
+	for {
+		if cancelled, err := w.service.CancelLapsed(ctx); err != nil {
+			log.Printf("Coaching subscription lapse check failed: %v", err)
+		} else if cancelled > 0 {
+			log.Printf("Cancelled %d lapsed coaching subscriptions", cancelled)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package types
+
+import "errors"
+
+var (
+	ErrPackageNotFound = errors.New("coaching package not found")
+	ErrPackageInactive = errors.New("coaching package is no longer offered")
+	ErrSubscribeToSelf = errors.New("coaches cannot subscribe to their own packages")
+)
+
+var (
+	ErrSubscriptionNotFound  = errors.New("subscription not found")
+	ErrSubscriptionExists    = errors.New("you already have a subscription with this coach")
+	ErrSubscriptionCancelled = errors.New("subscription is already cancelled")
+	ErrInvalidStatus         = errors.New("status must be trialing, active, past_due or cancelled")
+)
+
+var (
+	ErrUnknownProvider         = errors.New("unknown payment provider")
+	ErrInvalidWebhookSignature = errors.New("webhook signature is invalid")
+	ErrInvalidWebhookPayload   = errors.New("webhook payload is invalid")
+)
@@ -0,0 +1,130 @@
+package types
+
+import "time"
+
+type PackageFeature string
+
+const (
+	FeatureCustomPlans    PackageFeature = "custom_plans"
+	FeatureMessaging      PackageFeature = "messaging"
+	FeatureCheckIns       PackageFeature = "check_ins"
+	FeatureProgressPhotos PackageFeature = "progress_photos"
+)
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionTrialing  SubscriptionStatus = "trialing"
+	SubscriptionActive    SubscriptionStatus = "active"
+	SubscriptionPastDue   SubscriptionStatus = "past_due"
+	SubscriptionCancelled SubscriptionStatus = "cancelled"
+)
+
+// CoachingPackage is a monthly plan a coach sells to clients.
+type CoachingPackage struct {
+	PackageID   int64            `json:"package_id" db:"package_id"`
+	CoachID     string           `json:"coach_id" db:"coach_id"`
+	Name        string           `json:"name" db:"name"`
+	Description *string          `json:"description,omitempty" db:"description"`
+	PriceCents  int64            `json:"price_cents" db:"price_cents"`
+	Currency    string           `json:"currency" db:"currency"`
+	TrialDays   int              `json:"trial_days" db:"trial_days"`
+	Features    []PackageFeature `json:"features" db:"features"`
+	Active      bool             `json:"active" db:"active"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// HasFeature reports whether the package includes feature.
+func (p *CoachingPackage) HasFeature(feature PackageFeature) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is a client's subscription to one of a coach's packages. The
+// payment details stay with the provider.
+type Subscription struct {
+	SubscriptionID         int64              `json:"subscription_id" db:"subscription_id"`
+	PackageID              int64              `json:"package_id" db:"package_id"`
+	CoachID                string             `json:"coach_id" db:"coach_id"`
+	ClientID               string             `json:"client_id" db:"client_id"`
+	Status                 SubscriptionStatus `json:"status" db:"status"`
+	Provider               string             `json:"provider" db:"provider"`
+	ProviderSubscriptionID string             `json:"-" db:"provider_subscription_id"`
+	TrialEndsAt            *time.Time         `json:"trial_ends_at,omitempty" db:"trial_ends_at"`
+	CurrentPeriodStart     time.Time          `json:"current_period_start" db:"current_period_start"`
+	CurrentPeriodEnd       time.Time          `json:"current_period_end" db:"current_period_end"`
+	CancelAtPeriodEnd      bool               `json:"cancel_at_period_end" db:"cancel_at_period_end"`
+	CancelledAt            *time.Time         `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt              time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time          `json:"updated_at" db:"updated_at"`
+
+	// Package is joined in when subscriptions are read back.
+	Package *CoachingPackage `json:"package,omitempty" db:"-"`
+}
+
+type CreatePackageRequest struct {
+	Name        string           `json:"name" validate:"required,max=120"`
+	Description *string          `json:"description,omitempty" validate:"omitempty,max=2000"`
+	PriceCents  int64            `json:"price_cents" validate:"gte=0,lte=10000000"`
+	Currency    string           `json:"currency,omitempty" validate:"omitempty,len=3,alpha"`
+	TrialDays   int              `json:"trial_days" validate:"gte=0,lte=90"`
+	Features    []PackageFeature `json:"features" validate:"dive,oneof=custom_plans messaging check_ins progress_photos"`
+}
+
+// UpdatePackageRequest changes only the fields that are set. Price changes
+// apply to new subscriptions; existing ones keep the price they signed up at.
+type UpdatePackageRequest struct {
+	Name        *string           `json:"name,omitempty" validate:"omitempty,min=1,max=120"`
+	Description *string           `json:"description,omitempty" validate:"omitempty,max=2000"`
+	PriceCents  *int64            `json:"price_cents,omitempty" validate:"omitempty,gte=0,lte=10000000"`
+	TrialDays   *int              `json:"trial_days,omitempty" validate:"omitempty,gte=0,lte=90"`
+	Features    *[]PackageFeature `json:"features,omitempty" validate:"omitempty,dive,oneof=custom_plans messaging check_ins progress_photos"`
+	Active      *bool             `json:"active,omitempty"`
+}
+
+type SubscribeRequest struct {
+	PackageID int64 `json:"package_id" validate:"required,gt=0"`
+}
+
+// ProviderSubscriptionRequest is what a payment provider needs to start
+// billing a client for a package.
+type ProviderSubscriptionRequest struct {
+	ClientID   string
+	CoachID    string
+	PackageID  int64
+	PriceCents int64
+	Currency   string
+	TrialDays  int
+}
+
+// ProviderSubscription is the provider's view of a subscription it created.
+type ProviderSubscription struct {
+	ID                 string
+	Status             SubscriptionStatus
+	TrialEndsAt        *time.Time
+	CurrentPeriodStart time.Time
+	CurrentPeriodEnd   time.Time
+}
+
+type WebhookEventType string
+
+const (
+	EventPaymentSucceeded      WebhookEventType = "payment_succeeded"
+	EventPaymentFailed         WebhookEventType = "payment_failed"
+	EventSubscriptionCancelled WebhookEventType = "subscription_cancelled"
+)
+
+// WebhookEvent is a provider notification after its signature has been checked.
+type WebhookEvent struct {
+	ID                     string           `json:"id"`
+	Type                   WebhookEventType `json:"type"`
+	ProviderSubscriptionID string           `json:"subscription_id"`
+	PeriodStart            *time.Time       `json:"period_start,omitempty"`
+	PeriodEnd              *time.Time       `json:"period_end,omitempty"`
+	OccurredAt             time.Time        `json:"occurred_at"`
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/jobs/services"
+	"github.com/tdmdh/fit-up-server/internal/jobs/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+type JobsHandler struct {
+	scheduler services.SchedulerService
+}
+
+func NewJobsHandler(scheduler services.SchedulerService) *JobsHandler {
+	return &JobsHandler{scheduler: scheduler}
+}
+
+// ListJobs handles GET /admin/jobs
+func (h *JobsHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	status, err := h.scheduler.Status(r.Context())
+	if err != nil {
+		log.Printf("Failed to get job status: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to get job status")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+// GetJob handles GET /admin/jobs/{name} with the job's recent runs.
+func (h *JobsHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.scheduler.GetJob(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		if errors.Is(err, types.ErrJobNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("Failed to get job: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to get job")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, job)
+}
+
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithJSON(w, code, map[string]string{"error": message})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+func (h *JobsHandler) RegisterRoutes(r chi.Router, authMW *middleware.AuthMiddleware) {
+	r.Route("/admin/jobs", func(r chi.Router) {
+		r.Use(authMW.RequireJWTAuth())
+		r.Use(authMW.RequireAdminRole())
+
+		r.Get("/", h.ListJobs)
+		r.Get("/{name}", h.GetJob)
+	})
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SchedulerLockKey is the advisory lock id the job scheduler leader holds.
+const SchedulerLockKey int64 = 0x6a6f62736c6561
+
+// LeaderLock elects one scheduler leader across replicas with a session-level
+// Postgres advisory lock. The lock lives on a connection held out of the pool,
+// so it is released as soon as the leader's session ends, including when the
+// process dies without unlocking.
+type LeaderLock struct {
+	db  *pgxpool.Pool
+	key int64
+
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+func NewLeaderLock(db *pgxpool.Pool, key int64) *LeaderLock {
+	return &LeaderLock{db: db, key: key}
+}
+
+// TryAcquire reports whether this replica holds the lock, taking it if it is
+// free. A leader re-checks its connection on every call and steps down if the
+// session was lost.
+func (l *LeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if _, err := l.conn.Exec(ctx, `SELECT 1`); err != nil {
+			l.dropConn(ctx)
+			return false, err
+		}
+		return true, nil
+	}
+
+	conn, err := l.db.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, err
+	}
+	if !acquired {
+		conn.Release()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Release gives up leadership so another replica can take over straight away.
+func (l *LeaderLock) Release(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return
+	}
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		l.dropConn(ctx)
+		return
+	}
+	l.conn.Release()
+	l.conn = nil
+}
+
+// dropConn closes the session rather than returning it to the pool, so a lock
+// it may still hold cannot leak into an unrelated query's connection.
+func (l *LeaderLock) dropConn(ctx context.Context) {
+	l.conn.Conn().Close(ctx)
+	l.conn.Release()
+	l.conn = nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tdmdh/fit-up-server/internal/jobs/types"
+)
+
+type JobRepo interface {
+	ClaimRun(ctx context.Context, jobName string, scheduledFor time.Time, attempt int, host string) (*types.JobRun, error)
+	FinishRun(ctx context.Context, runID int64, status types.RunStatus, result, errorMessage string) error
+	FailRunningRuns(ctx context.Context, message string) (int64, error)
+	ListRecentRuns(ctx context.Context, jobName string, limit int) ([]types.JobRun, error)
+	GetJobSummaries(ctx context.Context) (map[string]types.JobSummary, error)
+	DeleteRunsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type Store struct {
+	db *pgxpool.Pool
+}
+
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+const runColumns = `
+	run_id, job_name, scheduled_for, attempt, status, host,
+	COALESCE(result, ''), COALESCE(error_message, ''), started_at, finished_at
+`
+
+func scanRun(row pgx.Row) (*types.JobRun, error) {
+	var run types.JobRun
+	err := row.Scan(
+		&run.RunID,
+		&run.JobName,
+		&run.ScheduledFor,
+		&run.Attempt,
+		&run.Status,
+		&run.Host,
+		&run.Result,
+		&run.ErrorMessage,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ClaimRun records the start of an attempt. The first attempt of a slot can
+// only be inserted once, so a replica that loses the race gets
+// ErrRunAlreadyClaimed and skips the slot.
+func (s *Store) ClaimRun(ctx context.Context, jobName string, scheduledFor time.Time, attempt int, host string) (*types.JobRun, error) {
+	query := `
+		INSERT INTO job_runs (job_name, scheduled_for, attempt, host)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_name, scheduled_for, attempt) DO NOTHING
+		RETURNING ` + runColumns
+
+	run, err := scanRun(s.db.QueryRow(ctx, query, jobName, scheduledFor, attempt, host))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, types.ErrRunAlreadyClaimed
+	}
+	return run, err
+}
+
+func (s *Store) FinishRun(ctx context.Context, runID int64, status types.RunStatus, result, errorMessage string) error {
+	query := `
+		UPDATE job_runs
+		SET status = $2, result = NULLIF($3, ''), error_message = NULLIF($4, ''), finished_at = NOW()
+		WHERE run_id = $1
+	`
+
+	_, err := s.db.Exec(ctx, query, runID, status, result, errorMessage)
+	return err
+}
+
+// FailRunningRuns closes runs left open by a leader that went away mid-run.
+// Only the leader runs jobs, so a new leader can assume every open run is
+// orphaned.
+func (s *Store) FailRunningRuns(ctx context.Context, message string) (int64, error) {
+	query := `
+		UPDATE job_runs
+		SET status = 'failed', error_message = $1, finished_at = NOW()
+		WHERE status = 'running'
+	`
+
+	tag, err := s.db.Exec(ctx, query, message)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s *Store) ListRecentRuns(ctx context.Context, jobName string, limit int) ([]types.JobRun, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM job_runs
+		WHERE job_name = $1
+		ORDER BY started_at DESC, run_id DESC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, jobName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []types.JobRun{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// GetJobSummaries returns the latest run and last success of every job with
+// history, keyed by job name.
+func (s *Store) GetJobSummaries(ctx context.Context) (map[string]types.JobSummary, error) {
+	query := `
+		SELECT ` + runColumns + `,
+			(SELECT MAX(finished_at) FROM job_runs ok WHERE ok.job_name = latest.job_name AND ok.status = 'succeeded')
+		FROM (
+			SELECT DISTINCT ON (job_name) *
+			FROM job_runs
+			ORDER BY job_name, started_at DESC, run_id DESC
+		) latest
+	`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make(map[string]types.JobSummary)
+	for rows.Next() {
+		var run types.JobRun
+		var lastSuccessAt *time.Time
+		err := rows.Scan(
+			&run.RunID,
+			&run.JobName,
+			&run.ScheduledFor,
+			&run.Attempt,
+			&run.Status,
+			&run.Host,
+			&run.Result,
+			&run.ErrorMessage,
+			&run.StartedAt,
+			&run.FinishedAt,
+			&lastSuccessAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		summaries[run.JobName] = types.JobSummary{LastRun: &run, LastSuccessAt: lastSuccessAt}
+	}
+	return summaries, rows.Err()
+}
+
+func (s *Store) DeleteRunsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM job_runs WHERE started_at < $1 AND status <> 'running'`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/jobs/repository"
+)
+
+const (
+	staleRoleAge    = 24 * time.Hour
+	jobRunRetention = 30 * 24 * time.Hour
+)
+
+// AuthCleanup is the part of the auth store with expiring rows to remove.
+type AuthCleanup interface {
+	CleanupExpiredRefreshTokens(ctx context.Context) error
+	CleanupExpiredOAuthStates(ctx context.Context) error
+}
+
+type InvitationExpirer interface {
+	ExpireOldInvitations(ctx context.Context) (int64, error)
+}
+
+type RoleSyncer interface {
+	SyncStaleRoles(ctx context.Context, staleAfter time.Duration) (int, error)
+}
+
+// MaintenanceJobs are the cleanup tasks shared by every module, including
+// pruning the scheduler's own run history.
+func MaintenanceJobs(auth AuthCleanup, invitations InvitationExpirer, roles RoleSyncer, runs repository.JobRepo) []Job {
+	return []Job{
+		{
+			Name:        "refresh-token-cleanup",
+			Description: "Delete expired refresh tokens",
+			Schedule:    "15 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				return "", auth.CleanupExpiredRefreshTokens(ctx)
+			},
+		},
+		{
+			Name:        "oauth-state-cleanup",
+			Description: "Delete expired OAuth login states",
+			Schedule:    "*/10 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				return "", auth.CleanupExpiredOAuthStates(ctx)
+			},
+		},
+		{
+			Name:        "invitation-expiry",
+			Description: "Mark pending coach invitations past their expiry as expired",
+			Schedule:    "5 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				expired, err := invitations.ExpireOldInvitations(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("expired %d invitations", expired), nil
+			},
+		},
+		{
+			Name:        "stale-role-sync",
+			Description: "Refresh cached user roles not synced in the last day",
+			Schedule:    "30 * * * *",
+			Run: func(ctx context.Context) (string, error) {
+				synced, err := roles.SyncStaleRoles(ctx, staleRoleAge)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("synced %d cached roles", synced), nil
+			},
+		},
+		{
+			Name:        "job-run-history-cleanup",
+			Description: "Delete job run history older than 30 days",
+			Schedule:    "0 4 * * *",
+			Run: func(ctx context.Context) (string, error) {
+				deleted, err := runs.DeleteRunsBefore(ctx, time.Now().Add(-jobRunRetention))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("deleted %d job runs", deleted), nil
+			},
+		},
+	}
+}
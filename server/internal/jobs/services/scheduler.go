@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/jobs/repository"
+	"github.com/tdmdh/fit-up-server/internal/jobs/types"
+	"github.com/tdmdh/fit-up-server/shared/utils"
+)
+
+const (
+	schedulerTickInterval = 15 * time.Second
+	defaultMaxAttempts    = 3
+	defaultRetryBackoff   = 30 * time.Second
+	maxRetryBackoff       = 10 * time.Minute
+	defaultJobTimeout     = 5 * time.Minute
+	recentRunLimit        = 50
+	finishRunTimeout      = 10 * time.Second
+)
+
+// Job is a task the scheduler runs on a cron schedule. Run returns a short
+// summary for the run history, such as how many rows it removed.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	// MaxAttempts includes the first try; failed attempts are retried after
+	// Backoff, doubling each time.
+	MaxAttempts int
+	Backoff     time.Duration
+	Timeout     time.Duration
+	Run         func(ctx context.Context) (string, error)
+}
+
+// Elector decides which replica runs jobs; repository.LeaderLock is the
+// Postgres implementation.
+type Elector interface {
+	TryAcquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context)
+}
+
+type SchedulerService interface {
+	Status(ctx context.Context) (*types.SchedulerStatus, error)
+	GetJob(ctx context.Context, name string) (*types.JobDetail, error)
+}
+
+type scheduledJob struct {
+	Job
+	schedule *utils.CronSchedule
+	next     time.Time
+	running  bool
+}
+
+// Scheduler runs registered jobs on the replica that holds leadership.
+// Schedules are evaluated in UTC so every replica agrees on each slot. A slot
+// missed while no replica was leader runs once when leadership is regained.
+type Scheduler struct {
+	repo    repository.JobRepo
+	elector Elector
+	host    string
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) bool
+
+	mu     sync.Mutex
+	jobs   []*scheduledJob
+	leader bool
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(repo repository.JobRepo, elector Elector) *Scheduler {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &Scheduler{
+		repo:    repo,
+		elector: elector,
+		host:    host,
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+}
+
+// Register adds a job, filling in the retry and timeout defaults. Jobs must be
+// registered before Run starts.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return types.ErrInvalidJob
+	}
+	schedule, err := utils.ParseCron(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", types.ErrInvalidCronSpec, job.Schedule, err)
+	}
+	if job.MaxAttempts < 1 {
+		job.MaxAttempts = defaultMaxAttempts
+	}
+	if job.Backoff <= 0 {
+		job.Backoff = defaultRetryBackoff
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = defaultJobTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.jobs {
+		if existing.Name == job.Name {
+			return fmt.Errorf("%w: %s", types.ErrDuplicateJob, job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &scheduledJob{
+		Job:      job,
+		schedule: schedule,
+		next:     schedule.Next(s.now().UTC()),
+	})
+	return nil
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTickInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			s.elector.Release(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick refreshes leadership and starts every job that is due. A job still
+// running from an earlier slot is not started twice.
+func (s *Scheduler) tick(ctx context.Context) {
+	leader, err := s.elector.TryAcquire(ctx)
+	if err != nil {
+		log.Printf("Job scheduler leader election failed: %v", err)
+	}
+
+	s.mu.Lock()
+	wasLeader := s.leader
+	s.leader = leader
+	s.mu.Unlock()
+
+	if leader && !wasLeader {
+		log.Printf("Job scheduler on %s is now the leader", s.host)
+		if failed, err := s.repo.FailRunningRuns(ctx, "abandoned by a previous leader"); err != nil {
+			log.Printf("Failed to close abandoned job runs: %v", err)
+		} else if failed > 0 {
+			log.Printf("Closed %d job runs abandoned by a previous leader", failed)
+		}
+	}
+	if !leader {
+		if wasLeader {
+			log.Printf("Job scheduler on %s is no longer the leader", s.host)
+		}
+		return
+	}
+
+	now := s.now().UTC()
+	type dueRun struct {
+		job  *scheduledJob
+		slot time.Time
+	}
+	var due []dueRun
+
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		if job.running || job.next.IsZero() || job.next.After(now) {
+			continue
+		}
+		due = append(due, dueRun{job: job, slot: job.next})
+		job.running = true
+		job.next = job.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for _, run := range due {
+		s.wg.Add(1)
+		go func(job *scheduledJob, slot time.Time) {
+			defer s.wg.Done()
+			s.execute(ctx, job, slot)
+		}(run.job, run.slot)
+	}
+}
+
+// execute runs one slot of a job, retrying failed attempts with exponential
+// backoff while this replica stays leader. Every attempt is recorded.
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob, slot time.Time) {
+	defer func() {
+		s.mu.Lock()
+		job.running = false
+		s.mu.Unlock()
+	}()
+
+	for attempt := 1; attempt <= job.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			if !s.sleep(ctx, retryBackoff(job.Backoff, attempt-1)) {
+				return
+			}
+			if !s.isLeader() {
+				log.Printf("Job %s: stopped retrying after losing leadership", job.Name)
+				return
+			}
+		}
+
+		run, err := s.repo.ClaimRun(ctx, job.Name, slot, attempt, s.host)
+		if errors.Is(err, types.ErrRunAlreadyClaimed) {
+			return
+		}
+		if err != nil {
+			log.Printf("Job %s: failed to record run: %v", job.Name, err)
+			return
+		}
+
+		result, runErr := runJob(ctx, job.Job)
+
+		status, message := types.RunSucceeded, ""
+		if runErr != nil {
+			status, message = types.RunFailed, runErr.Error()
+		}
+		finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishRunTimeout)
+		if err := s.repo.FinishRun(finishCtx, run.RunID, status, result, message); err != nil {
+			log.Printf("Job %s: failed to record result of run %d: %v", job.Name, run.RunID, err)
+		}
+		cancel()
+
+		if runErr == nil {
+			if result != "" {
+				log.Printf("Job %s: %s", job.Name, result)
+			}
+			return
+		}
+		log.Printf("Job %s: attempt %d of %d failed: %v", job.Name, attempt, job.MaxAttempts, runErr)
+	}
+}
+
+// runJob applies the job's timeout and turns a panic into a failed attempt
+// so one broken job cannot take the server down.
+func runJob(ctx context.Context, job Job) (result string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) isLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader
+}
+
+// retryBackoff is the wait before the given retry: base, then doubling, up to
+// maxRetryBackoff.
+func retryBackoff(base time.Duration, retry int) time.Duration {
+	delay := base
+	for i := 1; i < retry && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *Scheduler) Status(ctx context.Context) (*types.SchedulerStatus, error) {
+	summaries, err := s.repo.GetJobSummaries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job history: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &types.SchedulerStatus{
+		Host:   s.host,
+		Leader: s.leader,
+		Jobs:   make([]types.JobStatus, 0, len(s.jobs)),
+	}
+	for _, job := range s.jobs {
+		status.Jobs = append(status.Jobs, jobStatus(job, summaries[job.Name]))
+	}
+	return status, nil
+}
+
+func (s *Scheduler) GetJob(ctx context.Context, name string) (*types.JobDetail, error) {
+	s.mu.Lock()
+	var job *scheduledJob
+	for _, candidate := range s.jobs {
+		if candidate.Name == name {
+			job = candidate
+			break
+		}
+	}
+	s.mu.Unlock()
+	if job == nil {
+		return nil, types.ErrJobNotFound
+	}
+
+	summaries, err := s.repo.GetJobSummaries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job history: %w", err)
+	}
+	runs, err := s.repo.ListRecentRuns(ctx, name, recentRunLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job runs: %w", err)
+	}
+
+	s.mu.Lock()
+	detail := &types.JobDetail{JobStatus: jobStatus(job, summaries[name]), RecentRuns: runs}
+	s.mu.Unlock()
+	return detail, nil
+}
+
+// jobStatus must be called with s.mu held.
+func jobStatus(job *scheduledJob, summary types.JobSummary) types.JobStatus {
+	status := types.JobStatus{
+		Name:          job.Name,
+		Description:   job.Description,
+		Schedule:      job.Schedule,
+		MaxAttempts:   job.MaxAttempts,
+		Running:       job.running,
+		LastRun:       summary.LastRun,
+		LastSuccessAt: summary.LastSuccessAt,
+	}
+	if !job.next.IsZero() {
+		next := job.next
+		status.NextRunAt = &next
+	}
+	return status
+}
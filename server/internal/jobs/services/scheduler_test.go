@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/jobs/types"
+)
+
+type fakeElector struct {
+	leader bool
+}
+
+func (f *fakeElector) TryAcquire(ctx context.Context) (bool, error) { return f.leader, nil }
+func (f *fakeElector) Release(ctx context.Context)                  {}
+
+type fakeJobRepo struct {
+	mu       sync.Mutex
+	claimed  map[string]bool
+	runs     []types.JobRun
+	abandons int
+}
+
+func newFakeJobRepo() *fakeJobRepo {
+	return &fakeJobRepo{claimed: make(map[string]bool)}
+}
+
+func (f *fakeJobRepo) ClaimRun(ctx context.Context, jobName string, scheduledFor time.Time, attempt int, host string) (*types.JobRun, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%d", jobName, scheduledFor, attempt)
+	if f.claimed[key] {
+		return nil, types.ErrRunAlreadyClaimed
+	}
+	f.claimed[key] = true
+	run := types.JobRun{RunID: int64(len(f.runs) + 1), JobName: jobName, ScheduledFor: scheduledFor, Attempt: attempt, Status: types.RunRunning}
+	f.runs = append(f.runs, run)
+	return &run, nil
+}
+
+func (f *fakeJobRepo) FinishRun(ctx context.Context, runID int64, status types.RunStatus, result, errorMessage string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.runs[runID-1].Status = status
+	f.runs[runID-1].Result = result
+	f.runs[runID-1].ErrorMessage = errorMessage
+	return nil
+}
+
+func (f *fakeJobRepo) FailRunningRuns(ctx context.Context, message string) (int64, error) {
+	f.abandons++
+	return 0, nil
+}
+
+func (f *fakeJobRepo) ListRecentRuns(ctx context.Context, jobName string, limit int) ([]types.JobRun, error) {
+	return f.runs, nil
+}
+
+func (f *fakeJobRepo) GetJobSummaries(ctx context.Context) (map[string]types.JobSummary, error) {
+	return map[string]types.JobSummary{}, nil
+}
+
+func (f *fakeJobRepo) DeleteRunsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+// newTestScheduler returns a scheduler whose clock starts just before 10:00
+// and whose retries do not wait.
+func newTestScheduler(repo *fakeJobRepo, elector *fakeElector) (*Scheduler, *time.Time, *[]time.Duration) {
+	now := time.Date(2026, time.March, 11, 9, 59, 30, 0, time.UTC)
+	var waits []time.Duration
+
+	s := NewScheduler(repo, elector)
+	s.now = func() time.Time { return now }
+	s.sleep = func(ctx context.Context, d time.Duration) bool {
+		waits = append(waits, d)
+		return true
+	}
+	return s, &now, &waits
+}
+
+func TestSchedulerRetriesWithBackoff(t *testing.T) {
+	repo := newFakeJobRepo()
+	s, now, waits := newTestScheduler(repo, &fakeElector{leader: true})
+
+	calls := 0
+	err := s.Register(Job{Name: "flaky", Schedule: "0 * * * *", MaxAttempts: 3, Backoff: time.Second, Run: func(ctx context.Context) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("database unavailable")
+		}
+		return "cleaned up", nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	s.tick(context.Background())
+	s.wg.Wait()
+
+	if calls != 3 || len(repo.runs) != 3 {
+		t.Fatalf("got %d calls and %d recorded runs, want 3 of each", calls, len(repo.runs))
+	}
+	if repo.runs[0].Status != types.RunFailed || repo.runs[2].Status != types.RunSucceeded || repo.runs[2].Result != "cleaned up" {
+		t.Errorf("unexpected run history %+v", repo.runs)
+	}
+	if len(*waits) != 2 || (*waits)[0] != time.Second || (*waits)[1] != 2*time.Second {
+		t.Errorf("unexpected backoff %v", *waits)
+	}
+	if repo.abandons != 1 {
+		t.Errorf("a new leader should close abandoned runs once, got %d", repo.abandons)
+	}
+}
+
+func TestSchedulerOnlyRunsOnLeader(t *testing.T) {
+	repo := newFakeJobRepo()
+	elector := &fakeElector{}
+	s, now, _ := newTestScheduler(repo, elector)
+
+	calls := 0
+	s.Register(Job{Name: "cleanup", Schedule: "0 * * * *", Run: func(ctx context.Context) (string, error) {
+		calls++
+		return "", nil
+	}})
+
+	*now = now.Add(time.Minute)
+	s.tick(context.Background())
+	s.wg.Wait()
+	if calls != 0 {
+		t.Fatalf("a follower ran the job %d times", calls)
+	}
+
+	// The slot missed as a follower runs once after taking over, not twice.
+	elector.leader = true
+	s.tick(context.Background())
+	s.wg.Wait()
+	s.tick(context.Background())
+	s.wg.Wait()
+	if calls != 1 {
+		t.Errorf("leader ran the job %d times, want 1", calls)
+	}
+}
+
+func TestSchedulerSkipsSlotClaimedElsewhere(t *testing.T) {
+	repo := newFakeJobRepo()
+	s, now, _ := newTestScheduler(repo, &fakeElector{leader: true})
+
+	calls := 0
+	s.Register(Job{Name: "cleanup", Schedule: "0 * * * *", Run: func(ctx context.Context) (string, error) {
+		calls++
+		return "", nil
+	}})
+	repo.ClaimRun(context.Background(), "cleanup", time.Date(2026, time.March, 11, 10, 0, 0, 0, time.UTC), 1, "other-replica")
+
+	*now = now.Add(time.Minute)
+	s.tick(context.Background())
+	s.wg.Wait()
+	if calls != 0 {
+		t.Errorf("ran a slot another replica already claimed")
+	}
+}
+
+func TestSchedulerRecoversFromPanics(t *testing.T) {
+	repo := newFakeJobRepo()
+	s, now, _ := newTestScheduler(repo, &fakeElector{leader: true})
+
+	s.Register(Job{Name: "broken", Schedule: "0 * * * *", MaxAttempts: 1, Run: func(ctx context.Context) (string, error) {
+		panic("nil map")
+	}})
+
+	*now = now.Add(time.Minute)
+	s.tick(context.Background())
+	s.wg.Wait()
+	if len(repo.runs) != 1 || repo.runs[0].Status != types.RunFailed {
+		t.Errorf("expected one failed run, got %+v", repo.runs)
+	}
+}
+
+func TestRegisterRejectsBadJobs(t *testing.T) {
+	s := NewScheduler(newFakeJobRepo(), &fakeElector{})
+	noop := func(ctx context.Context) (string, error) { return "", nil }
+
+	if err := s.Register(Job{Name: "a", Schedule: "every day", Run: noop}); !errors.Is(err, types.ErrInvalidCronSpec) {
+		t.Errorf("expected ErrInvalidCronSpec, got %v", err)
+	}
+	if err := s.Register(Job{Schedule: "* * * * *", Run: noop}); !errors.Is(err, types.ErrInvalidJob) {
+		t.Errorf("expected ErrInvalidJob, got %v", err)
+	}
+	s.Register(Job{Name: "a", Schedule: "* * * * *", Run: noop})
+	if err := s.Register(Job{Name: "a", Schedule: "* * * * *", Run: noop}); !errors.Is(err, types.ErrDuplicateJob) {
+		t.Errorf("expected ErrDuplicateJob, got %v", err)
+	}
+}
+
+func TestRetryBackoffIsCapped(t *testing.T) {
+	if got := retryBackoff(30*time.Second, 3); got != 2*time.Minute {
+		t.Errorf("third retry waits %s, want 2m", got)
+	}
+	if got := retryBackoff(30*time.Second, 20); got != maxRetryBackoff {
+		t.Errorf("late retries wait %s, want %s", got, maxRetryBackoff)
+	}
+}
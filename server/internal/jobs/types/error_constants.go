@@ -0,0 +1,11 @@
+package types
+
+import "errors"
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrDuplicateJob      = errors.New("a job with this name is already registered")
+	ErrInvalidJob        = errors.New("a job needs a name and a function to run")
+	ErrInvalidCronSpec   = errors.New("invalid cron expression")
+	ErrRunAlreadyClaimed = errors.New("this run was already claimed by another replica")
+)
@@ -0,0 +1,57 @@
+package types
+
+import "time"
+
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+)
+
+// JobRun is one attempt at one scheduled slot of a job.
+type JobRun struct {
+	RunID        int64      `json:"run_id" db:"run_id"`
+	JobName      string     `json:"job_name" db:"job_name"`
+	ScheduledFor time.Time  `json:"scheduled_for" db:"scheduled_for"`
+	Attempt      int        `json:"attempt" db:"attempt"`
+	Status       RunStatus  `json:"status" db:"status"`
+	Host         string     `json:"host" db:"host"`
+	Result       string     `json:"result,omitempty" db:"result"`
+	ErrorMessage string     `json:"error_message,omitempty" db:"error_message"`
+	StartedAt    time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// JobSummary is the latest history of one job, as stored in job_runs.
+type JobSummary struct {
+	LastRun       *JobRun
+	LastSuccessAt *time.Time
+}
+
+// JobStatus is what the admin endpoint reports for a registered job. Running
+// and NextRunAt describe the replica that answered the request.
+type JobStatus struct {
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	Schedule      string     `json:"schedule"`
+	MaxAttempts   int        `json:"max_attempts"`
+	Running       bool       `json:"running"`
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	LastRun       *JobRun    `json:"last_run,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+type JobDetail struct {
+	JobStatus
+	RecentRuns []JobRun `json:"recent_runs"`
+}
+
+// SchedulerStatus lists every job and whether this replica is the leader
+// that runs them.
+type SchedulerStatus struct {
+	Host   string      `json:"host"`
+	Leader bool        `json:"leader"`
+	Jobs   []JobStatus `json:"jobs"`
+}
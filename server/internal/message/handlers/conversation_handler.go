@@ -54,6 +54,10 @@ func (h *ConversationHandler) CreateConversation(w http.ResponseWriter, r *http.
 	}
 
 	conversation, err := h.service.Conversations().CreateConversation(ctx, &req)
+	if err == types.ErrMessagingNotPermitted {
+		respondError(w, types.GetHTTPStatus(err), err.Error())
+		return
+	}
 	if err != nil {
 		log.Printf("Error creating conversation: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to create conversation")
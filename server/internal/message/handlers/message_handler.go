@@ -41,6 +41,10 @@ func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		req.ReplyToMessageID,
 	)
 	if err != nil {
+		if err == types.ErrMessagingPaused || err == types.ErrMessagingNotPermitted {
+			respondError(w, types.GetHTTPStatus(err), err.Error())
+			return
+		}
 		log.Printf("Error creating message: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to create message")
 		return
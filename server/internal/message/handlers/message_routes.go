@@ -27,6 +27,7 @@ func SetupMessageRoutes(
 	r chi.Router,
 	messageHandler *MessageHandler,
 	conversationHandler *ConversationHandler,
+	presenceHandler *PresenceHandler,
 	authMiddleware *middleware.AuthMiddleware,
 ) {
 	r.Group(func(r chi.Router) {
@@ -41,17 +42,50 @@ func SetupMessageRoutes(
 				r.Get("/unread-count", conversationHandler.GetUnreadCount)
 				r.Get("/messages", messageHandler.GetMessages)
 				r.Post("/messages/read-all", messageHandler.MarkAllAsRead)
+				r.Get("/pinned", messageHandler.GetPinnedMessages)
 			})
 		})
 
 		r.Route("/messages", func(r chi.Router) {
 			r.Post("/", messageHandler.SendMessage)
+			r.Get("/search", messageHandler.SearchMessages)
 
 			r.Route("/{message_id}", func(r chi.Router) {
 				r.Put("/", messageHandler.UpdateMessage)
 				r.Delete("/", messageHandler.DeleteMessage)
 				r.Post("/read", messageHandler.MarkMessageAsRead)
+				r.Get("/thread", messageHandler.GetThread)
+				r.Get("/reactions", messageHandler.GetReactions)
+				r.Post("/reactions", messageHandler.ToggleReaction)
+				r.Post("/pin", messageHandler.PinMessage)
+				r.Delete("/pin", messageHandler.UnpinMessage)
 			})
 		})
+
+		r.Route("/presence", func(r chi.Router) {
+			r.Get("/", presenceHandler.ListPresence)
+			r.Get("/{user_id}", presenceHandler.GetUserPresence)
+		})
+
+		r.Route("/workout-plans", func(r chi.Router) {
+			r.With(authMiddleware.RequireCoachRole()).Post("/", messageHandler.SendWorkoutPlan)
+
+			r.Route("/{card_id}", func(r chi.Router) {
+				r.Get("/", messageHandler.GetWorkoutPlan)
+				r.Get("/events", messageHandler.GetWorkoutPlanEvents)
+				r.Post("/accept", messageHandler.AcceptWorkoutPlan)
+				r.Post("/decline", messageHandler.DeclineWorkoutPlan)
+			})
+		})
+
+		r.Route("/scheduled-messages", func(r chi.Router) {
+			r.Use(authMiddleware.RequireCoachRole())
+
+			r.Post("/", messageHandler.CreateScheduledMessage)
+			r.Get("/", messageHandler.ListScheduledMessages)
+			r.Get("/{scheduled_message_id}", messageHandler.GetScheduledMessage)
+			r.Put("/{scheduled_message_id}", messageHandler.UpdateScheduledMessage)
+			r.Delete("/{scheduled_message_id}", messageHandler.CancelScheduledMessage)
+		})
 	})
 }
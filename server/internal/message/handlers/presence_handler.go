@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/services"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+type PresenceHandler struct {
+	authMiddleware  *middleware.AuthMiddleware
+	service         services.MessageServiceManager
+	realtimeService *services.RealtimeService
+}
+
+func NewPresenceHandler(
+	service services.MessageServiceManager,
+	authMiddleware *middleware.AuthMiddleware,
+) *PresenceHandler {
+	return &PresenceHandler{
+		authMiddleware:  authMiddleware,
+		service:         service,
+		realtimeService: service.Realtime(),
+	}
+}
+
+// ListPresence returns the presence of everyone the caller shares a conversation with.
+func (h *PresenceHandler) ListPresence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	partnerIDs, err := h.service.Conversations().ListPartnerIDs(ctx, userID)
+	if err != nil {
+		log.Printf("Error listing conversation partners: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch presence")
+		return
+	}
+
+	presences, err := h.service.Presence().ListPresence(ctx, partnerIDs)
+	if err != nil {
+		log.Printf("Error fetching presence: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch presence")
+		return
+	}
+
+	if h.realtimeService != nil {
+		presences = h.realtimeService.ApplyConnectionState(presences)
+	}
+
+	online := 0
+	for _, presence := range presences {
+		if presence.Status == types.PresenceOnline {
+			online++
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"presence": presences,
+		"online":   online,
+		"total":    len(presences),
+	})
+}
+
+func (h *PresenceHandler) GetUserPresence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+	targetID := chi.URLParam(r, "user_id")
+
+	if targetID == "" {
+		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if targetID != userID {
+		partnerIDs, err := h.service.Conversations().ListPartnerIDs(ctx, userID)
+		if err != nil {
+			log.Printf("Error listing conversation partners: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to verify permissions")
+			return
+		}
+
+		isPartner := false
+		for _, partnerID := range partnerIDs {
+			if partnerID == targetID {
+				isPartner = true
+				break
+			}
+		}
+
+		if !isPartner {
+			respondError(w, http.StatusForbidden, "You do not share a conversation with this user")
+			return
+		}
+	}
+
+	presence, err := h.service.Presence().GetPresence(ctx, targetID)
+	if err != nil {
+		log.Printf("Error fetching presence: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch presence")
+		return
+	}
+
+	if h.realtimeService != nil {
+		presence = &h.realtimeService.ApplyConnectionState([]types.UserPresence{*presence})[0]
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"presence": presence,
+	})
+}
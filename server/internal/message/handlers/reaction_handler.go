@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+// loadParticipantMessage resolves the {message_id} URL param and verifies the
+// caller takes part in the message's conversation. It writes the error response
+// itself and returns false when the request should stop.
+func (h *MessageHandler) loadParticipantMessage(w http.ResponseWriter, r *http.Request, userID string) (*types.Message, bool) {
+	messageIDStr := chi.URLParam(r, "message_id")
+	messageID, err := strconv.ParseInt(messageIDStr, 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid message ID")
+		return nil, false
+	}
+
+	message, err := h.service.Messages().GetMessageByID(r.Context(), messageID)
+	if err != nil {
+		log.Printf("Error fetching message: %v", err)
+		respondError(w, http.StatusNotFound, "Message not found")
+		return nil, false
+	}
+
+	isParticipant, err := h.service.Conversations().IsParticipant(r.Context(), message.ConversationID, userID)
+	if err != nil || !isParticipant {
+		respondError(w, http.StatusForbidden, "You are not a participant in this conversation")
+		return nil, false
+	}
+
+	return message, true
+}
+
+func (h *MessageHandler) ToggleReaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	message, ok := h.loadParticipantMessage(w, r, userID)
+	if !ok {
+		return
+	}
+
+	if message.IsDeleted {
+		respondError(w, http.StatusBadRequest, "Cannot react to a deleted message")
+		return
+	}
+
+	var req types.ToggleReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	reaction, added, err := h.service.Reactions().ToggleReaction(ctx, message.MessageID, userID, req.Emoji)
+	if err != nil {
+		if err == types.ErrInvalidEmoji {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error toggling reaction: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update reaction")
+		return
+	}
+
+	if h.realtimeService != nil {
+		if err := h.realtimeService.BroadcastReactionChanged(ctx, message.ConversationID, reaction, added); err != nil {
+			log.Printf("Failed to broadcast reaction: %v", err)
+		}
+	}
+
+	reactions, err := h.service.Reactions().ListReactions(ctx, message.MessageID, userID)
+	if err != nil {
+		log.Printf("Error listing reactions: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch reactions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"added":     added,
+		"reaction":  reaction,
+		"reactions": reactions,
+	})
+}
+
+func (h *MessageHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	message, ok := h.loadParticipantMessage(w, r, userID)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.service.Reactions().ListReactions(ctx, message.MessageID, userID)
+	if err != nil {
+		log.Printf("Error listing reactions: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch reactions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message_id": message.MessageID,
+		"reactions":  reactions,
+	})
+}
+
+func (h *MessageHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	message, ok := h.loadParticipantMessage(w, r, userID)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	offset := 0
+	if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
+		if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	thread, err := h.service.Messages().GetThread(ctx, message.MessageID, userID, limit, offset)
+	if err != nil {
+		log.Printf("Error fetching thread: %v", err)
+		respondError(w, types.GetHTTPStatus(err), "Failed to fetch thread")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, thread)
+}
+
+func (h *MessageHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	message, ok := h.loadParticipantMessage(w, r, userID)
+	if !ok {
+		return
+	}
+
+	if message.IsDeleted {
+		respondError(w, http.StatusBadRequest, "Cannot pin a deleted message")
+		return
+	}
+
+	pin, err := h.service.Pins().PinMessage(ctx, message.ConversationID, message.MessageID, userID)
+	if err != nil {
+		if err == types.ErrPinLimitReached {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		log.Printf("Error pinning message: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to pin message")
+		return
+	}
+
+	if h.realtimeService != nil {
+		if err := h.realtimeService.BroadcastMessagePinned(ctx, message.ConversationID, pin); err != nil {
+			log.Printf("Failed to broadcast pin: %v", err)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pin": pin,
+	})
+}
+
+func (h *MessageHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	message, ok := h.loadParticipantMessage(w, r, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Pins().UnpinMessage(ctx, message.ConversationID, message.MessageID); err != nil {
+		if err == types.ErrMessageNotPinned {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("Error unpinning message: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to unpin message")
+		return
+	}
+
+	if h.realtimeService != nil {
+		if err := h.realtimeService.BroadcastMessageUnpinned(ctx, message.ConversationID, message.MessageID); err != nil {
+			log.Printf("Failed to broadcast unpin: %v", err)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Message unpinned",
+	})
+}
+
+func (h *MessageHandler) GetPinnedMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	conversationIDStr := chi.URLParam(r, "conversation_id")
+	conversationID, err := strconv.Atoi(conversationIDStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+
+	isParticipant, err := h.service.Conversations().IsParticipant(ctx, conversationID, userID)
+	if err != nil || !isParticipant {
+		respondError(w, http.StatusForbidden, "You are not a participant in this conversation")
+		return
+	}
+
+	pins, err := h.service.Pins().ListPinnedMessages(ctx, conversationID, userID)
+	if err != nil {
+		log.Printf("Error listing pinned messages: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch pinned messages")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"conversation_id": conversationID,
+		"pinned":          pins,
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+func (h *MessageHandler) CreateScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	coachID := middleware.GetAuthIDFromContext(ctx)
+
+	var req types.CreateScheduledMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	scheduled, err := h.service.ScheduledMessages().CreateScheduledMessage(ctx, coachID, &req)
+	if err != nil {
+		respondScheduledMessageError(w, err, "Failed to schedule message")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"scheduled_message": scheduled,
+	})
+}
+
+func (h *MessageHandler) ListScheduledMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	coachID := middleware.GetAuthIDFromContext(ctx)
+
+	limit := 20
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	offset := 0
+	if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
+		if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+
+	var status *types.ScheduledMessageStatus
+	if statusParam := r.URL.Query().Get("status"); statusParam != "" {
+		value := types.ScheduledMessageStatus(statusParam)
+		switch value {
+		case types.ScheduledMessageActive, types.ScheduledMessageCompleted, types.ScheduledMessageCancelled:
+			status = &value
+		default:
+			respondError(w, http.StatusBadRequest, "Invalid status filter")
+			return
+		}
+	}
+
+	response, err := h.service.ScheduledMessages().ListScheduledMessages(ctx, coachID, status, limit, offset)
+	if err != nil {
+		respondScheduledMessageError(w, err, "Failed to fetch scheduled messages")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+func (h *MessageHandler) GetScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	coachID := middleware.GetAuthIDFromContext(ctx)
+
+	scheduledMessageID, ok := parseScheduledMessageID(w, r)
+	if !ok {
+		return
+	}
+
+	scheduled, err := h.service.ScheduledMessages().GetScheduledMessage(ctx, coachID, scheduledMessageID)
+	if err != nil {
+		respondScheduledMessageError(w, err, "Failed to fetch scheduled message")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"scheduled_message": scheduled,
+	})
+}
+
+func (h *MessageHandler) UpdateScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	coachID := middleware.GetAuthIDFromContext(ctx)
+
+	scheduledMessageID, ok := parseScheduledMessageID(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.UpdateScheduledMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	scheduled, err := h.service.ScheduledMessages().UpdateScheduledMessage(ctx, coachID, scheduledMessageID, &req)
+	if err != nil {
+		respondScheduledMessageError(w, err, "Failed to update scheduled message")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"scheduled_message": scheduled,
+	})
+}
+
+func (h *MessageHandler) CancelScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	coachID := middleware.GetAuthIDFromContext(ctx)
+
+	scheduledMessageID, ok := parseScheduledMessageID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ScheduledMessages().CancelScheduledMessage(ctx, coachID, scheduledMessageID); err != nil {
+		respondScheduledMessageError(w, err, "Failed to cancel scheduled message")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Scheduled message cancelled",
+	})
+}
+
+func parseScheduledMessageID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	scheduledMessageID, err := strconv.ParseInt(chi.URLParam(r, "scheduled_message_id"), 10, 64)
+	if err != nil || scheduledMessageID <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid scheduled message ID")
+		return 0, false
+	}
+	return scheduledMessageID, true
+}
+
+func respondScheduledMessageError(w http.ResponseWriter, err error, fallback string) {
+	status := types.GetHTTPStatus(err)
+	if status == types.StatusInternalError {
+		log.Printf("%s: %v", fallback, err)
+		respondError(w, status, fallback)
+		return
+	}
+	respondError(w, status, err.Error())
+}
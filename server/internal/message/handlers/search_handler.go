@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+func (h *MessageHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+	query := r.URL.Query()
+
+	params := types.MessageSearchParams{
+		Query:  query.Get("q"),
+		Cursor: query.Get("cursor"),
+	}
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
+			params.Limit = parsedLimit
+		}
+	}
+
+	if conversationParam := query.Get("conversation_id"); conversationParam != "" {
+		conversationID, err := strconv.Atoi(conversationParam)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid conversation ID")
+			return
+		}
+		params.ConversationID = &conversationID
+	}
+
+	if senderID := query.Get("sender_id"); senderID != "" {
+		params.SenderID = &senderID
+	}
+
+	if from := query.Get("from"); from != "" {
+		startDate, err := parseSearchDate(from)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD or RFC3339")
+			return
+		}
+		params.StartDate = &startDate
+	}
+
+	if to := query.Get("to"); to != "" {
+		endDate, err := parseSearchDate(to)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD or RFC3339")
+			return
+		}
+		// A bare date includes the whole day
+		if len(to) == len("2006-01-02") {
+			endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+		}
+		params.EndDate = &endDate
+	}
+
+	if hasAttachment := query.Get("has_attachment"); hasAttachment != "" {
+		value, err := strconv.ParseBool(hasAttachment)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid has_attachment value")
+			return
+		}
+		params.HasAttachment = &value
+	}
+
+	results, err := h.service.Messages().SearchMessages(ctx, userID, params)
+	if err != nil {
+		switch err {
+		case types.ErrInvalidSearchQuery, types.ErrInvalidSearchCursor:
+			respondError(w, http.StatusBadRequest, err.Error())
+		case types.ErrNotParticipant:
+			respondError(w, http.StatusForbidden, "You are not a participant in this conversation")
+		default:
+			log.Printf("Error searching messages: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to search messages")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, results)
+}
+
+func parseSearchDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	schemaTypes "github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+func (h *MessageHandler) SendWorkoutPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	coachID := middleware.GetAuthIDFromContext(ctx)
+
+	var req types.SendWorkoutPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	message, err := h.service.WorkoutPlans().SendWorkoutPlan(ctx, coachID, &req)
+	if err != nil {
+		respondWorkoutPlanError(w, err, "Failed to send workout plan")
+		return
+	}
+
+	if h.realtimeService != nil {
+		if err := h.realtimeService.BroadcastNewMessage(ctx, message.ConversationID, message); err != nil {
+			log.Printf("Failed to broadcast workout plan message: %v", err)
+		}
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": message,
+	})
+}
+
+func (h *MessageHandler) GetWorkoutPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	cardID, ok := parseWorkoutPlanCardID(w, r)
+	if !ok {
+		return
+	}
+
+	card, err := h.service.WorkoutPlans().GetWorkoutPlan(ctx, cardID, userID)
+	if err != nil {
+		respondWorkoutPlanError(w, err, "Failed to fetch workout plan")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"workout_plan": card,
+	})
+}
+
+func (h *MessageHandler) AcceptWorkoutPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	cardID, ok := parseWorkoutPlanCardID(w, r)
+	if !ok {
+		return
+	}
+
+	card, err := h.service.WorkoutPlans().AcceptWorkoutPlan(ctx, cardID, userID)
+	if err != nil {
+		respondWorkoutPlanError(w, err, "Failed to accept workout plan")
+		return
+	}
+
+	h.broadcastWorkoutPlan(r, card)
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"workout_plan": card,
+	})
+}
+
+func (h *MessageHandler) DeclineWorkoutPlan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	cardID, ok := parseWorkoutPlanCardID(w, r)
+	if !ok {
+		return
+	}
+
+	card, err := h.service.WorkoutPlans().DeclineWorkoutPlan(ctx, cardID, userID)
+	if err != nil {
+		respondWorkoutPlanError(w, err, "Failed to decline workout plan")
+		return
+	}
+
+	h.broadcastWorkoutPlan(r, card)
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"workout_plan": card,
+	})
+}
+
+func (h *MessageHandler) GetWorkoutPlanEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := middleware.GetAuthIDFromContext(ctx)
+
+	cardID, ok := parseWorkoutPlanCardID(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := h.service.WorkoutPlans().ListWorkoutPlanEvents(ctx, cardID, userID)
+	if err != nil {
+		respondWorkoutPlanError(w, err, "Failed to fetch workout plan history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"card_id": cardID,
+		"events":  events,
+	})
+}
+
+func (h *MessageHandler) broadcastWorkoutPlan(r *http.Request, card *types.WorkoutPlanCard) {
+	if h.realtimeService == nil {
+		return
+	}
+	if err := h.realtimeService.BroadcastWorkoutPlanUpdated(r.Context(), card); err != nil {
+		log.Printf("Failed to broadcast workout plan update: %v", err)
+	}
+}
+
+func parseWorkoutPlanCardID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	cardID, err := strconv.ParseInt(chi.URLParam(r, "card_id"), 10, 64)
+	if err != nil || cardID <= 0 {
+		respondError(w, http.StatusBadRequest, "Invalid workout plan ID")
+		return 0, false
+	}
+	return cardID, true
+}
+
+func respondWorkoutPlanError(w http.ResponseWriter, err error, fallback string) {
+	var schemaErr *schemaTypes.SchemaError
+	if errors.As(err, &schemaErr) {
+		switch schemaErr {
+		case schemaTypes.ErrSharedPlanDenied:
+			respondError(w, http.StatusForbidden, schemaErr.Message)
+		case schemaTypes.ErrPlanNotFound:
+			respondError(w, http.StatusNotFound, schemaErr.Message)
+		case schemaTypes.ErrCoachingSubscriptionLapsed:
+			respondError(w, http.StatusPaymentRequired, schemaErr.Message)
+		default:
+			respondError(w, http.StatusBadRequest, schemaErr.Message)
+		}
+		return
+	}
+
+	if err == types.ErrWorkoutPlansUnavailable {
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	status := types.GetHTTPStatus(err)
+	if status == types.StatusInternalError {
+		log.Printf("%s: %v", fallback, err)
+		respondError(w, status, fallback)
+		return
+	}
+	respondError(w, status, err.Error())
+}
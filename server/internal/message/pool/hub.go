@@ -26,8 +26,12 @@ type Hub struct {
 	subscriptions map[string]map[string]bool
 	mutex         sync.RWMutex
 	done          chan struct{}
+	eventHandler  EventHandler
 }
 
+// EventHandler receives inbound client frames other than the "pong" heartbeat.
+type EventHandler func(userID string, payload []byte)
+
 type Connection struct {
 	conn     *websocket.Conn
 	userID   string
@@ -239,7 +243,16 @@ func (c *Connection) readPump() {
 			continue
 		}
 
-		log.Printf("Received message from %s: %s", c.userID, msg)
+		c.hub.mutex.RLock()
+		handler := c.hub.eventHandler
+		c.hub.mutex.RUnlock()
+
+		if handler == nil {
+			log.Printf("Received message from %s: %s", c.userID, msg)
+			continue
+		}
+
+		handler(c.userID, []byte(msg))
 	}
 }
 
@@ -255,6 +268,13 @@ func (c *Connection) close() {
 	c.conn.Close()
 }
 
+func (h *Hub) SetEventHandler(handler EventHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.eventHandler = handler
+}
+
 func (h *Hub) Connect(userID string, wsConn *websocket.Conn) {
 	if userID == "" || wsConn == nil {
 		return
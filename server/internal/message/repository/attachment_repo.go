@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
 func (s *Store) CreateAttachment(ctx context.Context, messageID int64, attachmentType types.AttachmentType, fileName, fileURL string) (*types.MessageAttachment, error) {
@@ -14,7 +15,7 @@ func (s *Store) CreateAttachment(ctx context.Context, messageID int64, attachmen
 		RETURNING attachment_id, message_id, attachment_type, file_name, file_url, file_size, mime_type, uploaded_at
 	`
 	var attachment types.MessageAttachment
-	if err := s.db.QueryRow(ctx, q, messageID, attachmentType, fileName, fileURL).Scan(
+	if err := database.Conn(ctx, s.db).QueryRow(ctx, q, messageID, attachmentType, fileName, fileURL).Scan(
 		&attachment.AttachmentID,
 		&attachment.MessageID,
 		&attachment.AttachmentType,
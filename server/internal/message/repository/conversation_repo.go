@@ -191,3 +191,32 @@ func (s *Store) IsParticipant(ctx context.Context, conversationID int, userID st
 	err := s.db.QueryRow(ctx, q, conversationID, userID).Scan(&exists)
 	return exists, err
 }
+
+func (s *Store) ListConversationPartnerIDs(ctx context.Context, userID string) ([]string, error) {
+	q := `
+		SELECT DISTINCT CASE WHEN coach_id = $1 THEN client_id ELSE coach_id END
+		FROM conversations
+		WHERE (coach_id = $1 OR client_id = $1) AND is_archived = false
+	`
+
+	rows, err := s.db.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partnerIDs []string
+	for rows.Next() {
+		var partnerID string
+		if err := rows.Scan(&partnerID); err != nil {
+			return nil, err
+		}
+		partnerIDs = append(partnerIDs, partnerID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return partnerIDs, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/tdmdh/fit-up-server/internal/message/types"
 )
@@ -12,12 +13,16 @@ type ConversationRepo interface {
 	GetConversationByParticipants(ctx context.Context, coachID, clientID string) (*types.Conversation, error)
 	ListConversationsByUser(ctx context.Context, userID string, includeArchived bool, limit, offset int) ([]types.ConversationOverview, int, error)
 	IsParticipant(ctx context.Context, conversationID int, userID string) (bool, error)
+	ListConversationPartnerIDs(ctx context.Context, userID string) ([]string, error)
 }
 
 type MessageRepo interface {
 	CreateMessage(ctx context.Context, conversationID int, senderID, messageText string, replyToMessageID *int64) (*types.Message, error)
 	GetMessageByID(ctx context.Context, messageID int64) (*types.Message, error)
 	ListMessages(ctx context.Context, conversationID int, userID string, limit, offset int) ([]types.MessageWithDetails, int, error)
+	GetMessageWithDetails(ctx context.Context, messageID int64, userID string) (*types.MessageWithDetails, error)
+	ListReplies(ctx context.Context, messageID int64, userID string, limit, offset int) ([]types.MessageWithDetails, int, error)
+	SearchMessages(ctx context.Context, userID string, params types.MessageSearchParams, cursor *types.MessageSearchCursor, limit int) ([]types.MessageSearchResult, error)
 
 	UpdateMessage(ctx context.Context, messageID int64, messageText string) error
 	DeleteMessage(ctx context.Context, messageID int64) error
@@ -35,10 +40,58 @@ type MessageAttachmentRepo interface {
 	DeleteAttachment(ctx context.Context, attachmentID int64) error
 }
 
+type MessageReactionRepo interface {
+	ToggleReaction(ctx context.Context, messageID int64, userID, emoji string) (*types.MessageReaction, bool, error)
+	ListReactionsByMessage(ctx context.Context, messageID int64) ([]types.MessageReaction, error)
+}
+
+type PinnedMessageRepo interface {
+	PinMessage(ctx context.Context, conversationID int, messageID int64, userID string) (*types.PinnedMessage, error)
+	UnpinMessage(ctx context.Context, conversationID int, messageID int64) (bool, error)
+	CountPinnedMessages(ctx context.Context, conversationID int) (int, error)
+	ListPinnedMessages(ctx context.Context, conversationID int, userID string) ([]types.PinnedMessage, error)
+}
+
+type ScheduledMessageRepo interface {
+	CreateScheduledMessage(ctx context.Context, msg *types.ScheduledMessage) error
+	GetScheduledMessage(ctx context.Context, scheduledMessageID int64) (*types.ScheduledMessage, error)
+	ListScheduledMessagesByCoach(ctx context.Context, coachID string, status *types.ScheduledMessageStatus, limit, offset int) ([]types.ScheduledMessage, int, error)
+	UpdateScheduledMessage(ctx context.Context, msg *types.ScheduledMessage) error
+	CancelScheduledMessage(ctx context.Context, scheduledMessageID int64) error
+	GetUserTimezones(ctx context.Context, userIDs []string) (map[string]string, error)
+
+	ClaimDueDeliveries(ctx context.Context, now time.Time, limit int) ([]types.DueScheduledDelivery, error)
+	DeliverScheduledMessage(ctx context.Context, delivery *types.DueScheduledDelivery, sentAt time.Time, nextRunAt *time.Time) (*types.Message, error)
+	ReleaseDelivery(ctx context.Context, recipientID int64) error
+	SkipDelivery(ctx context.Context, recipientID int64, nextRunAt *time.Time) error
+}
+
+type WorkoutPlanCardRepo interface {
+	CreateWorkoutPlanCard(ctx context.Context, card *types.WorkoutPlanCard) error
+	GetWorkoutPlanCard(ctx context.Context, cardID int64) (*types.WorkoutPlanCard, error)
+	GetWorkoutPlanCardByMessage(ctx context.Context, messageID int64) (*types.WorkoutPlanCard, error)
+	RespondToWorkoutPlanCard(ctx context.Context, cardID int64, status types.WorkoutPlanCardStatus, acceptedSchemaID *int) (*types.WorkoutPlanCard, error)
+	SetWorkoutPlanCardSchema(ctx context.Context, cardID int64, schemaID int) (*types.WorkoutPlanCard, error)
+
+	CreateWorkoutPlanCardEvent(ctx context.Context, event *types.WorkoutPlanCardEvent) error
+	ListWorkoutPlanCardEvents(ctx context.Context, cardID int64) ([]types.WorkoutPlanCardEvent, error)
+}
+
+type PresenceRepo interface {
+	UpsertPresence(ctx context.Context, userID string, status types.PresenceStatus) (*types.UserPresence, error)
+	GetPresence(ctx context.Context, userID string) (*types.UserPresence, error)
+	ListPresenceByUsers(ctx context.Context, userIDs []string) ([]types.UserPresence, error)
+}
+
 type MessageStore interface {
 	Conversations() ConversationRepo
 	Messages() MessageRepo
 	ReadStatus() MessageReadStatusRepo
 	Attachments() MessageAttachmentRepo
+	Reactions() MessageReactionRepo
+	Pins() PinnedMessageRepo
+	Presence() PresenceRepo
+	ScheduledMessages() ScheduledMessageRepo
+	WorkoutPlans() WorkoutPlanCardRepo
 	WithTransaction(ctx context.Context, fn func(context.Context) error) error
 }
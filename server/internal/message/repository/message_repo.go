@@ -3,18 +3,20 @@ package repository
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
-func (s *Store) CreateMessage(ctx context.Context, conversationID int, senderID, messageText string, replyToMessageID *int64) (*types.Message, error) {
-	q := `
-		INSERT INTO messages (conversation_id, sender_id, message_text, reply_to_message_id)
-		VALUES ($1, $2, $3, $4)
-		RETURNING message_id, conversation_id, sender_id, message_text, reply_to_message_id, sent_at, edited_at
-	`
+const insertMessageQuery = `
+	INSERT INTO messages (conversation_id, sender_id, message_text, reply_to_message_id)
+	VALUES ($1, $2, $3, $4)
+	RETURNING message_id, conversation_id, sender_id, message_text, reply_to_message_id, sent_at, edited_at
+`
 
+func scanInsertedMessage(row pgx.Row) (*types.Message, error) {
 	var msg types.Message
-	err := s.db.QueryRow(ctx, q, conversationID, senderID, messageText, replyToMessageID).Scan(
+	err := row.Scan(
 		&msg.MessageID,
 		&msg.ConversationID,
 		&msg.SenderID,
@@ -29,6 +31,10 @@ func (s *Store) CreateMessage(ctx context.Context, conversationID int, senderID,
 	return &msg, nil
 }
 
+func (s *Store) CreateMessage(ctx context.Context, conversationID int, senderID, messageText string, replyToMessageID *int64) (*types.Message, error) {
+	return scanInsertedMessage(database.Conn(ctx, s.db).QueryRow(ctx, insertMessageQuery, conversationID, senderID, messageText, replyToMessageID))
+}
+
 func (s *Store) GetMessageByID(ctx context.Context, messageID int64) (*types.Message, error) {
 	q := `
 		SELECT message_id, conversation_id, sender_id, message_text, reply_to_message_id, sent_at, edited_at, is_deleted, deleted_at
@@ -142,3 +148,114 @@ func (s *Store) DeleteMessage(ctx context.Context, messageID int64) error {
 	_, err := s.db.Exec(ctx, q, messageID)
 	return err
 }
+
+func (s *Store) GetMessageWithDetails(ctx context.Context, messageID int64, userID string) (*types.MessageWithDetails, error) {
+	q := `
+		SELECT 
+			m.message_id,
+			m.conversation_id,
+			m.sender_id,
+			m.message_text,
+			m.reply_to_message_id,
+			m.sent_at,
+			m.edited_at,
+			m.is_deleted,
+			m.deleted_at,
+			u.name AS sender_name,
+			u.image AS sender_image,
+			COALESCE(rs.read_at IS NOT NULL, false) AS is_read
+		FROM messages m
+		JOIN users u ON m.sender_id = u.id
+		LEFT JOIN message_read_status rs ON rs.message_id = m.message_id AND rs.user_id = $2
+		WHERE m.message_id = $1
+	`
+
+	var msg types.MessageWithDetails
+	err := s.db.QueryRow(ctx, q, messageID, userID).Scan(
+		&msg.MessageID,
+		&msg.ConversationID,
+		&msg.SenderID,
+		&msg.MessageText,
+		&msg.ReplyToMessageID,
+		&msg.SentAt,
+		&msg.EditedAt,
+		&msg.IsDeleted,
+		&msg.DeletedAt,
+		&msg.SenderName,
+		&msg.SenderImage,
+		&msg.IsRead,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (s *Store) ListReplies(ctx context.Context, messageID int64, userID string, limit, offset int) ([]types.MessageWithDetails, int, error) {
+	q := `
+		SELECT 
+			m.message_id,
+			m.conversation_id,
+			m.sender_id,
+			m.message_text,
+			m.reply_to_message_id,
+			m.sent_at,
+			m.edited_at,
+			m.is_deleted,
+			m.deleted_at,
+			u.name AS sender_name,
+			u.image AS sender_image,
+			COALESCE(rs.read_at IS NOT NULL, false) AS is_read
+		FROM messages m
+		JOIN users u ON m.sender_id = u.id
+		LEFT JOIN message_read_status rs ON rs.message_id = m.message_id AND rs.user_id = $2
+		WHERE m.reply_to_message_id = $1 AND m.is_deleted = false
+		ORDER BY m.sent_at ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := s.db.Query(ctx, q, messageID, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var replies []types.MessageWithDetails
+	for rows.Next() {
+		var msg types.MessageWithDetails
+		if err := rows.Scan(
+			&msg.MessageID,
+			&msg.ConversationID,
+			&msg.SenderID,
+			&msg.MessageText,
+			&msg.ReplyToMessageID,
+			&msg.SentAt,
+			&msg.EditedAt,
+			&msg.IsDeleted,
+			&msg.DeletedAt,
+			&msg.SenderName,
+			&msg.SenderImage,
+			&msg.IsRead,
+		); err != nil {
+			return nil, 0, err
+		}
+		replies = append(replies, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM messages
+		WHERE reply_to_message_id = $1 AND is_deleted = false
+	`
+
+	var total int
+	if err := s.db.QueryRow(ctx, countQuery, messageID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return replies, total, nil
+}
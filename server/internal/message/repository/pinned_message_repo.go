@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+func (s *Store) PinMessage(ctx context.Context, conversationID int, messageID int64, userID string) (*types.PinnedMessage, error) {
+	q := `
+		INSERT INTO pinned_messages (conversation_id, message_id, pinned_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (conversation_id, message_id) DO UPDATE
+		SET pinned_by = EXCLUDED.pinned_by, pinned_at = NOW()
+		RETURNING pin_id, conversation_id, message_id, pinned_by, pinned_at
+	`
+
+	var pin types.PinnedMessage
+	err := s.db.QueryRow(ctx, q, conversationID, messageID, userID).Scan(
+		&pin.PinID,
+		&pin.ConversationID,
+		&pin.MessageID,
+		&pin.PinnedBy,
+		&pin.PinnedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pin, nil
+}
+
+func (s *Store) UnpinMessage(ctx context.Context, conversationID int, messageID int64) (bool, error) {
+	q := `DELETE FROM pinned_messages WHERE conversation_id = $1 AND message_id = $2`
+
+	tag, err := s.db.Exec(ctx, q, conversationID, messageID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *Store) CountPinnedMessages(ctx context.Context, conversationID int) (int, error) {
+	q := `SELECT COUNT(*) FROM pinned_messages WHERE conversation_id = $1`
+
+	var count int
+	err := s.db.QueryRow(ctx, q, conversationID).Scan(&count)
+	return count, err
+}
+
+func (s *Store) ListPinnedMessages(ctx context.Context, conversationID int, userID string) ([]types.PinnedMessage, error) {
+	q := `
+		SELECT
+			p.pin_id,
+			p.conversation_id,
+			p.message_id,
+			p.pinned_by,
+			p.pinned_at,
+			m.message_id,
+			m.conversation_id,
+			m.sender_id,
+			m.message_text,
+			m.reply_to_message_id,
+			m.sent_at,
+			m.edited_at,
+			m.is_deleted,
+			m.deleted_at,
+			u.name AS sender_name,
+			u.image AS sender_image,
+			COALESCE(rs.read_at IS NOT NULL, false) AS is_read
+		FROM pinned_messages p
+		JOIN messages m ON m.message_id = p.message_id
+		JOIN users u ON m.sender_id = u.id
+		LEFT JOIN message_read_status rs ON rs.message_id = m.message_id AND rs.user_id = $2
+		WHERE p.conversation_id = $1 AND m.is_deleted = false
+		ORDER BY p.pinned_at DESC
+	`
+
+	rows, err := s.db.Query(ctx, q, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pins []types.PinnedMessage
+	for rows.Next() {
+		var pin types.PinnedMessage
+		if err := rows.Scan(
+			&pin.PinID,
+			&pin.ConversationID,
+			&pin.MessageID,
+			&pin.PinnedBy,
+			&pin.PinnedAt,
+			&pin.Message.MessageID,
+			&pin.Message.ConversationID,
+			&pin.Message.SenderID,
+			&pin.Message.MessageText,
+			&pin.Message.ReplyToMessageID,
+			&pin.Message.SentAt,
+			&pin.Message.EditedAt,
+			&pin.Message.IsDeleted,
+			&pin.Message.DeletedAt,
+			&pin.Message.SenderName,
+			&pin.Message.SenderImage,
+			&pin.Message.IsRead,
+		); err != nil {
+			return nil, err
+		}
+		pins = append(pins, pin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pins, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+func (s *Store) UpsertPresence(ctx context.Context, userID string, status types.PresenceStatus) (*types.UserPresence, error) {
+	q := `
+		INSERT INTO user_presence (user_id, status, last_seen_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET status = EXCLUDED.status, last_seen_at = EXCLUDED.last_seen_at
+		RETURNING user_id, status, last_seen_at
+	`
+
+	var presence types.UserPresence
+	err := s.db.QueryRow(ctx, q, userID, status).Scan(
+		&presence.UserID,
+		&presence.Status,
+		&presence.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &presence, nil
+}
+
+func (s *Store) GetPresence(ctx context.Context, userID string) (*types.UserPresence, error) {
+	q := `
+		SELECT user_id, status, last_seen_at
+		FROM user_presence
+		WHERE user_id = $1
+	`
+
+	var presence types.UserPresence
+	err := s.db.QueryRow(ctx, q, userID).Scan(
+		&presence.UserID,
+		&presence.Status,
+		&presence.LastSeenAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &presence, nil
+}
+
+func (s *Store) ListPresenceByUsers(ctx context.Context, userIDs []string) ([]types.UserPresence, error) {
+	q := `
+		SELECT user_id, status, last_seen_at
+		FROM user_presence
+		WHERE user_id = ANY($1)
+	`
+
+	rows, err := s.db.Query(ctx, q, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var presences []types.UserPresence
+	for rows.Next() {
+		var presence types.UserPresence
+		if err := rows.Scan(
+			&presence.UserID,
+			&presence.Status,
+			&presence.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		presences = append(presences, presence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return presences, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+// ToggleReaction removes the user's reaction if it exists, otherwise adds it.
+// The returned bool reports whether the reaction was added.
+func (s *Store) ToggleReaction(ctx context.Context, messageID int64, userID, emoji string) (*types.MessageReaction, bool, error) {
+	deleteQuery := `
+		DELETE FROM message_reactions
+		WHERE message_id = $1 AND user_id = $2 AND emoji = $3
+		RETURNING reaction_id, message_id, user_id, emoji, created_at
+	`
+
+	var reaction types.MessageReaction
+	err := s.db.QueryRow(ctx, deleteQuery, messageID, userID, emoji).Scan(
+		&reaction.ReactionID,
+		&reaction.MessageID,
+		&reaction.UserID,
+		&reaction.Emoji,
+		&reaction.CreatedAt,
+	)
+	if err == nil {
+		return &reaction, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) && !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	insertQuery := `
+		INSERT INTO message_reactions (message_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+		RETURNING reaction_id, message_id, user_id, emoji, created_at
+	`
+
+	if err := s.db.QueryRow(ctx, insertQuery, messageID, userID, emoji).Scan(
+		&reaction.ReactionID,
+		&reaction.MessageID,
+		&reaction.UserID,
+		&reaction.Emoji,
+		&reaction.CreatedAt,
+	); err != nil {
+		return nil, false, err
+	}
+
+	return &reaction, true, nil
+}
+
+func (s *Store) ListReactionsByMessage(ctx context.Context, messageID int64) ([]types.MessageReaction, error) {
+	q := `
+		SELECT reaction_id, message_id, user_id, emoji, created_at
+		FROM message_reactions
+		WHERE message_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := s.db.Query(ctx, q, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []types.MessageReaction
+	for rows.Next() {
+		var reaction types.MessageReaction
+		if err := rows.Scan(
+			&reaction.ReactionID,
+			&reaction.MessageID,
+			&reaction.UserID,
+			&reaction.Emoji,
+			&reaction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reactions, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
 type Store struct {
@@ -31,23 +32,28 @@ func (s *Store) Attachments() MessageAttachmentRepo {
 	return s
 }
 
-func (s *Store) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
-	tx, err := s.db.Begin(ctx)
-	if err != nil {
-		return err
-	}
+func (s *Store) Reactions() MessageReactionRepo {
+	return s
+}
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback(ctx)
-			panic(p)
-		}
-	}()
+func (s *Store) Pins() PinnedMessageRepo {
+	return s
+}
 
-	if err := fn(ctx); err != nil {
-		tx.Rollback(ctx)
-		return err
-	}
+func (s *Store) Presence() PresenceRepo {
+	return s
+}
+
+func (s *Store) ScheduledMessages() ScheduledMessageRepo {
+	return s
+}
 
-	return tx.Commit(ctx)
+func (s *Store) WorkoutPlans() WorkoutPlanCardRepo {
+	return s
+}
+
+// WithTransaction runs fn in one transaction. Repository calls made with the
+// context fn receives, including those of other modules' stores, take part in it.
+func (s *Store) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return database.InTx(ctx, s.db, fn)
 }
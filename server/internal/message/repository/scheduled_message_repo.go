@@ -0,0 +1,432 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+func (s *Store) CreateScheduledMessage(ctx context.Context, msg *types.ScheduledMessage) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := `
+		INSERT INTO scheduled_messages (coach_id, message_text, send_at, cron_expression)
+		VALUES ($1, $2, $3, $4)
+		RETURNING scheduled_message_id, status, created_at, updated_at
+	`
+	if err := tx.QueryRow(ctx, q, msg.CoachID, msg.MessageText, msg.SendAt, msg.CronExpression).Scan(
+		&msg.ScheduledMessageID,
+		&msg.Status,
+		&msg.CreatedAt,
+		&msg.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to create scheduled message: %w", err)
+	}
+
+	recipientQuery := `
+		INSERT INTO scheduled_message_recipients (scheduled_message_id, conversation_id, client_id, next_run_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING recipient_id, status
+	`
+	for i := range msg.Recipients {
+		recipient := &msg.Recipients[i]
+		recipient.ScheduledMessageID = msg.ScheduledMessageID
+		if err := tx.QueryRow(ctx, recipientQuery, msg.ScheduledMessageID, recipient.ConversationID, recipient.ClientID, recipient.NextRunAt).Scan(
+			&recipient.RecipientID,
+			&recipient.Status,
+		); err != nil {
+			return fmt.Errorf("failed to create scheduled message recipient: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *Store) GetScheduledMessage(ctx context.Context, scheduledMessageID int64) (*types.ScheduledMessage, error) {
+	q := `
+		SELECT scheduled_message_id, coach_id, message_text, send_at, cron_expression, status, created_at, updated_at
+		FROM scheduled_messages
+		WHERE scheduled_message_id = $1
+	`
+
+	var msg types.ScheduledMessage
+	err := s.db.QueryRow(ctx, q, scheduledMessageID).Scan(
+		&msg.ScheduledMessageID,
+		&msg.CoachID,
+		&msg.MessageText,
+		&msg.SendAt,
+		&msg.CronExpression,
+		&msg.Status,
+		&msg.CreatedAt,
+		&msg.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return nil, types.ErrScheduledMessageNotFound
+		}
+		return nil, err
+	}
+
+	recipients, err := s.listScheduledRecipients(ctx, []int64{msg.ScheduledMessageID})
+	if err != nil {
+		return nil, err
+	}
+	msg.Recipients = recipients[msg.ScheduledMessageID]
+
+	return &msg, nil
+}
+
+func (s *Store) ListScheduledMessagesByCoach(ctx context.Context, coachID string, status *types.ScheduledMessageStatus, limit, offset int) ([]types.ScheduledMessage, int, error) {
+	baseQuery := `
+		SELECT scheduled_message_id, coach_id, message_text, send_at, cron_expression, status, created_at, updated_at
+		FROM scheduled_messages
+		WHERE coach_id = $1
+	`
+	countQuery := `SELECT COUNT(*) FROM scheduled_messages WHERE coach_id = $1`
+
+	params := []interface{}{coachID}
+	if status != nil {
+		params = append(params, *status)
+		baseQuery += " AND status = $2"
+		countQuery += " AND status = $2"
+	}
+
+	var total int
+	if err := s.db.QueryRow(ctx, countQuery, params...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limitPlaceholder := len(params) + 1
+	offsetPlaceholder := limitPlaceholder + 1
+	baseQuery += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", limitPlaceholder, offsetPlaceholder)
+	params = append(params, limit, offset)
+
+	rows, err := s.db.Query(ctx, baseQuery, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var messages []types.ScheduledMessage
+	var ids []int64
+	for rows.Next() {
+		var msg types.ScheduledMessage
+		if err := rows.Scan(
+			&msg.ScheduledMessageID,
+			&msg.CoachID,
+			&msg.MessageText,
+			&msg.SendAt,
+			&msg.CronExpression,
+			&msg.Status,
+			&msg.CreatedAt,
+			&msg.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, msg)
+		ids = append(ids, msg.ScheduledMessageID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	recipients, err := s.listScheduledRecipients(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range messages {
+		messages[i].Recipients = recipients[messages[i].ScheduledMessageID]
+	}
+
+	return messages, total, nil
+}
+
+func (s *Store) listScheduledRecipients(ctx context.Context, scheduledMessageIDs []int64) (map[int64][]types.ScheduledMessageRecipient, error) {
+	result := make(map[int64][]types.ScheduledMessageRecipient)
+	if len(scheduledMessageIDs) == 0 {
+		return result, nil
+	}
+
+	q := `
+		SELECT r.recipient_id, r.scheduled_message_id, r.conversation_id, r.client_id, u.timezone,
+		       r.next_run_at, r.last_sent_at, r.last_message_id, r.status
+		FROM scheduled_message_recipients r
+		JOIN users u ON u.id = r.client_id
+		WHERE r.scheduled_message_id = ANY($1)
+		ORDER BY r.recipient_id
+	`
+
+	rows, err := s.db.Query(ctx, q, scheduledMessageIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipient types.ScheduledMessageRecipient
+		if err := rows.Scan(
+			&recipient.RecipientID,
+			&recipient.ScheduledMessageID,
+			&recipient.ConversationID,
+			&recipient.ClientID,
+			&recipient.Timezone,
+			&recipient.NextRunAt,
+			&recipient.LastSentAt,
+			&recipient.LastMessageID,
+			&recipient.Status,
+		); err != nil {
+			return nil, err
+		}
+		result[recipient.ScheduledMessageID] = append(result[recipient.ScheduledMessageID], recipient)
+	}
+
+	return result, rows.Err()
+}
+
+// UpdateScheduledMessage stores the edited schedule and the recomputed next run of each pending recipient.
+func (s *Store) UpdateScheduledMessage(ctx context.Context, msg *types.ScheduledMessage) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := `
+		UPDATE scheduled_messages
+		SET message_text = $2, send_at = $3, cron_expression = $4, updated_at = NOW()
+		WHERE scheduled_message_id = $1 AND status = 'active'
+	`
+	tag, err := tx.Exec(ctx, q, msg.ScheduledMessageID, msg.MessageText, msg.SendAt, msg.CronExpression)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrScheduledMessageInactive
+	}
+
+	recipientQuery := `
+		UPDATE scheduled_message_recipients
+		SET next_run_at = $2
+		WHERE recipient_id = $1 AND status = 'pending'
+	`
+	for _, recipient := range msg.Recipients {
+		if _, err := tx.Exec(ctx, recipientQuery, recipient.RecipientID, recipient.NextRunAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *Store) CancelScheduledMessage(ctx context.Context, scheduledMessageID int64) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := `
+		UPDATE scheduled_messages
+		SET status = 'cancelled', updated_at = NOW()
+		WHERE scheduled_message_id = $1 AND status = 'active'
+	`
+	tag, err := tx.Exec(ctx, q, scheduledMessageID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrScheduledMessageInactive
+	}
+
+	recipientQuery := `
+		UPDATE scheduled_message_recipients
+		SET status = 'cancelled', next_run_at = NULL, claimed_at = NULL
+		WHERE scheduled_message_id = $1 AND status = 'pending'
+	`
+	if _, err := tx.Exec(ctx, recipientQuery, scheduledMessageID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *Store) GetUserTimezones(ctx context.Context, userIDs []string) (map[string]string, error) {
+	q := `SELECT id, timezone FROM users WHERE id = ANY($1)`
+
+	rows, err := s.db.Query(ctx, q, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timezones := make(map[string]string, len(userIDs))
+	for rows.Next() {
+		var userID, timezone string
+		if err := rows.Scan(&userID, &timezone); err != nil {
+			return nil, err
+		}
+		timezones[userID] = timezone
+	}
+
+	return timezones, rows.Err()
+}
+
+// ClaimDueDeliveries locks recipients whose next run has passed. Rows claimed by a
+// dispatcher that crashed become claimable again after five minutes.
+func (s *Store) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int) ([]types.DueScheduledDelivery, error) {
+	q := `
+		WITH due AS (
+			SELECT r.recipient_id
+			FROM scheduled_message_recipients r
+			JOIN scheduled_messages m ON m.scheduled_message_id = r.scheduled_message_id
+			WHERE r.status = 'pending'
+			  AND m.status = 'active'
+			  AND r.next_run_at <= $1
+			  AND (r.claimed_at IS NULL OR r.claimed_at < $1 - INTERVAL '5 minutes')
+			ORDER BY r.next_run_at
+			LIMIT $2
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		UPDATE scheduled_message_recipients r
+		SET claimed_at = $1
+		FROM due, scheduled_messages m, users u
+		WHERE r.recipient_id = due.recipient_id
+		  AND m.scheduled_message_id = r.scheduled_message_id
+		  AND u.id = r.client_id
+		RETURNING r.recipient_id, r.scheduled_message_id, r.conversation_id, r.client_id, u.timezone,
+		          r.next_run_at, r.last_sent_at, r.last_message_id, r.status,
+		          m.coach_id, m.message_text, m.cron_expression
+	`
+
+	rows, err := s.db.Query(ctx, q, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []types.DueScheduledDelivery
+	for rows.Next() {
+		var delivery types.DueScheduledDelivery
+		if err := rows.Scan(
+			&delivery.RecipientID,
+			&delivery.ScheduledMessageID,
+			&delivery.ConversationID,
+			&delivery.ClientID,
+			&delivery.Timezone,
+			&delivery.NextRunAt,
+			&delivery.LastSentAt,
+			&delivery.LastMessageID,
+			&delivery.Status,
+			&delivery.CoachID,
+			&delivery.MessageText,
+			&delivery.CronExpression,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// DeliverScheduledMessage posts the delivery's message and records it as sent
+// in one transaction, so a run is never sent without being recorded. A nil
+// nextRunAt finishes the recipient, and the scheduled message is completed
+// once no recipient is pending anymore.
+func (s *Store) DeliverScheduledMessage(ctx context.Context, delivery *types.DueScheduledDelivery, sentAt time.Time, nextRunAt *time.Time) (*types.Message, error) {
+	status := types.ScheduledRecipientPending
+	if nextRunAt == nil {
+		status = types.ScheduledRecipientSent
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	message, err := scanInsertedMessage(tx.QueryRow(ctx, insertMessageQuery,
+		delivery.ConversationID, delivery.CoachID, delivery.MessageText, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
+		WITH updated AS (
+			UPDATE scheduled_message_recipients
+			SET last_sent_at = $3, last_message_id = $2, next_run_at = $4, status = $5, claimed_at = NULL
+			WHERE recipient_id = $1
+			RETURNING scheduled_message_id
+		)
+		UPDATE scheduled_messages m
+		SET status = 'completed', updated_at = NOW()
+		FROM updated
+		WHERE m.scheduled_message_id = updated.scheduled_message_id
+		  AND m.status = 'active'
+		  AND NOT EXISTS (
+			SELECT 1 FROM scheduled_message_recipients r
+			WHERE r.scheduled_message_id = updated.scheduled_message_id
+			  AND r.status = 'pending'
+			  AND r.recipient_id <> $1
+		  )
+		  AND $5 = 'sent'
+	`
+	if _, err := tx.Exec(ctx, q, delivery.RecipientID, message.MessageID, sentAt, nextRunAt, status); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (s *Store) ReleaseDelivery(ctx context.Context, recipientID int64) error {
+	q := `UPDATE scheduled_message_recipients SET claimed_at = NULL WHERE recipient_id = $1`
+	_, err := s.db.Exec(ctx, q, recipientID)
+	return err
+}
+
+// SkipDelivery passes over a run that cannot be sent. A recurring recipient
+// moves on to nextRunAt; with no next run the recipient is marked skipped, and
+// the scheduled message is completed once no recipient is pending anymore.
+func (s *Store) SkipDelivery(ctx context.Context, recipientID int64, nextRunAt *time.Time) error {
+	status := types.ScheduledRecipientPending
+	if nextRunAt == nil {
+		status = types.ScheduledRecipientSkipped
+	}
+
+	q := `
+		WITH updated AS (
+			UPDATE scheduled_message_recipients
+			SET next_run_at = $2, status = $3, claimed_at = NULL
+			WHERE recipient_id = $1
+			RETURNING scheduled_message_id
+		)
+		UPDATE scheduled_messages m
+		SET status = 'completed', updated_at = NOW()
+		FROM updated
+		WHERE m.scheduled_message_id = updated.scheduled_message_id
+		  AND m.status = 'active'
+		  AND NOT EXISTS (
+			SELECT 1 FROM scheduled_message_recipients r
+			WHERE r.scheduled_message_id = updated.scheduled_message_id
+			  AND r.status = 'pending'
+			  AND r.recipient_id <> $1
+		  )
+		  AND $3 = 'skipped'
+	`
+
+	_, err := s.db.Exec(ctx, q, recipientID, nextRunAt, status)
+	return err
+}
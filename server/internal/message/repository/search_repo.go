@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+// SearchMessages runs a full-text search restricted to conversations the user takes part in.
+// It fetches limit rows after the cursor, newest first.
+// Snippets are raw message text with matches between SearchMatchStart and
+// SearchMatchStop; they must be escaped before they are shown.
+func (s *Store) SearchMessages(ctx context.Context, userID string, params types.MessageSearchParams, cursor *types.MessageSearchCursor, limit int) ([]types.MessageSearchResult, error) {
+	q := `
+		SELECT
+			m.message_id,
+			m.conversation_id,
+			m.sender_id,
+			u.name AS sender_name,
+			u.image AS sender_image,
+			m.message_text,
+			ts_headline('simple', m.message_text, query, $3) AS snippet,
+			ts_rank(m.search_vector, query) AS rank,
+			EXISTS (
+				SELECT 1 FROM message_attachments a WHERE a.message_id = m.message_id
+			) AS has_attachments,
+			m.sent_at
+		FROM messages m
+		JOIN conversations c ON c.conversation_id = m.conversation_id
+		JOIN users u ON m.sender_id = u.id
+		CROSS JOIN websearch_to_tsquery('simple', $2) AS query
+		WHERE (c.coach_id = $1 OR c.client_id = $1)
+		  AND m.is_deleted = false
+		  AND m.search_vector @@ query
+	`
+
+	headlineOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=30, MinWords=10, MaxFragments=2`,
+		types.SearchMatchStart, types.SearchMatchStop)
+	args := []interface{}{userID, params.Query, headlineOptions}
+
+	if params.ConversationID != nil {
+		args = append(args, *params.ConversationID)
+		q += fmt.Sprintf(" AND m.conversation_id = $%d", len(args))
+	}
+	if params.SenderID != nil {
+		args = append(args, *params.SenderID)
+		q += fmt.Sprintf(" AND m.sender_id = $%d", len(args))
+	}
+	if params.StartDate != nil {
+		args = append(args, *params.StartDate)
+		q += fmt.Sprintf(" AND m.sent_at >= $%d", len(args))
+	}
+	if params.EndDate != nil {
+		args = append(args, *params.EndDate)
+		q += fmt.Sprintf(" AND m.sent_at <= $%d", len(args))
+	}
+	if params.HasAttachment != nil {
+		existsClause := "EXISTS"
+		if !*params.HasAttachment {
+			existsClause = "NOT EXISTS"
+		}
+		q += fmt.Sprintf(" AND %s (SELECT 1 FROM message_attachments a WHERE a.message_id = m.message_id)", existsClause)
+	}
+	if cursor != nil {
+		args = append(args, cursor.SentAt, cursor.MessageID)
+		q += fmt.Sprintf(" AND (m.sent_at, m.message_id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, limit)
+	q += fmt.Sprintf(" ORDER BY m.sent_at DESC, m.message_id DESC LIMIT $%d", len(args))
+
+	rows, err := s.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []types.MessageSearchResult
+	for rows.Next() {
+		var result types.MessageSearchResult
+		if err := rows.Scan(
+			&result.MessageID,
+			&result.ConversationID,
+			&result.SenderID,
+			&result.SenderName,
+			&result.SenderImage,
+			&result.MessageText,
+			&result.Snippet,
+			&result.Rank,
+			&result.HasAttachments,
+			&result.SentAt,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
+)
+
+const workoutPlanCardColumns = `
+	card_id, message_id, conversation_id, coach_id, client_id, plan_kind, source_id,
+	summary, status, accepted_schema_id, responded_at, created_at
+`
+
+func scanWorkoutPlanCard(row pgx.Row) (*types.WorkoutPlanCard, error) {
+	var card types.WorkoutPlanCard
+	err := row.Scan(
+		&card.CardID,
+		&card.MessageID,
+		&card.ConversationID,
+		&card.CoachID,
+		&card.ClientID,
+		&card.Kind,
+		&card.SourceID,
+		&card.Summary,
+		&card.Status,
+		&card.AcceptedSchemaID,
+		&card.RespondedAt,
+		&card.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return nil, types.ErrWorkoutPlanNotFound
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+func (s *Store) CreateWorkoutPlanCard(ctx context.Context, card *types.WorkoutPlanCard) error {
+	q := `
+		INSERT INTO workout_plan_cards (message_id, conversation_id, coach_id, client_id, plan_kind, source_id, summary)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING card_id, status, created_at
+	`
+
+	err := database.Conn(ctx, s.db).QueryRow(ctx, q,
+		card.MessageID,
+		card.ConversationID,
+		card.CoachID,
+		card.ClientID,
+		card.Kind,
+		card.SourceID,
+		card.Summary,
+	).Scan(&card.CardID, &card.Status, &card.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create workout plan card: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetWorkoutPlanCard(ctx context.Context, cardID int64) (*types.WorkoutPlanCard, error) {
+	q := `SELECT ` + workoutPlanCardColumns + ` FROM workout_plan_cards WHERE card_id = $1`
+	return scanWorkoutPlanCard(s.db.QueryRow(ctx, q, cardID))
+}
+
+func (s *Store) GetWorkoutPlanCardByMessage(ctx context.Context, messageID int64) (*types.WorkoutPlanCard, error) {
+	q := `SELECT ` + workoutPlanCardColumns + ` FROM workout_plan_cards WHERE message_id = $1`
+	return scanWorkoutPlanCard(s.db.QueryRow(ctx, q, messageID))
+}
+
+// RespondToWorkoutPlanCard moves a pending card to its final status. It returns
+// ErrWorkoutPlanResponded when the card was answered in the meantime. Inside a
+// transaction the card stays locked until it ends, so a concurrent response
+// waits and then sees the card answered.
+func (s *Store) RespondToWorkoutPlanCard(ctx context.Context, cardID int64, status types.WorkoutPlanCardStatus, acceptedSchemaID *int) (*types.WorkoutPlanCard, error) {
+	q := `
+		UPDATE workout_plan_cards
+		SET status = $2, accepted_schema_id = $3, responded_at = NOW()
+		WHERE card_id = $1 AND status = 'pending'
+		RETURNING ` + workoutPlanCardColumns
+
+	card, err := scanWorkoutPlanCard(database.Conn(ctx, s.db).QueryRow(ctx, q, cardID, status, acceptedSchemaID))
+	if errors.Is(err, types.ErrWorkoutPlanNotFound) {
+		return nil, types.ErrWorkoutPlanResponded
+	}
+	return card, err
+}
+
+func (s *Store) SetWorkoutPlanCardSchema(ctx context.Context, cardID int64, schemaID int) (*types.WorkoutPlanCard, error) {
+	q := `
+		UPDATE workout_plan_cards
+		SET accepted_schema_id = $2
+		WHERE card_id = $1
+		RETURNING ` + workoutPlanCardColumns
+
+	return scanWorkoutPlanCard(database.Conn(ctx, s.db).QueryRow(ctx, q, cardID, schemaID))
+}
+
+func (s *Store) CreateWorkoutPlanCardEvent(ctx context.Context, event *types.WorkoutPlanCardEvent) error {
+	q := `
+		INSERT INTO workout_plan_card_events (card_id, actor_id, action, details)
+		VALUES ($1, $2, $3, $4)
+		RETURNING event_id, created_at
+	`
+
+	var details interface{}
+	if len(event.Details) > 0 {
+		details = event.Details
+	}
+
+	return s.db.QueryRow(ctx, q, event.CardID, event.ActorID, event.Action, details).Scan(&event.EventID, &event.CreatedAt)
+}
+
+func (s *Store) ListWorkoutPlanCardEvents(ctx context.Context, cardID int64) ([]types.WorkoutPlanCardEvent, error) {
+	q := `
+		SELECT event_id, card_id, COALESCE(actor_id, ''), action, details, created_at
+		FROM workout_plan_card_events
+		WHERE card_id = $1
+		ORDER BY created_at, event_id
+	`
+
+	rows, err := s.db.Query(ctx, q, cardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []types.WorkoutPlanCardEvent
+	for rows.Next() {
+		var event types.WorkoutPlanCardEvent
+		if err := rows.Scan(
+			&event.EventID,
+			&event.CardID,
+			&event.ActorID,
+			&event.Action,
+			&event.Details,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
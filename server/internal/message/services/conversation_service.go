@@ -10,11 +10,17 @@ import (
 
 type conversationService struct {
 	repo repository.ConversationRepo
+	team TeamAccess
 }
 
 func NewConversationService(repo repository.MessageStore) ConversationService {
+	return newConversationService(repo, nil)
+}
+
+func newConversationService(repo repository.MessageStore, team TeamAccess) ConversationService {
 	return &conversationService{
 		repo: repo.Conversations(),
+		team: team,
 	}
 }
 
@@ -32,6 +38,10 @@ func (s *conversationService) CreateConversation(ctx context.Context, req *types
 		return nil, types.ErrConversationExists
 	}
 
+	if err := checkTeamAccess(ctx, s.team, req.CoachID, req.ClientID); err != nil {
+		return nil, err
+	}
+
 	return s.repo.CreateConversation(ctx, req.CoachID, req.ClientID)
 }
 
@@ -83,6 +93,13 @@ func (s *conversationService) IsParticipant(ctx context.Context, conversationID
 	return s.repo.IsParticipant(ctx, conversationID, userID)
 }
 
+func (s *conversationService) ListPartnerIDs(ctx context.Context, userID string) ([]string, error) {
+	if userID == "" {
+		return nil, types.ErrInvalidUserID
+	}
+	return s.repo.ListConversationPartnerIDs(ctx, userID)
+}
+
 func ValidateParticipants(coachID, clientID string) error {
 	if coachID == "" || clientID == "" {
 		return types.ErrInvalidConversationParticipants
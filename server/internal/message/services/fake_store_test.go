@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+// fakeStore is an in-memory MessageStore. Repo methods a test does not need
+// are left to the embedded interfaces and panic if called.
+type fakeStore struct {
+	repository.ConversationRepo
+	repository.MessageRepo
+	repository.MessageReadStatusRepo
+	repository.MessageAttachmentRepo
+	repository.MessageReactionRepo
+	repository.PinnedMessageRepo
+	repository.PresenceRepo
+	repository.ScheduledMessageRepo
+	repository.WorkoutPlanCardRepo
+
+	mu            sync.Mutex
+	conversations map[int]*types.Conversation
+	messages      map[int64]*types.Message
+	attachments   map[int64][]types.MessageAttachment
+	reactions     map[int64][]types.MessageReaction
+	userNames     map[string]string
+	nextID        int64
+
+	searchResults []types.MessageSearchResult
+	searchCursor  *types.MessageSearchCursor
+	searchLimit   int
+
+	due        []types.DueScheduledDelivery
+	deliverErr error
+	recipients map[int64]*types.ScheduledMessageRecipient
+	released   []int64
+
+	cards         map[int64]*types.WorkoutPlanCard
+	cardEvents    []types.WorkoutPlanCardEvent
+	attachmentErr error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		conversations: map[int]*types.Conversation{
+			7: {ConversationID: 7, CoachID: "coach", ClientID: "client"},
+		},
+		messages:    make(map[int64]*types.Message),
+		attachments: make(map[int64][]types.MessageAttachment),
+		reactions:   make(map[int64][]types.MessageReaction),
+		userNames:   map[string]string{"coach": "Coach Carter", "client": "Casey Client"},
+		recipients:  make(map[int64]*types.ScheduledMessageRecipient),
+		cards:       make(map[int64]*types.WorkoutPlanCard),
+	}
+}
+
+func (f *fakeStore) Conversations() repository.ConversationRepo         { return f }
+func (f *fakeStore) Messages() repository.MessageRepo                   { return f }
+func (f *fakeStore) ReadStatus() repository.MessageReadStatusRepo       { return f }
+func (f *fakeStore) Attachments() repository.MessageAttachmentRepo      { return f }
+func (f *fakeStore) Reactions() repository.MessageReactionRepo          { return f }
+func (f *fakeStore) Pins() repository.PinnedMessageRepo                 { return f }
+func (f *fakeStore) Presence() repository.PresenceRepo                  { return f }
+func (f *fakeStore) ScheduledMessages() repository.ScheduledMessageRepo { return f }
+func (f *fakeStore) WorkoutPlans() repository.WorkoutPlanCardRepo       { return f }
+
+// WithTransaction restores messages, attachments and plan cards when fn fails.
+// Writes replace map entries rather than mutating them, so a shallow copy is
+// enough to roll back.
+func (f *fakeStore) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	f.mu.Lock()
+	messages := make(map[int64]*types.Message, len(f.messages))
+	for id, msg := range f.messages {
+		messages[id] = msg
+	}
+	attachments := make(map[int64][]types.MessageAttachment, len(f.attachments))
+	for id, list := range f.attachments {
+		attachments[id] = list
+	}
+	cards := make(map[int64]*types.WorkoutPlanCard, len(f.cards))
+	for id, card := range f.cards {
+		cards[id] = card
+	}
+	f.mu.Unlock()
+
+	if err := fn(ctx); err != nil {
+		f.mu.Lock()
+		f.messages, f.attachments, f.cards = messages, attachments, cards
+		f.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (f *fakeStore) id() int64 {
+	f.nextID++
+	return f.nextID
+}
+
+func (f *fakeStore) GetConversationByID(ctx context.Context, conversationID int) (*types.Conversation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	conversation, ok := f.conversations[conversationID]
+	if !ok {
+		return nil, types.ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+func (f *fakeStore) IsParticipant(ctx context.Context, conversationID int, userID string) (bool, error) {
+	conversation, err := f.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return false, nil
+	}
+	return conversation.CoachID == userID || conversation.ClientID == userID, nil
+}
+
+func (f *fakeStore) addMessage(conversationID int, senderID, text string, replyTo *int64) *types.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	msg := &types.Message{
+		MessageID:        f.id(),
+		ConversationID:   conversationID,
+		SenderID:         senderID,
+		MessageText:      text,
+		ReplyToMessageID: replyTo,
+		SentAt:           time.Now(),
+	}
+	f.messages[msg.MessageID] = msg
+	return msg
+}
+
+func (f *fakeStore) CreateMessage(ctx context.Context, conversationID int, senderID, messageText string, replyToMessageID *int64) (*types.Message, error) {
+	return f.addMessage(conversationID, senderID, messageText, replyToMessageID), nil
+}
+
+func (f *fakeStore) details(msg *types.Message) types.MessageWithDetails {
+	return types.MessageWithDetails{Message: *msg, SenderName: f.userNames[msg.SenderID]}
+}
+
+func (f *fakeStore) GetMessageWithDetails(ctx context.Context, messageID int64, userID string) (*types.MessageWithDetails, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	msg, ok := f.messages[messageID]
+	if !ok {
+		return nil, types.ErrMessageNotFound
+	}
+	details := f.details(msg)
+	return &details, nil
+}
+
+func (f *fakeStore) ListReplies(ctx context.Context, messageID int64, userID string, limit, offset int) ([]types.MessageWithDetails, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var replies []types.MessageWithDetails
+	for _, msg := range f.messages {
+		if msg.ReplyToMessageID != nil && *msg.ReplyToMessageID == messageID {
+			replies = append(replies, f.details(msg))
+		}
+	}
+	sort.Slice(replies, func(i, j int) bool { return replies[i].MessageID < replies[j].MessageID })
+
+	total := len(replies)
+	if offset >= total {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return replies[offset:end], total, nil
+}
+
+func (f *fakeStore) CreateAttachment(ctx context.Context, messageID int64, attachmentType types.AttachmentType, fileName, fileURL string) (*types.MessageAttachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.attachmentErr != nil {
+		return nil, f.attachmentErr
+	}
+	attachment := types.MessageAttachment{
+		AttachmentID:   f.id(),
+		MessageID:      messageID,
+		AttachmentType: attachmentType,
+		FileName:       fileName,
+		FileURL:        fileURL,
+	}
+	f.attachments[messageID] = append(f.attachments[messageID], attachment)
+	return &attachment, nil
+}
+
+func (f *fakeStore) ListAttachmentsByMessage(ctx context.Context, messageID int64) ([]types.MessageAttachment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attachments[messageID], nil
+}
+
+func (f *fakeStore) ListReactionsByMessage(ctx context.Context, messageID int64) ([]types.MessageReaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reactions[messageID], nil
+}
+
+func (f *fakeStore) SearchMessages(ctx context.Context, userID string, params types.MessageSearchParams, cursor *types.MessageSearchCursor, limit int) ([]types.MessageSearchResult, error) {
+	f.searchCursor = cursor
+	f.searchLimit = limit
+
+	var results []types.MessageSearchResult
+	for _, result := range f.searchResults {
+		if cursor != nil && !result.SentAt.Before(cursor.SentAt) {
+			continue
+		}
+		if len(results) == limit {
+			break
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (f *fakeStore) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int) ([]types.DueScheduledDelivery, error) {
+	due := f.due
+	f.due = nil
+	return due, nil
+}
+
+func (f *fakeStore) DeliverScheduledMessage(ctx context.Context, delivery *types.DueScheduledDelivery, sentAt time.Time, nextRunAt *time.Time) (*types.Message, error) {
+	if f.deliverErr != nil {
+		return nil, f.deliverErr
+	}
+
+	msg := f.addMessage(delivery.ConversationID, delivery.CoachID, delivery.MessageText, nil)
+	status := types.ScheduledRecipientPending
+	if nextRunAt == nil {
+		status = types.ScheduledRecipientSent
+	}
+	f.recipients[delivery.RecipientID] = &types.ScheduledMessageRecipient{
+		RecipientID:   delivery.RecipientID,
+		NextRunAt:     nextRunAt,
+		LastSentAt:    &sentAt,
+		LastMessageID: &msg.MessageID,
+		Status:        status,
+	}
+	return msg, nil
+}
+
+func (f *fakeStore) ReleaseDelivery(ctx context.Context, recipientID int64) error {
+	f.released = append(f.released, recipientID)
+	return nil
+}
+
+func (f *fakeStore) SkipDelivery(ctx context.Context, recipientID int64, nextRunAt *time.Time) error {
+	status := types.ScheduledRecipientPending
+	if nextRunAt == nil {
+		status = types.ScheduledRecipientSkipped
+	}
+	f.recipients[recipientID] = &types.ScheduledMessageRecipient{RecipientID: recipientID, NextRunAt: nextRunAt, Status: status}
+	return nil
+}
+
+func (f *fakeStore) CreateWorkoutPlanCard(ctx context.Context, card *types.WorkoutPlanCard) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	card.CardID = f.id()
+	card.Status = types.WorkoutPlanCardPending
+	card.CreatedAt = time.Now()
+	stored := *card
+	f.cards[card.CardID] = &stored
+	return nil
+}
+
+func (f *fakeStore) GetWorkoutPlanCard(ctx context.Context, cardID int64) (*types.WorkoutPlanCard, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	card, ok := f.cards[cardID]
+	if !ok {
+		return nil, types.ErrWorkoutPlanNotFound
+	}
+	found := *card
+	return &found, nil
+}
+
+func (f *fakeStore) RespondToWorkoutPlanCard(ctx context.Context, cardID int64, status types.WorkoutPlanCardStatus, acceptedSchemaID *int) (*types.WorkoutPlanCard, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	card, ok := f.cards[cardID]
+	if !ok || card.Status != types.WorkoutPlanCardPending {
+		return nil, types.ErrWorkoutPlanResponded
+	}
+	now := time.Now()
+	updated := *card
+	updated.Status = status
+	updated.AcceptedSchemaID = acceptedSchemaID
+	updated.RespondedAt = &now
+	f.cards[cardID] = &updated
+
+	answered := updated
+	return &answered, nil
+}
+
+func (f *fakeStore) SetWorkoutPlanCardSchema(ctx context.Context, cardID int64, schemaID int) (*types.WorkoutPlanCard, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	card, ok := f.cards[cardID]
+	if !ok {
+		return nil, types.ErrWorkoutPlanNotFound
+	}
+	updated := *card
+	updated.AcceptedSchemaID = &schemaID
+	f.cards[cardID] = &updated
+
+	answered := updated
+	return &answered, nil
+}
+
+func (f *fakeStore) CreateWorkoutPlanCardEvent(ctx context.Context, event *types.WorkoutPlanCardEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event.EventID = f.id()
+	f.cardEvents = append(f.cardEvents, *event)
+	return nil
+}
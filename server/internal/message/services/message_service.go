@@ -2,31 +2,127 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"log"
 
 	"github.com/tdmdh/fit-up-server/internal/message/repository"
 	"github.com/tdmdh/fit-up-server/internal/message/types"
 )
 
+// CoachingAccess reports whether a coach and client may still message each
+// other. The billing module satisfies it.
+type CoachingAccess interface {
+	AllowsMessaging(ctx context.Context, coachID, clientID string) (bool, error)
+}
+
+// TeamAccess reports whether a coach may message a client through their
+// coaching team. Coaches who share no team with the client's coach are not
+// restricted by it. The schema module satisfies it.
+type TeamAccess interface {
+	CanMessageClient(ctx context.Context, coachID, clientID string) (bool, error)
+}
+
 type messageService struct {
-	repo           repository.MessageRepo
-	attachmentRepo repository.MessageAttachmentRepo
+	repo             repository.MessageRepo
+	conversationRepo repository.ConversationRepo
+	attachmentRepo   repository.MessageAttachmentRepo
+	reactionRepo     repository.MessageReactionRepo
+	workoutPlanRepo  repository.WorkoutPlanCardRepo
+	access           CoachingAccess
+	team             TeamAccess
+}
+
+// NewMessageService creates the message service. access may be nil, in which
+// case messaging is never paused.
+func NewMessageService(repo repository.MessageStore, access CoachingAccess) MessageService {
+	return newMessageService(repo, access, nil)
 }
 
-func NewMessageService(repo repository.MessageStore) MessageService {
+func newMessageService(repo repository.MessageStore, access CoachingAccess, team TeamAccess) MessageService {
 	return &messageService{
-		repo:           repo.Messages(),
-		attachmentRepo: repo.Attachments(),
+		repo:             repo.Messages(),
+		conversationRepo: repo.Conversations(),
+		attachmentRepo:   repo.Attachments(),
+		reactionRepo:     repo.Reactions(),
+		workoutPlanRepo:  repo.WorkoutPlans(),
+		access:           access,
+		team:             team,
 	}
 }
 
 func (s *messageService) CreateMessage(ctx context.Context, conversationID int, senderID, messageText string, replyToMessageID *int64) (*types.Message, error) {
-	if err := ValidateMessageText(messageText); err != nil {
+	if err := s.CheckCanSend(ctx, conversationID, messageText); err != nil {
 		return nil, err
 	}
 
 	return s.repo.CreateMessage(ctx, conversationID, senderID, messageText, replyToMessageID)
 }
 
+// CheckCanSend runs the checks CreateMessage applies before storing a message,
+// for callers that store it themselves.
+func (s *messageService) CheckCanSend(ctx context.Context, conversationID int, messageText string) error {
+	if err := ValidateMessageText(messageText); err != nil {
+		return err
+	}
+
+	if s.access != nil || s.team != nil {
+		conversation, err := s.conversationRepo.GetConversationByID(ctx, conversationID)
+		if err != nil {
+			return conversationLookupError(err)
+		}
+		if err := checkTeamAccess(ctx, s.team, conversation.CoachID, conversation.ClientID); err != nil {
+			return err
+		}
+		if s.access != nil {
+			if err := checkMessagingAccess(ctx, s.access, conversation.CoachID, conversation.ClientID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withSenderDetails adds the sender's name and image to a message that was just
+// stored. The message already exists, so a failed lookup is logged rather than
+// reported as a failed send.
+func withSenderDetails(ctx context.Context, repo repository.MessageRepo, message *types.Message) *types.MessageWithDetails {
+	details, err := repo.GetMessageWithDetails(ctx, message.MessageID, message.SenderID)
+	if err != nil {
+		log.Printf("Failed to load sender of message %d: %v", message.MessageID, err)
+		return &types.MessageWithDetails{Message: *message}
+	}
+	return details
+}
+
+// checkMessagingAccess returns ErrMessagingPaused when the pair's coaching
+// subscription has lapsed or doesn't include messaging.
+func checkMessagingAccess(ctx context.Context, access CoachingAccess, coachID, clientID string) error {
+	allowed, err := access.AllowsMessaging(ctx, coachID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to check coaching subscription: %w", err)
+	}
+	if !allowed {
+		return types.ErrMessagingPaused
+	}
+	return nil
+}
+
+// checkTeamAccess returns ErrMessagingNotPermitted when the coach reaches the
+// client through a team that doesn't let them message. team may be nil.
+func checkTeamAccess(ctx context.Context, team TeamAccess, coachID, clientID string) error {
+	if team == nil {
+		return nil
+	}
+	allowed, err := team.CanMessageClient(ctx, coachID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to check team permissions: %w", err)
+	}
+	if !allowed {
+		return types.ErrMessagingNotPermitted
+	}
+	return nil
+}
+
 func ValidateMessageText(messageText string) error {
 	if len(messageText) == 0 {
 		return types.ErrMessageEmpty
@@ -72,14 +168,8 @@ func (s *messageService) ListMessages(ctx context.Context, conversationID int, u
 		return nil, err
 	}
 
-	for i := range messages {
-		attachments, err := s.attachmentRepo.ListAttachmentsByMessage(ctx, messages[i].MessageID)
-		if err != nil {
-			return nil, err
-		}
-		if len(attachments) > 0 {
-			messages[i].Attachments = attachments
-		}
+	if err := s.attachDetails(ctx, messages, userID); err != nil {
+		return nil, err
 	}
 
 	hasMore := offset+len(messages) < total
@@ -90,3 +180,84 @@ func (s *messageService) ListMessages(ctx context.Context, conversationID int, u
 		HasMore:  hasMore,
 	}, nil
 }
+
+func (s *messageService) GetThread(ctx context.Context, messageID int64, userID string, limit, offset int) (*types.ThreadResponse, error) {
+	if messageID <= 0 {
+		return nil, types.ErrInvalidMessageID
+	}
+	if userID == "" {
+		return nil, types.ErrInvalidUserID
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	root, err := s.repo.GetMessageWithDetails(ctx, messageID, userID)
+	if err != nil {
+		return nil, types.ErrMessageNotFound
+	}
+
+	replies, total, err := s.repo.ListReplies(ctx, messageID, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := []types.MessageWithDetails{*root}
+	if err := s.attachDetails(ctx, roots, userID); err != nil {
+		return nil, err
+	}
+	if err := s.attachDetails(ctx, replies, userID); err != nil {
+		return nil, err
+	}
+	if replies == nil {
+		replies = []types.MessageWithDetails{}
+	}
+
+	return &types.ThreadResponse{
+		Root:    roots[0],
+		Replies: replies,
+		Total:   total,
+		HasMore: offset+len(replies) < total,
+	}, nil
+}
+
+func (s *messageService) attachDetails(ctx context.Context, messages []types.MessageWithDetails, userID string) error {
+	for i := range messages {
+		attachments, err := s.attachmentRepo.ListAttachmentsByMessage(ctx, messages[i].MessageID)
+		if err != nil {
+			return err
+		}
+		if len(attachments) > 0 {
+			messages[i].Attachments = attachments
+		}
+
+		if hasWorkoutPlanAttachment(attachments) {
+			card, err := s.workoutPlanRepo.GetWorkoutPlanCardByMessage(ctx, messages[i].MessageID)
+			if err != nil && err != types.ErrWorkoutPlanNotFound {
+				return err
+			}
+			messages[i].WorkoutPlan = card
+		}
+
+		reactions, err := s.reactionRepo.ListReactionsByMessage(ctx, messages[i].MessageID)
+		if err != nil {
+			return err
+		}
+		if len(reactions) > 0 {
+			messages[i].Reactions = SummarizeReactions(reactions, userID)
+		}
+	}
+	return nil
+}
+
+func hasWorkoutPlanAttachment(attachments []types.MessageAttachment) bool {
+	for _, attachment := range attachments {
+		if attachment.AttachmentType == types.AttachmentTypeWorkoutPlan {
+			return true
+		}
+	}
+	return false
+}
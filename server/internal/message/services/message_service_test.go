@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+func TestGetThread(t *testing.T) {
+	store := newFakeStore()
+	service := NewMessageService(store, nil)
+	ctx := context.Background()
+
+	root := store.addMessage(7, "coach", "How did leg day go?", nil)
+	store.addMessage(7, "coach", "Unrelated", nil)
+	first := store.addMessage(7, "client", "Tough but done", &root.MessageID)
+	second := store.addMessage(7, "coach", "Nice", &root.MessageID)
+	store.reactions[first.MessageID] = []types.MessageReaction{{MessageID: first.MessageID, UserID: "coach", Emoji: "🔥"}}
+
+	thread, err := service.GetThread(ctx, root.MessageID, "client", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thread.Root.MessageID != root.MessageID || thread.Root.SenderName != "Coach Carter" {
+		t.Errorf("unexpected root %+v", thread.Root)
+	}
+	if thread.Total != 2 || !thread.HasMore || len(thread.Replies) != 1 || thread.Replies[0].MessageID != first.MessageID {
+		t.Fatalf("unexpected first page %+v", thread)
+	}
+	if len(thread.Replies[0].Reactions) != 1 || thread.Replies[0].Reactions[0].Emoji != "🔥" {
+		t.Errorf("replies should carry their reactions, got %+v", thread.Replies[0].Reactions)
+	}
+
+	thread, err = service.GetThread(ctx, root.MessageID, "client", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thread.HasMore || len(thread.Replies) != 1 || thread.Replies[0].MessageID != second.MessageID {
+		t.Errorf("unexpected second page %+v", thread)
+	}
+}
+
+func TestGetThreadWithoutReplies(t *testing.T) {
+	store := newFakeStore()
+	service := NewMessageService(store, nil)
+	ctx := context.Background()
+
+	root := store.addMessage(7, "coach", "Rest day today", nil)
+	thread, err := service.GetThread(ctx, root.MessageID, "client", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thread.Replies == nil || len(thread.Replies) != 0 || thread.Total != 0 || thread.HasMore {
+		t.Errorf("unexpected thread %+v", thread)
+	}
+
+	if _, err := service.GetThread(ctx, 999, "client", 0, 0); err != types.ErrMessageNotFound {
+		t.Errorf("missing root = %v, want %v", err, types.ErrMessageNotFound)
+	}
+	if _, err := service.GetThread(ctx, root.MessageID, "", 0, 0); err != types.ErrInvalidUserID {
+		t.Errorf("missing user = %v, want %v", err, types.ErrInvalidUserID)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+const maxPinnedMessages = 20
+
+type pinService struct {
+	repo repository.PinnedMessageRepo
+}
+
+func NewPinService(repo repository.PinnedMessageRepo) PinService {
+	return &pinService{
+		repo: repo,
+	}
+}
+
+func (s *pinService) PinMessage(ctx context.Context, conversationID int, messageID int64, userID string) (*types.PinnedMessage, error) {
+	if conversationID <= 0 {
+		return nil, types.ErrInvalidConversationID
+	}
+	if messageID <= 0 {
+		return nil, types.ErrInvalidMessageID
+	}
+
+	count, err := s.repo.CountPinnedMessages(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPinnedMessages {
+		return nil, types.ErrPinLimitReached
+	}
+
+	return s.repo.PinMessage(ctx, conversationID, messageID, userID)
+}
+
+func (s *pinService) UnpinMessage(ctx context.Context, conversationID int, messageID int64) error {
+	if conversationID <= 0 {
+		return types.ErrInvalidConversationID
+	}
+	if messageID <= 0 {
+		return types.ErrInvalidMessageID
+	}
+
+	removed, err := s.repo.UnpinMessage(ctx, conversationID, messageID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return types.ErrMessageNotPinned
+	}
+	return nil
+}
+
+func (s *pinService) ListPinnedMessages(ctx context.Context, conversationID int, userID string) ([]types.PinnedMessage, error) {
+	if conversationID <= 0 {
+		return nil, types.ErrInvalidConversationID
+	}
+
+	pins, err := s.repo.ListPinnedMessages(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pins == nil {
+		pins = []types.PinnedMessage{}
+	}
+	return pins, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+type fakePinRepo struct {
+	repository.PinnedMessageRepo
+	pinned map[int64]bool
+}
+
+func (f *fakePinRepo) CountPinnedMessages(ctx context.Context, conversationID int) (int, error) {
+	return len(f.pinned), nil
+}
+
+func (f *fakePinRepo) PinMessage(ctx context.Context, conversationID int, messageID int64, userID string) (*types.PinnedMessage, error) {
+	f.pinned[messageID] = true
+	return &types.PinnedMessage{ConversationID: conversationID, MessageID: messageID, PinnedBy: userID}, nil
+}
+
+func (f *fakePinRepo) UnpinMessage(ctx context.Context, conversationID int, messageID int64) (bool, error) {
+	removed := f.pinned[messageID]
+	delete(f.pinned, messageID)
+	return removed, nil
+}
+
+func (f *fakePinRepo) ListPinnedMessages(ctx context.Context, conversationID int, userID string) ([]types.PinnedMessage, error) {
+	return nil, nil
+}
+
+func TestPinMessageEnforcesLimit(t *testing.T) {
+	repo := &fakePinRepo{pinned: make(map[int64]bool)}
+	service := NewPinService(repo)
+	ctx := context.Background()
+
+	for id := int64(1); id <= maxPinnedMessages; id++ {
+		if _, err := service.PinMessage(ctx, 7, id, "coach"); err != nil {
+			t.Fatalf("pin %d: %v", id, err)
+		}
+	}
+	if _, err := service.PinMessage(ctx, 7, maxPinnedMessages+1, "coach"); err != types.ErrPinLimitReached {
+		t.Errorf("pin over the limit = %v, want %v", err, types.ErrPinLimitReached)
+	}
+
+	if err := service.UnpinMessage(ctx, 7, 1); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if _, err := service.PinMessage(ctx, 7, maxPinnedMessages+1, "coach"); err != nil {
+		t.Errorf("pin after unpinning = %v", err)
+	}
+}
+
+func TestPinServiceValidation(t *testing.T) {
+	service := NewPinService(&fakePinRepo{pinned: make(map[int64]bool)})
+	ctx := context.Background()
+
+	if _, err := service.PinMessage(ctx, 0, 1, "coach"); err != types.ErrInvalidConversationID {
+		t.Errorf("pin without conversation = %v", err)
+	}
+	if _, err := service.PinMessage(ctx, 7, 0, "coach"); err != types.ErrInvalidMessageID {
+		t.Errorf("pin without message = %v", err)
+	}
+	if err := service.UnpinMessage(ctx, 7, 3); err != types.ErrMessageNotPinned {
+		t.Errorf("unpin of an unpinned message = %v, want %v", err, types.ErrMessageNotPinned)
+	}
+
+	pins, err := service.ListPinnedMessages(ctx, 7, "coach")
+	if err != nil || pins == nil || len(pins) != 0 {
+		t.Errorf("ListPinnedMessages = %#v, %v, want an empty list", pins, err)
+	}
+}
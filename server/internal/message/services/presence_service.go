@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+type presenceService struct {
+	repo repository.PresenceRepo
+}
+
+func NewPresenceService(repo repository.PresenceRepo) PresenceService {
+	return &presenceService{
+		repo: repo,
+	}
+}
+
+func (s *presenceService) UpdatePresence(ctx context.Context, userID string, status types.PresenceStatus) (*types.UserPresence, error) {
+	if userID == "" {
+		return nil, types.ErrInvalidUserID
+	}
+	if err := ValidatePresenceStatus(status); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpsertPresence(ctx, userID, status)
+}
+
+func (s *presenceService) GetPresence(ctx context.Context, userID string) (*types.UserPresence, error) {
+	if userID == "" {
+		return nil, types.ErrInvalidUserID
+	}
+
+	presence, err := s.repo.GetPresence(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if presence == nil {
+		return &types.UserPresence{UserID: userID, Status: types.PresenceOffline}, nil
+	}
+	return presence, nil
+}
+
+func (s *presenceService) ListPresence(ctx context.Context, userIDs []string) ([]types.UserPresence, error) {
+	if len(userIDs) == 0 {
+		return []types.UserPresence{}, nil
+	}
+
+	stored, err := s.repo.ListPresenceByUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string]types.UserPresence, len(stored))
+	for _, presence := range stored {
+		byUser[presence.UserID] = presence
+	}
+
+	// Users that never connected have no row yet and are reported offline
+	presences := make([]types.UserPresence, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if presence, ok := byUser[userID]; ok {
+			presences = append(presences, presence)
+			continue
+		}
+		presences = append(presences, types.UserPresence{UserID: userID, Status: types.PresenceOffline})
+	}
+	return presences, nil
+}
+
+func ValidatePresenceStatus(status types.PresenceStatus) error {
+	switch status {
+	case types.PresenceOnline, types.PresenceAway, types.PresenceOffline:
+		return nil
+	default:
+		return types.ErrInvalidPresenceStatus
+	}
+}
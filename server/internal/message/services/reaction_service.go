@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+const maxEmojiRunes = 16
+
+type reactionService struct {
+	repo repository.MessageReactionRepo
+}
+
+func NewReactionService(repo repository.MessageReactionRepo) ReactionService {
+	return &reactionService{
+		repo: repo,
+	}
+}
+
+func (s *reactionService) ToggleReaction(ctx context.Context, messageID int64, userID, emoji string) (*types.MessageReaction, bool, error) {
+	if messageID <= 0 {
+		return nil, false, types.ErrInvalidMessageID
+	}
+	if userID == "" {
+		return nil, false, types.ErrInvalidUserID
+	}
+
+	emoji = strings.TrimSpace(emoji)
+	if err := ValidateEmoji(emoji); err != nil {
+		return nil, false, err
+	}
+
+	return s.repo.ToggleReaction(ctx, messageID, userID, emoji)
+}
+
+func (s *reactionService) ListReactions(ctx context.Context, messageID int64, userID string) ([]types.ReactionSummary, error) {
+	if messageID <= 0 {
+		return nil, types.ErrInvalidMessageID
+	}
+
+	reactions, err := s.repo.ListReactionsByMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeReactions(reactions, userID), nil
+}
+
+func ValidateEmoji(emoji string) error {
+	if emoji == "" || len(emoji) > 64 || utf8.RuneCountInString(emoji) > maxEmojiRunes {
+		return types.ErrInvalidEmoji
+	}
+	if strings.ContainsAny(emoji, " \t\n") {
+		return types.ErrInvalidEmoji
+	}
+	return nil
+}
+
+// SummarizeReactions groups reactions by emoji, keeping first-use order.
+func SummarizeReactions(reactions []types.MessageReaction, userID string) []types.ReactionSummary {
+	summaries := make([]types.ReactionSummary, 0)
+	index := make(map[string]int)
+
+	for _, reaction := range reactions {
+		i, exists := index[reaction.Emoji]
+		if !exists {
+			i = len(summaries)
+			index[reaction.Emoji] = i
+			summaries = append(summaries, types.ReactionSummary{Emoji: reaction.Emoji, UserIDs: []string{}})
+		}
+
+		summaries[i].Count++
+		summaries[i].UserIDs = append(summaries[i].UserIDs, reaction.UserID)
+		if reaction.UserID == userID {
+			summaries[i].ReactedByMe = true
+		}
+	}
+
+	return summaries
+}
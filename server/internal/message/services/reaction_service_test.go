@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+type fakeReactionRepo struct {
+	repository.MessageReactionRepo
+	toggled []string
+}
+
+func (f *fakeReactionRepo) ToggleReaction(ctx context.Context, messageID int64, userID, emoji string) (*types.MessageReaction, bool, error) {
+	f.toggled = append(f.toggled, emoji)
+	return &types.MessageReaction{MessageID: messageID, UserID: userID, Emoji: emoji}, true, nil
+}
+
+func TestToggleReactionValidatesInput(t *testing.T) {
+	repo := &fakeReactionRepo{}
+	service := NewReactionService(repo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		messageID int64
+		userID    string
+		emoji     string
+		want      error
+	}{
+		{"missing message", 0, "client", "👍", types.ErrInvalidMessageID},
+		{"missing user", 1, "", "👍", types.ErrInvalidUserID},
+		{"empty emoji", 1, "client", "  ", types.ErrInvalidEmoji},
+		{"sentence", 1, "client", "nice work", types.ErrInvalidEmoji},
+		{"too long", 1, "client", "🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥🔥", types.ErrInvalidEmoji},
+	}
+	for _, tt := range tests {
+		if _, _, err := service.ToggleReaction(ctx, tt.messageID, tt.userID, tt.emoji); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if len(repo.toggled) != 0 {
+		t.Fatalf("invalid reactions reached the repo: %v", repo.toggled)
+	}
+
+	if _, added, err := service.ToggleReaction(ctx, 1, "client", " 💪 "); err != nil || !added {
+		t.Fatalf("ToggleReaction = %v, %v", added, err)
+	}
+	if !reflect.DeepEqual(repo.toggled, []string{"💪"}) {
+		t.Errorf("emoji should be trimmed before storing, got %q", repo.toggled)
+	}
+}
+
+func TestSummarizeReactions(t *testing.T) {
+	reactions := []types.MessageReaction{
+		{UserID: "coach", Emoji: "🔥"},
+		{UserID: "client", Emoji: "👍"},
+		{UserID: "client", Emoji: "🔥"},
+	}
+
+	got := SummarizeReactions(reactions, "client")
+	want := []types.ReactionSummary{
+		{Emoji: "🔥", Count: 2, UserIDs: []string{"coach", "client"}, ReactedByMe: true},
+		{Emoji: "👍", Count: 1, UserIDs: []string{"client"}, ReactedByMe: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeReactions = %+v, want %+v", got, want)
+	}
+
+	if got := SummarizeReactions(nil, "client"); got == nil || len(got) != 0 {
+		t.Errorf("no reactions should summarize to an empty list, got %#v", got)
+	}
+}
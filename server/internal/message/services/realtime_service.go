@@ -9,6 +9,7 @@ import (
 
 	"github.com/tdmdh/fit-up-server/internal/message/pool"
 	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/ratelimit"
 	"golang.org/x/net/websocket"
 )
 
@@ -17,20 +18,39 @@ type RealtimeService struct {
 	messageService  MessageService
 	conversationSvc ConversationService
 	readStatusSvc   MessageReadStatusService
+	presenceSvc     PresenceService
+
+	// Socket events are limited per process; a user's connection lives on one replica.
+	eventLimiter *ratelimit.Limiter
 }
 
+const (
+	eventPolicyTyping   = "ws_typing"
+	eventPolicyPresence = "ws_presence"
+)
+
 func NewRealtimeService(
 	hub *pool.Hub,
 	messageService MessageService,
 	conversationSvc ConversationService,
 	readStatusSvc MessageReadStatusService,
+	presenceSvc PresenceService,
 ) *RealtimeService {
-	return &RealtimeService{
+	rs := &RealtimeService{
 		Hub:             hub,
 		messageService:  messageService,
 		conversationSvc: conversationSvc,
 		readStatusSvc:   readStatusSvc,
+		presenceSvc:     presenceSvc,
+		eventLimiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Policy{
+			{Name: eventPolicyTyping, Limit: 20, Window: 10 * time.Second},
+			{Name: eventPolicyPresence, Limit: 10, Window: time.Minute},
+		}),
 	}
+
+	hub.SetEventHandler(rs.HandleClientEvent)
+
+	return rs
 }
 
 func (rs *RealtimeService) HandleConnection(ctx context.Context, userID string, conn *websocket.Conn) error {
@@ -45,8 +65,16 @@ func (rs *RealtimeService) HandleConnection(ctx context.Context, userID string,
 
 	log.Printf("User %s connected to WebSocket", userID)
 
+	if err := rs.UpdatePresence(ctx, userID, types.PresenceOnline); err != nil {
+		log.Printf("Failed to mark user %s online: %v", userID, err)
+	}
+
 	rs.waitForDisconnect(userID)
 
+	if err := rs.UpdatePresence(ctx, userID, types.PresenceOffline); err != nil {
+		log.Printf("Failed to mark user %s offline: %v", userID, err)
+	}
+
 	return nil
 }
 
@@ -107,6 +135,58 @@ func (rs *RealtimeService) BroadcastMessageRead(ctx context.Context, conversatio
 	return rs.broadcastToConversation(conversationID, wsMessage)
 }
 
+func (rs *RealtimeService) BroadcastReactionChanged(ctx context.Context, conversationID int, reaction *types.MessageReaction, added bool) error {
+	messageType := types.WSTypeReactionRemoved
+	if added {
+		messageType = types.WSTypeReactionAdded
+	}
+
+	wsMessage := types.WebSocketMessage{
+		Type:           messageType,
+		ConversationID: conversationID,
+		MessageID:      &reaction.MessageID,
+		Reaction:       reaction,
+		Timestamp:      time.Now(),
+	}
+
+	return rs.broadcastToConversation(conversationID, wsMessage)
+}
+
+func (rs *RealtimeService) BroadcastMessagePinned(ctx context.Context, conversationID int, pin *types.PinnedMessage) error {
+	wsMessage := types.WebSocketMessage{
+		Type:           types.WSTypeMessagePinned,
+		ConversationID: conversationID,
+		MessageID:      &pin.MessageID,
+		Pin:            pin,
+		Timestamp:      time.Now(),
+	}
+
+	return rs.broadcastToConversation(conversationID, wsMessage)
+}
+
+func (rs *RealtimeService) BroadcastMessageUnpinned(ctx context.Context, conversationID int, messageID int64) error {
+	wsMessage := types.WebSocketMessage{
+		Type:           types.WSTypeMessageUnpinned,
+		ConversationID: conversationID,
+		MessageID:      &messageID,
+		Timestamp:      time.Now(),
+	}
+
+	return rs.broadcastToConversation(conversationID, wsMessage)
+}
+
+func (rs *RealtimeService) BroadcastWorkoutPlanUpdated(ctx context.Context, card *types.WorkoutPlanCard) error {
+	wsMessage := types.WebSocketMessage{
+		Type:           types.WSTypeWorkoutPlanUpdated,
+		ConversationID: card.ConversationID,
+		MessageID:      &card.MessageID,
+		WorkoutPlan:    card,
+		Timestamp:      time.Now(),
+	}
+
+	return rs.broadcastToConversation(card.ConversationID, wsMessage)
+}
+
 func (rs *RealtimeService) broadcastToConversation(conversationID int, message types.WebSocketMessage) error {
 	channel := fmt.Sprintf("conversation:%d", conversationID)
 
@@ -128,6 +208,27 @@ func (rs *RealtimeService) SendToUser(userID string, message types.WebSocketMess
 	return rs.Hub.SendMessage(userID, string(messageBytes))
 }
 
+// NotifyUser pushes a system notification to the user's live connection.
+// Offline users are skipped; callers are expected to have a durable channel such as email.
+func (rs *RealtimeService) NotifyUser(ctx context.Context, userID, kind, title, body string, data map[string]interface{}) error {
+	if !rs.Hub.IsConnected(userID) {
+		return nil
+	}
+
+	wsMessage := types.WebSocketMessage{
+		Type: types.WSTypeNotification,
+		Notification: &types.Notification{
+			Kind:  kind,
+			Title: title,
+			Body:  body,
+			Data:  data,
+		},
+		Timestamp: time.Now(),
+	}
+
+	return rs.SendToUser(userID, wsMessage)
+}
+
 func (rs *RealtimeService) sendErrorToUser(userID string, errorMsg string) {
 	errMessage := types.WebSocketMessage{
 		Type:      types.WSTypeError,
@@ -184,3 +285,124 @@ func (rs *RealtimeService) NotifyNewMessageToParticipants(ctx context.Context, m
 	rs.Hub.Subscribe(clientID, channel)
 	return rs.BroadcastNewMessage(ctx, message.ConversationID, message)
 }
+
+func (rs *RealtimeService) allowEvent(ctx context.Context, policy, userID string) bool {
+	decision, err := rs.eventLimiter.Allow(ctx, policy, userID)
+	if err != nil {
+		log.Printf("Event rate limit check failed for %s: %v", userID, err)
+	}
+	return decision.Allowed
+}
+
+// HandleClientEvent processes an inbound frame from a connected client.
+func (rs *RealtimeService) HandleClientEvent(userID string, payload []byte) {
+	ctx := context.Background()
+
+	var event types.WebSocketClientEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		rs.sendErrorToUser(userID, types.ErrInvalidEvent.Error())
+		return
+	}
+
+	var err error
+	switch event.Type {
+	case types.WSTypeTypingStart, types.WSTypeTypingStop:
+		if !rs.allowEvent(ctx, eventPolicyTyping, userID) {
+			err = types.ErrEventRateLimited
+			break
+		}
+		err = rs.relayTyping(ctx, userID, event)
+	case types.WSTypePresence:
+		if !rs.allowEvent(ctx, eventPolicyPresence, userID) {
+			err = types.ErrEventRateLimited
+			break
+		}
+		// Clients may only report online or away; offline is derived from the socket closing
+		if event.Status != types.PresenceOnline && event.Status != types.PresenceAway {
+			err = types.ErrInvalidPresenceStatus
+			break
+		}
+		err = rs.UpdatePresence(ctx, userID, event.Status)
+	default:
+		err = types.ErrInvalidEvent
+	}
+
+	if err != nil {
+		rs.sendErrorToUser(userID, err.Error())
+	}
+}
+
+func (rs *RealtimeService) relayTyping(ctx context.Context, userID string, event types.WebSocketClientEvent) error {
+	if event.ConversationID <= 0 {
+		return types.ErrInvalidConversationID
+	}
+
+	conversation, err := rs.conversationSvc.GetConversationByID(ctx, event.ConversationID)
+	if err != nil {
+		return types.ErrConversationNotFound
+	}
+
+	var recipientID string
+	switch userID {
+	case conversation.CoachID:
+		recipientID = conversation.ClientID
+	case conversation.ClientID:
+		recipientID = conversation.CoachID
+	default:
+		return types.ErrNotParticipant
+	}
+
+	wsMessage := types.WebSocketMessage{
+		Type:           event.Type,
+		ConversationID: event.ConversationID,
+		UserID:         &userID,
+		Timestamp:      time.Now(),
+	}
+
+	messageBytes, err := json.Marshal(wsMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	rs.Hub.SendToUsers([]string{recipientID}, string(messageBytes))
+	return nil
+}
+
+// UpdatePresence stores the user's presence and relays it to everyone they share a conversation with.
+func (rs *RealtimeService) UpdatePresence(ctx context.Context, userID string, status types.PresenceStatus) error {
+	presence, err := rs.presenceSvc.UpdatePresence(ctx, userID, status)
+	if err != nil {
+		return err
+	}
+
+	partnerIDs, err := rs.conversationSvc.ListPartnerIDs(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list conversation partners: %w", err)
+	}
+
+	wsMessage := types.WebSocketMessage{
+		Type:      types.WSTypePresence,
+		UserID:    &userID,
+		Presence:  presence,
+		Timestamp: time.Now(),
+	}
+
+	messageBytes, err := json.Marshal(wsMessage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	rs.Hub.SendToUsers(partnerIDs, string(messageBytes))
+	return nil
+}
+
+// ApplyConnectionState corrects stored presence with the hub's live view, so users
+// left "online" by an unclean shutdown are reported offline.
+func (rs *RealtimeService) ApplyConnectionState(presences []types.UserPresence) []types.UserPresence {
+	for i := range presences {
+		if presences[i].Status != types.PresenceOffline && !rs.Hub.IsConnected(presences[i].UserID) {
+			presences[i].Status = types.PresenceOffline
+		}
+	}
+	return presences
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/message/pool"
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"golang.org/x/net/websocket"
+)
+
+type fakeConversationService struct {
+	ConversationService
+	conversations map[int]*types.Conversation
+}
+
+func (f *fakeConversationService) GetConversationByID(ctx context.Context, conversationID int) (*types.Conversation, error) {
+	conversation, ok := f.conversations[conversationID]
+	if !ok {
+		return nil, types.ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+func (f *fakeConversationService) ListPartnerIDs(ctx context.Context, userID string) ([]string, error) {
+	var partners []string
+	for _, conversation := range f.conversations {
+		switch userID {
+		case conversation.CoachID:
+			partners = append(partners, conversation.ClientID)
+		case conversation.ClientID:
+			partners = append(partners, conversation.CoachID)
+		}
+	}
+	return partners, nil
+}
+
+type fakePresenceRepo struct {
+	mu       sync.Mutex
+	presence map[string]types.PresenceStatus
+}
+
+func newFakePresenceRepo() *fakePresenceRepo {
+	return &fakePresenceRepo{presence: make(map[string]types.PresenceStatus)}
+}
+
+func (f *fakePresenceRepo) UpsertPresence(ctx context.Context, userID string, status types.PresenceStatus) (*types.UserPresence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.presence[userID] = status
+	return &types.UserPresence{UserID: userID, Status: status}, nil
+}
+
+func (f *fakePresenceRepo) GetPresence(ctx context.Context, userID string) (*types.UserPresence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status, ok := f.presence[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &types.UserPresence{UserID: userID, Status: status}, nil
+}
+
+func (f *fakePresenceRepo) ListPresenceByUsers(ctx context.Context, userIDs []string) ([]types.UserPresence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var presences []types.UserPresence
+	for _, userID := range userIDs {
+		if status, ok := f.presence[userID]; ok {
+			presences = append(presences, types.UserPresence{UserID: userID, Status: status})
+		}
+	}
+	return presences, nil
+}
+
+var _ repository.PresenceRepo = (*fakePresenceRepo)(nil)
+
+// realtimeHarness serves RealtimeService over a real WebSocket so tests see
+// the frames each participant receives.
+type realtimeHarness struct {
+	rs       *RealtimeService
+	presence *fakePresenceRepo
+	server   *httptest.Server
+}
+
+func newRealtimeHarness(t *testing.T) *realtimeHarness {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hub := pool.NewHub()
+	go hub.Run(ctx)
+
+	conversations := &fakeConversationService{conversations: map[int]*types.Conversation{
+		7: {ConversationID: 7, CoachID: "coach", ClientID: "client"},
+	}}
+	presence := newFakePresenceRepo()
+	rs := NewRealtimeService(hub, nil, conversations, nil, NewPresenceService(presence))
+
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		rs.HandleConnection(ctx, conn.Request().URL.Query().Get("user"), conn)
+	}))
+	t.Cleanup(func() {
+		cancel()
+		server.Close()
+	})
+
+	return &realtimeHarness{rs: rs, presence: presence, server: server}
+}
+
+func (h *realtimeHarness) connect(t *testing.T, userID string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(h.server.URL, "http") + "/?user=" + userID
+	conn, err := websocket.Dial(url, "", h.server.URL)
+	if err != nil {
+		t.Fatalf("dial as %s: %v", userID, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !h.rs.IsUserConnected(userID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s never registered with the hub", userID)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, event types.WebSocketClientEvent) {
+	t.Helper()
+	if err := websocket.JSON.Send(conn, event); err != nil {
+		t.Fatalf("send %s: %v", event.Type, err)
+	}
+}
+
+// receive returns the next frame of the given type, skipping others such as
+// presence updates from connecting peers.
+func receive(t *testing.T, conn *websocket.Conn, want types.WebSocketMessageType) types.WebSocketMessage {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg types.WebSocketMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			t.Fatalf("waiting for %s: %v", want, err)
+		}
+		if msg.Type == want {
+			return msg
+		}
+	}
+}
+
+func TestTypingIsRelayedToTheOtherParticipant(t *testing.T) {
+	h := newRealtimeHarness(t)
+	coach := h.connect(t, "coach")
+	client := h.connect(t, "client")
+
+	send(t, client, types.WebSocketClientEvent{Type: types.WSTypeTypingStart, ConversationID: 7})
+
+	msg := receive(t, coach, types.WSTypeTypingStart)
+	if msg.ConversationID != 7 || msg.UserID == nil || *msg.UserID != "client" {
+		t.Errorf("unexpected typing event %+v", msg)
+	}
+
+	send(t, coach, types.WebSocketClientEvent{Type: types.WSTypeTypingStop, ConversationID: 7})
+
+	msg = receive(t, client, types.WSTypeTypingStop)
+	if msg.UserID == nil || *msg.UserID != "coach" {
+		t.Errorf("unexpected typing event %+v", msg)
+	}
+}
+
+func TestTypingRejectsOutsiders(t *testing.T) {
+	h := newRealtimeHarness(t)
+	stranger := h.connect(t, "stranger")
+
+	send(t, stranger, types.WebSocketClientEvent{Type: types.WSTypeTypingStart, ConversationID: 7})
+	if msg := receive(t, stranger, types.WSTypeError); *msg.Error != types.ErrNotParticipant.Error() {
+		t.Errorf("error = %q, want %q", *msg.Error, types.ErrNotParticipant)
+	}
+
+	send(t, stranger, types.WebSocketClientEvent{Type: types.WSTypeTypingStart, ConversationID: 99})
+	if msg := receive(t, stranger, types.WSTypeError); *msg.Error != types.ErrConversationNotFound.Error() {
+		t.Errorf("error = %q, want %q", *msg.Error, types.ErrConversationNotFound)
+	}
+}
+
+func TestTypingIsRateLimited(t *testing.T) {
+	h := newRealtimeHarness(t)
+	h.connect(t, "coach")
+	client := h.connect(t, "client")
+
+	for i := 0; i < 21; i++ {
+		send(t, client, types.WebSocketClientEvent{Type: types.WSTypeTypingStart, ConversationID: 7})
+	}
+
+	if msg := receive(t, client, types.WSTypeError); *msg.Error != types.ErrEventRateLimited.Error() {
+		t.Errorf("error = %q, want %q", *msg.Error, types.ErrEventRateLimited)
+	}
+}
+
+func TestPresenceEvents(t *testing.T) {
+	h := newRealtimeHarness(t)
+	coach := h.connect(t, "coach")
+	client := h.connect(t, "client")
+
+	msg := receive(t, coach, types.WSTypePresence)
+	if msg.Presence == nil || msg.Presence.UserID != "client" || msg.Presence.Status != types.PresenceOnline {
+		t.Fatalf("coach should see the client come online, got %+v", msg)
+	}
+
+	send(t, client, types.WebSocketClientEvent{Type: types.WSTypePresence, Status: types.PresenceAway})
+	msg = receive(t, coach, types.WSTypePresence)
+	if msg.Presence == nil || msg.Presence.Status != types.PresenceAway {
+		t.Fatalf("coach should see the client go away, got %+v", msg)
+	}
+
+	// Offline is only set when the socket closes
+	send(t, client, types.WebSocketClientEvent{Type: types.WSTypePresence, Status: types.PresenceOffline})
+	if msg := receive(t, client, types.WSTypeError); *msg.Error != types.ErrInvalidPresenceStatus.Error() {
+		t.Errorf("error = %q, want %q", *msg.Error, types.ErrInvalidPresenceStatus)
+	}
+
+	client.Close()
+	msg = receive(t, coach, types.WSTypePresence)
+	if msg.Presence == nil || msg.Presence.UserID != "client" || msg.Presence.Status != types.PresenceOffline {
+		t.Errorf("coach should see the client go offline, got %+v", msg)
+	}
+}
+
+func TestUnknownEventsAreRejected(t *testing.T) {
+	h := newRealtimeHarness(t)
+	coach := h.connect(t, "coach")
+
+	if err := websocket.Message.Send(coach, "not json"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receive(t, coach, types.WSTypeError); *msg.Error != types.ErrInvalidEvent.Error() {
+		t.Errorf("error = %q, want %q", *msg.Error, types.ErrInvalidEvent)
+	}
+
+	send(t, coach, types.WebSocketClientEvent{Type: types.WSTypeNewMessage, ConversationID: 7})
+	if msg := receive(t, coach, types.WSTypeError); *msg.Error != types.ErrInvalidEvent.Error() {
+		t.Errorf("error = %q, want %q", *msg.Error, types.ErrInvalidEvent)
+	}
+}
+
+func TestListPresenceReportsUnknownUsersOffline(t *testing.T) {
+	repo := newFakePresenceRepo()
+	repo.presence["coach"] = types.PresenceAway
+	service := NewPresenceService(repo)
+
+	presences, err := service.ListPresence(context.Background(), []string{"coach", "client"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presences) != 2 || presences[0].Status != types.PresenceAway || presences[1].Status != types.PresenceOffline {
+		t.Errorf("unexpected presences %+v", presences)
+	}
+
+	if _, err := service.UpdatePresence(context.Background(), "coach", "busy"); err != types.ErrInvalidPresenceStatus {
+		t.Errorf("UpdatePresence with unknown status = %v, want %v", err, types.ErrInvalidPresenceStatus)
+	}
+}
+
+func TestApplyConnectionStateMarksDisconnectedUsersOffline(t *testing.T) {
+	rs := &RealtimeService{Hub: pool.NewHub()}
+
+	presences := rs.ApplyConnectionState([]types.UserPresence{
+		{UserID: "coach", Status: types.PresenceOnline},
+		{UserID: "client", Status: types.PresenceAway},
+	})
+	for _, presence := range presences {
+		if presence.Status != types.PresenceOffline {
+			t.Errorf("%s should be offline without a live connection, got %s", presence.UserID, presence.Status)
+		}
+	}
+}
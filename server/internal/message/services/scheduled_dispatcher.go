@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/utils"
+)
+
+const (
+	scheduledDispatchInterval  = 30 * time.Second
+	scheduledDispatchBatchSize = 100
+)
+
+// ScheduledMessageDispatcher delivers scheduled messages once they are due.
+// Recipients are claimed with SKIP LOCKED, so several server instances can run
+// a dispatcher without sending the same message twice.
+type ScheduledMessageDispatcher struct {
+	repo            repository.ScheduledMessageRepo
+	messageRepo     repository.MessageRepo
+	messageService  MessageService
+	realtimeService *RealtimeService
+}
+
+func NewScheduledMessageDispatcher(repo repository.MessageStore, messageService MessageService, realtimeService *RealtimeService) *ScheduledMessageDispatcher {
+	return &ScheduledMessageDispatcher{
+		repo:            repo.ScheduledMessages(),
+		messageRepo:     repo.Messages(),
+		messageService:  messageService,
+		realtimeService: realtimeService,
+	}
+}
+
+func (d *ScheduledMessageDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduledDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := d.DispatchDue(ctx); err != nil {
+			log.Printf("Scheduled message dispatch failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("Dispatched %d scheduled messages", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *ScheduledMessageDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, now, scheduledDispatchBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, delivery := range deliveries {
+		delivered, err := d.deliver(ctx, delivery, now)
+		if err != nil {
+			log.Printf("Failed to deliver scheduled message %d to conversation %d: %v",
+				delivery.ScheduledMessageID, delivery.ConversationID, err)
+			if err := d.repo.ReleaseDelivery(ctx, delivery.RecipientID); err != nil {
+				log.Printf("Failed to release scheduled delivery %d: %v", delivery.RecipientID, err)
+			}
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// deliver sends one claimed delivery. It reports false without an error when
+// the run was skipped because it can never be sent; only transient failures
+// are returned, so the row is released and retried.
+func (d *ScheduledMessageDispatcher) deliver(ctx context.Context, delivery types.DueScheduledDelivery, now time.Time) (bool, error) {
+	var nextRunAt *time.Time
+	if delivery.CronExpression != nil {
+		schedule, err := utils.ParseCron(*delivery.CronExpression)
+		if err != nil {
+			return false, d.skip(ctx, delivery, nil, err)
+		}
+		if next := schedule.Next(now.In(loadLocation(delivery.Timezone))); !next.IsZero() {
+			next = next.UTC()
+			nextRunAt = &next
+		}
+	}
+
+	if err := d.messageService.CheckCanSend(ctx, delivery.ConversationID, delivery.MessageText); err != nil {
+		if isUndeliverable(err) {
+			return false, d.skip(ctx, delivery, nextRunAt, err)
+		}
+		return false, err
+	}
+
+	message, err := d.repo.DeliverScheduledMessage(ctx, &delivery, now, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+
+	if d.realtimeService != nil {
+		details := withSenderDetails(ctx, d.messageRepo, message)
+		if err := d.realtimeService.NotifyNewMessageToParticipants(ctx, details, delivery.CoachID, delivery.ClientID); err != nil {
+			log.Printf("Failed to broadcast scheduled message: %v", err)
+		}
+	}
+
+	return true, nil
+}
+
+// skip records a run that will not be sent. Recurring deliveries move on to
+// their next slot; one-off deliveries, or ones whose schedule no longer
+// parses, are marked skipped.
+func (d *ScheduledMessageDispatcher) skip(ctx context.Context, delivery types.DueScheduledDelivery, nextRunAt *time.Time, reason error) error {
+	log.Printf("Skipping scheduled message %d for conversation %d: %v",
+		delivery.ScheduledMessageID, delivery.ConversationID, reason)
+	return d.repo.SkipDelivery(ctx, delivery.RecipientID, nextRunAt)
+}
+
+// isUndeliverable reports errors that retrying the same run cannot fix.
+func isUndeliverable(err error) bool {
+	switch {
+	case errors.Is(err, types.ErrMessagingPaused),
+		errors.Is(err, types.ErrMessagingNotPermitted),
+		errors.Is(err, types.ErrConversationNotFound),
+		errors.Is(err, types.ErrMessageEmpty),
+		errors.Is(err, types.ErrMessageTooLong):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+type fakeCoachingAccess struct {
+	allowed bool
+}
+
+func (f fakeCoachingAccess) AllowsMessaging(ctx context.Context, coachID, clientID string) (bool, error) {
+	return f.allowed, nil
+}
+
+func dueDelivery(recipientID int64, cron *string) types.DueScheduledDelivery {
+	return types.DueScheduledDelivery{
+		ScheduledMessageRecipient: types.ScheduledMessageRecipient{
+			RecipientID:    recipientID,
+			ConversationID: 7,
+			ClientID:       "client",
+			Timezone:       "Europe/Amsterdam",
+			Status:         types.ScheduledRecipientPending,
+		},
+		CoachID:        "coach",
+		MessageText:    "Time for your weekly check-in",
+		CronExpression: cron,
+	}
+}
+
+func TestDispatchDueSendsAndRecordsDeliveries(t *testing.T) {
+	store := newFakeStore()
+	dispatcher := NewScheduledMessageDispatcher(store, NewMessageService(store, fakeCoachingAccess{allowed: true}), nil)
+
+	weekly := "0 9 * * 1"
+	store.due = []types.DueScheduledDelivery{dueDelivery(1, nil), dueDelivery(2, &weekly)}
+
+	sent, err := dispatcher.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 || len(store.messages) != 2 {
+		t.Fatalf("sent %d, stored %d messages, want 2", sent, len(store.messages))
+	}
+
+	if once := store.recipients[1]; once.Status != types.ScheduledRecipientSent || once.NextRunAt != nil {
+		t.Errorf("one-off delivery should be finished, got %+v", once)
+	}
+	if recurring := store.recipients[2]; recurring.Status != types.ScheduledRecipientPending || recurring.NextRunAt == nil {
+		t.Errorf("recurring delivery should move to its next run, got %+v", recurring)
+	}
+}
+
+func TestDispatchDueReleasesFailedDeliveries(t *testing.T) {
+	store := newFakeStore()
+	dispatcher := NewScheduledMessageDispatcher(store, NewMessageService(store, fakeCoachingAccess{allowed: true}), nil)
+
+	store.due = []types.DueScheduledDelivery{dueDelivery(1, nil)}
+	store.deliverErr = errors.New("connection reset")
+
+	sent, err := dispatcher.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 || len(store.messages) != 0 {
+		t.Errorf("a failed delivery must not leave a message behind, sent %d, stored %d", sent, len(store.messages))
+	}
+	if len(store.released) != 1 || store.released[0] != 1 {
+		t.Errorf("failed delivery should be released for a retry, released %v", store.released)
+	}
+}
+
+func TestDispatchDueSkipsUndeliverableRuns(t *testing.T) {
+	store := newFakeStore()
+	dispatcher := NewScheduledMessageDispatcher(store, NewMessageService(store, fakeCoachingAccess{allowed: false}), nil)
+
+	weekly, broken := "0 9 * * 1", "every monday"
+	store.due = []types.DueScheduledDelivery{dueDelivery(1, nil), dueDelivery(2, &weekly), dueDelivery(3, &broken)}
+
+	sent, err := dispatcher.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 || len(store.messages) != 0 || len(store.released) != 0 {
+		t.Fatalf("paused messaging should skip, not send or retry: sent %d, released %v", sent, store.released)
+	}
+
+	if r := store.recipients[1]; r.Status != types.ScheduledRecipientSkipped {
+		t.Errorf("one-off run should be skipped, got %+v", r)
+	}
+	if r := store.recipients[2]; r.Status != types.ScheduledRecipientPending || r.NextRunAt == nil {
+		t.Errorf("recurring run should move to its next slot, got %+v", r)
+	}
+	if r := store.recipients[3]; r.Status != types.ScheduledRecipientSkipped {
+		t.Errorf("run with a bad schedule should be skipped, got %+v", r)
+	}
+}
+
+func TestWithSenderDetailsUsesDisplayName(t *testing.T) {
+	store := newFakeStore()
+	msg := store.addMessage(7, "coach", "Hello", nil)
+
+	if details := withSenderDetails(context.Background(), store, msg); details.SenderName != "Coach Carter" {
+		t.Errorf("SenderName = %q, want the coach's name", details.SenderName)
+	}
+
+	missing := &types.Message{MessageID: 999, SenderID: "coach"}
+	if details := withSenderDetails(context.Background(), store, missing); details.MessageID != 999 || details.SenderName == "coach" {
+		t.Errorf("a failed lookup should keep the message without using the ID as a name, got %+v", details)
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	"github.com/tdmdh/fit-up-server/shared/utils"
+)
+
+type scheduledMessageService struct {
+	repo             repository.ScheduledMessageRepo
+	conversationRepo repository.ConversationRepo
+	now              func() time.Time
+}
+
+func NewScheduledMessageService(repo repository.MessageStore) ScheduledMessageService {
+	return &scheduledMessageService{
+		repo:             repo.ScheduledMessages(),
+		conversationRepo: repo.Conversations(),
+		now:              time.Now,
+	}
+}
+
+func (s *scheduledMessageService) CreateScheduledMessage(ctx context.Context, coachID string, req *types.CreateScheduledMessageRequest) (*types.ScheduledMessage, error) {
+	if err := ValidateMessageText(req.MessageText); err != nil {
+		return nil, err
+	}
+
+	cronExpr, schedule, err := s.validateSchedule(req.SendAt, req.CronExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients, err := s.resolveRecipients(ctx, coachID, req.ConversationIDs, req.ClientIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.assignNextRuns(ctx, recipients, req.SendAt, schedule); err != nil {
+		return nil, err
+	}
+
+	msg := &types.ScheduledMessage{
+		CoachID:        coachID,
+		MessageText:    req.MessageText,
+		SendAt:         req.SendAt,
+		CronExpression: cronExpr,
+		Recipients:     recipients,
+	}
+	if err := s.repo.CreateScheduledMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (s *scheduledMessageService) GetScheduledMessage(ctx context.Context, coachID string, scheduledMessageID int64) (*types.ScheduledMessage, error) {
+	msg, err := s.repo.GetScheduledMessage(ctx, scheduledMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.CoachID != coachID {
+		return nil, types.ErrScheduledMessageNotFound
+	}
+	return msg, nil
+}
+
+func (s *scheduledMessageService) ListScheduledMessages(ctx context.Context, coachID string, status *types.ScheduledMessageStatus, limit, offset int) (*types.ScheduledMessagesResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	messages, total, err := s.repo.ListScheduledMessagesByCoach(ctx, coachID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []types.ScheduledMessage{}
+	}
+
+	return &types.ScheduledMessagesResponse{
+		ScheduledMessages: messages,
+		Total:             total,
+		HasMore:           offset+len(messages) < total,
+	}, nil
+}
+
+func (s *scheduledMessageService) UpdateScheduledMessage(ctx context.Context, coachID string, scheduledMessageID int64, req *types.UpdateScheduledMessageRequest) (*types.ScheduledMessage, error) {
+	msg, err := s.GetScheduledMessage(ctx, coachID, scheduledMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status != types.ScheduledMessageActive {
+		return nil, types.ErrScheduledMessageInactive
+	}
+
+	if req.MessageText != nil {
+		if err := ValidateMessageText(*req.MessageText); err != nil {
+			return nil, err
+		}
+		msg.MessageText = *req.MessageText
+	}
+
+	// Changing either field replaces the whole schedule, so a one-off message
+	// can become recurring and vice versa.
+	if req.SendAt != nil || req.CronExpression != nil {
+		cronExpr, schedule, err := s.validateSchedule(req.SendAt, req.CronExpression)
+		if err != nil {
+			return nil, err
+		}
+		msg.SendAt = req.SendAt
+		msg.CronExpression = cronExpr
+
+		pending := make([]types.ScheduledMessageRecipient, 0, len(msg.Recipients))
+		for _, recipient := range msg.Recipients {
+			if recipient.Status == types.ScheduledRecipientPending {
+				pending = append(pending, recipient)
+			}
+		}
+		if err := s.assignNextRuns(ctx, pending, req.SendAt, schedule); err != nil {
+			return nil, err
+		}
+		msg.Recipients = pending
+	} else {
+		msg.Recipients = nil
+	}
+
+	if err := s.repo.UpdateScheduledMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetScheduledMessage(ctx, scheduledMessageID)
+}
+
+func (s *scheduledMessageService) CancelScheduledMessage(ctx context.Context, coachID string, scheduledMessageID int64) error {
+	if _, err := s.GetScheduledMessage(ctx, coachID, scheduledMessageID); err != nil {
+		return err
+	}
+	return s.repo.CancelScheduledMessage(ctx, scheduledMessageID)
+}
+
+// validateSchedule accepts exactly one of a future send time or a cron expression.
+func (s *scheduledMessageService) validateSchedule(sendAt *time.Time, cronExpression *string) (*string, *utils.CronSchedule, error) {
+	hasCron := cronExpression != nil && strings.TrimSpace(*cronExpression) != ""
+	if (sendAt == nil) == !hasCron {
+		return nil, nil, types.ErrInvalidSchedule
+	}
+
+	if sendAt != nil {
+		if !sendAt.After(s.now()) {
+			return nil, nil, types.ErrInvalidSchedule
+		}
+		return nil, nil, nil
+	}
+
+	expr := strings.TrimSpace(*cronExpression)
+	schedule, err := utils.ParseCron(expr)
+	if err != nil {
+		return nil, nil, types.ErrInvalidSchedule
+	}
+	return &expr, schedule, nil
+}
+
+func (s *scheduledMessageService) resolveRecipients(ctx context.Context, coachID string, conversationIDs []int, clientIDs []string) ([]types.ScheduledMessageRecipient, error) {
+	seen := make(map[int]bool)
+	var recipients []types.ScheduledMessageRecipient
+
+	add := func(conversation *types.Conversation) {
+		if seen[conversation.ConversationID] {
+			return
+		}
+		seen[conversation.ConversationID] = true
+		recipients = append(recipients, types.ScheduledMessageRecipient{
+			ConversationID: conversation.ConversationID,
+			ClientID:       conversation.ClientID,
+		})
+	}
+
+	for _, conversationID := range conversationIDs {
+		conversation, err := s.conversationRepo.GetConversationByID(ctx, conversationID)
+		if err != nil {
+			return nil, conversationLookupError(err)
+		}
+		if conversation.CoachID != coachID {
+			return nil, types.ErrNotParticipant
+		}
+		add(conversation)
+	}
+
+	for _, clientID := range clientIDs {
+		conversation, err := s.conversationRepo.GetConversationByParticipants(ctx, coachID, clientID)
+		if err != nil {
+			return nil, conversationLookupError(err)
+		}
+		add(conversation)
+	}
+
+	if len(recipients) == 0 {
+		return nil, types.ErrNoRecipients
+	}
+	return recipients, nil
+}
+
+// assignNextRuns sets the first delivery time of each recipient. Recurring
+// schedules are evaluated in the client's own timezone.
+func (s *scheduledMessageService) assignNextRuns(ctx context.Context, recipients []types.ScheduledMessageRecipient, sendAt *time.Time, schedule *utils.CronSchedule) error {
+	if schedule == nil {
+		for i := range recipients {
+			recipients[i].NextRunAt = sendAt
+		}
+		return nil
+	}
+
+	clientIDs := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		clientIDs = append(clientIDs, recipient.ClientID)
+	}
+	timezones, err := s.repo.GetUserTimezones(ctx, clientIDs)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	for i := range recipients {
+		recipients[i].Timezone = timezones[recipients[i].ClientID]
+		next := schedule.Next(now.In(loadLocation(recipients[i].Timezone)))
+		if next.IsZero() {
+			return types.ErrInvalidSchedule
+		}
+		next = next.UTC()
+		recipients[i].NextRunAt = &next
+	}
+	return nil
+}
+
+func conversationLookupError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return types.ErrConversationNotFound
+	}
+	return err
+}
+
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
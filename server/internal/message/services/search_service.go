@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+func (s *messageService) SearchMessages(ctx context.Context, userID string, params types.MessageSearchParams) (*types.MessageSearchResponse, error) {
+	if userID == "" {
+		return nil, types.ErrInvalidUserID
+	}
+
+	params.Query = strings.TrimSpace(params.Query)
+	if len(params.Query) < 2 || len(params.Query) > 200 {
+		return nil, types.ErrInvalidSearchQuery
+	}
+
+	if params.ConversationID != nil {
+		isParticipant, err := s.conversationRepo.IsParticipant(ctx, *params.ConversationID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isParticipant {
+			return nil, types.ErrNotParticipant
+		}
+	}
+
+	var cursor *types.MessageSearchCursor
+	if params.Cursor != "" {
+		decoded, err := DecodeSearchCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = decoded
+	}
+
+	limit := params.Limit
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	// Fetch one extra row to know whether another page exists
+	results, err := s.repo.SearchMessages(ctx, userID, params, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Snippet = HighlightSnippet(results[i].Snippet)
+	}
+
+	response := &types.MessageSearchResponse{
+		Results: results,
+	}
+	if len(results) > limit {
+		response.Results = results[:limit]
+		response.HasMore = true
+
+		last := response.Results[limit-1]
+		next := EncodeSearchCursor(types.MessageSearchCursor{SentAt: last.SentAt, MessageID: last.MessageID})
+		response.NextCursor = &next
+	}
+	if response.Results == nil {
+		response.Results = []types.MessageSearchResult{}
+	}
+
+	return response, nil
+}
+
+// HighlightSnippet turns a raw search headline into safe HTML: the message text
+// is escaped and only the match delimiters become <mark> tags. Delimiters a
+// sender typed themselves cannot unbalance the markup.
+func HighlightSnippet(raw string) string {
+	var b strings.Builder
+	open := false
+
+	for {
+		i := strings.IndexAny(raw, types.SearchMatchStart+types.SearchMatchStop)
+		if i < 0 {
+			b.WriteString(html.EscapeString(raw))
+			break
+		}
+		b.WriteString(html.EscapeString(raw[:i]))
+
+		marker := raw[i : i+len(types.SearchMatchStart)]
+		switch {
+		case marker == types.SearchMatchStart && !open:
+			b.WriteString("<mark>")
+			open = true
+		case marker == types.SearchMatchStop && open:
+			b.WriteString("</mark>")
+			open = false
+		}
+		raw = raw[i+len(marker):]
+	}
+
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
+
+func EncodeSearchCursor(cursor types.MessageSearchCursor) string {
+	raw := fmt.Sprintf("%s|%d", cursor.SentAt.UTC().Format(time.RFC3339Nano), cursor.MessageID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeSearchCursor(encoded string) (*types.MessageSearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, types.ErrInvalidSearchCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, types.ErrInvalidSearchCursor
+	}
+
+	sentAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, types.ErrInvalidSearchCursor
+	}
+
+	messageID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || messageID <= 0 {
+		return nil, types.ErrInvalidSearchCursor
+	}
+
+	return &types.MessageSearchCursor{SentAt: sentAt, MessageID: messageID}, nil
+}
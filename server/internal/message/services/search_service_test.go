@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+)
+
+func TestHighlightSnippetEscapesMessageText(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"great " + types.SearchMatchStart + "squat" + types.SearchMatchStop + " today", "great <mark>squat</mark> today"},
+		{
+			`<img src=x onerror=alert(1)> ` + types.SearchMatchStart + "squat" + types.SearchMatchStop,
+			"&lt;img src=x onerror=alert(1)&gt; <mark>squat</mark>",
+		},
+		{types.SearchMatchStart + "<b>squat</b>" + types.SearchMatchStop, "<mark>&lt;b&gt;squat&lt;/b&gt;</mark>"},
+		// Delimiters typed by the sender cannot leave a tag open or close one twice
+		{types.SearchMatchStop + "a" + types.SearchMatchStart + types.SearchMatchStart + "b", "a<mark>b</mark>"},
+	}
+
+	for _, tt := range tests {
+		if got := HighlightSnippet(tt.raw); got != tt.want {
+			t.Errorf("HighlightSnippet(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	cursor := types.MessageSearchCursor{SentAt: time.Date(2026, 3, 4, 5, 6, 7, 890, time.UTC), MessageID: 42}
+
+	decoded, err := DecodeSearchCursor(EncodeSearchCursor(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.SentAt.Equal(cursor.SentAt) || decoded.MessageID != cursor.MessageID {
+		t.Errorf("round trip = %+v, want %+v", decoded, cursor)
+	}
+
+	for _, bad := range []string{"!!!", "bm8tc2VwYXJhdG9y", EncodeSearchCursor(types.MessageSearchCursor{SentAt: cursor.SentAt})} {
+		if _, err := DecodeSearchCursor(bad); err != types.ErrInvalidSearchCursor {
+			t.Errorf("DecodeSearchCursor(%q) = %v, want %v", bad, err, types.ErrInvalidSearchCursor)
+		}
+	}
+}
+
+func TestSearchMessagesPages(t *testing.T) {
+	store := newFakeStore()
+	service := NewMessageService(store, nil)
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		store.searchResults = append(store.searchResults, types.MessageSearchResult{
+			MessageID: int64(10 - i),
+			SentAt:    start.Add(-time.Duration(i) * time.Hour),
+			Snippet:   types.SearchMatchStart + "<squat>" + types.SearchMatchStop,
+		})
+	}
+
+	page, err := service.SearchMessages(ctx, "client", types.MessageSearchParams{Query: "squat", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.searchLimit != 3 {
+		t.Errorf("search should fetch one extra row, fetched %d", store.searchLimit)
+	}
+	if len(page.Results) != 2 || !page.HasMore || page.NextCursor == nil {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	if page.Results[0].Snippet != "<mark>&lt;squat&gt;</mark>" {
+		t.Errorf("snippet = %q, want it escaped and highlighted", page.Results[0].Snippet)
+	}
+
+	var seen []int64
+	for _, result := range page.Results {
+		seen = append(seen, result.MessageID)
+	}
+	for page.HasMore {
+		page, err = service.SearchMessages(ctx, "client", types.MessageSearchParams{Query: "squat", Limit: 2, Cursor: *page.NextCursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, result := range page.Results {
+			seen = append(seen, result.MessageID)
+		}
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("paging returned %v, want all 5 results once", seen)
+	}
+	for i, id := range seen {
+		if id != int64(10-i) {
+			t.Errorf("result %d = %d, want %d", i, id, 10-i)
+		}
+	}
+	if page.NextCursor != nil {
+		t.Error("the last page should not have a cursor")
+	}
+}
+
+func TestSearchMessagesValidation(t *testing.T) {
+	service := NewMessageService(newFakeStore(), nil)
+	ctx := context.Background()
+
+	if _, err := service.SearchMessages(ctx, "client", types.MessageSearchParams{Query: " a "}); err != types.ErrInvalidSearchQuery {
+		t.Errorf("short query = %v, want %v", err, types.ErrInvalidSearchQuery)
+	}
+	if _, err := service.SearchMessages(ctx, "client", types.MessageSearchParams{Query: "squat", Cursor: "not a cursor"}); err != types.ErrInvalidSearchCursor {
+		t.Errorf("bad cursor = %v, want %v", err, types.ErrInvalidSearchCursor)
+	}
+
+	conversationID := 7
+	if _, err := service.SearchMessages(ctx, "stranger", types.MessageSearchParams{Query: "squat", ConversationID: &conversationID}); err != types.ErrNotParticipant {
+		t.Errorf("search outside own conversations = %v, want %v", err, types.ErrNotParticipant)
+	}
+
+	page, err := service.SearchMessages(ctx, "client", types.MessageSearchParams{Query: "squat", ConversationID: &conversationID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Results == nil || page.HasMore {
+		t.Errorf("no matches should be an empty page, got %+v", page)
+	}
+}
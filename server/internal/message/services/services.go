@@ -14,12 +14,16 @@ type ConversationService interface {
 	ListConversationsByUser(ctx context.Context, userID string, includeArchived bool, limit, offset int) (*types.ConversationsResponse, error)
 
 	IsParticipant(ctx context.Context, conversationID int, userID string) (bool, error)
+	ListPartnerIDs(ctx context.Context, userID string) ([]string, error)
 }
 
 type MessageService interface {
 	CreateMessage(ctx context.Context, conversationID int, senderID, messageText string, replyToMessageID *int64) (*types.Message, error)
+	CheckCanSend(ctx context.Context, conversationID int, messageText string) error
 	GetMessageByID(ctx context.Context, messageID int64) (*types.Message, error)
 	ListMessages(ctx context.Context, conversationID int, userID string, limit, offset int) (*types.MessagesResponse, error)
+	GetThread(ctx context.Context, messageID int64, userID string, limit, offset int) (*types.ThreadResponse, error)
+	SearchMessages(ctx context.Context, userID string, params types.MessageSearchParams) (*types.MessageSearchResponse, error)
 
 	UpdateMessage(ctx context.Context, messageID int64, messageText string) error
 	DeleteMessage(ctx context.Context, messageID int64) error
@@ -37,11 +41,49 @@ type MessageAttachmentService interface {
 	DeleteAttachment(ctx context.Context, attachmentID int64) error
 }
 
+type ReactionService interface {
+	ToggleReaction(ctx context.Context, messageID int64, userID, emoji string) (*types.MessageReaction, bool, error)
+	ListReactions(ctx context.Context, messageID int64, userID string) ([]types.ReactionSummary, error)
+}
+
+type PinService interface {
+	PinMessage(ctx context.Context, conversationID int, messageID int64, userID string) (*types.PinnedMessage, error)
+	UnpinMessage(ctx context.Context, conversationID int, messageID int64) error
+	ListPinnedMessages(ctx context.Context, conversationID int, userID string) ([]types.PinnedMessage, error)
+}
+
+type PresenceService interface {
+	UpdatePresence(ctx context.Context, userID string, status types.PresenceStatus) (*types.UserPresence, error)
+	GetPresence(ctx context.Context, userID string) (*types.UserPresence, error)
+	ListPresence(ctx context.Context, userIDs []string) ([]types.UserPresence, error)
+}
+
+type ScheduledMessageService interface {
+	CreateScheduledMessage(ctx context.Context, coachID string, req *types.CreateScheduledMessageRequest) (*types.ScheduledMessage, error)
+	GetScheduledMessage(ctx context.Context, coachID string, scheduledMessageID int64) (*types.ScheduledMessage, error)
+	ListScheduledMessages(ctx context.Context, coachID string, status *types.ScheduledMessageStatus, limit, offset int) (*types.ScheduledMessagesResponse, error)
+	UpdateScheduledMessage(ctx context.Context, coachID string, scheduledMessageID int64, req *types.UpdateScheduledMessageRequest) (*types.ScheduledMessage, error)
+	CancelScheduledMessage(ctx context.Context, coachID string, scheduledMessageID int64) error
+}
+
+type WorkoutPlanService interface {
+	SendWorkoutPlan(ctx context.Context, coachID string, req *types.SendWorkoutPlanRequest) (*types.MessageWithDetails, error)
+	GetWorkoutPlan(ctx context.Context, cardID int64, userID string) (*types.WorkoutPlanCard, error)
+	AcceptWorkoutPlan(ctx context.Context, cardID int64, clientID string) (*types.WorkoutPlanCard, error)
+	DeclineWorkoutPlan(ctx context.Context, cardID int64, clientID string) (*types.WorkoutPlanCard, error)
+	ListWorkoutPlanEvents(ctx context.Context, cardID int64, userID string) ([]types.WorkoutPlanCardEvent, error)
+}
+
 type MessageServiceManager interface {
 	Conversations() ConversationService
 	Messages() MessageService
 	ReadStatus() MessageReadStatusService
 	Attachments() MessageAttachmentService
+	Reactions() ReactionService
+	Pins() PinService
+	Presence() PresenceService
+	ScheduledMessages() ScheduledMessageService
+	WorkoutPlans() WorkoutPlanService
 	Realtime() *RealtimeService
 	WithTransaction(ctx context.Context, fn func(context.Context) error) error
 }
@@ -54,16 +96,30 @@ type Service struct {
 	messageService           MessageService
 	messageReadStatusService MessageReadStatusService
 	messageAttachmentService MessageAttachmentService
+	reactionService          ReactionService
+	pinService               PinService
+	presenceService          PresenceService
+	scheduledMessageService  ScheduledMessageService
+	workoutPlanService       WorkoutPlanService
+
+	workoutPlanSource WorkoutPlanSource
+	coachingAccess    CoachingAccess
+	teamAccess        TeamAccess
 }
 
 func NewMessagesService(repo repository.MessageStore) *Service {
 	return &Service{
 		repo:                     repo,
 		conversationService:      NewConversationService(repo),
-		messageService:           NewMessageService(repo),
+		messageService:           NewMessageService(repo, nil), // Will be set later with SetCoachingAccess
 		messageReadStatusService: NewMessageReadStatusService(repo.ReadStatus()),
 		messageAttachmentService: NewMessageAttachmentService(repo),
-		realtimeService:          nil, // Will be set later with SetRealtimeService
+		reactionService:          NewReactionService(repo.Reactions()),
+		pinService:               NewPinService(repo.Pins()),
+		presenceService:          NewPresenceService(repo.Presence()),
+		scheduledMessageService:  NewScheduledMessageService(repo),
+		workoutPlanService:       NewWorkoutPlanService(repo, nil, nil), // Will be set later with SetWorkoutPlanSource
+		realtimeService:          nil,                                   // Will be set later with SetRealtimeService
 	}
 }
 
@@ -71,6 +127,30 @@ func (s *Service) SetRealtimeService(realtimeService *RealtimeService) {
 	s.realtimeService = realtimeService
 }
 
+// SetWorkoutPlanSource connects plan cards to the schema module.
+func (s *Service) SetWorkoutPlanSource(source WorkoutPlanSource) {
+	s.workoutPlanSource = source
+	s.workoutPlanService = NewWorkoutPlanService(s.repo, source, s.coachingAccess)
+}
+
+// SetCoachingAccess pauses messaging between coaches and clients whose
+// coaching subscription has lapsed. Call it before Messages() is handed to
+// other services, since it replaces the message service.
+func (s *Service) SetCoachingAccess(access CoachingAccess) {
+	s.coachingAccess = access
+	s.messageService = newMessageService(s.repo, access, s.teamAccess)
+	s.workoutPlanService = NewWorkoutPlanService(s.repo, s.workoutPlanSource, access)
+}
+
+// SetTeamAccess stops team staff without the message permission from
+// messaging their teammates' clients. Like SetCoachingAccess it replaces the
+// message and conversation services.
+func (s *Service) SetTeamAccess(team TeamAccess) {
+	s.teamAccess = team
+	s.conversationService = newConversationService(s.repo, team)
+	s.messageService = newMessageService(s.repo, s.coachingAccess, team)
+}
+
 func (s *Service) Conversations() ConversationService {
 	return s.conversationService
 }
@@ -87,6 +167,26 @@ func (s *Service) Attachments() MessageAttachmentService {
 	return s.messageAttachmentService
 }
 
+func (s *Service) Reactions() ReactionService {
+	return s.reactionService
+}
+
+func (s *Service) Pins() PinService {
+	return s.pinService
+}
+
+func (s *Service) Presence() PresenceService {
+	return s.presenceService
+}
+
+func (s *Service) ScheduledMessages() ScheduledMessageService {
+	return s.scheduledMessageService
+}
+
+func (s *Service) WorkoutPlans() WorkoutPlanService {
+	return s.workoutPlanService
+}
+
 func (s *Service) Realtime() *RealtimeService {
 	return s.realtimeService
 }
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/tdmdh/fit-up-server/internal/message/repository"
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	schemaTypes "github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// WorkoutPlanSource gives plan cards access to the schema module. The schema
+// CoachService satisfies it.
+type WorkoutPlanSource interface {
+	SummarizeSharedPlan(ctx context.Context, coachID string, kind schemaTypes.SharedPlanKind, sourceID int) (*schemaTypes.SharedPlanSummary, error)
+	CloneSharedPlanToClient(ctx context.Context, coachID string, clientAuthID string, kind schemaTypes.SharedPlanKind, sourceID int) (*schemaTypes.WeeklySchemaExtended, error)
+}
+
+type workoutPlanService struct {
+	store            repository.MessageStore
+	repo             repository.WorkoutPlanCardRepo
+	conversationRepo repository.ConversationRepo
+	messageRepo      repository.MessageRepo
+	attachmentRepo   repository.MessageAttachmentRepo
+	source           WorkoutPlanSource
+	access           CoachingAccess
+}
+
+func NewWorkoutPlanService(repo repository.MessageStore, source WorkoutPlanSource, access CoachingAccess) WorkoutPlanService {
+	return &workoutPlanService{
+		store:            repo,
+		repo:             repo.WorkoutPlans(),
+		conversationRepo: repo.Conversations(),
+		messageRepo:      repo.Messages(),
+		attachmentRepo:   repo.Attachments(),
+		source:           source,
+		access:           access,
+	}
+}
+
+func (s *workoutPlanService) SendWorkoutPlan(ctx context.Context, coachID string, req *types.SendWorkoutPlanRequest) (*types.MessageWithDetails, error) {
+	if s.source == nil {
+		return nil, types.ErrWorkoutPlansUnavailable
+	}
+	if !isValidWorkoutPlanKind(req.Kind) {
+		return nil, types.ErrInvalidWorkoutPlanKind
+	}
+	if req.SourceID <= 0 {
+		return nil, types.ErrWorkoutPlanNotFound
+	}
+
+	conversation, err := s.conversationRepo.GetConversationByID(ctx, req.ConversationID)
+	if err != nil {
+		return nil, conversationLookupError(err)
+	}
+	if conversation.CoachID != coachID {
+		return nil, types.ErrNotParticipant
+	}
+	if s.access != nil {
+		if err := checkMessagingAccess(ctx, s.access, conversation.CoachID, conversation.ClientID); err != nil {
+			return nil, err
+		}
+	}
+
+	summary, err := s.source.SummarizeSharedPlan(ctx, coachID, schemaTypes.SharedPlanKind(req.Kind), req.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return nil, err
+	}
+
+	messageText := req.MessageText
+	if messageText == "" {
+		messageText = fmt.Sprintf("Shared a workout plan: %s", summary.Title)
+	}
+	if err := ValidateMessageText(messageText); err != nil {
+		return nil, err
+	}
+
+	var (
+		message    *types.Message
+		attachment *types.MessageAttachment
+		card       *types.WorkoutPlanCard
+	)
+	err = s.store.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		message, err = s.messageRepo.CreateMessage(ctx, conversation.ConversationID, coachID, messageText, nil)
+		if err != nil {
+			return err
+		}
+
+		card = &types.WorkoutPlanCard{
+			MessageID:      message.MessageID,
+			ConversationID: conversation.ConversationID,
+			CoachID:        coachID,
+			ClientID:       conversation.ClientID,
+			Kind:           req.Kind,
+			SourceID:       req.SourceID,
+			Summary:        summaryJSON,
+		}
+		if err := s.repo.CreateWorkoutPlanCard(ctx, card); err != nil {
+			return err
+		}
+
+		attachment, err = s.attachmentRepo.CreateAttachment(ctx, message.MessageID, types.AttachmentTypeWorkoutPlan,
+			summary.Title, fmt.Sprintf("/api/v1/workout-plans/%d", card.CardID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, card.CardID, coachID, types.WorkoutPlanCardActionSent, map[string]interface{}{
+		"kind":      req.Kind,
+		"source_id": req.SourceID,
+		"client_id": conversation.ClientID,
+	})
+
+	details := withSenderDetails(ctx, s.messageRepo, message)
+	details.Attachments = []types.MessageAttachment{*attachment}
+	details.WorkoutPlan = card
+	return details, nil
+}
+
+func (s *workoutPlanService) GetWorkoutPlan(ctx context.Context, cardID int64, userID string) (*types.WorkoutPlanCard, error) {
+	card, err := s.repo.GetWorkoutPlanCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if card.CoachID != userID && card.ClientID != userID {
+		return nil, types.ErrNotParticipant
+	}
+	return card, nil
+}
+
+func (s *workoutPlanService) AcceptWorkoutPlan(ctx context.Context, cardID int64, clientID string) (*types.WorkoutPlanCard, error) {
+	if s.source == nil {
+		return nil, types.ErrWorkoutPlansUnavailable
+	}
+
+	card, err := s.pendingCardForClient(ctx, cardID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The card is claimed before the plan is cloned and stays locked until the
+	// transaction ends, so a concurrent accept waits and then finds it answered
+	// instead of cloning the plan a second time.
+	err = s.store.WithTransaction(ctx, func(ctx context.Context) error {
+		claimed, err := s.repo.RespondToWorkoutPlanCard(ctx, cardID, types.WorkoutPlanCardAccepted, nil)
+		if err != nil {
+			return err
+		}
+
+		schema, err := s.source.CloneSharedPlanToClient(ctx, claimed.CoachID, clientID, schemaTypes.SharedPlanKind(claimed.Kind), claimed.SourceID)
+		if err != nil {
+			return err
+		}
+
+		card, err = s.repo.SetWorkoutPlanCardSchema(ctx, cardID, schema.SchemaID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, cardID, clientID, types.WorkoutPlanCardActionAccepted, map[string]interface{}{
+		"schema_id": *card.AcceptedSchemaID,
+	})
+
+	return card, nil
+}
+
+func (s *workoutPlanService) DeclineWorkoutPlan(ctx context.Context, cardID int64, clientID string) (*types.WorkoutPlanCard, error) {
+	if _, err := s.pendingCardForClient(ctx, cardID, clientID); err != nil {
+		return nil, err
+	}
+
+	card, err := s.repo.RespondToWorkoutPlanCard(ctx, cardID, types.WorkoutPlanCardDeclined, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, cardID, clientID, types.WorkoutPlanCardActionDeclined, nil)
+
+	return card, nil
+}
+
+func (s *workoutPlanService) ListWorkoutPlanEvents(ctx context.Context, cardID int64, userID string) ([]types.WorkoutPlanCardEvent, error) {
+	if _, err := s.GetWorkoutPlan(ctx, cardID, userID); err != nil {
+		return nil, err
+	}
+
+	events, err := s.repo.ListWorkoutPlanCardEvents(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []types.WorkoutPlanCardEvent{}
+	}
+	return events, nil
+}
+
+func (s *workoutPlanService) pendingCardForClient(ctx context.Context, cardID int64, clientID string) (*types.WorkoutPlanCard, error) {
+	card, err := s.repo.GetWorkoutPlanCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if card.ClientID != clientID {
+		return nil, types.ErrNotWorkoutPlanRecipient
+	}
+	if card.Status != types.WorkoutPlanCardPending {
+		return nil, types.ErrWorkoutPlanResponded
+	}
+	return card, nil
+}
+
+// recordEvent appends to the audit trail. A failed write is logged rather than
+// undoing the action it describes.
+func (s *workoutPlanService) recordEvent(ctx context.Context, cardID int64, actorID string, action types.WorkoutPlanCardAction, details map[string]interface{}) {
+	event := &types.WorkoutPlanCardEvent{
+		CardID:  cardID,
+		ActorID: actorID,
+		Action:  action,
+	}
+	if details != nil {
+		if raw, err := json.Marshal(details); err == nil {
+			event.Details = raw
+		}
+	}
+
+	if err := s.repo.CreateWorkoutPlanCardEvent(ctx, event); err != nil {
+		log.Printf("Failed to record workout plan card event %s for card %d: %v", action, cardID, err)
+	}
+}
+
+func isValidWorkoutPlanKind(kind types.WorkoutPlanKind) bool {
+	switch kind {
+	case types.WorkoutPlanKindWeeklySchema, types.WorkoutPlanKindGeneratedPlan, types.WorkoutPlanKindWorkout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tdmdh/fit-up-server/internal/message/types"
+	schemaTypes "github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+type fakePlanSource struct {
+	clones   int
+	cloneErr error
+	onClone  func()
+}
+
+func (f *fakePlanSource) SummarizeSharedPlan(ctx context.Context, coachID string, kind schemaTypes.SharedPlanKind, sourceID int) (*schemaTypes.SharedPlanSummary, error) {
+	return &schemaTypes.SharedPlanSummary{Kind: kind, SourceID: sourceID, Title: "Push pull legs", WorkoutCount: 3}, nil
+}
+
+func (f *fakePlanSource) CloneSharedPlanToClient(ctx context.Context, coachID string, clientAuthID string, kind schemaTypes.SharedPlanKind, sourceID int) (*schemaTypes.WeeklySchemaExtended, error) {
+	if f.onClone != nil {
+		f.onClone()
+	}
+	if f.cloneErr != nil {
+		return nil, f.cloneErr
+	}
+	f.clones++
+	schema := &schemaTypes.WeeklySchemaExtended{CoachID: &coachID}
+	schema.SchemaID = 100 + f.clones
+	schema.UserID = clientAuthID
+	return schema, nil
+}
+
+func newWorkoutPlanHarness() (*fakeStore, *fakePlanSource, WorkoutPlanService) {
+	store := newFakeStore()
+	source := &fakePlanSource{}
+	return store, source, NewWorkoutPlanService(store, source, fakeCoachingAccess{allowed: true})
+}
+
+func sendPlan(t *testing.T, service WorkoutPlanService) *types.WorkoutPlanCard {
+	t.Helper()
+
+	sent, err := service.SendWorkoutPlan(context.Background(), "coach", &types.SendWorkoutPlanRequest{
+		ConversationID: 7,
+		Kind:           types.WorkoutPlanKindWeeklySchema,
+		SourceID:       12,
+	})
+	if err != nil {
+		t.Fatalf("SendWorkoutPlan: %v", err)
+	}
+	return sent.WorkoutPlan
+}
+
+func TestSendWorkoutPlan(t *testing.T) {
+	store, _, service := newWorkoutPlanHarness()
+
+	sent, err := service.SendWorkoutPlan(context.Background(), "coach", &types.SendWorkoutPlanRequest{
+		ConversationID: 7,
+		Kind:           types.WorkoutPlanKindWeeklySchema,
+		SourceID:       12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent.SenderName != "Coach Carter" {
+		t.Errorf("SenderName = %q, want the coach's display name", sent.SenderName)
+	}
+	if sent.MessageText != "Shared a workout plan: Push pull legs" {
+		t.Errorf("MessageText = %q", sent.MessageText)
+	}
+	if sent.WorkoutPlan == nil || sent.WorkoutPlan.MessageID != sent.MessageID || sent.WorkoutPlan.ClientID != "client" {
+		t.Errorf("unexpected card %+v", sent.WorkoutPlan)
+	}
+	if len(sent.Attachments) != 1 || sent.Attachments[0].AttachmentType != types.AttachmentTypeWorkoutPlan {
+		t.Errorf("unexpected attachments %+v", sent.Attachments)
+	}
+	if len(store.cardEvents) != 1 || store.cardEvents[0].Action != types.WorkoutPlanCardActionSent {
+		t.Errorf("unexpected events %+v", store.cardEvents)
+	}
+}
+
+func TestSendWorkoutPlanStoresNothingWhenAStepFails(t *testing.T) {
+	store, _, service := newWorkoutPlanHarness()
+	store.attachmentErr = errors.New("connection reset")
+
+	_, err := service.SendWorkoutPlan(context.Background(), "coach", &types.SendWorkoutPlanRequest{
+		ConversationID: 7,
+		Kind:           types.WorkoutPlanKindWorkout,
+		SourceID:       12,
+	})
+	if err == nil {
+		t.Fatal("expected the attachment failure to be returned")
+	}
+	if len(store.messages) != 0 || len(store.cards) != 0 || len(store.cardEvents) != 0 {
+		t.Errorf("nothing should be stored, got %d messages, %d cards and %d events",
+			len(store.messages), len(store.cards), len(store.cardEvents))
+	}
+}
+
+func TestSendWorkoutPlanValidation(t *testing.T) {
+	_, _, service := newWorkoutPlanHarness()
+
+	tests := []struct {
+		name    string
+		coachID string
+		req     types.SendWorkoutPlanRequest
+		want    error
+	}{
+		{"unknown kind", "coach", types.SendWorkoutPlanRequest{ConversationID: 7, Kind: "program", SourceID: 12}, types.ErrInvalidWorkoutPlanKind},
+		{"missing source", "coach", types.SendWorkoutPlanRequest{ConversationID: 7, Kind: types.WorkoutPlanKindWorkout}, types.ErrWorkoutPlanNotFound},
+		{"client sends", "client", types.SendWorkoutPlanRequest{ConversationID: 7, Kind: types.WorkoutPlanKindWorkout, SourceID: 12}, types.ErrNotParticipant},
+		{"unknown conversation", "coach", types.SendWorkoutPlanRequest{ConversationID: 99, Kind: types.WorkoutPlanKindWorkout, SourceID: 12}, types.ErrConversationNotFound},
+	}
+
+	for _, tt := range tests {
+		if _, err := service.SendWorkoutPlan(context.Background(), tt.coachID, &tt.req); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestAcceptWorkoutPlan(t *testing.T) {
+	store, source, service := newWorkoutPlanHarness()
+	card := sendPlan(t, service)
+
+	accepted, err := service.AcceptWorkoutPlan(context.Background(), card.CardID, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Status != types.WorkoutPlanCardAccepted || accepted.AcceptedSchemaID == nil || *accepted.AcceptedSchemaID != 101 {
+		t.Errorf("unexpected card %+v", accepted)
+	}
+	if source.clones != 1 {
+		t.Errorf("plan cloned %d times, want 1", source.clones)
+	}
+	if last := store.cardEvents[len(store.cardEvents)-1]; last.Action != types.WorkoutPlanCardActionAccepted || last.ActorID != "client" {
+		t.Errorf("unexpected event %+v", last)
+	}
+}
+
+func TestAcceptWorkoutPlanRejectsAnsweredCards(t *testing.T) {
+	_, source, service := newWorkoutPlanHarness()
+
+	accepted := sendPlan(t, service)
+	if _, err := service.AcceptWorkoutPlan(context.Background(), accepted.CardID, "client"); err != nil {
+		t.Fatal(err)
+	}
+	declined := sendPlan(t, service)
+	if _, err := service.DeclineWorkoutPlan(context.Background(), declined.CardID, "client"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cardID := range []int64{accepted.CardID, declined.CardID} {
+		if _, err := service.AcceptWorkoutPlan(context.Background(), cardID, "client"); err != types.ErrWorkoutPlanResponded {
+			t.Errorf("accepting card %d again: err = %v, want %v", cardID, err, types.ErrWorkoutPlanResponded)
+		}
+	}
+	if source.clones != 1 {
+		t.Errorf("plan cloned %d times, want 1", source.clones)
+	}
+}
+
+func TestAcceptWorkoutPlanClaimsTheCardBeforeCloning(t *testing.T) {
+	_, source, service := newWorkoutPlanHarness()
+	card := sendPlan(t, service)
+
+	// A second accept arriving while the plan is being cloned must find the
+	// card already answered rather than clone it again.
+	var raceErr error
+	source.onClone = func() {
+		source.onClone = nil
+		_, raceErr = service.AcceptWorkoutPlan(context.Background(), card.CardID, "client")
+	}
+
+	if _, err := service.AcceptWorkoutPlan(context.Background(), card.CardID, "client"); err != nil {
+		t.Fatal(err)
+	}
+	if raceErr != types.ErrWorkoutPlanResponded {
+		t.Errorf("concurrent accept: err = %v, want %v", raceErr, types.ErrWorkoutPlanResponded)
+	}
+	if source.clones != 1 {
+		t.Errorf("plan cloned %d times, want 1", source.clones)
+	}
+}
+
+func TestAcceptWorkoutPlanLeavesCardPendingWhenCloneFails(t *testing.T) {
+	store, source, service := newWorkoutPlanHarness()
+	card := sendPlan(t, service)
+	source.cloneErr = schemaTypes.ErrSharedPlanDenied
+
+	if _, err := service.AcceptWorkoutPlan(context.Background(), card.CardID, "client"); err != schemaTypes.ErrSharedPlanDenied {
+		t.Fatalf("err = %v, want %v", err, schemaTypes.ErrSharedPlanDenied)
+	}
+	if got := store.cards[card.CardID]; got.Status != types.WorkoutPlanCardPending || got.AcceptedSchemaID != nil {
+		t.Errorf("card should still be pending, got %+v", got)
+	}
+
+	source.cloneErr = nil
+	if _, err := service.AcceptWorkoutPlan(context.Background(), card.CardID, "client"); err != nil {
+		t.Errorf("retrying the accept: %v", err)
+	}
+}
+
+func TestOnlyTheRecipientCanAnswerAWorkoutPlan(t *testing.T) {
+	store, source, service := newWorkoutPlanHarness()
+	card := sendPlan(t, service)
+
+	for _, userID := range []string{"coach", "other-client"} {
+		if _, err := service.AcceptWorkoutPlan(context.Background(), card.CardID, userID); err != types.ErrNotWorkoutPlanRecipient {
+			t.Errorf("accept as %s: err = %v, want %v", userID, err, types.ErrNotWorkoutPlanRecipient)
+		}
+		if _, err := service.DeclineWorkoutPlan(context.Background(), card.CardID, userID); err != types.ErrNotWorkoutPlanRecipient {
+			t.Errorf("decline as %s: err = %v, want %v", userID, err, types.ErrNotWorkoutPlanRecipient)
+		}
+	}
+
+	if source.clones != 0 || store.cards[card.CardID].Status != types.WorkoutPlanCardPending {
+		t.Errorf("card should be untouched, got %+v after %d clones", store.cards[card.CardID], source.clones)
+	}
+	if _, err := service.AcceptWorkoutPlan(context.Background(), 999, "client"); err != types.ErrWorkoutPlanNotFound {
+		t.Errorf("unknown card: err = %v, want %v", err, types.ErrWorkoutPlanNotFound)
+	}
+}
+
+func TestDeclineWorkoutPlan(t *testing.T) {
+	store, source, service := newWorkoutPlanHarness()
+	card := sendPlan(t, service)
+
+	declined, err := service.DeclineWorkoutPlan(context.Background(), card.CardID, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if declined.Status != types.WorkoutPlanCardDeclined || declined.AcceptedSchemaID != nil {
+		t.Errorf("unexpected card %+v", declined)
+	}
+	if source.clones != 0 {
+		t.Errorf("declining should not clone the plan")
+	}
+	if last := store.cardEvents[len(store.cardEvents)-1]; last.Action != types.WorkoutPlanCardActionDeclined {
+		t.Errorf("unexpected event %+v", last)
+	}
+
+	if _, err := service.DeclineWorkoutPlan(context.Background(), card.CardID, "client"); err != types.ErrWorkoutPlanResponded {
+		t.Errorf("declining twice: err = %v, want %v", err, types.ErrWorkoutPlanResponded)
+	}
+}
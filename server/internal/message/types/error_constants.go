@@ -33,6 +33,31 @@ var (
 	ErrInvalidMessageID      = errors.New("invalid message ID")
 	ErrInvalidPagination     = errors.New("invalid pagination parameters")
 
+	ErrInvalidEvent          = errors.New("invalid websocket event")
+	ErrInvalidPresenceStatus = errors.New("invalid presence status")
+	ErrEventRateLimited      = errors.New("too many events, slow down")
+
+	ErrInvalidEmoji       = errors.New("invalid reaction emoji")
+	ErrMessageNotPinned   = errors.New("message is not pinned")
+	ErrPinLimitReached    = errors.New("conversation has reached the pinned message limit")
+
+	ErrInvalidSearchQuery  = errors.New("search query must be between 2 and 200 characters")
+	ErrInvalidSearchCursor = errors.New("invalid search cursor")
+
+	ErrScheduledMessageNotFound = errors.New("scheduled message not found")
+	ErrInvalidSchedule          = errors.New("provide either send_at in the future or a valid cron_expression")
+	ErrNoRecipients             = errors.New("scheduled message needs at least one conversation or client")
+	ErrScheduledMessageInactive = errors.New("scheduled message is no longer active")
+
+	ErrWorkoutPlanNotFound     = errors.New("workout plan card not found")
+	ErrInvalidWorkoutPlanKind  = errors.New("workout plan kind must be weekly_schema, generated_plan or workout")
+	ErrWorkoutPlanResponded    = errors.New("workout plan card has already been answered")
+	ErrNotWorkoutPlanRecipient = errors.New("only the client who received the plan can answer it")
+	ErrWorkoutPlansUnavailable = errors.New("workout plan sharing is not available")
+
+	ErrMessagingPaused       = errors.New("messaging is paused until the coaching subscription is renewed")
+	ErrMessagingNotPermitted = errors.New("your team permissions do not allow messaging this client")
+
 	ErrInternalServer = errors.New("internal server error")
 	ErrDatabaseError  = errors.New("database error")
 )
@@ -41,6 +66,7 @@ const (
 	StatusConversationNotFound = 404
 	StatusMessageNotFound      = 404
 	StatusUnauthorized         = 403
+	StatusPaymentRequired      = 402
 	StatusInvalidRequest       = 400
 	StatusConversationExists   = 409
 	StatusInternalError        = 500
@@ -87,6 +113,10 @@ func getErrorCode(err error) string {
 		return "NOT_PARTICIPANT"
 	case ErrMessageDeleted:
 		return "MESSAGE_DELETED"
+	case ErrMessagingPaused:
+		return "MESSAGING_PAUSED"
+	case ErrMessagingNotPermitted:
+		return "MESSAGING_NOT_PERMITTED"
 	default:
 		return "INTERNAL_ERROR"
 	}
@@ -94,16 +124,24 @@ func getErrorCode(err error) string {
 
 func GetHTTPStatus(err error) int {
 	switch err {
-	case ErrConversationNotFound, ErrMessageNotFound, ErrAttachmentNotFound:
+	case ErrConversationNotFound, ErrMessageNotFound, ErrAttachmentNotFound, ErrMessageNotPinned,
+		ErrScheduledMessageNotFound, ErrWorkoutPlanNotFound:
 		return StatusConversationNotFound
-	case ErrUnauthorized, ErrNotParticipant, ErrNotMessageSender:
+	case ErrUnauthorized, ErrNotParticipant, ErrNotMessageSender, ErrNotWorkoutPlanRecipient,
+		ErrMessagingNotPermitted:
 		return StatusUnauthorized
 	case ErrConversationExists:
 		return StatusConversationExists
 	case ErrInvalidConversation, ErrMessageEmpty, ErrMessageTooLong,
 		ErrInvalidAttachment, ErrInvalidUserID, ErrInvalidConversationID,
-		ErrInvalidMessageID, ErrInvalidPagination:
+		ErrInvalidMessageID, ErrInvalidPagination, ErrInvalidEvent,
+		ErrInvalidPresenceStatus, ErrInvalidEmoji, ErrPinLimitReached,
+		ErrInvalidSearchQuery, ErrInvalidSearchCursor, ErrInvalidSchedule,
+		ErrNoRecipients, ErrScheduledMessageInactive, ErrInvalidWorkoutPlanKind,
+		ErrWorkoutPlanResponded:
 		return StatusInvalidRequest
+	case ErrMessagingPaused:
+		return StatusPaymentRequired
 	default:
 		return StatusInternalError
 	}
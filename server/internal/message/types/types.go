@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	"golang.org/x/net/websocket"
@@ -84,11 +85,41 @@ type MarkAsReadRequest struct {
 	MessageIDs []int64 `json:"message_ids" validate:"required,min=1"`
 }
 
+type MessageReaction struct {
+	ReactionID int64     `json:"reaction_id" db:"reaction_id"`
+	MessageID  int64     `json:"message_id" db:"message_id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	Emoji      string    `json:"emoji" db:"emoji"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type ReactionSummary struct {
+	Emoji       string   `json:"emoji"`
+	Count       int      `json:"count"`
+	UserIDs     []string `json:"user_ids"`
+	ReactedByMe bool     `json:"reacted_by_me"`
+}
+
+type PinnedMessage struct {
+	PinID          int64              `json:"pin_id" db:"pin_id"`
+	ConversationID int                `json:"conversation_id" db:"conversation_id"`
+	MessageID      int64              `json:"message_id" db:"message_id"`
+	PinnedBy       string             `json:"pinned_by" db:"pinned_by"`
+	PinnedAt       time.Time          `json:"pinned_at" db:"pinned_at"`
+	Message        MessageWithDetails `json:"message"`
+}
+
+type ToggleReactionRequest struct {
+	Emoji string `json:"emoji" validate:"required,max=64"`
+}
+
 type MessageWithDetails struct {
 	Message
 	SenderName     string              `json:"sender_name"`
 	SenderImage    *string             `json:"sender_image,omitempty"`
 	Attachments    []MessageAttachment `json:"attachments,omitempty"`
+	Reactions      []ReactionSummary   `json:"reactions,omitempty"`
+	WorkoutPlan    *WorkoutPlanCard    `json:"workout_plan,omitempty"`
 	IsRead         bool                `json:"is_read"`
 	ReplyToMessage *MessageWithDetails `json:"reply_to_message,omitempty"`
 }
@@ -135,6 +166,51 @@ type MessageFilters struct {
 	PaginationParams
 }
 
+type MessageSearchParams struct {
+	Query          string     `json:"query" validate:"required,min=2,max=200"`
+	ConversationID *int       `json:"conversation_id,omitempty"`
+	SenderID       *string    `json:"sender_id,omitempty"`
+	StartDate      *time.Time `json:"start_date,omitempty"`
+	EndDate        *time.Time `json:"end_date,omitempty"`
+	HasAttachment  *bool      `json:"has_attachment,omitempty"`
+	Limit          int        `json:"limit"`
+	Cursor         string     `json:"cursor,omitempty"`
+}
+
+// MessageSearchCursor marks the last row of a search page; results are ordered by sent_at, message_id descending.
+type MessageSearchCursor struct {
+	SentAt    time.Time
+	MessageID int64
+}
+
+// Search matches are delimited with private-use characters rather than HTML,
+// so the message text can be escaped before the <mark> tags are added.
+const (
+	SearchMatchStart = "\ue000"
+	SearchMatchStop  = "\ue001"
+)
+
+// MessageSearchResult is one search hit. Snippet is HTML-escaped message text
+// with the matches wrapped in <mark>.
+type MessageSearchResult struct {
+	MessageID      int64     `json:"message_id" db:"message_id"`
+	ConversationID int       `json:"conversation_id" db:"conversation_id"`
+	SenderID       string    `json:"sender_id" db:"sender_id"`
+	SenderName     string    `json:"sender_name" db:"sender_name"`
+	SenderImage    *string   `json:"sender_image,omitempty" db:"sender_image"`
+	MessageText    string    `json:"message_text" db:"message_text"`
+	Snippet        string    `json:"snippet" db:"snippet"`
+	Rank           float32   `json:"rank" db:"rank"`
+	HasAttachments bool      `json:"has_attachments" db:"has_attachments"`
+	SentAt         time.Time `json:"sent_at" db:"sent_at"`
+}
+
+type MessageSearchResponse struct {
+	Results    []MessageSearchResult `json:"results"`
+	NextCursor *string               `json:"next_cursor,omitempty"`
+	HasMore    bool                  `json:"has_more"`
+}
+
 type ConversationFilters struct {
 	UserID          string `json:"user_id"`
 	IncludeArchived bool   `json:"include_archived"`
@@ -158,6 +234,13 @@ type MessagesResponse struct {
 	HasMore  bool                 `json:"has_more"`
 }
 
+type ThreadResponse struct {
+	Root    MessageWithDetails   `json:"root"`
+	Replies []MessageWithDetails `json:"replies"`
+	Total   int                  `json:"total"`
+	HasMore bool                 `json:"has_more"`
+}
+
 type ConversationResponse struct {
 	Conversation ConversationWithDetails `json:"conversation"`
 }
@@ -168,22 +251,194 @@ type ConversationsResponse struct {
 	HasMore       bool                   `json:"has_more"`
 }
 
+type WorkoutPlanKind string
+
+const (
+	WorkoutPlanKindWeeklySchema  WorkoutPlanKind = "weekly_schema"
+	WorkoutPlanKindGeneratedPlan WorkoutPlanKind = "generated_plan"
+	WorkoutPlanKindWorkout       WorkoutPlanKind = "workout"
+)
+
+type WorkoutPlanCardStatus string
+
+const (
+	WorkoutPlanCardPending  WorkoutPlanCardStatus = "pending"
+	WorkoutPlanCardAccepted WorkoutPlanCardStatus = "accepted"
+	WorkoutPlanCardDeclined WorkoutPlanCardStatus = "declined"
+)
+
+// WorkoutPlanCard is the structured payload behind a workout_plan attachment.
+// Summary is the preview rendered by clients and is frozen at send time.
+type WorkoutPlanCard struct {
+	CardID           int64                 `json:"card_id" db:"card_id"`
+	MessageID        int64                 `json:"message_id" db:"message_id"`
+	ConversationID   int                   `json:"conversation_id" db:"conversation_id"`
+	CoachID          string                `json:"coach_id" db:"coach_id"`
+	ClientID         string                `json:"client_id" db:"client_id"`
+	Kind             WorkoutPlanKind       `json:"kind" db:"plan_kind"`
+	SourceID         int                   `json:"source_id" db:"source_id"`
+	Summary          json.RawMessage       `json:"summary" db:"summary"`
+	Status           WorkoutPlanCardStatus `json:"status" db:"status"`
+	AcceptedSchemaID *int                  `json:"accepted_schema_id,omitempty" db:"accepted_schema_id"`
+	RespondedAt      *time.Time            `json:"responded_at,omitempty" db:"responded_at"`
+	CreatedAt        time.Time             `json:"created_at" db:"created_at"`
+}
+
+type WorkoutPlanCardAction string
+
+const (
+	WorkoutPlanCardActionSent     WorkoutPlanCardAction = "sent"
+	WorkoutPlanCardActionAccepted WorkoutPlanCardAction = "accepted"
+	WorkoutPlanCardActionDeclined WorkoutPlanCardAction = "declined"
+)
+
+// WorkoutPlanCardEvent is one entry in a plan card's audit trail. ActorID is
+// empty once the actor's account has been deleted.
+type WorkoutPlanCardEvent struct {
+	EventID   int64                 `json:"event_id" db:"event_id"`
+	CardID    int64                 `json:"card_id" db:"card_id"`
+	ActorID   string                `json:"actor_id" db:"actor_id"`
+	Action    WorkoutPlanCardAction `json:"action" db:"action"`
+	Details   json.RawMessage       `json:"details,omitempty" db:"details"`
+	CreatedAt time.Time             `json:"created_at" db:"created_at"`
+}
+
+type SendWorkoutPlanRequest struct {
+	ConversationID int             `json:"conversation_id" validate:"required"`
+	Kind           WorkoutPlanKind `json:"kind" validate:"required"`
+	SourceID       int             `json:"source_id" validate:"required"`
+	MessageText    string          `json:"message_text,omitempty" validate:"max=5000"`
+}
+
+type ScheduledMessageStatus string
+
+const (
+	ScheduledMessageActive    ScheduledMessageStatus = "active"
+	ScheduledMessageCompleted ScheduledMessageStatus = "completed"
+	ScheduledMessageCancelled ScheduledMessageStatus = "cancelled"
+)
+
+type ScheduledRecipientStatus string
+
+const (
+	ScheduledRecipientPending   ScheduledRecipientStatus = "pending"
+	ScheduledRecipientSent      ScheduledRecipientStatus = "sent"
+	ScheduledRecipientCancelled ScheduledRecipientStatus = "cancelled"
+	ScheduledRecipientSkipped   ScheduledRecipientStatus = "skipped"
+)
+
+type ScheduledMessage struct {
+	ScheduledMessageID int64                       `json:"scheduled_message_id" db:"scheduled_message_id"`
+	CoachID            string                      `json:"coach_id" db:"coach_id"`
+	MessageText        string                      `json:"message_text" db:"message_text"`
+	SendAt             *time.Time                  `json:"send_at,omitempty" db:"send_at"`
+	CronExpression     *string                     `json:"cron_expression,omitempty" db:"cron_expression"`
+	Status             ScheduledMessageStatus      `json:"status" db:"status"`
+	CreatedAt          time.Time                   `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time                   `json:"updated_at" db:"updated_at"`
+	Recipients         []ScheduledMessageRecipient `json:"recipients"`
+}
+
+type ScheduledMessageRecipient struct {
+	RecipientID        int64                    `json:"recipient_id" db:"recipient_id"`
+	ScheduledMessageID int64                    `json:"scheduled_message_id" db:"scheduled_message_id"`
+	ConversationID     int                      `json:"conversation_id" db:"conversation_id"`
+	ClientID           string                   `json:"client_id" db:"client_id"`
+	Timezone           string                   `json:"timezone" db:"timezone"`
+	NextRunAt          *time.Time               `json:"next_run_at,omitempty" db:"next_run_at"`
+	LastSentAt         *time.Time               `json:"last_sent_at,omitempty" db:"last_sent_at"`
+	LastMessageID      *int64                   `json:"last_message_id,omitempty" db:"last_message_id"`
+	Status             ScheduledRecipientStatus `json:"status" db:"status"`
+}
+
+// DueScheduledDelivery is a claimed recipient row joined with the message it should receive.
+type DueScheduledDelivery struct {
+	ScheduledMessageRecipient
+	CoachID        string  `db:"coach_id"`
+	MessageText    string  `db:"message_text"`
+	CronExpression *string `db:"cron_expression"`
+}
+
+type CreateScheduledMessageRequest struct {
+	MessageText     string     `json:"message_text" validate:"required,min=1,max=5000"`
+	SendAt          *time.Time `json:"send_at,omitempty"`
+	CronExpression  *string    `json:"cron_expression,omitempty"`
+	ConversationIDs []int      `json:"conversation_ids,omitempty"`
+	ClientIDs       []string   `json:"client_ids,omitempty"`
+}
+
+type ScheduledMessagesResponse struct {
+	ScheduledMessages []ScheduledMessage `json:"scheduled_messages"`
+	Total             int                `json:"total"`
+	HasMore           bool               `json:"has_more"`
+}
+
+type UpdateScheduledMessageRequest struct {
+	MessageText    *string    `json:"message_text,omitempty" validate:"omitempty,min=1,max=5000"`
+	SendAt         *time.Time `json:"send_at,omitempty"`
+	CronExpression *string    `json:"cron_expression,omitempty"`
+}
+
 type WebSocketMessageType string
 
 const (
-	WSTypeNewMessage     WebSocketMessageType = "new_message"
-	WSTypeMessageEdited  WebSocketMessageType = "message_edited"
-	WSTypeMessageRead    WebSocketMessageType = "message_read"
-	WSTypeMessageDeleted WebSocketMessageType = "message_deleted"
-	WSTypeError          WebSocketMessageType = "error"
+	WSTypeNewMessage         WebSocketMessageType = "new_message"
+	WSTypeMessageEdited      WebSocketMessageType = "message_edited"
+	WSTypeMessageRead        WebSocketMessageType = "message_read"
+	WSTypeMessageDeleted     WebSocketMessageType = "message_deleted"
+	WSTypeTypingStart        WebSocketMessageType = "typing_start"
+	WSTypeTypingStop         WebSocketMessageType = "typing_stop"
+	WSTypePresence           WebSocketMessageType = "presence"
+	WSTypeReactionAdded      WebSocketMessageType = "reaction_added"
+	WSTypeReactionRemoved    WebSocketMessageType = "reaction_removed"
+	WSTypeMessagePinned      WebSocketMessageType = "message_pinned"
+	WSTypeMessageUnpinned    WebSocketMessageType = "message_unpinned"
+	WSTypeWorkoutPlanUpdated WebSocketMessageType = "workout_plan_updated"
+	WSTypeNotification       WebSocketMessageType = "notification"
+	WSTypeError              WebSocketMessageType = "error"
+)
+
+type PresenceStatus string
+
+const (
+	PresenceOnline  PresenceStatus = "online"
+	PresenceAway    PresenceStatus = "away"
+	PresenceOffline PresenceStatus = "offline"
 )
 
+type UserPresence struct {
+	UserID     string         `json:"user_id" db:"user_id"`
+	Status     PresenceStatus `json:"status" db:"status"`
+	LastSeenAt *time.Time     `json:"last_seen_at,omitempty" db:"last_seen_at"`
+}
+
+// WebSocketClientEvent is an inbound frame sent by a client over the socket.
+type WebSocketClientEvent struct {
+	Type           WebSocketMessageType `json:"type"`
+	ConversationID int                  `json:"conversation_id,omitempty"`
+	Status         PresenceStatus       `json:"status,omitempty"`
+}
+
+// Notification is a system event pushed to a single user outside any conversation.
+type Notification struct {
+	Kind  string                 `json:"kind"`
+	Title string                 `json:"title"`
+	Body  string                 `json:"body"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
 type WebSocketMessage struct {
 	Type           WebSocketMessageType `json:"type"`
 	ConversationID int                  `json:"conversation_id"`
 	Message        *MessageWithDetails  `json:"message,omitempty"`
 	MessageID      *int64               `json:"message_id,omitempty"`
 	ReadBy         *string              `json:"read_by,omitempty"`
+	UserID         *string              `json:"user_id,omitempty"`
+	Presence       *UserPresence        `json:"presence,omitempty"`
+	Reaction       *MessageReaction     `json:"reaction,omitempty"`
+	Pin            *PinnedMessage       `json:"pin,omitempty"`
+	WorkoutPlan    *WorkoutPlanCard     `json:"workout_plan,omitempty"`
+	Notification   *Notification        `json:"notification,omitempty"`
 	Error          *string              `json:"error,omitempty"`
 	Timestamp      time.Time            `json:"timestamp"`
 }
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/privacy/services"
+	"github.com/tdmdh/fit-up-server/internal/privacy/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+type PrivacyHandler struct {
+	exports   services.ExportService
+	deletions services.AccountDeletionService
+}
+
+func NewPrivacyHandler(exports services.ExportService, deletions services.AccountDeletionService) *PrivacyHandler {
+	return &PrivacyHandler{
+		exports:   exports,
+		deletions: deletions,
+	}
+}
+
+func respondWithExportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrExportNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, types.ErrExportInProgress), errors.Is(err, types.ErrExportNotReady):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, types.ErrExportExpired):
+		respondWithError(w, http.StatusGone, err.Error())
+	case errors.Is(err, types.ErrInvalidDownloadURL):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		log.Printf("Data export request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Data export request failed")
+	}
+}
+
+// RequestExport handles POST /privacy/exports
+func (h *PrivacyHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	export, err := h.exports.RequestExport(r.Context(), userID)
+	if err != nil {
+		respondWithExportError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, export)
+}
+
+// ListExports handles GET /privacy/exports
+func (h *PrivacyHandler) ListExports(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	exports, err := h.exports.ListExports(r.Context(), userID)
+	if err != nil {
+		respondWithExportError(w, err)
+		return
+	}
+	if exports == nil {
+		exports = []types.DataExport{}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"exports": exports,
+	})
+}
+
+// GetExport handles GET /privacy/exports/{exportID}
+func (h *PrivacyHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	export, err := h.exports.GetExport(r.Context(), userID, chi.URLParam(r, "exportID"))
+	if err != nil {
+		respondWithExportError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, export)
+}
+
+// DownloadExport handles GET /privacy/exports/{exportID}/download?expires=&signature=
+// It is authorised by the signature rather than a bearer token.
+func (h *PrivacyHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	export, file, err := h.exports.OpenDownload(r.Context(), chi.URLParam(r, "exportID"), query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		respondWithExportError(w, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="fitup-export-%s.zip"`, export.ExportID))
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	modified := export.RequestedAt
+	if export.CompletedAt != nil {
+		modified = *export.CompletedAt
+	}
+	http.ServeContent(w, r, "", modified, file)
+}
+
+// ListDeletionReports handles GET /privacy/deletion-reports?user_id=
+func (h *PrivacyHandler) ListDeletionReports(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	reports, err := h.deletions.ListReports(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to list deletion reports: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list deletion reports")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"reports": reports,
+	})
+}
+
+// GetDeletionReport handles GET /privacy/deletion-reports/{reportID}. The
+// response says whether the stored report still matches its signature.
+func (h *PrivacyHandler) GetDeletionReport(w http.ResponseWriter, r *http.Request) {
+	report, verified, err := h.deletions.GetReport(r.Context(), chi.URLParam(r, "reportID"))
+	if err != nil {
+		if errors.Is(err, types.ErrDeletionReportNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("Failed to get deletion report: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to get deletion report")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"report":   report,
+		"verified": verified,
+	})
+}
+
+func respondWithError(w http.ResponseWriter, code int, message string) {
+	respondWithJSON(w, code, map[string]string{"error": message})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+func (h *PrivacyHandler) RegisterRoutes(r chi.Router, authMW *middleware.AuthMiddleware) {
+	r.Route("/privacy/exports", func(r chi.Router) {
+		r.Get("/{exportID}/download", h.DownloadExport)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authMW.RequireJWTAuth())
+
+			r.Post("/", h.RequestExport)
+			r.Get("/", h.ListExports)
+			r.Get("/{exportID}", h.GetExport)
+		})
+	})
+
+	r.Route("/privacy/deletion-reports", func(r chi.Router) {
+		r.Use(authMW.RequireJWTAuth())
+		r.Use(authMW.RequireAdminRole())
+
+		r.Get("/", h.ListDeletionReports)
+		r.Get("/{reportID}", h.GetDeletionReport)
+	})
+}
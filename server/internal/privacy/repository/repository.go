@@ -0,0 +1,444 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tdmdh/fit-up-server/internal/privacy/types"
+)
+
+type PrivacyRepo interface {
+	CreateExport(ctx context.Context, exportID, userID string) (*types.DataExport, error)
+	GetExport(ctx context.Context, exportID string) (*types.DataExport, error)
+	ListExports(ctx context.Context, userID string, limit int) ([]types.DataExport, error)
+
+	ClaimPendingExports(ctx context.Context, limit int, staleAfter time.Duration) ([]types.DataExport, error)
+	CompleteExport(ctx context.Context, exportID, filePath string, fileSize int64, expiresAt time.Time) error
+	FailExport(ctx context.Context, exportID, message string, retry bool) error
+	ExpireExports(ctx context.Context, now time.Time) ([]string, error)
+
+	FetchUserRows(ctx context.Context, query, userID string) (*types.Dataset, error)
+
+	ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]types.DueAccountDeletion, error)
+	ListExportFiles(ctx context.Context, userID string) ([]string, error)
+	ListProgressPhotoFiles(ctx context.Context, userID string) ([]string, error)
+	ExecuteAccountDeletion(ctx context.Context, userID string, statements []types.DeletionStatement, finalize func([]types.DeletionStepResult) (*types.DeletionReport, error)) (*types.DeletionReport, error)
+	GetDeletionReport(ctx context.Context, reportID string) (*types.DeletionReport, error)
+	ListDeletionReports(ctx context.Context, userID string) ([]types.DeletionReport, error)
+}
+
+type Store struct {
+	db *pgxpool.Pool
+}
+
+func NewStore(db *pgxpool.Pool) *Store {
+	return &Store{db: db}
+}
+
+const exportColumns = `
+	export_id, user_id, status, COALESCE(file_path, ''), COALESCE(file_size, 0),
+	COALESCE(error_message, ''), attempts, requested_at, started_at, completed_at, expires_at
+`
+
+func scanExport(row pgx.Row) (*types.DataExport, error) {
+	var export types.DataExport
+	err := row.Scan(
+		&export.ExportID,
+		&export.UserID,
+		&export.Status,
+		&export.FilePath,
+		&export.FileSize,
+		&export.ErrorMessage,
+		&export.Attempts,
+		&export.RequestedAt,
+		&export.StartedAt,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (s *Store) CreateExport(ctx context.Context, exportID, userID string) (*types.DataExport, error) {
+	query := `
+		INSERT INTO data_exports (export_id, user_id)
+		VALUES ($1, $2)
+		RETURNING ` + exportColumns
+
+	export, err := scanExport(s.db.QueryRow(ctx, query, exportID, userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, types.ErrExportInProgress
+		}
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+	return export, nil
+}
+
+func (s *Store) GetExport(ctx context.Context, exportID string) (*types.DataExport, error) {
+	query := `SELECT ` + exportColumns + ` FROM data_exports WHERE export_id = $1`
+
+	export, err := scanExport(s.db.QueryRow(ctx, query, exportID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.ErrExportNotFound
+		}
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return export, nil
+}
+
+func (s *Store) ListExports(ctx context.Context, userID string, limit int) ([]types.DataExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM data_exports
+		WHERE user_id = $1
+		ORDER BY requested_at DESC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []types.DataExport
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, *export)
+	}
+	return exports, rows.Err()
+}
+
+// ClaimPendingExports marks up to limit pending exports as processing and
+// returns them. Exports stuck in processing for longer than staleAfter (for
+// example because the server restarted mid-build) are claimed again.
+func (s *Store) ClaimPendingExports(ctx context.Context, limit int, staleAfter time.Duration) ([]types.DataExport, error) {
+	query := `
+		UPDATE data_exports
+		SET status = 'processing', started_at = NOW(), attempts = attempts + 1
+		WHERE export_id IN (
+			SELECT export_id FROM data_exports
+			WHERE status = 'pending'
+			   OR (status = 'processing' AND started_at < NOW() - make_interval(secs => $2))
+			ORDER BY requested_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportColumns
+
+	rows, err := s.db.Query(ctx, query, limit, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim data exports: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []types.DataExport
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, *export)
+	}
+	return exports, rows.Err()
+}
+
+func (s *Store) CompleteExport(ctx context.Context, exportID, filePath string, fileSize int64, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = 'completed', file_path = $2, file_size = $3, completed_at = NOW(),
+		    expires_at = $4, error_message = NULL
+		WHERE export_id = $1
+	`
+
+	if _, err := s.db.Exec(ctx, query, exportID, filePath, fileSize, expiresAt); err != nil {
+		return fmt.Errorf("failed to complete data export: %w", err)
+	}
+	return nil
+}
+
+// FailExport records a build error. With retry set the export goes back to
+// pending so the worker picks it up again.
+func (s *Store) FailExport(ctx context.Context, exportID, message string, retry bool) error {
+	status := types.ExportFailed
+	if retry {
+		status = types.ExportPending
+	}
+
+	query := `UPDATE data_exports SET status = $2, error_message = $3 WHERE export_id = $1`
+	if _, err := s.db.Exec(ctx, query, exportID, status, message); err != nil {
+		return fmt.Errorf("failed to mark data export as failed: %w", err)
+	}
+	return nil
+}
+
+// ExpireExports marks completed exports past their expiry as expired and
+// returns their file paths so the caller can delete the archives.
+func (s *Store) ExpireExports(ctx context.Context, now time.Time) ([]string, error) {
+	query := `
+		UPDATE data_exports
+		SET status = 'expired'
+		WHERE status = 'completed' AND expires_at <= $1
+		RETURNING COALESCE(file_path, '')
+	`
+
+	rows, err := s.db.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire data exports: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan expired export: %w", err)
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, rows.Err()
+}
+
+// FetchUserRows runs a dataset query with the user ID as $1 and returns the
+// raw column names and values, so new tables can be exported without a
+// dedicated struct.
+func (s *Store) FetchUserRows(ctx context.Context, query, userID string) (*types.Dataset, error) {
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	dataset := &types.Dataset{Columns: make([]string, len(fields))}
+	for i, field := range fields {
+		dataset.Columns[i] = field.Name
+	}
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		dataset.Rows = append(dataset.Rows, values)
+	}
+	return dataset, rows.Err()
+}
+
+// ListDueDeletions returns accounts whose grace period has ended.
+func (s *Store) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]types.DueAccountDeletion, error) {
+	query := `
+		SELECT id, deletion_requested_at, deletion_scheduled_for
+		FROM users
+		WHERE deletion_scheduled_for <= $1 AND deleted_at IS NULL
+		ORDER BY deletion_scheduled_for
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due account deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var due []types.DueAccountDeletion
+	for rows.Next() {
+		var d types.DueAccountDeletion
+		if err := rows.Scan(&d.UserID, &d.RequestedAt, &d.ScheduledFor); err != nil {
+			return nil, fmt.Errorf("failed to scan due account deletion: %w", err)
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+// ListExportFiles returns the archive paths of a user's exports so they can
+// be removed from disk along with the account.
+func (s *Store) ListExportFiles(ctx context.Context, userID string) ([]string, error) {
+	rows, err := s.db.Query(ctx, `SELECT file_path FROM data_exports WHERE user_id = $1 AND file_path IS NOT NULL`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export files: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// ListProgressPhotoFiles returns the image paths of a user's progress photos,
+// oldest first, for exports and account deletion.
+func (s *Store) ListProgressPhotoFiles(ctx context.Context, userID string) ([]string, error) {
+	rows, err := s.db.Query(ctx, `SELECT file_path FROM progress_photos WHERE user_id = $1 ORDER BY taken_on, photo_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list progress photo files: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// ExecuteAccountDeletion runs every statement in one transaction. The user row
+// is locked first and the deletion re-checked, so a sign-in that cancelled it
+// in the meantime wins. finalize builds the report from the step results; it
+// is stored in the same transaction.
+func (s *Store) ExecuteAccountDeletion(ctx context.Context, userID string, statements []types.DeletionStatement, finalize func([]types.DeletionStepResult) (*types.DeletionReport, error)) (*types.DeletionReport, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin account deletion: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked string
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM users
+		WHERE id = $1 AND deleted_at IS NULL AND deletion_scheduled_for <= NOW()
+		FOR UPDATE
+	`, userID).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.ErrDeletionNotDue
+		}
+		return nil, fmt.Errorf("failed to lock user for deletion: %w", err)
+	}
+
+	results := make([]types.DeletionStepResult, 0, len(statements))
+	for _, stmt := range statements {
+		var rowCount int64
+		if stmt.Action == types.DeletionActionRetain {
+			err = tx.QueryRow(ctx, stmt.Query, userID).Scan(&rowCount)
+		} else {
+			var tag pgconn.CommandTag
+			tag, err = tx.Exec(ctx, stmt.Query, userID)
+			rowCount = tag.RowsAffected()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("account deletion step %s/%s failed: %w", stmt.Module, stmt.Table, err)
+		}
+
+		results = append(results, types.DeletionStepResult{
+			Module: stmt.Module,
+			Table:  stmt.Table,
+			Action: stmt.Action,
+			Rows:   rowCount,
+		})
+	}
+
+	report, err := finalize(results)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := json.Marshal(report.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO account_deletion_reports (report_id, user_id, requested_at, scheduled_for, executed_at, steps, digest, signature)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, report.ReportID, report.UserID, report.RequestedAt, report.ScheduledFor, report.ExecutedAt, steps, report.Digest, report.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store deletion report: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit account deletion: %w", err)
+	}
+	return report, nil
+}
+
+const deletionReportColumns = `
+	report_id, user_id, requested_at, scheduled_for, executed_at, steps, digest, signature
+`
+
+func scanDeletionReport(row pgx.Row) (*types.DeletionReport, error) {
+	var report types.DeletionReport
+	var steps []byte
+	err := row.Scan(
+		&report.ReportID,
+		&report.UserID,
+		&report.RequestedAt,
+		&report.ScheduledFor,
+		&report.ExecutedAt,
+		&steps,
+		&report.Digest,
+		&report.Signature,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(steps, &report.Steps); err != nil {
+		return nil, fmt.Errorf("failed to decode deletion report steps: %w", err)
+	}
+	return &report, nil
+}
+
+func (s *Store) GetDeletionReport(ctx context.Context, reportID string) (*types.DeletionReport, error) {
+	query := `SELECT ` + deletionReportColumns + ` FROM account_deletion_reports WHERE report_id = $1`
+
+	report, err := scanDeletionReport(s.db.QueryRow(ctx, query, reportID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.ErrDeletionReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get deletion report: %w", err)
+	}
+	return report, nil
+}
+
+func (s *Store) ListDeletionReports(ctx context.Context, userID string) ([]types.DeletionReport, error) {
+	query := `
+		SELECT ` + deletionReportColumns + `
+		FROM account_deletion_reports
+		WHERE user_id = $1
+		ORDER BY executed_at DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deletion reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []types.DeletionReport{}
+	for rows.Next() {
+		report, err := scanDeletionReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deletion report: %w", err)
+		}
+		reports = append(reports, *report)
+	}
+	return reports, rows.Err()
+}
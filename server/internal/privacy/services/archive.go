@@ -0,0 +1,237 @@
+package services
+
+import (
+	"archive/zip"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tdmdh/fit-up-server/internal/privacy/types"
+)
+
+const exportFormatVersion = 1
+
+// exportFile pairs a dataset definition with the rows fetched for it.
+type exportFile struct {
+	dataset exportDataset
+	data    *types.Dataset
+}
+
+// exportAttachment is a stored file copied into the archive as
+// <module>/<dataset>/<file name>, such as a progress photo.
+type exportAttachment struct {
+	module  string
+	dataset string
+	path    string
+}
+
+// writeArchive writes every dataset as <module>/<name>.json and .csv, the
+// attachments next to them, and a manifest.json describing the files.
+func writeArchive(w io.Writer, manifest *types.ExportManifest, files []exportFile, attachments []exportAttachment) error {
+	zw := zip.NewWriter(w)
+
+	for _, file := range files {
+		data := withoutExcludedColumns(file.data)
+		base := fmt.Sprintf("%s/%s", file.dataset.Module, file.dataset.Name)
+
+		if err := writeZipEntry(zw, base+".json", func(out io.Writer) error {
+			return writeDatasetJSON(out, data)
+		}); err != nil {
+			return err
+		}
+		if err := writeZipEntry(zw, base+".csv", func(out io.Writer) error {
+			return writeDatasetCSV(out, data)
+		}); err != nil {
+			return err
+		}
+
+		for _, format := range []string{"json", "csv"} {
+			manifest.Files = append(manifest.Files, types.ExportManifestFile{
+				Path:        base + "." + format,
+				Module:      file.dataset.Module,
+				Dataset:     file.dataset.Name,
+				Format:      format,
+				Records:     len(data.Rows),
+				Description: file.dataset.Description,
+			})
+		}
+	}
+
+	for _, attachment := range attachments {
+		name := fmt.Sprintf("%s/%s/%s", attachment.module, attachment.dataset, filepath.Base(attachment.path))
+		err := writeZipEntry(zw, name, func(out io.Writer) error {
+			src, err := os.Open(attachment.path)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			_, err = io.Copy(out, src)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		manifest.Files = append(manifest.Files, types.ExportManifestFile{
+			Path:    name,
+			Module:  attachment.module,
+			Dataset: attachment.dataset,
+			Format:  strings.TrimPrefix(filepath.Ext(name), "."),
+			Records: 1,
+		})
+	}
+
+	if err := writeZipEntry(zw, "manifest.json", func(out io.Writer) error {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	}); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeZipEntry(zw *zip.Writer, name string, write func(io.Writer) error) error {
+	out, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	if err := write(out); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func withoutExcludedColumns(data *types.Dataset) *types.Dataset {
+	keep := make([]int, 0, len(data.Columns))
+	for i, column := range data.Columns {
+		if !excludedColumns[column] {
+			keep = append(keep, i)
+		}
+	}
+
+	filtered := &types.Dataset{Columns: make([]string, len(keep))}
+	for j, i := range keep {
+		filtered.Columns[j] = data.Columns[i]
+	}
+	for _, row := range data.Rows {
+		values := make([]interface{}, len(keep))
+		for j, i := range keep {
+			values[j] = normalizeValue(row[i])
+		}
+		filtered.Rows = append(filtered.Rows, values)
+	}
+	return filtered
+}
+
+func writeDatasetJSON(w io.Writer, data *types.Dataset) error {
+	records := make([]map[string]interface{}, 0, len(data.Rows))
+	for _, row := range data.Rows {
+		record := make(map[string]interface{}, len(data.Columns))
+		for i, column := range data.Columns {
+			record[column] = row[i]
+		}
+		records = append(records, record)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+func writeDatasetCSV(w io.Writer, data *types.Dataset) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(data.Columns); err != nil {
+		return err
+	}
+
+	for _, row := range data.Rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = csvCell(value)
+		}
+		if err := writer.Write(cells); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// normalizeValue turns pgx driver values into plain JSON-friendly values:
+// timestamps become RFC 3339 strings, UUIDs and numerics become strings.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalizeValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeValue(item)
+		}
+		return out
+	case string, bool, int16, int32, int64, int, float32, float64:
+		return v
+	case driver.Valuer:
+		inner, err := v.Value()
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return normalizeValue(inner)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+func csvCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int, int16, int32, int64:
+		return fmt.Sprint(v)
+	default:
+		// Arrays and JSON documents stay machine readable inside a single cell
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+// exportDataset is one file pair (JSON and CSV) in the export archive. Every
+// query takes the user ID as $1 and must only return rows the user owns or
+// took part in.
+type exportDataset struct {
+	Module      string
+	Name        string
+	Description string
+	Query       string
+}
+
+// excludedColumns are never written to an export: credentials, token hashes
+// and internal search/fingerprint columns that mean nothing to the user.
+var excludedColumns = map[string]bool{
+	"password":           true,
+	"token_hash":         true,
+	"access_token_jti":   true,
+	"encrypted_secret":   true,
+	"code_hash":          true,
+	"device_fingerprint": true,
+	"search_vector":      true,
+}
+
+// exportDatasets lists everything a user owns across modules. Add new
+// user-owned tables here so they are included in GDPR exports.
+var exportDatasets = []exportDataset{
+	// auth
+	{"auth", "profile", "Account profile", `SELECT * FROM users WHERE id = $1`},
+	{"auth", "sessions", "Signed-in devices and refresh token history", `SELECT * FROM jwt_refresh_tokens WHERE user_id = $1 ORDER BY created_at`},
+	{"auth", "login_events", "Successful sign-ins", `SELECT * FROM login_events WHERE user_id = $1 ORDER BY created_at`},
+	{"auth", "two_factor", "Two-factor authentication settings", `SELECT * FROM user_two_factor WHERE user_id = $1`},
+	{"auth", "linked_accounts", "Sign-in providers linked to the account", `
+		SELECT provider, provider_account_id, type, created_at
+		FROM accounts WHERE user_id = $1 ORDER BY created_at`},
+	{"auth", "achievements", "Unlocked achievements", `
+		SELECT ua.*, a.name AS achievement_name
+		FROM user_achievements ua
+		JOIN achievements a ON a.achievement_id = ua.achievement_id
+		WHERE ua.user_id = $1`},
+	{"auth", "workout_templates", "Saved workout templates", `SELECT * FROM workout_templates WHERE user_id = $1`},
+	{"auth", "workout_template_exercises", "Exercises in saved workout templates", `
+		SELECT te.* FROM workout_template_exercises te
+		JOIN workout_templates t ON t.template_id = te.template_id
+		WHERE t.user_id = $1
+		ORDER BY te.template_id, te.day_of_week, te.position`},
+	{"auth", "coach_applications", "Coach applications", `SELECT * FROM coach_applications WHERE user_id = $1 ORDER BY created_at`},
+
+	// schema
+	{"schema", "workout_profiles", "Fitness profile", `SELECT * FROM workout_profiles WHERE auth_user_id = $1`},
+	{"schema", "weekly_schemas", "Weekly workout schedules", `SELECT * FROM weekly_schemas WHERE user_id = $1 ORDER BY week_start`},
+	{"schema", "workouts", "Workouts in weekly schedules", `
+		SELECT w.* FROM workouts w
+		JOIN weekly_schemas ws ON ws.schema_id = w.schema_id
+		WHERE ws.user_id = $1`},
+	{"schema", "workout_exercises", "Exercises in scheduled workouts", `
+		SELECT we.* FROM workout_exercises we
+		JOIN workouts w ON w.workout_id = we.workout_id
+		JOIN weekly_schemas ws ON ws.schema_id = w.schema_id
+		WHERE ws.user_id = $1`},
+	{"schema", "progress_logs", "Logged exercise progress", `SELECT * FROM progress_logs WHERE user_id = $1 ORDER BY date`},
+	{"schema", "generated_plans", "Generated training plans", `SELECT * FROM generated_plans WHERE user_id = $1`},
+	{"schema", "generated_plan_days", "Days in generated plans", `
+		SELECT d.* FROM generated_plan_days d
+		JOIN generated_plans p ON p.plan_id = d.plan_id
+		WHERE p.user_id = $1`},
+	{"schema", "generated_plan_exercises", "Exercises in generated plans", `
+		SELECT e.* FROM generated_plan_exercises e
+		JOIN generated_plan_days d ON d.plan_day_id = e.plan_day_id
+		JOIN generated_plans p ON p.plan_id = d.plan_id
+		WHERE p.user_id = $1`},
+	{"schema", "plan_performance_data", "Plan performance tracking", `
+		SELECT pd.* FROM plan_performance_data pd
+		JOIN generated_plans p ON p.plan_id = pd.plan_id
+		WHERE p.user_id = $1`},
+	{"schema", "plan_adaptations", "Plan adaptations", `
+		SELECT pa.* FROM plan_adaptations pa
+		JOIN generated_plans p ON p.plan_id = pa.plan_id
+		WHERE p.user_id = $1`},
+	{"schema", "plan_generation_metadata", "Inputs used to generate plans", `
+		SELECT pm.* FROM plan_generation_metadata pm
+		JOIN generated_plans p ON p.plan_id = pm.plan_id
+		WHERE p.user_id = $1`},
+	{"schema", "recovery_metrics", "Recovery metrics", `SELECT * FROM recovery_metrics WHERE user_id = $1 ORDER BY date`},
+	{"schema", "workout_sessions", "Workout sessions", `SELECT * FROM workout_sessions WHERE user_id = $1 ORDER BY start_time`},
+	{"schema", "exercise_performances", "Exercise results in sessions", `
+		SELECT ep.* FROM exercise_performances ep
+		JOIN workout_sessions s ON s.session_id = ep.session_id
+		WHERE s.user_id = $1`},
+	{"schema", "set_performances", "Set results in sessions", `
+		SELECT sp.* FROM set_performances sp
+		JOIN exercise_performances ep ON ep.performance_id = sp.performance_id
+		JOIN workout_sessions s ON s.session_id = ep.session_id
+		WHERE s.user_id = $1`},
+	{"schema", "session_metrics", "Session summaries", `
+		SELECT sm.* FROM session_metrics sm
+		JOIN workout_sessions s ON s.session_id = sm.session_id
+		WHERE s.user_id = $1`},
+	{"schema", "skipped_workouts", "Skipped workouts", `SELECT * FROM skipped_workouts WHERE user_id = $1`},
+	{"schema", "weekly_session_stats", "Weekly training statistics", `SELECT * FROM weekly_session_stats WHERE user_id = $1 ORDER BY week_start`},
+	{"schema", "coach_assignments", "Coach relationships as client or coach", `
+		SELECT ca.* FROM coach_assignments ca
+		LEFT JOIN workout_profiles wp ON wp.workout_profile_id = ca.user_id
+		WHERE wp.auth_user_id = $1 OR ca.coach_id = $1`},
+	{"schema", "coach_alerts", "Compliance alerts raised about you, or for you as a coach", `
+		SELECT * FROM coach_alerts WHERE client_id = $1 OR coach_id = $1 ORDER BY created_at`},
+	{"schema", "coach_alert_rules", "Your coach alert settings", `SELECT * FROM coach_alert_rules WHERE coach_id = $1`},
+	{"schema", "checkin_templates", "Check-in forms you built as a coach", `SELECT * FROM checkin_templates WHERE coach_id = $1`},
+	{"schema", "checkin_questions", "Questions on your check-in forms", `
+		SELECT q.* FROM checkin_questions q
+		JOIN checkin_templates t ON t.template_id = q.template_id
+		WHERE t.coach_id = $1`},
+	{"schema", "checkin_schedules", "Weekly check-in schedules you set or were assigned", `
+		SELECT * FROM checkin_schedules WHERE client_id = $1 OR coach_id = $1`},
+	{"schema", "checkin_submissions", "Weekly check-ins you received or sent", `
+		SELECT * FROM checkin_submissions WHERE client_id = $1 OR coach_id = $1 ORDER BY week_start`},
+	{"schema", "checkin_answers", "Your check-in answers", `
+		SELECT a.* FROM checkin_answers a
+		JOIN checkin_submissions s ON s.submission_id = a.submission_id
+		WHERE s.client_id = $1`},
+	{"schema", "progress_photos", "Progress photo details; file_name points to the image in schema/progress_photos/", `
+		SELECT photo_id, pose, taken_on, notes, regexp_replace(file_path, '^.*[\\/]', '') AS file_name,
+			content_type, width, height, size_bytes, created_at
+		FROM progress_photos WHERE user_id = $1 ORDER BY taken_on`},
+	{"schema", "template_ratings", "Ratings and reviews of public workout templates", `SELECT * FROM template_ratings WHERE user_id = $1 ORDER BY created_at`},
+	{"schema", "template_uses", "Weekly schedules built from workout templates", `SELECT * FROM template_uses WHERE user_id = $1 ORDER BY used_at`},
+	{"schema", "coach_teams", "Coaching teams you own", `SELECT * FROM coach_teams WHERE owner_id = $1`},
+	{"schema", "coach_team_members", "Your coaching team memberships and invitations", `
+		SELECT team_id, role, status, can_edit_schemas, can_message, invited_at, joined_at
+		FROM coach_team_members WHERE coach_id = $1`},
+
+	// food-tracker
+	{"food_tracker", "food_log_entries", "Food diary", `SELECT * FROM food_log_entries WHERE user_id = $1 ORDER BY log_date`},
+	{"food_tracker", "nutrition_goals", "Nutrition goals", `SELECT * FROM nutrition_goals WHERE user_id = $1`},
+	{"food_tracker", "recipes", "Own recipes", `SELECT * FROM user_recipes WHERE user_id = $1`},
+	{"food_tracker", "recipe_ingredients", "Ingredients of own recipes", `
+		SELECT i.* FROM user_recipe_ingredients i
+		JOIN user_recipes r ON r.id = i.recipe_id
+		WHERE r.user_id = $1`},
+	{"food_tracker", "recipe_instructions", "Instructions of own recipes", `
+		SELECT i.* FROM user_recipe_instructions i
+		JOIN user_recipes r ON r.id = i.recipe_id
+		WHERE r.user_id = $1`},
+	{"food_tracker", "recipe_tags", "Tags of own recipes", `
+		SELECT t.* FROM user_recipe_tags t
+		JOIN user_recipes r ON r.id = t.recipe_id
+		WHERE r.user_id = $1`},
+	{"food_tracker", "favorite_recipes", "Favourite recipes", `SELECT * FROM user_favorite_recipes WHERE user_id = $1`},
+
+	// message
+	{"message", "conversations", "Conversations", `SELECT * FROM conversations WHERE coach_id = $1 OR client_id = $1`},
+	{"message", "messages", "Messages in your conversations", `
+		SELECT m.* FROM messages m
+		JOIN conversations c ON c.conversation_id = m.conversation_id
+		WHERE c.coach_id = $1 OR c.client_id = $1
+		ORDER BY m.sent_at`},
+	{"message", "message_attachments", "Attachments in your conversations", `
+		SELECT a.* FROM message_attachments a
+		JOIN messages m ON m.message_id = a.message_id
+		JOIN conversations c ON c.conversation_id = m.conversation_id
+		WHERE c.coach_id = $1 OR c.client_id = $1`},
+	{"message", "message_read_status", "Read receipts", `SELECT * FROM message_read_status WHERE user_id = $1`},
+	{"message", "message_reactions", "Reactions", `SELECT * FROM message_reactions WHERE user_id = $1`},
+	{"message", "pinned_messages", "Pinned messages", `SELECT * FROM pinned_messages WHERE pinned_by = $1`},
+	{"message", "scheduled_messages", "Scheduled messages", `SELECT * FROM scheduled_messages WHERE coach_id = $1`},
+	{"message", "scheduled_message_recipients", "Scheduled message deliveries", `
+		SELECT r.* FROM scheduled_message_recipients r
+		JOIN scheduled_messages sm ON sm.scheduled_message_id = r.scheduled_message_id
+		WHERE r.client_id = $1 OR sm.coach_id = $1`},
+	{"message", "workout_plan_cards", "Workout plan cards", `SELECT * FROM workout_plan_cards WHERE coach_id = $1 OR client_id = $1`},
+	{"message", "workout_plan_card_events", "Workout plan card history", `
+		SELECT * FROM workout_plan_card_events
+		WHERE actor_id = $1
+		   OR card_id IN (SELECT card_id FROM workout_plan_cards WHERE coach_id = $1 OR client_id = $1)`},
+
+	// billing
+	{"billing", "coaching_packages", "Coaching packages you offer", `SELECT * FROM coaching_packages WHERE coach_id = $1`},
+	{"billing", "coaching_subscriptions", "Coaching subscriptions you hold or sold", `
+		SELECT subscription_id, package_id, coach_id, client_id, status, provider, trial_ends_at,
+			current_period_start, current_period_end, cancel_at_period_end, cancelled_at, created_at, updated_at
+		FROM coaching_subscriptions WHERE client_id = $1 OR coach_id = $1 ORDER BY created_at`},
+
+	// mindfulness
+	{"mindfulness", "sessions", "Mindfulness sessions", `SELECT * FROM mindfulness_sessions WHERE user_id = $1 ORDER BY completed_at`},
+	{"mindfulness", "breathing_exercises", "Breathing exercises", `SELECT * FROM breathing_exercises WHERE user_id = $1`},
+	{"mindfulness", "gratitude_entries", "Gratitude journal", `SELECT * FROM gratitude_entries WHERE user_id = $1`},
+	{"mindfulness", "reflection_responses", "Reflection answers", `SELECT * FROM reflection_responses WHERE user_id = $1`},
+	{"mindfulness", "streaks", "Mindfulness streak", `SELECT * FROM mindfulness_streaks WHERE user_id = $1`},
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tdmdh/fit-up-server/internal/privacy/repository"
+	"github.com/tdmdh/fit-up-server/internal/privacy/types"
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+const deletionBatchSize = 10
+
+type AccountDeletionService interface {
+	ProcessDue(ctx context.Context) (int, error)
+	GetReport(ctx context.Context, reportID string) (*types.DeletionReport, bool, error)
+	ListReports(ctx context.Context, userID string) ([]types.DeletionReport, error)
+}
+
+type accountDeletionService struct {
+	repo repository.PrivacyRepo
+	key  []byte
+}
+
+func NewAccountDeletionService(repo repository.PrivacyRepo, cfg *config.Config) AccountDeletionService {
+	return &accountDeletionService{
+		repo: repo,
+		key:  []byte(privacySigningKey(cfg)),
+	}
+}
+
+// privacySigningKey is shared by export download links and deletion reports.
+func privacySigningKey(cfg *config.Config) string {
+	if cfg.DataExport.SigningKey != "" {
+		return cfg.DataExport.SigningKey
+	}
+	return cfg.JWTSecret
+}
+
+// ProcessDue erases every account whose grace period has ended and returns
+// how many were deleted.
+func (s *accountDeletionService) ProcessDue(ctx context.Context) (int, error) {
+	due, err := s.repo.ListDueDeletions(ctx, time.Now(), deletionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, account := range due {
+		if err := s.deleteAccount(ctx, account); err != nil {
+			if errors.Is(err, types.ErrDeletionNotDue) {
+				continue
+			}
+			log.Printf("Account deletion for user %s failed: %v", account.UserID, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *accountDeletionService) deleteAccount(ctx context.Context, account types.DueAccountDeletion) error {
+	exportFiles, err := s.repo.ListExportFiles(ctx, account.UserID)
+	if err != nil {
+		return err
+	}
+	photoFiles, err := s.repo.ListProgressPhotoFiles(ctx, account.UserID)
+	if err != nil {
+		return err
+	}
+
+	report, err := s.repo.ExecuteAccountDeletion(ctx, account.UserID, accountDeletionStatements, func(steps []types.DeletionStepResult) (*types.DeletionReport, error) {
+		report := &types.DeletionReport{
+			ReportID:     uuid.NewString(),
+			UserID:       account.UserID,
+			RequestedAt:  account.RequestedAt,
+			ScheduledFor: account.ScheduledFor,
+			// Postgres keeps microseconds; truncating keeps the digest stable after a round trip
+			ExecutedAt: time.Now().UTC().Truncate(time.Microsecond),
+			Steps:      steps,
+		}
+		if err := s.sign(report); err != nil {
+			return nil, err
+		}
+		return report, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range exportFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove export archive %s of deleted user %s: %v", path, account.UserID, err)
+		}
+	}
+	for _, path := range photoFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove progress photo %s of deleted user %s: %v", path, account.UserID, err)
+		}
+	}
+
+	log.Printf("Deleted account %s (report %s, %d steps)", account.UserID, report.ReportID, len(report.Steps))
+	return nil
+}
+
+// deletionReportBody is the canonical, signed part of a report.
+type deletionReportBody struct {
+	ReportID     string                     `json:"report_id"`
+	UserID       string                     `json:"user_id"`
+	RequestedAt  string                     `json:"requested_at"`
+	ScheduledFor string                     `json:"scheduled_for"`
+	ExecutedAt   string                     `json:"executed_at"`
+	Steps        []types.DeletionStepResult `json:"steps"`
+}
+
+func reportDigest(report *types.DeletionReport) (string, error) {
+	body := deletionReportBody{
+		ReportID:     report.ReportID,
+		UserID:       report.UserID,
+		ScheduledFor: report.ScheduledFor.UTC().Format(time.RFC3339Nano),
+		ExecutedAt:   report.ExecutedAt.UTC().Format(time.RFC3339Nano),
+		Steps:        report.Steps,
+	}
+	if report.RequestedAt != nil {
+		body.RequestedAt = report.RequestedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *accountDeletionService) signature(digest string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(digest))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *accountDeletionService) sign(report *types.DeletionReport) error {
+	digest, err := reportDigest(report)
+	if err != nil {
+		return err
+	}
+	report.Digest = digest
+	report.Signature = s.signature(digest)
+	return nil
+}
+
+// verify recomputes the digest from the stored report and checks the signature.
+func (s *accountDeletionService) verify(report *types.DeletionReport) bool {
+	digest, err := reportDigest(report)
+	if err != nil || digest != report.Digest {
+		return false
+	}
+	return hmac.Equal([]byte(s.signature(digest)), []byte(report.Signature))
+}
+
+// GetReport returns the report and whether its digest and signature verify.
+func (s *accountDeletionService) GetReport(ctx context.Context, reportID string) (*types.DeletionReport, bool, error) {
+	report, err := s.repo.GetDeletionReport(ctx, reportID)
+	if err != nil {
+		return nil, false, err
+	}
+	return report, s.verify(report), nil
+}
+
+func (s *accountDeletionService) ListReports(ctx context.Context, userID string) ([]types.DeletionReport, error) {
+	return s.repo.ListDeletionReports(ctx, userID)
+}
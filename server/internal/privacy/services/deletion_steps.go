@@ -0,0 +1,141 @@
+package services
+
+import "github.com/tdmdh/fit-up-server/internal/privacy/types"
+
+const (
+	actDelete    = types.DeletionActionDelete
+	actAnonymise = types.DeletionActionAnonymise
+	actClose     = types.DeletionActionClose
+	actRetain    = types.DeletionActionRetain
+)
+
+// userEmail resolves the account email before the users row is scrubbed in
+// the final step; tables keyed by email use it.
+const userEmail = `(SELECT email FROM users WHERE id = $1)`
+
+// accountDeletionStatements erase an account across every module, in order.
+// The users row itself is kept as a scrubbed tombstone so that coach
+// conversations keep their history with the sender shown as "Deleted user".
+// Keep this list in sync with exportDatasets when adding user-owned tables.
+var accountDeletionStatements = []types.DeletionStatement{
+	// message
+	{Module: "message", Table: "scheduled_message_recipients", Action: actDelete, Query: `
+		DELETE FROM scheduled_message_recipients
+		WHERE client_id = $1
+		   OR scheduled_message_id IN (SELECT scheduled_message_id FROM scheduled_messages WHERE coach_id = $1)`},
+	{Module: "message", Table: "scheduled_messages", Action: actDelete, Query: `DELETE FROM scheduled_messages WHERE coach_id = $1`},
+	{Module: "message", Table: "message_reactions", Action: actDelete, Query: `DELETE FROM message_reactions WHERE user_id = $1`},
+	{Module: "message", Table: "message_read_status", Action: actDelete, Query: `DELETE FROM message_read_status WHERE user_id = $1`},
+	{Module: "message", Table: "pinned_messages", Action: actDelete, Query: `DELETE FROM pinned_messages WHERE pinned_by = $1`},
+	{Module: "message", Table: "user_presence", Action: actDelete, Query: `DELETE FROM user_presence WHERE user_id = $1`},
+	// Plan cards sent to a deleted client go with them; cards a deleted coach
+	// sent stay with the retained messages they are attached to.
+	{Module: "message", Table: "workout_plan_card_events", Action: actDelete, Query: `
+		DELETE FROM workout_plan_card_events
+		WHERE card_id IN (SELECT card_id FROM workout_plan_cards WHERE client_id = $1)`},
+	{Module: "message", Table: "workout_plan_cards", Action: actDelete, Query: `DELETE FROM workout_plan_cards WHERE client_id = $1`},
+	{Module: "message", Table: "workout_plan_card_events", Action: actAnonymise, Query: `UPDATE workout_plan_card_events SET actor_id = NULL WHERE actor_id = $1`},
+	{Module: "message", Table: "conversations", Action: actClose, Query: `
+		UPDATE conversations SET is_archived = TRUE, updated_at = NOW()
+		WHERE (coach_id = $1 OR client_id = $1) AND is_archived IS NOT TRUE`},
+	{Module: "message", Table: "messages", Action: actRetain, Query: `SELECT COUNT(*) FROM messages WHERE sender_id = $1`},
+	{Module: "message", Table: "workout_plan_cards", Action: actRetain, Query: `SELECT COUNT(*) FROM workout_plan_cards WHERE coach_id = $1`},
+
+	// schema: close coaching relationships before the profile they hang off is removed.
+	// An older inactive row for the same pair would collide with the unique
+	// (coach_id, user_id, is_active) constraint, so those are dropped first.
+	{Module: "schema", Table: "coach_assignments", Action: actDelete, Query: `
+		DELETE FROM coach_assignments old
+		WHERE old.coach_id = $1 AND old.is_active = FALSE
+		  AND EXISTS (
+			SELECT 1 FROM coach_assignments cur
+			WHERE cur.coach_id = old.coach_id AND cur.user_id = old.user_id AND cur.is_active = TRUE
+		  )`},
+	{Module: "schema", Table: "coach_assignments", Action: actClose, Query: `
+		UPDATE coach_assignments SET is_active = FALSE, deactivated_at = NOW()
+		WHERE coach_id = $1 AND is_active = TRUE`},
+	{Module: "schema", Table: "coach_assignments", Action: actDelete, Query: `
+		DELETE FROM coach_assignments
+		WHERE user_id IN (SELECT workout_profile_id FROM workout_profiles WHERE auth_user_id = $1)`},
+	{Module: "schema", Table: "coach_invitations", Action: actClose, Query: `
+		UPDATE coach_invitations SET status = 'cancelled'
+		WHERE status = 'pending' AND (coach_id = $1 OR email = ` + userEmail + `)`},
+	{Module: "schema", Table: "coach_invitations", Action: actAnonymise, Query: `
+		UPDATE coach_invitations
+		SET email = 'deleted-' || id || '@deleted.invalid', first_name = NULL, last_name = NULL, accepted_by_user_id = NULL
+		WHERE coach_id <> $1 AND (accepted_by_user_id = $1 OR email = ` + userEmail + `)`},
+	{Module: "schema", Table: "weekly_schemas", Action: actDelete, Query: `DELETE FROM weekly_schemas WHERE user_id = $1`},
+	{Module: "schema", Table: "progress_logs", Action: actDelete, Query: `DELETE FROM progress_logs WHERE user_id = $1`},
+	{Module: "schema", Table: "generated_plans", Action: actDelete, Query: `DELETE FROM generated_plans WHERE user_id = $1`},
+	{Module: "schema", Table: "recovery_metrics", Action: actDelete, Query: `DELETE FROM recovery_metrics WHERE user_id = $1`},
+	{Module: "schema", Table: "workout_sessions", Action: actDelete, Query: `DELETE FROM workout_sessions WHERE user_id = $1`},
+	{Module: "schema", Table: "skipped_workouts", Action: actDelete, Query: `DELETE FROM skipped_workouts WHERE user_id = $1`},
+	{Module: "schema", Table: "weekly_session_stats", Action: actDelete, Query: `DELETE FROM weekly_session_stats WHERE user_id = $1`},
+	{Module: "schema", Table: "workout_profiles", Action: actDelete, Query: `DELETE FROM workout_profiles WHERE auth_user_id = $1`},
+	{Module: "schema", Table: "user_roles_cache", Action: actDelete, Query: `DELETE FROM user_roles_cache WHERE auth_user_id = $1`},
+	{Module: "schema", Table: "coach_applications", Action: actDelete, Query: `DELETE FROM coach_applications WHERE user_id = $1`},
+	{Module: "schema", Table: "coach_alerts", Action: actDelete, Query: `DELETE FROM coach_alerts WHERE coach_id = $1 OR client_id = $1`},
+	{Module: "schema", Table: "coach_alert_rules", Action: actDelete, Query: `DELETE FROM coach_alert_rules WHERE coach_id = $1`},
+	{Module: "schema", Table: "checkin_submissions", Action: actDelete, Query: `DELETE FROM checkin_submissions WHERE client_id = $1 OR coach_id = $1`},
+	{Module: "schema", Table: "checkin_schedules", Action: actDelete, Query: `DELETE FROM checkin_schedules WHERE client_id = $1 OR coach_id = $1`},
+	{Module: "schema", Table: "checkin_templates", Action: actDelete, Query: `DELETE FROM checkin_templates WHERE coach_id = $1`},
+	{Module: "schema", Table: "progress_photos", Action: actDelete, Query: `DELETE FROM progress_photos WHERE user_id = $1`},
+	{Module: "schema", Table: "template_ratings", Action: actDelete, Query: `DELETE FROM template_ratings WHERE user_id = $1`},
+	{Module: "schema", Table: "template_uses", Action: actDelete, Query: `DELETE FROM template_uses WHERE user_id = $1`},
+	{Module: "schema", Table: "coach_teams", Action: actDelete, Query: `DELETE FROM coach_teams WHERE owner_id = $1`},
+	{Module: "schema", Table: "coach_team_members", Action: actDelete, Query: `DELETE FROM coach_team_members WHERE coach_id = $1`},
+	{Module: "schema", Table: "coach_team_members", Action: actAnonymise, Query: `UPDATE coach_team_members SET invited_by = NULL WHERE invited_by = $1`},
+
+	// food-tracker
+	{Module: "food_tracker", Table: "food_log_entries", Action: actDelete, Query: `DELETE FROM food_log_entries WHERE user_id = $1`},
+	{Module: "food_tracker", Table: "nutrition_goals", Action: actDelete, Query: `DELETE FROM nutrition_goals WHERE user_id = $1`},
+	{Module: "food_tracker", Table: "user_favorite_recipes", Action: actDelete, Query: `DELETE FROM user_favorite_recipes WHERE user_id = $1`},
+	{Module: "food_tracker", Table: "user_recipes", Action: actDelete, Query: `DELETE FROM user_recipes WHERE user_id = $1`},
+
+	// mindfulness
+	{Module: "mindfulness", Table: "mindfulness_sessions", Action: actDelete, Query: `DELETE FROM mindfulness_sessions WHERE user_id = $1`},
+	{Module: "mindfulness", Table: "breathing_exercises", Action: actDelete, Query: `DELETE FROM breathing_exercises WHERE user_id = $1`},
+	{Module: "mindfulness", Table: "gratitude_entries", Action: actDelete, Query: `DELETE FROM gratitude_entries WHERE user_id = $1`},
+	{Module: "mindfulness", Table: "reflection_responses", Action: actDelete, Query: `DELETE FROM reflection_responses WHERE user_id = $1`},
+	{Module: "mindfulness", Table: "mindfulness_streaks", Action: actDelete, Query: `DELETE FROM mindfulness_streaks WHERE user_id = $1`},
+
+	// billing
+	{Module: "billing", Table: "coaching_subscriptions", Action: actDelete, Query: `DELETE FROM coaching_subscriptions WHERE client_id = $1 OR coach_id = $1`},
+	{Module: "billing", Table: "coaching_packages", Action: actDelete, Query: `DELETE FROM coaching_packages WHERE coach_id = $1`},
+
+	// privacy
+	{Module: "privacy", Table: "data_exports", Action: actDelete, Query: `DELETE FROM data_exports WHERE user_id = $1`},
+
+	// auth
+	{Module: "auth", Table: "jwt_refresh_tokens", Action: actDelete, Query: `DELETE FROM jwt_refresh_tokens WHERE user_id = $1`},
+	{Module: "auth", Table: "two_factor_challenges", Action: actDelete, Query: `DELETE FROM two_factor_challenges WHERE user_id = $1`},
+	{Module: "auth", Table: "two_factor_recovery_codes", Action: actDelete, Query: `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`},
+	{Module: "auth", Table: "user_two_factor", Action: actDelete, Query: `DELETE FROM user_two_factor WHERE user_id = $1`},
+	{Module: "auth", Table: "security_alert_tokens", Action: actDelete, Query: `DELETE FROM security_alert_tokens WHERE user_id = $1`},
+	{Module: "auth", Table: "accounts", Action: actDelete, Query: `DELETE FROM accounts WHERE user_id = $1`},
+	{Module: "auth", Table: "login_events", Action: actDelete, Query: `DELETE FROM login_events WHERE user_id = $1`},
+	{Module: "auth", Table: "login_lockouts", Action: actDelete, Query: `DELETE FROM login_lockouts WHERE user_id = $1`},
+	{Module: "auth", Table: "user_achievements", Action: actDelete, Query: `DELETE FROM user_achievements WHERE user_id = $1`},
+	{Module: "auth", Table: "workout_templates", Action: actDelete, Query: `DELETE FROM workout_templates WHERE user_id = $1`},
+	{Module: "auth", Table: "password_reset_tokens", Action: actDelete, Query: `DELETE FROM password_reset_tokens WHERE email = ` + userEmail},
+	{Module: "auth", Table: "verification_tokens", Action: actDelete, Query: `DELETE FROM verification_tokens WHERE user_id = $1 OR email = ` + userEmail},
+	{Module: "auth", Table: "users", Action: actAnonymise, Query: `
+		UPDATE users
+		SET username = 'deleted-' || id,
+			name = 'Deleted user',
+			bio = NULL,
+			email = 'deleted-' || id || '@deleted.invalid',
+			email_verified = NULL,
+			image = NULL,
+			password = NULL,
+			role = 'user',
+			is_two_factor_enabled = FALSE,
+			suspended_at = NULL,
+			suspended_reason = NULL,
+			password_reset_required = FALSE,
+			timezone = 'UTC',
+			deletion_scheduled_for = NULL,
+			deleted_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1`},
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tdmdh/fit-up-server/internal/privacy/repository"
+	"github.com/tdmdh/fit-up-server/internal/privacy/types"
+	"github.com/tdmdh/fit-up-server/shared/config"
+)
+
+const (
+	exportRetention    = 7 * 24 * time.Hour
+	downloadLinkTTL    = 24 * time.Hour
+	exportStaleAfter   = 30 * time.Minute
+	exportMaxAttempts  = 3
+	exportBatchSize    = 5
+	exportListLimit    = 20
+	exportQueryTimeout = 2 * time.Minute
+)
+
+type ExportService interface {
+	RequestExport(ctx context.Context, userID string) (*types.DataExport, error)
+	ListExports(ctx context.Context, userID string) ([]types.DataExport, error)
+	GetExport(ctx context.Context, userID, exportID string) (*types.DataExport, error)
+	OpenDownload(ctx context.Context, exportID, expires, signature string) (*types.DataExport, *os.File, error)
+
+	ProcessPending(ctx context.Context) (int, error)
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type exportService struct {
+	repo    repository.PrivacyRepo
+	dir     string
+	baseURL string
+	signer  *downloadSigner
+}
+
+func NewExportService(repo repository.PrivacyRepo, cfg *config.Config) ExportService {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.DataExport.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://localhost:%s/api/v1", cfg.Port)
+	}
+
+	return &exportService{
+		repo:    repo,
+		dir:     cfg.DataExport.Dir,
+		baseURL: baseURL,
+		signer:  newDownloadSigner(privacySigningKey(cfg)),
+	}
+}
+
+func (s *exportService) RequestExport(ctx context.Context, userID string) (*types.DataExport, error) {
+	return s.repo.CreateExport(ctx, uuid.NewString(), userID)
+}
+
+func (s *exportService) ListExports(ctx context.Context, userID string) ([]types.DataExport, error) {
+	exports, err := s.repo.ListExports(ctx, userID, exportListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range exports {
+		s.attachDownloadURL(&exports[i])
+	}
+	return exports, nil
+}
+
+func (s *exportService) GetExport(ctx context.Context, userID, exportID string) (*types.DataExport, error) {
+	export, err := s.repo.GetExport(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if export.UserID != userID {
+		return nil, types.ErrExportNotFound
+	}
+
+	s.attachDownloadURL(export)
+	return export, nil
+}
+
+// attachDownloadURL adds a freshly signed link to completed exports. The link
+// never outlives the archive itself.
+func (s *exportService) attachDownloadURL(export *types.DataExport) {
+	if export.Status != types.ExportCompleted || export.ExpiresAt == nil {
+		return
+	}
+
+	expiresAt := time.Now().Add(downloadLinkTTL)
+	if export.ExpiresAt.Before(expiresAt) {
+		expiresAt = *export.ExpiresAt
+	}
+	expires := expiresAt.Unix()
+
+	query := url.Values{}
+	query.Set("expires", fmt.Sprint(expires))
+	query.Set("signature", s.signer.sign(export.ExportID, expires))
+
+	export.DownloadURL = fmt.Sprintf("%s/privacy/exports/%s/download?%s", s.baseURL, url.PathEscape(export.ExportID), query.Encode())
+	linkExpiry := time.Unix(expires, 0).UTC()
+	export.DownloadURLExpiresAt = &linkExpiry
+}
+
+// OpenDownload validates a signed link and opens the archive. The caller must
+// close the returned file.
+func (s *exportService) OpenDownload(ctx context.Context, exportID, expires, signature string) (*types.DataExport, *os.File, error) {
+	if !s.signer.verify(exportID, expires, signature) {
+		return nil, nil, types.ErrInvalidDownloadURL
+	}
+
+	export, err := s.repo.GetExport(ctx, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch export.Status {
+	case types.ExportCompleted:
+	case types.ExportExpired:
+		return nil, nil, types.ErrExportExpired
+	default:
+		return nil, nil, types.ErrExportNotReady
+	}
+	if export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt) {
+		return nil, nil, types.ErrExportExpired
+	}
+
+	file, err := os.Open(export.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, types.ErrExportExpired
+		}
+		return nil, nil, fmt.Errorf("failed to open export archive: %w", err)
+	}
+	return export, file, nil
+}
+
+// ProcessPending builds the archives for claimed exports and returns how many
+// were completed.
+func (s *exportService) ProcessPending(ctx context.Context) (int, error) {
+	exports, err := s.repo.ClaimPendingExports(ctx, exportBatchSize, exportStaleAfter)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, export := range exports {
+		if err := s.build(ctx, &export); err != nil {
+			retry := export.Attempts < exportMaxAttempts
+			log.Printf("Data export %s for user %s failed (attempt %d): %v", export.ExportID, export.UserID, export.Attempts, err)
+			if err := s.repo.FailExport(ctx, export.ExportID, "Export could not be generated", retry); err != nil {
+				log.Printf("Failed to record data export failure: %v", err)
+			}
+			continue
+		}
+		completed++
+	}
+	return completed, nil
+}
+
+func (s *exportService) build(ctx context.Context, export *types.DataExport) error {
+	files := make([]exportFile, 0, len(exportDatasets))
+	for _, dataset := range exportDatasets {
+		queryCtx, cancel := context.WithTimeout(ctx, exportQueryTimeout)
+		data, err := s.repo.FetchUserRows(queryCtx, dataset.Query, export.UserID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to collect %s/%s: %w", dataset.Module, dataset.Name, err)
+		}
+		files = append(files, exportFile{dataset: dataset, data: data})
+	}
+
+	photoFiles, err := s.repo.ListProgressPhotoFiles(ctx, export.UserID)
+	if err != nil {
+		return err
+	}
+	attachments := make([]exportAttachment, 0, len(photoFiles))
+	for _, path := range photoFiles {
+		attachments = append(attachments, exportAttachment{module: "schema", dataset: "progress_photos", path: path})
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	finalPath := filepath.Join(s.dir, export.ExportID+".zip")
+	tmp, err := os.CreateTemp(s.dir, export.ExportID+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest := &types.ExportManifest{
+		ExportID:      export.ExportID,
+		UserID:        export.UserID,
+		GeneratedAt:   time.Now().UTC(),
+		FormatVersion: exportFormatVersion,
+	}
+	if err := writeArchive(tmp, manifest, files, attachments); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), finalPath); err != nil {
+		return fmt.Errorf("failed to store export archive: %w", err)
+	}
+
+	expiresAt := time.Now().Add(exportRetention)
+	if err := s.repo.CompleteExport(ctx, export.ExportID, finalPath, info.Size(), expiresAt); err != nil {
+		os.Remove(finalPath)
+		return err
+	}
+
+	log.Printf("Data export %s for user %s completed (%d bytes)", export.ExportID, export.UserID, info.Size())
+	return nil
+}
+
+// PurgeExpired deletes archives past their retention and returns how many
+// files were removed.
+func (s *exportService) PurgeExpired(ctx context.Context) (int, error) {
+	paths, err := s.repo.ExpireExports(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove expired export %s: %v", path, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
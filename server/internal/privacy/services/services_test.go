@@ -0,0 +1,214 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/privacy/types"
+)
+
+func TestDownloadSigner(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := newDownloadSigner("secret")
+	signer.now = func() time.Time { return now }
+
+	expires := now.Add(time.Hour).Unix()
+	sig := signer.sign("export-1", expires)
+	expiresParam := fmt.Sprint(expires)
+
+	if !signer.verify("export-1", expiresParam, sig) {
+		t.Fatal("valid signature rejected")
+	}
+	if signer.verify("export-2", expiresParam, sig) {
+		t.Error("signature accepted for a different export")
+	}
+	if signer.verify("export-1", fmt.Sprint(expires+1), sig) {
+		t.Error("signature accepted with a tampered expiry")
+	}
+	if signer.verify("export-1", "not-a-number", sig) {
+		t.Error("signature accepted with an invalid expiry")
+	}
+	if newDownloadSigner("other").verify("export-1", expiresParam, sig) {
+		t.Error("signature accepted with a different key")
+	}
+
+	signer.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if signer.verify("export-1", expiresParam, sig) {
+		t.Error("expired link accepted")
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	id := [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}
+
+	tests := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{"nil", nil, nil},
+		{"time in UTC", ts, "2024-05-01T10:30:00Z"},
+		{"uuid", id, "12345678-9abc-def0-1234-56789abcdef0"},
+		{"inet", netip.MustParsePrefix("10.0.0.1/32"), "10.0.0.1/32"},
+		{"text bytes", []byte("hello"), "hello"},
+		{"binary bytes", []byte{0xff, 0x00}, "/wA="},
+		{"int", int32(7), int32(7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeValue(tt.in); got != tt.want {
+				t.Errorf("normalizeValue(%v) = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	nested := normalizeValue(map[string]interface{}{"at": ts, "tags": []interface{}{ts}}).(map[string]interface{})
+	if nested["at"] != "2024-05-01T10:30:00Z" || nested["tags"].([]interface{})[0] != "2024-05-01T10:30:00Z" {
+		t.Errorf("nested values not normalised: %#v", nested)
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	files := []exportFile{{
+		dataset: exportDataset{Module: "auth", Name: "profile", Description: "Account profile"},
+		data: &types.Dataset{
+			Columns: []string{"id", "email", "password", "metadata"},
+			Rows: [][]interface{}{
+				{"user-1", "a@example.com", "$2a$hash", map[string]interface{}{"goal": "strength"}},
+			},
+		},
+	}}
+	manifest := &types.ExportManifest{ExportID: "export-1", UserID: "user-1", FormatVersion: exportFormatVersion}
+
+	photoPath := filepath.Join(t.TempDir(), "photo-1.jpg")
+	if err := os.WriteFile(photoPath, []byte("jpeg bytes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	attachments := []exportAttachment{{module: "schema", dataset: "progress_photos", path: photoPath}}
+
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, manifest, files, attachments); err != nil {
+		t.Fatalf("writeArchive: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading archive: %v", err)
+	}
+
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(data)
+	}
+
+	for _, name := range []string{"manifest.json", "auth/profile.json", "auth/profile.csv", "schema/progress_photos/photo-1.jpg"} {
+		if _, ok := contents[name]; !ok {
+			t.Fatalf("archive is missing %s", name)
+		}
+	}
+
+	if strings.Contains(contents["auth/profile.json"], "$2a$hash") || strings.Contains(contents["auth/profile.csv"], "$2a$hash") {
+		t.Error("password hash was exported")
+	}
+
+	records, err := csv.NewReader(strings.NewReader(contents["auth/profile.csv"])).ReadAll()
+	if err != nil {
+		t.Fatalf("parsing csv: %v", err)
+	}
+	if got := strings.Join(records[0], ","); got != "id,email,metadata" {
+		t.Errorf("csv header = %q", got)
+	}
+	if records[1][2] != `{"goal":"strength"}` {
+		t.Errorf("nested value cell = %q", records[1][2])
+	}
+
+	var parsed types.ExportManifest
+	if err := json.Unmarshal([]byte(contents["manifest.json"]), &parsed); err != nil {
+		t.Fatalf("parsing manifest: %v", err)
+	}
+	if len(parsed.Files) != 3 || parsed.Files[0].Records != 1 || parsed.Files[0].Path != "auth/profile.json" {
+		t.Errorf("unexpected manifest files: %+v", parsed.Files)
+	}
+	if contents["schema/progress_photos/photo-1.jpg"] != "jpeg bytes" || parsed.Files[2].Format != "jpg" {
+		t.Errorf("photo attachment not exported as-is: %+v", parsed.Files[2])
+	}
+}
+
+func TestDeletionReportSignature(t *testing.T) {
+	svc := &accountDeletionService{key: []byte("secret")}
+	requested := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	report := &types.DeletionReport{
+		ReportID:     "report-1",
+		UserID:       "user-1",
+		RequestedAt:  &requested,
+		ScheduledFor: requested.Add(30 * 24 * time.Hour),
+		ExecutedAt:   requested.Add(30*24*time.Hour + time.Minute),
+		Steps: []types.DeletionStepResult{
+			{Module: "auth", Table: "users", Action: types.DeletionActionAnonymise, Rows: 1},
+		},
+	}
+	if err := svc.sign(report); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if !svc.verify(report) {
+		t.Fatal("freshly signed report does not verify")
+	}
+
+	// Reading the report back in another time zone must not break verification
+	reloaded := *report
+	reloaded.ExecutedAt = report.ExecutedAt.In(time.FixedZone("CET", 3600))
+	if !svc.verify(&reloaded) {
+		t.Error("report did not verify after a time zone change")
+	}
+
+	tampered := *report
+	tampered.Steps = []types.DeletionStepResult{{Module: "auth", Table: "users", Action: types.DeletionActionAnonymise, Rows: 0}}
+	if svc.verify(&tampered) {
+		t.Error("tampered steps verified")
+	}
+
+	forged := *report
+	other := &accountDeletionService{key: []byte("other")}
+	if other.verify(&forged) {
+		t.Error("report verified with a different key")
+	}
+}
+
+func TestAccountDeletionStatements(t *testing.T) {
+	last := accountDeletionStatements[len(accountDeletionStatements)-1]
+	if last.Table != "users" || last.Action != types.DeletionActionAnonymise {
+		t.Errorf("users must be anonymised last, got %s/%s", last.Table, last.Action)
+	}
+
+	for _, stmt := range accountDeletionStatements {
+		if !strings.Contains(stmt.Query, "$1") {
+			t.Errorf("%s/%s does not filter on the user ID", stmt.Module, stmt.Table)
+		}
+		query := strings.TrimSpace(stmt.Query)
+		if stmt.Action == types.DeletionActionRetain && !strings.HasPrefix(query, "SELECT COUNT(*)") {
+			t.Errorf("%s/%s retain step must be a count query", stmt.Module, stmt.Table)
+		}
+		if stmt.Table == "users" && strings.HasPrefix(query, "DELETE") {
+			t.Error("users must be scrubbed, not deleted, so conversations are kept")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	exportPollInterval   = 30 * time.Second
+	deletionPollInterval = 10 * time.Minute
+)
+
+// ExportWorker builds requested exports in the background and removes
+// archives once they expire.
+type ExportWorker struct {
+	service ExportService
+}
+
+func NewExportWorker(service ExportService) *ExportWorker {
+	return &ExportWorker{service: service}
+}
+
+func (w *ExportWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(exportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if completed, err := w.service.ProcessPending(ctx); err != nil {
+			log.Printf("Data export processing failed: %v", err)
+		} else if completed > 0 {
+			log.Printf("Completed %d data exports", completed)
+		}
+
+		if removed, err := w.service.PurgeExpired(ctx); err != nil {
+			log.Printf("Data export cleanup failed: %v", err)
+		} else if removed > 0 {
+			log.Printf("Removed %d expired data exports", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeletionWorker erases accounts once their deletion grace period has ended.
+type DeletionWorker struct {
+	service AccountDeletionService
+}
+
+func NewDeletionWorker(service AccountDeletionService) *DeletionWorker {
+	return &DeletionWorker{service: service}
+}
+
+func (w *DeletionWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(deletionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := w.service.ProcessDue(ctx); err != nil {
+			log.Printf("Account deletion processing failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d accounts after their grace period", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
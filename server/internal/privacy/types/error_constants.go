@@ -0,0 +1,16 @@
+package types
+
+import "errors"
+
+var (
+	ErrExportNotFound     = errors.New("export not found")
+	ErrExportInProgress   = errors.New("an export is already being prepared")
+	ErrExportNotReady     = errors.New("export is not ready for download")
+	ErrExportExpired      = errors.New("export has expired; request a new one")
+	ErrInvalidDownloadURL = errors.New("download link is invalid or has expired")
+)
+
+var (
+	ErrDeletionNotDue         = errors.New("account deletion was cancelled or is not due yet")
+	ErrDeletionReportNotFound = errors.New("deletion report not found")
+)
@@ -0,0 +1,101 @@
+package types
+
+import "time"
+
+type ExportStatus string
+
+const (
+	ExportPending    ExportStatus = "pending"
+	ExportProcessing ExportStatus = "processing"
+	ExportCompleted  ExportStatus = "completed"
+	ExportFailed     ExportStatus = "failed"
+	ExportExpired    ExportStatus = "expired"
+)
+
+type DataExport struct {
+	ExportID     string       `json:"export_id" db:"export_id"`
+	UserID       string       `json:"user_id" db:"user_id"`
+	Status       ExportStatus `json:"status" db:"status"`
+	FilePath     string       `json:"-" db:"file_path"`
+	FileSize     int64        `json:"file_size,omitempty" db:"file_size"`
+	ErrorMessage string       `json:"error_message,omitempty" db:"error_message"`
+	Attempts     int          `json:"-" db:"attempts"`
+	RequestedAt  time.Time    `json:"requested_at" db:"requested_at"`
+	StartedAt    *time.Time   `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt  *time.Time   `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt    *time.Time   `json:"expires_at,omitempty" db:"expires_at"`
+
+	// DownloadURL is a signed link, only set on completed exports in API responses.
+	DownloadURL          string     `json:"download_url,omitempty" db:"-"`
+	DownloadURLExpiresAt *time.Time `json:"download_url_expires_at,omitempty" db:"-"`
+}
+
+// Dataset is one table's worth of a user's data as fetched from the database.
+type Dataset struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// ExportManifest is written to manifest.json at the root of every export.
+type ExportManifest struct {
+	ExportID      string               `json:"export_id"`
+	UserID        string               `json:"user_id"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+	FormatVersion int                  `json:"format_version"`
+	Files         []ExportManifestFile `json:"files"`
+}
+
+type ExportManifestFile struct {
+	Path        string `json:"path"`
+	Module      string `json:"module"`
+	Dataset     string `json:"dataset"`
+	Format      string `json:"format"`
+	Records     int    `json:"records"`
+	Description string `json:"description"`
+}
+
+// DueAccountDeletion is an account whose deletion grace period has ended.
+type DueAccountDeletion struct {
+	UserID       string
+	RequestedAt  *time.Time
+	ScheduledFor time.Time
+}
+
+const (
+	DeletionActionDelete    = "delete"
+	DeletionActionAnonymise = "anonymise"
+	DeletionActionClose     = "close"
+	// DeletionActionRetain counts rows that are deliberately kept; its query is a SELECT COUNT(*)
+	DeletionActionRetain = "retain"
+)
+
+// DeletionStatement is one SQL step of an account deletion. The query takes
+// the user ID as $1.
+type DeletionStatement struct {
+	Module string
+	Table  string
+	Action string
+	Query  string
+}
+
+// DeletionStepResult records how many rows one step touched.
+type DeletionStepResult struct {
+	Module string `json:"module"`
+	Table  string `json:"table"`
+	Action string `json:"action"`
+	Rows   int64  `json:"rows"`
+}
+
+// DeletionReport is the signed record kept after an account is erased.
+// Digest is the SHA-256 of the canonical report body; Signature is an HMAC of
+// the digest so the report can be verified later.
+type DeletionReport struct {
+	ReportID     string               `json:"report_id"`
+	UserID       string               `json:"user_id"`
+	RequestedAt  *time.Time           `json:"requested_at,omitempty"`
+	ScheduledFor time.Time            `json:"scheduled_for"`
+	ExecutedAt   time.Time            `json:"executed_at"`
+	Steps        []DeletionStepResult `json:"steps"`
+	Digest       string               `json:"digest"`
+	Signature    string               `json:"signature"`
+}
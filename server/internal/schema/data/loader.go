@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/tdmdh/fit-up-server/internal/schema/types"
@@ -312,6 +313,10 @@ func (f *FitUpData) GetWorkoutTemplateByGoalAndLevel(goal, level string) []Worko
 	return suitable
 }
 
+var scheduleDayNumbers = map[string]int{
+	"monday": 1, "tuesday": 2, "wednesday": 3, "thursday": 4, "friday": 5, "saturday": 6, "sunday": 7,
+}
+
 func (f *FitUpData) ConvertToGoTypes() ([]types.Exercise, []types.WorkoutTemplate, error) {
 	var exercises []types.Exercise
 	for _, ex := range f.Exercises {
@@ -335,18 +340,45 @@ func (f *FitUpData) ConvertToGoTypes() ([]types.Exercise, []types.WorkoutTemplat
 		exercises = append(exercises, exercise)
 	}
 
+	exerciseNames := make(map[int]string, len(f.Exercises))
+	for _, ex := range f.Exercises {
+		exerciseNames[ex.ID] = ex.Name
+	}
+
 	var templates []types.WorkoutTemplate
 	for _, tmpl := range f.WorkoutTemplates {
-		suitableGoalsJSON, _ := json.Marshal(tmpl.SuitableGoals)
-
+		description := tmpl.Description
 		template := types.WorkoutTemplate{
-			Name:          tmpl.Name,
-			Description:   tmpl.Description,
-			MinLevel:      types.FitnessLevel(tmpl.SuitableLevels[0]),
-			MaxLevel:      types.FitnessLevel(tmpl.SuitableLevels[len(tmpl.SuitableLevels)-1]),
-			SuitableGoals: string(suitableGoalsJSON),
-			DaysPerWeek:   tmpl.DaysPerWeek,
+			Name:        tmpl.Name,
+			Description: &description,
+			Visibility:  types.TemplateVisibilityPublic,
+			Source:      types.TemplateSourceCustom,
+			Days:        []types.TemplateDay{},
+		}
+
+		// Structure keys follow the schedule: day_1 is the first scheduled day
+		for i, dayName := range tmpl.Schedule {
+			structure, ok := tmpl.Structure[fmt.Sprintf("day_%d", i+1)]
+			dayOfWeek := scheduleDayNumbers[strings.ToLower(dayName)]
+			if !ok || dayOfWeek == 0 {
+				continue
+			}
+
+			day := types.TemplateDay{DayOfWeek: dayOfWeek, Focus: structure.Focus, Exercises: []types.TemplateExercise{}}
+			for _, spec := range structure.Exercises {
+				exerciseID := spec.ExerciseID
+				day.Exercises = append(day.Exercises, types.TemplateExercise{
+					ExerciseID:   &exerciseID,
+					ExerciseName: exerciseNames[spec.ExerciseID],
+					Sets:         spec.Sets,
+					Reps:         spec.Reps,
+					RestSeconds:  spec.Rest,
+				})
+			}
+			template.Days = append(template.Days, day)
+			template.ExerciseCount += len(day.Exercises)
 		}
+		template.DaysPerWeek = len(template.Days)
 		templates = append(templates, template)
 	}
 
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	authMiddleware "github.com/tdmdh/fit-up-server/internal/auth/middleware"
+	authTypes "github.com/tdmdh/fit-up-server/internal/auth/types"
+	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+type AdminHandler struct {
+	service service.AdminService
+}
+
+func NewAdminHandler(service service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+	}
+}
+
+type changeRoleRequest struct {
+	Role types.UserRole `json:"role"`
+}
+
+type suspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+func adminActorFromRequest(r *http.Request) (service.AdminActor, bool) {
+	adminID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || adminID == "" {
+		return service.AdminActor{}, false
+	}
+	return service.AdminActor{
+		AdminID:   adminID,
+		IPAddress: authMiddleware.GetClientIP(r),
+	}, true
+}
+
+// respondAdminError maps admin service errors to status codes.
+func respondAdminError(w http.ResponseWriter, err error) {
+	switch err {
+	case types.ErrUserNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case types.ErrInvalidRole:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case types.ErrAdminSelfAction:
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case types.ErrUserAlreadySuspended, types.ErrUserNotSuspended:
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Admin action failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Admin action failed")
+	}
+}
+
+// ListUsers handles GET /admin/users?search=&role=&status=&page=&limit=
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pagination := extractPaginationParams(r)
+
+	status := authTypes.UserStatus(query.Get("status"))
+	if status != "" && status != authTypes.UserStatusActive && status != authTypes.UserStatusSuspended {
+		respondWithError(w, http.StatusBadRequest, "Status must be active or suspended")
+		return
+	}
+
+	role := authTypes.UserRole(query.Get("role"))
+	if role != "" && role != authTypes.RoleUser && role != authTypes.RoleCoach && role != authTypes.RoleAdmin {
+		respondWithError(w, http.StatusBadRequest, types.ErrInvalidRole.Error())
+		return
+	}
+
+	result, err := h.service.ListUsers(r.Context(), authTypes.AdminUserFilter{
+		Search:   query.Get("search"),
+		Role:     role,
+		Status:   status,
+		Page:     pagination.Page,
+		PageSize: pagination.Limit,
+	})
+	if err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	detail, err := h.service.GetUserDetail(r.Context(), chi.URLParam(r, "userID"))
+	if err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, detail)
+}
+
+func (h *AdminHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActorFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req changeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := h.service.ChangeRole(r.Context(), actor, chi.URLParam(r, "userID"), req.Role)
+	if err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Role updated",
+		"user":    user,
+	})
+}
+
+func (h *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActorFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req suspendUserRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	if err := h.service.SuspendUser(r.Context(), actor, chi.URLParam(r, "userID"), req.Reason); err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "User suspended"})
+}
+
+func (h *AdminHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActorFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.service.ReactivateUser(r.Context(), actor, chi.URLParam(r, "userID")); err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "User reactivated"})
+}
+
+func (h *AdminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActorFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.service.ForcePasswordReset(r.Context(), actor, chi.URLParam(r, "userID")); err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Password reset required; the user has been signed out"})
+}
+
+func (h *AdminHandler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActorFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.service.RevokeSessions(r.Context(), actor, chi.URLParam(r, "userID")); err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "All sessions revoked"})
+}
+
+// GetAuditLog handles GET /admin/audit-log?user_id= and GET /admin/users/{userID}/audit-log
+func (h *AdminHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	targetUserID := chi.URLParam(r, "userID")
+	if targetUserID == "" {
+		targetUserID = r.URL.Query().Get("user_id")
+	}
+
+	pagination := extractPaginationParams(r)
+	entries, err := h.service.ListAuditLog(r.Context(), targetUserID, pagination)
+	if err != nil {
+		respondAdminError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"page":    pagination.Page,
+		"limit":   pagination.Limit,
+		"count":   len(entries),
+	})
+}
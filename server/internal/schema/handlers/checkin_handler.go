@@ -0,0 +1,480 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+type CheckInHandler struct {
+	service service.CheckInService
+}
+
+func NewCheckInHandler(service service.CheckInService) *CheckInHandler {
+	return &CheckInHandler{
+		service: service,
+	}
+}
+
+// respondCheckInError maps check-in errors to status codes. Question and
+// answer errors are wrapped with details, so they are matched with errors.Is.
+func respondCheckInError(w http.ResponseWriter, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case errors.Is(err, types.ErrInvalidCheckInQuestion), errors.Is(err, types.ErrInvalidCheckInAnswer),
+		errors.Is(err, types.ErrInvalidCheckInStatus):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrClientAccessDenied):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrCheckInTemplateNotFound), errors.Is(err, types.ErrCheckInScheduleNotFound),
+		errors.Is(err, types.ErrCheckInNotFound), errors.Is(err, types.ErrCheckInQuestionNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, types.ErrCheckInAlreadyReviewed), errors.Is(err, types.ErrCheckInNotSubmitted):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Check-in request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Check-in request failed")
+	}
+}
+
+func parseCheckInID(r *http.Request, param string) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+}
+
+// parseCheckInStatus reads the status filter; an empty value returns every status.
+func parseCheckInStatus(r *http.Request) (types.CheckInStatus, error) {
+	switch status := types.CheckInStatus(r.URL.Query().Get("status")); status {
+	case "", types.CheckInPending, types.CheckInSubmitted, types.CheckInReviewed:
+		return status, nil
+	default:
+		return "", types.ErrInvalidCheckInStatus
+	}
+}
+
+// CreateTemplate handles POST /coach/check-in-templates
+func (h *CheckInHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	var req types.CheckInTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.CreateTemplate(r.Context(), coachID, &req)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, template)
+}
+
+// ListTemplates handles GET /coach/check-in-templates
+func (h *CheckInHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	templates, err := h.service.ListTemplates(r.Context(), coachID)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"templates": templates,
+	})
+}
+
+// GetTemplate handles GET /coach/check-in-templates/{templateID}
+func (h *CheckInHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	templateID, err := parseCheckInID(r, "templateID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	template, err := h.service.GetTemplate(r.Context(), coachID, templateID)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, template)
+}
+
+// UpdateTemplate handles PUT /coach/check-in-templates/{templateID}
+func (h *CheckInHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	templateID, err := parseCheckInID(r, "templateID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req types.CheckInTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(r.Context(), coachID, templateID, &req)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, template)
+}
+
+// ArchiveTemplate handles DELETE /coach/check-in-templates/{templateID}
+func (h *CheckInHandler) ArchiveTemplate(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	templateID, err := parseCheckInID(r, "templateID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	if err := h.service.ArchiveTemplate(r.Context(), coachID, templateID); err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ScheduleForClient handles POST /coach/clients/{userID}/check-in-schedules
+func (h *CheckInHandler) ScheduleForClient(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req types.ScheduleCheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schedule, err := h.service.ScheduleForClient(r.Context(), coachID, userID, &req)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, schedule)
+}
+
+// ListClientSchedules handles GET /coach/clients/{userID}/check-in-schedules
+func (h *CheckInHandler) ListClientSchedules(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	schedules, err := h.service.ListClientSchedules(r.Context(), coachID, userID)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"schedules": schedules,
+	})
+}
+
+// CancelSchedule handles DELETE /coach/check-in-schedules/{scheduleID}
+func (h *CheckInHandler) CancelSchedule(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	scheduleID, err := parseCheckInID(r, "scheduleID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid schedule ID")
+		return
+	}
+
+	if err := h.service.CancelSchedule(r.Context(), coachID, scheduleID); err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCoachCheckIns handles GET /coach/check-ins?status=&page=&limit=
+func (h *CheckInHandler) ListCoachCheckIns(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	status, err := parseCheckInStatus(r)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	pagination := extractPaginationParams(r)
+	checkIns, err := h.service.ListCoachCheckIns(r.Context(), coachID, status, pagination)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"check_ins": checkIns,
+		"page":      pagination.Page,
+		"limit":     pagination.Limit,
+	})
+}
+
+// ListClientCheckIns handles GET /coach/clients/{userID}/check-ins?status=&page=&limit=
+func (h *CheckInHandler) ListClientCheckIns(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	status, err := parseCheckInStatus(r)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	pagination := extractPaginationParams(r)
+	checkIns, err := h.service.ListClientCheckInsForCoach(r.Context(), coachID, userID, status, pagination)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"check_ins": checkIns,
+		"page":      pagination.Page,
+		"limit":     pagination.Limit,
+	})
+}
+
+// GetCoachCheckIn handles GET /coach/check-ins/{submissionID}
+func (h *CheckInHandler) GetCoachCheckIn(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	submissionID, err := parseCheckInID(r, "submissionID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid check-in ID")
+		return
+	}
+
+	detail, err := h.service.GetCheckInForCoach(r.Context(), coachID, submissionID)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, detail)
+}
+
+// ReviewCheckIn handles POST /coach/check-ins/{submissionID}/review
+func (h *CheckInHandler) ReviewCheckIn(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	submissionID, err := parseCheckInID(r, "submissionID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid check-in ID")
+		return
+	}
+
+	var req types.ReviewCheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	submission, err := h.service.ReviewCheckIn(r.Context(), coachID, submissionID, &req)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, submission)
+}
+
+// GetQuestionHistory handles GET /coach/clients/{userID}/check-in-questions/{questionID}/history
+func (h *CheckInHandler) GetQuestionHistory(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	questionID, err := parseCheckInID(r, "questionID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	history, err := h.service.GetQuestionHistory(r.Context(), coachID, userID, questionID)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, history)
+}
+
+// ListMyCheckIns handles GET /check-ins?status=&page=&limit=
+func (h *CheckInHandler) ListMyCheckIns(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := parseCheckInStatus(r)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	pagination := extractPaginationParams(r)
+	checkIns, err := h.service.ListMyCheckIns(r.Context(), userID, status, pagination)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"check_ins": checkIns,
+		"page":      pagination.Page,
+		"limit":     pagination.Limit,
+	})
+}
+
+// GetMyCheckIn handles GET /check-ins/{submissionID}
+func (h *CheckInHandler) GetMyCheckIn(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	submissionID, err := parseCheckInID(r, "submissionID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid check-in ID")
+		return
+	}
+
+	detail, err := h.service.GetMyCheckIn(r.Context(), userID, submissionID)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, detail)
+}
+
+// SubmitCheckIn handles POST /check-ins/{submissionID}/submit
+func (h *CheckInHandler) SubmitCheckIn(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	submissionID, err := parseCheckInID(r, "submissionID")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid check-in ID")
+		return
+	}
+
+	var req types.SubmitCheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	detail, err := h.service.SubmitCheckIn(r.Context(), userID, submissionID, &req)
+	if err != nil {
+		respondCheckInError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, detail)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// GetClientTimeline handles
+// GET /coach/clients/{userID}/timeline?types=workout,message&from=&to=&cursor=&limit=
+//
+// from and to accept RFC 3339 timestamps or YYYY-MM-DD dates; a date-only to
+// includes the whole day.
+func (h *CoachHandler) GetClientTimeline(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	params := r.URL.Query()
+	query := types.TimelineQuery{
+		Cursor: params.Get("cursor"),
+	}
+
+	for _, t := range strings.Split(params.Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			query.Types = append(query.Types, types.TimelineEventType(t))
+		}
+	}
+
+	if query.From, err = parseTimelineBound(params.Get("from"), false); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid from date")
+		return
+	}
+	if query.To, err = parseTimelineBound(params.Get("to"), true); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid to date")
+		return
+	}
+
+	if limitStr := params.Get("limit"); limitStr != "" {
+		if query.Limit, err = strconv.Atoi(limitStr); err != nil || query.Limit < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+	}
+
+	page, err := h.service.GetClientTimeline(r.Context(), coachID, userID, query)
+	if err != nil {
+		switch err {
+		case types.ErrClientAccessDenied:
+			respondWithError(w, http.StatusForbidden, err.Error())
+		case types.ErrInvalidTimelineType, types.ErrInvalidTimelineCursor, types.ErrInvalidDateRange:
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("Failed to load timeline for client %d: %v", userID, err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to load client timeline")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// parseTimelineBound parses a from/to parameter. An end bound given as a plain
+// date moves to the following midnight since the range end is exclusive.
+func parseTimelineBound(value string, isEnd bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return &ts, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if isEnd {
+		day = day.AddDate(0, 0, 1)
+	}
+	return &day, nil
+}
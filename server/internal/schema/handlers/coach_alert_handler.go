@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+type CoachAlertHandler struct {
+	service service.CoachAlertService
+}
+
+func NewCoachAlertHandler(service service.CoachAlertService) *CoachAlertHandler {
+	return &CoachAlertHandler{
+		service: service,
+	}
+}
+
+func respondCoachAlertError(w http.ResponseWriter, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch err {
+	case types.ErrCoachAlertNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case types.ErrUnknownAlertRule, types.ErrInvalidAlertThreshold, types.ErrInvalidCoachAlertStatus:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Coach alert request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Coach alert request failed")
+	}
+}
+
+// GetAlerts handles GET /coach/alerts?status=open|acknowledged|all&page=&limit=
+func (h *CoachAlertHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	var status types.CoachAlertStatus
+	switch r.URL.Query().Get("status") {
+	case "", string(types.CoachAlertOpen):
+		status = types.CoachAlertOpen
+	case string(types.CoachAlertAcknowledged):
+		status = types.CoachAlertAcknowledged
+	case "all":
+		status = ""
+	default:
+		respondCoachAlertError(w, types.ErrInvalidCoachAlertStatus)
+		return
+	}
+
+	pagination := extractPaginationParams(r)
+	inbox, err := h.service.GetInbox(r.Context(), coachID, status, pagination)
+	if err != nil {
+		respondCoachAlertError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"alerts":     inbox.Alerts,
+		"open_count": inbox.OpenCount,
+		"page":       pagination.Page,
+		"limit":      pagination.Limit,
+	})
+}
+
+// AcknowledgeAlert handles POST /coach/alerts/{alertID}/acknowledge
+func (h *CoachAlertHandler) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	alertID, err := strconv.ParseInt(chi.URLParam(r, "alertID"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid alert ID")
+		return
+	}
+
+	alert, err := h.service.AcknowledgeAlert(r.Context(), coachID, alertID)
+	if err != nil {
+		respondCoachAlertError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, alert)
+}
+
+// AcknowledgeAllAlerts handles POST /coach/alerts/acknowledge-all
+func (h *CoachAlertHandler) AcknowledgeAllAlerts(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	acknowledged, err := h.service.AcknowledgeAll(r.Context(), coachID)
+	if err != nil {
+		respondCoachAlertError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"acknowledged": acknowledged,
+	})
+}
+
+// ScanClients handles POST /coach/alerts/scan, running the rules now instead of
+// waiting for the next scheduled scan.
+func (h *CoachAlertHandler) ScanClients(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	created, err := h.service.ScanCoach(r.Context(), coachID)
+	if err != nil {
+		respondCoachAlertError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"new_alerts": created,
+	})
+}
+
+// GetRules handles GET /coach/alert-rules
+func (h *CoachAlertHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	rules, err := h.service.GetRules(r.Context(), coachID)
+	if err != nil {
+		respondCoachAlertError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": rules,
+	})
+}
+
+// UpdateRules handles PUT /coach/alert-rules
+func (h *CoachAlertHandler) UpdateRules(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	var req types.UpdateCoachAlertRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rules, err := h.service.UpdateRules(r.Context(), coachID, &req)
+	if err != nil {
+		respondCoachAlertError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": rules,
+	})
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+type CoachApplicationHandler struct {
+	service service.CoachApplicationService
+}
+
+func NewCoachApplicationHandler(service service.CoachApplicationService) *CoachApplicationHandler {
+	return &CoachApplicationHandler{
+		service: service,
+	}
+}
+
+// respondCoachApplicationError maps coach application errors to status codes.
+func respondCoachApplicationError(w http.ResponseWriter, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch err {
+	case types.ErrCoachApplicationNotFound, types.ErrUserNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case types.ErrCoachApplicationPending, types.ErrCoachApplicationNotPending, types.ErrAlreadyCoach:
+		respondWithError(w, http.StatusConflict, err.Error())
+	case types.ErrRejectionReasonRequired:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Coach application request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Coach application request failed")
+	}
+}
+
+func parseApplicationID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(r, "applicationID"), 10, 64)
+}
+
+// SubmitApplication handles POST /coach-applications
+func (h *CoachApplicationHandler) SubmitApplication(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.SubmitCoachApplicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	app, err := h.service.SubmitApplication(r.Context(), userID, &req)
+	if err != nil {
+		respondCoachApplicationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, app)
+}
+
+// ListMyApplications handles GET /coach-applications/me
+func (h *CoachApplicationHandler) ListMyApplications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	apps, err := h.service.ListMyApplications(r.Context(), userID)
+	if err != nil {
+		respondCoachApplicationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"applications": apps,
+	})
+}
+
+// WithdrawApplication handles DELETE /coach-applications/{applicationID}
+func (h *CoachApplicationHandler) WithdrawApplication(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	applicationID, err := parseApplicationID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid application ID")
+		return
+	}
+
+	app, err := h.service.WithdrawApplication(r.Context(), userID, applicationID)
+	if err != nil {
+		respondCoachApplicationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, app)
+}
+
+// ListApplications handles GET /admin/coach-applications?status=&page=&limit=
+func (h *CoachApplicationHandler) ListApplications(w http.ResponseWriter, r *http.Request) {
+	pagination := extractPaginationParams(r)
+
+	status := types.CoachApplicationStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", types.CoachApplicationPending, types.CoachApplicationApproved,
+		types.CoachApplicationRejected, types.CoachApplicationWithdrawn:
+	default:
+		respondWithError(w, http.StatusBadRequest, "Status must be pending, approved, rejected or withdrawn")
+		return
+	}
+
+	apps, err := h.service.ListApplications(r.Context(), status, pagination)
+	if err != nil {
+		respondCoachApplicationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"applications": apps,
+		"page":         pagination.Page,
+		"limit":        pagination.Limit,
+	})
+}
+
+// GetApplication handles GET /admin/coach-applications/{applicationID}
+func (h *CoachApplicationHandler) GetApplication(w http.ResponseWriter, r *http.Request) {
+	applicationID, err := parseApplicationID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid application ID")
+		return
+	}
+
+	app, err := h.service.GetApplication(r.Context(), applicationID)
+	if err != nil {
+		respondCoachApplicationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, app)
+}
+
+// ApproveApplication handles POST /admin/coach-applications/{applicationID}/approve
+func (h *CoachApplicationHandler) ApproveApplication(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, true)
+}
+
+// RejectApplication handles POST /admin/coach-applications/{applicationID}/reject
+func (h *CoachApplicationHandler) RejectApplication(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, false)
+}
+
+func (h *CoachApplicationHandler) review(w http.ResponseWriter, r *http.Request, approve bool) {
+	actor, ok := adminActorFromRequest(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	applicationID, err := parseApplicationID(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid application ID")
+		return
+	}
+
+	var req types.ReviewCoachApplicationRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var app *types.CoachApplication
+	if approve {
+		app, err = h.service.ApproveApplication(r.Context(), actor, applicationID, req.Reason)
+	} else {
+		app, err = h.service.RejectApplication(r.Context(), actor, applicationID, req.Reason)
+	}
+	if err != nil {
+		respondCoachApplicationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, app)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -30,6 +31,20 @@ func getCoachIDFromContext(r *http.Request) (string, bool) {
 	return authID, true
 }
 
+// respondNewSchemaError reports a lapsed coaching subscription as 402 so the
+// app can prompt for renewal and missing client or team permissions as 403;
+// other failures keep their existing 500.
+func respondNewSchemaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, types.ErrCoachingSubscriptionLapsed):
+		respondWithError(w, http.StatusPaymentRequired, err.Error())
+	case errors.Is(err, types.ErrClientAccessDenied), errors.Is(err, types.ErrTeamPermissionDenied):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
 func (h *CoachHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
 	coachID, ok := getCoachIDFromContext(r)
 	if !ok {
@@ -206,7 +221,7 @@ func (h *CoachHandler) CreateSchemaForClient(w http.ResponseWriter, r *http.Requ
 
 	schema, err := h.service.CreateManualSchemaForClient(r.Context(), coachID, &req)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondNewSchemaError(w, err)
 		return
 	}
 
@@ -234,7 +249,7 @@ func (h *CoachHandler) UpdateSchema(w http.ResponseWriter, r *http.Request) {
 
 	schema, err := h.service.UpdateManualSchema(r.Context(), coachID, schemaID, &req)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondNewSchemaError(w, err)
 		return
 	}
 
@@ -254,7 +269,7 @@ func (h *CoachHandler) DeleteSchema(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.DeleteSchema(r.Context(), coachID, schemaID); err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondNewSchemaError(w, err)
 		return
 	}
 
@@ -287,7 +302,7 @@ func (h *CoachHandler) CloneSchema(w http.ResponseWriter, r *http.Request) {
 
 	schema, err := h.service.CloneSchemaToClient(r.Context(), coachID, schemaID, req.TargetUserID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondNewSchemaError(w, err)
 		return
 	}
 
@@ -313,6 +328,7 @@ func (h *CoachHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// SaveTemplate snapshots a schema into the coach's template library.
 func (h *CoachHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
 	coachID, ok := getCoachIDFromContext(r)
 	if !ok {
@@ -320,34 +336,22 @@ func (h *CoachHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		SchemaID     int    `json:"schema_id"`
-		TemplateName string `json:"template_name"`
-		Description  string `json:"description"`
-	}
+	var req types.SaveSchemaTemplateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if req.SchemaID <= 0 {
-		respondWithError(w, http.StatusBadRequest, "Invalid schema ID")
-		return
-	}
-
-	if req.TemplateName == "" {
-		respondWithError(w, http.StatusBadRequest, "Template name is required")
-		return
-	}
-
-	if err := h.service.SaveSchemaAsTemplate(r.Context(), coachID, req.SchemaID, req.TemplateName); err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+	template, err := h.service.SaveSchemaAsTemplate(r.Context(), coachID, &req)
+	if err != nil {
+		respondTemplateError(w, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusCreated, map[string]string{
-		"message": "Template saved successfully",
-		"name":    req.TemplateName,
+	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"message":  "Template saved successfully",
+		"name":     template.Name,
+		"template": template,
 	})
 }
 
@@ -380,7 +384,7 @@ func (h *CoachHandler) CreateFromTemplate(w http.ResponseWriter, r *http.Request
 
 	schema, err := h.service.CreateSchemaFromCoachTemplate(r.Context(), coachID, templateID, req.UserID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondTemplateError(w, err)
 		return
 	}
 
@@ -388,7 +392,7 @@ func (h *CoachHandler) CreateFromTemplate(w http.ResponseWriter, r *http.Request
 }
 
 func (h *CoachHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
-	_, ok := getCoachIDFromContext(r)
+	coachID, ok := getCoachIDFromContext(r)
 	if !ok {
 		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
 		return
@@ -400,8 +404,10 @@ func (h *CoachHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Implement DeleteTemplate in service
-	_ = templateID
+	if err := h.service.DeleteCoachTemplate(r.Context(), coachID, templateID); err != nil {
+		respondTemplateError(w, err)
+		return
+	}
 
 	respondWithJSON(w, http.StatusOK, map[string]string{
 		"message": "Template deleted successfully",
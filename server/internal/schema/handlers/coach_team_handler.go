@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+type CoachTeamHandler struct {
+	service service.CoachTeamService
+}
+
+func NewCoachTeamHandler(service service.CoachTeamService) *CoachTeamHandler {
+	return &CoachTeamHandler{
+		service: service,
+	}
+}
+
+func respondTeamError(w http.ResponseWriter, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case errors.Is(err, types.ErrReassignTarget), errors.Is(err, types.ErrTeamOwnerCannotLeave):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrTeamOwnerRequired), errors.Is(err, types.ErrClientAccessDenied),
+		errors.Is(err, types.ErrTeamPermissionDenied):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrTeamNotFound), errors.Is(err, types.ErrTeamMemberNotFound),
+		errors.Is(err, types.ErrTeamCoachNotFound), errors.Is(err, types.ErrTeamInvitationNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, types.ErrAlreadyInTeam):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Coach team request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Coach team request failed")
+	}
+}
+
+// GetTeam handles GET /coach/team
+func (h *CoachTeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	team, err := h.service.GetTeam(r.Context(), coachID)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, team)
+}
+
+// CreateTeam handles POST /coach/team
+func (h *CoachTeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	var req types.CreateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	team, err := h.service.CreateTeam(r.Context(), coachID, &req)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, team)
+}
+
+// RenameTeam handles PUT /coach/team
+func (h *CoachTeamHandler) RenameTeam(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	var req types.CreateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	team, err := h.service.RenameTeam(r.Context(), coachID, &req)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, team)
+}
+
+// DeleteTeam handles DELETE /coach/team
+func (h *CoachTeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	if err := h.service.DeleteTeam(r.Context(), coachID); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// InviteMember handles POST /coach/team/members
+func (h *CoachTeamHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	var req types.InviteTeamMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	member, err := h.service.InviteMember(r.Context(), coachID, &req)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, member)
+}
+
+// UpdateMember handles PUT /coach/team/members/{coachID}
+func (h *CoachTeamHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	var req types.UpdateTeamMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	team, err := h.service.UpdateMember(r.Context(), coachID, chi.URLParam(r, "coachID"), &req)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, team)
+}
+
+// RemoveMember handles DELETE /coach/team/members/{coachID}. Staff leave the
+// team by removing themselves.
+func (h *CoachTeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	if err := h.service.RemoveMember(r.Context(), coachID, chi.URLParam(r, "coachID")); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListInvitations handles GET /coach/team/invitations
+func (h *CoachTeamHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(r.Context(), coachID)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"invitations": invitations,
+	})
+}
+
+// AcceptInvitation handles POST /coach/team/invitations/{teamID}/accept
+func (h *CoachTeamHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	teamID, err := strconv.Atoi(chi.URLParam(r, "teamID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid team ID")
+		return
+	}
+
+	team, err := h.service.AcceptInvitation(r.Context(), coachID, teamID)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, team)
+}
+
+// DeclineInvitation handles POST /coach/team/invitations/{teamID}/decline
+func (h *CoachTeamHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	teamID, err := strconv.Atoi(chi.URLParam(r, "teamID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid team ID")
+		return
+	}
+
+	if err := h.service.DeclineInvitation(r.Context(), coachID, teamID); err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListClients handles GET /coach/team/clients
+func (h *CoachTeamHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	clients, err := h.service.ListClients(r.Context(), coachID)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"clients": clients,
+		"total":   len(clients),
+	})
+}
+
+// ReassignClient handles POST /coach/team/clients/{userID}/reassign
+func (h *CoachTeamHandler) ReassignClient(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req types.ReassignClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	assignment, err := h.service.ReassignClient(r.Context(), coachID, userID, &req)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, assignment)
+}
+
+// GetDashboard handles GET /coach/team/dashboard
+func (h *CoachTeamHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	dashboard, err := h.service.GetDashboard(r.Context(), coachID)
+	if err != nil {
+		respondTeamError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dashboard)
+}
@@ -2,13 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
 )
 
+// maxInvitationCSVSize caps bulk invitation uploads; 500 rows fit easily.
+const maxInvitationCSVSize = 1 << 20
+
 // InvitationHandler handles invitation-related HTTP requests
 type InvitationHandler struct {
 	invitationService service.InvitationService
@@ -25,8 +32,7 @@ func NewInvitationHandler(invitationService service.InvitationService) *Invitati
 func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Get coach ID from context (set by auth middleware)
-	userID, ok := ctx.Value("user_id").(string)
+	userID, ok := getCoachIDFromContext(r)
 	if !ok {
 		log.Printf("[CreateInvitation] No user_id in context")
 		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
@@ -60,12 +66,61 @@ func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Requ
 	respondWithJSON(w, http.StatusCreated, invitation)
 }
 
+// BulkCreateInvitations handles POST /api/v1/coach/invitations/bulk as
+// multipart form data with a CSV "file" and an optional "custom_message".
+func (h *InvitationHandler) BulkCreateInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := getCoachIDFromContext(r)
+	if !ok {
+		log.Printf("[BulkCreateInvitations] No user_id in context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxInvitationCSVSize+multipartOverhead)
+	if err := r.ParseMultipartForm(maxInvitationCSVSize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "CSV file is too large")
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "A CSV file is required")
+		return
+	}
+	defer file.Close()
+
+	var customMessage *string
+	if message := strings.TrimSpace(r.FormValue("custom_message")); message != "" {
+		customMessage = &message
+	}
+
+	result, err := h.invitationService.BulkCreateInvitations(ctx, userID, file, customMessage)
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidInvitationCSV) || errors.Is(err, types.ErrTooManyInvitations) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("[BulkCreateInvitations] Failed to create invitations: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create invitations")
+		return
+	}
+
+	log.Printf("[BulkCreateInvitations] Coach %s invited %d of %d rows", userID, result.Invited, len(result.Rows))
+	respondWithJSON(w, http.StatusOK, result)
+}
+
 // GetInvitations handles GET /api/v1/coach/invitations
 func (h *InvitationHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Get coach ID from context
-	userID, ok := ctx.Value("user_id").(string)
+	userID, ok := getCoachIDFromContext(r)
 	if !ok {
 		log.Printf("[GetInvitations] No user_id in context")
 		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
@@ -87,8 +142,7 @@ func (h *InvitationHandler) GetInvitations(w http.ResponseWriter, r *http.Reques
 func (h *InvitationHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Get coach ID from context
-	userID, ok := ctx.Value("user_id").(string)
+	userID, ok := getCoachIDFromContext(r)
 	if !ok {
 		log.Printf("[ResendInvitation] No user_id in context")
 		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
@@ -101,7 +155,7 @@ func (h *InvitationHandler) ResendInvitation(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	invitation, err := h.invitationService.ResendInvitation(ctx, invitationID)
+	invitation, err := h.invitationService.ResendInvitation(ctx, userID, invitationID)
 	if err != nil {
 		log.Printf("[ResendInvitation] Failed to resend invitation: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to resend invitation")
@@ -116,8 +170,7 @@ func (h *InvitationHandler) ResendInvitation(w http.ResponseWriter, r *http.Requ
 func (h *InvitationHandler) CancelInvitation(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Get coach ID from context
-	userID, ok := ctx.Value("user_id").(string)
+	userID, ok := getCoachIDFromContext(r)
 	if !ok {
 		log.Printf("[CancelInvitation] No user_id in context")
 		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
@@ -145,9 +198,8 @@ func (h *InvitationHandler) CancelInvitation(w http.ResponseWriter, r *http.Requ
 func (h *InvitationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Get user ID from context
-	userID, ok := ctx.Value("user_id").(string)
-	if !ok {
+	userID, ok := middleware.GetAuthUserIDFromContext(ctx)
+	if !ok || userID == "" {
 		log.Printf("[AcceptInvitation] No user_id in context")
 		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
 		return
@@ -311,7 +311,7 @@ func (h *PlanGenerationHandler) CreateWeeklySchemaFromTemplate(w http.ResponseWr
 
 	schema, err := h.service.CreateWeeklySchemaFromTemplate(r.Context(), req.UserID, req.TemplateID, req.WeekStart)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondTemplateError(w, err)
 		return
 	}
 
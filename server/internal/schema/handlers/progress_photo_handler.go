@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+	"github.com/tdmdh/fit-up-server/shared/utils"
+)
+
+// multipartOverhead leaves room for the form fields around the image.
+const multipartOverhead = 1 << 20
+
+type ProgressPhotoHandler struct {
+	service service.ProgressPhotoService
+}
+
+func NewProgressPhotoHandler(service service.ProgressPhotoService) *ProgressPhotoHandler {
+	return &ProgressPhotoHandler{
+		service: service,
+	}
+}
+
+func respondProgressPhotoError(w http.ResponseWriter, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case errors.Is(err, types.ErrInvalidProgressPhoto), errors.Is(err, types.ErrInvalidDateRange):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrProgressPhotoTooLarge):
+		respondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, types.ErrClientAccessDenied):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrProgressPhotoNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("Progress photo request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Progress photo request failed")
+	}
+}
+
+// parsePhotoFilter reads ?pose=&from=&to= with YYYY-MM-DD dates.
+func parsePhotoFilter(r *http.Request) (types.ProgressPhotoFilter, error) {
+	query := r.URL.Query()
+	filter := types.ProgressPhotoFilter{Pose: types.ProgressPhotoPose(query.Get("pose"))}
+
+	for param, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(param); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return filter, types.ErrInvalidDateRange
+			}
+			*dest = &date
+		}
+	}
+	return filter, nil
+}
+
+// parseComparisonDates reads the required ?from=&to= dates.
+func parseComparisonDates(r *http.Request) (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01-02", r.URL.Query().Get("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, types.ErrInvalidDateRange
+	}
+	to, err := time.Parse("2006-01-02", r.URL.Query().Get("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, types.ErrInvalidDateRange
+	}
+	return from, to, nil
+}
+
+// UploadPhoto handles POST /progress-photos as multipart form data with a
+// "photo" file and pose, taken_on and notes fields.
+func (h *ProgressPhotoHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, utils.MaxFileSize+multipartOverhead)
+	if err := r.ParseMultipartForm(utils.MaxFileSize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondProgressPhotoError(w, types.ErrProgressPhotoTooLarge)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+
+	file, _, err := utils.GetFormFile(r, "photo")
+	if err != nil || file == nil {
+		respondWithError(w, http.StatusBadRequest, "A photo file is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, utils.MaxFileSize+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to read photo")
+		return
+	}
+
+	req := types.UploadProgressPhotoRequest{
+		Pose:    types.ProgressPhotoPose(strings.ToLower(r.FormValue("pose"))),
+		TakenOn: r.FormValue("taken_on"),
+	}
+	if notes := strings.TrimSpace(r.FormValue("notes")); notes != "" {
+		req.Notes = &notes
+	}
+
+	photo, err := h.service.UploadPhoto(r.Context(), userID, &req, data)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, photo)
+}
+
+// ListMyPhotos handles GET /progress-photos?pose=&from=&to=&page=&limit=
+func (h *ProgressPhotoHandler) ListMyPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, err := parsePhotoFilter(r)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	pagination := extractPaginationParams(r)
+	photos, err := h.service.ListMyPhotos(r.Context(), userID, filter, pagination)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"photos": photos,
+		"page":   pagination.Page,
+		"limit":  pagination.Limit,
+	})
+}
+
+// CompareMyPhotos handles GET /progress-photos/compare?from=&to=
+func (h *ProgressPhotoHandler) CompareMyPhotos(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	from, to, err := parseComparisonDates(r)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	comparison, err := h.service.CompareMyPhotos(r.Context(), userID, from, to)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comparison)
+}
+
+// GetPhotoImage handles GET /progress-photos/{photoID}/image for the owner
+// and their coach.
+func (h *ProgressPhotoHandler) GetPhotoImage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	photoID, err := strconv.ParseInt(chi.URLParam(r, "photoID"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid photo ID")
+		return
+	}
+
+	photo, file, err := h.service.OpenPhoto(r.Context(), userID, photoID)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", photo.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", photo.CreatedAt, file)
+}
+
+// DeletePhoto handles DELETE /progress-photos/{photoID}
+func (h *ProgressPhotoHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	photoID, err := strconv.ParseInt(chi.URLParam(r, "photoID"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid photo ID")
+		return
+	}
+
+	if err := h.service.DeletePhoto(r.Context(), userID, photoID); err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListClientPhotos handles GET /coach/clients/{userID}/progress-photos?pose=&from=&to=&page=&limit=
+func (h *ProgressPhotoHandler) ListClientPhotos(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	filter, err := parsePhotoFilter(r)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	pagination := extractPaginationParams(r)
+	photos, err := h.service.ListClientPhotos(r.Context(), coachID, userID, filter, pagination)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"photos": photos,
+		"page":   pagination.Page,
+		"limit":  pagination.Limit,
+	})
+}
+
+// CompareClientPhotos handles GET /coach/clients/{userID}/progress-photos/compare?from=&to=
+func (h *ProgressPhotoHandler) CompareClientPhotos(w http.ResponseWriter, r *http.Request) {
+	coachID, ok := getCoachIDFromContext(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Coach ID not found")
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	from, to, err := parseComparisonDates(r)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	comparison, err := h.service.CompareClientPhotos(r.Context(), coachID, userID, from, to)
+	if err != nil {
+		respondProgressPhotoError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comparison)
+}
@@ -20,6 +20,13 @@ type SchemaRoutes struct {
 	coachHandler          *CoachHandler
 	invitationHandler     *InvitationHandler
 	workoutSharingHandler *WorkoutSharingHandler
+	adminHandler          *AdminHandler
+	coachAppHandler       *CoachApplicationHandler
+	coachAlertHandler     *CoachAlertHandler
+	checkInHandler        *CheckInHandler
+	progressPhotoHandler  *ProgressPhotoHandler
+	templateHandler       *TemplateLibraryHandler
+	coachTeamHandler      *CoachTeamHandler
 }
 
 func NewSchemaRoutes(
@@ -30,6 +37,13 @@ func NewSchemaRoutes(
 	planGenerationService service.PlanGenerationService,
 	coachService service.CoachService,
 	invitationService service.InvitationService,
+	adminService service.AdminService,
+	coachApplicationService service.CoachApplicationService,
+	coachAlertService service.CoachAlertService,
+	checkInService service.CheckInService,
+	progressPhotoService service.ProgressPhotoService,
+	templateLibraryService service.TemplateLibraryService,
+	coachTeamService service.CoachTeamService,
 ) *SchemaRoutes {
 	store, ok := schemaRepo.(*repository.Store)
 	if !ok {
@@ -45,6 +59,13 @@ func NewSchemaRoutes(
 		coachHandler:          NewCoachHandler(coachService),
 		invitationHandler:     NewInvitationHandler(invitationService),
 		workoutSharingHandler: NewWorkoutSharingHandler(store),
+		adminHandler:          NewAdminHandler(adminService),
+		coachAppHandler:       NewCoachApplicationHandler(coachApplicationService),
+		coachAlertHandler:     NewCoachAlertHandler(coachAlertService),
+		checkInHandler:        NewCheckInHandler(checkInService),
+		progressPhotoHandler:  NewProgressPhotoHandler(progressPhotoService),
+		templateHandler:       NewTemplateLibraryHandler(templateLibraryService),
+		coachTeamHandler:      NewCoachTeamHandler(coachTeamService),
 	}
 }
 
@@ -106,6 +127,40 @@ func (sr *SchemaRoutes) RegisterRoutes(r chi.Router) {
 			r.Post("/share", sr.workoutSharingHandler.HandleShareWorkout)
 		})
 
+		r.Route("/coach-applications", func(r chi.Router) {
+			r.Post("/", sr.coachAppHandler.SubmitApplication)
+			r.Get("/me", sr.coachAppHandler.ListMyApplications)
+			r.Delete("/{applicationID}", sr.coachAppHandler.WithdrawApplication)
+		})
+
+		r.Route("/check-ins", func(r chi.Router) {
+			r.Get("/", sr.checkInHandler.ListMyCheckIns)
+			r.Get("/{submissionID}", sr.checkInHandler.GetMyCheckIn)
+			r.Post("/{submissionID}/submit", sr.checkInHandler.SubmitCheckIn)
+		})
+
+		r.Route("/progress-photos", func(r chi.Router) {
+			r.Get("/", sr.progressPhotoHandler.ListMyPhotos)
+			r.Post("/", sr.progressPhotoHandler.UploadPhoto)
+			r.Get("/compare", sr.progressPhotoHandler.CompareMyPhotos)
+			r.Get("/{photoID}/image", sr.progressPhotoHandler.GetPhotoImage)
+			r.Delete("/{photoID}", sr.progressPhotoHandler.DeletePhoto)
+		})
+
+		r.Route("/template-library", func(r chi.Router) {
+			r.Get("/", sr.templateHandler.ListTemplates)
+			r.Post("/", sr.templateHandler.CreateTemplate)
+			r.Get("/marketplace", sr.templateHandler.ListMarketplace)
+			r.Get("/{templateID}", sr.templateHandler.GetTemplate)
+			r.Put("/{templateID}", sr.templateHandler.UpdateTemplate)
+			r.Delete("/{templateID}", sr.templateHandler.DeleteTemplate)
+			r.Post("/{templateID}/use", sr.templateHandler.UseTemplate)
+			r.Post("/{templateID}/fork", sr.templateHandler.ForkTemplate)
+			r.Get("/{templateID}/reviews", sr.templateHandler.ListReviews)
+			r.Put("/{templateID}/rating", sr.templateHandler.RateTemplate)
+			r.Delete("/{templateID}/rating", sr.templateHandler.DeleteRating)
+		})
+
 		r.Get("/coach/assigned/{userID}", sr.coachHandler.GetAssignedCoach)
 
 		r.Route("/coach", func(r chi.Router) {
@@ -120,6 +175,7 @@ func (sr *SchemaRoutes) RegisterRoutes(r chi.Router) {
 			r.Delete("/clients/{assignmentID}", sr.coachHandler.RemoveClient)
 
 			r.Get("/clients/{userID}/progress", sr.coachHandler.GetClientProgress)
+			r.Get("/clients/{userID}/timeline", sr.coachHandler.GetClientTimeline)
 			r.Get("/clients/{userID}/workouts", sr.coachHandler.GetClientWorkouts)
 			r.Get("/clients/{userID}/schemas", sr.coachHandler.GetClientSchemas)
 
@@ -133,8 +189,49 @@ func (sr *SchemaRoutes) RegisterRoutes(r chi.Router) {
 			r.Post("/templates/{templateID}/create-schema", sr.coachHandler.CreateFromTemplate)
 			r.Delete("/templates/{templateID}", sr.coachHandler.DeleteTemplate)
 
+			r.Get("/alerts", sr.coachAlertHandler.GetAlerts)
+			r.Post("/alerts/scan", sr.coachAlertHandler.ScanClients)
+			r.Post("/alerts/acknowledge-all", sr.coachAlertHandler.AcknowledgeAllAlerts)
+			r.Post("/alerts/{alertID}/acknowledge", sr.coachAlertHandler.AcknowledgeAlert)
+			r.Get("/alert-rules", sr.coachAlertHandler.GetRules)
+			r.Put("/alert-rules", sr.coachAlertHandler.UpdateRules)
+
+			r.Get("/check-in-templates", sr.checkInHandler.ListTemplates)
+			r.Post("/check-in-templates", sr.checkInHandler.CreateTemplate)
+			r.Get("/check-in-templates/{templateID}", sr.checkInHandler.GetTemplate)
+			r.Put("/check-in-templates/{templateID}", sr.checkInHandler.UpdateTemplate)
+			r.Delete("/check-in-templates/{templateID}", sr.checkInHandler.ArchiveTemplate)
+			r.Get("/clients/{userID}/check-in-schedules", sr.checkInHandler.ListClientSchedules)
+			r.Post("/clients/{userID}/check-in-schedules", sr.checkInHandler.ScheduleForClient)
+			r.Delete("/check-in-schedules/{scheduleID}", sr.checkInHandler.CancelSchedule)
+			r.Get("/check-ins", sr.checkInHandler.ListCoachCheckIns)
+			r.Get("/clients/{userID}/check-ins", sr.checkInHandler.ListClientCheckIns)
+			r.Get("/check-ins/{submissionID}", sr.checkInHandler.GetCoachCheckIn)
+			r.Post("/check-ins/{submissionID}/review", sr.checkInHandler.ReviewCheckIn)
+			r.Get("/clients/{userID}/check-in-questions/{questionID}/history", sr.checkInHandler.GetQuestionHistory)
+
+			r.Get("/clients/{userID}/progress-photos", sr.progressPhotoHandler.ListClientPhotos)
+			r.Get("/clients/{userID}/progress-photos/compare", sr.progressPhotoHandler.CompareClientPhotos)
+
+			r.Route("/team", func(r chi.Router) {
+				r.Get("/", sr.coachTeamHandler.GetTeam)
+				r.Post("/", sr.coachTeamHandler.CreateTeam)
+				r.Put("/", sr.coachTeamHandler.RenameTeam)
+				r.Delete("/", sr.coachTeamHandler.DeleteTeam)
+				r.Post("/members", sr.coachTeamHandler.InviteMember)
+				r.Put("/members/{coachID}", sr.coachTeamHandler.UpdateMember)
+				r.Delete("/members/{coachID}", sr.coachTeamHandler.RemoveMember)
+				r.Get("/invitations", sr.coachTeamHandler.ListInvitations)
+				r.Post("/invitations/{teamID}/accept", sr.coachTeamHandler.AcceptInvitation)
+				r.Post("/invitations/{teamID}/decline", sr.coachTeamHandler.DeclineInvitation)
+				r.Get("/clients", sr.coachTeamHandler.ListClients)
+				r.Post("/clients/{userID}/reassign", sr.coachTeamHandler.ReassignClient)
+				r.Get("/dashboard", sr.coachTeamHandler.GetDashboard)
+			})
+
 			// Invitation routes
 			r.Post("/invitations", sr.invitationHandler.CreateInvitation)
+			r.Post("/invitations/bulk", sr.invitationHandler.BulkCreateInvitations)
 			r.Get("/invitations", sr.invitationHandler.GetInvitations)
 			r.Post("/invitations/{id}/resend", sr.invitationHandler.ResendInvitation)
 			r.Delete("/invitations/{id}", sr.invitationHandler.CancelInvitation)
@@ -146,8 +243,22 @@ func (sr *SchemaRoutes) RegisterRoutes(r chi.Router) {
 		r.Group(func(r chi.Router) {
 			r.Use(sr.authMiddleware.RequireAdminRole())
 
-			// Admin-specific routes can be added here
-			// For example: user management, system settings, etc.
+			r.Route("/admin", func(r chi.Router) {
+				r.Get("/users", sr.adminHandler.ListUsers)
+				r.Get("/users/{userID}", sr.adminHandler.GetUser)
+				r.Put("/users/{userID}/role", sr.adminHandler.ChangeRole)
+				r.Post("/users/{userID}/suspend", sr.adminHandler.SuspendUser)
+				r.Post("/users/{userID}/reactivate", sr.adminHandler.ReactivateUser)
+				r.Post("/users/{userID}/force-password-reset", sr.adminHandler.ForcePasswordReset)
+				r.Post("/users/{userID}/revoke-sessions", sr.adminHandler.RevokeSessions)
+				r.Get("/users/{userID}/audit-log", sr.adminHandler.GetAuditLog)
+				r.Get("/audit-log", sr.adminHandler.GetAuditLog)
+
+				r.Get("/coach-applications", sr.coachAppHandler.ListApplications)
+				r.Get("/coach-applications/{applicationID}", sr.coachAppHandler.GetApplication)
+				r.Post("/coach-applications/{applicationID}/approve", sr.coachAppHandler.ApproveApplication)
+				r.Post("/coach-applications/{applicationID}/reject", sr.coachAppHandler.RejectApplication)
+			})
 		})
 	})
 }
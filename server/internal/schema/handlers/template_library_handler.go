@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	service "github.com/tdmdh/fit-up-server/internal/schema/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/middleware"
+)
+
+type TemplateLibraryHandler struct {
+	service service.TemplateLibraryService
+}
+
+func NewTemplateLibraryHandler(service service.TemplateLibraryService) *TemplateLibraryHandler {
+	return &TemplateLibraryHandler{
+		service: service,
+	}
+}
+
+func respondTemplateError(w http.ResponseWriter, err error) {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case errors.Is(err, types.ErrTemplateEmpty),
+		errors.Is(err, types.ErrTemplateDuplicateDay),
+		errors.Is(err, types.ErrTemplateExerciseNotFound),
+		errors.Is(err, types.ErrInvalidTemplateScope):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, types.ErrCoachingSubscriptionLapsed):
+		respondWithError(w, http.StatusPaymentRequired, err.Error())
+	case errors.Is(err, types.ErrClientAccessDenied),
+		errors.Is(err, types.ErrTeamPermissionDenied),
+		errors.Is(err, types.ErrSharedPlanDenied),
+		errors.Is(err, types.ErrTemplateNotPublic):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, types.ErrTemplateNotFound), errors.Is(err, types.ErrTemplateRatingNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	default:
+		log.Printf("Template request failed: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Template request failed")
+	}
+}
+
+func parseTemplateID(r *http.Request) (int, bool) {
+	templateID, err := strconv.Atoi(chi.URLParam(r, "templateID"))
+	return templateID, err == nil && templateID > 0
+}
+
+// ListTemplates handles GET /template-library?scope=&search=&page=&limit=
+func (h *TemplateLibraryHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter := types.TemplateFilter{
+		Scope:  types.TemplateScope(r.URL.Query().Get("scope")),
+		Search: r.URL.Query().Get("search"),
+	}
+
+	page, err := h.service.ListTemplates(r.Context(), userID, filter, extractPaginationParams(r))
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+func (h *TemplateLibraryHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	template, err := h.service.GetTemplate(r.Context(), userID, templateID)
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, template)
+}
+
+func (h *TemplateLibraryHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.CreateWorkoutTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.CreateTemplate(r.Context(), userID, &req)
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, template)
+}
+
+func (h *TemplateLibraryHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req types.UpdateWorkoutTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(r.Context(), userID, templateID, &req)
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, template)
+}
+
+func (h *TemplateLibraryHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	if err := h.service.DeleteTemplate(r.Context(), userID, templateID); err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Template deleted successfully"})
+}
+
+// UseTemplate handles POST /template-library/{templateID}/use, which replaces
+// the caller's active schema with one built from the template.
+func (h *TemplateLibraryHandler) UseTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	schema, err := h.service.UseTemplate(r.Context(), userID, templateID)
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, schema)
+}
+
+// ListMarketplace handles GET /template-library/marketplace?sort=&search=&page=&limit=
+func (h *TemplateLibraryHandler) ListMarketplace(w http.ResponseWriter, r *http.Request) {
+	filter := types.MarketplaceFilter{
+		Sort:   types.TemplateSort(r.URL.Query().Get("sort")),
+		Search: r.URL.Query().Get("search"),
+	}
+
+	page, err := h.service.ListMarketplace(r.Context(), filter, extractPaginationParams(r))
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// ForkTemplate handles POST /template-library/{templateID}/fork. The body is
+// optional and may rename the copy.
+func (h *TemplateLibraryHandler) ForkTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req types.ForkTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.service.ForkTemplate(r.Context(), userID, templateID, &req)
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, template)
+}
+
+func (h *TemplateLibraryHandler) RateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	var req types.RateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rating, err := h.service.RateTemplate(r.Context(), userID, templateID, &req)
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rating)
+}
+
+func (h *TemplateLibraryHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	if err := h.service.DeleteRating(r.Context(), userID, templateID); err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Rating deleted successfully"})
+}
+
+// ListReviews handles GET /template-library/{templateID}/reviews?page=&limit=
+func (h *TemplateLibraryHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetAuthUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templateID, ok := parseTemplateID(r)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid template ID")
+		return
+	}
+
+	page, err := h.service.ListReviews(r.Context(), userID, templateID, extractPaginationParams(r))
+	if err != nil {
+		respondTemplateError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,695 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const checkInQuestionColumns = `
+	q.question_id, q.position, q.question_type, q.prompt, q.required,
+	q.min_value, q.max_value, q.unit, q.options, q.allow_multiple
+`
+
+const checkInSubmissionColumns = `
+	s.submission_id, s.schedule_id, s.template_id, t.name, s.coach_id, s.client_id, s.workout_profile_id,
+	COALESCE(NULLIF(u.name, ''), u.username, 'Client') AS client_name,
+	s.week_start, s.due_date, s.status, s.submitted_at, s.reviewed_at, s.coach_feedback, s.created_at
+`
+
+const checkInSubmissionJoins = `
+	JOIN checkin_templates t ON t.template_id = s.template_id
+	LEFT JOIN users u ON u.id = s.client_id
+`
+
+// CreateCheckInTemplate stores the template and its questions, filling in the
+// generated IDs.
+func (s *Store) CreateCheckInTemplate(ctx context.Context, template *types.CheckInTemplate) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO checkin_templates (coach_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING template_id, created_at, updated_at`,
+		template.CoachID, template.Name, template.Description,
+	).Scan(&template.TemplateID, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range template.Questions {
+		if err := insertCheckInQuestion(ctx, tx, template.TemplateID, &template.Questions[i]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateCheckInTemplate replaces the template's name, description and question
+// list. Questions that are left out are archived so their answers stay chartable.
+func (s *Store) UpdateCheckInTemplate(ctx context.Context, template *types.CheckInTemplate) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE checkin_templates
+		SET name = $3, description = $4, updated_at = NOW()
+		WHERE template_id = $1 AND coach_id = $2 AND archived_at IS NULL
+		RETURNING created_at, updated_at`,
+		template.TemplateID, template.CoachID, template.Name, template.Description,
+	).Scan(&template.CreatedAt, &template.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return types.ErrCheckInTemplateNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	kept := []int64{}
+	for i := range template.Questions {
+		q := &template.Questions[i]
+		if q.QuestionID == 0 {
+			if err := insertCheckInQuestion(ctx, tx, template.TemplateID, q); err != nil {
+				return err
+			}
+			kept = append(kept, q.QuestionID)
+			continue
+		}
+
+		options, err := json.Marshal(checkInOptions(q.Options))
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE checkin_questions
+			SET position = $3, question_type = $4, prompt = $5, required = $6,
+				min_value = $7, max_value = $8, unit = $9, options = $10, allow_multiple = $11
+			WHERE question_id = $1 AND template_id = $2 AND archived_at IS NULL`,
+			q.QuestionID, template.TemplateID, q.Position, q.Type, q.Prompt, q.Required,
+			q.MinValue, q.MaxValue, q.Unit, options, q.AllowMultiple,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return types.ErrCheckInQuestionNotFound
+		}
+		kept = append(kept, q.QuestionID)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE checkin_questions
+		SET archived_at = NOW()
+		WHERE template_id = $1 AND archived_at IS NULL AND NOT (question_id = ANY($2::bigint[]))`,
+		template.TemplateID, kept,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertCheckInQuestion(ctx context.Context, tx pgx.Tx, templateID int64, q *types.CheckInQuestion) error {
+	options, err := json.Marshal(checkInOptions(q.Options))
+	if err != nil {
+		return err
+	}
+	return tx.QueryRow(ctx, `
+		INSERT INTO checkin_questions
+			(template_id, position, question_type, prompt, required, min_value, max_value, unit, options, allow_multiple)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING question_id`,
+		templateID, q.Position, q.Type, q.Prompt, q.Required, q.MinValue, q.MaxValue, q.Unit, options, q.AllowMultiple,
+	).Scan(&q.QuestionID)
+}
+
+func checkInOptions(options []string) []string {
+	if options == nil {
+		return []string{}
+	}
+	return options
+}
+
+// GetCheckInTemplate returns one of the coach's templates with its current questions.
+func (s *Store) GetCheckInTemplate(ctx context.Context, coachID string, templateID int64) (*types.CheckInTemplate, error) {
+	var template types.CheckInTemplate
+	err := s.db.QueryRow(ctx, `
+		SELECT template_id, coach_id, name, description, created_at, updated_at
+		FROM checkin_templates
+		WHERE template_id = $1 AND coach_id = $2 AND archived_at IS NULL`,
+		templateID, coachID,
+	).Scan(&template.TemplateID, &template.CoachID, &template.Name, &template.Description, &template.CreatedAt, &template.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrCheckInTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	template.Questions, err = s.queryCheckInQuestions(ctx, `
+		SELECT `+checkInQuestionColumns+`
+		FROM checkin_questions q
+		WHERE q.template_id = $1 AND q.archived_at IS NULL
+		ORDER BY q.position, q.question_id`,
+		templateID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListCheckInTemplates returns the coach's templates without their questions.
+func (s *Store) ListCheckInTemplates(ctx context.Context, coachID string) ([]types.CheckInTemplate, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT template_id, coach_id, name, description, created_at, updated_at
+		FROM checkin_templates
+		WHERE coach_id = $1 AND archived_at IS NULL
+		ORDER BY updated_at DESC`,
+		coachID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []types.CheckInTemplate{}
+	for rows.Next() {
+		var template types.CheckInTemplate
+		if err := rows.Scan(&template.TemplateID, &template.CoachID, &template.Name, &template.Description, &template.CreatedAt, &template.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+// ArchiveCheckInTemplate hides the template, stops its schedules and drops
+// check-ins that were never answered. Submitted check-ins are kept.
+func (s *Store) ArchiveCheckInTemplate(ctx context.Context, coachID string, templateID int64) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE checkin_templates
+		SET archived_at = NOW(), updated_at = NOW()
+		WHERE template_id = $1 AND coach_id = $2 AND archived_at IS NULL`,
+		templateID, coachID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrCheckInTemplateNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE checkin_schedules SET active = FALSE, updated_at = NOW() WHERE template_id = $1`, templateID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM checkin_submissions WHERE template_id = $1 AND status = 'pending'`, templateID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpsertCheckInSchedule creates the schedule, or reactivates and reschedules an
+// existing one for the same template and client.
+func (s *Store) UpsertCheckInSchedule(ctx context.Context, schedule *types.CheckInSchedule) error {
+	return s.db.QueryRow(ctx, `
+		INSERT INTO checkin_schedules (template_id, coach_id, client_id, workout_profile_id, day_of_week)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (template_id, client_id) DO UPDATE
+		SET coach_id = EXCLUDED.coach_id,
+			workout_profile_id = EXCLUDED.workout_profile_id,
+			day_of_week = EXCLUDED.day_of_week,
+			active = TRUE,
+			updated_at = NOW()
+		RETURNING schedule_id, active, created_at`,
+		schedule.TemplateID, schedule.CoachID, schedule.ClientID, schedule.WorkoutProfileID, schedule.DayOfWeek,
+	).Scan(&schedule.ScheduleID, &schedule.Active, &schedule.CreatedAt)
+}
+
+const checkInScheduleSelect = `
+	SELECT cs.schedule_id, cs.template_id, t.name, cs.coach_id, cs.client_id, cs.workout_profile_id,
+		cs.day_of_week, cs.active, cs.created_at
+	FROM checkin_schedules cs
+	JOIN checkin_templates t ON t.template_id = cs.template_id
+`
+
+// ListCheckInSchedules returns the coach's active schedules for one client.
+func (s *Store) ListCheckInSchedules(ctx context.Context, coachID, clientID string) ([]types.CheckInSchedule, error) {
+	return s.queryCheckInSchedules(ctx, checkInScheduleSelect+`
+		WHERE cs.coach_id = $1 AND cs.client_id = $2 AND cs.active = TRUE
+		ORDER BY cs.day_of_week, t.name`,
+		coachID, clientID,
+	)
+}
+
+// ListActiveCheckInSchedules returns every schedule that should still produce
+// check-ins: the template is live and the coach still coaches the client.
+func (s *Store) ListActiveCheckInSchedules(ctx context.Context) ([]types.CheckInSchedule, error) {
+	return s.queryCheckInSchedules(ctx, checkInScheduleSelect+`
+		WHERE cs.active = TRUE AND t.archived_at IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM coach_assignments ca
+			WHERE ca.coach_id = cs.coach_id AND ca.user_id = cs.workout_profile_id AND ca.is_active = TRUE
+		  )
+		ORDER BY cs.schedule_id`,
+	)
+}
+
+func (s *Store) DeactivateCheckInSchedule(ctx context.Context, coachID string, scheduleID int64) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE checkin_schedules
+		SET active = FALSE, updated_at = NOW()
+		WHERE schedule_id = $1 AND coach_id = $2 AND active = TRUE`,
+		scheduleID, coachID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrCheckInScheduleNotFound
+	}
+	return nil
+}
+
+func (s *Store) queryCheckInSchedules(ctx context.Context, query string, args ...interface{}) ([]types.CheckInSchedule, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []types.CheckInSchedule{}
+	for rows.Next() {
+		var sc types.CheckInSchedule
+		err := rows.Scan(&sc.ScheduleID, &sc.TemplateID, &sc.TemplateName, &sc.CoachID, &sc.ClientID,
+			&sc.WorkoutProfileID, &sc.DayOfWeek, &sc.Active, &sc.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, sc)
+	}
+	return schedules, rows.Err()
+}
+
+// CreateCheckInSubmission opens the check-in for a week. It returns false when
+// the client already has this template's check-in for that week.
+func (s *Store) CreateCheckInSubmission(ctx context.Context, submission *types.CheckInSubmission) (bool, error) {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO checkin_submissions (schedule_id, template_id, coach_id, client_id, workout_profile_id, week_start, due_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (template_id, client_id, week_start) DO NOTHING
+		RETURNING submission_id, status, created_at`,
+		submission.ScheduleID,
+		submission.TemplateID,
+		submission.CoachID,
+		submission.ClientID,
+		submission.WorkoutProfileID,
+		submission.WeekStart,
+		submission.DueDate,
+	).Scan(&submission.SubmissionID, &submission.Status, &submission.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Store) GetCheckInSubmission(ctx context.Context, submissionID int64) (*types.CheckInSubmission, error) {
+	query := `SELECT ` + checkInSubmissionColumns + ` FROM checkin_submissions s ` + checkInSubmissionJoins + `
+		WHERE s.submission_id = $1`
+
+	submission, err := scanCheckInSubmission(s.db.QueryRow(ctx, query, submissionID))
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrCheckInNotFound
+	}
+	return submission, err
+}
+
+// ListClientCheckIns returns the client's check-ins, newest week first. An
+// empty status returns every status.
+func (s *Store) ListClientCheckIns(ctx context.Context, clientID string, status types.CheckInStatus, limit, offset int) ([]types.CheckInSubmission, error) {
+	return s.queryCheckInSubmissions(ctx, `SELECT `+checkInSubmissionColumns+` FROM checkin_submissions s `+checkInSubmissionJoins+`
+		WHERE s.client_id = $1 AND ($2 = '' OR s.status = $2)
+		ORDER BY s.week_start DESC, s.submission_id DESC
+		LIMIT $3 OFFSET $4`,
+		clientID, string(status), limit, offset,
+	)
+}
+
+// ListCoachCheckIns returns check-ins sent by the coach, optionally for a single
+// client. Submitted check-ins awaiting review come first.
+func (s *Store) ListCoachCheckIns(ctx context.Context, coachID, clientID string, status types.CheckInStatus, limit, offset int) ([]types.CheckInSubmission, error) {
+	return s.queryCheckInSubmissions(ctx, `SELECT `+checkInSubmissionColumns+` FROM checkin_submissions s `+checkInSubmissionJoins+`
+		WHERE s.coach_id = $1 AND ($2 = '' OR s.client_id = $2) AND ($3 = '' OR s.status = $3)
+		ORDER BY (s.status = 'submitted') DESC, COALESCE(s.submitted_at, s.created_at) DESC, s.submission_id DESC
+		LIMIT $4 OFFSET $5`,
+		coachID, clientID, string(status), limit, offset,
+	)
+}
+
+func (s *Store) queryCheckInSubmissions(ctx context.Context, query string, args ...interface{}) ([]types.CheckInSubmission, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []types.CheckInSubmission{}
+	for rows.Next() {
+		submission, err := scanCheckInSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, *submission)
+	}
+	return submissions, rows.Err()
+}
+
+// ListCheckInQuestionsForSubmission returns the template's current questions
+// plus any archived ones the submission answered.
+func (s *Store) ListCheckInQuestionsForSubmission(ctx context.Context, submissionID, templateID int64) ([]types.CheckInQuestion, error) {
+	return s.queryCheckInQuestions(ctx, `
+		SELECT `+checkInQuestionColumns+`
+		FROM checkin_questions q
+		WHERE q.template_id = $2
+		  AND (q.archived_at IS NULL OR EXISTS (
+			SELECT 1 FROM checkin_answers a WHERE a.submission_id = $1 AND a.question_id = q.question_id
+		  ))
+		ORDER BY q.position, q.question_id`,
+		submissionID, templateID,
+	)
+}
+
+// GetCheckInQuestion returns a question, archived or not, together with the
+// coach who owns its template.
+func (s *Store) GetCheckInQuestion(ctx context.Context, questionID int64) (*types.CheckInQuestion, string, error) {
+	var coachID string
+	row := s.db.QueryRow(ctx, `
+		SELECT `+checkInQuestionColumns+`, t.coach_id
+		FROM checkin_questions q
+		JOIN checkin_templates t ON t.template_id = q.template_id
+		WHERE q.question_id = $1`,
+		questionID,
+	)
+	question, err := scanCheckInQuestion(row, &coachID)
+	if err == pgx.ErrNoRows {
+		return nil, "", types.ErrCheckInQuestionNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return question, coachID, nil
+}
+
+func (s *Store) queryCheckInQuestions(ctx context.Context, query string, args ...interface{}) ([]types.CheckInQuestion, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []types.CheckInQuestion{}
+	for rows.Next() {
+		question, err := scanCheckInQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, *question)
+	}
+	return questions, rows.Err()
+}
+
+func (s *Store) ListCheckInAnswers(ctx context.Context, submissionID int64) ([]types.CheckInAnswer, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT a.question_id, a.number_value, a.text_value, a.choice_values, a.photo_url
+		FROM checkin_answers a
+		JOIN checkin_questions q ON q.question_id = a.question_id
+		WHERE a.submission_id = $1
+		ORDER BY q.position, q.question_id`,
+		submissionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	answers := []types.CheckInAnswer{}
+	for rows.Next() {
+		var answer types.CheckInAnswer
+		var choices []byte
+		if err := rows.Scan(&answer.QuestionID, &answer.Number, &answer.Text, &choices, &answer.PhotoURL); err != nil {
+			return nil, err
+		}
+		if len(choices) > 0 {
+			if err := json.Unmarshal(choices, &answer.Choices); err != nil {
+				return nil, fmt.Errorf("failed to decode check-in choices: %w", err)
+			}
+		}
+		answers = append(answers, answer)
+	}
+	return answers, rows.Err()
+}
+
+// SaveCheckInAnswers replaces the submission's answers and marks it submitted.
+// Answers can be changed until the coach has reviewed the check-in.
+func (s *Store) SaveCheckInAnswers(ctx context.Context, submissionID int64, answers []types.CheckInAnswer) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE checkin_submissions
+		SET status = 'submitted', submitted_at = NOW()
+		WHERE submission_id = $1 AND status IN ('pending', 'submitted')`,
+		submissionID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrCheckInAlreadyReviewed
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM checkin_answers WHERE submission_id = $1`, submissionID); err != nil {
+		return err
+	}
+
+	for _, answer := range answers {
+		var choices []byte
+		if answer.Choices != nil {
+			if choices, err = json.Marshal(answer.Choices); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO checkin_answers (submission_id, question_id, number_value, text_value, choice_values, photo_url)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			submissionID, answer.QuestionID, answer.Number, answer.Text, choices, answer.PhotoURL,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReviewCheckInSubmission records the coach's feedback. Reviewing again
+// replaces the feedback.
+func (s *Store) ReviewCheckInSubmission(ctx context.Context, coachID string, submissionID int64, feedback string) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE checkin_submissions
+		SET status = 'reviewed', reviewed_at = NOW(), coach_feedback = NULLIF($3, '')
+		WHERE submission_id = $1 AND coach_id = $2 AND status IN ('submitted', 'reviewed')`,
+		submissionID, coachID, feedback,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrCheckInNotSubmitted
+	}
+	return nil
+}
+
+// GetCheckInWeekMetrics summarises the client's tracked data for the seven
+// days starting at weekStart.
+func (s *Store) GetCheckInWeekMetrics(ctx context.Context, clientID string, weekStart time.Time) (*types.CheckInWeekMetrics, error) {
+	query := `
+		WITH bounds AS (
+			SELECT $2::date AS start_date, $2::date + 7 AS end_date
+		),
+		nutrition_days AS (
+			SELECT f.log_date, SUM(f.calories) AS calories, SUM(f.protein) AS protein
+			FROM food_log_entries f, bounds b
+			WHERE f.user_id = $1 AND f.log_date >= b.start_date AND f.log_date < b.end_date
+			GROUP BY f.log_date
+		)
+		SELECT
+			(SELECT COUNT(*) FROM workout_sessions ws, bounds b
+			 WHERE ws.user_id = $1 AND ws.status = 'completed'
+			   AND COALESCE(ws.end_time, ws.start_time) >= b.start_date
+			   AND COALESCE(ws.end_time, ws.start_time) < b.end_date),
+			(SELECT COALESCE(SUM(ws.total_volume), 0) FROM workout_sessions ws, bounds b
+			 WHERE ws.user_id = $1 AND ws.status = 'completed'
+			   AND COALESCE(ws.end_time, ws.start_time) >= b.start_date
+			   AND COALESCE(ws.end_time, ws.start_time) < b.end_date),
+			(SELECT COUNT(*) FROM progress_logs pl, bounds b
+			 WHERE pl.user_id = $1 AND pl.date >= b.start_date AND pl.date < b.end_date
+			   AND pl.weight_used > (
+				SELECT MAX(p2.weight_used) FROM progress_logs p2
+				WHERE p2.user_id = pl.user_id AND p2.exercise_id = pl.exercise_id AND p2.date < pl.date
+			   )),
+			rm.avg_sleep, rm.avg_energy, rm.avg_stress, rm.avg_soreness,
+			(SELECT COUNT(*) FROM nutrition_days),
+			(SELECT AVG(calories)::float8 FROM nutrition_days),
+			(SELECT AVG(protein)::float8 FROM nutrition_days),
+			ng.calories_goal, ng.protein_goal,
+			(SELECT COALESCE(SUM(ms.duration_seconds), 0) / 60 FROM mindfulness_sessions ms, bounds b
+			 WHERE ms.user_id = $1 AND ms.completed_at >= b.start_date AND ms.completed_at < b.end_date)
+		FROM bounds b
+		LEFT JOIN LATERAL (
+			SELECT AVG(sleep_hours) AS avg_sleep, AVG(energy_level) AS avg_energy,
+				AVG(stress_level) AS avg_stress, AVG(soreness) AS avg_soreness
+			FROM recovery_metrics
+			WHERE user_id = $1 AND date >= b.start_date AND date < b.end_date
+		) rm ON TRUE
+		LEFT JOIN nutrition_goals ng ON ng.user_id = $1
+	`
+
+	var m types.CheckInWeekMetrics
+	err := s.db.QueryRow(ctx, query, clientID, weekStart).Scan(
+		&m.WorkoutsCompleted,
+		&m.TotalVolume,
+		&m.PersonalRecords,
+		&m.AvgSleepHours,
+		&m.AvgEnergyLevel,
+		&m.AvgStressLevel,
+		&m.AvgSoreness,
+		&m.NutritionDaysLogged,
+		&m.AvgCalories,
+		&m.AvgProtein,
+		&m.CalorieGoal,
+		&m.ProteinGoal,
+		&m.MindfulnessMinutes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetCheckInAnswerHistory returns the client's answers to a question from
+// submitted check-ins, oldest first.
+func (s *Store) GetCheckInAnswerHistory(ctx context.Context, clientID string, questionID int64, limit int) ([]types.CheckInAnswerPoint, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT * FROM (
+			SELECT s.submission_id, s.week_start, s.submitted_at, a.number_value, a.choice_values
+			FROM checkin_answers a
+			JOIN checkin_submissions s ON s.submission_id = a.submission_id
+			WHERE s.client_id = $1 AND a.question_id = $2 AND s.submitted_at IS NOT NULL
+			ORDER BY s.week_start DESC
+			LIMIT $3
+		) recent
+		ORDER BY week_start`,
+		clientID, questionID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []types.CheckInAnswerPoint{}
+	for rows.Next() {
+		var point types.CheckInAnswerPoint
+		var choices []byte
+		if err := rows.Scan(&point.SubmissionID, &point.WeekStart, &point.SubmittedAt, &point.Value, &choices); err != nil {
+			return nil, err
+		}
+		if len(choices) > 0 {
+			if err := json.Unmarshal(choices, &point.Choices); err != nil {
+				return nil, fmt.Errorf("failed to decode check-in choices: %w", err)
+			}
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
+
+func scanCheckInSubmission(row pgx.Row) (*types.CheckInSubmission, error) {
+	var sub types.CheckInSubmission
+	err := row.Scan(
+		&sub.SubmissionID,
+		&sub.ScheduleID,
+		&sub.TemplateID,
+		&sub.TemplateName,
+		&sub.CoachID,
+		&sub.ClientID,
+		&sub.WorkoutProfileID,
+		&sub.ClientName,
+		&sub.WeekStart,
+		&sub.DueDate,
+		&sub.Status,
+		&sub.SubmittedAt,
+		&sub.ReviewedAt,
+		&sub.CoachFeedback,
+		&sub.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func scanCheckInQuestion(row pgx.Row, extra ...interface{}) (*types.CheckInQuestion, error) {
+	var q types.CheckInQuestion
+	var options []byte
+	dest := append([]interface{}{
+		&q.QuestionID,
+		&q.Position,
+		&q.Type,
+		&q.Prompt,
+		&q.Required,
+		&q.MinValue,
+		&q.MaxValue,
+		&q.Unit,
+		&options,
+		&q.AllowMultiple,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &q.Options); err != nil {
+			return nil, fmt.Errorf("failed to decode check-in options: %w", err)
+		}
+	}
+	if len(q.Options) == 0 {
+		q.Options = nil
+	}
+	return &q, nil
+}
@@ -0,0 +1,416 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const (
+	// complianceRecoveryRecentDays is compared against the baseline window
+	// that precedes it when looking for a recovery drop.
+	complianceRecoveryRecentDays    = 3
+	complianceRecoveryBaselineDays  = 14
+	complianceNutritionLookbackDays = 30
+	compliancePersonalRecordDays    = 7
+)
+
+const coachAlertColumns = `
+	a.alert_id, a.coach_id, a.client_id, a.workout_profile_id,
+	COALESCE(NULLIF(u.name, ''), u.username, 'Client') AS client_name,
+	a.rule_type, a.severity, a.title, a.message, a.data, a.status, a.acknowledged_at, a.created_at
+`
+
+// CreateCoachAlert stores a new alert. It returns false when an alert with the
+// same dedupe key already exists for the coach and client.
+func (s *Store) CreateCoachAlert(ctx context.Context, alert *types.CoachAlert) (bool, error) {
+	data, err := json.Marshal(alert.Data)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode alert data: %w", err)
+	}
+
+	query := `
+		INSERT INTO coach_alerts (coach_id, client_id, workout_profile_id, rule_type, severity, title, message, data, dedupe_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (coach_id, client_id, dedupe_key) DO NOTHING
+		RETURNING alert_id, status, created_at
+	`
+
+	err = s.db.QueryRow(ctx, query,
+		alert.CoachID,
+		alert.ClientID,
+		alert.WorkoutProfileID,
+		alert.RuleType,
+		alert.Severity,
+		alert.Title,
+		alert.Message,
+		data,
+		alert.DedupeKey,
+	).Scan(&alert.AlertID, &alert.Status, &alert.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListCoachAlerts returns the coach's alerts with the given status (all when
+// empty), newest first.
+func (s *Store) ListCoachAlerts(ctx context.Context, coachID string, status types.CoachAlertStatus, limit, offset int) ([]types.CoachAlert, error) {
+	query := `SELECT ` + coachAlertColumns + `
+		FROM coach_alerts a
+		LEFT JOIN users u ON u.id = a.client_id
+		WHERE a.coach_id = $1 AND ($2 = '' OR a.status = $2)
+		ORDER BY a.created_at DESC, a.alert_id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := s.db.Query(ctx, query, coachID, string(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []types.CoachAlert{}
+	for rows.Next() {
+		alert, err := scanCoachAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *alert)
+	}
+	return alerts, rows.Err()
+}
+
+func (s *Store) CountOpenCoachAlerts(ctx context.Context, coachID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM coach_alerts WHERE coach_id = $1 AND status = 'open'`,
+		coachID,
+	).Scan(&count)
+	return count, err
+}
+
+// AcknowledgeCoachAlert marks one of the coach's alerts as handled. Acknowledging
+// twice keeps the original acknowledgement time.
+func (s *Store) AcknowledgeCoachAlert(ctx context.Context, coachID string, alertID int64) (*types.CoachAlert, error) {
+	query := `
+		WITH updated AS (
+			UPDATE coach_alerts
+			SET status = 'acknowledged', acknowledged_at = COALESCE(acknowledged_at, NOW())
+			WHERE alert_id = $1 AND coach_id = $2
+			RETURNING *
+		)
+		SELECT ` + coachAlertColumns + `
+		FROM updated a
+		LEFT JOIN users u ON u.id = a.client_id`
+
+	alert, err := scanCoachAlert(s.db.QueryRow(ctx, query, alertID, coachID))
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrCoachAlertNotFound
+	}
+	return alert, err
+}
+
+func (s *Store) AcknowledgeAllCoachAlerts(ctx context.Context, coachID string) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE coach_alerts
+		SET status = 'acknowledged', acknowledged_at = NOW()
+		WHERE coach_id = $1 AND status = 'open'`,
+		coachID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetCoachAlertRules returns the rules the coach has changed; the service fills
+// in defaults for the rest.
+func (s *Store) GetCoachAlertRules(ctx context.Context, coachID string) ([]types.CoachAlertRule, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT rule_type, enabled, threshold, push
+		FROM coach_alert_rules
+		WHERE coach_id = $1`,
+		coachID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []types.CoachAlertRule
+	for rows.Next() {
+		var rule types.CoachAlertRule
+		if err := rows.Scan(&rule.RuleType, &rule.Enabled, &rule.Threshold, &rule.Push); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (s *Store) UpsertCoachAlertRule(ctx context.Context, coachID string, rule types.CoachAlertRule) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO coach_alert_rules (coach_id, rule_type, enabled, threshold, push)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (coach_id, rule_type) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			threshold = EXCLUDED.threshold,
+			push = EXCLUDED.push,
+			updated_at = NOW()`,
+		coachID, rule.RuleType, rule.Enabled, rule.Threshold, rule.Push,
+	)
+	return err
+}
+
+func (s *Store) ListCoachesWithActiveClients(ctx context.Context) ([]string, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT DISTINCT coach_id
+		FROM coach_assignments
+		WHERE is_active = TRUE
+		ORDER BY coach_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var coachIDs []string
+	for rows.Next() {
+		var coachID string
+		if err := rows.Scan(&coachID); err != nil {
+			return nil, err
+		}
+		coachIDs = append(coachIDs, coachID)
+	}
+	return coachIDs, rows.Err()
+}
+
+// GetComplianceSnapshots gathers the activity the alert rules evaluate for each
+// of the coach's active clients, as of now.
+func (s *Store) GetComplianceSnapshots(ctx context.Context, coachID string, now time.Time) ([]types.ClientComplianceSnapshot, error) {
+	query := `
+		SELECT
+			wp.auth_user_id,
+			wp.workout_profile_id,
+			COALESCE(NULLIF(u.name, ''), u.username, 'Client') AS client_name,
+			ca.assigned_at,
+			(
+				SELECT MAX(COALESCE(ws.end_time, ws.start_time))
+				FROM workout_sessions ws
+				WHERE ws.user_id = wp.auth_user_id AND ws.status = 'completed'
+			) AS last_workout_at,
+			(
+				SELECT MAX(f.log_date)
+				FROM food_log_entries f
+				WHERE f.user_id = wp.auth_user_id AND f.log_date >= $2::date - $3::int
+			) AS last_food_log_date,
+			(
+				SELECT COUNT(DISTINCT f.log_date)
+				FROM food_log_entries f
+				WHERE f.user_id = wp.auth_user_id AND f.log_date >= $2::date - $3::int
+			) AS food_log_days
+		FROM coach_assignments ca
+		JOIN workout_profiles wp ON wp.workout_profile_id = ca.user_id
+		LEFT JOIN users u ON u.id = wp.auth_user_id
+		WHERE ca.coach_id = $1 AND ca.is_active = TRUE
+		ORDER BY ca.assigned_at
+	`
+
+	rows, err := s.db.Query(ctx, query, coachID, now, complianceNutritionLookbackDays)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []types.ClientComplianceSnapshot
+	for rows.Next() {
+		var snap types.ClientComplianceSnapshot
+		if err := rows.Scan(
+			&snap.ClientID,
+			&snap.WorkoutProfileID,
+			&snap.ClientName,
+			&snap.AssignedAt,
+			&snap.LastWorkoutAt,
+			&snap.LastFoodLogDate,
+			&snap.FoodLogDays,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		snapshots = append(snapshots, snap)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range snapshots {
+		snap := &snapshots[i]
+		if snap.WeeklyCompletion, err = s.getWeeklyCompletion(ctx, snap.ClientID, now); err != nil {
+			return nil, fmt.Errorf("weekly completion for %s: %w", snap.ClientID, err)
+		}
+		if snap.RecentRecoveryScore, snap.BaselineRecoveryScore, err = s.getRecoveryScores(ctx, snap.ClientID, now); err != nil {
+			return nil, fmt.Errorf("recovery scores for %s: %w", snap.ClientID, err)
+		}
+		if snap.PersonalRecords, err = s.getRecentPersonalRecords(ctx, snap.ClientID, now); err != nil {
+			return nil, fmt.Errorf("personal records for %s: %w", snap.ClientID, err)
+		}
+	}
+
+	return snapshots, nil
+}
+
+// getWeeklyCompletion compares the workouts in the client's active schema with
+// completed sessions for the last two full weeks (Monday to Sunday).
+func (s *Store) getWeeklyCompletion(ctx context.Context, authUserID string, now time.Time) ([]types.WeeklyCompletion, error) {
+	query := `
+		SELECT
+			wk.week_start,
+			(
+				SELECT COUNT(*)
+				FROM workouts w
+				WHERE w.schema_id = (
+					SELECT MAX(s.schema_id)
+					FROM weekly_schemas s
+					WHERE s.user_id = $1 AND s.active = TRUE AND s.week_start < wk.week_start + INTERVAL '7 days'
+				)
+			) AS planned,
+			(
+				SELECT COUNT(*)
+				FROM workout_sessions ws
+				WHERE ws.user_id = $1 AND ws.status = 'completed'
+				  AND ws.start_time >= wk.week_start AND ws.start_time < wk.week_start + INTERVAL '7 days'
+			) AS completed
+		FROM generate_series(
+			date_trunc('week', $2::timestamptz) - INTERVAL '14 days',
+			date_trunc('week', $2::timestamptz) - INTERVAL '7 days',
+			INTERVAL '7 days'
+		) AS wk(week_start)
+		ORDER BY wk.week_start DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, authUserID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var weeks []types.WeeklyCompletion
+	for rows.Next() {
+		var week types.WeeklyCompletion
+		if err := rows.Scan(&week.WeekStart, &week.Planned, &week.Completed); err != nil {
+			return nil, err
+		}
+		weeks = append(weeks, week)
+	}
+	return weeks, rows.Err()
+}
+
+// getRecoveryScores scores the last few days of recovery check-ins against the
+// two weeks before them. Either score is nil when there are no check-ins in its
+// window; missing fields within a check-in count as neutral.
+func (s *Store) getRecoveryScores(ctx context.Context, authUserID string, now time.Time) (*float64, *float64, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(AVG(sleep_hours), 7),
+			COALESCE(AVG(sleep_quality), 5),
+			COALESCE(AVG(stress_level), 5),
+			COALESCE(AVG(energy_level), 5),
+			COALESCE(AVG(soreness), 5)
+		FROM recovery_metrics
+		WHERE user_id = $1 AND date > $2::date - $3::int AND date <= $2::date - $4::int
+	`
+
+	score := func(fromDaysAgo, toDaysAgo int) (*float64, error) {
+		var count int
+		var sleep, sleepQuality, stress, energy, soreness float64
+		err := s.db.QueryRow(ctx, query, authUserID, now, fromDaysAgo, toDaysAgo).
+			Scan(&count, &sleep, &sleepQuality, &stress, &energy, &soreness)
+		if err != nil || count == 0 {
+			return nil, err
+		}
+		value := s.calculateRecoveryScore(sleep, sleepQuality, stress, energy, soreness)
+		return &value, nil
+	}
+
+	recent, err := score(complianceRecoveryRecentDays, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseline, err := score(complianceRecoveryRecentDays+complianceRecoveryBaselineDays, complianceRecoveryRecentDays)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recent, baseline, nil
+}
+
+// getRecentPersonalRecords finds logged weights from the last week that beat
+// every earlier log for the same exercise. First-ever logs are not records.
+func (s *Store) getRecentPersonalRecords(ctx context.Context, authUserID string, now time.Time) ([]types.PersonalRecordEvent, error) {
+	query := `
+		SELECT pl.exercise_id, e.name, pl.weight_used, prev.best, pl.date
+		FROM progress_logs pl
+		JOIN exercises e ON e.exercise_id = pl.exercise_id
+		JOIN LATERAL (
+			SELECT MAX(p2.weight_used) AS best
+			FROM progress_logs p2
+			WHERE p2.user_id = pl.user_id AND p2.exercise_id = pl.exercise_id AND p2.date < pl.date
+		) prev ON TRUE
+		WHERE pl.user_id = $1
+		  AND pl.date > $2::date - $3::int
+		  AND prev.best IS NOT NULL
+		  AND pl.weight_used > prev.best
+		ORDER BY pl.date DESC, pl.weight_used DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, authUserID, now, compliancePersonalRecordDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []types.PersonalRecordEvent
+	for rows.Next() {
+		var record types.PersonalRecordEvent
+		if err := rows.Scan(&record.ExerciseID, &record.ExerciseName, &record.Weight, &record.PreviousBest, &record.Date); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func scanCoachAlert(row pgx.Row) (*types.CoachAlert, error) {
+	var alert types.CoachAlert
+	var data []byte
+	err := row.Scan(
+		&alert.AlertID,
+		&alert.CoachID,
+		&alert.ClientID,
+		&alert.WorkoutProfileID,
+		&alert.ClientName,
+		&alert.RuleType,
+		&alert.Severity,
+		&alert.Title,
+		&alert.Message,
+		&data,
+		&alert.Status,
+		&alert.AcknowledgedAt,
+		&alert.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &alert.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode alert data: %w", err)
+		}
+	}
+	return &alert, nil
+}
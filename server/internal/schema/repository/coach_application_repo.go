@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
+)
+
+const coachApplicationColumns = `
+	application_id, user_id, status, credentials, experience_years, specialties,
+	certifications, documents, review_reason, reviewed_by, reviewed_at, created_at, updated_at
+`
+
+func (s *Store) CreateCoachApplication(ctx context.Context, app *types.CoachApplication) error {
+	certifications, err := json.Marshal(app.Certifications)
+	if err != nil {
+		return fmt.Errorf("failed to encode certifications: %w", err)
+	}
+	documents, err := json.Marshal(app.Documents)
+	if err != nil {
+		return fmt.Errorf("failed to encode documents: %w", err)
+	}
+
+	query := `
+		INSERT INTO coach_applications (user_id, credentials, experience_years, specialties, certifications, documents)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING application_id, status, created_at, updated_at
+	`
+
+	err = s.db.QueryRow(ctx, query,
+		app.UserID,
+		app.Credentials,
+		app.ExperienceYears,
+		app.Specialties,
+		certifications,
+		documents,
+	).Scan(&app.ApplicationID, &app.Status, &app.CreatedAt, &app.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return types.ErrCoachApplicationPending
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Store) GetCoachApplication(ctx context.Context, applicationID int64) (*types.CoachApplication, error) {
+	query := `SELECT ` + coachApplicationColumns + ` FROM coach_applications WHERE application_id = $1`
+
+	app, err := scanCoachApplication(s.db.QueryRow(ctx, query, applicationID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, types.ErrCoachApplicationNotFound
+		}
+		return nil, err
+	}
+	return app, nil
+}
+
+func (s *Store) ListCoachApplicationsByUser(ctx context.Context, userID string) ([]types.CoachApplication, error) {
+	query := `SELECT ` + coachApplicationColumns + `
+		FROM coach_applications
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	return s.queryCoachApplications(ctx, query, userID)
+}
+
+// ListCoachApplications returns applications with the given status (all when empty),
+// oldest first so reviewers work through the queue in order.
+func (s *Store) ListCoachApplications(ctx context.Context, status types.CoachApplicationStatus, limit, offset int) ([]types.CoachApplication, error) {
+	query := `SELECT ` + coachApplicationColumns + `
+		FROM coach_applications
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at ASC
+		LIMIT $2 OFFSET $3`
+
+	return s.queryCoachApplications(ctx, query, string(status), limit, offset)
+}
+
+// ReviewCoachApplication moves a pending application to its final status. It
+// returns ErrCoachApplicationNotPending if someone else already decided it.
+func (s *Store) ReviewCoachApplication(ctx context.Context, applicationID int64, status types.CoachApplicationStatus, reviewerID, reason string) (*types.CoachApplication, error) {
+	query := `
+		UPDATE coach_applications
+		SET status = $2,
+			reviewed_by = NULLIF($3, ''),
+			review_reason = NULLIF($4, ''),
+			reviewed_at = NOW(),
+			updated_at = NOW()
+		WHERE application_id = $1 AND status = 'pending'
+		RETURNING ` + coachApplicationColumns
+
+	app, err := scanCoachApplication(database.Conn(ctx, s.db).QueryRow(ctx, query, applicationID, status, reviewerID, reason))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			if _, getErr := s.GetCoachApplication(ctx, applicationID); getErr != nil {
+				return nil, getErr
+			}
+			return nil, types.ErrCoachApplicationNotPending
+		}
+		return nil, err
+	}
+	return app, nil
+}
+
+func (s *Store) queryCoachApplications(ctx context.Context, query string, args ...interface{}) ([]types.CoachApplication, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apps := []types.CoachApplication{}
+	for rows.Next() {
+		app, err := scanCoachApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, *app)
+	}
+
+	return apps, rows.Err()
+}
+
+func scanCoachApplication(row pgx.Row) (*types.CoachApplication, error) {
+	var app types.CoachApplication
+	var certifications, documents []byte
+
+	err := row.Scan(
+		&app.ApplicationID,
+		&app.UserID,
+		&app.Status,
+		&app.Credentials,
+		&app.ExperienceYears,
+		&app.Specialties,
+		&certifications,
+		&documents,
+		&app.ReviewReason,
+		&app.ReviewedBy,
+		&app.ReviewedAt,
+		&app.CreatedAt,
+		&app.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(certifications, &app.Certifications); err != nil {
+		return nil, fmt.Errorf("failed to decode certifications: %w", err)
+	}
+	if err := json.Unmarshal(documents, &app.Documents); err != nil {
+		return nil, fmt.Errorf("failed to decode documents: %w", err)
+	}
+
+	return &app, nil
+}
@@ -121,9 +121,15 @@ func (s *Store) GetCoachDashboard(ctx context.Context, coachID string) (*types.C
 		return nil, err
 	}
 
+	openAlerts, err := s.CountOpenCoachAlerts(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+
 	dashboard := &types.CoachDashboard{
 		CoachID:      coachID,
 		TotalClients: len(clients),
+		OpenAlerts:   openAlerts,
 		Clients:      clients,
 	}
 
@@ -22,6 +22,7 @@ type CoachInvitation struct {
 	CreatedAt        time.Time  `json:"created_at"`
 	AcceptedAt       *time.Time `json:"accepted_at"`
 	AcceptedByUserID *string    `json:"accepted_by_user_id"`
+	EmailQueued      bool       `json:"-"` // email left to the background sender
 }
 
 
@@ -29,8 +30,9 @@ func (s *Store) CreateInvitation(ctx context.Context, inv *CoachInvitation) erro
 	query := `
 		INSERT INTO coach_invitations (
 			id, coach_id, email, first_name, last_name, 
-			invitation_token, status, custom_message, expires_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			invitation_token, status, custom_message, expires_at,
+			email_next_attempt_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $10 THEN NOW() END)
 		RETURNING created_at
 	`
 
@@ -44,6 +46,7 @@ func (s *Store) CreateInvitation(ctx context.Context, inv *CoachInvitation) erro
 		inv.Status,
 		inv.CustomMessage,
 		inv.ExpiresAt,
+		inv.EmailQueued,
 	).Scan(&inv.CreatedAt)
 
 	if err != nil {
@@ -245,3 +248,75 @@ func (s *Store) GetInvitationByCoachAndEmail(ctx context.Context, coachID, email
 	return &inv, nil
 }
 
+// ClaimQueuedInvitationEmails returns pending invitations whose email is due
+// and pushes their next attempt back by lease, so other instances skip them
+// while they are being sent.
+func (s *Store) ClaimQueuedInvitationEmails(ctx context.Context, limit int, lease time.Duration) ([]*CoachInvitation, error) {
+	query := `
+		UPDATE coach_invitations
+		SET email_next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM coach_invitations
+			WHERE email_next_attempt_at <= NOW() AND status = 'pending' AND expires_at > NOW()
+			ORDER BY email_next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id, coach_id, email, first_name, last_name,
+			invitation_token, status, custom_message, expires_at,
+			created_at, accepted_at, accepted_by_user_id
+	`
+
+	rows, err := s.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim invitation emails: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []*CoachInvitation
+	for rows.Next() {
+		var inv CoachInvitation
+		err := rows.Scan(
+			&inv.ID,
+			&inv.CoachID,
+			&inv.Email,
+			&inv.FirstName,
+			&inv.LastName,
+			&inv.InvitationToken,
+			&inv.Status,
+			&inv.CustomMessage,
+			&inv.ExpiresAt,
+			&inv.CreatedAt,
+			&inv.AcceptedAt,
+			&inv.AcceptedByUserID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		inv.EmailQueued = true
+		invitations = append(invitations, &inv)
+	}
+
+	return invitations, rows.Err()
+}
+
+// FinishInvitationEmail records a send attempt. A failed email is retried
+// after retryAfter until it has been tried maxAttempts times.
+func (s *Store) FinishInvitationEmail(ctx context.Context, id string, sent bool, retryAfter time.Duration, maxAttempts int) error {
+	query := `
+		UPDATE coach_invitations
+		SET email_attempts = email_attempts + 1,
+			email_next_attempt_at = CASE
+				WHEN $2 OR email_attempts + 1 >= $4 THEN NULL
+				ELSE NOW() + make_interval(secs => $3)
+			END
+		WHERE id = $1
+	`
+
+	if _, err := s.db.Exec(ctx, query, id, sent, retryAfter.Seconds(), maxAttempts); err != nil {
+		return fmt.Errorf("failed to record invitation email: %w", err)
+	}
+	return nil
+}
+
@@ -0,0 +1,443 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// activeTeammates selects the active members of the team that coach $1 is
+// an active member of, including $1 itself.
+const activeTeammates = `
+	SELECT other.coach_id FROM coach_team_members me
+	JOIN coach_team_members other ON other.team_id = me.team_id AND other.status = 'active'
+	WHERE me.coach_id = $1 AND me.status = 'active'
+`
+
+// isUniqueViolation reports a clash with the one-active-team-per-coach index.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// CreateTeam creates the team with its owner as the first active member.
+func (s *Store) CreateTeam(ctx context.Context, ownerID, name string) (*types.CoachTeam, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var teamID int
+	err = tx.QueryRow(ctx,
+		`INSERT INTO coach_teams (name, owner_id) VALUES ($1, $2) RETURNING team_id`,
+		name, ownerID,
+	).Scan(&teamID)
+	if isUniqueViolation(err) {
+		return nil, types.ErrAlreadyInTeam
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO coach_team_members (team_id, coach_id, role, status, can_edit_schemas, can_message, invited_by, joined_at)
+		VALUES ($1, $2, 'owner', 'active', TRUE, TRUE, $2, NOW())`,
+		teamID, ownerID,
+	)
+	if isUniqueViolation(err) {
+		return nil, types.ErrAlreadyInTeam
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetTeamByCoach(ctx, ownerID)
+}
+
+// GetTeamByCoach returns the team the coach is an active member of, with all
+// members including pending invitations.
+func (s *Store) GetTeamByCoach(ctx context.Context, coachID string) (*types.CoachTeam, error) {
+	var team types.CoachTeam
+	err := s.db.QueryRow(ctx, `
+		SELECT t.team_id, t.name, t.owner_id, t.created_at, t.updated_at
+		FROM coach_teams t
+		JOIN coach_team_members m ON m.team_id = t.team_id
+		WHERE m.coach_id = $1 AND m.status = 'active'`,
+		coachID,
+	).Scan(&team.TeamID, &team.Name, &team.OwnerID, &team.CreatedAt, &team.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT m.team_id, m.coach_id, COALESCE(u.username, ''), COALESCE(u.name, u.username, ''),
+			m.role, m.status, m.can_edit_schemas, m.can_message, m.invited_at, m.joined_at
+		FROM coach_team_members m
+		LEFT JOIN users u ON u.id = m.coach_id
+		WHERE m.team_id = $1
+		ORDER BY m.role = 'owner' DESC, m.status, m.invited_at`,
+		team.TeamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	team.Members = []types.TeamMember{}
+	for rows.Next() {
+		var member types.TeamMember
+		if err := rows.Scan(
+			&member.TeamID, &member.CoachID, &member.Username, &member.Name,
+			&member.Role, &member.Status, &member.CanEditSchemas, &member.CanMessage,
+			&member.InvitedAt, &member.JoinedAt,
+		); err != nil {
+			return nil, err
+		}
+		team.Members = append(team.Members, member)
+	}
+	return &team, rows.Err()
+}
+
+func (s *Store) UpdateTeamName(ctx context.Context, teamID int, name string) error {
+	_, err := s.db.Exec(ctx, `UPDATE coach_teams SET name = $2, updated_at = NOW() WHERE team_id = $1`, teamID, name)
+	return err
+}
+
+func (s *Store) DeleteTeam(ctx context.Context, teamID int) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM coach_teams WHERE team_id = $1`, teamID)
+	return err
+}
+
+// GetCoachIDByUsername resolves a username to a user with coach access.
+func (s *Store) GetCoachIDByUsername(ctx context.Context, username string) (string, error) {
+	var coachID string
+	err := s.db.QueryRow(ctx,
+		`SELECT id FROM users WHERE username = $1 AND role IN ('coach', 'admin')`,
+		username,
+	).Scan(&coachID)
+	if err == pgx.ErrNoRows {
+		return "", types.ErrTeamCoachNotFound
+	}
+	return coachID, err
+}
+
+// InviteTeamMember adds a pending staff member. Inviting a coach again
+// updates the pending invitation; a coach who already joined is reported as
+// ErrAlreadyInTeam.
+func (s *Store) InviteTeamMember(ctx context.Context, member *types.TeamMember, invitedBy string) error {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO coach_team_members (team_id, coach_id, role, status, can_edit_schemas, can_message, invited_by)
+		VALUES ($1, $2, 'staff', 'invited', $3, $4, $5)
+		ON CONFLICT (team_id, coach_id) DO UPDATE
+		SET can_edit_schemas = EXCLUDED.can_edit_schemas,
+			can_message = EXCLUDED.can_message,
+			invited_by = EXCLUDED.invited_by,
+			invited_at = NOW()
+		WHERE coach_team_members.status = 'invited'
+		RETURNING role, status, invited_at`,
+		member.TeamID, member.CoachID, member.CanEditSchemas, member.CanMessage, invitedBy,
+	).Scan(&member.Role, &member.Status, &member.InvitedAt)
+	if err == pgx.ErrNoRows {
+		return types.ErrAlreadyInTeam
+	}
+	return err
+}
+
+// UpdateTeamMember changes a staff member's permissions. The owner's
+// permissions cannot be changed.
+func (s *Store) UpdateTeamMember(ctx context.Context, teamID int, coachID string, canEditSchemas, canMessage bool) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE coach_team_members
+		SET can_edit_schemas = $3, can_message = $4
+		WHERE team_id = $1 AND coach_id = $2 AND role = 'staff'`,
+		teamID, coachID, canEditSchemas, canMessage,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrTeamMemberNotFound
+	}
+	return nil
+}
+
+// RemoveTeamMember removes a staff member or withdraws their invitation.
+// Clients stay assigned to the coach.
+func (s *Store) RemoveTeamMember(ctx context.Context, teamID int, coachID string) error {
+	tag, err := s.db.Exec(ctx,
+		`DELETE FROM coach_team_members WHERE team_id = $1 AND coach_id = $2 AND role = 'staff'`,
+		teamID, coachID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrTeamMemberNotFound
+	}
+	return nil
+}
+
+func (s *Store) ListTeamInvitations(ctx context.Context, coachID string) ([]types.TeamInvitation, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT t.team_id, t.name, t.owner_id, COALESCE(u.name, u.username, ''), m.invited_at
+		FROM coach_team_members m
+		JOIN coach_teams t ON t.team_id = m.team_id
+		LEFT JOIN users u ON u.id = t.owner_id
+		WHERE m.coach_id = $1 AND m.status = 'invited'
+		ORDER BY m.invited_at DESC`,
+		coachID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []types.TeamInvitation{}
+	for rows.Next() {
+		var inv types.TeamInvitation
+		if err := rows.Scan(&inv.TeamID, &inv.TeamName, &inv.OwnerID, &inv.OwnerName, &inv.InvitedAt); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, rows.Err()
+}
+
+func (s *Store) AcceptTeamInvitation(ctx context.Context, teamID int, coachID string) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE coach_team_members
+		SET status = 'active', joined_at = NOW()
+		WHERE team_id = $1 AND coach_id = $2 AND status = 'invited'`,
+		teamID, coachID,
+	)
+	if isUniqueViolation(err) {
+		return types.ErrAlreadyInTeam
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrTeamInvitationNotFound
+	}
+	return nil
+}
+
+func (s *Store) DeclineTeamInvitation(ctx context.Context, teamID int, coachID string) error {
+	tag, err := s.db.Exec(ctx,
+		`DELETE FROM coach_team_members WHERE team_id = $1 AND coach_id = $2 AND status = 'invited'`,
+		teamID, coachID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrTeamInvitationNotFound
+	}
+	return nil
+}
+
+// GetClientCoachAccess returns how coachID relates to the client, or nil when
+// the client has no active coach.
+func (s *Store) GetClientCoachAccess(ctx context.Context, coachID string, userID int) (*types.ClientCoachAccess, error) {
+	var (
+		access types.ClientCoachAccess
+		role   *types.TeamRole
+		edit   *bool
+		msg    *bool
+	)
+	err := s.db.QueryRow(ctx, `
+		SELECT ca.coach_id, ca.coach_id = $1, me.role, me.can_edit_schemas, me.can_message
+		FROM coach_assignments ca
+		LEFT JOIN coach_team_members assigned ON assigned.coach_id = ca.coach_id AND assigned.status = 'active'
+		LEFT JOIN coach_team_members me
+			ON me.team_id = assigned.team_id AND me.coach_id = $1 AND me.status = 'active'
+		WHERE ca.user_id = $2 AND ca.is_active = TRUE
+		ORDER BY ca.coach_id = $1 DESC, me.role IS NOT NULL DESC
+		LIMIT 1`,
+		coachID, userID,
+	).Scan(&access.AssignedCoachID, &access.Assigned, &role, &edit, &msg)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	access.TeamRole = role
+	access.CanEditSchemas = edit != nil && *edit
+	access.CanMessage = msg != nil && *msg
+	return &access, nil
+}
+
+func (s *Store) AreTeammates(ctx context.Context, coachID, otherCoachID string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (`+activeTeammates+` AND other.coach_id = $2)`, coachID, otherCoachID).Scan(&exists)
+	return exists, err
+}
+
+// ListTeamClients returns the active clients of every active team member.
+func (s *Store) ListTeamClients(ctx context.Context, teamID int) ([]types.TeamClient, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT
+			ca.user_id,
+			wp.auth_user_id,
+			COALESCE(NULLIF(split_part(u.name, ' ', 1), ''), u.username, 'Client'),
+			COALESCE(NULLIF(split_part(u.name, ' ', 2), ''), ''),
+			COALESCE(u.email, ''),
+			ca.assigned_at,
+			(
+				SELECT ws.schema_id
+				FROM weekly_schemas ws
+				WHERE ws.user_id = wp.auth_user_id AND ws.active = TRUE
+				ORDER BY ws.schema_id DESC
+				LIMIT 1
+			),
+			COALESCE(wp.level, ''),
+			ca.coach_id,
+			COALESCE(cu.name, cu.username, '')
+		FROM coach_team_members m
+		JOIN coach_assignments ca ON ca.coach_id = m.coach_id AND ca.is_active = TRUE
+		JOIN workout_profiles wp ON ca.user_id = wp.workout_profile_id
+		LEFT JOIN users u ON u.id = wp.auth_user_id
+		LEFT JOIN users cu ON cu.id = ca.coach_id
+		WHERE m.team_id = $1 AND m.status = 'active'
+		ORDER BY ca.assigned_at DESC`,
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []types.TeamClient{}
+	for rows.Next() {
+		var client types.TeamClient
+		if err := rows.Scan(
+			&client.UserID, &client.AuthID, &client.FirstName, &client.LastName, &client.Email,
+			&client.AssignedAt, &client.CurrentSchemaID, &client.FitnessLevel,
+			&client.CoachID, &client.CoachName,
+		); err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+// ReassignClient moves the client's active assignment from one coach to
+// another. An older inactive row for the previous pair would collide with
+// the unique (coach_id, user_id, is_active) constraint, so it is dropped.
+func (s *Store) ReassignClient(ctx context.Context, userID int, fromCoachID, toCoachID, assignedBy string) (*types.CoachAssignment, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM coach_assignments WHERE coach_id = $1 AND user_id = $2 AND is_active = FALSE`,
+		fromCoachID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes *string
+	err = tx.QueryRow(ctx, `
+		UPDATE coach_assignments SET is_active = FALSE, deactivated_at = NOW()
+		WHERE coach_id = $1 AND user_id = $2 AND is_active = TRUE
+		RETURNING notes`,
+		fromCoachID, userID,
+	).Scan(&notes)
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrClientAccessDenied
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var assignment types.CoachAssignment
+	err = tx.QueryRow(ctx, `
+		INSERT INTO coach_assignments (coach_id, user_id, assigned_by, notes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING assignment_id, coach_id, user_id, assigned_at, assigned_by, is_active, notes, deactivated_at`,
+		toCoachID, userID, assignedBy, notes,
+	).Scan(
+		&assignment.AssignmentID,
+		&assignment.CoachID,
+		&assignment.UserID,
+		&assignment.AssignedAt,
+		&assignment.AssignedBy,
+		&assignment.IsActive,
+		&assignment.Notes,
+		&assignment.DeactivatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// GetTeamCoachSummaries returns one row per active member, owner first.
+func (s *Store) GetTeamCoachSummaries(ctx context.Context, teamID int) ([]types.TeamCoachSummary, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT
+			m.coach_id,
+			COALESCE(u.name, u.username, ''),
+			m.role,
+			(SELECT COUNT(*) FROM coach_assignments ca WHERE ca.coach_id = m.coach_id AND ca.is_active = TRUE),
+			(SELECT COUNT(*) FROM coach_alerts a WHERE a.coach_id = m.coach_id AND a.status = 'open'),
+			(
+				SELECT COUNT(*)
+				FROM coach_assignments ca
+				JOIN workout_profiles wp ON wp.workout_profile_id = ca.user_id
+				JOIN workout_sessions ses ON ses.user_id = wp.auth_user_id
+				WHERE ca.coach_id = m.coach_id AND ca.is_active = TRUE
+				  AND ses.status = 'completed' AND ses.start_time >= NOW() - INTERVAL '7 days'
+			),
+			(
+				SELECT COUNT(*)
+				FROM coach_assignments ca
+				JOIN workout_profiles wp ON wp.workout_profile_id = ca.user_id
+				WHERE ca.coach_id = m.coach_id AND ca.is_active = TRUE
+				  AND NOT EXISTS (
+					SELECT 1 FROM weekly_schemas ws WHERE ws.user_id = wp.auth_user_id AND ws.active = TRUE
+				  )
+			)
+		FROM coach_team_members m
+		LEFT JOIN users u ON u.id = m.coach_id
+		WHERE m.team_id = $1 AND m.status = 'active'
+		ORDER BY m.role = 'owner' DESC, m.joined_at`,
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []types.TeamCoachSummary{}
+	for rows.Next() {
+		var summary types.TeamCoachSummary
+		if err := rows.Scan(
+			&summary.CoachID, &summary.Name, &summary.Role, &summary.Clients,
+			&summary.OpenAlerts, &summary.CompletedSessions, &summary.ClientsWithoutPlan,
+		); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
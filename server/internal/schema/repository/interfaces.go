@@ -44,29 +44,33 @@ type ExerciseRepo interface {
 	BulkCreateExercises(ctx context.Context, exercises []types.ExerciseRequest) ([]types.Exercise, error)
 }
 
+// WorkoutTemplateRepo is the template library shared by users and coaches.
+// Owner-scoped writes report ErrTemplateNotFound for other owners' templates.
 type WorkoutTemplateRepo interface {
-	CreateTemplate(ctx context.Context, template *types.WorkoutTemplateRequest) (*types.WorkoutTemplate, error)
+	CreateTemplate(ctx context.Context, template *types.NewTemplate) (*types.WorkoutTemplate, error)
 	GetTemplateByID(ctx context.Context, templateID int) (*types.WorkoutTemplate, error)
-	UpdateTemplate(ctx context.Context, templateID int, template *types.WorkoutTemplateRequest) (*types.WorkoutTemplate, error)
-	DeleteTemplate(ctx context.Context, templateID int) error
+	UpdateTemplate(ctx context.Context, ownerID string, templateID int, req *types.UpdateWorkoutTemplateRequest) (*types.WorkoutTemplate, error)
+	DeleteTemplate(ctx context.Context, ownerID string, templateID int) error
 
-	ListTemplates(ctx context.Context, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
-	FilterTemplates(ctx context.Context, filter types.TemplateFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
-	SearchTemplates(ctx context.Context, query string, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
-
-	GetTemplatesByLevel(ctx context.Context, level types.FitnessLevel) ([]types.WorkoutTemplate, error)
-	GetTemplatesByGoal(ctx context.Context, goal types.FitnessGoal) ([]types.WorkoutTemplate, error)
-	GetRecommendedTemplates(ctx context.Context, userID int, count int) ([]types.WorkoutTemplate, error)
+	// ListTemplates returns the templates in the filter's scope as seen by
+	// viewerID, most recently updated first, with the total count.
+	ListTemplates(ctx context.Context, viewerID string, filter types.TemplateFilter, limit, offset int) ([]types.WorkoutTemplate, int, error)
 	GetPopularTemplates(ctx context.Context, count int) ([]types.WorkoutTemplate, error)
+
+	// GetTemplatesByIDs loads templates in the order of ids, skipping ids that
+	// no longer exist.
+	GetTemplatesByIDs(ctx context.Context, ids []int) ([]types.WorkoutTemplate, error)
+
+	// IsActiveClientOf reports whether userID is coached by coachID, which
+	// grants access to the coach's client-visible templates.
+	IsActiveClientOf(ctx context.Context, userID string, coachID string) (bool, error)
 }
 
 type WeeklySchemaRepo interface {
-	GetTemplatesByCoachID(ctx context.Context, coachID string) ([]types.WorkoutTemplate, error)
 	CreateWeeklySchema(ctx context.Context, schema *types.WeeklySchemaRequest) (*types.WeeklySchema, error)
 	GetWeeklySchemaByID(ctx context.Context, schemaID int) (*types.WeeklySchema, error)
 	UpdateWeeklySchema(ctx context.Context, schemaID int, active bool) (*types.WeeklySchema, error)
 	DeleteWeeklySchema(ctx context.Context, schemaID int) error
-	SaveSchemaAsTemplate(ctx context.Context, schemaID int, templateName string) error
 	GetWeeklySchemasByUserID(ctx context.Context, authUserID string, pagination types.PaginationParams) (*types.PaginatedResponse[types.WeeklySchema], error)
 	GetActiveWeeklySchemaByUserID(ctx context.Context, authUserID string) (*types.WeeklySchema, error)
 	GetWeeklySchemaByUserAndWeek(ctx context.Context, authUserID string, weekStart time.Time) (*types.WeeklySchema, error)
@@ -203,6 +207,8 @@ type CoachAssignmentRepo interface {
 
 	LogCoachActivity(ctx context.Context, activity *types.CoachActivity) error
 	GetCoachActivityLog(ctx context.Context, coachID string, limit int) ([]types.CoachActivity, error)
+
+	GetClientTimeline(ctx context.Context, coachID, clientAuthID string, query types.TimelineQuery, cursor *types.TimelineCursor, limit int) ([]types.TimelineEvent, error)
 }
 
 type UserRoleRepo interface {
@@ -223,12 +229,114 @@ type CoachInvitationRepo interface {
 	ExpireOldInvitations(ctx context.Context) (int64, error)
 	AcceptInvitation(ctx context.Context, id, userID string) error
 	GetInvitationByCoachAndEmail(ctx context.Context, coachID, email string) (*CoachInvitation, error)
+	ClaimQueuedInvitationEmails(ctx context.Context, limit int, lease time.Duration) ([]*CoachInvitation, error)
+	FinishInvitationEmail(ctx context.Context, id string, sent bool, retryAfter time.Duration, maxAttempts int) error
+}
+
+type CoachApplicationRepo interface {
+	CreateCoachApplication(ctx context.Context, app *types.CoachApplication) error
+	GetCoachApplication(ctx context.Context, applicationID int64) (*types.CoachApplication, error)
+	ListCoachApplicationsByUser(ctx context.Context, userID string) ([]types.CoachApplication, error)
+	ListCoachApplications(ctx context.Context, status types.CoachApplicationStatus, limit, offset int) ([]types.CoachApplication, error)
+	ReviewCoachApplication(ctx context.Context, applicationID int64, status types.CoachApplicationStatus, reviewerID, reason string) (*types.CoachApplication, error)
+}
+
+type CoachAlertRepo interface {
+	CreateCoachAlert(ctx context.Context, alert *types.CoachAlert) (bool, error)
+	ListCoachAlerts(ctx context.Context, coachID string, status types.CoachAlertStatus, limit, offset int) ([]types.CoachAlert, error)
+	CountOpenCoachAlerts(ctx context.Context, coachID string) (int, error)
+	AcknowledgeCoachAlert(ctx context.Context, coachID string, alertID int64) (*types.CoachAlert, error)
+	AcknowledgeAllCoachAlerts(ctx context.Context, coachID string) (int64, error)
+
+	GetCoachAlertRules(ctx context.Context, coachID string) ([]types.CoachAlertRule, error)
+	UpsertCoachAlertRule(ctx context.Context, coachID string, rule types.CoachAlertRule) error
+
+	ListCoachesWithActiveClients(ctx context.Context) ([]string, error)
+	GetComplianceSnapshots(ctx context.Context, coachID string, now time.Time) ([]types.ClientComplianceSnapshot, error)
+}
+
+type CheckInRepo interface {
+	CreateCheckInTemplate(ctx context.Context, template *types.CheckInTemplate) error
+	UpdateCheckInTemplate(ctx context.Context, template *types.CheckInTemplate) error
+	GetCheckInTemplate(ctx context.Context, coachID string, templateID int64) (*types.CheckInTemplate, error)
+	ListCheckInTemplates(ctx context.Context, coachID string) ([]types.CheckInTemplate, error)
+	ArchiveCheckInTemplate(ctx context.Context, coachID string, templateID int64) error
+
+	UpsertCheckInSchedule(ctx context.Context, schedule *types.CheckInSchedule) error
+	ListCheckInSchedules(ctx context.Context, coachID, clientID string) ([]types.CheckInSchedule, error)
+	ListActiveCheckInSchedules(ctx context.Context) ([]types.CheckInSchedule, error)
+	DeactivateCheckInSchedule(ctx context.Context, coachID string, scheduleID int64) error
+
+	CreateCheckInSubmission(ctx context.Context, submission *types.CheckInSubmission) (bool, error)
+	GetCheckInSubmission(ctx context.Context, submissionID int64) (*types.CheckInSubmission, error)
+	ListClientCheckIns(ctx context.Context, clientID string, status types.CheckInStatus, limit, offset int) ([]types.CheckInSubmission, error)
+	ListCoachCheckIns(ctx context.Context, coachID, clientID string, status types.CheckInStatus, limit, offset int) ([]types.CheckInSubmission, error)
+	ListCheckInQuestionsForSubmission(ctx context.Context, submissionID, templateID int64) ([]types.CheckInQuestion, error)
+	GetCheckInQuestion(ctx context.Context, questionID int64) (*types.CheckInQuestion, string, error)
+	ListCheckInAnswers(ctx context.Context, submissionID int64) ([]types.CheckInAnswer, error)
+	SaveCheckInAnswers(ctx context.Context, submissionID int64, answers []types.CheckInAnswer) error
+	ReviewCheckInSubmission(ctx context.Context, coachID string, submissionID int64, feedback string) error
+
+	GetCheckInWeekMetrics(ctx context.Context, clientID string, weekStart time.Time) (*types.CheckInWeekMetrics, error)
+	GetCheckInAnswerHistory(ctx context.Context, clientID string, questionID int64, limit int) ([]types.CheckInAnswerPoint, error)
+}
+
+// TemplateMarketplaceRepo holds what users add to public templates: ratings,
+// forks and recorded uses.
+type TemplateMarketplaceRepo interface {
+	// GetMarketplaceStats returns stats for every public template whose name
+	// matches search; an empty search matches all.
+	GetMarketplaceStats(ctx context.Context, search string) ([]types.TemplateStats, error)
+	GetTemplateStats(ctx context.Context, templateID int) (*types.TemplateStats, error)
+
+	// ForkTemplate copies a template and its exercises into ownerID's library
+	// as a private template linked to the original.
+	ForkTemplate(ctx context.Context, ownerID string, templateID int, name string) (*types.WorkoutTemplate, error)
+	RecordTemplateUse(ctx context.Context, templateID int, userID string, schemaID int) error
+
+	UpsertTemplateRating(ctx context.Context, rating *types.TemplateRating) error
+	DeleteTemplateRating(ctx context.Context, templateID int, userID string) error
+	ListTemplateRatings(ctx context.Context, templateID int, limit, offset int) ([]types.TemplateRating, int, error)
+}
+
+// CoachTeamRepo manages coaching teams and resolves a coach's access to
+// clients of their teammates.
+type CoachTeamRepo interface {
+	CreateTeam(ctx context.Context, ownerID, name string) (*types.CoachTeam, error)
+	GetTeamByCoach(ctx context.Context, coachID string) (*types.CoachTeam, error)
+	UpdateTeamName(ctx context.Context, teamID int, name string) error
+	DeleteTeam(ctx context.Context, teamID int) error
+
+	GetCoachIDByUsername(ctx context.Context, username string) (string, error)
+	InviteTeamMember(ctx context.Context, member *types.TeamMember, invitedBy string) error
+	UpdateTeamMember(ctx context.Context, teamID int, coachID string, canEditSchemas, canMessage bool) error
+	RemoveTeamMember(ctx context.Context, teamID int, coachID string) error
+
+	ListTeamInvitations(ctx context.Context, coachID string) ([]types.TeamInvitation, error)
+	AcceptTeamInvitation(ctx context.Context, teamID int, coachID string) error
+	DeclineTeamInvitation(ctx context.Context, teamID int, coachID string) error
+
+	// GetClientCoachAccess returns nil when the client has no active coach.
+	GetClientCoachAccess(ctx context.Context, coachID string, userID int) (*types.ClientCoachAccess, error)
+	AreTeammates(ctx context.Context, coachID, otherCoachID string) (bool, error)
+	ListTeamClients(ctx context.Context, teamID int) ([]types.TeamClient, error)
+	ReassignClient(ctx context.Context, userID int, fromCoachID, toCoachID, assignedBy string) (*types.CoachAssignment, error)
+	GetTeamCoachSummaries(ctx context.Context, teamID int) ([]types.TeamCoachSummary, error)
+}
+
+type ProgressPhotoRepo interface {
+	CreateProgressPhoto(ctx context.Context, photo *types.ProgressPhoto) error
+	GetProgressPhoto(ctx context.Context, photoID int64) (*types.ProgressPhoto, error)
+	ListProgressPhotos(ctx context.Context, userID string, filter types.ProgressPhotoFilter, limit, offset int) ([]types.ProgressPhoto, error)
+	GetClosestProgressPhotos(ctx context.Context, userID string, date time.Time) ([]types.ProgressPhoto, error)
+	DeleteProgressPhoto(ctx context.Context, userID string, photoID int64) (string, error)
 }
 
 type SchemaRepo interface {
 	WorkoutProfiles() WorkoutProfileRepo
 	Exercises() ExerciseRepo
 	Templates() WorkoutTemplateRepo
+	TemplateMarketplace() TemplateMarketplaceRepo
 	Schemas() WeeklySchemaRepo
 	Workouts() WorkoutRepo
 	WorkoutExercises() WorkoutExerciseRepo
@@ -239,5 +347,10 @@ type SchemaRepo interface {
 	CoachAssignments() CoachAssignmentRepo
 	UserRoles() UserRoleRepo
 	CoachInvitations() CoachInvitationRepo
+	CoachApplications() CoachApplicationRepo
+	CoachAlerts() CoachAlertRepo
+	CheckIns() CheckInRepo
+	ProgressPhotos() ProgressPhotoRepo
+	CoachTeams() CoachTeamRepo
 	WithTransaction(ctx context.Context, fn func(context.Context) error) error
 }
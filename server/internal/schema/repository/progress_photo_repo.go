@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const progressPhotoColumns = `
+	photo_id, user_id, pose, taken_on, notes, file_path, content_type, width, height, size_bytes, created_at
+`
+
+func scanProgressPhoto(row pgx.Row) (*types.ProgressPhoto, error) {
+	var photo types.ProgressPhoto
+	err := row.Scan(
+		&photo.PhotoID,
+		&photo.UserID,
+		&photo.Pose,
+		&photo.TakenOn,
+		&photo.Notes,
+		&photo.FilePath,
+		&photo.ContentType,
+		&photo.Width,
+		&photo.Height,
+		&photo.SizeBytes,
+		&photo.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+func (s *Store) CreateProgressPhoto(ctx context.Context, photo *types.ProgressPhoto) error {
+	return s.db.QueryRow(ctx, `
+		INSERT INTO progress_photos (user_id, pose, taken_on, notes, file_path, content_type, width, height, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING photo_id, created_at`,
+		photo.UserID,
+		photo.Pose,
+		photo.TakenOn,
+		photo.Notes,
+		photo.FilePath,
+		photo.ContentType,
+		photo.Width,
+		photo.Height,
+		photo.SizeBytes,
+	).Scan(&photo.PhotoID, &photo.CreatedAt)
+}
+
+func (s *Store) GetProgressPhoto(ctx context.Context, photoID int64) (*types.ProgressPhoto, error) {
+	photo, err := scanProgressPhoto(s.db.QueryRow(ctx, `SELECT `+progressPhotoColumns+` FROM progress_photos WHERE photo_id = $1`, photoID))
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrProgressPhotoNotFound
+	}
+	return photo, err
+}
+
+// ListProgressPhotos returns the user's photos, newest first. From and To are
+// inclusive dates.
+func (s *Store) ListProgressPhotos(ctx context.Context, userID string, filter types.ProgressPhotoFilter, limit, offset int) ([]types.ProgressPhoto, error) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+
+	if filter.Pose != "" {
+		args = append(args, filter.Pose)
+		conditions = append(conditions, fmt.Sprintf("pose = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("taken_on >= $%d::date", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("taken_on <= $%d::date", len(args)))
+	}
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM progress_photos
+		WHERE %s
+		ORDER BY taken_on DESC, photo_id DESC
+		LIMIT $%d OFFSET $%d`,
+		progressPhotoColumns, strings.Join(conditions, " AND "), len(args)-1, len(args),
+	)
+	return s.queryProgressPhotos(ctx, query, args...)
+}
+
+// GetClosestProgressPhotos returns, for each pose, the user's photo taken
+// closest to date. On a tie the earlier photo wins.
+func (s *Store) GetClosestProgressPhotos(ctx context.Context, userID string, date time.Time) ([]types.ProgressPhoto, error) {
+	return s.queryProgressPhotos(ctx, `
+		SELECT DISTINCT ON (pose) `+progressPhotoColumns+`
+		FROM progress_photos
+		WHERE user_id = $1
+		ORDER BY pose, ABS(taken_on - $2::date), taken_on, photo_id DESC`,
+		userID, date,
+	)
+}
+
+// DeleteProgressPhoto removes one of the user's photos and returns its file
+// path so the caller can remove the image.
+func (s *Store) DeleteProgressPhoto(ctx context.Context, userID string, photoID int64) (string, error) {
+	var filePath string
+	err := s.db.QueryRow(ctx, `
+		DELETE FROM progress_photos
+		WHERE photo_id = $1 AND user_id = $2
+		RETURNING file_path`,
+		photoID, userID,
+	).Scan(&filePath)
+	if err == pgx.ErrNoRows {
+		return "", types.ErrProgressPhotoNotFound
+	}
+	return filePath, err
+}
+
+func (s *Store) queryProgressPhotos(ctx context.Context, query string, args ...interface{}) ([]types.ProgressPhoto, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	photos := []types.ProgressPhoto{}
+	for rows.Next() {
+		photo, err := scanProgressPhoto(rows)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, *photo)
+	}
+	return photos, rows.Err()
+}
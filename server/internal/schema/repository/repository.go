@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
 type Store struct {
@@ -28,6 +29,10 @@ func (s *Store) Templates() WorkoutTemplateRepo {
 	return s
 }
 
+func (s *Store) TemplateMarketplace() TemplateMarketplaceRepo {
+	return s
+}
+
 func (s *Store) Schemas() WeeklySchemaRepo {
 	return s
 }
@@ -68,21 +73,32 @@ func (s *Store) CoachInvitations() CoachInvitationRepo {
 	return s
 }
 
-func (s *Store) WorkoutSharing() WorkoutSharingRepo {
+func (s *Store) CoachApplications() CoachApplicationRepo {
 	return s
 }
 
-func (s *Store) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
-	tx, err := s.db.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback(ctx)
+func (s *Store) CoachAlerts() CoachAlertRepo {
+	return s
+}
 
-	err = fn(ctx)
-	if err != nil {
-		return err
-	}
+func (s *Store) CheckIns() CheckInRepo {
+	return s
+}
 
-	return tx.Commit(ctx)
+func (s *Store) ProgressPhotos() ProgressPhotoRepo {
+	return s
+}
+
+func (s *Store) CoachTeams() CoachTeamRepo {
+	return s
+}
+
+func (s *Store) WorkoutSharing() WorkoutSharingRepo {
+	return s
+}
+
+// WithTransaction runs fn in one transaction. Repository calls made with the
+// context fn receives, including those of other modules' stores, take part in it.
+func (s *Store) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return database.InTx(ctx, s.db, fn)
 }
@@ -6,18 +6,20 @@ import (
 	"time"
 
 	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
 func (s *Store) CreateWeeklySchema(ctx context.Context, schema *types.WeeklySchemaRequest) (*types.WeeklySchema, error) {
 	q := `
-		INSERT INTO weekly_schemas (user_id, week_start, active)
-		VALUES ($1, $2, $3)
+		INSERT INTO weekly_schemas (user_id, week_start, active, base_template_id)
+		VALUES ($1, $2, $3, $4)
 		RETURNING schema_id, user_id, week_start, active
 	`
-	row := s.db.QueryRow(ctx, q,
+	row := database.Conn(ctx, s.db).QueryRow(ctx, q,
 		schema.UserID,
 		schema.WeekStart,
 		true,
+		schema.BaseTemplateID,
 	)
 
 	var ws types.WeeklySchema
@@ -186,7 +188,7 @@ func (s *Store) DeactivateAllWeeklySchemasForUser(ctx context.Context, authUserI
 		SET active = false, updated_at = NOW()
 		WHERE user_id = $1
 	`
-	_, err := s.db.Exec(ctx, q, authUserID)
+	_, err := database.Conn(ctx, s.db).Exec(ctx, q, authUserID)
 	return err
 }
 
@@ -246,12 +248,3 @@ func (s *Store) GetWeeklySchemaHistory(ctx context.Context, authUserID string, l
 	}
 	return schemas, nil
 }
-
-func (s *Store) SaveSchemaAsTemplate(ctx context.Context, schemaID int, templateName string) error {
-	q := `
-		INSERT INTO schema_templates (schema_id, template_name, created_at)
-		VALUES ($1, $2, NOW())
-	`
-	_, err := s.db.Exec(ctx, q, schemaID, templateName)
-	return err
-}
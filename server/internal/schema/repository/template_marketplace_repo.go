@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
+)
+
+// templateStatsQuery aggregates ratings, uses, forks and completion per
+// template. A schema counts towards completion once its week is over; its
+// rate is the share of its workouts with a completed session, capped at 1.
+// Callers append the WHERE clause on t.
+const templateStatsQuery = `
+	WITH ratings AS (
+		SELECT template_id, COUNT(*) AS rating_count, AVG(rating)::float8 AS average_rating
+		FROM template_ratings
+		GROUP BY template_id
+	), uses AS (
+		SELECT template_id, COUNT(*) AS use_count, COUNT(DISTINCT user_id) AS user_count
+		FROM template_uses
+		GROUP BY template_id
+	), forks AS (
+		SELECT forked_from_template_id AS template_id, COUNT(*) AS fork_count
+		FROM workout_templates
+		WHERE forked_from_template_id IS NOT NULL
+		GROUP BY forked_from_template_id
+	), schema_weeks AS (
+		SELECT tu.template_id,
+			(SELECT COUNT(*) FROM workouts w WHERE w.schema_id = tu.schema_id) AS planned,
+			(
+				SELECT COUNT(DISTINCT ses.workout_id)
+				FROM workout_sessions ses
+				JOIN workouts w ON w.workout_id = ses.workout_id
+				WHERE w.schema_id = tu.schema_id AND ses.user_id = tu.user_id AND ses.status = 'completed'
+			) AS completed
+		FROM template_uses tu
+		JOIN weekly_schemas ws ON ws.schema_id = tu.schema_id
+		WHERE ws.week_start + 7 <= CURRENT_DATE
+	), completion AS (
+		SELECT template_id, COUNT(*) AS tracked_schemas,
+			AVG(LEAST(completed::float8 / planned, 1)) AS completion_rate
+		FROM schema_weeks
+		WHERE planned > 0
+		GROUP BY template_id
+	)
+	SELECT t.template_id, t.created_at,
+		COALESCE(r.rating_count, 0), COALESCE(r.average_rating, 0),
+		COALESCE(u.use_count, 0), COALESCE(u.user_count, 0),
+		COALESCE(f.fork_count, 0),
+		COALESCE(c.tracked_schemas, 0), c.completion_rate
+	FROM workout_templates t
+	LEFT JOIN ratings r ON r.template_id = t.template_id
+	LEFT JOIN uses u ON u.template_id = t.template_id
+	LEFT JOIN forks f ON f.template_id = t.template_id
+	LEFT JOIN completion c ON c.template_id = t.template_id
+`
+
+func scanTemplateStats(row pgx.Row) (*types.TemplateStats, error) {
+	var stats types.TemplateStats
+	err := row.Scan(
+		&stats.TemplateID,
+		&stats.CreatedAt,
+		&stats.RatingCount,
+		&stats.AverageRating,
+		&stats.UseCount,
+		&stats.UserCount,
+		&stats.ForkCount,
+		&stats.TrackedSchemas,
+		&stats.CompletionRate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (s *Store) GetMarketplaceStats(ctx context.Context, search string) ([]types.TemplateStats, error) {
+	rows, err := s.db.Query(ctx, templateStatsQuery+`
+		WHERE t.visibility = 'public' AND ($1 = '' OR t.name ILIKE '%' || $1 || '%')`,
+		search,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []types.TemplateStats{}
+	for rows.Next() {
+		stat, err := scanTemplateStats(rows)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, *stat)
+	}
+	return stats, rows.Err()
+}
+
+func (s *Store) GetTemplateStats(ctx context.Context, templateID int) (*types.TemplateStats, error) {
+	stats, err := scanTemplateStats(s.db.QueryRow(ctx, templateStatsQuery+`WHERE t.template_id = $1`, templateID))
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrTemplateNotFound
+	}
+	return stats, err
+}
+
+func (s *Store) ForkTemplate(ctx context.Context, ownerID string, templateID int, name string) (*types.WorkoutTemplate, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var forkID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO workout_templates (user_id, name, description, visibility, source, forked_from_template_id)
+		SELECT $1, $3, description, 'private', 'fork', template_id
+		FROM workout_templates
+		WHERE template_id = $2
+		RETURNING template_id`,
+		ownerID, templateID, name,
+	).Scan(&forkID)
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO workout_template_exercises
+			(template_id, day_of_week, focus, position, exercise_id, exercise_name, sets, reps, target_weight, rest_seconds)
+		SELECT $1, day_of_week, focus, position, exercise_id, exercise_name, sets, reps, target_weight, rest_seconds
+		FROM workout_template_exercises
+		WHERE template_id = $2`,
+		forkID, templateID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy template exercises: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetTemplateByID(ctx, forkID)
+}
+
+func (s *Store) RecordTemplateUse(ctx context.Context, templateID int, userID string, schemaID int) error {
+	_, err := database.Conn(ctx, s.db).Exec(ctx, `
+		INSERT INTO template_uses (template_id, user_id, schema_id)
+		VALUES ($1, $2, $3)`,
+		templateID, userID, schemaID,
+	)
+	return err
+}
+
+func (s *Store) UpsertTemplateRating(ctx context.Context, rating *types.TemplateRating) error {
+	return s.db.QueryRow(ctx, `
+		INSERT INTO template_ratings (template_id, user_id, rating, review)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (template_id, user_id) DO UPDATE
+		SET rating = EXCLUDED.rating, review = EXCLUDED.review, updated_at = NOW()
+		RETURNING created_at, updated_at, (SELECT username FROM users WHERE id = $2)`,
+		rating.TemplateID, rating.UserID, rating.Rating, rating.Review,
+	).Scan(&rating.CreatedAt, &rating.UpdatedAt, &rating.Username)
+}
+
+func (s *Store) DeleteTemplateRating(ctx context.Context, templateID int, userID string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM template_ratings WHERE template_id = $1 AND user_id = $2`, templateID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrTemplateRatingNotFound
+	}
+	return nil
+}
+
+// ListTemplateRatings returns the newest ratings first, with the total count.
+func (s *Store) ListTemplateRatings(ctx context.Context, templateID int, limit, offset int) ([]types.TemplateRating, int, error) {
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM template_ratings WHERE template_id = $1`, templateID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT r.template_id, r.user_id, u.username, r.rating, r.review, r.created_at, r.updated_at
+		FROM template_ratings r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.template_id = $1
+		ORDER BY r.updated_at DESC
+		LIMIT $2 OFFSET $3`,
+		templateID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	ratings := []types.TemplateRating{}
+	for rows.Next() {
+		var rating types.TemplateRating
+		if err := rows.Scan(
+			&rating.TemplateID, &rating.UserID, &rating.Username, &rating.Rating,
+			&rating.Review, &rating.CreatedAt, &rating.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		ratings = append(ratings, rating)
+	}
+	return ratings, total, rows.Err()
+}
@@ -2,390 +2,340 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/tdmdh/fit-up-server/internal/schema/types"
 )
 
-func (s *Store) CreateTemplate(ctx context.Context, template *types.WorkoutTemplateRequest) (*types.WorkoutTemplate, error) {
-	q := `
-		INSERT INTO workout_templates (name, description, min_level, max_level, suitable_goals, days_per_week)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING template_id, name, description, min_level, max_level, suitable_goals, days_per_week
-	`
-
-	// Convert suitable goals slice to comma-separated string
-	suitableGoalsStr := ""
-	if len(template.SuitableGoals) > 0 {
-		for i, goal := range template.SuitableGoals {
-			if i > 0 {
-				suitableGoalsStr += ","
-			}
-			suitableGoalsStr += string(goal)
-		}
-	}
-
-	row := s.db.QueryRow(ctx, q,
-		template.Name,
-		template.Description,
-		template.MinLevel,
-		template.MaxLevel,
-		suitableGoalsStr,
-		template.DaysPerWeek,
+const workoutTemplateColumns = `
+	t.template_id, t.user_id, t.name, t.description, t.visibility, t.source, t.source_schema_id,
+	t.forked_from_template_id,
+	(SELECT f.name FROM workout_templates f WHERE f.template_id = t.forked_from_template_id),
+	(SELECT u.username FROM workout_templates f JOIN users u ON u.id = f.user_id WHERE f.template_id = t.forked_from_template_id),
+	t.created_at, t.updated_at
+`
+
+// coachOfViewer selects the coaches actively assigned to the auth user in $1.
+const coachOfViewer = `
+	SELECT ca.coach_id FROM coach_assignments ca
+	JOIN workout_profiles wp ON wp.workout_profile_id = ca.user_id
+	WHERE wp.auth_user_id = $1 AND ca.is_active = TRUE
+`
+
+func scanWorkoutTemplate(row pgx.Row) (*types.WorkoutTemplate, error) {
+	var (
+		template     types.WorkoutTemplate
+		forkedFromID *int
+		forkedFrom   *string
+		forkedFromBy *string
 	)
-
-	var wt types.WorkoutTemplate
 	err := row.Scan(
-		&wt.TemplateID,
-		&wt.Name,
-		&wt.Description,
-		&wt.MinLevel,
-		&wt.MaxLevel,
-		&wt.SuitableGoals,
-		&wt.DaysPerWeek,
+		&template.TemplateID,
+		&template.OwnerID,
+		&template.Name,
+		&template.Description,
+		&template.Visibility,
+		&template.Source,
+		&template.SourceSchemaID,
+		&forkedFromID,
+		&forkedFrom,
+		&forkedFromBy,
+		&template.CreatedAt,
+		&template.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
-
-	return &wt, nil
+	if forkedFromID != nil && forkedFrom != nil {
+		template.ForkedFrom = &types.TemplateAttribution{TemplateID: *forkedFromID, Name: *forkedFrom}
+		if forkedFromBy != nil {
+			template.ForkedFrom.OwnerUsername = *forkedFromBy
+		}
+	}
+	template.Days = []types.TemplateDay{}
+	return &template, nil
 }
 
-func (s *Store) GetTemplateByID(ctx context.Context, templateID int) (*types.WorkoutTemplate, error) {
-	q := `
-		SELECT template_id, name, description, min_level, max_level, suitable_goals, days_per_week
-		FROM workout_templates
-		WHERE template_id = $1
-	`
-	row := s.db.QueryRow(ctx, q, templateID)
-	var wt types.WorkoutTemplate
-	err := row.Scan(
-		&wt.TemplateID,
-		&wt.Name,
-		&wt.Description,
-		&wt.MinLevel,
-		&wt.MaxLevel,
-		&wt.SuitableGoals,
-		&wt.DaysPerWeek,
-	)
+// CreateTemplate stores the template and its exercises. Exercise names are
+// copied from the exercise library.
+func (s *Store) CreateTemplate(ctx context.Context, template *types.NewTemplate) (*types.WorkoutTemplate, error) {
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
 
-	return &wt, nil
-}
-
-func (s *Store) UpdateTemplate(ctx context.Context, templateID int, template *types.WorkoutTemplateRequest) (*types.WorkoutTemplate, error) {
-	q := `
-		UPDATE workout_templates
-		SET name = $1, description = $2, level = $3, goal = $4, duration_minutes = $5, updated_at = NOW()
-		WHERE id = $6
-		RETURNING id, name, description, level, goal, duration_minutes, created_at, updated_at
-	`
-	row := s.db.QueryRow(ctx, q,
-		template.Name,
-		template.DaysPerWeek,
-		template.Description,
-		template.MaxLevel,
-		template.MinLevel,
-		template.SuitableGoals,
-		templateID,
-	)
-	var wt types.WorkoutTemplate
-	err := row.Scan(
-		&wt.TemplateID,
-		&wt.SuitableGoals,
-		&wt.Name,
-		&wt.MinLevel,
-		&wt.MaxLevel,
-		&wt.Description,
-		&wt.DaysPerWeek,
-	)
+	var templateID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO workout_templates (user_id, name, description, visibility, source, source_schema_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING template_id`,
+		template.OwnerID, template.Name, template.Description, template.Visibility, template.Source, template.SourceSchemaID,
+	).Scan(&templateID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &wt, nil
+	if err := insertTemplateDays(ctx, tx, templateID, template.Days); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetTemplateByID(ctx, templateID)
 }
 
-func (s *Store) DeleteTemplate(ctx context.Context, templateID int) error {
-	q := `
-		DELETE FROM workout_templates
-		WHERE id = $1
-	`
-	_, err := s.db.Exec(ctx, q, templateID)
-	return err
+func insertTemplateDays(ctx context.Context, tx pgx.Tx, templateID int, days []types.TemplateDayRequest) error {
+	for _, day := range days {
+		for i, exercise := range day.Exercises {
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO workout_template_exercises
+					(template_id, day_of_week, focus, position, exercise_id, exercise_name, sets, reps, target_weight, rest_seconds)
+				SELECT $1, $2, $3, $4, e.exercise_id, e.name, $6, $7, $8, $9
+				FROM exercises e
+				WHERE e.exercise_id = $5`,
+				templateID, day.DayOfWeek, day.Focus, i+1, exercise.ExerciseID,
+				exercise.Sets, exercise.Reps, exercise.TargetWeight, exercise.RestSeconds,
+			)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return types.ErrTemplateExerciseNotFound
+			}
+		}
+	}
+	return nil
 }
 
-func (s *Store) ListTemplates(ctx context.Context, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
-	q := `
-		SELECT id, name, description, level, goal, duration_minutes, created_at, updated_at
-		FROM workout_templates
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := s.db.Query(ctx, q, pagination.Limit, pagination.Offset)
+func (s *Store) GetTemplateByID(ctx context.Context, templateID int) (*types.WorkoutTemplate, error) {
+	template, err := scanWorkoutTemplate(s.db.QueryRow(ctx,
+		`SELECT `+workoutTemplateColumns+` FROM workout_templates t WHERE t.template_id = $1`, templateID))
+	if err == pgx.ErrNoRows {
+		return nil, types.ErrTemplateNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	defer rows.Close()
-
-	var templates []types.WorkoutTemplate
-	for rows.Next() {
-		var wt types.WorkoutTemplate
-		err := rows.Scan(
-			&wt.TemplateID,
-			&wt.SuitableGoals,
-			&wt.Name,
-			&wt.MinLevel,
-			&wt.MaxLevel,
-			&wt.Description,
-			&wt.DaysPerWeek,
-		)
-		if err != nil {
-			return nil, err
-		}
-		templates = append(templates, wt)
+	templates := []types.WorkoutTemplate{*template}
+	if err := s.loadTemplateDays(ctx, templates); err != nil {
+		return nil, err
 	}
-
-	return &types.PaginatedResponse[types.WorkoutTemplate]{
-		Data:       templates,
-		TotalCount: len(templates),
-		TotalPages: 1,
-		Page:       1,
-		PageSize:   pagination.Limit,
-	}, nil
+	return &templates[0], nil
 }
 
-func (s *Store) FilterTemplates(ctx context.Context, filter types.TemplateFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
-
-	q := `
-		SELECT id, name, description, level, goal, duration_minutes, created_at, updated_at
-		FROM workout_templates
-		WHERE ($1::INT[] IS NULL OR level && $1::INT[])
-		AND ($2::TEXT[] IS NULL OR goal && $2::TEXT[])
-		ORDER BY created_at DESC
-		LIMIT $3 OFFSET $4
-	`
-	rows, err := s.db.Query(ctx, q,
-		filter.Search,
-		filter.Level,
-		filter.Goals,
-		filter.DaysPerWeek,
-		pagination.Limit,
-		pagination.Offset,
-	)
+// UpdateTemplate changes the fields that are set. Days, when given, replace
+// all of the template's exercises.
+func (s *Store) UpdateTemplate(ctx context.Context, ownerID string, templateID int, req *types.UpdateWorkoutTemplateRequest) (*types.WorkoutTemplate, error) {
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
 
-	defer rows.Close()
+	tag, err := tx.Exec(ctx, `
+		UPDATE workout_templates
+		SET name = COALESCE($3, name),
+			description = COALESCE($4, description),
+			visibility = COALESCE($5, visibility),
+			updated_at = NOW()
+		WHERE template_id = $1 AND user_id = $2`,
+		templateID, ownerID, req.Name, req.Description, req.Visibility,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, types.ErrTemplateNotFound
+	}
 
-	var templates []types.WorkoutTemplate
-	for rows.Next() {
-		var wt types.WorkoutTemplate
-		err := rows.Scan(
-			&wt.TemplateID,
-			&wt.SuitableGoals,
-			&wt.Name,
-			&wt.MinLevel,
-			&wt.MaxLevel,
-			&wt.Description,
-			&wt.DaysPerWeek,
-		)
-		if err != nil {
+	if req.Days != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM workout_template_exercises WHERE template_id = $1`, templateID); err != nil {
+			return nil, err
+		}
+		if err := insertTemplateDays(ctx, tx, templateID, req.Days); err != nil {
 			return nil, err
 		}
-		templates = append(templates, wt)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
 	}
 
-	return &types.PaginatedResponse[types.WorkoutTemplate]{
-		Data:       templates,
-		TotalCount: len(templates),
-		TotalPages: 1,
-		Page:       1,
-		PageSize:   pagination.Limit,
-	}, nil
+	return s.GetTemplateByID(ctx, templateID)
 }
 
-func (s *Store) SearchTemplates(ctx context.Context, query string, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
-	q := `
-		SELECT id, name, description, level, goal, duration_minutes, created_at, updated_at
-		FROM workout_templates
-		WHERE name ILIKE '%' || $1 || '%' OR description ILIKE '%' || $1 || '%'
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := s.db.Query(ctx, q, query, pagination.Limit, pagination.Offset)
+func (s *Store) DeleteTemplate(ctx context.Context, ownerID string, templateID int) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM workout_templates WHERE template_id = $1 AND user_id = $2`, templateID, ownerID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return types.ErrTemplateNotFound
 	}
+	return nil
+}
 
-	defer rows.Close()
+func (s *Store) ListTemplates(ctx context.Context, viewerID string, filter types.TemplateFilter, limit, offset int) ([]types.WorkoutTemplate, int, error) {
+	args := []interface{}{viewerID}
+	var conditions []string
+
+	switch filter.Scope {
+	case types.TemplateScopeMine:
+		conditions = append(conditions, "t.user_id = $1")
+	case types.TemplateScopeCoach:
+		conditions = append(conditions, "t.visibility IN ('clients', 'public') AND t.user_id IN ("+coachOfViewer+")")
+	case types.TemplateScopeTeam:
+		conditions = append(conditions, "t.visibility IN ('clients', 'public') AND t.user_id <> $1 AND t.user_id IN ("+activeTeammates+")")
+	case types.TemplateScopePublic:
+		conditions = append(conditions, "t.visibility = 'public'")
+	default:
+		conditions = append(conditions, `(t.user_id = $1 OR t.visibility = 'public'
+			OR (t.visibility = 'clients' AND t.user_id IN (`+coachOfViewer+`))
+			OR (t.visibility = 'clients' AND t.user_id IN (`+activeTeammates+`)))`)
+	}
+	if filter.Search != "" {
+		args = append(args, filter.Search)
+		conditions = append(conditions, fmt.Sprintf("t.name ILIKE '%%' || $%d || '%%'", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
 
-	var templates []types.WorkoutTemplate
-	for rows.Next() {
-		var wt types.WorkoutTemplate
-		err := rows.Scan(
-			&wt.TemplateID,
-			&wt.SuitableGoals,
-			&wt.Name,
-			&wt.MinLevel,
-			&wt.MaxLevel,
-			&wt.Description,
-			&wt.DaysPerWeek,
-		)
-		if err != nil {
-			return nil, err
-		}
-		templates = append(templates, wt)
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM workout_templates t WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
 	}
 
-	return &types.PaginatedResponse[types.WorkoutTemplate]{
-		Data:       templates,
-		TotalCount: len(templates),
-		TotalPages: 1,
-		Page:       1,
-		PageSize:   pagination.Limit,
-	}, nil
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT %s FROM workout_templates t
+		WHERE %s
+		ORDER BY t.updated_at DESC, t.template_id DESC
+		LIMIT $%d OFFSET $%d`,
+		workoutTemplateColumns, where, len(args)-1, len(args),
+	)
+	templates, err := s.queryWorkoutTemplates(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return templates, total, nil
 }
 
-func (s *Store) GetTemplatesByLevel(ctx context.Context, level types.FitnessLevel) ([]types.WorkoutTemplate, error) {
-	q := `
-		SELECT id, name, description, level, goal, duration_minutes, created_at, updated_at
-		FROM workout_templates
-		WHERE $1 = ANY(level)
-		ORDER BY created_at DESC
-	`
-	rows, err := s.db.Query(ctx, q, level)
-	if err != nil {
-		return nil, err
+// GetPopularTemplates ranks public templates by how many users built a
+// schema from them.
+func (s *Store) GetPopularTemplates(ctx context.Context, count int) ([]types.WorkoutTemplate, error) {
+	return s.queryWorkoutTemplates(ctx, `
+		SELECT `+workoutTemplateColumns+`
+		FROM workout_templates t
+		LEFT JOIN template_uses tu ON tu.template_id = t.template_id
+		WHERE t.visibility = 'public'
+		GROUP BY t.template_id
+		ORDER BY COUNT(DISTINCT tu.user_id) DESC, t.updated_at DESC
+		LIMIT $1`,
+		count,
+	)
+}
+
+func (s *Store) GetTemplatesByIDs(ctx context.Context, ids []int) ([]types.WorkoutTemplate, error) {
+	if len(ids) == 0 {
+		return []types.WorkoutTemplate{}, nil
 	}
-	defer rows.Close()
 
-	var templates []types.WorkoutTemplate
-	for rows.Next() {
-		var wt types.WorkoutTemplate
-		err := rows.Scan(
-			&wt.TemplateID,
-			&wt.SuitableGoals,
-			&wt.Name,
-			&wt.MinLevel,
-			&wt.MaxLevel,
-			&wt.Description,
-			&wt.DaysPerWeek,
-		)
-		if err != nil {
-			return nil, err
-		}
-		templates = append(templates, wt)
+	positions := make([]int32, len(ids))
+	for i, id := range ids {
+		positions[i] = int32(id)
 	}
+	return s.queryWorkoutTemplates(ctx, `
+		SELECT `+workoutTemplateColumns+`
+		FROM workout_templates t
+		JOIN unnest($1::int[]) WITH ORDINALITY AS ids(template_id, position) ON ids.template_id = t.template_id
+		ORDER BY ids.position`,
+		positions,
+	)
+}
 
-	return templates, nil
+func (s *Store) IsActiveClientOf(ctx context.Context, userID string, coachID string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (`+coachOfViewer+` AND ca.coach_id = $2)`, userID, coachID).Scan(&exists)
+	return exists, err
 }
 
-func (s *Store) GetTemplatesByGoal(ctx context.Context, goal types.FitnessGoal) ([]types.WorkoutTemplate, error) {
-	q := `
-		SELECT id, name, description, level, goal, duration_minutes, created_at, updated_at
-		FROM workout_templates
-		WHERE $1 = ANY(goal)
-		ORDER BY created_at DESC
-	`
-	rows, err := s.db.Query(ctx, q, goal)
+func (s *Store) queryWorkoutTemplates(ctx context.Context, query string, args ...interface{}) ([]types.WorkoutTemplate, error) {
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var templates []types.WorkoutTemplate
+	templates := []types.WorkoutTemplate{}
 	for rows.Next() {
-		var wt types.WorkoutTemplate
-		err := rows.Scan(
-			&wt.TemplateID,
-			&wt.SuitableGoals,
-			&wt.Name,
-			&wt.MinLevel,
-			&wt.MaxLevel,
-			&wt.Description,
-			&wt.DaysPerWeek,
-		)
+		template, err := scanWorkoutTemplate(rows)
 		if err != nil {
 			return nil, err
 		}
-		templates = append(templates, wt)
+		templates = append(templates, *template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	if err := s.loadTemplateDays(ctx, templates); err != nil {
+		return nil, err
+	}
 	return templates, nil
 }
 
-func (s *Store) GetRecommendedTemplates(ctx context.Context, userID int, count int) ([]types.WorkoutTemplate, error) {
-	q := `
-		SELECT wt.id, wt.name, wt.description, wt.level, wt.goal, wt.duration_minutes, wt.created_at, wt.updated_at
-		FROM workout_templates wt
-		JOIN users u ON u.fitness_level = ANY(wt.level) AND u.fitness_goal = ANY(wt.goal)
-		WHERE u.id = $1
-		ORDER BY wt.created_at DESC
-		LIMIT $2
-	`
-	rows, err := s.db.Query(ctx, q, userID, count)
-	if err != nil {
-		return nil, err
+// loadTemplateDays fills in the days of each template, grouping its exercises
+// by day of the week in order.
+func (s *Store) loadTemplateDays(ctx context.Context, templates []types.WorkoutTemplate) error {
+	if len(templates) == 0 {
+		return nil
 	}
-	defer rows.Close()
 
-	var templates []types.WorkoutTemplate
-	for rows.Next() {
-		var wt types.WorkoutTemplate
-		err := rows.Scan(
-			&wt.TemplateID,
-			&wt.SuitableGoals,
-			&wt.Name,
-			&wt.MinLevel,
-			&wt.MaxLevel,
-			&wt.Description,
-			&wt.DaysPerWeek,
-		)
-		if err != nil {
-			return nil, err
-		}
-		templates = append(templates, wt)
+	index := make(map[int]*types.WorkoutTemplate, len(templates))
+	ids := make([]int32, 0, len(templates))
+	for i := range templates {
+		index[templates[i].TemplateID] = &templates[i]
+		ids = append(ids, int32(templates[i].TemplateID))
 	}
 
-	return templates, nil
-}
-
-func (s *Store) GetPopularTemplates(ctx context.Context, count int) ([]types.WorkoutTemplate, error) {
-	q := `
-		SELECT wt.id, wt.name, wt.description, wt.level, wt.goal, wt.duration_minutes, wt.created_at, wt.updated_at
-		FROM workout_templates wt
-		LEFT JOIN workouts w ON w.template_id = wt.id
-		GROUP BY wt.id
-		ORDER BY COUNT(w.id) DESC
-		LIMIT $1
-	`
-	rows, err := s.db.Query(ctx, q, count)
+	rows, err := s.db.Query(ctx, `
+		SELECT te.template_id, te.day_of_week, te.focus, te.exercise_id, COALESCE(e.name, te.exercise_name),
+			te.sets, te.reps, te.target_weight::float8, te.rest_seconds
+		FROM workout_template_exercises te
+		LEFT JOIN exercises e ON e.exercise_id = te.exercise_id
+		WHERE te.template_id = ANY($1::int[])
+		ORDER BY te.template_id, te.day_of_week, te.position`,
+		ids,
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var templates []types.WorkoutTemplate
 	for rows.Next() {
-		var wt types.WorkoutTemplate
-		err := rows.Scan(
-			&wt.TemplateID,
-			&wt.SuitableGoals,
-			&wt.Name,
-			&wt.MinLevel,
-			&wt.MaxLevel,
-			&wt.Description,
-			&wt.DaysPerWeek,
+		var (
+			templateID, dayOfWeek int
+			focus                 string
+			exercise              types.TemplateExercise
 		)
-		if err != nil {
-			return nil, err
+		if err := rows.Scan(
+			&templateID, &dayOfWeek, &focus, &exercise.ExerciseID, &exercise.ExerciseName,
+			&exercise.Sets, &exercise.Reps, &exercise.TargetWeight, &exercise.RestSeconds,
+		); err != nil {
+			return err
 		}
-		templates = append(templates, wt)
-	}
 
-	return templates, nil
+		template := index[templateID]
+		if n := len(template.Days); n == 0 || template.Days[n-1].DayOfWeek != dayOfWeek {
+			template.Days = append(template.Days, types.TemplateDay{DayOfWeek: dayOfWeek, Focus: focus, Exercises: []types.TemplateExercise{}})
+			template.DaysPerWeek++
+		}
+		day := &template.Days[len(template.Days)-1]
+		day.Exercises = append(day.Exercises, exercise)
+		template.ExerciseCount++
+	}
+	return rows.Err()
 }
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// clientTimelineQuery merges every module's activity for one client into a
+// single stream. $1 is the client's auth user ID and $2 the coach; messages are
+// limited to the conversation between the two. Each branch is skipped unless
+// its type is in $3. Mindfulness notes are left out as they are private to the client.
+const clientTimelineQuery = `
+	SELECT event_type, source_id, occurred_at, data
+	FROM (
+		SELECT
+			'workout'::text AS event_type,
+			ws.session_id::text AS source_id,
+			COALESCE(ws.end_time, ws.start_time) AS occurred_at,
+			jsonb_build_object(
+				'session_id', ws.session_id,
+				'workout_id', ws.workout_id,
+				'focus', w.focus,
+				'completed_exercises', ws.completed_exercises,
+				'total_exercises', ws.total_exercises,
+				'total_volume', ws.total_volume,
+				'duration_minutes', ROUND(EXTRACT(EPOCH FROM ws.end_time - ws.start_time) / 60),
+				'notes', NULLIF(ws.notes, '')
+			) AS data
+		FROM workout_sessions ws
+		LEFT JOIN workouts w ON w.workout_id = ws.workout_id
+		WHERE ws.user_id = $1 AND ws.status = 'completed' AND 'workout' = ANY($3::text[])
+
+		UNION ALL
+
+		SELECT
+			'personal_record',
+			pl.log_id::text,
+			pl.date::timestamptz,
+			jsonb_build_object(
+				'log_id', pl.log_id,
+				'exercise_id', pl.exercise_id,
+				'exercise_name', e.name,
+				'weight', pl.weight_used,
+				'reps', pl.reps_completed,
+				'previous_best', prev.best
+			)
+		FROM progress_logs pl
+		JOIN exercises e ON e.exercise_id = pl.exercise_id
+		JOIN LATERAL (
+			SELECT MAX(p2.weight_used) AS best
+			FROM progress_logs p2
+			WHERE p2.user_id = pl.user_id AND p2.exercise_id = pl.exercise_id AND p2.date < pl.date
+		) prev ON TRUE
+		WHERE pl.user_id = $1 AND prev.best IS NOT NULL AND pl.weight_used > prev.best
+		  AND 'personal_record' = ANY($3::text[])
+
+		UNION ALL
+
+		SELECT
+			'plan_adaptation',
+			pa.adaptation_id::text,
+			pa.adaptation_date,
+			jsonb_build_object(
+				'plan_id', pa.plan_id,
+				'reason', pa.reason,
+				'trigger', pa.trigger,
+				'changes', pa.changes
+			)
+		FROM plan_adaptations pa
+		JOIN generated_plans gp ON gp.plan_id = pa.plan_id
+		WHERE gp.user_id = $1 AND 'plan_adaptation' = ANY($3::text[])
+
+		UNION ALL
+
+		SELECT
+			'recovery_checkin',
+			rm.metric_id::text,
+			COALESCE(rm.created_at, rm.date::timestamptz),
+			jsonb_build_object(
+				'date', rm.date,
+				'sleep_hours', rm.sleep_hours,
+				'sleep_quality', rm.sleep_quality,
+				'stress_level', rm.stress_level,
+				'energy_level', rm.energy_level,
+				'soreness', rm.soreness
+			)
+		FROM recovery_metrics rm
+		WHERE rm.user_id = $1 AND 'recovery_checkin' = ANY($3::text[])
+
+		UNION ALL
+
+		SELECT
+			'nutrition_summary',
+			f.log_date::text,
+			COALESCE(MAX(f.created_at), f.log_date::timestamptz),
+			jsonb_build_object(
+				'date', f.log_date,
+				'entries', COUNT(*),
+				'totals', jsonb_build_object(
+					'calories', SUM(f.calories),
+					'protein', SUM(f.protein),
+					'carbs', SUM(f.carbs),
+					'fat', SUM(f.fat),
+					'fiber', SUM(COALESCE(f.fiber, 0))
+				),
+				'goals', CASE WHEN ng.user_id IS NULL THEN NULL ELSE jsonb_build_object(
+					'calories', ng.calories_goal,
+					'protein', ng.protein_goal,
+					'carbs', ng.carbs_goal,
+					'fat', ng.fat_goal,
+					'fiber', ng.fiber_goal
+				) END
+			)
+		FROM food_log_entries f
+		LEFT JOIN nutrition_goals ng ON ng.user_id = f.user_id
+		WHERE f.user_id = $1 AND 'nutrition_summary' = ANY($3::text[])
+		GROUP BY f.log_date, ng.user_id, ng.calories_goal, ng.protein_goal, ng.carbs_goal, ng.fat_goal, ng.fiber_goal
+
+		UNION ALL
+
+		SELECT
+			'mindfulness_session',
+			ms.session_id::text,
+			ms.completed_at,
+			jsonb_build_object(
+				'session_type', ms.session_type,
+				'duration_seconds', ms.duration_seconds,
+				'mood_before', ms.mood_before,
+				'mood_after', ms.mood_after
+			)
+		FROM mindfulness_sessions ms
+		WHERE ms.user_id = $1 AND 'mindfulness_session' = ANY($3::text[])
+
+		UNION ALL
+
+		SELECT
+			'message',
+			m.message_id::text,
+			m.sent_at,
+			jsonb_build_object(
+				'conversation_id', m.conversation_id,
+				'sender_id', m.sender_id,
+				'from', CASE WHEN m.sender_id = c.coach_id THEN 'coach' ELSE 'client' END,
+				'text', m.message_text
+			)
+		FROM messages m
+		JOIN conversations c ON c.conversation_id = m.conversation_id
+		WHERE c.client_id = $1 AND c.coach_id = $2 AND m.is_deleted IS NOT TRUE
+		  AND 'message' = ANY($3::text[])
+	) AS timeline
+	WHERE occurred_at IS NOT NULL
+	  AND ($4::timestamptz IS NULL OR occurred_at >= $4::timestamptz)
+	  AND ($5::timestamptz IS NULL OR occurred_at < $5::timestamptz)
+	  AND ($6::timestamptz IS NULL OR (occurred_at, event_type, source_id) < ($6::timestamptz, $7::text, $8::text))
+	ORDER BY occurred_at DESC, event_type DESC, source_id DESC
+	LIMIT $9
+`
+
+// GetClientTimeline returns up to limit events for the client, newest first,
+// starting after cursor. Callers must have checked that coachID coaches the client.
+func (s *Store) GetClientTimeline(ctx context.Context, coachID, clientAuthID string, query types.TimelineQuery, cursor *types.TimelineCursor, limit int) ([]types.TimelineEvent, error) {
+	eventTypes := make([]string, len(query.Types))
+	for i, t := range query.Types {
+		eventTypes[i] = string(t)
+	}
+
+	var cursorAt interface{}
+	var cursorType, cursorSource string
+	if cursor != nil {
+		cursorAt = cursor.OccurredAt
+		cursorType = string(cursor.Type)
+		cursorSource = cursor.SourceID
+	}
+
+	rows, err := s.db.Query(ctx, clientTimelineQuery,
+		clientAuthID,
+		coachID,
+		eventTypes,
+		query.From,
+		query.To,
+		cursorAt,
+		cursorType,
+		cursorSource,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []types.TimelineEvent{}
+	for rows.Next() {
+		var event types.TimelineEvent
+		var sourceID string
+		var data []byte
+		if err := rows.Scan(&event.Type, &sourceID, &event.OccurredAt, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &event.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode %s event data: %w", event.Type, err)
+		}
+		event.ID = string(event.Type) + ":" + sourceID
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
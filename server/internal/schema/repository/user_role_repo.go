@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
 // GetUserRole haalt de rol van een gebruiker op uit de cache
@@ -19,7 +21,7 @@ func (s *Store) GetUserRole(ctx context.Context, authUserID string) (types.UserR
 	var role types.UserRole
 	err := s.db.QueryRow(ctx, query, authUserID).Scan(&role)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 			// Als niet in cache, default naar 'user'
 			return types.RoleUser, nil
 		}
@@ -40,7 +42,7 @@ func (s *Store) UpsertUserRole(ctx context.Context, authUserID string, role type
 			last_synced_at = EXCLUDED.last_synced_at
 	`
 
-	_, err := s.db.Exec(ctx, query, authUserID, role, time.Now())
+	_, err := database.Conn(ctx, s.db).Exec(ctx, query, authUserID, role, time.Now())
 	return err
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/database"
 )
 
 func (s *Store) CreateWorkoutExercise(ctx context.Context, workoutExercise *types.WorkoutExerciseRequest) (*types.WorkoutExercise, error) {
@@ -14,7 +15,7 @@ func (s *Store) CreateWorkoutExercise(ctx context.Context, workoutExercise *type
 	`
 
 	var we types.WorkoutExercise
-	err := s.db.QueryRow(ctx, q,
+	err := database.Conn(ctx, s.db).QueryRow(ctx, q,
 		workoutExercise.WorkoutID,
 		workoutExercise.ExerciseID,
 		workoutExercise.Sets,
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	authRepo "github.com/tdmdh/fit-up-server/internal/auth/repository"
+	authService "github.com/tdmdh/fit-up-server/internal/auth/services"
+	authTypes "github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const forcedPasswordResetTTL = 24 * time.Hour
+
+// AdminUserStore is the part of the auth store the admin service works on.
+type AdminUserStore interface {
+	authRepo.UserStore
+	authRepo.AdminStore
+}
+
+// AdminActor identifies who performed an admin action, for the audit log.
+type AdminActor struct {
+	AdminID   string
+	IPAddress string
+}
+
+type AdminUserDetail struct {
+	User           authTypes.User       `json:"user"`
+	CachedRole     types.UserRole       `json:"cached_role"`
+	LinkedAccounts []*authTypes.Account `json:"linked_accounts"`
+	ActiveSessions []authTypes.Session  `json:"active_sessions"`
+}
+
+type AdminService interface {
+	ListUsers(ctx context.Context, filter authTypes.AdminUserFilter) (*authTypes.AdminUserListResponse, error)
+	GetUserDetail(ctx context.Context, userID string) (*AdminUserDetail, error)
+	ChangeRole(ctx context.Context, actor AdminActor, userID string, role types.UserRole) (*authTypes.User, error)
+	SuspendUser(ctx context.Context, actor AdminActor, userID, reason string) error
+	ReactivateUser(ctx context.Context, actor AdminActor, userID string) error
+	ForcePasswordReset(ctx context.Context, actor AdminActor, userID string) error
+	RevokeSessions(ctx context.Context, actor AdminActor, userID string) error
+	ListAuditLog(ctx context.Context, targetUserID string, pagination types.PaginationParams) ([]authTypes.AdminAuditEntry, error)
+	SyncStaleRoles(ctx context.Context, staleAfter time.Duration) (int, error)
+}
+
+type adminService struct {
+	store repository.SchemaRepo
+	users AdminUserStore
+	roles repository.UserRoleRepo
+}
+
+func NewAdminService(users AdminUserStore, store repository.SchemaRepo) AdminService {
+	return &adminService{
+		store: store,
+		users: users,
+		roles: store.UserRoles(),
+	}
+}
+
+func (s *adminService) ListUsers(ctx context.Context, filter authTypes.AdminUserFilter) (*authTypes.AdminUserListResponse, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	users, total, err := s.users.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return &authTypes.AdminUserListResponse{
+		Users:    users,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
+}
+
+func (s *adminService) GetUserDetail(ctx context.Context, userID string) (*AdminUserDetail, error) {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedRole, err := s.roles.GetUserRole(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached role: %w", err)
+	}
+
+	linked := []*authTypes.Account{}
+	if oauthStore, ok := s.users.(authRepo.OAuthStore); ok {
+		accounts, err := oauthStore.GetAccountsByUserID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get linked accounts: %w", err)
+		}
+		linked = accounts
+	}
+
+	sessions, err := s.users.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return &AdminUserDetail{
+		User:           *user,
+		CachedRole:     cachedRole,
+		LinkedAccounts: linked,
+		ActiveSessions: sessions,
+	}, nil
+}
+
+// ChangeRole updates the role in the auth store and the schema role cache in
+// one transaction, so role checks agree immediately instead of after the next sync.
+func (s *adminService) ChangeRole(ctx context.Context, actor AdminActor, userID string, role types.UserRole) (*authTypes.User, error) {
+	if role != types.RoleUser && role != types.RoleCoach && role != types.RoleAdmin {
+		return nil, types.ErrInvalidRole
+	}
+	if userID == actor.AdminID {
+		return nil, types.ErrAdminSelfAction
+	}
+
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := user.Role
+	err = s.store.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.users.UpdateUserRole(ctx, userID, authTypes.UserRole(role)); err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if err := s.roles.UpsertUserRole(ctx, userID, role); err != nil {
+			return fmt.Errorf("failed to sync role cache: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, actor, authTypes.AdminActionChangeRole, userID, map[string]interface{}{
+		"from": previous,
+		"to":   role,
+	})
+
+	user.Role = authTypes.UserRole(role)
+	return user, nil
+}
+
+// SuspendUser blocks sign-in and refresh and signs the user out of every session.
+func (s *adminService) SuspendUser(ctx context.Context, actor AdminActor, userID, reason string) error {
+	if userID == actor.AdminID {
+		return types.ErrAdminSelfAction
+	}
+
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.SuspendedAt != nil {
+		return types.ErrUserAlreadySuspended
+	}
+
+	if err := s.users.SetUserSuspended(ctx, userID, true, reason); err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+	if err := s.users.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.audit(ctx, actor, authTypes.AdminActionSuspend, userID, map[string]interface{}{
+		"reason": reason,
+	})
+	return nil
+}
+
+func (s *adminService) ReactivateUser(ctx context.Context, actor AdminActor, userID string) error {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.SuspendedAt == nil {
+		return types.ErrUserNotSuspended
+	}
+
+	if err := s.users.SetUserSuspended(ctx, userID, false, ""); err != nil {
+		return fmt.Errorf("failed to reactivate user: %w", err)
+	}
+
+	s.audit(ctx, actor, authTypes.AdminActionReactivate, userID, map[string]interface{}{
+		"suspended_at": user.SuspendedAt,
+	})
+	return nil
+}
+
+// ForcePasswordReset signs the user out, blocks sign-in until a new password is
+// set, and emails a reset link.
+func (s *adminService) ForcePasswordReset(ctx context.Context, actor AdminActor, userID string) error {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.SetPasswordResetRequired(ctx, userID, true); err != nil {
+		return fmt.Errorf("failed to require password reset: %w", err)
+	}
+	if err := s.users.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	resetToken, err := authService.CreatePasswordResetToken(user.Email)
+	if err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+	if err := s.users.CreatePasswordResetToken(ctx, user.Email, resetToken.Token, time.Now().Add(forcedPasswordResetTTL)); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	emailSent := true
+	if err := authService.SendPasswordResetEmail(user.Email, resetToken.Token); err != nil {
+		// The user can still request a new link through forgot-password
+		log.Printf("Failed to send forced password reset email to user %s: %v", userID, err)
+		emailSent = false
+	}
+
+	s.audit(ctx, actor, authTypes.AdminActionForcePasswordReset, userID, map[string]interface{}{
+		"email_sent": emailSent,
+	})
+	return nil
+}
+
+func (s *adminService) RevokeSessions(ctx context.Context, actor AdminActor, userID string) error {
+	if _, err := s.loadUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.users.RevokeAllUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.audit(ctx, actor, authTypes.AdminActionRevokeSessions, userID, nil)
+	return nil
+}
+
+func (s *adminService) ListAuditLog(ctx context.Context, targetUserID string, pagination types.PaginationParams) ([]authTypes.AdminAuditEntry, error) {
+	return s.users.ListAdminAuditEntries(ctx, targetUserID, pagination.Limit, pagination.Offset)
+}
+
+// SyncStaleRoles re-reads from the auth store every cached role not synced
+// within staleAfter, and drops entries for users that are gone. It returns how
+// many cache entries it refreshed or removed.
+func (s *adminService) SyncStaleRoles(ctx context.Context, staleAfter time.Duration) (int, error) {
+	stale, err := s.roles.GetStaleRoles(ctx, staleAfter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load stale roles: %w", err)
+	}
+
+	synced := 0
+	for _, cached := range stale {
+		user, err := s.loadUser(ctx, cached.AuthUserID)
+		if err != nil && err != types.ErrUserNotFound {
+			return synced, err
+		}
+
+		if user == nil || user.DeletedAt != nil {
+			err = s.roles.DeleteUserRole(ctx, cached.AuthUserID)
+		} else {
+			err = s.roles.UpsertUserRole(ctx, cached.AuthUserID, cachedRole(user.Role))
+		}
+		if err != nil {
+			return synced, fmt.Errorf("failed to sync role of %s: %w", cached.AuthUserID, err)
+		}
+		synced++
+	}
+	return synced, nil
+}
+
+// cachedRole maps an auth role onto the roles the cache accepts; clients are
+// plain users as far as role checks go.
+func cachedRole(role authTypes.UserRole) types.UserRole {
+	switch role {
+	case authTypes.RoleAdmin:
+		return types.RoleAdmin
+	case authTypes.RoleCoach:
+		return types.RoleCoach
+	default:
+		return types.RoleUser
+	}
+}
+
+func (s *adminService) loadUser(ctx context.Context, userID string) (*authTypes.User, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == authTypes.ErrUserNotFound {
+			return nil, types.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// audit appends to the admin audit log.
+func (s *adminService) audit(ctx context.Context, actor AdminActor, action authTypes.AdminAction, targetUserID string, details map[string]interface{}) {
+	recordAdminAudit(ctx, s.users, actor, action, targetUserID, details)
+}
+
+// recordAdminAudit appends an entry to the admin audit log. The action has
+// already been applied at this point, so a logging failure is reported but
+// does not fail the request.
+func recordAdminAudit(ctx context.Context, store authRepo.AdminStore, actor AdminActor, action authTypes.AdminAction, targetUserID string, details map[string]interface{}) {
+	entry := &authTypes.AdminAuditEntry{
+		AdminID:      actor.AdminID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		IPAddress:    actor.IPAddress,
+	}
+
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			log.Printf("Failed to encode audit details for %s: %v", action, err)
+		} else {
+			entry.Details = raw
+		}
+	}
+
+	if err := store.CreateAdminAuditEntry(ctx, entry); err != nil {
+		log.Printf("AUDIT FAILURE: admin %s action %s on user %s was not recorded: %v", actor.AdminID, action, targetUserID, err)
+	}
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	authTypes "github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// fakeAdminUsers is an in-memory auth store. Methods the admin service does
+// not use are left to the embedded interface and panic if called.
+type fakeAdminUsers struct {
+	AdminUserStore
+
+	users       map[string]*authTypes.User
+	revoked     []string
+	audit       []authTypes.AdminAuditEntry
+	auditErr    error
+	auditLimit  int
+	auditOffset int
+}
+
+func newFakeAdminUsers() *fakeAdminUsers {
+	return &fakeAdminUsers{users: map[string]*authTypes.User{
+		"admin": {ID: "admin", Email: "admin@example.com", Role: authTypes.RoleAdmin},
+		"sam":   {ID: "sam", Email: "sam@example.com", Role: authTypes.RoleClient},
+	}}
+}
+
+func (f *fakeAdminUsers) GetUserByID(ctx context.Context, id string) (*authTypes.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, authTypes.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (f *fakeAdminUsers) UpdateUserRole(ctx context.Context, userID string, role authTypes.UserRole) error {
+	f.users[userID].Role = role
+	return nil
+}
+
+func (f *fakeAdminUsers) SetUserSuspended(ctx context.Context, userID string, suspended bool, reason string) error {
+	if suspended {
+		now := time.Now()
+		f.users[userID].SuspendedAt = &now
+	} else {
+		f.users[userID].SuspendedAt = nil
+	}
+	return nil
+}
+
+func (f *fakeAdminUsers) RevokeAllUserRefreshTokens(ctx context.Context, userID string) error {
+	f.revoked = append(f.revoked, userID)
+	return nil
+}
+
+func (f *fakeAdminUsers) CreateAdminAuditEntry(ctx context.Context, entry *authTypes.AdminAuditEntry) error {
+	if f.auditErr != nil {
+		return f.auditErr
+	}
+	entry.ID = int64(len(f.audit) + 1)
+	f.audit = append(f.audit, *entry)
+	return nil
+}
+
+func (f *fakeAdminUsers) ListAdminAuditEntries(ctx context.Context, targetUserID string, limit, offset int) ([]authTypes.AdminAuditEntry, error) {
+	f.auditLimit, f.auditOffset = limit, offset
+
+	var entries []authTypes.AdminAuditEntry
+	for _, entry := range f.audit {
+		if targetUserID == "" || entry.TargetUserID == targetUserID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// fakeAdminSchema is the schema store side: the role cache and coach
+// applications. Its transactions also cover the linked auth users, as the
+// real stores share a database, and roll all of them back when fn fails.
+type fakeAdminSchema struct {
+	repository.SchemaRepo
+	repository.UserRoleRepo
+	repository.CoachApplicationRepo
+
+	users        *fakeAdminUsers
+	roles        map[string]types.UserRole
+	stale        []types.UserRoleCache
+	applications map[int64]*types.CoachApplication
+	upsertErr    error
+	rollbacks    int
+}
+
+func newAdminFakes() (*fakeAdminUsers, *fakeAdminSchema) {
+	users := newFakeAdminUsers()
+	return users, &fakeAdminSchema{
+		users:        users,
+		roles:        map[string]types.UserRole{"admin": types.RoleAdmin, "sam": types.RoleUser},
+		applications: make(map[int64]*types.CoachApplication),
+	}
+}
+
+func (f *fakeAdminSchema) UserRoles() repository.UserRoleRepo                 { return f }
+func (f *fakeAdminSchema) CoachApplications() repository.CoachApplicationRepo { return f }
+
+func (f *fakeAdminSchema) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	roles := make(map[string]types.UserRole, len(f.roles))
+	for id, role := range f.roles {
+		roles[id] = role
+	}
+	statuses := make(map[int64]types.CoachApplicationStatus, len(f.applications))
+	for id, app := range f.applications {
+		statuses[id] = app.Status
+	}
+	userRoles := make(map[string]authTypes.UserRole, len(f.users.users))
+	for id, user := range f.users.users {
+		userRoles[id] = user.Role
+	}
+
+	if err := fn(ctx); err != nil {
+		f.rollbacks++
+		f.roles = roles
+		for id, status := range statuses {
+			f.applications[id].Status = status
+		}
+		for id, role := range userRoles {
+			f.users.users[id].Role = role
+		}
+		return err
+	}
+	return nil
+}
+
+func (f *fakeAdminSchema) UpsertUserRole(ctx context.Context, authUserID string, role types.UserRole) error {
+	if f.upsertErr != nil {
+		return f.upsertErr
+	}
+	f.roles[authUserID] = role
+	return nil
+}
+
+func (f *fakeAdminSchema) DeleteUserRole(ctx context.Context, authUserID string) error {
+	delete(f.roles, authUserID)
+	return nil
+}
+
+func (f *fakeAdminSchema) GetStaleRoles(ctx context.Context, staleDuration time.Duration) ([]types.UserRoleCache, error) {
+	return f.stale, nil
+}
+
+func (f *fakeAdminSchema) ReviewCoachApplication(ctx context.Context, applicationID int64, status types.CoachApplicationStatus, reviewerID, reason string) (*types.CoachApplication, error) {
+	app, ok := f.applications[applicationID]
+	if !ok {
+		return nil, types.ErrCoachApplicationNotFound
+	}
+	if app.Status != types.CoachApplicationPending {
+		return nil, types.ErrCoachApplicationNotPending
+	}
+	app.Status = status
+	reviewed := *app
+	return &reviewed, nil
+}
+
+var testAdmin = AdminActor{AdminID: "admin", IPAddress: "203.0.113.7"}
+
+func auditDetails(t *testing.T, entry authTypes.AdminAuditEntry) map[string]interface{} {
+	t.Helper()
+
+	var details map[string]interface{}
+	if err := json.Unmarshal(entry.Details, &details); err != nil {
+		t.Fatalf("audit details %q: %v", entry.Details, err)
+	}
+	return details
+}
+
+func TestChangeRoleUpdatesBothStoresAndAudits(t *testing.T) {
+	users, roles := newAdminFakes()
+	service := NewAdminService(users, roles)
+
+	user, err := service.ChangeRole(context.Background(), testAdmin, "sam", types.RoleCoach)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Role != authTypes.RoleCoach || users.users["sam"].Role != authTypes.RoleCoach || roles.roles["sam"] != types.RoleCoach {
+		t.Errorf("role not changed everywhere: returned %s, auth %s, cache %s", user.Role, users.users["sam"].Role, roles.roles["sam"])
+	}
+
+	if len(users.audit) != 1 {
+		t.Fatalf("expected one audit entry, got %+v", users.audit)
+	}
+	entry := users.audit[0]
+	if entry.AdminID != "admin" || entry.Action != authTypes.AdminActionChangeRole || entry.TargetUserID != "sam" || entry.IPAddress != "203.0.113.7" {
+		t.Errorf("unexpected audit entry %+v", entry)
+	}
+	if details := auditDetails(t, entry); details["from"] != "client" || details["to"] != "coach" {
+		t.Errorf("unexpected audit details %v", details)
+	}
+}
+
+func TestChangeRoleRejections(t *testing.T) {
+	users, roles := newAdminFakes()
+	service := NewAdminService(users, roles)
+
+	tests := []struct {
+		name   string
+		userID string
+		role   types.UserRole
+		want   error
+	}{
+		{"unknown role", "sam", "owner", types.ErrInvalidRole},
+		{"own role", "admin", types.RoleUser, types.ErrAdminSelfAction},
+		{"unknown user", "nobody", types.RoleCoach, types.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		if _, err := service.ChangeRole(context.Background(), testAdmin, tt.userID, tt.role); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if len(users.audit) != 0 || roles.roles["admin"] != types.RoleAdmin {
+		t.Errorf("rejected changes should not be applied or audited, got %+v", users.audit)
+	}
+}
+
+func TestChangeRoleRollsBackWhenTheCacheWriteFails(t *testing.T) {
+	users, roles := newAdminFakes()
+	roles.upsertErr = errors.New("connection reset")
+	service := NewAdminService(users, roles)
+
+	if _, err := service.ChangeRole(context.Background(), testAdmin, "sam", types.RoleCoach); !errors.Is(err, roles.upsertErr) {
+		t.Fatalf("err = %v, want the cache write error", err)
+	}
+	if roles.rollbacks != 1 || users.users["sam"].Role != authTypes.RoleClient {
+		t.Errorf("the auth role should be rolled back, got %s after %d rollbacks", users.users["sam"].Role, roles.rollbacks)
+	}
+	if len(users.audit) != 0 {
+		t.Errorf("a failed change should not be audited, got %+v", users.audit)
+	}
+}
+
+func TestSuspendAndReactivateUser(t *testing.T) {
+	users, roles := newAdminFakes()
+	service := NewAdminService(users, roles)
+	ctx := context.Background()
+
+	if err := service.SuspendUser(ctx, testAdmin, "sam", "chargeback"); err != nil {
+		t.Fatal(err)
+	}
+	if users.users["sam"].SuspendedAt == nil {
+		t.Error("user should be suspended")
+	}
+	if len(users.revoked) != 1 || users.revoked[0] != "sam" {
+		t.Errorf("suspending should revoke every session, revoked %v", users.revoked)
+	}
+	if err := service.SuspendUser(ctx, testAdmin, "sam", "again"); err != types.ErrUserAlreadySuspended {
+		t.Errorf("suspending twice: err = %v, want %v", err, types.ErrUserAlreadySuspended)
+	}
+
+	if err := service.ReactivateUser(ctx, testAdmin, "sam"); err != nil {
+		t.Fatal(err)
+	}
+	if users.users["sam"].SuspendedAt != nil {
+		t.Error("user should be active again")
+	}
+	if err := service.ReactivateUser(ctx, testAdmin, "sam"); err != types.ErrUserNotSuspended {
+		t.Errorf("reactivating an active user: err = %v, want %v", err, types.ErrUserNotSuspended)
+	}
+
+	if err := service.SuspendUser(ctx, testAdmin, "admin", "oops"); err != types.ErrAdminSelfAction {
+		t.Errorf("suspending yourself: err = %v, want %v", err, types.ErrAdminSelfAction)
+	}
+
+	if len(users.audit) != 2 || users.audit[0].Action != authTypes.AdminActionSuspend || users.audit[1].Action != authTypes.AdminActionReactivate {
+		t.Fatalf("unexpected audit log %+v", users.audit)
+	}
+	if details := auditDetails(t, users.audit[0]); details["reason"] != "chargeback" {
+		t.Errorf("suspension reason missing from audit, got %v", details)
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	users, roles := newAdminFakes()
+	service := NewAdminService(users, roles)
+
+	if err := service.RevokeSessions(context.Background(), testAdmin, "nobody"); err != types.ErrUserNotFound {
+		t.Errorf("unknown user: err = %v, want %v", err, types.ErrUserNotFound)
+	}
+	if err := service.RevokeSessions(context.Background(), testAdmin, "sam"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users.revoked) != 1 || users.revoked[0] != "sam" {
+		t.Errorf("revoked %v, want sam", users.revoked)
+	}
+	if len(users.audit) != 1 || users.audit[0].Action != authTypes.AdminActionRevokeSessions || users.audit[0].Details != nil {
+		t.Errorf("unexpected audit log %+v", users.audit)
+	}
+}
+
+func TestAdminActionsSucceedWhenTheAuditWriteFails(t *testing.T) {
+	users, roles := newAdminFakes()
+	users.auditErr = errors.New("audit table locked")
+	service := NewAdminService(users, roles)
+
+	if err := service.RevokeSessions(context.Background(), testAdmin, "sam"); err != nil {
+		t.Errorf("an applied action should not fail on the audit write, got %v", err)
+	}
+	if len(users.revoked) != 1 {
+		t.Errorf("sessions should still be revoked, got %v", users.revoked)
+	}
+}
+
+func TestListAuditLog(t *testing.T) {
+	users, roles := newAdminFakes()
+	service := NewAdminService(users, roles)
+	ctx := context.Background()
+
+	if err := service.SuspendUser(ctx, testAdmin, "sam", "spam"); err != nil {
+		t.Fatal(err)
+	}
+	users.users["lee"] = &authTypes.User{ID: "lee"}
+	if err := service.RevokeSessions(ctx, testAdmin, "lee"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := service.ListAuditLog(ctx, "sam", types.PaginationParams{Limit: 10, Offset: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].TargetUserID != "sam" {
+		t.Errorf("expected only sam's entry, got %+v", entries)
+	}
+	if users.auditLimit != 10 || users.auditOffset != 20 {
+		t.Errorf("pagination = %d/%d, want 10/20", users.auditLimit, users.auditOffset)
+	}
+}
+
+func TestSyncStaleRoles(t *testing.T) {
+	users, roles := newAdminFakes()
+	deletedAt := time.Now()
+	users.users["gone"] = &authTypes.User{ID: "gone", Role: authTypes.RoleCoach, DeletedAt: &deletedAt}
+	users.users["coach"] = &authTypes.User{ID: "coach", Role: authTypes.RoleCoach}
+	roles.roles["gone"] = types.RoleCoach
+	roles.roles["missing"] = types.RoleUser
+	roles.roles["coach"] = types.RoleUser
+	roles.roles["sam"] = types.RoleCoach
+	roles.stale = []types.UserRoleCache{{AuthUserID: "gone"}, {AuthUserID: "missing"}, {AuthUserID: "coach"}, {AuthUserID: "sam"}}
+
+	synced, err := NewAdminService(users, roles).SyncStaleRoles(context.Background(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced != 4 {
+		t.Errorf("synced = %d, want 4", synced)
+	}
+
+	if _, ok := roles.roles["gone"]; ok {
+		t.Error("deleted users should be dropped from the cache")
+	}
+	if _, ok := roles.roles["missing"]; ok {
+		t.Error("unknown users should be dropped from the cache")
+	}
+	if roles.roles["coach"] != types.RoleCoach {
+		t.Errorf("coach cached as %s, want coach", roles.roles["coach"])
+	}
+	if roles.roles["sam"] != types.RoleUser {
+		t.Errorf("clients should be cached as plain users, got %s", roles.roles["sam"])
+	}
+}
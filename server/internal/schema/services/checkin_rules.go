@@ -0,0 +1,187 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const (
+	defaultScaleMin = 1.0
+	defaultScaleMax = 10.0
+	maxScaleSteps   = 100
+)
+
+// normalizeCheckInQuestions validates a template's questions in order and
+// clears settings that don't apply to each question's type. Positions follow
+// the order given.
+func normalizeCheckInQuestions(questions []types.CheckInQuestion) error {
+	seen := make(map[int64]bool, len(questions))
+	for i := range questions {
+		q := &questions[i]
+		q.Position = i + 1
+		q.Prompt = strings.TrimSpace(q.Prompt)
+		if q.Prompt == "" {
+			return fmt.Errorf("%w: question %d needs a prompt", types.ErrInvalidCheckInQuestion, q.Position)
+		}
+		if q.QuestionID != 0 {
+			if seen[q.QuestionID] {
+				return fmt.Errorf("%w: question %d is listed twice", types.ErrInvalidCheckInQuestion, q.QuestionID)
+			}
+			seen[q.QuestionID] = true
+		}
+
+		if q.Type != types.CheckInQuestionChoice {
+			q.Options = nil
+			q.AllowMultiple = false
+		}
+		if q.Type != types.CheckInQuestionScale && q.Type != types.CheckInQuestionNumber {
+			q.MinValue, q.MaxValue, q.Unit = nil, nil, nil
+		}
+
+		switch q.Type {
+		case types.CheckInQuestionScale:
+			if q.MinValue == nil {
+				min := defaultScaleMin
+				q.MinValue = &min
+			}
+			if q.MaxValue == nil {
+				max := defaultScaleMax
+				q.MaxValue = &max
+			}
+			min, max := *q.MinValue, *q.MaxValue
+			if min != math.Trunc(min) || max != math.Trunc(max) || min >= max || max-min > maxScaleSteps {
+				return fmt.Errorf("%w: question %d needs a whole-number scale with min below max", types.ErrInvalidCheckInQuestion, q.Position)
+			}
+		case types.CheckInQuestionNumber:
+			if q.MinValue != nil && q.MaxValue != nil && *q.MinValue >= *q.MaxValue {
+				return fmt.Errorf("%w: question %d has min_value above max_value", types.ErrInvalidCheckInQuestion, q.Position)
+			}
+		case types.CheckInQuestionChoice:
+			options := make([]string, 0, len(q.Options))
+			unique := make(map[string]bool, len(q.Options))
+			for _, option := range q.Options {
+				option = strings.TrimSpace(option)
+				key := strings.ToLower(option)
+				if option == "" || unique[key] {
+					return fmt.Errorf("%w: question %d has an empty or repeated option", types.ErrInvalidCheckInQuestion, q.Position)
+				}
+				unique[key] = true
+				options = append(options, option)
+			}
+			if len(options) < 2 {
+				return fmt.Errorf("%w: question %d needs at least two options", types.ErrInvalidCheckInQuestion, q.Position)
+			}
+			q.Options = options
+		}
+	}
+	return nil
+}
+
+// buildCheckInAnswers checks the client's answers against the form and returns
+// them with only the field for each question's type kept. Answers without a
+// value count as unanswered.
+func buildCheckInAnswers(questions []types.CheckInQuestion, answers []types.CheckInAnswer) ([]types.CheckInAnswer, error) {
+	byID := make(map[int64]types.CheckInQuestion, len(questions))
+	for _, q := range questions {
+		byID[q.QuestionID] = q
+	}
+
+	answered := make(map[int64]bool, len(answers))
+	result := make([]types.CheckInAnswer, 0, len(answers))
+	for _, answer := range answers {
+		q, ok := byID[answer.QuestionID]
+		if !ok {
+			return nil, fmt.Errorf("%w: question %d is not part of this check-in", types.ErrInvalidCheckInAnswer, answer.QuestionID)
+		}
+		if answered[q.QuestionID] {
+			return nil, fmt.Errorf("%w: question %d is answered twice", types.ErrInvalidCheckInAnswer, q.QuestionID)
+		}
+
+		value, err := checkInAnswerValue(q, answer)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		answered[q.QuestionID] = true
+		result = append(result, *value)
+	}
+
+	for _, q := range questions {
+		if q.Required && !answered[q.QuestionID] {
+			return nil, fmt.Errorf("%w: %q is required", types.ErrInvalidCheckInAnswer, q.Prompt)
+		}
+	}
+	return result, nil
+}
+
+func checkInAnswerValue(q types.CheckInQuestion, answer types.CheckInAnswer) (*types.CheckInAnswer, error) {
+	value := types.CheckInAnswer{QuestionID: q.QuestionID}
+
+	switch q.Type {
+	case types.CheckInQuestionScale, types.CheckInQuestionNumber:
+		if answer.Number == nil {
+			return nil, nil
+		}
+		n := *answer.Number
+		if math.IsNaN(n) || math.IsInf(n, 0) ||
+			(q.MinValue != nil && n < *q.MinValue) || (q.MaxValue != nil && n > *q.MaxValue) {
+			return nil, fmt.Errorf("%w: %q is out of range", types.ErrInvalidCheckInAnswer, q.Prompt)
+		}
+		if q.Type == types.CheckInQuestionScale && n != math.Trunc(n) {
+			return nil, fmt.Errorf("%w: %q takes a whole number", types.ErrInvalidCheckInAnswer, q.Prompt)
+		}
+		value.Number = &n
+	case types.CheckInQuestionText:
+		if answer.Text == nil || strings.TrimSpace(*answer.Text) == "" {
+			return nil, nil
+		}
+		text := strings.TrimSpace(*answer.Text)
+		value.Text = &text
+	case types.CheckInQuestionChoice:
+		if len(answer.Choices) == 0 {
+			return nil, nil
+		}
+		if len(answer.Choices) > 1 && !q.AllowMultiple {
+			return nil, fmt.Errorf("%w: %q takes a single choice", types.ErrInvalidCheckInAnswer, q.Prompt)
+		}
+		picked := make(map[string]bool, len(answer.Choices))
+		for _, choice := range answer.Choices {
+			if !containsString(q.Options, choice) || picked[choice] {
+				return nil, fmt.Errorf("%w: %q is not a valid choice for %q", types.ErrInvalidCheckInAnswer, choice, q.Prompt)
+			}
+			picked[choice] = true
+		}
+		value.Choices = answer.Choices
+	case types.CheckInQuestionPhoto:
+		if answer.PhotoURL == nil || *answer.PhotoURL == "" {
+			return nil, nil
+		}
+		value.PhotoURL = answer.PhotoURL
+	}
+	return &value, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInWeek returns the Monday starting now's week (UTC) and the date within
+// that week a check-in scheduled for dayOfWeek is due.
+func checkInWeek(now time.Time, dayOfWeek int) (weekStart, dueDate time.Time) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	dueDate = weekStart.AddDate(0, 0, (dayOfWeek+6)%7)
+	return weekStart, dueDate
+}
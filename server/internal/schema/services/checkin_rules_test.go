@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+func TestNormalizeCheckInQuestions(t *testing.T) {
+	unit := "kg"
+	questions := []types.CheckInQuestion{
+		{Type: types.CheckInQuestionNumber, Prompt: " Morning weight ", Required: true, Unit: &unit, Options: []string{"ignored"}},
+		{Type: types.CheckInQuestionScale, Prompt: "Energy this week"},
+		{Type: types.CheckInQuestionChoice, Prompt: "Diet adherence", Options: []string{" Good ", "Okay", "Poor"}, Unit: &unit},
+		{Type: types.CheckInQuestionPhoto, Prompt: "Front photo", AllowMultiple: true},
+	}
+
+	if err := normalizeCheckInQuestions(questions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	weight := questions[0]
+	if weight.Position != 1 || weight.Prompt != "Morning weight" || weight.Options != nil || weight.Unit == nil {
+		t.Errorf("number question not normalised: %+v", weight)
+	}
+	scale := questions[1]
+	if scale.MinValue == nil || *scale.MinValue != defaultScaleMin || scale.MaxValue == nil || *scale.MaxValue != defaultScaleMax {
+		t.Errorf("scale question should default to 1-10: %+v", scale)
+	}
+	choice := questions[2]
+	if choice.Unit != nil || len(choice.Options) != 3 || choice.Options[0] != "Good" {
+		t.Errorf("choice question not normalised: %+v", choice)
+	}
+	if questions[3].AllowMultiple || questions[3].Position != 4 {
+		t.Errorf("photo question not normalised: %+v", questions[3])
+	}
+
+	min, max := 5.0, 5.0
+	invalid := map[string]types.CheckInQuestion{
+		"blank prompt":       {Type: types.CheckInQuestionText, Prompt: "   "},
+		"one option":         {Type: types.CheckInQuestionChoice, Prompt: "Pick", Options: []string{"Only"}},
+		"repeated option":    {Type: types.CheckInQuestionChoice, Prompt: "Pick", Options: []string{"Yes", "yes"}},
+		"empty scale range":  {Type: types.CheckInQuestionScale, Prompt: "Mood", MinValue: &min, MaxValue: &max},
+		"empty number range": {Type: types.CheckInQuestionNumber, Prompt: "Steps", MinValue: &min, MaxValue: &max},
+	}
+	for name, q := range invalid {
+		err := normalizeCheckInQuestions([]types.CheckInQuestion{q})
+		if !errors.Is(err, types.ErrInvalidCheckInQuestion) {
+			t.Errorf("%s: expected invalid question error, got %v", name, err)
+		}
+	}
+
+	duplicate := []types.CheckInQuestion{
+		{QuestionID: 4, Type: types.CheckInQuestionText, Prompt: "Notes"},
+		{QuestionID: 4, Type: types.CheckInQuestionText, Prompt: "Notes again"},
+	}
+	if err := normalizeCheckInQuestions(duplicate); !errors.Is(err, types.ErrInvalidCheckInQuestion) {
+		t.Errorf("expected duplicate question IDs to be rejected, got %v", err)
+	}
+}
+
+func TestBuildCheckInAnswers(t *testing.T) {
+	scaleMin, scaleMax := 1.0, 10.0
+	questions := []types.CheckInQuestion{
+		{QuestionID: 1, Type: types.CheckInQuestionNumber, Prompt: "Weight", Required: true},
+		{QuestionID: 2, Type: types.CheckInQuestionScale, Prompt: "Energy", Required: true, MinValue: &scaleMin, MaxValue: &scaleMax},
+		{QuestionID: 3, Type: types.CheckInQuestionChoice, Prompt: "Adherence", Options: []string{"Good", "Okay", "Poor"}},
+		{QuestionID: 4, Type: types.CheckInQuestionText, Prompt: "Notes"},
+		{QuestionID: 5, Type: types.CheckInQuestionPhoto, Prompt: "Photo"},
+	}
+	num := func(v float64) *float64 { return &v }
+	str := func(v string) *string { return &v }
+
+	answers, err := buildCheckInAnswers(questions, []types.CheckInAnswer{
+		{QuestionID: 1, Number: num(81.4), Text: str("ignored")},
+		{QuestionID: 2, Number: num(7)},
+		{QuestionID: 3, Choices: []string{"Good"}},
+		{QuestionID: 4, Text: str("   ")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(answers) != 3 {
+		t.Fatalf("blank answers should be dropped, got %+v", answers)
+	}
+	if answers[0].Text != nil || answers[0].Number == nil || *answers[0].Number != 81.4 {
+		t.Errorf("number answer should keep only the number: %+v", answers[0])
+	}
+
+	invalid := map[string][]types.CheckInAnswer{
+		"missing required":   {{QuestionID: 1, Number: num(80)}},
+		"unknown question":   {{QuestionID: 1, Number: num(80)}, {QuestionID: 2, Number: num(5)}, {QuestionID: 9, Number: num(1)}},
+		"scale out of range": {{QuestionID: 1, Number: num(80)}, {QuestionID: 2, Number: num(11)}},
+		"fractional scale":   {{QuestionID: 1, Number: num(80)}, {QuestionID: 2, Number: num(6.5)}},
+		"unknown choice":     {{QuestionID: 1, Number: num(80)}, {QuestionID: 2, Number: num(5)}, {QuestionID: 3, Choices: []string{"Great"}}},
+		"several choices":    {{QuestionID: 1, Number: num(80)}, {QuestionID: 2, Number: num(5)}, {QuestionID: 3, Choices: []string{"Good", "Okay"}}},
+		"answered twice":     {{QuestionID: 1, Number: num(80)}, {QuestionID: 1, Number: num(81)}, {QuestionID: 2, Number: num(5)}},
+	}
+	for name, given := range invalid {
+		if _, err := buildCheckInAnswers(questions, given); !errors.Is(err, types.ErrInvalidCheckInAnswer) {
+			t.Errorf("%s: expected invalid answer error, got %v", name, err)
+		}
+	}
+}
+
+func TestCheckInWeek(t *testing.T) {
+	// Wednesday 12 June 2024
+	now := time.Date(2024, 6, 12, 15, 30, 0, 0, time.UTC)
+	monday := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		dayOfWeek int
+		due       time.Time
+	}{
+		{int(time.Monday), monday},
+		{int(time.Wednesday), time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)},
+		{int(time.Sunday), time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		weekStart, due := checkInWeek(now, tt.dayOfWeek)
+		if !weekStart.Equal(monday) || !due.Equal(tt.due) {
+			t.Errorf("day %d: got week %s due %s", tt.dayOfWeek, weekStart, due)
+		}
+	}
+
+	// A Sunday still belongs to the week that started the previous Monday
+	weekStart, _ := checkInWeek(time.Date(2024, 6, 16, 8, 0, 0, 0, time.UTC), int(time.Monday))
+	if !weekStart.Equal(monday) {
+		t.Errorf("sunday week start = %s", weekStart)
+	}
+}
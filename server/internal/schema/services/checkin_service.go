@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const (
+	NotificationCheckInDue       = "checkin_due"
+	NotificationCheckInSubmitted = "checkin_submitted"
+	NotificationCheckInReviewed  = "checkin_reviewed"
+
+	checkInGenerateInterval = time.Hour
+	checkInHistoryWeeks     = 52
+)
+
+type CheckInService interface {
+	CreateTemplate(ctx context.Context, coachID string, req *types.CheckInTemplateRequest) (*types.CheckInTemplate, error)
+	UpdateTemplate(ctx context.Context, coachID string, templateID int64, req *types.CheckInTemplateRequest) (*types.CheckInTemplate, error)
+	GetTemplate(ctx context.Context, coachID string, templateID int64) (*types.CheckInTemplate, error)
+	ListTemplates(ctx context.Context, coachID string) ([]types.CheckInTemplate, error)
+	ArchiveTemplate(ctx context.Context, coachID string, templateID int64) error
+
+	// Schedules and client history are addressed by workout profile ID, like the other coach client routes.
+	ScheduleForClient(ctx context.Context, coachID string, userID int, req *types.ScheduleCheckInRequest) (*types.CheckInSchedule, error)
+	ListClientSchedules(ctx context.Context, coachID string, userID int) ([]types.CheckInSchedule, error)
+	CancelSchedule(ctx context.Context, coachID string, scheduleID int64) error
+
+	ListCoachCheckIns(ctx context.Context, coachID string, status types.CheckInStatus, pagination types.PaginationParams) ([]types.CheckInSubmission, error)
+	ListClientCheckInsForCoach(ctx context.Context, coachID string, userID int, status types.CheckInStatus, pagination types.PaginationParams) ([]types.CheckInSubmission, error)
+	GetCheckInForCoach(ctx context.Context, coachID string, submissionID int64) (*types.CheckInDetail, error)
+	ReviewCheckIn(ctx context.Context, coachID string, submissionID int64, req *types.ReviewCheckInRequest) (*types.CheckInSubmission, error)
+	GetQuestionHistory(ctx context.Context, coachID string, userID int, questionID int64) (*types.CheckInQuestionHistory, error)
+
+	ListMyCheckIns(ctx context.Context, clientID string, status types.CheckInStatus, pagination types.PaginationParams) ([]types.CheckInSubmission, error)
+	GetMyCheckIn(ctx context.Context, clientID string, submissionID int64) (*types.CheckInDetail, error)
+	SubmitCheckIn(ctx context.Context, clientID string, submissionID int64, req *types.SubmitCheckInRequest) (*types.CheckInDetail, error)
+
+	// GenerateDueCheckIns opens this week's check-in for every schedule whose
+	// due day has arrived and returns how many were opened.
+	GenerateDueCheckIns(ctx context.Context) (int, error)
+
+	SetNotifier(notifier UserNotifier)
+}
+
+type checkInService struct {
+	repo      repository.SchemaRepo
+	validator *validator.Validate
+	notifier  UserNotifier
+	now       func() time.Time
+}
+
+func NewCheckInService(repo repository.SchemaRepo) CheckInService {
+	return &checkInService{
+		repo:      repo,
+		validator: validator.New(),
+		now:       time.Now,
+	}
+}
+
+// SetNotifier enables pushing check-in events to clients and coaches.
+func (s *checkInService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+func (s *checkInService) CreateTemplate(ctx context.Context, coachID string, req *types.CheckInTemplateRequest) (*types.CheckInTemplate, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	for i := range req.Questions {
+		req.Questions[i].QuestionID = 0
+	}
+	if err := normalizeCheckInQuestions(req.Questions); err != nil {
+		return nil, err
+	}
+
+	template := &types.CheckInTemplate{
+		CoachID:     coachID,
+		Name:        req.Name,
+		Description: req.Description,
+		Questions:   req.Questions,
+	}
+	if err := s.repo.CheckIns().CreateCheckInTemplate(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to create check-in template: %w", err)
+	}
+	return template, nil
+}
+
+func (s *checkInService) UpdateTemplate(ctx context.Context, coachID string, templateID int64, req *types.CheckInTemplateRequest) (*types.CheckInTemplate, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	if err := normalizeCheckInQuestions(req.Questions); err != nil {
+		return nil, err
+	}
+
+	template := &types.CheckInTemplate{
+		TemplateID:  templateID,
+		CoachID:     coachID,
+		Name:        req.Name,
+		Description: req.Description,
+		Questions:   req.Questions,
+	}
+	if err := s.repo.CheckIns().UpdateCheckInTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	return s.repo.CheckIns().GetCheckInTemplate(ctx, coachID, templateID)
+}
+
+func (s *checkInService) GetTemplate(ctx context.Context, coachID string, templateID int64) (*types.CheckInTemplate, error) {
+	return s.repo.CheckIns().GetCheckInTemplate(ctx, coachID, templateID)
+}
+
+func (s *checkInService) ListTemplates(ctx context.Context, coachID string) ([]types.CheckInTemplate, error) {
+	return s.repo.CheckIns().ListCheckInTemplates(ctx, coachID)
+}
+
+func (s *checkInService) ArchiveTemplate(ctx context.Context, coachID string, templateID int64) error {
+	return s.repo.CheckIns().ArchiveCheckInTemplate(ctx, coachID, templateID)
+}
+
+// clientAuthID checks that coachID coaches the client and returns the client's auth user ID.
+func (s *checkInService) clientAuthID(ctx context.Context, coachID string, userID int) (string, error) {
+	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check coach permission: %w", err)
+	}
+	if !isCoach {
+		return "", types.ErrClientAccessDenied
+	}
+
+	profile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user profile: %w", err)
+	}
+	return profile.AuthUserID, nil
+}
+
+func (s *checkInService) ScheduleForClient(ctx context.Context, coachID string, userID int, req *types.ScheduleCheckInRequest) (*types.CheckInSchedule, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	clientID, err := s.clientAuthID(ctx, coachID, userID)
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.repo.CheckIns().GetCheckInTemplate(ctx, coachID, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &types.CheckInSchedule{
+		TemplateID:       template.TemplateID,
+		TemplateName:     template.Name,
+		CoachID:          coachID,
+		ClientID:         clientID,
+		WorkoutProfileID: userID,
+		DayOfWeek:        req.DayOfWeek,
+	}
+	if err := s.repo.CheckIns().UpsertCheckInSchedule(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to save check-in schedule: %w", err)
+	}
+
+	// A schedule due today opens straight away instead of on the next scan
+	if _, err := s.openCheckIn(ctx, *schedule, s.now()); err != nil {
+		log.Printf("Failed to open check-in for schedule %d: %v", schedule.ScheduleID, err)
+	}
+
+	return schedule, nil
+}
+
+func (s *checkInService) ListClientSchedules(ctx context.Context, coachID string, userID int) ([]types.CheckInSchedule, error) {
+	clientID, err := s.clientAuthID(ctx, coachID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CheckIns().ListCheckInSchedules(ctx, coachID, clientID)
+}
+
+func (s *checkInService) CancelSchedule(ctx context.Context, coachID string, scheduleID int64) error {
+	return s.repo.CheckIns().DeactivateCheckInSchedule(ctx, coachID, scheduleID)
+}
+
+func (s *checkInService) ListCoachCheckIns(ctx context.Context, coachID string, status types.CheckInStatus, pagination types.PaginationParams) ([]types.CheckInSubmission, error) {
+	return s.repo.CheckIns().ListCoachCheckIns(ctx, coachID, "", status, pagination.Limit, pagination.Offset)
+}
+
+func (s *checkInService) ListClientCheckInsForCoach(ctx context.Context, coachID string, userID int, status types.CheckInStatus, pagination types.PaginationParams) ([]types.CheckInSubmission, error) {
+	clientID, err := s.clientAuthID(ctx, coachID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CheckIns().ListCoachCheckIns(ctx, coachID, clientID, status, pagination.Limit, pagination.Offset)
+}
+
+// GetCheckInForCoach returns the submission with the client's numbers for the
+// check-in week alongside the answers.
+func (s *checkInService) GetCheckInForCoach(ctx context.Context, coachID string, submissionID int64) (*types.CheckInDetail, error) {
+	submission, err := s.repo.CheckIns().GetCheckInSubmission(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.CoachID != coachID {
+		return nil, types.ErrCheckInNotFound
+	}
+
+	detail, err := s.loadDetail(ctx, submission)
+	if err != nil {
+		return nil, err
+	}
+
+	detail.Week, err = s.repo.CheckIns().GetCheckInWeekMetrics(ctx, submission.ClientID, submission.WeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load week metrics: %w", err)
+	}
+	return detail, nil
+}
+
+func (s *checkInService) ReviewCheckIn(ctx context.Context, coachID string, submissionID int64, req *types.ReviewCheckInRequest) (*types.CheckInSubmission, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	submission, err := s.repo.CheckIns().GetCheckInSubmission(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.CoachID != coachID {
+		return nil, types.ErrCheckInNotFound
+	}
+
+	if err := s.repo.CheckIns().ReviewCheckInSubmission(ctx, coachID, submissionID, req.Feedback); err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, submission.ClientID, NotificationCheckInReviewed,
+		"Check-in reviewed",
+		fmt.Sprintf("Your coach reviewed your %s check-in", submission.TemplateName),
+		submission)
+
+	return s.repo.CheckIns().GetCheckInSubmission(ctx, submissionID)
+}
+
+func (s *checkInService) GetQuestionHistory(ctx context.Context, coachID string, userID int, questionID int64) (*types.CheckInQuestionHistory, error) {
+	clientID, err := s.clientAuthID(ctx, coachID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	question, ownerID, err := s.repo.CheckIns().GetCheckInQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != coachID {
+		return nil, types.ErrCheckInQuestionNotFound
+	}
+
+	points, err := s.repo.CheckIns().GetCheckInAnswerHistory(ctx, clientID, questionID, checkInHistoryWeeks)
+	if err != nil {
+		return nil, err
+	}
+	return &types.CheckInQuestionHistory{
+		Question: *question,
+		Points:   points,
+	}, nil
+}
+
+func (s *checkInService) ListMyCheckIns(ctx context.Context, clientID string, status types.CheckInStatus, pagination types.PaginationParams) ([]types.CheckInSubmission, error) {
+	return s.repo.CheckIns().ListClientCheckIns(ctx, clientID, status, pagination.Limit, pagination.Offset)
+}
+
+func (s *checkInService) GetMyCheckIn(ctx context.Context, clientID string, submissionID int64) (*types.CheckInDetail, error) {
+	submission, err := s.repo.CheckIns().GetCheckInSubmission(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.ClientID != clientID {
+		return nil, types.ErrCheckInNotFound
+	}
+	return s.loadDetail(ctx, submission)
+}
+
+// SubmitCheckIn stores the client's answers. Clients may resubmit to correct
+// answers until the coach has reviewed the check-in.
+func (s *checkInService) SubmitCheckIn(ctx context.Context, clientID string, submissionID int64, req *types.SubmitCheckInRequest) (*types.CheckInDetail, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	submission, err := s.repo.CheckIns().GetCheckInSubmission(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.ClientID != clientID {
+		return nil, types.ErrCheckInNotFound
+	}
+	if submission.Status == types.CheckInReviewed {
+		return nil, types.ErrCheckInAlreadyReviewed
+	}
+
+	questions, err := s.repo.CheckIns().ListCheckInQuestionsForSubmission(ctx, submissionID, submission.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := buildCheckInAnswers(questions, req.Answers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CheckIns().SaveCheckInAnswers(ctx, submissionID, answers); err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("%s submitted their %s check-in", submission.ClientName, submission.TemplateName)
+	if submission.Status == types.CheckInSubmitted {
+		body = fmt.Sprintf("%s updated their %s check-in", submission.ClientName, submission.TemplateName)
+	}
+	s.notify(ctx, submission.CoachID, NotificationCheckInSubmitted, "Check-in submitted", body, submission)
+
+	return s.GetMyCheckIn(ctx, clientID, submissionID)
+}
+
+func (s *checkInService) loadDetail(ctx context.Context, submission *types.CheckInSubmission) (*types.CheckInDetail, error) {
+	questions, err := s.repo.CheckIns().ListCheckInQuestionsForSubmission(ctx, submission.SubmissionID, submission.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := s.repo.CheckIns().ListCheckInAnswers(ctx, submission.SubmissionID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.CheckInDetail{
+		Submission: *submission,
+		Questions:  questions,
+		Answers:    answers,
+	}, nil
+}
+
+func (s *checkInService) GenerateDueCheckIns(ctx context.Context) (int, error) {
+	schedules, err := s.repo.CheckIns().ListActiveCheckInSchedules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	opened := 0
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			return opened, ctx.Err()
+		}
+		isNew, err := s.openCheckIn(ctx, schedule, now)
+		if err != nil {
+			log.Printf("Failed to open check-in for schedule %d: %v", schedule.ScheduleID, err)
+			continue
+		}
+		if isNew {
+			opened++
+		}
+	}
+	return opened, nil
+}
+
+// openCheckIn creates this week's check-in once its due day has arrived. A
+// schedule created after this week's due day starts the following week.
+func (s *checkInService) openCheckIn(ctx context.Context, schedule types.CheckInSchedule, now time.Time) (bool, error) {
+	weekStart, dueDate := checkInWeek(now, schedule.DayOfWeek)
+	if now.Before(dueDate) {
+		return false, nil
+	}
+	created := schedule.CreatedAt.UTC()
+	if dueDate.Before(time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)) {
+		return false, nil
+	}
+
+	submission := &types.CheckInSubmission{
+		ScheduleID:       &schedule.ScheduleID,
+		TemplateID:       schedule.TemplateID,
+		TemplateName:     schedule.TemplateName,
+		CoachID:          schedule.CoachID,
+		ClientID:         schedule.ClientID,
+		WorkoutProfileID: schedule.WorkoutProfileID,
+		WeekStart:        weekStart,
+		DueDate:          dueDate,
+	}
+	isNew, err := s.repo.CheckIns().CreateCheckInSubmission(ctx, submission)
+	if err != nil || !isNew {
+		return false, err
+	}
+
+	s.notify(ctx, schedule.ClientID, NotificationCheckInDue,
+		"Weekly check-in",
+		fmt.Sprintf("Your %s check-in is ready", schedule.TemplateName),
+		submission)
+	return true, nil
+}
+
+func (s *checkInService) notify(ctx context.Context, userID, kind, title, body string, submission *types.CheckInSubmission) {
+	if s.notifier == nil {
+		return
+	}
+
+	err := s.notifier.NotifyUser(ctx, userID, kind, title, body, map[string]interface{}{
+		"submission_id": submission.SubmissionID,
+		"template_id":   submission.TemplateID,
+		"week_start":    submission.WeekStart.Format("2006-01-02"),
+	})
+	if err != nil {
+		log.Printf("Failed to send %s notification for check-in %d: %v", kind, submission.SubmissionID, err)
+	}
+}
+
+// CheckInWorker opens scheduled check-ins as their due day arrives.
+type CheckInWorker struct {
+	service CheckInService
+}
+
+func NewCheckInWorker(service CheckInService) *CheckInWorker {
+	return &CheckInWorker{service: service}
+}
+
+func (w *CheckInWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInGenerateInterval)
+	defer ticker.Stop()
+
+	for {
+		if opened, err := w.service.GenerateDueCheckIns(ctx); err != nil {
+			log.Printf("Check-in generation failed: %v", err)
+		} else if opened > 0 {
+			log.Printf("Opened %d weekly check-ins", opened)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const (
+	defaultTimelineLimit = 30
+	maxTimelineLimit     = 100
+)
+
+// GetClientTimeline merges the client's workouts, records, plan changes,
+// check-ins, nutrition, mindfulness and messages into one page, newest first.
+func (s *coachService) GetClientTimeline(ctx context.Context, coachID string, userID int, query types.TimelineQuery) (*types.TimelinePage, error) {
+	if err := s.ValidateCoachPermission(ctx, coachID, userID); err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := normalizeTimelineTypes(query.Types)
+	if err != nil {
+		return nil, err
+	}
+	query.Types = eventTypes
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, types.ErrInvalidDateRange
+	}
+
+	var cursor *types.TimelineCursor
+	if query.Cursor != "" {
+		if cursor, err = decodeTimelineCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultTimelineLimit
+	}
+	if limit > maxTimelineLimit {
+		limit = maxTimelineLimit
+	}
+
+	profile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	events, err := s.repo.CoachAssignments().GetClientTimeline(ctx, coachID, profile.AuthUserID, query, cursor, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client timeline: %w", err)
+	}
+
+	page := &types.TimelinePage{}
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[len(events)-1]
+		next := encodeTimelineCursor(last)
+		page.NextCursor = &next
+		page.HasMore = true
+	}
+	for i := range events {
+		decorateTimelineEvent(&events[i])
+	}
+	page.Events = events
+
+	return page, nil
+}
+
+// normalizeTimelineTypes rejects unknown types and drops duplicates. No types
+// selects every source.
+func normalizeTimelineTypes(requested []types.TimelineEventType) ([]types.TimelineEventType, error) {
+	if len(requested) == 0 {
+		return types.AllTimelineEventTypes, nil
+	}
+
+	known := make(map[types.TimelineEventType]bool, len(types.AllTimelineEventTypes))
+	for _, t := range types.AllTimelineEventTypes {
+		known[t] = true
+	}
+
+	seen := make(map[types.TimelineEventType]bool, len(requested))
+	result := make([]types.TimelineEventType, 0, len(requested))
+	for _, t := range requested {
+		if !known[t] {
+			return nil, types.ErrInvalidTimelineType
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// Cursors are opaque to clients: base64 of "<occurred_at>|<type>|<source id>".
+func encodeTimelineCursor(event types.TimelineEvent) string {
+	sourceID := strings.TrimPrefix(event.ID, string(event.Type)+":")
+	raw := event.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + string(event.Type) + "|" + sourceID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTimelineCursor(cursor string) (*types.TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, types.ErrInvalidTimelineCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return nil, types.ErrInvalidTimelineCursor
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, types.ErrInvalidTimelineCursor
+	}
+
+	return &types.TimelineCursor{
+		OccurredAt: occurredAt,
+		Type:       types.TimelineEventType(parts[1]),
+		SourceID:   parts[2],
+	}, nil
+}
+
+// decorateTimelineEvent fills in the display title and derived fields.
+func decorateTimelineEvent(event *types.TimelineEvent) {
+	data := event.Data
+	switch event.Type {
+	case types.TimelineWorkout:
+		event.Title = "Completed workout"
+		if focus, ok := data["focus"].(string); ok && focus != "" {
+			event.Title = fmt.Sprintf("Completed %s workout", focus)
+		}
+	case types.TimelinePersonalRecord:
+		event.Title = "New personal record"
+		if name, ok := data["exercise_name"].(string); ok && name != "" {
+			if weight, ok := data["weight"].(float64); ok {
+				event.Title = fmt.Sprintf("New %s record: %g kg", name, weight)
+			}
+		}
+	case types.TimelinePlanAdaptation:
+		event.Title = "Plan adapted"
+		if reason, ok := data["reason"].(string); ok && reason != "" {
+			event.Title = "Plan adapted: " + reason
+		}
+	case types.TimelineRecoveryCheckIn:
+		event.Title = "Recovery check-in"
+	case types.TimelineNutritionSummary:
+		event.Title = "Nutrition summary"
+		if percent := nutritionGoalPercent(data); percent != nil {
+			data["percent_of_goal"] = percent
+			if calories, ok := percent["calories"]; ok {
+				event.Title = fmt.Sprintf("Nutrition summary: %.0f%% of calorie goal", calories)
+			}
+		}
+	case types.TimelineMindfulness:
+		event.Title = "Mindfulness session"
+		if sessionType, ok := data["session_type"].(string); ok && sessionType != "" {
+			event.Title = "Mindfulness: " + strings.ReplaceAll(sessionType, "_", " ")
+		}
+	case types.TimelineMessage:
+		event.Title = "Message from client"
+		if data["from"] == "coach" {
+			event.Title = "Message from coach"
+		}
+	}
+}
+
+// nutritionGoalPercent compares a day's totals with the client's goals. It
+// returns nil when the client has no goals set.
+func nutritionGoalPercent(data map[string]interface{}) map[string]float64 {
+	totals, _ := data["totals"].(map[string]interface{})
+	goals, _ := data["goals"].(map[string]interface{})
+	if totals == nil || goals == nil {
+		return nil
+	}
+
+	percent := make(map[string]float64, len(goals))
+	for nutrient, goal := range goals {
+		goalValue, ok := goal.(float64)
+		if !ok || goalValue <= 0 {
+			continue
+		}
+		total, _ := totals[nutrient].(float64)
+		percent[nutrient] = math.Round(total/goalValue*1000) / 10
+	}
+	if len(percent) == 0 {
+		return nil
+	}
+	return percent
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+func TestTimelineCursorRoundTrip(t *testing.T) {
+	event := types.TimelineEvent{
+		ID:         "nutrition_summary:2024-06-10",
+		Type:       types.TimelineNutritionSummary,
+		OccurredAt: time.Date(2024, 6, 10, 20, 15, 3, 120000000, time.FixedZone("CEST", 2*60*60)),
+	}
+
+	cursor, err := decodeTimelineCursor(encodeTimelineCursor(event))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !cursor.OccurredAt.Equal(event.OccurredAt) || cursor.Type != event.Type || cursor.SourceID != "2024-06-10" {
+		t.Errorf("cursor did not round-trip: %+v", cursor)
+	}
+
+	for _, bad := range []string{"not base64!", "bm8tc2VwYXJhdG9ycw", encodeTimelineCursor(types.TimelineEvent{Type: types.TimelineMessage, ID: "message:"})} {
+		if _, err := decodeTimelineCursor(bad); err != types.ErrInvalidTimelineCursor {
+			t.Errorf("decodeTimelineCursor(%q) = %v, want ErrInvalidTimelineCursor", bad, err)
+		}
+	}
+}
+
+func TestNormalizeTimelineTypes(t *testing.T) {
+	all, err := normalizeTimelineTypes(nil)
+	if err != nil || len(all) != len(types.AllTimelineEventTypes) {
+		t.Errorf("empty filter should select every type, got %v, %v", all, err)
+	}
+
+	got, err := normalizeTimelineTypes([]types.TimelineEventType{types.TimelineMessage, types.TimelineWorkout, types.TimelineMessage})
+	if err != nil || len(got) != 2 {
+		t.Errorf("expected duplicates dropped, got %v, %v", got, err)
+	}
+
+	if _, err := normalizeTimelineTypes([]types.TimelineEventType{"steps"}); err != types.ErrInvalidTimelineType {
+		t.Errorf("unknown type error = %v", err)
+	}
+}
+
+func TestDecorateNutritionSummary(t *testing.T) {
+	event := types.TimelineEvent{
+		Type: types.TimelineNutritionSummary,
+		Data: map[string]interface{}{
+			"totals": map[string]interface{}{"calories": 1800.0, "protein": 150.0, "fiber": 0.0},
+			"goals":  map[string]interface{}{"calories": 2400.0, "protein": 120.0, "fiber": 0.0},
+		},
+	}
+	decorateTimelineEvent(&event)
+
+	percent, ok := event.Data["percent_of_goal"].(map[string]float64)
+	if !ok {
+		t.Fatalf("percent_of_goal missing: %+v", event.Data)
+	}
+	if percent["calories"] != 75 || percent["protein"] != 125 {
+		t.Errorf("unexpected percentages: %v", percent)
+	}
+	if _, ok := percent["fiber"]; ok {
+		t.Errorf("a zero goal should be skipped: %v", percent)
+	}
+	if event.Title != "Nutrition summary: 75% of calorie goal" {
+		t.Errorf("title = %q", event.Title)
+	}
+
+	noGoals := types.TimelineEvent{
+		Type: types.TimelineNutritionSummary,
+		Data: map[string]interface{}{"totals": map[string]interface{}{"calories": 1800.0}, "goals": nil},
+	}
+	decorateTimelineEvent(&noGoals)
+	if _, ok := noGoals.Data["percent_of_goal"]; ok || noGoals.Title != "Nutrition summary" {
+		t.Errorf("client without goals should get plain summary: %+v", noGoals)
+	}
+}
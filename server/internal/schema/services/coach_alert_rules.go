@@ -0,0 +1,291 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const (
+	// A client must have logged food on this many days in the lookback window
+	// before a gap counts as "stopped" rather than "never started".
+	minNutritionLoggingDays = 3
+	// A recovery score below this makes a drop critical.
+	criticalRecoveryScore = 0.4
+)
+
+// alertRuleSpec describes a rule's default setting and the thresholds a coach may choose.
+type alertRuleSpec struct {
+	description      string
+	defaultThreshold float64
+	minThreshold     float64
+	maxThreshold     float64
+}
+
+var alertRuleSpecs = map[types.CoachAlertRuleType]alertRuleSpec{
+	types.AlertRuleNoRecentWorkout: {
+		description:      "No completed workout in this many days",
+		defaultThreshold: 5, minThreshold: 1, maxThreshold: 60,
+	},
+	types.AlertRuleLowCompletion: {
+		description:      "Completion rate below this ratio for two weeks in a row",
+		defaultThreshold: 0.5, minThreshold: 0.05, maxThreshold: 1,
+	},
+	types.AlertRuleRecoveryDrop: {
+		description:      "Recovery score (0-1) over the last 3 days dropped by at least this much against the 2 weeks before",
+		defaultThreshold: 0.15, minThreshold: 0.05, maxThreshold: 1,
+	},
+	types.AlertRuleNutritionStopped: {
+		description:      "A client who logs meals has not logged any for this many days",
+		defaultThreshold: 3, minThreshold: 1, maxThreshold: 30,
+	},
+	types.AlertRulePersonalRecord: {
+		description: "A client logged a heavier weight than ever before on an exercise",
+	},
+}
+
+// alertRuleOrder is the order rules are listed and evaluated in.
+var alertRuleOrder = []types.CoachAlertRuleType{
+	types.AlertRuleNoRecentWorkout,
+	types.AlertRuleLowCompletion,
+	types.AlertRuleRecoveryDrop,
+	types.AlertRuleNutritionStopped,
+	types.AlertRulePersonalRecord,
+}
+
+// effectiveAlertRules merges a coach's saved settings over the defaults.
+func effectiveAlertRules(saved []types.CoachAlertRule) []types.CoachAlertRule {
+	byType := make(map[types.CoachAlertRuleType]types.CoachAlertRule, len(saved))
+	for _, rule := range saved {
+		byType[rule.RuleType] = rule
+	}
+
+	rules := make([]types.CoachAlertRule, 0, len(alertRuleOrder))
+	for _, ruleType := range alertRuleOrder {
+		spec := alertRuleSpecs[ruleType]
+		rule, ok := byType[ruleType]
+		if !ok {
+			rule = types.CoachAlertRule{
+				RuleType:  ruleType,
+				Enabled:   true,
+				Threshold: spec.defaultThreshold,
+				Push:      true,
+			}
+		}
+		rule.Description = spec.description
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func validateAlertThreshold(ruleType types.CoachAlertRuleType, threshold float64) error {
+	spec, ok := alertRuleSpecs[ruleType]
+	if !ok {
+		return types.ErrUnknownAlertRule
+	}
+	if spec.maxThreshold == 0 {
+		// Rule has no threshold
+		return nil
+	}
+	if math.IsNaN(threshold) || threshold < spec.minThreshold || threshold > spec.maxThreshold {
+		return types.ErrInvalidAlertThreshold
+	}
+	return nil
+}
+
+// evaluateComplianceRules returns the alerts a client's snapshot raises under
+// the enabled rules. Each alert carries a dedupe key naming the occurrence, so
+// a condition that lasts across scans is only stored once.
+func evaluateComplianceRules(snap types.ClientComplianceSnapshot, rules []types.CoachAlertRule, now time.Time) []types.CoachAlert {
+	var alerts []types.CoachAlert
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		switch rule.RuleType {
+		case types.AlertRuleNoRecentWorkout:
+			alerts = appendAlert(alerts, noRecentWorkoutAlert(snap, rule, now))
+		case types.AlertRuleLowCompletion:
+			alerts = appendAlert(alerts, lowCompletionAlert(snap, rule))
+		case types.AlertRuleRecoveryDrop:
+			alerts = appendAlert(alerts, recoveryDropAlert(snap, rule, now))
+		case types.AlertRuleNutritionStopped:
+			alerts = appendAlert(alerts, nutritionStoppedAlert(snap, rule, now))
+		case types.AlertRulePersonalRecord:
+			alerts = append(alerts, personalRecordAlerts(snap)...)
+		}
+	}
+
+	for i := range alerts {
+		alerts[i].ClientID = snap.ClientID
+		alerts[i].ClientName = snap.ClientName
+		profileID := snap.WorkoutProfileID
+		alerts[i].WorkoutProfileID = &profileID
+	}
+	return alerts
+}
+
+func appendAlert(alerts []types.CoachAlert, alert *types.CoachAlert) []types.CoachAlert {
+	if alert == nil {
+		return alerts
+	}
+	return append(alerts, *alert)
+}
+
+// noRecentWorkoutAlert counts from the last completed workout, or from the
+// assignment for clients who have not trained since joining.
+func noRecentWorkoutAlert(snap types.ClientComplianceSnapshot, rule types.CoachAlertRule, now time.Time) *types.CoachAlert {
+	since := snap.AssignedAt
+	if snap.LastWorkoutAt != nil {
+		since = *snap.LastWorkoutAt
+	}
+
+	days := daysBetween(since, now)
+	if float64(days) < rule.Threshold {
+		return nil
+	}
+
+	severity := types.AlertSeverityWarning
+	if float64(days) >= 2*rule.Threshold {
+		severity = types.AlertSeverityCritical
+	}
+
+	message := fmt.Sprintf("%s has not completed a workout in %d days.", snap.ClientName, days)
+	if snap.LastWorkoutAt == nil {
+		message = fmt.Sprintf("%s has not completed a workout since joining %d days ago.", snap.ClientName, days)
+	}
+
+	return &types.CoachAlert{
+		RuleType:  types.AlertRuleNoRecentWorkout,
+		Severity:  severity,
+		Title:     "No recent workouts",
+		Message:   message,
+		DedupeKey: fmt.Sprintf("%s:%s", types.AlertRuleNoRecentWorkout, since.UTC().Format("2006-01-02")),
+		Data: map[string]interface{}{
+			"days_inactive": days,
+			"since":         since,
+		},
+	}
+}
+
+// lowCompletionAlert needs the last two full weeks to both have planned
+// workouts and both fall under the threshold.
+func lowCompletionAlert(snap types.ClientComplianceSnapshot, rule types.CoachAlertRule) *types.CoachAlert {
+	if len(snap.WeeklyCompletion) < 2 {
+		return nil
+	}
+
+	weeks := snap.WeeklyCompletion[:2]
+	rates := make([]float64, len(weeks))
+	for i, week := range weeks {
+		if week.Planned == 0 {
+			return nil
+		}
+		rates[i] = float64(week.Completed) / float64(week.Planned)
+		if rates[i] >= rule.Threshold {
+			return nil
+		}
+	}
+
+	return &types.CoachAlert{
+		RuleType: types.AlertRuleLowCompletion,
+		Severity: types.AlertSeverityWarning,
+		Title:    "Low workout completion",
+		Message: fmt.Sprintf("%s completed %d of %d planned workouts last week and %d of %d the week before.",
+			snap.ClientName, weeks[0].Completed, weeks[0].Planned, weeks[1].Completed, weeks[1].Planned),
+		DedupeKey: fmt.Sprintf("%s:%s", types.AlertRuleLowCompletion, weeks[0].WeekStart.UTC().Format("2006-01-02")),
+		Data: map[string]interface{}{
+			"weeks":     weeks,
+			"rates":     rates,
+			"threshold": rule.Threshold,
+		},
+	}
+}
+
+// recoveryDropAlert is raised at most once per ISO week.
+func recoveryDropAlert(snap types.ClientComplianceSnapshot, rule types.CoachAlertRule, now time.Time) *types.CoachAlert {
+	if snap.RecentRecoveryScore == nil || snap.BaselineRecoveryScore == nil {
+		return nil
+	}
+
+	recent, baseline := *snap.RecentRecoveryScore, *snap.BaselineRecoveryScore
+	drop := baseline - recent
+	if drop < rule.Threshold {
+		return nil
+	}
+
+	severity := types.AlertSeverityWarning
+	if recent < criticalRecoveryScore {
+		severity = types.AlertSeverityCritical
+	}
+
+	year, week := now.ISOWeek()
+	return &types.CoachAlert{
+		RuleType: types.AlertRuleRecoveryDrop,
+		Severity: severity,
+		Title:    "Recovery dropping",
+		Message: fmt.Sprintf("%s's recovery score fell from %.0f%% to %.0f%% over the last few days.",
+			snap.ClientName, baseline*100, recent*100),
+		DedupeKey: fmt.Sprintf("%s:%d-W%02d", types.AlertRuleRecoveryDrop, year, week),
+		Data: map[string]interface{}{
+			"recent_score":   recent,
+			"baseline_score": baseline,
+			"drop":           drop,
+		},
+	}
+}
+
+func nutritionStoppedAlert(snap types.ClientComplianceSnapshot, rule types.CoachAlertRule, now time.Time) *types.CoachAlert {
+	if snap.LastFoodLogDate == nil || snap.FoodLogDays < minNutritionLoggingDays {
+		return nil
+	}
+
+	days := daysBetween(*snap.LastFoodLogDate, now)
+	if float64(days) < rule.Threshold {
+		return nil
+	}
+
+	return &types.CoachAlert{
+		RuleType:  types.AlertRuleNutritionStopped,
+		Severity:  types.AlertSeverityInfo,
+		Title:     "Nutrition logging stopped",
+		Message:   fmt.Sprintf("%s has not logged any meals in %d days.", snap.ClientName, days),
+		DedupeKey: fmt.Sprintf("%s:%s", types.AlertRuleNutritionStopped, snap.LastFoodLogDate.Format("2006-01-02")),
+		Data: map[string]interface{}{
+			"days_since_last_log": days,
+			"last_log_date":       snap.LastFoodLogDate.Format("2006-01-02"),
+		},
+	}
+}
+
+func personalRecordAlerts(snap types.ClientComplianceSnapshot) []types.CoachAlert {
+	alerts := make([]types.CoachAlert, 0, len(snap.PersonalRecords))
+	for _, pr := range snap.PersonalRecords {
+		alerts = append(alerts, types.CoachAlert{
+			RuleType: types.AlertRulePersonalRecord,
+			Severity: types.AlertSeverityInfo,
+			Title:    "New personal record",
+			Message: fmt.Sprintf("%s set a new %s record: %.1f kg (previous best %.1f kg).",
+				snap.ClientName, pr.ExerciseName, pr.Weight, pr.PreviousBest),
+			DedupeKey: fmt.Sprintf("%s:%d:%s", types.AlertRulePersonalRecord, pr.ExerciseID, pr.Date.Format("2006-01-02")),
+			Data: map[string]interface{}{
+				"exercise_id":   pr.ExerciseID,
+				"exercise_name": pr.ExerciseName,
+				"weight":        pr.Weight,
+				"previous_best": pr.PreviousBest,
+				"date":          pr.Date.Format("2006-01-02"),
+			},
+		})
+	}
+	return alerts
+}
+
+// daysBetween counts whole days from since to now.
+func daysBetween(since, now time.Time) int {
+	if now.Before(since) {
+		return 0
+	}
+	return int(now.Sub(since).Hours() / 24)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+func alertsByRule(alerts []types.CoachAlert) map[types.CoachAlertRuleType]types.CoachAlert {
+	byRule := make(map[types.CoachAlertRuleType]types.CoachAlert, len(alerts))
+	for _, alert := range alerts {
+		byRule[alert.RuleType] = alert
+	}
+	return byRule
+}
+
+func TestEvaluateComplianceRules(t *testing.T) {
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		ts := now.AddDate(0, 0, -days)
+		return &ts
+	}
+	score := func(v float64) *float64 { return &v }
+
+	atRisk := types.ClientComplianceSnapshot{
+		ClientID:         "client-1",
+		WorkoutProfileID: 7,
+		ClientName:       "Sam",
+		AssignedAt:       now.AddDate(0, -3, 0),
+		LastWorkoutAt:    daysAgo(6),
+		WeeklyCompletion: []types.WeeklyCompletion{
+			{WeekStart: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), Planned: 4, Completed: 1},
+			{WeekStart: time.Date(2024, 5, 27, 0, 0, 0, 0, time.UTC), Planned: 4, Completed: 1},
+		},
+		RecentRecoveryScore:   score(0.35),
+		BaselineRecoveryScore: score(0.75),
+		LastFoodLogDate:       daysAgo(4),
+		FoodLogDays:           10,
+		PersonalRecords: []types.PersonalRecordEvent{
+			{ExerciseID: 3, ExerciseName: "Squat", Weight: 102.5, PreviousBest: 100, Date: *daysAgo(2)},
+		},
+	}
+
+	alerts := evaluateComplianceRules(atRisk, effectiveAlertRules(nil), now)
+	if len(alerts) != 5 {
+		t.Fatalf("expected one alert per rule, got %d: %+v", len(alerts), alerts)
+	}
+	byRule := alertsByRule(alerts)
+
+	inactive := byRule[types.AlertRuleNoRecentWorkout]
+	if inactive.Severity != types.AlertSeverityWarning || inactive.DedupeKey != "no_recent_workout:2024-06-06" {
+		t.Errorf("unexpected inactivity alert: %+v", inactive)
+	}
+	if inactive.ClientID != "client-1" || inactive.WorkoutProfileID == nil || *inactive.WorkoutProfileID != 7 {
+		t.Errorf("alert not attributed to the client: %+v", inactive)
+	}
+	if got := byRule[types.AlertRuleLowCompletion].DedupeKey; got != "low_completion:2024-06-03" {
+		t.Errorf("low completion dedupe key = %q", got)
+	}
+	if got := byRule[types.AlertRuleRecoveryDrop]; got.Severity != types.AlertSeverityCritical || got.DedupeKey != "recovery_drop:2024-W24" {
+		t.Errorf("unexpected recovery alert: %+v", got)
+	}
+	if got := byRule[types.AlertRulePersonalRecord].DedupeKey; got != "personal_record:3:2024-06-10" {
+		t.Errorf("personal record dedupe key = %q", got)
+	}
+
+	// The same snapshot an hour later must produce the same keys so the scan doesn't repeat alerts
+	later := evaluateComplianceRules(atRisk, effectiveAlertRules(nil), now.Add(time.Hour))
+	for _, alert := range later {
+		if byRule[alert.RuleType].DedupeKey != alert.DedupeKey {
+			t.Errorf("%s dedupe key changed between scans: %q -> %q", alert.RuleType, byRule[alert.RuleType].DedupeKey, alert.DedupeKey)
+		}
+	}
+}
+
+func TestEvaluateComplianceRulesQuietClient(t *testing.T) {
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	score := 0.7
+
+	healthy := types.ClientComplianceSnapshot{
+		ClientID:      "client-2",
+		ClientName:    "Alex",
+		AssignedAt:    now.AddDate(0, -1, 0),
+		LastWorkoutAt: &yesterday,
+		WeeklyCompletion: []types.WeeklyCompletion{
+			{Planned: 3, Completed: 3},
+			// A week with nothing planned never counts as low completion
+			{Planned: 0, Completed: 0},
+		},
+		RecentRecoveryScore:   &score,
+		BaselineRecoveryScore: &score,
+		LastFoodLogDate:       &yesterday,
+		FoodLogDays:           20,
+	}
+	if alerts := evaluateComplianceRules(healthy, effectiveAlertRules(nil), now); len(alerts) != 0 {
+		t.Errorf("expected no alerts, got %+v", alerts)
+	}
+
+	// Clients who barely used the food log are not "stopped"
+	occasional := healthy
+	lastLog := now.AddDate(0, 0, -10)
+	occasional.LastFoodLogDate = &lastLog
+	occasional.FoodLogDays = 1
+	if alerts := evaluateComplianceRules(occasional, effectiveAlertRules(nil), now); len(alerts) != 0 {
+		t.Errorf("expected no nutrition alert for occasional loggers, got %+v", alerts)
+	}
+}
+
+func TestEvaluateComplianceRulesHonoursCoachSettings(t *testing.T) {
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	neverTrained := types.ClientComplianceSnapshot{
+		ClientID:   "client-3",
+		ClientName: "Robin",
+		AssignedAt: now.AddDate(0, 0, -8),
+	}
+
+	alerts := evaluateComplianceRules(neverTrained, effectiveAlertRules(nil), now)
+	if len(alerts) != 1 || alerts[0].Severity != types.AlertSeverityWarning {
+		t.Fatalf("expected a warning for a client who never trained, got %+v", alerts)
+	}
+
+	rules := effectiveAlertRules([]types.CoachAlertRule{
+		{RuleType: types.AlertRuleNoRecentWorkout, Enabled: true, Threshold: 3},
+	})
+	if alerts := evaluateComplianceRules(neverTrained, rules, now); len(alerts) != 1 || alerts[0].Severity != types.AlertSeverityCritical {
+		t.Errorf("expected a lower threshold to escalate to critical, got %+v", alerts)
+	}
+
+	rules = effectiveAlertRules([]types.CoachAlertRule{
+		{RuleType: types.AlertRuleNoRecentWorkout, Enabled: false, Threshold: 5},
+	})
+	if alerts := evaluateComplianceRules(neverTrained, rules, now); len(alerts) != 0 {
+		t.Errorf("expected disabled rule to stay quiet, got %+v", alerts)
+	}
+}
+
+func TestValidateAlertThreshold(t *testing.T) {
+	tests := []struct {
+		rule      types.CoachAlertRuleType
+		threshold float64
+		want      error
+	}{
+		{types.AlertRuleNoRecentWorkout, 7, nil},
+		{types.AlertRuleNoRecentWorkout, 0, types.ErrInvalidAlertThreshold},
+		{types.AlertRuleLowCompletion, 1.5, types.ErrInvalidAlertThreshold},
+		{types.AlertRuleRecoveryDrop, 0.2, nil},
+		{types.AlertRulePersonalRecord, 0, nil},
+		{"made_up", 1, types.ErrUnknownAlertRule},
+	}
+	for _, tt := range tests {
+		if got := validateAlertThreshold(tt.rule, tt.threshold); got != tt.want {
+			t.Errorf("validateAlertThreshold(%s, %v) = %v, want %v", tt.rule, tt.threshold, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const (
+	NotificationCoachAlert = "coach_alert"
+
+	coachAlertScanInterval = time.Hour
+)
+
+type CoachAlertService interface {
+	GetInbox(ctx context.Context, coachID string, status types.CoachAlertStatus, pagination types.PaginationParams) (*types.CoachAlertInbox, error)
+	AcknowledgeAlert(ctx context.Context, coachID string, alertID int64) (*types.CoachAlert, error)
+	AcknowledgeAll(ctx context.Context, coachID string) (int64, error)
+
+	GetRules(ctx context.Context, coachID string) ([]types.CoachAlertRule, error)
+	UpdateRules(ctx context.Context, coachID string, req *types.UpdateCoachAlertRulesRequest) ([]types.CoachAlertRule, error)
+
+	// ScanCoach evaluates the rules for one coach's clients and returns the number of new alerts.
+	ScanCoach(ctx context.Context, coachID string) (int, error)
+	ScanAll(ctx context.Context) (int, error)
+
+	SetNotifier(notifier UserNotifier)
+}
+
+type coachAlertService struct {
+	repo     repository.CoachAlertRepo
+	notifier UserNotifier
+	now      func() time.Time
+}
+
+func NewCoachAlertService(repo repository.CoachAlertRepo) CoachAlertService {
+	return &coachAlertService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// SetNotifier enables pushing new alerts to coaches over WebSocket.
+func (s *coachAlertService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+func (s *coachAlertService) GetInbox(ctx context.Context, coachID string, status types.CoachAlertStatus, pagination types.PaginationParams) (*types.CoachAlertInbox, error) {
+	alerts, err := s.repo.ListCoachAlerts(ctx, coachID, status, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	openCount, err := s.repo.CountOpenCoachAlerts(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.CoachAlertInbox{
+		Alerts:    alerts,
+		OpenCount: openCount,
+	}, nil
+}
+
+func (s *coachAlertService) AcknowledgeAlert(ctx context.Context, coachID string, alertID int64) (*types.CoachAlert, error) {
+	return s.repo.AcknowledgeCoachAlert(ctx, coachID, alertID)
+}
+
+func (s *coachAlertService) AcknowledgeAll(ctx context.Context, coachID string) (int64, error) {
+	return s.repo.AcknowledgeAllCoachAlerts(ctx, coachID)
+}
+
+func (s *coachAlertService) GetRules(ctx context.Context, coachID string) ([]types.CoachAlertRule, error) {
+	saved, err := s.repo.GetCoachAlertRules(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveAlertRules(saved), nil
+}
+
+// UpdateRules applies partial updates; fields left out keep their current value.
+func (s *coachAlertService) UpdateRules(ctx context.Context, coachID string, req *types.UpdateCoachAlertRulesRequest) ([]types.CoachAlertRule, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetRules(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[types.CoachAlertRuleType]types.CoachAlertRule, len(current))
+	for _, rule := range current {
+		byType[rule.RuleType] = rule
+	}
+
+	updated := make([]types.CoachAlertRule, 0, len(req.Rules))
+	for _, change := range req.Rules {
+		rule, ok := byType[change.RuleType]
+		if !ok {
+			return nil, types.ErrUnknownAlertRule
+		}
+		if change.Enabled != nil {
+			rule.Enabled = *change.Enabled
+		}
+		if change.Push != nil {
+			rule.Push = *change.Push
+		}
+		if change.Threshold != nil {
+			if err := validateAlertThreshold(rule.RuleType, *change.Threshold); err != nil {
+				return nil, err
+			}
+			rule.Threshold = *change.Threshold
+		}
+		byType[rule.RuleType] = rule
+		updated = append(updated, rule)
+	}
+
+	for _, rule := range updated {
+		if err := s.repo.UpsertCoachAlertRule(ctx, coachID, rule); err != nil {
+			return nil, fmt.Errorf("failed to save %s rule: %w", rule.RuleType, err)
+		}
+	}
+
+	return s.GetRules(ctx, coachID)
+}
+
+func (s *coachAlertService) ScanCoach(ctx context.Context, coachID string) (int, error) {
+	rules, err := s.GetRules(ctx, coachID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	snapshots, err := s.repo.GetComplianceSnapshots(ctx, coachID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	pushRules := make(map[types.CoachAlertRuleType]bool, len(rules))
+	for _, rule := range rules {
+		pushRules[rule.RuleType] = rule.Push
+	}
+
+	created := 0
+	for _, snap := range snapshots {
+		for _, alert := range evaluateComplianceRules(snap, rules, now) {
+			alert.CoachID = coachID
+			isNew, err := s.repo.CreateCoachAlert(ctx, &alert)
+			if err != nil {
+				return created, fmt.Errorf("failed to store alert for client %s: %w", snap.ClientID, err)
+			}
+			if !isNew {
+				continue
+			}
+			created++
+			if pushRules[alert.RuleType] {
+				s.push(ctx, &alert)
+			}
+		}
+	}
+
+	return created, nil
+}
+
+// ScanAll scans every coach with active clients. A failure for one coach is
+// logged and does not stop the others.
+func (s *coachAlertService) ScanAll(ctx context.Context) (int, error) {
+	coachIDs, err := s.repo.ListCoachesWithActiveClients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, coachID := range coachIDs {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		created, err := s.ScanCoach(ctx, coachID)
+		total += created
+		if err != nil {
+			log.Printf("Compliance scan failed for coach %s: %v", coachID, err)
+		}
+	}
+	return total, nil
+}
+
+func (s *coachAlertService) push(ctx context.Context, alert *types.CoachAlert) {
+	if s.notifier == nil {
+		return
+	}
+
+	err := s.notifier.NotifyUser(ctx, alert.CoachID, NotificationCoachAlert, alert.Title, alert.Message, map[string]interface{}{
+		"alert_id":  alert.AlertID,
+		"client_id": alert.ClientID,
+		"rule_type": alert.RuleType,
+		"severity":  alert.Severity,
+	})
+	if err != nil {
+		log.Printf("Failed to push coach alert %d: %v", alert.AlertID, err)
+	}
+}
+
+// CoachAlertWorker runs the compliance scan on a schedule.
+type CoachAlertWorker struct {
+	service CoachAlertService
+}
+
+func NewCoachAlertWorker(service CoachAlertService) *CoachAlertWorker {
+	return &CoachAlertWorker{service: service}
+}
+
+func (w *CoachAlertWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(coachAlertScanInterval)
+	defer ticker.Stop()
+
+	for {
+		if created, err := w.service.ScanAll(ctx); err != nil {
+			log.Printf("Coach compliance scan failed: %v", err)
+		} else if created > 0 {
+			log.Printf("Raised %d coach alerts", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	authService "github.com/tdmdh/fit-up-server/internal/auth/services"
+	authTypes "github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const NotificationCoachApplicationReviewed = "coach_application_reviewed"
+
+// UserNotifier delivers in-app notifications to a user. The message module's
+// realtime service implements it.
+type UserNotifier interface {
+	NotifyUser(ctx context.Context, userID, kind, title, body string, data map[string]interface{}) error
+}
+
+type CoachApplicationService interface {
+	SubmitApplication(ctx context.Context, userID string, req *types.SubmitCoachApplicationRequest) (*types.CoachApplication, error)
+	ListMyApplications(ctx context.Context, userID string) ([]types.CoachApplication, error)
+	WithdrawApplication(ctx context.Context, userID string, applicationID int64) (*types.CoachApplication, error)
+
+	ListApplications(ctx context.Context, status types.CoachApplicationStatus, pagination types.PaginationParams) ([]types.CoachApplication, error)
+	GetApplication(ctx context.Context, applicationID int64) (*types.CoachApplication, error)
+	ApproveApplication(ctx context.Context, actor AdminActor, applicationID int64, reason string) (*types.CoachApplication, error)
+	RejectApplication(ctx context.Context, actor AdminActor, applicationID int64, reason string) (*types.CoachApplication, error)
+
+	SetNotifier(notifier UserNotifier)
+}
+
+type coachApplicationService struct {
+	store    repository.SchemaRepo
+	repo     repository.CoachApplicationRepo
+	users    AdminUserStore
+	roles    repository.UserRoleRepo
+	notifier UserNotifier
+}
+
+func NewCoachApplicationService(store repository.SchemaRepo, users AdminUserStore) CoachApplicationService {
+	return &coachApplicationService{
+		store: store,
+		repo:  store.CoachApplications(),
+		users: users,
+		roles: store.UserRoles(),
+	}
+}
+
+// SetNotifier enables in-app notifications; the realtime service is created after this one.
+func (s *coachApplicationService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+func (s *coachApplicationService) SubmitApplication(ctx context.Context, userID string, req *types.SubmitCoachApplicationRequest) (*types.CoachApplication, error) {
+	if err := validator.New().Struct(req); err != nil {
+		return nil, fmt.Errorf("invalid application: %w", err)
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if err == authTypes.ErrUserNotFound {
+			return nil, types.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Role == authTypes.RoleCoach || user.Role == authTypes.RoleAdmin {
+		return nil, types.ErrAlreadyCoach
+	}
+
+	app := &types.CoachApplication{
+		UserID:          userID,
+		Credentials:     strings.TrimSpace(req.Credentials),
+		ExperienceYears: req.ExperienceYears,
+		Specialties:     req.Specialties,
+		Certifications:  req.Certifications,
+		Documents:       req.Documents,
+	}
+	if app.Specialties == nil {
+		app.Specialties = []string{}
+	}
+	if app.Certifications == nil {
+		app.Certifications = []types.CoachCertification{}
+	}
+	if app.Documents == nil {
+		app.Documents = []types.CoachApplicationDocument{}
+	}
+
+	if err := s.repo.CreateCoachApplication(ctx, app); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (s *coachApplicationService) ListMyApplications(ctx context.Context, userID string) ([]types.CoachApplication, error) {
+	return s.repo.ListCoachApplicationsByUser(ctx, userID)
+}
+
+func (s *coachApplicationService) WithdrawApplication(ctx context.Context, userID string, applicationID int64) (*types.CoachApplication, error) {
+	app, err := s.repo.GetCoachApplication(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if app.UserID != userID {
+		return nil, types.ErrCoachApplicationNotFound
+	}
+
+	return s.repo.ReviewCoachApplication(ctx, applicationID, types.CoachApplicationWithdrawn, "", "")
+}
+
+func (s *coachApplicationService) ListApplications(ctx context.Context, status types.CoachApplicationStatus, pagination types.PaginationParams) ([]types.CoachApplication, error) {
+	return s.repo.ListCoachApplications(ctx, status, pagination.Limit, pagination.Offset)
+}
+
+func (s *coachApplicationService) GetApplication(ctx context.Context, applicationID int64) (*types.CoachApplication, error) {
+	return s.repo.GetCoachApplication(ctx, applicationID)
+}
+
+// ApproveApplication is the only way a user becomes a coach. The role is updated
+// in the auth store and the role cache so coach routes unlock on the next token refresh.
+// The approval and both role writes share a transaction, so an application is
+// never approved without its applicant becoming a coach.
+func (s *coachApplicationService) ApproveApplication(ctx context.Context, actor AdminActor, applicationID int64, reason string) (*types.CoachApplication, error) {
+	var app *types.CoachApplication
+	err := s.store.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		app, err = s.repo.ReviewCoachApplication(ctx, applicationID, types.CoachApplicationApproved, actor.AdminID, strings.TrimSpace(reason))
+		if err != nil {
+			return err
+		}
+
+		if err := s.users.UpdateUserRole(ctx, app.UserID, authTypes.RoleCoach); err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if err := s.roles.UpsertUserRole(ctx, app.UserID, types.RoleCoach); err != nil {
+			return fmt.Errorf("failed to sync role cache: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAdminAudit(ctx, s.users, actor, authTypes.AdminActionApproveCoach, app.UserID, map[string]interface{}{
+		"application_id": app.ApplicationID,
+		"reason":         reason,
+	})
+	s.notifyDecision(ctx, app)
+
+	return app, nil
+}
+
+func (s *coachApplicationService) RejectApplication(ctx context.Context, actor AdminActor, applicationID int64, reason string) (*types.CoachApplication, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, types.ErrRejectionReasonRequired
+	}
+
+	app, err := s.repo.ReviewCoachApplication(ctx, applicationID, types.CoachApplicationRejected, actor.AdminID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAdminAudit(ctx, s.users, actor, authTypes.AdminActionRejectCoach, app.UserID, map[string]interface{}{
+		"application_id": app.ApplicationID,
+		"reason":         reason,
+	})
+	s.notifyDecision(ctx, app)
+
+	return app, nil
+}
+
+// notifyDecision tells the applicant by email and in-app. Delivery failures are
+// logged only; the decision is already stored and visible in their application list.
+func (s *coachApplicationService) notifyDecision(ctx context.Context, app *types.CoachApplication) {
+	approved := app.Status == types.CoachApplicationApproved
+	reason := ""
+	if app.ReviewReason != nil {
+		reason = *app.ReviewReason
+	}
+
+	user, err := s.users.GetUserByID(ctx, app.UserID)
+	if err != nil {
+		log.Printf("Failed to load applicant %s for notification: %v", app.UserID, err)
+	} else if err := authService.SendCoachApplicationDecisionEmail(user.Email, user.Name, approved, reason); err != nil {
+		log.Printf("Failed to email coach application decision to %s: %v", app.UserID, err)
+	}
+
+	if s.notifier == nil {
+		return
+	}
+
+	title := "Coach application not approved"
+	body := "Your application to become a coach was not approved."
+	if approved {
+		title = "Coach application approved"
+		body = "You now have coach access. Refresh your session to open the coach dashboard."
+	}
+
+	data := map[string]interface{}{
+		"application_id":   app.ApplicationID,
+		"status":           app.Status,
+		"reason":           reason,
+		"refresh_required": approved,
+	}
+	if err := s.notifier.NotifyUser(ctx, app.UserID, NotificationCoachApplicationReviewed, title, body, data); err != nil {
+		log.Printf("Failed to send in-app coach application notification to %s: %v", app.UserID, err)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	authTypes "github.com/tdmdh/fit-up-server/internal/auth/types"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+func TestApproveApplicationRollsBackWhenARoleWriteFails(t *testing.T) {
+	users, schema := newAdminFakes()
+	schema.applications[1] = &types.CoachApplication{ApplicationID: 1, UserID: "sam", Status: types.CoachApplicationPending}
+	schema.upsertErr = errors.New("connection reset")
+	service := NewCoachApplicationService(schema, users)
+
+	if _, err := service.ApproveApplication(context.Background(), testAdmin, 1, "great references"); !errors.Is(err, schema.upsertErr) {
+		t.Fatalf("err = %v, want the cache write error", err)
+	}
+
+	if status := schema.applications[1].Status; status != types.CoachApplicationPending {
+		t.Errorf("application status = %s, want it still pending", status)
+	}
+	if role := users.users["sam"].Role; role != authTypes.RoleClient {
+		t.Errorf("auth role = %s, want the approval rolled back", role)
+	}
+	if schema.rollbacks != 1 || len(users.audit) != 0 {
+		t.Errorf("expected one rollback and no audit entry, got %d and %+v", schema.rollbacks, users.audit)
+	}
+
+}
+
+func TestApproveApplicationRejectsReviewedApplications(t *testing.T) {
+	users, schema := newAdminFakes()
+	schema.applications[1] = &types.CoachApplication{ApplicationID: 1, UserID: "sam", Status: types.CoachApplicationRejected}
+	service := NewCoachApplicationService(schema, users)
+
+	if _, err := service.ApproveApplication(context.Background(), testAdmin, 1, ""); err != types.ErrCoachApplicationNotPending {
+		t.Errorf("err = %v, want %v", err, types.ErrCoachApplicationNotPending)
+	}
+	if _, err := service.ApproveApplication(context.Background(), testAdmin, 2, ""); err != types.ErrCoachApplicationNotFound {
+		t.Errorf("err = %v, want %v", err, types.ErrCoachApplicationNotFound)
+	}
+	if users.users["sam"].Role != authTypes.RoleClient || schema.roles["sam"] != types.RoleUser {
+		t.Errorf("roles should be untouched, got %s and %s", users.users["sam"].Role, schema.roles["sam"])
+	}
+}
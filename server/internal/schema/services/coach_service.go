@@ -14,9 +14,16 @@ import (
 	"github.com/tdmdh/fit-up-server/internal/schema/types"
 )
 
+// CoachingAccess reports whether a client's coaching subscription still covers
+// custom plans from the coach. The billing module satisfies it.
+type CoachingAccess interface {
+	AllowsCustomPlans(ctx context.Context, coachID, clientID string) (bool, error)
+}
+
 type coachService struct {
 	repo      repository.SchemaRepo
 	validator *validator.Validate
+	access    CoachingAccess
 }
 
 func NewCoachService(repo repository.SchemaRepo) CoachService {
@@ -26,6 +33,28 @@ func NewCoachService(repo repository.SchemaRepo) CoachService {
 	}
 }
 
+// SetCoachingAccess blocks new schemas for clients whose coaching
+// subscription has lapsed.
+func (s *coachService) SetCoachingAccess(access CoachingAccess) {
+	s.access = access
+}
+
+// checkPlanAccess returns ErrCoachingSubscriptionLapsed when the client's
+// subscription no longer covers custom plans.
+func (s *coachService) checkPlanAccess(ctx context.Context, coachID, clientAuthID string) error {
+	if s.access == nil {
+		return nil
+	}
+	allowed, err := s.access.AllowsCustomPlans(ctx, coachID, clientAuthID)
+	if err != nil {
+		return fmt.Errorf("failed to check coaching subscription: %w", err)
+	}
+	if !allowed {
+		return types.ErrCoachingSubscriptionLapsed
+	}
+	return nil
+}
+
 func (s *coachService) CreateManualSchemaForClient(ctx context.Context, coachID string, req *types.ManualSchemaRequest) (*types.WeeklySchemaExtended, error) {
 	reqJSON, _ := json.Marshal(req)
 	log.Printf("CreateManualSchemaForClient - Request data: %s", string(reqJSON))
@@ -36,7 +65,8 @@ func (s *coachService) CreateManualSchemaForClient(ctx context.Context, coachID
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	if err := s.ValidateCoachPermission(ctx, coachID, req.UserID); err != nil {
+	access, err := clientAccess(ctx, s.repo, coachID, req.UserID, types.TeamPermissionEditSchemas)
+	if err != nil {
 		log.Printf("CreateManualSchemaForClient - Permission validation failed: %v", err)
 		return nil, err
 	}
@@ -47,6 +77,10 @@ func (s *coachService) CreateManualSchemaForClient(ctx context.Context, coachID
 		return nil, fmt.Errorf("failed to find user profile: %w", err)
 	}
 
+	if err := s.checkPlanAccess(ctx, access.AssignedCoachID, authUserID.AuthUserID); err != nil {
+		return nil, err
+	}
+
 	log.Printf("CreateManualSchemaForClient - Creating schema for workout_profile_id=%d, auth_user_id=%s", req.UserID, authUserID.AuthUserID)
 
 	schemaReq := &types.WeeklySchemaRequest{
@@ -112,12 +146,8 @@ func (s *coachService) UpdateManualSchema(ctx context.Context, coachID string, s
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, req.UserID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check coach permission: %w", err)
-	}
-	if !isCoach {
-		return nil, fmt.Errorf("coach %s is not authorized for user %d", coachID, req.UserID)
+	if _, err := clientAccess(ctx, s.repo, coachID, req.UserID, types.TeamPermissionEditSchemas); err != nil {
+		return nil, err
 	}
 
 	schema, err := s.repo.Schemas().GetWeeklySchemaByID(ctx, schemaID)
@@ -202,12 +232,8 @@ func (s *coachService) DeleteSchema(ctx context.Context, coachID string, schemaI
 		return fmt.Errorf("failed to get user profile: %w", err)
 	}
 
-	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, profile.WorkoutProfileID)
-	if err != nil {
-		return fmt.Errorf("failed to check coach permission: %w", err)
-	}
-	if !isCoach {
-		return fmt.Errorf("coach %s is not authorized for schema %d", coachID, schemaID)
+	if _, err := clientAccess(ctx, s.repo, coachID, profile.WorkoutProfileID, types.TeamPermissionEditSchemas); err != nil {
+		return err
 	}
 	if err := s.repo.Schemas().DeleteWeeklySchema(ctx, schemaID); err != nil {
 		return fmt.Errorf("failed to delete schema: %w", err)
@@ -226,18 +252,21 @@ func (s *coachService) CloneSchemaToClient(ctx context.Context, coachID string,
 		return nil, fmt.Errorf("failed to get source user profile: %w", err)
 	}
 
-	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, sourceProfile.WorkoutProfileID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check coach permission: %w", err)
-	}
-	if !isCoach {
-		return nil, fmt.Errorf("coach %s is not authorized for schema %d", coachID, sourceSchemaID)
+	if _, err := clientAccess(ctx, s.repo, coachID, sourceProfile.WorkoutProfileID, types.TeamPermissionView); err != nil {
+		return nil, err
 	}
 
 	targetProfile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByID(ctx, targetUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get target user profile: %w", err)
 	}
+	access, err := clientAccess(ctx, s.repo, coachID, targetUserID, types.TeamPermissionEditSchemas)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPlanAccess(ctx, access.AssignedCoachID, targetProfile.AuthUserID); err != nil {
+		return nil, err
+	}
 
 	clonedSchemaReq := &types.WeeklySchemaRequest{
 		UserID:    targetProfile.AuthUserID,
@@ -253,44 +282,48 @@ func (s *coachService) CloneSchemaToClient(ctx context.Context, coachID string,
 	}, nil
 }
 
-func (s *coachService) SaveSchemaAsTemplate(ctx context.Context, coachID string, schemaID int, templateName string) error {
-	schema, err := s.repo.Schemas().GetWeeklySchemaByID(ctx, schemaID)
-	if err != nil {
-		return fmt.Errorf("failed to get schema: %w", err)
+// SaveSchemaAsTemplate snapshots one of the coach's or their clients' schemas
+// into the coach's template library.
+func (s *coachService) SaveSchemaAsTemplate(ctx context.Context, coachID string, req *types.SaveSchemaTemplateRequest) (*types.WorkoutTemplate, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
 	}
 
-	// Get workout_profile_id from auth_user_id to check coach permission
-	profile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByAuthID(ctx, schema.UserID)
+	schema, err := s.repo.Workouts().GetSchemaWithAllWorkouts(ctx, req.SchemaID)
 	if err != nil {
-		return fmt.Errorf("failed to get user profile: %w", err)
+		return nil, fmt.Errorf("failed to get schema: %w", err)
 	}
-
-	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, profile.WorkoutProfileID)
-	if err != nil {
-		return fmt.Errorf("failed to check coach permission: %w", err)
+	if err := s.checkSharedPlanOwner(ctx, coachID, schema.UserID); err != nil {
+		return nil, err
 	}
-	if !isCoach {
-		return fmt.Errorf("coach %s is not authorized for schema %d", coachID, schemaID)
+
+	days := schemaTemplateDays(schema)
+	if len(days) == 0 {
+		return nil, types.ErrTemplateEmpty
 	}
 
-	if err := s.repo.Schemas().SaveSchemaAsTemplate(ctx, schemaID, templateName); err != nil {
-		return fmt.Errorf("failed to save schema as template: %w", err)
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = types.TemplateVisibilityPrivate
 	}
-	return nil
 
+	schemaID := req.SchemaID
+	return s.repo.Templates().CreateTemplate(ctx, &types.NewTemplate{
+		OwnerID:        coachID,
+		Name:           req.Name,
+		Description:    req.Description,
+		Visibility:     visibility,
+		Source:         types.TemplateSourceSchema,
+		SourceSchemaID: &schemaID,
+		Days:           days,
+	})
 }
 
+// ValidateCoachPermission allows the client's own coach and the members of
+// that coach's team to view the client.
 func (s *coachService) ValidateCoachPermission(ctx context.Context, coachID string, userID int) error {
-	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to check coach permission: %w", err)
-	}
-
-	if !isCoach {
-		return fmt.Errorf("coach %s is not authorized for user %d", coachID, userID)
-	}
-
-	return nil
+	_, err := clientAccess(ctx, s.repo, coachID, userID, types.TeamPermissionView)
+	return err
 }
 
 func (s *coachService) GetCoachDashboard(ctx context.Context, coachID string) (*types.CoachDashboard, error) {
@@ -388,35 +421,45 @@ func (s *coachService) RemoveClientFromCoach(ctx context.Context, assignmentID i
 	return s.repo.CoachAssignments().DeactivateAssignment(ctx, assignmentID)
 }
 
+const coachTemplateListLimit = 100
+
+// GetCoachTemplates returns the coach's own library templates, most recently
+// updated first.
 func (s *coachService) GetCoachTemplates(ctx context.Context, coachID string) ([]types.WorkoutTemplate, error) {
-	return s.repo.Schemas().GetTemplatesByCoachID(ctx, coachID)
+	templates, _, err := s.repo.Templates().ListTemplates(ctx, coachID, types.TemplateFilter{Scope: types.TemplateScopeMine}, coachTemplateListLimit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coach templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (s *coachService) DeleteCoachTemplate(ctx context.Context, coachID string, templateID int) error {
+	return s.repo.Templates().DeleteTemplate(ctx, coachID, templateID)
 }
 
+// CreateSchemaFromCoachTemplate replaces the client's active schema with one
+// built from a template the coach can use.
 func (s *coachService) CreateSchemaFromCoachTemplate(ctx context.Context, coachID string, templateID int, userID int) (*types.WeeklySchemaExtended, error) {
-	_, err := s.repo.Templates().GetTemplateByID(ctx, templateID)
+	template, err := getVisibleTemplate(ctx, s.repo, coachID, templateID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get template: %w", err)
+		return nil, err
 	}
-	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, userID)
+	access, err := clientAccess(ctx, s.repo, coachID, userID, types.TeamPermissionEditSchemas)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check coach permission: %w", err)
-	}
-	if !isCoach {
-		return nil, fmt.Errorf("coach %s is not authorized for user %d", coachID, userID)
+		return nil, err
 	}
 
 	profile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user profile: %w", err)
 	}
-
-	schemaReq := &types.WeeklySchemaRequest{
-		UserID:    profile.AuthUserID,
-		WeekStart: time.Now().AddDate(0, 0, -int(time.Now().Weekday())),
+	if err := s.checkPlanAccess(ctx, access.AssignedCoachID, profile.AuthUserID); err != nil {
+		return nil, err
 	}
-	createdSchema, err := s.repo.Schemas().CreateWeeklySchema(ctx, schemaReq)
+
+	createdSchema, err := createSchemaFromTemplate(ctx, s.repo, profile.AuthUserID, template, weekStartOf(time.Now().UTC()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create schema from template: %w", err)
+		return nil, err
 	}
 	return &types.WeeklySchemaExtended{
 		WeeklySchema: *createdSchema,
@@ -425,12 +468,8 @@ func (s *coachService) CreateSchemaFromCoachTemplate(ctx context.Context, coachI
 }
 
 func (s *coachService) GetClientProgress(ctx context.Context, coachID string, userID int) (*types.UserProgressSummary, error) {
-	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check coach permission: %w", err)
-	}
-	if !isCoach {
-		return nil, fmt.Errorf("coach %s is not authorized for user %d", coachID, userID)
+	if err := s.ValidateCoachPermission(ctx, coachID, userID); err != nil {
+		return nil, err
 	}
 
 	pagination := types.PaginationParams{
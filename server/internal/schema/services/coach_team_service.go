@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// CoachTeamService lets coaches run a practice together. The owner invites
+// staff coaches, who can see every client of the team and, depending on their
+// permissions, edit schemas for and message clients of their teammates.
+type CoachTeamService interface {
+	CreateTeam(ctx context.Context, coachID string, req *types.CreateTeamRequest) (*types.CoachTeam, error)
+	GetTeam(ctx context.Context, coachID string) (*types.CoachTeam, error)
+	RenameTeam(ctx context.Context, coachID string, req *types.CreateTeamRequest) (*types.CoachTeam, error)
+	DeleteTeam(ctx context.Context, coachID string) error
+
+	InviteMember(ctx context.Context, coachID string, req *types.InviteTeamMemberRequest) (*types.TeamMember, error)
+	UpdateMember(ctx context.Context, coachID, memberID string, req *types.UpdateTeamMemberRequest) (*types.CoachTeam, error)
+	// RemoveMember lets the owner remove a staff member and staff remove
+	// themselves. Their clients stay assigned to them.
+	RemoveMember(ctx context.Context, coachID, memberID string) error
+
+	ListInvitations(ctx context.Context, coachID string) ([]types.TeamInvitation, error)
+	AcceptInvitation(ctx context.Context, coachID string, teamID int) (*types.CoachTeam, error)
+	DeclineInvitation(ctx context.Context, coachID string, teamID int) error
+
+	ListClients(ctx context.Context, coachID string) ([]types.TeamClient, error)
+	ReassignClient(ctx context.Context, coachID string, userID int, req *types.ReassignClientRequest) (*types.CoachAssignment, error)
+	GetDashboard(ctx context.Context, coachID string) (*types.TeamDashboard, error)
+
+	// CanMessageClient reports whether the coach may message the client.
+	// It is only false for team staff without the message permission.
+	CanMessageClient(ctx context.Context, coachID, clientID string) (bool, error)
+}
+
+type coachTeamService struct {
+	repo      repository.SchemaRepo
+	validator *validator.Validate
+}
+
+func NewCoachTeamService(repo repository.SchemaRepo) CoachTeamService {
+	return &coachTeamService{
+		repo:      repo,
+		validator: validator.New(),
+	}
+}
+
+// clientAccess resolves how coachID may work with a client and requires the
+// permission. Coaches who neither coach the client nor share a team with the
+// client's coach get ErrClientAccessDenied.
+func clientAccess(ctx context.Context, repo repository.SchemaRepo, coachID string, userID int, permission types.TeamPermission) (*types.ClientCoachAccess, error) {
+	access, err := repo.CoachTeams().GetClientCoachAccess(ctx, coachID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check coach permission: %w", err)
+	}
+	if access == nil || (!access.Assigned && access.TeamRole == nil) {
+		return nil, types.ErrClientAccessDenied
+	}
+	if !access.Allows(permission) {
+		return nil, types.ErrTeamPermissionDenied
+	}
+	return access, nil
+}
+
+// ownedTeam returns the coach's team and requires the coach to own it.
+func (s *coachTeamService) ownedTeam(ctx context.Context, coachID string) (*types.CoachTeam, error) {
+	team, err := s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	if team.OwnerID != coachID {
+		return nil, types.ErrTeamOwnerRequired
+	}
+	return team, nil
+}
+
+func (s *coachTeamService) CreateTeam(ctx context.Context, coachID string, req *types.CreateTeamRequest) (*types.CoachTeam, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	return s.repo.CoachTeams().CreateTeam(ctx, coachID, req.Name)
+}
+
+func (s *coachTeamService) GetTeam(ctx context.Context, coachID string) (*types.CoachTeam, error) {
+	return s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+}
+
+func (s *coachTeamService) RenameTeam(ctx context.Context, coachID string, req *types.CreateTeamRequest) (*types.CoachTeam, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	team, err := s.ownedTeam(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CoachTeams().UpdateTeamName(ctx, team.TeamID, req.Name); err != nil {
+		return nil, fmt.Errorf("failed to rename team: %w", err)
+	}
+	return s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+}
+
+// DeleteTeam disbands the team. Every coach keeps their own clients.
+func (s *coachTeamService) DeleteTeam(ctx context.Context, coachID string) error {
+	team, err := s.ownedTeam(ctx, coachID)
+	if err != nil {
+		return err
+	}
+	return s.repo.CoachTeams().DeleteTeam(ctx, team.TeamID)
+}
+
+func (s *coachTeamService) InviteMember(ctx context.Context, coachID string, req *types.InviteTeamMemberRequest) (*types.TeamMember, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	team, err := s.ownedTeam(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberID, err := s.repo.CoachTeams().GetCoachIDByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if memberID == coachID {
+		return nil, types.ErrAlreadyInTeam
+	}
+
+	member := &types.TeamMember{
+		TeamID:         team.TeamID,
+		CoachID:        memberID,
+		Username:       req.Username,
+		CanEditSchemas: req.CanEditSchemas,
+		CanMessage:     req.CanMessage,
+	}
+	if err := s.repo.CoachTeams().InviteTeamMember(ctx, member, coachID); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *coachTeamService) UpdateMember(ctx context.Context, coachID, memberID string, req *types.UpdateTeamMemberRequest) (*types.CoachTeam, error) {
+	team, err := s.ownedTeam(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CoachTeams().UpdateTeamMember(ctx, team.TeamID, memberID, req.CanEditSchemas, req.CanMessage); err != nil {
+		return nil, err
+	}
+	return s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+}
+
+func (s *coachTeamService) RemoveMember(ctx context.Context, coachID, memberID string) error {
+	team, err := s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+	if err != nil {
+		return err
+	}
+	if memberID == team.OwnerID {
+		return types.ErrTeamOwnerCannotLeave
+	}
+	if memberID != coachID && team.OwnerID != coachID {
+		return types.ErrTeamOwnerRequired
+	}
+	return s.repo.CoachTeams().RemoveTeamMember(ctx, team.TeamID, memberID)
+}
+
+func (s *coachTeamService) ListInvitations(ctx context.Context, coachID string) ([]types.TeamInvitation, error) {
+	return s.repo.CoachTeams().ListTeamInvitations(ctx, coachID)
+}
+
+// AcceptInvitation joins the team. A coach can be active in one team at a
+// time, so coaches who already belong to a team must leave it first.
+func (s *coachTeamService) AcceptInvitation(ctx context.Context, coachID string, teamID int) (*types.CoachTeam, error) {
+	if _, err := s.repo.CoachTeams().GetTeamByCoach(ctx, coachID); err == nil {
+		return nil, types.ErrAlreadyInTeam
+	} else if !errors.Is(err, types.ErrTeamNotFound) {
+		return nil, err
+	}
+
+	if err := s.repo.CoachTeams().AcceptTeamInvitation(ctx, teamID, coachID); err != nil {
+		return nil, err
+	}
+	return s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+}
+
+func (s *coachTeamService) DeclineInvitation(ctx context.Context, coachID string, teamID int) error {
+	return s.repo.CoachTeams().DeclineTeamInvitation(ctx, teamID, coachID)
+}
+
+func (s *coachTeamService) ListClients(ctx context.Context, coachID string) ([]types.TeamClient, error) {
+	team, err := s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CoachTeams().ListTeamClients(ctx, team.TeamID)
+}
+
+// ReassignClient hands a client over to another active member of the team.
+// The owner can move any client; staff can only hand over their own.
+func (s *coachTeamService) ReassignClient(ctx context.Context, coachID string, userID int, req *types.ReassignClientRequest) (*types.CoachAssignment, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	team, err := s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := clientAccess(ctx, s.repo, coachID, userID, types.TeamPermissionView)
+	if err != nil {
+		return nil, err
+	}
+	if !access.Assigned && team.OwnerID != coachID {
+		return nil, types.ErrTeamOwnerRequired
+	}
+	if !isActiveMember(team, req.CoachID) || req.CoachID == access.AssignedCoachID {
+		return nil, types.ErrReassignTarget
+	}
+
+	return s.repo.CoachTeams().ReassignClient(ctx, userID, access.AssignedCoachID, req.CoachID, coachID)
+}
+
+func isActiveMember(team *types.CoachTeam, coachID string) bool {
+	for _, member := range team.Members {
+		if member.CoachID == coachID && member.Status == types.TeamMemberActive {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *coachTeamService) GetDashboard(ctx context.Context, coachID string) (*types.TeamDashboard, error) {
+	team, err := s.repo.CoachTeams().GetTeamByCoach(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.repo.CoachTeams().GetTeamCoachSummaries(ctx, team.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team dashboard: %w", err)
+	}
+	return teamDashboard(team, summaries), nil
+}
+
+// teamDashboard adds up the per-coach summaries into the team totals.
+func teamDashboard(team *types.CoachTeam, summaries []types.TeamCoachSummary) *types.TeamDashboard {
+	dashboard := &types.TeamDashboard{
+		TeamID:  team.TeamID,
+		Name:    team.Name,
+		Coaches: summaries,
+	}
+	for _, summary := range summaries {
+		dashboard.TotalClients += summary.Clients
+		dashboard.OpenAlerts += summary.OpenAlerts
+		dashboard.CompletedSessions += summary.CompletedSessions
+		dashboard.ClientsWithoutPlan += summary.ClientsWithoutPlan
+	}
+	return dashboard
+}
+
+func (s *coachTeamService) CanMessageClient(ctx context.Context, coachID, clientID string) (bool, error) {
+	profile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByAuthID(ctx, clientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get client profile: %w", err)
+	}
+
+	access, err := s.repo.CoachTeams().GetClientCoachAccess(ctx, coachID, profile.WorkoutProfileID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check team permissions: %w", err)
+	}
+	if access == nil || access.Assigned || access.TeamRole == nil {
+		return true, nil
+	}
+	return access.Allows(types.TeamPermissionMessage), nil
+}
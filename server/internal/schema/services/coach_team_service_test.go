@@ -0,0 +1,84 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+func TestClientCoachAccessAllows(t *testing.T) {
+	owner, staff := types.TeamRoleOwner, types.TeamRoleStaff
+
+	tests := []struct {
+		name   string
+		access types.ClientCoachAccess
+		want   map[types.TeamPermission]bool
+	}{
+		{
+			name:   "assigned coach",
+			access: types.ClientCoachAccess{Assigned: true},
+			want:   map[types.TeamPermission]bool{types.TeamPermissionView: true, types.TeamPermissionEditSchemas: true, types.TeamPermissionMessage: true},
+		},
+		{
+			name:   "team owner",
+			access: types.ClientCoachAccess{TeamRole: &owner},
+			want:   map[types.TeamPermission]bool{types.TeamPermissionView: true, types.TeamPermissionEditSchemas: true, types.TeamPermissionMessage: true},
+		},
+		{
+			name:   "staff without permissions",
+			access: types.ClientCoachAccess{TeamRole: &staff},
+			want:   map[types.TeamPermission]bool{types.TeamPermissionView: true, types.TeamPermissionEditSchemas: false, types.TeamPermissionMessage: false},
+		},
+		{
+			name:   "staff who can message",
+			access: types.ClientCoachAccess{TeamRole: &staff, CanMessage: true},
+			want:   map[types.TeamPermission]bool{types.TeamPermissionView: true, types.TeamPermissionEditSchemas: false, types.TeamPermissionMessage: true},
+		},
+		{
+			name:   "other coach",
+			access: types.ClientCoachAccess{CanEditSchemas: true, CanMessage: true},
+			want:   map[types.TeamPermission]bool{types.TeamPermissionView: false, types.TeamPermissionEditSchemas: false, types.TeamPermissionMessage: false},
+		},
+	}
+
+	for _, tt := range tests {
+		for permission, want := range tt.want {
+			if got := tt.access.Allows(permission); got != want {
+				t.Errorf("%s: Allows(%s) = %v, want %v", tt.name, permission, got, want)
+			}
+		}
+	}
+}
+
+func TestTeamDashboardSumsCoaches(t *testing.T) {
+	team := &types.CoachTeam{TeamID: 4, Name: "North Gym"}
+	summaries := []types.TeamCoachSummary{
+		{CoachID: "owner", Role: types.TeamRoleOwner, Clients: 12, OpenAlerts: 3, CompletedSessions: 40, ClientsWithoutPlan: 2},
+		{CoachID: "staff", Role: types.TeamRoleStaff, Clients: 5, OpenAlerts: 0, CompletedSessions: 11, ClientsWithoutPlan: 1},
+	}
+
+	dashboard := teamDashboard(team, summaries)
+	if dashboard.TeamID != 4 || dashboard.Name != "North Gym" || len(dashboard.Coaches) != 2 {
+		t.Fatalf("unexpected dashboard %+v", dashboard)
+	}
+	if dashboard.TotalClients != 17 || dashboard.OpenAlerts != 3 || dashboard.CompletedSessions != 51 || dashboard.ClientsWithoutPlan != 3 {
+		t.Errorf("unexpected totals %+v", dashboard)
+	}
+}
+
+func TestIsActiveMemberIgnoresInvitations(t *testing.T) {
+	team := &types.CoachTeam{Members: []types.TeamMember{
+		{CoachID: "owner", Status: types.TeamMemberActive},
+		{CoachID: "pending", Status: types.TeamMemberInvited},
+	}}
+
+	if !isActiveMember(team, "owner") {
+		t.Error("owner should be an active member")
+	}
+	if isActiveMember(team, "pending") {
+		t.Error("invited coaches are not active members")
+	}
+	if isActiveMember(team, "stranger") {
+		t.Error("coaches outside the team are not members")
+	}
+}
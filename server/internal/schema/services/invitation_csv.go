@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+const maxBulkInvitations = 500
+
+type BulkInvitationStatus string
+
+const (
+	BulkInvitationInvited   BulkInvitationStatus = "invited"
+	BulkInvitationDuplicate BulkInvitationStatus = "duplicate"
+	BulkInvitationInvalid   BulkInvitationStatus = "invalid"
+	BulkInvitationFailed    BulkInvitationStatus = "failed"
+)
+
+// BulkInvitationRow is the outcome for one CSV row. Row is the line number in
+// the file, so coaches can find and fix rejected rows.
+type BulkInvitationRow struct {
+	Row          int                  `json:"row"`
+	Email        string               `json:"email"`
+	Status       BulkInvitationStatus `json:"status"`
+	InvitationID string               `json:"invitation_id,omitempty"`
+	Error        string               `json:"error,omitempty"`
+}
+
+type BulkInvitationResult struct {
+	Invited    int                 `json:"invited"`
+	Duplicates int                 `json:"duplicates"`
+	Invalid    int                 `json:"invalid"`
+	Failed     int                 `json:"failed"`
+	Rows       []BulkInvitationRow `json:"rows"`
+}
+
+type invitationCSVRow struct {
+	line      int
+	email     string
+	firstName string
+	lastName  string
+}
+
+// parseInvitationCSV reads invitees from a CSV. A header row names the email,
+// first_name and last_name columns in any order; without one, the columns are
+// read in that order.
+func parseInvitationCSV(r io.Reader) ([]invitationCSVRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns := map[string]int{"email": 0, "first_name": 1, "last_name": 2}
+	var rows []invitationCSVRow
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, types.ErrInvalidInvitationCSV
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			first = false
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if header, ok := invitationCSVHeader(record); ok {
+				columns = header
+				continue
+			}
+			if !strings.Contains(record[0], "@") {
+				return nil, types.ErrInvalidInvitationCSV
+			}
+		}
+
+		if len(rows) == maxBulkInvitations {
+			return nil, types.ErrTooManyInvitations
+		}
+		rows = append(rows, invitationCSVRow{
+			line:      line,
+			email:     csvField(record, columns["email"]),
+			firstName: csvField(record, columns["first_name"]),
+			lastName:  csvField(record, columns["last_name"]),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, types.ErrInvalidInvitationCSV
+	}
+	return rows, nil
+}
+
+// invitationCSVHeader maps column names to positions if the record is a
+// header with an email column.
+func invitationCSVHeader(record []string) (map[string]int, bool) {
+	columns := map[string]int{"email": -1, "first_name": -1, "last_name": -1}
+	for i, cell := range record {
+		name := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(cell)))
+		switch name {
+		case "email", "e_mail", "email_address":
+			columns["email"] = i
+		case "first_name", "firstname", "first":
+			columns["first_name"] = i
+		case "last_name", "lastname", "last", "surname":
+			columns["last_name"] = i
+		}
+	}
+	return columns, columns["email"] >= 0
+}
+
+func csvField(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// BulkCreateInvitations invites each row of the CSV. Rows with an invalid
+// email, an email listed earlier in the file or a pending invitation from
+// this coach are reported rather than invited. Invitation emails are queued
+// for InvitationEmailWorker, so the result covers creating the invitations
+// only.
+func (s *invitationService) BulkCreateInvitations(ctx context.Context, coachID string, r io.Reader, customMessage *string) (*BulkInvitationResult, error) {
+	rows, err := parseInvitationCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkInvitationResult{Rows: make([]BulkInvitationRow, 0, len(rows))}
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		outcome := s.inviteCSVRow(ctx, coachID, row, customMessage, seen)
+		switch outcome.Status {
+		case BulkInvitationInvited:
+			result.Invited++
+		case BulkInvitationDuplicate:
+			result.Duplicates++
+		case BulkInvitationInvalid:
+			result.Invalid++
+		case BulkInvitationFailed:
+			result.Failed++
+		}
+		result.Rows = append(result.Rows, outcome)
+	}
+	return result, nil
+}
+
+func (s *invitationService) inviteCSVRow(ctx context.Context, coachID string, row invitationCSVRow, customMessage *string, seen map[string]bool) BulkInvitationRow {
+	outcome := BulkInvitationRow{Row: row.line, Email: row.email}
+
+	address, err := mail.ParseAddress(row.email)
+	if err != nil || address.Address != row.email {
+		outcome.Status = BulkInvitationInvalid
+		outcome.Error = "invalid email address"
+		if row.email == "" {
+			outcome.Error = "email is required"
+		}
+		return outcome
+	}
+
+	key := strings.ToLower(row.email)
+	if seen[key] {
+		outcome.Status = BulkInvitationDuplicate
+		outcome.Error = "email is listed more than once"
+		return outcome
+	}
+	seen[key] = true
+
+	existing, err := s.repo.GetInvitationByCoachAndEmail(ctx, coachID, row.email)
+	if err != nil {
+		outcome.Status = BulkInvitationFailed
+		outcome.Error = "failed to check existing invitations"
+		return outcome
+	}
+	if existing != nil && existing.ExpiresAt.After(time.Now()) {
+		outcome.Status = BulkInvitationDuplicate
+		outcome.InvitationID = existing.ID
+		outcome.Error = "client already has a pending invitation"
+		return outcome
+	}
+
+	req := &CreateInvitationRequest{
+		CoachID:       coachID,
+		Email:         row.email,
+		FirstName:     optionalString(row.firstName),
+		LastName:      optionalString(row.lastName),
+		CustomMessage: customMessage,
+	}
+	invitation, err := s.createInvitation(ctx, req, true)
+	if err != nil {
+		outcome.Status = BulkInvitationFailed
+		outcome.Error = "failed to create invitation"
+		return outcome
+	}
+
+	outcome.Status = BulkInvitationInvited
+	outcome.InvitationID = invitation.ID
+	return outcome
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -8,19 +8,48 @@ import (
 	"log"
 	"time"
 
+	authService "github.com/tdmdh/fit-up-server/internal/auth/services"
+	authTypes "github.com/tdmdh/fit-up-server/internal/auth/types"
 	"github.com/tdmdh/fit-up-server/internal/schema/repository"
 )
 
+const (
+	// Queued invitation emails go out in small batches, one every
+	// invitationEmailInterval, to stay under the email provider's rate limit.
+	invitationEmailBatchSize    = 20
+	invitationEmailInterval     = 500 * time.Millisecond
+	invitationEmailPollInterval = 15 * time.Second
+	invitationEmailLease        = 5 * time.Minute
+	invitationEmailRetryDelay   = 10 * time.Minute
+	invitationEmailMaxAttempts  = 3
+)
+
+// CoachLookup finds the inviting coach so emails can name them. The auth
+// user store satisfies it.
+type CoachLookup interface {
+	GetUserByID(ctx context.Context, id string) (*authTypes.User, error)
+}
+
 type invitationService struct {
-	repo repository.CoachInvitationRepo
-	// emailService *email.Service // TODO: Add email service when implemented
+	repo          repository.CoachInvitationRepo
+	coaches       CoachLookup
+	sendEmail     func(toEmail string, invite authService.CoachInvitationEmail) error
+	emailInterval time.Duration
 }
+
 func NewInvitationService(repo repository.CoachInvitationRepo) InvitationService {
 	return &invitationService{
-		repo: repo,
+		repo:          repo,
+		sendEmail:     authService.SendCoachInvitationEmail,
+		emailInterval: invitationEmailInterval,
 	}
 }
 
+// SetCoachLookup lets invitation emails name the coach instead of "Your coach".
+func (s *invitationService) SetCoachLookup(coaches CoachLookup) {
+	s.coaches = coaches
+}
+
 type CreateInvitationRequest struct {
 	CoachID       string  `json:"coach_id"`
 	Email         string  `json:"email"`
@@ -40,6 +69,7 @@ type InvitationResponse struct {
 	CreatedAt       time.Time  `json:"created_at"`
 	AcceptedAt      *time.Time `json:"accepted_at"`
 	InvitationToken string     `json:"invitation_token,omitempty"`
+	EmailSent       bool       `json:"email_sent"`
 }
 
 func generateInvitationToken() (string, error) {
@@ -58,6 +88,12 @@ func generateID() string {
 }
 
 func (s *invitationService) CreateInvitation(ctx context.Context, req *CreateInvitationRequest) (*InvitationResponse, error) {
+	return s.createInvitation(ctx, req, false)
+}
+
+// createInvitation stores a new invitation. With queueEmail the email is left
+// to SendQueuedInvitationEmails instead of being sent before returning.
+func (s *invitationService) createInvitation(ctx context.Context, req *CreateInvitationRequest, queueEmail bool) (*InvitationResponse, error) {
 	existing, err := s.repo.GetInvitationByCoachAndEmail(ctx, req.CoachID, req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing invitation: %w", err)
@@ -99,6 +135,7 @@ func (s *invitationService) CreateInvitation(ctx context.Context, req *CreateInv
 		Status:          "pending",
 		CustomMessage:   req.CustomMessage,
 		ExpiresAt:       time.Now().Add(7 * 24 * time.Hour), // 7 days expiration
+		EmailQueued:     queueEmail,
 	}
 
 	err = s.repo.CreateInvitation(ctx, invitation)
@@ -106,8 +143,11 @@ func (s *invitationService) CreateInvitation(ctx context.Context, req *CreateInv
 		return nil, fmt.Errorf("failed to create invitation: %w", err)
 	}
 
-	// TODO: Send invitation email
-	log.Printf("[CreateInvitation] Created invitation for %s (token: %s)", req.Email, token)
+	log.Printf("[CreateInvitation] Created invitation %s for coach %s", invitation.ID, invitation.CoachID)
+	emailSent := false
+	if !queueEmail {
+		emailSent = s.sendInvitationEmail(ctx, invitation)
+	}
 
 	return &InvitationResponse{
 		ID:              invitation.ID,
@@ -119,9 +159,70 @@ func (s *invitationService) CreateInvitation(ctx context.Context, req *CreateInv
 		ExpiresAt:       invitation.ExpiresAt,
 		CreatedAt:       invitation.CreatedAt,
 		InvitationToken: token, // Include token in response for testing
+		EmailSent:       emailSent,
 	}, nil
 }
 
+// sendInvitationEmail emails the invitation link. A failed delivery is logged
+// and reported as false; the invitation stays valid and can be resent.
+func (s *invitationService) sendInvitationEmail(ctx context.Context, invitation *repository.CoachInvitation) bool {
+	invite := authService.CoachInvitationEmail{
+		Token:     invitation.InvitationToken,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+	if invitation.FirstName != nil {
+		invite.FirstName = *invitation.FirstName
+	}
+	if invitation.CustomMessage != nil {
+		invite.CustomMessage = *invitation.CustomMessage
+	}
+	if s.coaches != nil {
+		if coach, err := s.coaches.GetUserByID(ctx, invitation.CoachID); err != nil {
+			log.Printf("[Invitation] Failed to load coach %s: %v", invitation.CoachID, err)
+		} else if coach.Name != "" {
+			invite.CoachName = coach.Name
+		} else {
+			invite.CoachName = coach.Username
+		}
+	}
+
+	if err := s.sendEmail(invitation.Email, invite); err != nil {
+		log.Printf("[Invitation] Failed to email invitation %s: %v", invitation.ID, err)
+		return false
+	}
+	return true
+}
+
+// SendQueuedInvitationEmails sends a batch of queued invitation emails, pausing
+// between sends, and returns how many were delivered. Failed emails are
+// retried on a later run until invitationEmailMaxAttempts is reached.
+func (s *invitationService) SendQueuedInvitationEmails(ctx context.Context) (int, error) {
+	invitations, err := s.repo.ClaimQueuedInvitationEmails(ctx, invitationEmailBatchSize, invitationEmailLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i, invitation := range invitations {
+		if i > 0 && s.emailInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return sent, ctx.Err()
+			case <-time.After(s.emailInterval):
+			}
+		}
+
+		delivered := s.sendInvitationEmail(ctx, invitation)
+		if delivered {
+			sent++
+		}
+		if err := s.repo.FinishInvitationEmail(ctx, invitation.ID, delivered, invitationEmailRetryDelay, invitationEmailMaxAttempts); err != nil {
+			log.Printf("[Invitation] Failed to record email for invitation %s: %v", invitation.ID, err)
+		}
+	}
+	return sent, nil
+}
+
 // GetInvitations retrieves all invitations for a coach
 func (s *invitationService) GetInvitations(ctx context.Context, coachID string) ([]*InvitationResponse, error) {
 	// Expire old invitations first
@@ -153,10 +254,10 @@ func (s *invitationService) GetInvitations(ctx context.Context, coachID string)
 	return responses, nil
 }
 
-// ResendInvitation resends an invitation (creates a new token)
-func (s *invitationService) ResendInvitation(ctx context.Context, invitationID string) (*InvitationResponse, error) {
+// ResendInvitation resends one of the coach's invitations with a new token
+func (s *invitationService) ResendInvitation(ctx context.Context, coachID, invitationID string) (*InvitationResponse, error) {
 	// Get the invitation
-	invitations, err := s.repo.GetInvitationsByCoachID(ctx, "")
+	invitations, err := s.repo.GetInvitationsByCoachID(ctx, coachID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get invitation: %w", err)
 	}
@@ -206,8 +307,8 @@ func (s *invitationService) ResendInvitation(ctx context.Context, invitationID s
 		return nil, fmt.Errorf("failed to create new invitation: %w", err)
 	}
 
-	// TODO: Send invitation email
-	log.Printf("[ResendInvitation] Resent invitation for %s (token: %s)", invitation.Email, token)
+	log.Printf("[ResendInvitation] Replaced invitation %s with %s", invitationID, newInvitation.ID)
+	emailSent := s.sendInvitationEmail(ctx, newInvitation)
 
 	return &InvitationResponse{
 		ID:              newInvitation.ID,
@@ -219,6 +320,7 @@ func (s *invitationService) ResendInvitation(ctx context.Context, invitationID s
 		ExpiresAt:       newInvitation.ExpiresAt,
 		CreatedAt:       newInvitation.CreatedAt,
 		InvitationToken: token,
+		EmailSent:       emailSent,
 	}, nil
 }
 
@@ -262,3 +364,31 @@ func (s *invitationService) AcceptInvitation(ctx context.Context, token, userID
 
 	return nil
 }
+
+// InvitationEmailWorker sends the invitation emails queued by bulk invites.
+type InvitationEmailWorker struct {
+	service InvitationService
+}
+
+func NewInvitationEmailWorker(service InvitationService) *InvitationEmailWorker {
+	return &InvitationEmailWorker{service: service}
+}
+
+func (w *InvitationEmailWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(invitationEmailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := w.service.SendQueuedInvitationEmails(ctx); err != nil {
+			log.Printf("Invitation email sending failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("Sent %d queued invitation emails", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	authService "github.com/tdmdh/fit-up-server/internal/auth/services"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+type fakeInvitationRepo struct {
+	repository.CoachInvitationRepo
+	pending map[string]*repository.CoachInvitation
+	created []*repository.CoachInvitation
+	// attempts counts recorded email attempts by invitation ID
+	attempts map[string]int
+}
+
+func (f *fakeInvitationRepo) GetInvitationByCoachAndEmail(ctx context.Context, coachID, email string) (*repository.CoachInvitation, error) {
+	return f.pending[strings.ToLower(email)], nil
+}
+
+func (f *fakeInvitationRepo) CreateInvitation(ctx context.Context, inv *repository.CoachInvitation) error {
+	f.created = append(f.created, inv)
+	return nil
+}
+
+// ClaimQueuedInvitationEmails ignores the lease; every queued invitation that
+// has attempts left is due.
+func (f *fakeInvitationRepo) ClaimQueuedInvitationEmails(ctx context.Context, limit int, lease time.Duration) ([]*repository.CoachInvitation, error) {
+	var claimed []*repository.CoachInvitation
+	for _, inv := range f.created {
+		if inv.EmailQueued && len(claimed) < limit {
+			claimed = append(claimed, inv)
+		}
+	}
+	return claimed, nil
+}
+
+func (f *fakeInvitationRepo) FinishInvitationEmail(ctx context.Context, id string, sent bool, retryAfter time.Duration, maxAttempts int) error {
+	if f.attempts == nil {
+		f.attempts = make(map[string]int)
+	}
+	f.attempts[id]++
+	for _, inv := range f.created {
+		if inv.ID == id && (sent || f.attempts[id] >= maxAttempts) {
+			inv.EmailQueued = false
+		}
+	}
+	return nil
+}
+
+func (f *fakeInvitationRepo) UpdateInvitationStatus(ctx context.Context, id, status string) error {
+	return nil
+}
+
+func TestParseInvitationCSVWithHeader(t *testing.T) {
+	csv := "\ufeffLast Name,Email,First Name\nDoe, jane@example.com ,Jane\n"
+
+	rows, err := parseInvitationCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	if row := rows[0]; row.line != 2 || row.email != "jane@example.com" || row.firstName != "Jane" || row.lastName != "Doe" {
+		t.Errorf("unexpected row %+v", row)
+	}
+}
+
+func TestParseInvitationCSVWithoutHeader(t *testing.T) {
+	rows, err := parseInvitationCSV(strings.NewReader("a@example.com\nb@example.com,Bo\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[1].email != "b@example.com" || rows[1].firstName != "Bo" {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+}
+
+func TestParseInvitationCSVRejectsBadFiles(t *testing.T) {
+	cases := map[string]struct {
+		csv  string
+		want error
+	}{
+		"empty":         {"", types.ErrInvalidInvitationCSV},
+		"header only":   {"email,first_name\n", types.ErrInvalidInvitationCSV},
+		"no email":      {"name,phone\nJane,123\n", types.ErrInvalidInvitationCSV},
+		"too many rows": {strings.Repeat("a@example.com\n", maxBulkInvitations+1), types.ErrTooManyInvitations},
+	}
+
+	for name, tc := range cases {
+		if _, err := parseInvitationCSV(strings.NewReader(tc.csv)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", name, err, tc.want)
+		}
+	}
+}
+
+func TestBulkCreateInvitationsReportsEachRow(t *testing.T) {
+	repo := &fakeInvitationRepo{pending: map[string]*repository.CoachInvitation{
+		"pending@example.com": {ID: "existing", Email: "pending@example.com", ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	var emailed []string
+	svc := &invitationService{repo: repo, sendEmail: func(to string, invite authService.CoachInvitationEmail) error {
+		if to == "bounce@example.com" {
+			return errors.New("mailbox unavailable")
+		}
+		emailed = append(emailed, to)
+		return nil
+	}}
+
+	csv := "email,first_name\nnew@example.com,Ann\nNEW@example.com,Ann\nnot-an-email,Bob\npending@example.com,Cat\nbounce@example.com,Dan\n,Eve\n"
+	result, err := svc.BulkCreateInvitations(context.Background(), "coach-1", strings.NewReader(csv), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Invited != 2 || result.Duplicates != 2 || result.Invalid != 2 || result.Failed != 0 {
+		t.Errorf("unexpected totals %+v", result)
+	}
+	want := []BulkInvitationStatus{BulkInvitationInvited, BulkInvitationDuplicate, BulkInvitationInvalid, BulkInvitationDuplicate, BulkInvitationInvited, BulkInvitationInvalid}
+	for i, row := range result.Rows {
+		if row.Status != want[i] || row.Row != i+2 {
+			t.Errorf("row %d: got %s on line %d, want %s on line %d", i, row.Status, row.Row, want[i], i+2)
+		}
+	}
+	if result.Rows[3].InvitationID != "existing" {
+		t.Errorf("pending duplicate should point at the existing invitation, got %q", result.Rows[3].InvitationID)
+	}
+	if len(repo.created) != 2 || len(emailed) != 0 {
+		t.Fatalf("created %d invitations and emailed %v, want 2 created and no emails yet", len(repo.created), emailed)
+	}
+	for _, inv := range repo.created {
+		if !inv.EmailQueued {
+			t.Errorf("invitation for %s should queue its email", inv.Email)
+		}
+	}
+}
+
+func TestSendQueuedInvitationEmailsRetriesFailures(t *testing.T) {
+	repo := &fakeInvitationRepo{}
+	var emailed []string
+	svc := &invitationService{repo: repo, sendEmail: func(to string, invite authService.CoachInvitationEmail) error {
+		if to == "bounce@example.com" {
+			return errors.New("mailbox unavailable")
+		}
+		emailed = append(emailed, to)
+		return nil
+	}}
+
+	csv := "email\nnew@example.com\nbounce@example.com\n"
+	if _, err := svc.BulkCreateInvitations(context.Background(), "coach-1", strings.NewReader(csv), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent, err := svc.SendQueuedInvitationEmails(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 1 || len(emailed) != 1 || emailed[0] != "new@example.com" {
+		t.Errorf("sent %d and emailed %v, want only new@example.com", sent, emailed)
+	}
+
+	// The bounce stays queued until it runs out of attempts
+	for i := 1; i < invitationEmailMaxAttempts; i++ {
+		if sent, err := svc.SendQueuedInvitationEmails(context.Background()); err != nil || sent != 0 {
+			t.Fatalf("retry %d: sent %d, err %v", i, sent, err)
+		}
+	}
+	bounce := repo.created[1]
+	if bounce.EmailQueued || repo.attempts[bounce.ID] != invitationEmailMaxAttempts {
+		t.Errorf("bounce should be dropped after %d attempts, got %d", invitationEmailMaxAttempts, repo.attempts[bounce.ID])
+	}
+	if repo.attempts[repo.created[0].ID] != 1 {
+		t.Errorf("a delivered email should not be sent again, got %d attempts", repo.attempts[repo.created[0].ID])
+	}
+}
@@ -1652,28 +1652,13 @@ func (s *planGenerationServiceImpl) GetTemplateByID(ctx context.Context, templat
 	return s.repo.Templates().GetTemplateByID(ctx, templateID)
 }
 
+// ListTemplates lists the public part of the template library.
 func (s *planGenerationServiceImpl) ListTemplates(ctx context.Context, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
-	return s.repo.Templates().ListTemplates(ctx, pagination)
-}
-
-func (s *planGenerationServiceImpl) FilterTemplates(ctx context.Context, filter types.TemplateFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
-	return s.repo.Templates().FilterTemplates(ctx, filter, pagination)
-}
-
-func (s *planGenerationServiceImpl) SearchTemplates(ctx context.Context, query string, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
-	return s.repo.Templates().SearchTemplates(ctx, query, pagination)
-}
-
-func (s *planGenerationServiceImpl) GetTemplatesByLevel(ctx context.Context, level types.FitnessLevel) ([]types.WorkoutTemplate, error) {
-	return s.repo.Templates().GetTemplatesByLevel(ctx, level)
-}
-
-func (s *planGenerationServiceImpl) GetTemplatesByGoal(ctx context.Context, goal types.FitnessGoal) ([]types.WorkoutTemplate, error) {
-	return s.repo.Templates().GetTemplatesByGoal(ctx, goal)
-}
-
-func (s *planGenerationServiceImpl) GetRecommendedTemplates(ctx context.Context, userID int, count int) ([]types.WorkoutTemplate, error) {
-	return s.repo.Templates().GetRecommendedTemplates(ctx, userID, count)
+	templates, total, err := s.repo.Templates().ListTemplates(ctx, "", types.TemplateFilter{Scope: types.TemplateScopePublic}, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templatePage(templates, total, pagination), nil
 }
 
 func (s *planGenerationServiceImpl) GetPopularTemplates(ctx context.Context, count int) ([]types.WorkoutTemplate, error) {
@@ -1724,29 +1709,24 @@ func (s *planGenerationServiceImpl) GetWeeklySchemaHistory(ctx context.Context,
 	return s.repo.Schemas().GetWeeklySchemaHistory(ctx, authUserID, limit)
 }
 
+// CreateWeeklySchemaFromTemplate builds the user's schema for the week from a
+// library template the user can see.
 func (s *planGenerationServiceImpl) CreateWeeklySchemaFromTemplate(ctx context.Context, userID, templateID int, weekStart time.Time) (*types.WeeklySchemaWithWorkouts, error) {
 	authUserID, err := s.repo.WorkoutProfiles().LookupAuthUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	schemaRequest := &types.WeeklySchemaRequest{
-		UserID:    authUserID,
-		WeekStart: weekStart,
+	template, err := getVisibleTemplate(ctx, s.repo, authUserID, templateID)
+	if err != nil {
+		return nil, err
 	}
 
-	schema, err := s.repo.Schemas().CreateWeeklySchema(ctx, schemaRequest)
+	schema, err := createSchemaFromTemplate(ctx, s.repo, authUserID, template, weekStart)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create weekly schema: %w", err)
+		return nil, err
 	}
-
-	return &types.WeeklySchemaWithWorkouts{
-		SchemaID:  schema.SchemaID,
-		UserID:    schema.UserID,
-		WeekStart: schema.WeekStart,
-		Active:    schema.Active,
-		Workouts:  []types.WorkoutWithExercises{},
-	}, nil
+	return s.repo.Workouts().GetSchemaWithAllWorkouts(ctx, schema.SchemaID)
 }
 
 func (s *planGenerationServiceImpl) analyzeAdaptationPatterns(adaptations []types.PlanAdaptation) map[string]any {
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+	"github.com/tdmdh/fit-up-server/shared/utils"
+)
+
+type ProgressPhotoService interface {
+	UploadPhoto(ctx context.Context, userID string, req *types.UploadProgressPhotoRequest, data []byte) (*types.ProgressPhoto, error)
+	ListMyPhotos(ctx context.Context, userID string, filter types.ProgressPhotoFilter, pagination types.PaginationParams) ([]types.ProgressPhoto, error)
+	CompareMyPhotos(ctx context.Context, userID string, from, to time.Time) (*types.ProgressPhotoComparison, error)
+	DeletePhoto(ctx context.Context, userID string, photoID int64) error
+
+	// Coach routes address clients by workout profile ID, like the other coach client routes.
+	ListClientPhotos(ctx context.Context, coachID string, clientProfileID int, filter types.ProgressPhotoFilter, pagination types.PaginationParams) ([]types.ProgressPhoto, error)
+	CompareClientPhotos(ctx context.Context, coachID string, clientProfileID int, from, to time.Time) (*types.ProgressPhotoComparison, error)
+
+	// OpenPhoto returns the image for the owner or the owner's assigned coach.
+	OpenPhoto(ctx context.Context, viewerID string, photoID int64) (*types.ProgressPhoto, *os.File, error)
+}
+
+type progressPhotoService struct {
+	repo      repository.SchemaRepo
+	validator *validator.Validate
+	dir       string
+}
+
+// NewProgressPhotoService stores images under dir, which must not be served
+// as static files.
+func NewProgressPhotoService(repo repository.SchemaRepo, dir string) ProgressPhotoService {
+	return &progressPhotoService{
+		repo:      repo,
+		validator: validator.New(),
+		dir:       dir,
+	}
+}
+
+func progressPhotoImagePath(photoID int64) string {
+	return fmt.Sprintf("/progress-photos/%d/image", photoID)
+}
+
+func withImagePaths(photos []types.ProgressPhoto) []types.ProgressPhoto {
+	for i := range photos {
+		photos[i].ImagePath = progressPhotoImagePath(photos[i].PhotoID)
+	}
+	return photos
+}
+
+// UploadPhoto strips the image's metadata before anything is written to disk,
+// so location and device details never reach storage.
+func (s *progressPhotoService) UploadPhoto(ctx context.Context, userID string, req *types.UploadProgressPhotoRequest, data []byte) (*types.ProgressPhoto, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	if len(data) > utils.MaxFileSize {
+		return nil, types.ErrProgressPhotoTooLarge
+	}
+
+	takenOn := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.TakenOn != "" {
+		parsed, err := time.Parse("2006-01-02", req.TakenOn)
+		if err != nil {
+			return nil, err
+		}
+		takenOn = parsed
+	}
+
+	image, err := utils.StripImageMetadata(data)
+	if err != nil {
+		if errors.Is(err, utils.ErrUnsupportedImage) {
+			return nil, types.ErrInvalidProgressPhoto
+		}
+		return nil, fmt.Errorf("%w: %v", types.ErrInvalidProgressPhoto, err)
+	}
+
+	filePath, err := s.writeFile(userID, image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store progress photo: %w", err)
+	}
+
+	photo := &types.ProgressPhoto{
+		UserID:      userID,
+		Pose:        req.Pose,
+		TakenOn:     takenOn,
+		Notes:       req.Notes,
+		ContentType: image.ContentType,
+		Width:       image.Width,
+		Height:      image.Height,
+		SizeBytes:   int64(len(image.Data)),
+		FilePath:    filePath,
+	}
+	if err := s.repo.ProgressPhotos().CreateProgressPhoto(ctx, photo); err != nil {
+		os.Remove(filePath)
+		return nil, fmt.Errorf("failed to save progress photo: %w", err)
+	}
+
+	photo.ImagePath = progressPhotoImagePath(photo.PhotoID)
+	return photo, nil
+}
+
+// writeFile stores the image under the user's own directory, readable only
+// by the server process.
+func (s *progressPhotoService) writeFile(userID string, image *utils.SanitizedImage) (string, error) {
+	dir := filepath.Join(s.dir, filepath.Base(userID))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(dir, "upload-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(image.Data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	finalPath := filepath.Join(dir, uuid.NewString()+image.Ext)
+	if err := os.Rename(tmp.Name(), finalPath); err != nil {
+		return "", err
+	}
+	return finalPath, nil
+}
+
+func (s *progressPhotoService) ListMyPhotos(ctx context.Context, userID string, filter types.ProgressPhotoFilter, pagination types.PaginationParams) ([]types.ProgressPhoto, error) {
+	photos, err := s.repo.ProgressPhotos().ListProgressPhotos(ctx, userID, filter, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return withImagePaths(photos), nil
+}
+
+func (s *progressPhotoService) CompareMyPhotos(ctx context.Context, userID string, from, to time.Time) (*types.ProgressPhotoComparison, error) {
+	return s.compare(ctx, userID, from, to)
+}
+
+func (s *progressPhotoService) DeletePhoto(ctx context.Context, userID string, photoID int64) error {
+	filePath, err := s.repo.ProgressPhotos().DeleteProgressPhoto(ctx, userID, photoID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove progress photo file %s: %v", filePath, err)
+	}
+	return nil
+}
+
+// clientAuthID checks the coach assignment and returns the client's auth user ID.
+func (s *progressPhotoService) clientAuthID(ctx context.Context, coachID string, clientProfileID int) (string, error) {
+	isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, coachID, clientProfileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check coach permission: %w", err)
+	}
+	if !isCoach {
+		return "", types.ErrClientAccessDenied
+	}
+	return s.repo.WorkoutProfiles().LookupAuthUserID(ctx, clientProfileID)
+}
+
+func (s *progressPhotoService) ListClientPhotos(ctx context.Context, coachID string, clientProfileID int, filter types.ProgressPhotoFilter, pagination types.PaginationParams) ([]types.ProgressPhoto, error) {
+	clientID, err := s.clientAuthID(ctx, coachID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListMyPhotos(ctx, clientID, filter, pagination)
+}
+
+func (s *progressPhotoService) CompareClientPhotos(ctx context.Context, coachID string, clientProfileID int, from, to time.Time) (*types.ProgressPhotoComparison, error) {
+	clientID, err := s.clientAuthID(ctx, coachID, clientProfileID)
+	if err != nil {
+		return nil, err
+	}
+	return s.compare(ctx, clientID, from, to)
+}
+
+func (s *progressPhotoService) compare(ctx context.Context, userID string, from, to time.Time) (*types.ProgressPhotoComparison, error) {
+	if !from.Before(to) {
+		return nil, types.ErrInvalidDateRange
+	}
+
+	before, err := s.repo.ProgressPhotos().GetClosestProgressPhotos(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.repo.ProgressPhotos().GetClosestProgressPhotos(ctx, userID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ProgressPhotoComparison{
+		From:  from,
+		To:    to,
+		Pairs: pairProgressPhotos(withImagePaths(before), withImagePaths(after), from, to),
+	}, nil
+}
+
+// OpenPhoto checks access through the coach assignment rather than trusting
+// the photo ID, so an image URL is useless to anyone else.
+func (s *progressPhotoService) OpenPhoto(ctx context.Context, viewerID string, photoID int64) (*types.ProgressPhoto, *os.File, error) {
+	photo, err := s.repo.ProgressPhotos().GetProgressPhoto(ctx, photoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if photo.UserID != viewerID {
+		profile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByAuthID(ctx, photo.UserID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, types.ErrProgressPhotoNotFound
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get photo owner: %w", err)
+		}
+		isCoach, err := s.repo.CoachAssignments().IsCoachForUser(ctx, viewerID, profile.WorkoutProfileID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check coach permission: %w", err)
+		}
+		if !isCoach {
+			// Same answer as a missing photo so IDs can't be probed
+			return nil, nil, types.ErrProgressPhotoNotFound
+		}
+	}
+
+	file, err := os.Open(photo.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, types.ErrProgressPhotoNotFound
+		}
+		return nil, nil, err
+	}
+	photo.ImagePath = progressPhotoImagePath(photo.PhotoID)
+	return photo, file, nil
+}
+
+// pairProgressPhotos lines up the photos closest to from and to by pose. When
+// a pose has only one photo it is the closest to both dates, so it is kept on
+// the side it is nearer to and the other side stays empty.
+func pairProgressPhotos(before, after []types.ProgressPhoto, from, to time.Time) []types.ProgressPhotoPair {
+	byPose := func(photos []types.ProgressPhoto) map[types.ProgressPhotoPose]*types.ProgressPhoto {
+		m := make(map[types.ProgressPhotoPose]*types.ProgressPhoto, len(photos))
+		for i := range photos {
+			m[photos[i].Pose] = &photos[i]
+		}
+		return m
+	}
+	befores, afters := byPose(before), byPose(after)
+
+	pairs := make([]types.ProgressPhotoPair, 0, len(types.AllProgressPhotoPoses))
+	for _, pose := range types.AllProgressPhotoPoses {
+		pair := types.ProgressPhotoPair{Pose: pose, Before: befores[pose], After: afters[pose]}
+		if pair.Before == nil && pair.After == nil {
+			continue
+		}
+
+		if pair.Before != nil && pair.After != nil && pair.Before.PhotoID == pair.After.PhotoID {
+			if absDuration(pair.Before.TakenOn.Sub(from)) <= absDuration(pair.After.TakenOn.Sub(to)) {
+				pair.After = nil
+			} else {
+				pair.Before = nil
+			}
+		}
+
+		if pair.Before != nil && pair.After != nil {
+			days := int(pair.After.TakenOn.Sub(pair.Before.TakenOn).Hours() / 24)
+			pair.DaysBetween = &days
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+func TestPairProgressPhotos(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	photo := func(id int64, pose types.ProgressPhotoPose, takenOn time.Time) types.ProgressPhoto {
+		return types.ProgressPhoto{PhotoID: id, Pose: pose, TakenOn: takenOn}
+	}
+	from, to := day(time.January, 1), day(time.April, 1)
+
+	before := []types.ProgressPhoto{
+		photo(1, types.PoseFront, day(time.January, 3)),
+		photo(2, types.PoseSide, day(time.March, 25)),
+	}
+	after := []types.ProgressPhoto{
+		photo(3, types.PoseFront, day(time.March, 30)),
+		photo(2, types.PoseSide, day(time.March, 25)),
+	}
+
+	pairs := pairProgressPhotos(before, after, from, to)
+	if len(pairs) != 2 {
+		t.Fatalf("expected poses without photos to be left out, got %+v", pairs)
+	}
+
+	front := pairs[0]
+	if front.Pose != types.PoseFront || front.Before.PhotoID != 1 || front.After.PhotoID != 3 {
+		t.Errorf("unexpected front pair: %+v", front)
+	}
+	if front.DaysBetween == nil || *front.DaysBetween != 87 {
+		t.Errorf("days between = %v, want 87", front.DaysBetween)
+	}
+
+	// The only side photo is nearest to both dates; it belongs to the later one
+	side := pairs[1]
+	if side.Before != nil || side.After == nil || side.After.PhotoID != 2 || side.DaysBetween != nil {
+		t.Errorf("single photo should only fill the nearer side: %+v", side)
+	}
+}
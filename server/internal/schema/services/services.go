@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/tdmdh/fit-up-server/internal/schema/repository"
@@ -47,11 +48,6 @@ type PlanGenerationService interface {
 
 	GetTemplateByID(ctx context.Context, templateID int) (*types.WorkoutTemplate, error)
 	ListTemplates(ctx context.Context, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
-	FilterTemplates(ctx context.Context, filter types.TemplateFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
-	SearchTemplates(ctx context.Context, query string, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
-	GetTemplatesByLevel(ctx context.Context, level types.FitnessLevel) ([]types.WorkoutTemplate, error)
-	GetTemplatesByGoal(ctx context.Context, goal types.FitnessGoal) ([]types.WorkoutTemplate, error)
-	GetRecommendedTemplates(ctx context.Context, userID int, count int) ([]types.WorkoutTemplate, error)
 	GetPopularTemplates(ctx context.Context, count int) ([]types.WorkoutTemplate, error)
 
 	GetWeeklySchemaByID(ctx context.Context, schemaID int) (*types.WeeklySchema, error)
@@ -73,20 +69,33 @@ type CoachService interface {
 	UpdateManualSchema(ctx context.Context, coachID string, schemaID int, req *types.ManualSchemaRequest) (*types.WeeklySchemaExtended, error)
 	DeleteSchema(ctx context.Context, coachID string, schemaID int) error
 	CloneSchemaToClient(ctx context.Context, coachID string, sourceSchemaID int, targetUserID int) (*types.WeeklySchemaExtended, error)
-	SaveSchemaAsTemplate(ctx context.Context, coachID string, schemaID int, templateName string) error
+	SaveSchemaAsTemplate(ctx context.Context, coachID string, req *types.SaveSchemaTemplateRequest) (*types.WorkoutTemplate, error)
 	GetCoachTemplates(ctx context.Context, coachID string) ([]types.WorkoutTemplate, error)
+	DeleteCoachTemplate(ctx context.Context, coachID string, templateID int) error
 	CreateSchemaFromCoachTemplate(ctx context.Context, coachID string, templateID int, userID int) (*types.WeeklySchemaExtended, error)
 	GetClientProgress(ctx context.Context, coachID string, userID int) (*types.UserProgressSummary, error)
+	GetClientTimeline(ctx context.Context, coachID string, userID int, query types.TimelineQuery) (*types.TimelinePage, error)
 	GetCoachForUser(ctx context.Context, userID int) (*types.CoachAssignment, error)
 	ValidateCoachPermission(ctx context.Context, coachID string, userID int) error
+	SummarizeSharedPlan(ctx context.Context, coachID string, kind types.SharedPlanKind, sourceID int) (*types.SharedPlanSummary, error)
+	CloneSharedPlanToClient(ctx context.Context, coachID string, clientAuthID string, kind types.SharedPlanKind, sourceID int) (*types.WeeklySchemaExtended, error)
+	SetCoachingAccess(access CoachingAccess)
 }
 
 type InvitationService interface {
 	CreateInvitation(ctx context.Context, req *CreateInvitationRequest) (*InvitationResponse, error)
 	GetInvitations(ctx context.Context, coachID string) ([]*InvitationResponse, error)
-	ResendInvitation(ctx context.Context, invitationID string) (*InvitationResponse, error)
+	ResendInvitation(ctx context.Context, coachID, invitationID string) (*InvitationResponse, error)
 	CancelInvitation(ctx context.Context, invitationID string) error
 	AcceptInvitation(ctx context.Context, token, userID string) error
+
+	// BulkCreateInvitations invites every client listed in a CSV with an
+	// email column and optional first_name and last_name columns.
+	BulkCreateInvitations(ctx context.Context, coachID string, csv io.Reader, customMessage *string) (*BulkInvitationResult, error)
+	// SendQueuedInvitationEmails sends a throttled batch of the emails queued
+	// by bulk invites and returns how many were sent.
+	SendQueuedInvitationEmails(ctx context.Context) (int, error)
+	SetCoachLookup(coaches CoachLookup)
 }
 type SchemaService interface {
 	Exercises() ExerciseService
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// sharedPlan is a plan of any kind flattened into the workouts a client receives.
+type sharedPlan struct {
+	summary  types.SharedPlanSummary
+	workouts []sharedPlanWorkout
+}
+
+type sharedPlanWorkout struct {
+	dayOfWeek int
+	focus     string
+	exercises []types.WorkoutExerciseRequest
+}
+
+func (s *coachService) SummarizeSharedPlan(ctx context.Context, coachID string, kind types.SharedPlanKind, sourceID int) (*types.SharedPlanSummary, error) {
+	plan, err := s.loadSharedPlan(ctx, coachID, kind, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	return &plan.summary, nil
+}
+
+// CloneSharedPlanToClient copies a shared plan into the client's account. Full
+// plans replace the client's active schema; a single workout is added to it.
+// The writes happen in one transaction, joining the caller's if ctx has one.
+func (s *coachService) CloneSharedPlanToClient(ctx context.Context, coachID string, clientAuthID string, kind types.SharedPlanKind, sourceID int) (*types.WeeklySchemaExtended, error) {
+	clientProfile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByAuthID(ctx, clientAuthID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client profile: %w", err)
+	}
+	access, err := clientAccess(ctx, s.repo, coachID, clientProfile.WorkoutProfileID, types.TeamPermissionEditSchemas)
+	if errors.Is(err, types.ErrClientAccessDenied) {
+		return nil, types.ErrSharedPlanDenied
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPlanAccess(ctx, access.AssignedCoachID, clientAuthID); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.loadSharedPlan(ctx, coachID, kind, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *types.WeeklySchema
+	if kind == types.SharedPlanWorkout {
+		schema, err = s.repo.Schemas().GetActiveWeeklySchemaByUserID(ctx, clientAuthID)
+		if err != nil {
+			schema = nil
+		}
+	}
+
+	err = s.repo.WithTransaction(ctx, func(ctx context.Context) error {
+		if schema == nil {
+			if kind != types.SharedPlanWorkout {
+				if err := s.repo.Schemas().DeactivateAllWeeklySchemasForUser(ctx, clientAuthID); err != nil {
+					return fmt.Errorf("failed to deactivate current schemas: %w", err)
+				}
+			}
+
+			created, err := s.repo.Schemas().CreateWeeklySchema(ctx, &types.WeeklySchemaRequest{
+				UserID:    clientAuthID,
+				WeekStart: weekStartOf(time.Now().UTC()),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create cloned schema: %w", err)
+			}
+			schema = created
+		}
+
+		return addPlanWorkouts(ctx, s.repo, schema.SchemaID, plan.workouts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.WeeklySchemaExtended{
+		WeeklySchema: *schema,
+		CoachID:      &coachID,
+	}, nil
+}
+
+func (s *coachService) loadSharedPlan(ctx context.Context, coachID string, kind types.SharedPlanKind, sourceID int) (*sharedPlan, error) {
+	var (
+		plan *sharedPlan
+		err  error
+	)
+
+	switch kind {
+	case types.SharedPlanWeeklySchema:
+		plan, err = s.loadSharedSchema(ctx, coachID, sourceID)
+	case types.SharedPlanWorkout:
+		plan, err = s.loadSharedWorkout(ctx, coachID, sourceID)
+	case types.SharedPlanGeneratedPlan:
+		plan, err = s.loadSharedGeneratedPlan(ctx, coachID, sourceID)
+	default:
+		return nil, types.ErrInvalidSharedPlanKind
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plan.workouts) == 0 {
+		return nil, types.ErrSharedPlanEmpty
+	}
+
+	plan.summary.Kind = kind
+	plan.summary.SourceID = sourceID
+	plan.summary.WorkoutCount = len(plan.workouts)
+	for _, workout := range plan.workouts {
+		plan.summary.ExerciseCount += len(workout.exercises)
+	}
+
+	return plan, nil
+}
+
+func (s *coachService) loadSharedSchema(ctx context.Context, coachID string, schemaID int) (*sharedPlan, error) {
+	schema, err := s.repo.Workouts().GetSchemaWithAllWorkouts(ctx, schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+	if err := s.checkSharedPlanOwner(ctx, coachID, schema.UserID); err != nil {
+		return nil, err
+	}
+
+	weekStart := schema.WeekStart
+	plan := &sharedPlan{
+		summary: types.SharedPlanSummary{
+			Title:     fmt.Sprintf("Weekly schema (week of %s)", weekStart.Format("2006-01-02")),
+			WeekStart: &weekStart,
+		},
+	}
+	for _, workout := range schema.Workouts {
+		plan.addWorkout(workout)
+	}
+	return plan, nil
+}
+
+func (s *coachService) loadSharedWorkout(ctx context.Context, coachID string, workoutID int) (*sharedPlan, error) {
+	workout, err := s.repo.Workouts().GetWorkoutWithExercises(ctx, workoutID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout: %w", err)
+	}
+
+	schema, err := s.repo.Schemas().GetWeeklySchemaByID(ctx, workout.SchemaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+	if err := s.checkSharedPlanOwner(ctx, coachID, schema.UserID); err != nil {
+		return nil, err
+	}
+
+	plan := &sharedPlan{
+		summary: types.SharedPlanSummary{
+			Title: fmt.Sprintf("Workout: %s", workout.Focus),
+		},
+	}
+	plan.addWorkout(*workout)
+	return plan, nil
+}
+
+func (s *coachService) loadSharedGeneratedPlan(ctx context.Context, coachID string, planID int) (*sharedPlan, error) {
+	generated, err := s.repo.PlanGeneration().GetPlanID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generated plans are keyed by workout profile rather than auth user
+	ownerAuthID, err := s.repo.WorkoutProfiles().LookupAuthUserID(ctx, generated.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan owner: %w", err)
+	}
+	if err := s.checkSharedPlanOwner(ctx, coachID, ownerAuthID); err != nil {
+		return nil, err
+	}
+
+	days, err := s.repo.PlanGeneration().GetGeneratedPlanStructure(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan structure: %w", err)
+	}
+
+	weekStart := generated.WeekStart
+	plan := &sharedPlan{
+		summary: types.SharedPlanSummary{
+			Title:     fmt.Sprintf("Generated plan (week of %s)", weekStart.Format("2006-01-02")),
+			WeekStart: &weekStart,
+		},
+	}
+
+	for _, day := range days {
+		if day.IsRest || day.DayIndex < 1 || day.DayIndex > 7 {
+			continue
+		}
+
+		workout := sharedPlanWorkout{dayOfWeek: day.DayIndex, focus: day.Focus}
+		summaryDay := types.SharedPlanDay{DayOfWeek: day.DayIndex, Focus: day.Focus, Exercises: []string{}}
+		for _, exercise := range day.Exercises {
+			// Exercises the generator could not match to the library cannot be cloned
+			if exercise.ExerciseID == nil {
+				continue
+			}
+			workout.exercises = append(workout.exercises, types.WorkoutExerciseRequest{
+				ExerciseID:  *exercise.ExerciseID,
+				Sets:        exercise.Sets,
+				Reps:        exercise.Reps,
+				RestSeconds: exercise.RestSeconds,
+			})
+			summaryDay.Exercises = append(summaryDay.Exercises, exercise.Name)
+		}
+
+		plan.workouts = append(plan.workouts, workout)
+		plan.summary.Days = append(plan.summary.Days, summaryDay)
+	}
+
+	return plan, nil
+}
+
+// addPlanWorkouts creates the workouts and their exercises in the schema.
+func addPlanWorkouts(ctx context.Context, repo repository.SchemaRepo, schemaID int, workouts []sharedPlanWorkout) error {
+	for _, workout := range workouts {
+		createdWorkout, err := repo.Workouts().CreateWorkout(ctx, &types.WorkoutRequest{
+			SchemaID:  schemaID,
+			DayOfWeek: workout.dayOfWeek,
+			Focus:     workout.focus,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clone workout: %w", err)
+		}
+
+		if _, err := repo.WorkoutExercises().BulkCreateWorkoutExercisesForWorkout(ctx, createdWorkout.WorkoutID, workout.exercises); err != nil {
+			return fmt.Errorf("failed to clone workout exercises: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *sharedPlan) addWorkout(workout types.WorkoutWithExercises) {
+	cloned := sharedPlanWorkout{dayOfWeek: workout.DayOfWeek, focus: workout.Focus}
+	day := types.SharedPlanDay{DayOfWeek: workout.DayOfWeek, Focus: workout.Focus, Exercises: []string{}}
+
+	for _, exercise := range workout.Exercises {
+		cloned.exercises = append(cloned.exercises, types.WorkoutExerciseRequest{
+			ExerciseID:  exercise.Exercise.ExerciseID,
+			Sets:        exercise.Sets,
+			Reps:        exercise.Reps,
+			RestSeconds: exercise.RestSeconds,
+		})
+		day.Exercises = append(day.Exercises, exercise.Exercise.Name)
+	}
+
+	p.workouts = append(p.workouts, cloned)
+	p.summary.Days = append(p.summary.Days, day)
+}
+
+// checkSharedPlanOwner allows coaches to share their own plans and plans of
+// clients they can view, including those of teammates.
+func (s *coachService) checkSharedPlanOwner(ctx context.Context, coachID string, ownerAuthID string) error {
+	if ownerAuthID == coachID {
+		return nil
+	}
+
+	profile, err := s.repo.WorkoutProfiles().GetWorkoutProfileByAuthID(ctx, ownerAuthID)
+	if err != nil {
+		return types.ErrSharedPlanDenied
+	}
+
+	err = s.ValidateCoachPermission(ctx, coachID, profile.WorkoutProfileID)
+	if errors.Is(err, types.ErrClientAccessDenied) {
+		return types.ErrSharedPlanDenied
+	}
+	return err
+}
+
+func weekStartOf(t time.Time) time.Time {
+	base := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	daysSinceMonday := (int(base.Weekday()) + 6) % 7
+	return base.AddDate(0, 0, -daysSinceMonday)
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// TemplateLibraryService manages the workout template library. Users and
+// coaches share it; visibility decides who besides the owner can use a template.
+type TemplateLibraryService interface {
+	ListTemplates(ctx context.Context, viewerID string, filter types.TemplateFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
+	GetTemplate(ctx context.Context, viewerID string, templateID int) (*types.WorkoutTemplate, error)
+	CreateTemplate(ctx context.Context, ownerID string, req *types.CreateWorkoutTemplateRequest) (*types.WorkoutTemplate, error)
+	UpdateTemplate(ctx context.Context, ownerID string, templateID int, req *types.UpdateWorkoutTemplateRequest) (*types.WorkoutTemplate, error)
+	DeleteTemplate(ctx context.Context, ownerID string, templateID int) error
+
+	// UseTemplate replaces the user's active schema with one built from the template.
+	UseTemplate(ctx context.Context, userID string, templateID int) (*types.WeeklySchemaWithWorkouts, error)
+
+	// ListMarketplace ranks the public templates; see rankTemplates.
+	ListMarketplace(ctx context.Context, filter types.MarketplaceFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error)
+	ForkTemplate(ctx context.Context, userID string, templateID int, req *types.ForkTemplateRequest) (*types.WorkoutTemplate, error)
+	RateTemplate(ctx context.Context, userID string, templateID int, req *types.RateTemplateRequest) (*types.TemplateRating, error)
+	DeleteRating(ctx context.Context, userID string, templateID int) error
+	ListReviews(ctx context.Context, viewerID string, templateID int, pagination types.PaginationParams) (*types.PaginatedResponse[types.TemplateRating], error)
+}
+
+type templateLibraryService struct {
+	repo      repository.SchemaRepo
+	validator *validator.Validate
+}
+
+func NewTemplateLibraryService(repo repository.SchemaRepo) TemplateLibraryService {
+	return &templateLibraryService{
+		repo:      repo,
+		validator: validator.New(),
+	}
+}
+
+func (s *templateLibraryService) ListTemplates(ctx context.Context, viewerID string, filter types.TemplateFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
+	switch filter.Scope {
+	case "", types.TemplateScopeMine, types.TemplateScopeCoach, types.TemplateScopeTeam, types.TemplateScopePublic:
+	default:
+		return nil, types.ErrInvalidTemplateScope
+	}
+
+	templates, total, err := s.repo.Templates().ListTemplates(ctx, viewerID, filter, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	return templatePage(templates, total, pagination), nil
+}
+
+func templatePage[T any](items []T, total int, pagination types.PaginationParams) *types.PaginatedResponse[T] {
+	page := &types.PaginatedResponse[T]{
+		Data:       items,
+		TotalCount: total,
+		Page:       pagination.Page,
+		PageSize:   pagination.Limit,
+	}
+	if pagination.Limit > 0 {
+		page.TotalPages = (total + pagination.Limit - 1) / pagination.Limit
+	}
+	return page
+}
+
+func (s *templateLibraryService) GetTemplate(ctx context.Context, viewerID string, templateID int) (*types.WorkoutTemplate, error) {
+	template, err := getVisibleTemplate(ctx, s.repo, viewerID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if template.Visibility == types.TemplateVisibilityPublic {
+		stats, err := s.repo.TemplateMarketplace().GetTemplateStats(ctx, templateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load template stats: %w", err)
+		}
+		stats.Score = templateRankScore(stats, time.Now())
+		template.Stats = stats
+	}
+	return template, nil
+}
+
+func (s *templateLibraryService) CreateTemplate(ctx context.Context, ownerID string, req *types.CreateWorkoutTemplateRequest) (*types.WorkoutTemplate, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	if err := validateTemplateDays(req.Days); err != nil {
+		return nil, err
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = types.TemplateVisibilityPrivate
+	}
+
+	return s.repo.Templates().CreateTemplate(ctx, &types.NewTemplate{
+		OwnerID:     ownerID,
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  visibility,
+		Source:      types.TemplateSourceCustom,
+		Days:        req.Days,
+	})
+}
+
+func (s *templateLibraryService) UpdateTemplate(ctx context.Context, ownerID string, templateID int, req *types.UpdateWorkoutTemplateRequest) (*types.WorkoutTemplate, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+	if err := validateTemplateDays(req.Days); err != nil {
+		return nil, err
+	}
+	return s.repo.Templates().UpdateTemplate(ctx, ownerID, templateID, req)
+}
+
+func (s *templateLibraryService) DeleteTemplate(ctx context.Context, ownerID string, templateID int) error {
+	return s.repo.Templates().DeleteTemplate(ctx, ownerID, templateID)
+}
+
+func (s *templateLibraryService) UseTemplate(ctx context.Context, userID string, templateID int) (*types.WeeklySchemaWithWorkouts, error) {
+	template, err := getVisibleTemplate(ctx, s.repo, userID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := createSchemaFromTemplate(ctx, s.repo, userID, template, weekStartOf(time.Now().UTC()))
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Workouts().GetSchemaWithAllWorkouts(ctx, schema.SchemaID)
+}
+
+// getVisibleTemplate loads a template the viewer may use. Templates the viewer
+// cannot see are reported as not found.
+func getVisibleTemplate(ctx context.Context, repo repository.SchemaRepo, viewerID string, templateID int) (*types.WorkoutTemplate, error) {
+	template, err := repo.Templates().GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case template.OwnerID == viewerID, template.Visibility == types.TemplateVisibilityPublic:
+		return template, nil
+	case template.Visibility == types.TemplateVisibilityClients:
+		isClient, err := repo.Templates().IsActiveClientOf(ctx, viewerID, template.OwnerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check template access: %w", err)
+		}
+		if isClient {
+			return template, nil
+		}
+		teammates, err := repo.CoachTeams().AreTeammates(ctx, viewerID, template.OwnerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check template access: %w", err)
+		}
+		if teammates {
+			return template, nil
+		}
+	}
+	return nil, types.ErrTemplateNotFound
+}
+
+func validateTemplateDays(days []types.TemplateDayRequest) error {
+	seen := make(map[int]bool, len(days))
+	for _, day := range days {
+		if seen[day.DayOfWeek] {
+			return types.ErrTemplateDuplicateDay
+		}
+		seen[day.DayOfWeek] = true
+	}
+	return nil
+}
+
+// templateWorkouts turns a template into the workouts of a schema. Exercises
+// without a library ID cannot be scheduled and are left out, as are days that
+// end up empty.
+func templateWorkouts(template *types.WorkoutTemplate) []sharedPlanWorkout {
+	var workouts []sharedPlanWorkout
+	for _, day := range template.Days {
+		workout := sharedPlanWorkout{dayOfWeek: day.DayOfWeek, focus: day.Focus}
+		for _, exercise := range day.Exercises {
+			if exercise.ExerciseID == nil {
+				continue
+			}
+			workout.exercises = append(workout.exercises, types.WorkoutExerciseRequest{
+				ExerciseID:  *exercise.ExerciseID,
+				Sets:        exercise.Sets,
+				Reps:        exercise.Reps,
+				RestSeconds: exercise.RestSeconds,
+			})
+		}
+		if len(workout.exercises) > 0 {
+			workouts = append(workouts, workout)
+		}
+	}
+	return workouts
+}
+
+// schemaTemplateDays snapshots a schema's workouts as template days. Workouts
+// on the same day are merged, keeping the first workout's focus.
+func schemaTemplateDays(schema *types.WeeklySchemaWithWorkouts) []types.TemplateDayRequest {
+	var days []types.TemplateDayRequest
+	byDay := make(map[int]int)
+
+	for _, workout := range schema.Workouts {
+		if len(workout.Exercises) == 0 {
+			continue
+		}
+
+		i, ok := byDay[workout.DayOfWeek]
+		if !ok {
+			i = len(days)
+			byDay[workout.DayOfWeek] = i
+			days = append(days, types.TemplateDayRequest{DayOfWeek: workout.DayOfWeek, Focus: workout.Focus})
+		}
+		for _, exercise := range workout.Exercises {
+			days[i].Exercises = append(days[i].Exercises, types.TemplateExerciseRequest{
+				ExerciseID:  exercise.Exercise.ExerciseID,
+				Sets:        exercise.Sets,
+				Reps:        exercise.Reps,
+				RestSeconds: exercise.RestSeconds,
+			})
+		}
+	}
+	return days
+}
+
+// createSchemaFromTemplate replaces the user's active schemas with one built
+// from the template, records the template it came from and counts the use.
+// It all happens in one transaction, so a failure leaves the old schema active.
+func createSchemaFromTemplate(ctx context.Context, repo repository.SchemaRepo, userID string, template *types.WorkoutTemplate, weekStart time.Time) (*types.WeeklySchema, error) {
+	workouts := templateWorkouts(template)
+	if len(workouts) == 0 {
+		return nil, types.ErrTemplateEmpty
+	}
+
+	templateID := template.TemplateID
+	var schema *types.WeeklySchema
+	err := repo.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := repo.Schemas().DeactivateAllWeeklySchemasForUser(ctx, userID); err != nil {
+			return fmt.Errorf("failed to deactivate current schemas: %w", err)
+		}
+
+		var err error
+		schema, err = repo.Schemas().CreateWeeklySchema(ctx, &types.WeeklySchemaRequest{
+			UserID:         userID,
+			WeekStart:      weekStart,
+			BaseTemplateID: &templateID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create schema from template: %w", err)
+		}
+
+		if err := addPlanWorkouts(ctx, repo, schema.SchemaID, workouts); err != nil {
+			return err
+		}
+		if err := repo.TemplateMarketplace().RecordTemplateUse(ctx, templateID, userID, schema.SchemaID); err != nil {
+			return fmt.Errorf("failed to record template use: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/repository"
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// fakeTemplateStore holds the schemas built from templates. A failed
+// transaction restores the schemas, workouts and uses it started with.
+type fakeTemplateStore struct {
+	repository.SchemaRepo
+	repository.WeeklySchemaRepo
+	repository.WorkoutRepo
+	repository.WorkoutExerciseRepo
+	repository.TemplateMarketplaceRepo
+
+	schemas   []types.WeeklySchema
+	workouts  int
+	uses      int
+	recordErr error
+}
+
+func (f *fakeTemplateStore) Schemas() repository.WeeklySchemaRepo                    { return f }
+func (f *fakeTemplateStore) Workouts() repository.WorkoutRepo                        { return f }
+func (f *fakeTemplateStore) WorkoutExercises() repository.WorkoutExerciseRepo        { return f }
+func (f *fakeTemplateStore) TemplateMarketplace() repository.TemplateMarketplaceRepo { return f }
+
+func (f *fakeTemplateStore) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	schemas := append([]types.WeeklySchema(nil), f.schemas...)
+	workouts, uses := f.workouts, f.uses
+
+	if err := fn(ctx); err != nil {
+		f.schemas, f.workouts, f.uses = schemas, workouts, uses
+		return err
+	}
+	return nil
+}
+
+func (f *fakeTemplateStore) DeactivateAllWeeklySchemasForUser(ctx context.Context, authUserID string) error {
+	for i := range f.schemas {
+		if f.schemas[i].UserID == authUserID {
+			f.schemas[i].Active = false
+		}
+	}
+	return nil
+}
+
+func (f *fakeTemplateStore) CreateWeeklySchema(ctx context.Context, req *types.WeeklySchemaRequest) (*types.WeeklySchema, error) {
+	schema := types.WeeklySchema{SchemaID: len(f.schemas) + 1, UserID: req.UserID, WeekStart: req.WeekStart, Active: true}
+	f.schemas = append(f.schemas, schema)
+	return &schema, nil
+}
+
+func (f *fakeTemplateStore) CreateWorkout(ctx context.Context, req *types.WorkoutRequest) (*types.Workout, error) {
+	f.workouts++
+	return &types.Workout{WorkoutID: f.workouts, SchemaID: req.SchemaID, DayOfWeek: req.DayOfWeek, Focus: req.Focus}, nil
+}
+
+func (f *fakeTemplateStore) BulkCreateWorkoutExercisesForWorkout(ctx context.Context, workoutID int, exercises []types.WorkoutExerciseRequest) ([]types.WorkoutExercise, error) {
+	return nil, nil
+}
+
+func (f *fakeTemplateStore) RecordTemplateUse(ctx context.Context, templateID int, userID string, schemaID int) error {
+	if f.recordErr != nil {
+		return f.recordErr
+	}
+	f.uses++
+	return nil
+}
+
+func TestValidateTemplateDays(t *testing.T) {
+	days := []types.TemplateDayRequest{{DayOfWeek: 1}, {DayOfWeek: 3}}
+	if err := validateTemplateDays(days); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateTemplateDays(append(days, types.TemplateDayRequest{DayOfWeek: 1})); err != types.ErrTemplateDuplicateDay {
+		t.Errorf("expected ErrTemplateDuplicateDay, got %v", err)
+	}
+	if err := validateTemplateDays(nil); err != nil {
+		t.Errorf("an update without days should pass, got %v", err)
+	}
+}
+
+func TestTemplateWorkouts(t *testing.T) {
+	squat := 4
+	template := &types.WorkoutTemplate{Days: []types.TemplateDay{
+		{DayOfWeek: 1, Focus: "legs", Exercises: []types.TemplateExercise{
+			{ExerciseID: &squat, ExerciseName: "Squat", Sets: 5, Reps: "5", RestSeconds: 180},
+			{ExerciseName: "Sled Push", Sets: 3, Reps: "20m"},
+		}},
+		// Only exercises the library never matched: nothing can be scheduled
+		{DayOfWeek: 3, Focus: "conditioning", Exercises: []types.TemplateExercise{
+			{ExerciseName: "Rowing Intervals", Sets: 6, Reps: "500m"},
+		}},
+	}}
+
+	workouts := templateWorkouts(template)
+	if len(workouts) != 1 {
+		t.Fatalf("expected the unmatched day to be left out, got %+v", workouts)
+	}
+	if workouts[0].dayOfWeek != 1 || workouts[0].focus != "legs" || len(workouts[0].exercises) != 1 {
+		t.Errorf("unexpected workout: %+v", workouts[0])
+	}
+	if ex := workouts[0].exercises[0]; ex.ExerciseID != squat || ex.Sets != 5 || ex.Reps != "5" || ex.RestSeconds != 180 {
+		t.Errorf("unexpected exercise: %+v", ex)
+	}
+}
+
+func TestSchemaTemplateDays(t *testing.T) {
+	exercise := func(id int, sets int) types.WorkoutExerciseDetail {
+		return types.WorkoutExerciseDetail{Sets: sets, Reps: "8-12", RestSeconds: 90, Exercise: types.ExerciseResponse{ExerciseID: id}}
+	}
+	schema := &types.WeeklySchemaWithWorkouts{Workouts: []types.WorkoutWithExercises{
+		{DayOfWeek: 2, Focus: "push", Exercises: []types.WorkoutExerciseDetail{exercise(1, 4)}},
+		{DayOfWeek: 4, Focus: "rest"},
+		{DayOfWeek: 2, Focus: "arms", Exercises: []types.WorkoutExerciseDetail{exercise(7, 3)}},
+		{DayOfWeek: 5, Focus: "pull", Exercises: []types.WorkoutExerciseDetail{exercise(2, 4)}},
+	}}
+
+	days := schemaTemplateDays(schema)
+	if len(days) != 2 {
+		t.Fatalf("expected two days, got %+v", days)
+	}
+	if days[0].DayOfWeek != 2 || days[0].Focus != "push" || len(days[0].Exercises) != 2 || days[0].Exercises[1].ExerciseID != 7 {
+		t.Errorf("workouts on the same day should be merged: %+v", days[0])
+	}
+	if days[1].DayOfWeek != 5 || days[1].Exercises[0].Reps != "8-12" {
+		t.Errorf("unexpected second day: %+v", days[1])
+	}
+	if err := validateTemplateDays(days); err != nil {
+		t.Errorf("snapshot should produce valid days: %v", err)
+	}
+}
+
+func TestCreateSchemaFromTemplate(t *testing.T) {
+	squat := 4
+	template := &types.WorkoutTemplate{TemplateID: 9, Days: []types.TemplateDay{
+		{DayOfWeek: 1, Focus: "legs", Exercises: []types.TemplateExercise{{ExerciseID: &squat, Sets: 5, Reps: "5"}}},
+		{DayOfWeek: 4, Focus: "legs again", Exercises: []types.TemplateExercise{{ExerciseID: &squat, Sets: 3, Reps: "8"}}},
+	}}
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+
+	store := &fakeTemplateStore{schemas: []types.WeeklySchema{{SchemaID: 1, UserID: "sam", Active: true}}}
+	schema, err := createSchemaFromTemplate(context.Background(), store, "sam", template, weekStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schema.Active || store.schemas[0].Active || store.workouts != 2 || store.uses != 1 {
+		t.Errorf("expected the new schema to replace the old one, got %+v with %d workouts and %d uses", store.schemas, store.workouts, store.uses)
+	}
+
+	store = &fakeTemplateStore{schemas: []types.WeeklySchema{{SchemaID: 1, UserID: "sam", Active: true}}}
+	store.recordErr = errors.New("connection reset")
+	if _, err := createSchemaFromTemplate(context.Background(), store, "sam", template, weekStart); !errors.Is(err, store.recordErr) {
+		t.Fatalf("err = %v, want the record error", err)
+	}
+	if len(store.schemas) != 1 || !store.schemas[0].Active || store.workouts != 0 {
+		t.Errorf("a failed use should leave the old schema active, got %+v with %d workouts", store.schemas, store.workouts)
+	}
+}
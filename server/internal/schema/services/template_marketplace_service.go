@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+// Ratings and completion rates are pulled towards a neutral prior until a
+// template has enough of them, so a single five-star rating does not put a
+// new template above an established one.
+const (
+	ratingPrior           = 3.0
+	ratingPriorWeight     = 5.0
+	completionPrior       = 0.5
+	completionPriorWeight = 3.0
+	recencyHalfLife       = 30 * 24 * time.Hour
+
+	rankWeightRating     = 0.4
+	rankWeightCompletion = 0.35
+	rankWeightRecency    = 0.25
+)
+
+func smoothedRating(stats *types.TemplateStats) float64 {
+	n := float64(stats.RatingCount)
+	return (ratingPrior*ratingPriorWeight + stats.AverageRating*n) / (ratingPriorWeight + n)
+}
+
+func smoothedCompletion(stats *types.TemplateStats) float64 {
+	if stats.CompletionRate == nil {
+		return completionPrior
+	}
+	n := float64(stats.TrackedSchemas)
+	return (completionPrior*completionPriorWeight + *stats.CompletionRate*n) / (completionPriorWeight + n)
+}
+
+// templateRankScore scores a template between 0 and 1 from its smoothed
+// rating, the smoothed completion rate of schemas built from it and its age,
+// which halves the recency part every recencyHalfLife.
+func templateRankScore(stats *types.TemplateStats, now time.Time) float64 {
+	age := max(now.Sub(stats.CreatedAt), 0)
+	recency := math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+
+	return rankWeightRating*(smoothedRating(stats)-1)/4 +
+		rankWeightCompletion*smoothedCompletion(stats) +
+		rankWeightRecency*recency
+}
+
+// rankTemplates scores every template and orders them by the sort. Ties go
+// to the newer template.
+func rankTemplates(stats []types.TemplateStats, order types.TemplateSort, now time.Time) {
+	for i := range stats {
+		stats[i].Score = templateRankScore(&stats[i], now)
+	}
+
+	less := func(a, b *types.TemplateStats) (bool, bool) {
+		switch order {
+		case types.TemplateSortNewest:
+			return a.CreatedAt.After(b.CreatedAt), !a.CreatedAt.Equal(b.CreatedAt)
+		case types.TemplateSortRating:
+			ra, rb := smoothedRating(a), smoothedRating(b)
+			return ra > rb, ra != rb
+		case types.TemplateSortPopular:
+			if a.UserCount != b.UserCount {
+				return a.UserCount > b.UserCount, true
+			}
+			return a.UseCount > b.UseCount, a.UseCount != b.UseCount
+		default:
+			return a.Score > b.Score, a.Score != b.Score
+		}
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if before, decided := less(&stats[i], &stats[j]); decided {
+			return before
+		}
+		return stats[i].TemplateID > stats[j].TemplateID
+	})
+}
+
+func (s *templateLibraryService) ListMarketplace(ctx context.Context, filter types.MarketplaceFilter, pagination types.PaginationParams) (*types.PaginatedResponse[types.WorkoutTemplate], error) {
+	switch filter.Sort {
+	case "":
+		filter.Sort = types.TemplateSortTop
+	case types.TemplateSortTop, types.TemplateSortNewest, types.TemplateSortRating, types.TemplateSortPopular:
+	default:
+		return nil, types.ErrInvalidTemplateSort
+	}
+
+	stats, err := s.repo.TemplateMarketplace().GetMarketplaceStats(ctx, filter.Search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load marketplace: %w", err)
+	}
+	rankTemplates(stats, filter.Sort, time.Now())
+
+	start := min(pagination.Offset, len(stats))
+	end := min(start+pagination.Limit, len(stats))
+	page := stats[start:end]
+
+	ids := make([]int, len(page))
+	byID := make(map[int]*types.TemplateStats, len(page))
+	for i := range page {
+		ids[i] = page[i].TemplateID
+		byID[page[i].TemplateID] = &page[i]
+	}
+
+	templates, err := s.repo.Templates().GetTemplatesByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load marketplace templates: %w", err)
+	}
+	for i := range templates {
+		templates[i].Stats = byID[templates[i].TemplateID]
+	}
+
+	return templatePage(templates, len(stats), pagination), nil
+}
+
+// getMarketplaceTemplate loads a template the user can see and requires it to
+// be public.
+func (s *templateLibraryService) getMarketplaceTemplate(ctx context.Context, userID string, templateID int) (*types.WorkoutTemplate, error) {
+	template, err := getVisibleTemplate(ctx, s.repo, userID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.Visibility != types.TemplateVisibilityPublic {
+		return nil, types.ErrTemplateNotPublic
+	}
+	return template, nil
+}
+
+// ForkTemplate copies a public template, or one of the user's own, into the
+// user's library as a private template.
+func (s *templateLibraryService) ForkTemplate(ctx context.Context, userID string, templateID int, req *types.ForkTemplateRequest) (*types.WorkoutTemplate, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	template, err := getVisibleTemplate(ctx, s.repo, userID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.OwnerID != userID && template.Visibility != types.TemplateVisibilityPublic {
+		return nil, types.ErrTemplateNotPublic
+	}
+
+	name := template.Name
+	if req.Name != nil {
+		name = *req.Name
+	}
+	return s.repo.TemplateMarketplace().ForkTemplate(ctx, userID, templateID, name)
+}
+
+func (s *templateLibraryService) RateTemplate(ctx context.Context, userID string, templateID int, req *types.RateTemplateRequest) (*types.TemplateRating, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	template, err := s.getMarketplaceTemplate(ctx, userID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.OwnerID == userID {
+		return nil, types.ErrTemplateOwnRating
+	}
+
+	rating := &types.TemplateRating{
+		TemplateID: templateID,
+		UserID:     userID,
+		Rating:     req.Rating,
+		Review:     req.Review,
+	}
+	if err := s.repo.TemplateMarketplace().UpsertTemplateRating(ctx, rating); err != nil {
+		return nil, fmt.Errorf("failed to save rating: %w", err)
+	}
+	return rating, nil
+}
+
+// DeleteRating removes the user's own rating. It works even if the template
+// has since been made private.
+func (s *templateLibraryService) DeleteRating(ctx context.Context, userID string, templateID int) error {
+	return s.repo.TemplateMarketplace().DeleteTemplateRating(ctx, templateID, userID)
+}
+
+func (s *templateLibraryService) ListReviews(ctx context.Context, viewerID string, templateID int, pagination types.PaginationParams) (*types.PaginatedResponse[types.TemplateRating], error) {
+	if _, err := s.getMarketplaceTemplate(ctx, viewerID, templateID); err != nil {
+		return nil, err
+	}
+
+	ratings, total, err := s.repo.TemplateMarketplace().ListTemplateRatings(ctx, templateID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	return templatePage(ratings, total, pagination), nil
+}
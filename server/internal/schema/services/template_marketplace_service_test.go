@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tdmdh/fit-up-server/internal/schema/types"
+)
+
+func rankedIDs(stats []types.TemplateStats) []int {
+	ids := make([]int, len(stats))
+	for i, s := range stats {
+		ids[i] = s.TemplateID
+	}
+	return ids
+}
+
+func TestRankTemplatesSmoothsRatings(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	created := now.AddDate(0, -2, 0)
+	stats := []types.TemplateStats{
+		{TemplateID: 1, CreatedAt: created, RatingCount: 1, AverageRating: 5},
+		{TemplateID: 2, CreatedAt: created, RatingCount: 40, AverageRating: 4.6},
+		{TemplateID: 3, CreatedAt: created},
+	}
+
+	rankTemplates(stats, types.TemplateSortRating, now)
+	if got := rankedIDs(stats); got[0] != 2 || got[1] != 1 || got[2] != 3 {
+		t.Errorf("a single five-star rating should not beat forty good ones, got %v", got)
+	}
+}
+
+func TestRankTemplatesTopUsesCompletionAndRecency(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	high, low := 0.9, 0.3
+	stats := []types.TemplateStats{
+		{TemplateID: 1, CreatedAt: now.AddDate(0, -1, 0), RatingCount: 10, AverageRating: 4, TrackedSchemas: 20, CompletionRate: &low},
+		{TemplateID: 2, CreatedAt: now.AddDate(0, -1, 0), RatingCount: 10, AverageRating: 4, TrackedSchemas: 20, CompletionRate: &high},
+		{TemplateID: 3, CreatedAt: now.AddDate(-1, 0, 0), RatingCount: 10, AverageRating: 4, TrackedSchemas: 20, CompletionRate: &high},
+	}
+
+	rankTemplates(stats, types.TemplateSortTop, now)
+	if got := rankedIDs(stats); got[0] != 2 || got[1] != 3 || got[2] != 1 {
+		t.Errorf("unexpected order %v", got)
+	}
+	for _, s := range stats {
+		if s.Score <= 0 || s.Score > 1 {
+			t.Errorf("template %d: score %.3f outside (0, 1]", s.TemplateID, s.Score)
+		}
+	}
+}
+
+func TestRankTemplatesPopularBreaksTies(t *testing.T) {
+	now := time.Now()
+	stats := []types.TemplateStats{
+		{TemplateID: 1, UserCount: 3, UseCount: 9},
+		{TemplateID: 2, UserCount: 5, UseCount: 5},
+		{TemplateID: 3, UserCount: 3, UseCount: 9},
+		{TemplateID: 4, UserCount: 3, UseCount: 4},
+	}
+
+	rankTemplates(stats, types.TemplateSortPopular, now)
+	if got := rankedIDs(stats); got[0] != 2 || got[1] != 3 || got[2] != 1 || got[3] != 4 {
+		t.Errorf("unexpected order %v", got)
+	}
+}
+
+func TestTemplateRankScoreWithoutActivity(t *testing.T) {
+	now := time.Now()
+	stats := &types.TemplateStats{CreatedAt: now}
+
+	// Neutral rating and completion, fully recent
+	want := rankWeightRating*0.5 + rankWeightCompletion*0.5 + rankWeightRecency
+	if got := templateRankScore(stats, now); got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("templateRankScore() = %.4f, want %.4f", got, want)
+	}
+
+	stats.CreatedAt = now.Add(-recencyHalfLife)
+	if got := templateRankScore(stats, now); got > want-rankWeightRecency/2+1e-9 {
+		t.Errorf("recency should halve after one half-life, got %.4f", got)
+	}
+}
@@ -0,0 +1,150 @@
+package types
+
+import "time"
+
+type CheckInQuestionType string
+
+const (
+	CheckInQuestionScale  CheckInQuestionType = "scale"
+	CheckInQuestionNumber CheckInQuestionType = "number"
+	CheckInQuestionText   CheckInQuestionType = "text"
+	CheckInQuestionChoice CheckInQuestionType = "choice"
+	CheckInQuestionPhoto  CheckInQuestionType = "photo"
+)
+
+type CheckInStatus string
+
+const (
+	CheckInPending   CheckInStatus = "pending"
+	CheckInSubmitted CheckInStatus = "submitted"
+	CheckInReviewed  CheckInStatus = "reviewed"
+)
+
+// CheckInQuestion is one question on a check-in form. Scale and number
+// questions may set a range, choice questions list their options. When
+// updating a template, a QuestionID keeps the existing question (and its
+// answer history); questions without one are added.
+type CheckInQuestion struct {
+	QuestionID    int64               `json:"question_id,omitempty"`
+	Position      int                 `json:"position"`
+	Type          CheckInQuestionType `json:"type" validate:"required,oneof=scale number text choice photo"`
+	Prompt        string              `json:"prompt" validate:"required,max=500"`
+	Required      bool                `json:"required"`
+	MinValue      *float64            `json:"min_value,omitempty"`
+	MaxValue      *float64            `json:"max_value,omitempty"`
+	Unit          *string             `json:"unit,omitempty" validate:"omitempty,max=20"`
+	Options       []string            `json:"options,omitempty" validate:"max=20,dive,required,max=100"`
+	AllowMultiple bool                `json:"allow_multiple,omitempty"`
+}
+
+type CheckInTemplate struct {
+	TemplateID  int64             `json:"template_id"`
+	CoachID     string            `json:"coach_id"`
+	Name        string            `json:"name"`
+	Description *string           `json:"description,omitempty"`
+	Questions   []CheckInQuestion `json:"questions"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type CheckInTemplateRequest struct {
+	Name        string            `json:"name" validate:"required,max=120"`
+	Description *string           `json:"description,omitempty" validate:"omitempty,max=2000"`
+	Questions   []CheckInQuestion `json:"questions" validate:"required,min=1,max=50,dive"`
+}
+
+// CheckInSchedule sends a template to a client every week. DayOfWeek uses
+// time.Weekday numbering (0 = Sunday) and is the day the check-in is due.
+type CheckInSchedule struct {
+	ScheduleID       int64     `json:"schedule_id"`
+	TemplateID       int64     `json:"template_id"`
+	TemplateName     string    `json:"template_name"`
+	CoachID          string    `json:"coach_id"`
+	ClientID         string    `json:"client_id"`
+	WorkoutProfileID int       `json:"workout_profile_id"`
+	DayOfWeek        int       `json:"day_of_week"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type ScheduleCheckInRequest struct {
+	TemplateID int64 `json:"template_id" validate:"required,min=1"`
+	DayOfWeek  int   `json:"day_of_week" validate:"min=0,max=6"`
+}
+
+type CheckInSubmission struct {
+	SubmissionID     int64         `json:"submission_id"`
+	ScheduleID       *int64        `json:"schedule_id,omitempty"`
+	TemplateID       int64         `json:"template_id"`
+	TemplateName     string        `json:"template_name"`
+	CoachID          string        `json:"coach_id"`
+	ClientID         string        `json:"client_id"`
+	WorkoutProfileID int           `json:"workout_profile_id"`
+	ClientName       string        `json:"client_name"`
+	WeekStart        time.Time     `json:"week_start"`
+	DueDate          time.Time     `json:"due_date"`
+	Status           CheckInStatus `json:"status"`
+	SubmittedAt      *time.Time    `json:"submitted_at,omitempty"`
+	ReviewedAt       *time.Time    `json:"reviewed_at,omitempty"`
+	CoachFeedback    *string       `json:"coach_feedback,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+// CheckInAnswer holds the value for one question; only the field matching the
+// question type is set.
+type CheckInAnswer struct {
+	QuestionID int64    `json:"question_id" validate:"required,min=1"`
+	Number     *float64 `json:"number,omitempty"`
+	Text       *string  `json:"text,omitempty" validate:"omitempty,max=5000"`
+	Choices    []string `json:"choices,omitempty"`
+	PhotoURL   *string  `json:"photo_url,omitempty" validate:"omitempty,url,max=2048"`
+}
+
+type SubmitCheckInRequest struct {
+	Answers []CheckInAnswer `json:"answers" validate:"dive"`
+}
+
+type ReviewCheckInRequest struct {
+	Feedback string `json:"feedback" validate:"max=5000"`
+}
+
+// CheckInWeekMetrics are the client's tracked numbers for the check-in week,
+// shown to the coach next to the answers.
+type CheckInWeekMetrics struct {
+	WorkoutsCompleted   int      `json:"workouts_completed"`
+	TotalVolume         float64  `json:"total_volume"`
+	PersonalRecords     int      `json:"personal_records"`
+	AvgSleepHours       *float64 `json:"avg_sleep_hours,omitempty"`
+	AvgEnergyLevel      *float64 `json:"avg_energy_level,omitempty"`
+	AvgStressLevel      *float64 `json:"avg_stress_level,omitempty"`
+	AvgSoreness         *float64 `json:"avg_soreness,omitempty"`
+	NutritionDaysLogged int      `json:"nutrition_days_logged"`
+	AvgCalories         *float64 `json:"avg_calories,omitempty"`
+	AvgProtein          *float64 `json:"avg_protein,omitempty"`
+	CalorieGoal         *int     `json:"calorie_goal,omitempty"`
+	ProteinGoal         *int     `json:"protein_goal,omitempty"`
+	MindfulnessMinutes  int      `json:"mindfulness_minutes"`
+}
+
+// CheckInDetail is a submission with its form and answers. Week is only
+// filled in for the coach.
+type CheckInDetail struct {
+	Submission CheckInSubmission   `json:"submission"`
+	Questions  []CheckInQuestion   `json:"questions"`
+	Answers    []CheckInAnswer     `json:"answers"`
+	Week       *CheckInWeekMetrics `json:"week,omitempty"`
+}
+
+// CheckInAnswerPoint is one week's answer to a question, for charting.
+type CheckInAnswerPoint struct {
+	SubmissionID int64     `json:"submission_id"`
+	WeekStart    time.Time `json:"week_start"`
+	SubmittedAt  time.Time `json:"submitted_at"`
+	Value        *float64  `json:"value,omitempty"`
+	Choices      []string  `json:"choices,omitempty"`
+}
+
+type CheckInQuestionHistory struct {
+	Question CheckInQuestion      `json:"question"`
+	Points   []CheckInAnswerPoint `json:"points"`
+}
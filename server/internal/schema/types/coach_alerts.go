@@ -0,0 +1,103 @@
+package types
+
+import "time"
+
+type CoachAlertRuleType string
+
+const (
+	AlertRuleNoRecentWorkout  CoachAlertRuleType = "no_recent_workout"
+	AlertRuleLowCompletion    CoachAlertRuleType = "low_completion"
+	AlertRuleRecoveryDrop     CoachAlertRuleType = "recovery_drop"
+	AlertRuleNutritionStopped CoachAlertRuleType = "nutrition_logging_stopped"
+	AlertRulePersonalRecord   CoachAlertRuleType = "personal_record"
+)
+
+type CoachAlertSeverity string
+
+const (
+	AlertSeverityInfo     CoachAlertSeverity = "info"
+	AlertSeverityWarning  CoachAlertSeverity = "warning"
+	AlertSeverityCritical CoachAlertSeverity = "critical"
+)
+
+type CoachAlertStatus string
+
+const (
+	CoachAlertOpen         CoachAlertStatus = "open"
+	CoachAlertAcknowledged CoachAlertStatus = "acknowledged"
+)
+
+// CoachAlertRule is a coach's setting for one rule. Threshold is in the
+// rule's own unit: days for inactivity and nutrition, a 0-1 ratio for
+// completion, and a 0-1 recovery score drop. The personal record rule has no threshold.
+type CoachAlertRule struct {
+	RuleType    CoachAlertRuleType `json:"rule_type"`
+	Description string             `json:"description"`
+	Enabled     bool               `json:"enabled"`
+	Threshold   float64            `json:"threshold"`
+	Push        bool               `json:"push"`
+}
+
+type CoachAlert struct {
+	AlertID          int64                  `json:"alert_id"`
+	CoachID          string                 `json:"coach_id"`
+	ClientID         string                 `json:"client_id"`
+	WorkoutProfileID *int                   `json:"workout_profile_id,omitempty"`
+	ClientName       string                 `json:"client_name"`
+	RuleType         CoachAlertRuleType     `json:"rule_type"`
+	Severity         CoachAlertSeverity     `json:"severity"`
+	Title            string                 `json:"title"`
+	Message          string                 `json:"message"`
+	Data             map[string]interface{} `json:"data"`
+	DedupeKey        string                 `json:"-"`
+	Status           CoachAlertStatus       `json:"status"`
+	AcknowledgedAt   *time.Time             `json:"acknowledged_at,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+}
+
+type CoachAlertInbox struct {
+	Alerts    []CoachAlert `json:"alerts"`
+	OpenCount int          `json:"open_count"`
+}
+
+// WeeklyCompletion compares planned workouts with completed sessions for one week.
+type WeeklyCompletion struct {
+	WeekStart time.Time `json:"week_start"`
+	Planned   int       `json:"planned"`
+	Completed int       `json:"completed"`
+}
+
+type PersonalRecordEvent struct {
+	ExerciseID   int       `json:"exercise_id"`
+	ExerciseName string    `json:"exercise_name"`
+	Weight       float64   `json:"weight"`
+	PreviousBest float64   `json:"previous_best"`
+	Date         time.Time `json:"date"`
+}
+
+// ClientComplianceSnapshot is what the alert rules look at for one client.
+type ClientComplianceSnapshot struct {
+	ClientID         string
+	WorkoutProfileID int
+	ClientName       string
+	AssignedAt       time.Time
+	LastWorkoutAt    *time.Time
+	// Most recent full week first
+	WeeklyCompletion      []WeeklyCompletion
+	RecentRecoveryScore   *float64
+	BaselineRecoveryScore *float64
+	LastFoodLogDate       *time.Time
+	FoodLogDays           int
+	PersonalRecords       []PersonalRecordEvent
+}
+
+type CoachAlertRuleUpdate struct {
+	RuleType  CoachAlertRuleType `json:"rule_type" validate:"required"`
+	Enabled   *bool              `json:"enabled,omitempty"`
+	Threshold *float64           `json:"threshold,omitempty"`
+	Push      *bool              `json:"push,omitempty"`
+}
+
+type UpdateCoachAlertRulesRequest struct {
+	Rules []CoachAlertRuleUpdate `json:"rules" validate:"required,min=1,max=10,dive"`
+}
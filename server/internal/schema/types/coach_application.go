@@ -0,0 +1,53 @@
+package types
+
+import "time"
+
+type CoachApplicationStatus string
+
+const (
+	CoachApplicationPending   CoachApplicationStatus = "pending"
+	CoachApplicationApproved  CoachApplicationStatus = "approved"
+	CoachApplicationRejected  CoachApplicationStatus = "rejected"
+	CoachApplicationWithdrawn CoachApplicationStatus = "withdrawn"
+)
+
+type CoachCertification struct {
+	Name         string `json:"name" validate:"required,max=200"`
+	Issuer       string `json:"issuer,omitempty" validate:"max=200"`
+	CredentialID string `json:"credential_id,omitempty" validate:"max=100"`
+	Year         int    `json:"year,omitempty" validate:"omitempty,min=1950,max=2100"`
+}
+
+// CoachApplicationDocument points at a supporting file such as a certificate scan.
+type CoachApplicationDocument struct {
+	Name string `json:"name" validate:"required,max=200"`
+	URL  string `json:"url" validate:"required,url,max=2000"`
+}
+
+type CoachApplication struct {
+	ApplicationID   int64                      `json:"application_id" db:"application_id"`
+	UserID          string                     `json:"user_id" db:"user_id"`
+	Status          CoachApplicationStatus     `json:"status" db:"status"`
+	Credentials     string                     `json:"credentials" db:"credentials"`
+	ExperienceYears int                        `json:"experience_years" db:"experience_years"`
+	Specialties     []string                   `json:"specialties" db:"specialties"`
+	Certifications  []CoachCertification       `json:"certifications" db:"certifications"`
+	Documents       []CoachApplicationDocument `json:"documents" db:"documents"`
+	ReviewReason    *string                    `json:"review_reason,omitempty" db:"review_reason"`
+	ReviewedBy      *string                    `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt      *time.Time                 `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt       time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time                  `json:"updated_at" db:"updated_at"`
+}
+
+type SubmitCoachApplicationRequest struct {
+	Credentials     string                     `json:"credentials" validate:"required,min=20,max=5000"`
+	ExperienceYears int                        `json:"experience_years" validate:"min=0,max=80"`
+	Specialties     []string                   `json:"specialties" validate:"max=20,dive,required,max=100"`
+	Certifications  []CoachCertification       `json:"certifications" validate:"max=20,dive"`
+	Documents       []CoachApplicationDocument `json:"documents" validate:"max=10,dive"`
+}
+
+type ReviewCoachApplicationRequest struct {
+	Reason string `json:"reason" validate:"max=2000"`
+}
@@ -0,0 +1,142 @@
+package types
+
+import "time"
+
+type TeamRole string
+
+const (
+	TeamRoleOwner TeamRole = "owner"
+	TeamRoleStaff TeamRole = "staff"
+)
+
+type TeamMemberStatus string
+
+const (
+	TeamMemberInvited TeamMemberStatus = "invited"
+	TeamMemberActive  TeamMemberStatus = "active"
+)
+
+// TeamPermission is what a coach wants to do with a client. Every team member
+// can view the team's clients; editing schemas and messaging are granted per
+// staff member.
+type TeamPermission string
+
+const (
+	TeamPermissionView        TeamPermission = "view"
+	TeamPermissionEditSchemas TeamPermission = "edit_schemas"
+	TeamPermissionMessage     TeamPermission = "message"
+)
+
+type CoachTeam struct {
+	TeamID    int          `json:"team_id"`
+	Name      string       `json:"name"`
+	OwnerID   string       `json:"owner_id"`
+	Members   []TeamMember `json:"members"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+type TeamMember struct {
+	TeamID         int              `json:"team_id"`
+	CoachID        string           `json:"coach_id"`
+	Username       string           `json:"username"`
+	Name           string           `json:"name"`
+	Role           TeamRole         `json:"role"`
+	Status         TeamMemberStatus `json:"status"`
+	CanEditSchemas bool             `json:"can_edit_schemas"`
+	CanMessage     bool             `json:"can_message"`
+	InvitedAt      time.Time        `json:"invited_at"`
+	JoinedAt       *time.Time       `json:"joined_at,omitempty"`
+}
+
+// TeamInvitation is a pending invitation as seen by the invited coach.
+type TeamInvitation struct {
+	TeamID    int       `json:"team_id"`
+	TeamName  string    `json:"team_name"`
+	OwnerID   string    `json:"owner_id"`
+	OwnerName string    `json:"owner_name"`
+	InvitedAt time.Time `json:"invited_at"`
+}
+
+type CreateTeamRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type InviteTeamMemberRequest struct {
+	Username       string `json:"username" validate:"required"`
+	CanEditSchemas bool   `json:"can_edit_schemas"`
+	CanMessage     bool   `json:"can_message"`
+}
+
+type UpdateTeamMemberRequest struct {
+	CanEditSchemas bool `json:"can_edit_schemas"`
+	CanMessage     bool `json:"can_message"`
+}
+
+type ReassignClientRequest struct {
+	CoachID string `json:"coach_id" validate:"required"`
+}
+
+// ClientCoachAccess describes how a coach relates to a client: as the
+// assigned coach, or through a team that the assigned coach belongs to.
+// TeamRole is nil when the coach and the assigned coach share no team.
+type ClientCoachAccess struct {
+	AssignedCoachID string
+	Assigned        bool
+	TeamRole        *TeamRole
+	CanEditSchemas  bool
+	CanMessage      bool
+}
+
+// Allows reports whether the access covers the permission. Assigned coaches
+// and team owners may do everything.
+func (a *ClientCoachAccess) Allows(permission TeamPermission) bool {
+	if a.Assigned {
+		return true
+	}
+	if a.TeamRole == nil {
+		return false
+	}
+	if *a.TeamRole == TeamRoleOwner {
+		return true
+	}
+
+	switch permission {
+	case TeamPermissionView:
+		return true
+	case TeamPermissionEditSchemas:
+		return a.CanEditSchemas
+	case TeamPermissionMessage:
+		return a.CanMessage
+	}
+	return false
+}
+
+// TeamClient is a client of any active team member.
+type TeamClient struct {
+	ClientSummary
+	CoachID   string `json:"coach_id"`
+	CoachName string `json:"coach_name"`
+}
+
+type TeamDashboard struct {
+	TeamID             int                `json:"team_id"`
+	Name               string             `json:"name"`
+	TotalClients       int                `json:"total_clients"`
+	OpenAlerts         int                `json:"open_alerts"`
+	CompletedSessions  int                `json:"completed_sessions_7d"`
+	ClientsWithoutPlan int                `json:"clients_without_plan"`
+	Coaches            []TeamCoachSummary `json:"coaches"`
+}
+
+// TeamCoachSummary covers one active member's clients. Sessions are counted
+// over the last seven days.
+type TeamCoachSummary struct {
+	CoachID            string   `json:"coach_id"`
+	Name               string   `json:"name"`
+	Role               TeamRole `json:"role"`
+	Clients            int      `json:"clients"`
+	OpenAlerts         int      `json:"open_alerts"`
+	CompletedSessions  int      `json:"completed_sessions_7d"`
+	ClientsWithoutPlan int      `json:"clients_without_plan"`
+}
@@ -16,4 +16,70 @@ var (
 	ErrPlanLimitReached = &SchemaError{Code: "PLAN_LIMIT_REACHED", Message: "Maximum number of active plans reached"}
 	ErrPlanNotFound     = &SchemaError{Code: "PLAN_NOT_FOUND", Message: "Plan not found"}
 	ErrPlanDeleteDenied = &SchemaError{Code: "PLAN_DELETE_DENIED", Message: "You do not have permission to delete this plan"}
+
+	ErrInvalidSharedPlanKind = &SchemaError{Code: "INVALID_SHARED_PLAN_KIND", Message: "Plan kind must be weekly_schema, generated_plan or workout"}
+	ErrSharedPlanDenied      = &SchemaError{Code: "SHARED_PLAN_DENIED", Message: "You do not have permission to share this plan"}
+	ErrSharedPlanEmpty       = &SchemaError{Code: "SHARED_PLAN_EMPTY", Message: "Plan has no workouts to share"}
+
+	ErrInvalidRole          = &SchemaError{Code: "INVALID_ROLE", Message: "Role must be user, coach or admin"}
+	ErrAdminSelfAction      = &SchemaError{Code: "ADMIN_SELF_ACTION", Message: "Admins cannot change their own role or suspend themselves"}
+	ErrUserAlreadySuspended = &SchemaError{Code: "USER_ALREADY_SUSPENDED", Message: "User is already suspended"}
+	ErrUserNotSuspended     = &SchemaError{Code: "USER_NOT_SUSPENDED", Message: "User is not suspended"}
+
+	ErrCoachApplicationNotFound   = &SchemaError{Code: "COACH_APPLICATION_NOT_FOUND", Message: "Coach application not found"}
+	ErrCoachApplicationPending    = &SchemaError{Code: "COACH_APPLICATION_PENDING", Message: "You already have a coach application under review"}
+	ErrCoachApplicationNotPending = &SchemaError{Code: "COACH_APPLICATION_NOT_PENDING", Message: "Coach application has already been reviewed"}
+	ErrAlreadyCoach               = &SchemaError{Code: "ALREADY_COACH", Message: "You already have coach access"}
+	ErrRejectionReasonRequired    = &SchemaError{Code: "REJECTION_REASON_REQUIRED", Message: "A reason is required when rejecting an application"}
+
+	ErrClientAccessDenied         = &SchemaError{Code: "CLIENT_ACCESS_DENIED", Message: "Not authorized for this client"}
+	ErrCoachingSubscriptionLapsed = &SchemaError{Code: "COACHING_SUBSCRIPTION_LAPSED", Message: "The client's coaching subscription does not currently include custom plans"}
+
+	ErrInvalidTimelineType   = &SchemaError{Code: "INVALID_TIMELINE_TYPE", Message: "Unknown timeline event type"}
+	ErrInvalidTimelineCursor = &SchemaError{Code: "INVALID_TIMELINE_CURSOR", Message: "Invalid timeline cursor"}
+	ErrInvalidDateRange      = &SchemaError{Code: "INVALID_DATE_RANGE", Message: "The start of the date range must be before its end"}
+
+	ErrCheckInTemplateNotFound = &SchemaError{Code: "CHECKIN_TEMPLATE_NOT_FOUND", Message: "Check-in template not found"}
+	ErrCheckInScheduleNotFound = &SchemaError{Code: "CHECKIN_SCHEDULE_NOT_FOUND", Message: "Check-in schedule not found"}
+	ErrCheckInNotFound         = &SchemaError{Code: "CHECKIN_NOT_FOUND", Message: "Check-in not found"}
+	ErrCheckInQuestionNotFound = &SchemaError{Code: "CHECKIN_QUESTION_NOT_FOUND", Message: "Check-in question not found"}
+	ErrInvalidCheckInQuestion  = &SchemaError{Code: "INVALID_CHECKIN_QUESTION", Message: "Invalid check-in question"}
+	ErrInvalidCheckInAnswer    = &SchemaError{Code: "INVALID_CHECKIN_ANSWER", Message: "Invalid check-in answer"}
+	ErrCheckInAlreadyReviewed  = &SchemaError{Code: "CHECKIN_ALREADY_REVIEWED", Message: "This check-in has already been reviewed"}
+	ErrCheckInNotSubmitted     = &SchemaError{Code: "CHECKIN_NOT_SUBMITTED", Message: "This check-in has not been submitted yet"}
+	ErrInvalidCheckInStatus    = &SchemaError{Code: "INVALID_CHECKIN_STATUS", Message: "Status must be pending, submitted or reviewed"}
+
+	ErrProgressPhotoNotFound     = &SchemaError{Code: "PROGRESS_PHOTO_NOT_FOUND", Message: "Progress photo not found"}
+	ErrInvalidProgressPhoto      = &SchemaError{Code: "INVALID_PROGRESS_PHOTO", Message: "Progress photos must be JPEG or PNG images"}
+	ErrProgressPhotoTooLarge     = &SchemaError{Code: "PROGRESS_PHOTO_TOO_LARGE", Message: "Progress photo exceeds the maximum upload size"}
+	ErrProgressPhotoAccessDenied = &SchemaError{Code: "PROGRESS_PHOTO_ACCESS_DENIED", Message: "Not authorized to view these progress photos"}
+
+	ErrCoachAlertNotFound      = &SchemaError{Code: "COACH_ALERT_NOT_FOUND", Message: "Alert not found"}
+	ErrUnknownAlertRule        = &SchemaError{Code: "UNKNOWN_ALERT_RULE", Message: "Unknown alert rule"}
+	ErrInvalidAlertThreshold   = &SchemaError{Code: "INVALID_ALERT_THRESHOLD", Message: "Alert threshold is out of range for this rule"}
+	ErrInvalidCoachAlertStatus = &SchemaError{Code: "INVALID_COACH_ALERT_STATUS", Message: "Status must be open, acknowledged or all"}
+
+	ErrTemplateNotFound         = &SchemaError{Code: "TEMPLATE_NOT_FOUND", Message: "Workout template not found"}
+	ErrTemplateEmpty            = &SchemaError{Code: "TEMPLATE_EMPTY", Message: "Template has no library exercises to build a schema from"}
+	ErrTemplateDuplicateDay     = &SchemaError{Code: "TEMPLATE_DUPLICATE_DAY", Message: "Each day of the week can only appear once in a template"}
+	ErrTemplateExerciseNotFound = &SchemaError{Code: "TEMPLATE_EXERCISE_NOT_FOUND", Message: "Template references an exercise that does not exist"}
+	ErrInvalidTemplateScope     = &SchemaError{Code: "INVALID_TEMPLATE_SCOPE", Message: "Scope must be mine, coach, team or public"}
+
+	ErrTemplateNotPublic      = &SchemaError{Code: "TEMPLATE_NOT_PUBLIC", Message: "Template is not in the public marketplace"}
+	ErrTemplateOwnRating      = &SchemaError{Code: "TEMPLATE_OWN_RATING", Message: "You cannot rate your own template"}
+	ErrTemplateRatingNotFound = &SchemaError{Code: "TEMPLATE_RATING_NOT_FOUND", Message: "Template rating not found"}
+	ErrInvalidTemplateSort    = &SchemaError{Code: "INVALID_TEMPLATE_SORT", Message: "Sort must be top, newest, rating or popular"}
+
+	ErrTeamNotFound           = &SchemaError{Code: "TEAM_NOT_FOUND", Message: "You are not a member of a coach team"}
+	ErrAlreadyInTeam          = &SchemaError{Code: "ALREADY_IN_TEAM", Message: "Coach already belongs to a team"}
+	ErrTeamOwnerRequired      = &SchemaError{Code: "TEAM_OWNER_REQUIRED", Message: "Only the team owner can do this"}
+	ErrTeamOwnerCannotLeave   = &SchemaError{Code: "TEAM_OWNER_CANNOT_LEAVE", Message: "The team owner cannot leave the team; delete the team instead"}
+	ErrTeamMemberNotFound     = &SchemaError{Code: "TEAM_MEMBER_NOT_FOUND", Message: "Team member not found"}
+	ErrTeamCoachNotFound      = &SchemaError{Code: "TEAM_COACH_NOT_FOUND", Message: "No coach found with that username"}
+	ErrTeamInvitationNotFound = &SchemaError{Code: "TEAM_INVITATION_NOT_FOUND", Message: "Team invitation not found"}
+	ErrTeamPermissionDenied   = &SchemaError{Code: "TEAM_PERMISSION_DENIED", Message: "Your team permissions do not allow this for this client"}
+	ErrReassignTarget         = &SchemaError{Code: "INVALID_REASSIGN_TARGET", Message: "Clients can only be reassigned to another active member of the team"}
+
+	ErrInvalidInvitationCSV = &SchemaError{Code: "INVALID_INVITATION_CSV", Message: "The CSV needs an email column, or an email address in the first column of every row"}
+	ErrTooManyInvitations   = &SchemaError{Code: "TOO_MANY_INVITATIONS", Message: "A CSV can invite at most 500 clients at once"}
 )
@@ -0,0 +1,60 @@
+package types
+
+import "time"
+
+type ProgressPhotoPose string
+
+const (
+	PoseFront ProgressPhotoPose = "front"
+	PoseSide  ProgressPhotoPose = "side"
+	PoseBack  ProgressPhotoPose = "back"
+)
+
+// AllProgressPhotoPoses is the order poses are shown in a comparison.
+var AllProgressPhotoPoses = []ProgressPhotoPose{PoseFront, PoseSide, PoseBack}
+
+// ProgressPhoto is a stored photo's metadata. The image itself is only served
+// through ImagePath, which checks that the caller is the owner or their coach.
+type ProgressPhoto struct {
+	PhotoID     int64             `json:"photo_id"`
+	UserID      string            `json:"user_id"`
+	Pose        ProgressPhotoPose `json:"pose"`
+	TakenOn     time.Time         `json:"taken_on"`
+	Notes       *string           `json:"notes,omitempty"`
+	ContentType string            `json:"content_type"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	SizeBytes   int64             `json:"size_bytes"`
+	ImagePath   string            `json:"image_path"`
+	FilePath    string            `json:"-"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// UploadProgressPhotoRequest holds the form fields sent with the image.
+// TakenOn is a YYYY-MM-DD date and defaults to today.
+type UploadProgressPhotoRequest struct {
+	Pose    ProgressPhotoPose `validate:"required,oneof=front side back"`
+	TakenOn string            `validate:"omitempty,datetime=2006-01-02"`
+	Notes   *string           `validate:"omitempty,max=1000"`
+}
+
+type ProgressPhotoFilter struct {
+	Pose ProgressPhotoPose
+	From *time.Time
+	To   *time.Time
+}
+
+// ProgressPhotoPair lines up the photos of one pose closest to the two
+// compared dates. Before or After is nil when no photo of that pose exists.
+type ProgressPhotoPair struct {
+	Pose        ProgressPhotoPose `json:"pose"`
+	Before      *ProgressPhoto    `json:"before,omitempty"`
+	After       *ProgressPhoto    `json:"after,omitempty"`
+	DaysBetween *int              `json:"days_between,omitempty"`
+}
+
+type ProgressPhotoComparison struct {
+	From  time.Time           `json:"from"`
+	To    time.Time           `json:"to"`
+	Pairs []ProgressPhotoPair `json:"pairs"`
+}
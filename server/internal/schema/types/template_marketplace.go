@@ -0,0 +1,64 @@
+package types
+
+import "time"
+
+// TemplateSort orders the template marketplace.
+type TemplateSort string
+
+const (
+	// TemplateSortTop combines ratings, completion rates and recency.
+	TemplateSortTop     TemplateSort = "top"
+	TemplateSortNewest  TemplateSort = "newest"
+	TemplateSortRating  TemplateSort = "rating"
+	TemplateSortPopular TemplateSort = "popular"
+)
+
+// TemplateAttribution links a fork to the template it was copied from.
+type TemplateAttribution struct {
+	TemplateID    int    `json:"template_id"`
+	Name          string `json:"name"`
+	OwnerUsername string `json:"owner_username"`
+}
+
+// TemplateStats summarises how a template is received. UseCount counts every
+// schema built from the template and UserCount the distinct users behind
+// them. CompletionRate is the average share of planned workouts completed in
+// schemas whose week is over; it is nil until there is at least one.
+type TemplateStats struct {
+	TemplateID     int       `json:"-"`
+	CreatedAt      time.Time `json:"-"`
+	RatingCount    int       `json:"rating_count"`
+	AverageRating  float64   `json:"average_rating"`
+	UseCount       int       `json:"use_count"`
+	UserCount      int       `json:"user_count"`
+	ForkCount      int       `json:"fork_count"`
+	TrackedSchemas int       `json:"tracked_schemas"`
+	CompletionRate *float64  `json:"completion_rate,omitempty"`
+	Score          float64   `json:"score"`
+}
+
+type TemplateRating struct {
+	TemplateID int       `json:"template_id"`
+	UserID     string    `json:"user_id"`
+	Username   string    `json:"username"`
+	Rating     int       `json:"rating"`
+	Review     *string   `json:"review,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type RateTemplateRequest struct {
+	Rating int     `json:"rating" validate:"required,min=1,max=5"`
+	Review *string `json:"review,omitempty" validate:"omitempty,max=2000"`
+}
+
+// ForkTemplateRequest names the copy; it keeps the original's name if empty.
+type ForkTemplateRequest struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+}
+
+// MarketplaceFilter narrows the marketplace. Search matches template names.
+type MarketplaceFilter struct {
+	Sort   TemplateSort
+	Search string
+}
@@ -0,0 +1,124 @@
+package types
+
+import "time"
+
+// TemplateVisibility controls who can see and use a template besides its owner.
+type TemplateVisibility string
+
+const (
+	TemplateVisibilityPrivate TemplateVisibility = "private"
+	// TemplateVisibilityClients shares a coach's template with their active clients.
+	TemplateVisibilityClients TemplateVisibility = "clients"
+	TemplateVisibilityPublic  TemplateVisibility = "public"
+)
+
+// TemplateSource records how a template was made.
+type TemplateSource string
+
+const (
+	TemplateSourceCustom TemplateSource = "custom"
+	TemplateSourceSchema TemplateSource = "schema"
+	TemplateSourceFork   TemplateSource = "fork"
+)
+
+// TemplateScope selects which part of the library a listing covers.
+type TemplateScope string
+
+const (
+	TemplateScopeMine   TemplateScope = "mine"
+	TemplateScopeCoach  TemplateScope = "coach"
+	TemplateScopeTeam   TemplateScope = "team"
+	TemplateScopePublic TemplateScope = "public"
+)
+
+// WorkoutTemplate is a template in the shared library. DaysPerWeek and
+// ExerciseCount are derived from Days. Stats are only filled in for public
+// templates.
+type WorkoutTemplate struct {
+	TemplateID     int                  `json:"template_id"`
+	OwnerID        string               `json:"owner_id"`
+	Name           string               `json:"name"`
+	Description    *string              `json:"description,omitempty"`
+	Visibility     TemplateVisibility   `json:"visibility"`
+	Source         TemplateSource       `json:"source"`
+	SourceSchemaID *int                 `json:"source_schema_id,omitempty"`
+	ForkedFrom     *TemplateAttribution `json:"forked_from,omitempty"`
+	DaysPerWeek    int                  `json:"days_per_week"`
+	ExerciseCount  int                  `json:"exercise_count"`
+	Days           []TemplateDay        `json:"days"`
+	Stats          *TemplateStats       `json:"stats,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+type TemplateDay struct {
+	DayOfWeek int                `json:"day_of_week"`
+	Focus     string             `json:"focus"`
+	Exercises []TemplateExercise `json:"exercises"`
+}
+
+// TemplateExercise references the exercise library by ID. ExerciseID is nil
+// for exercises carried over from name-based templates that did not match
+// any library exercise; those are skipped when the template is used.
+type TemplateExercise struct {
+	ExerciseID   *int    `json:"exercise_id"`
+	ExerciseName string  `json:"exercise_name"`
+	Sets         int     `json:"sets"`
+	Reps         string  `json:"reps"`
+	TargetWeight float64 `json:"target_weight"`
+	RestSeconds  int     `json:"rest_seconds"`
+}
+
+type CreateWorkoutTemplateRequest struct {
+	Name        string               `json:"name" validate:"required,min=1,max=255"`
+	Description *string              `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Visibility  TemplateVisibility   `json:"visibility" validate:"omitempty,oneof=private clients public"`
+	Days        []TemplateDayRequest `json:"days" validate:"required,min=1,max=7,dive"`
+}
+
+// UpdateWorkoutTemplateRequest replaces all days when Days is set.
+type UpdateWorkoutTemplateRequest struct {
+	Name        *string              `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string              `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Visibility  *TemplateVisibility  `json:"visibility,omitempty" validate:"omitempty,oneof=private clients public"`
+	Days        []TemplateDayRequest `json:"days,omitempty" validate:"omitempty,min=1,max=7,dive"`
+}
+
+type TemplateDayRequest struct {
+	DayOfWeek int                       `json:"day_of_week" validate:"required,min=1,max=7"`
+	Focus     string                    `json:"focus" validate:"max=50"`
+	Exercises []TemplateExerciseRequest `json:"exercises" validate:"required,min=1,dive"`
+}
+
+type TemplateExerciseRequest struct {
+	ExerciseID   int     `json:"exercise_id" validate:"required,min=1"`
+	Sets         int     `json:"sets" validate:"required,min=1,max=10"`
+	Reps         string  `json:"reps" validate:"required,max=20"`
+	TargetWeight float64 `json:"target_weight" validate:"min=0"`
+	RestSeconds  int     `json:"rest_seconds" validate:"min=0,max=600"`
+}
+
+// SaveSchemaTemplateRequest snapshots a weekly schema into the library.
+type SaveSchemaTemplateRequest struct {
+	SchemaID    int                `json:"schema_id" validate:"required,min=1"`
+	Name        string             `json:"template_name" validate:"required,min=1,max=255"`
+	Description *string            `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Visibility  TemplateVisibility `json:"visibility" validate:"omitempty,oneof=private clients public"`
+}
+
+// TemplateFilter narrows a library listing. Search matches template names.
+type TemplateFilter struct {
+	Scope  TemplateScope
+	Search string
+}
+
+// NewTemplate is what the repository stores for a new template.
+type NewTemplate struct {
+	OwnerID        string
+	Name           string
+	Description    *string
+	Visibility     TemplateVisibility
+	Source         TemplateSource
+	SourceSchemaID *int
+	Days           []TemplateDayRequest
+}
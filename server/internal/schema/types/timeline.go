@@ -0,0 +1,60 @@
+package types
+
+import "time"
+
+type TimelineEventType string
+
+const (
+	TimelineWorkout          TimelineEventType = "workout"
+	TimelinePersonalRecord   TimelineEventType = "personal_record"
+	TimelinePlanAdaptation   TimelineEventType = "plan_adaptation"
+	TimelineRecoveryCheckIn  TimelineEventType = "recovery_checkin"
+	TimelineNutritionSummary TimelineEventType = "nutrition_summary"
+	TimelineMindfulness      TimelineEventType = "mindfulness_session"
+	TimelineMessage          TimelineEventType = "message"
+)
+
+// AllTimelineEventTypes lists every source merged into a client timeline.
+var AllTimelineEventTypes = []TimelineEventType{
+	TimelineWorkout,
+	TimelinePersonalRecord,
+	TimelinePlanAdaptation,
+	TimelineRecoveryCheckIn,
+	TimelineNutritionSummary,
+	TimelineMindfulness,
+	TimelineMessage,
+}
+
+// TimelineEvent is one entry in a client's timeline. ID is stable across
+// requests ("<type>:<source id>"); Data holds the type-specific details.
+type TimelineEvent struct {
+	ID         string                 `json:"id"`
+	Type       TimelineEventType      `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Title      string                 `json:"title"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// TimelineCursor marks the last event of a page; events are ordered by
+// occurred_at, type and source ID descending.
+type TimelineCursor struct {
+	OccurredAt time.Time
+	Type       TimelineEventType
+	SourceID   string
+}
+
+// TimelineQuery filters a timeline. From is inclusive and To exclusive; an
+// empty Types means every type.
+type TimelineQuery struct {
+	Types  []TimelineEventType
+	From   *time.Time
+	To     *time.Time
+	Cursor string
+	Limit  int
+}
+
+type TimelinePage struct {
+	Events     []TimelineEvent `json:"events"`
+	NextCursor *string         `json:"next_cursor,omitempty"`
+	HasMore    bool            `json:"has_more"`
+}
@@ -82,6 +82,7 @@ type CoachDashboard struct {
 	ActiveSchemas     int             `json:"active_schemas"`
 	TotalWorkouts     int             `json:"total_workouts_this_month"`
 	AverageCompletion float64         `json:"average_completion_rate"`
+	OpenAlerts        int             `json:"open_alerts"`
 	Clients           []ClientSummary `json:"clients"`
 	RecentActivity    []CoachActivity `json:"recent_activity"`
 }
@@ -187,35 +188,6 @@ type ExerciseResponse struct {
 	RestSeconds  int           `json:"rest_seconds"`
 }
 
-type WorkoutTemplate struct {
-	TemplateID    int          `json:"template_id" db:"template_id"`
-	Name          string       `json:"name" db:"name"`
-	Description   string       `json:"description" db:"description"`
-	MinLevel      FitnessLevel `json:"min_level" db:"min_level"`
-	MaxLevel      FitnessLevel `json:"max_level" db:"max_level"`
-	SuitableGoals string       `json:"suitable_goals" db:"suitable_goals"`
-	DaysPerWeek   int          `json:"days_per_week" db:"days_per_week"`
-}
-
-type WorkoutTemplateRequest struct {
-	Name          string        `json:"name" validate:"required,min=2,max=50"`
-	Description   string        `json:"description" validate:"max=500"`
-	MinLevel      FitnessLevel  `json:"min_level" validate:"required"`
-	MaxLevel      FitnessLevel  `json:"max_level" validate:"required"`
-	SuitableGoals []FitnessGoal `json:"suitable_goals" validate:"required,min=1"`
-	DaysPerWeek   int           `json:"days_per_week" validate:"required,min=1,max=7"`
-}
-
-type WorkoutTemplateResponse struct {
-	TemplateID    int           `json:"template_id"`
-	Name          string        `json:"name"`
-	Description   string        `json:"description"`
-	MinLevel      FitnessLevel  `json:"min_level"`
-	MaxLevel      FitnessLevel  `json:"max_level"`
-	SuitableGoals []FitnessGoal `json:"suitable_goals"`
-	DaysPerWeek   int           `json:"days_per_week"`
-}
-
 // =============================================================================
 // WEEKLY SCHEMA AND WORKOUT TYPES
 // =============================================================================
@@ -230,6 +202,8 @@ type WeeklySchema struct {
 type WeeklySchemaRequest struct {
 	UserID    string    `json:"user_id" validate:"required"` // Changed from int to string (auth_user_id)
 	WeekStart time.Time `json:"week_start" validate:"required"`
+	// BaseTemplateID is set when the schema was built from a library template
+	BaseTemplateID *int `json:"base_template_id,omitempty"`
 }
 
 type Workout struct {
@@ -340,13 +314,6 @@ type ExerciseFilter struct {
 	Search       string          `json:"search"`
 }
 
-type TemplateFilter struct {
-	Level       *FitnessLevel `json:"level"`
-	Goals       []FitnessGoal `json:"goals"`
-	DaysPerWeek *int          `json:"days_per_week"`
-	Search      string        `json:"search"`
-}
-
 type ProgressFilter struct {
 	UserID     int        `json:"user_id"`
 	ExerciseID *int       `json:"exercise_id"`
@@ -760,6 +727,31 @@ type WeeklySchemaExtended struct {
 	Workouts  []WorkoutDetail `json:"workouts"`
 }
 
+type SharedPlanKind string
+
+const (
+	SharedPlanWeeklySchema  SharedPlanKind = "weekly_schema"
+	SharedPlanGeneratedPlan SharedPlanKind = "generated_plan"
+	SharedPlanWorkout       SharedPlanKind = "workout"
+)
+
+// SharedPlanSummary is the read-only preview of a plan a coach sends to a client.
+type SharedPlanSummary struct {
+	Kind          SharedPlanKind  `json:"kind"`
+	SourceID      int             `json:"source_id"`
+	Title         string          `json:"title"`
+	WeekStart     *time.Time      `json:"week_start,omitempty"`
+	WorkoutCount  int             `json:"workout_count"`
+	ExerciseCount int             `json:"exercise_count"`
+	Days          []SharedPlanDay `json:"days"`
+}
+
+type SharedPlanDay struct {
+	DayOfWeek int      `json:"day_of_week"`
+	Focus     string   `json:"focus"`
+	Exercises []string `json:"exercises"`
+}
+
 type WorkoutDetail struct {
 	Workout
 	Exercises    []WorkoutExerciseDetail `json:"exercises"`
@@ -4,6 +4,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -17,7 +18,15 @@ type Config struct {
 	ResendAPIKey                    string
 	FrontendURL                     string
 	MobileVerificationURL           string
+	MobileInvitationURL             string // app deep link for coach invitations, e.g. fitup://invitations/accept
+	TwoFactorIssuer                 string
+	TwoFactorEncryptionKey          string
 	OAuthConfig                     OAuthConfig
+	RateLimit                       RateLimitConfig
+	GeoIPFile                       string
+	DataExport                      DataExportConfig
+	ProgressPhotoDir                string // private directory for progress photos; never served statically
+	Billing                         BillingConfig
 }
 
 type DatabaseConfig struct {
@@ -29,6 +38,23 @@ type DatabaseConfig struct {
 	ConnectTimeout    int64 // in seconds
 }
 
+type RateLimitConfig struct {
+	Store          string // "postgres" or "memory"
+	TrustedProxies string // comma-separated CIDRs or IPs whose forwarding headers are honoured
+	Policies       string // overrides such as "login=5/15m,token_refresh=10/1m/user"
+}
+
+type DataExportConfig struct {
+	Dir        string // where finished export archives are stored
+	SigningKey string // HMAC key for download links and deletion reports; falls back to JWT_SECRET
+	BaseURL    string // public API base used in download links, e.g. https://api.example.com/api/v1
+}
+
+type BillingConfig struct {
+	Provider      string // payment provider for coaching subscriptions; only "fake" exists so far
+	WebhookSecret string // shared secret provider webhooks are signed with
+}
+
 type OAuthConfig struct {
 	GoogleClientID           string
 	GoogleClientSecret       string
@@ -51,9 +77,33 @@ type OAuthConfig struct {
 	FacebookMobileClientSecret string
 	FacebookMobileRedirectURI  string
 
+	// Sign in with Apple. The client secret is a JWT signed with the .p8 key.
+	AppleClientID       string // Services ID used by the web flow
+	AppleMobileClientID string // app bundle ID used by native sign-in
+	AppleRedirectURI    string
+	AppleTeamID         string
+	AppleKeyID          string
+	ApplePrivateKey     string // PEM contents of the .p8 key
+
+	// OIDCProviders are partner identity providers found via OpenID Connect discovery.
+	OIDCProviders []OIDCProviderConfig
+
 	OAuthStateSecret string
 }
 
+// OIDCProviderConfig is read from OIDC_<NAME>_* variables for every name
+// listed in OIDC_PROVIDERS.
+type OIDCProviderConfig struct {
+	Name           string // registry key used in URLs, e.g. "acme-gym"
+	DisplayName    string
+	Issuer         string
+	ClientID       string
+	ClientSecret   string
+	RedirectURI    string
+	MobileClientID string
+	Scopes         []string
+}
+
 func NewConfig() Config {
 	return LoadConfig()
 }
@@ -77,6 +127,25 @@ func LoadConfig() Config {
 		ResendAPIKey:                    getEnv("RESEND_API_KEY", ""),
 		FrontendURL:                     getEnv("FRONTEND_URL", ""),
 		MobileVerificationURL:           getEnv("MOBILE_VERIFICATION_URL", ""),
+		MobileInvitationURL:             getEnv("MOBILE_INVITATION_URL", "fitup://invitations/accept"),
+		TwoFactorIssuer:                 getEnv("TWO_FACTOR_ISSUER", "Fit-Up"),
+		TwoFactorEncryptionKey:          getEnv("TWO_FACTOR_ENCRYPTION_KEY", ""),
+		GeoIPFile:                       getEnv("GEOIP_FILE", ""),
+		ProgressPhotoDir:                getEnv("PROGRESS_PHOTO_DIR", "./data/progress-photos"),
+		Billing: BillingConfig{
+			Provider:      getEnv("BILLING_PROVIDER", "fake"),
+			WebhookSecret: getEnv("BILLING_WEBHOOK_SECRET", ""),
+		},
+		DataExport: DataExportConfig{
+			Dir:        getEnv("DATA_EXPORT_DIR", "./data/exports"),
+			SigningKey: getEnv("DATA_EXPORT_SIGNING_KEY", ""),
+			BaseURL:    getEnv("DATA_EXPORT_BASE_URL", ""),
+		},
+		RateLimit: RateLimitConfig{
+			Store:          getEnv("RATE_LIMIT_STORE", "postgres"),
+			TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+			Policies:       getEnv("RATE_LIMIT_POLICIES", ""),
+		},
 		OAuthConfig: OAuthConfig{
 			GoogleClientID:             getEnv("GOOGLE_CLIENT_ID", ""),
 			GoogleClientSecret:         getEnv("GOOGLE_CLIENT_SECRET", ""),
@@ -96,11 +165,46 @@ func LoadConfig() Config {
 			FacebookMobileClientID:     getEnv("FACEBOOK_MOBILE_CLIENT_ID", ""),
 			FacebookMobileClientSecret: getEnv("FACEBOOK_MOBILE_CLIENT_SECRET", ""),
 			FacebookMobileRedirectURI:  getEnv("FACEBOOK_MOBILE_REDIRECT_URI", ""),
+			AppleClientID:              getEnv("APPLE_CLIENT_ID", ""),
+			AppleMobileClientID:        getEnv("APPLE_MOBILE_CLIENT_ID", ""),
+			AppleRedirectURI:           getEnv("APPLE_REDIRECT_URI", ""),
+			AppleTeamID:                getEnv("APPLE_TEAM_ID", ""),
+			AppleKeyID:                 getEnv("APPLE_KEY_ID", ""),
+			ApplePrivateKey:            getEnv("APPLE_PRIVATE_KEY", ""),
+			OIDCProviders:              loadOIDCProviders(),
 			OAuthStateSecret:           getEnv("OAUTH_STATE_SECRET", ""),
 		},
 	}
 }
 
+// loadOIDCProviders reads OIDC_PROVIDERS=acme-gym,globex and the matching
+// OIDC_ACME_GYM_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URI,
+// _MOBILE_CLIENT_ID, _SCOPES and _DISPLAY_NAME variables.
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		scopes := strings.Fields(strings.ReplaceAll(getEnv(prefix+"SCOPES", "openid email profile"), ",", " "))
+
+		providers = append(providers, OIDCProviderConfig{
+			Name:           name,
+			DisplayName:    getEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:         getEnv(prefix+"ISSUER", ""),
+			ClientID:       getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret:   getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURI:    getEnv(prefix+"REDIRECT_URI", ""),
+			MobileClientID: getEnv(prefix+"MOBILE_CLIENT_ID", ""),
+			Scopes:         scopes,
+		})
+	}
+	return providers
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -0,0 +1,4 @@
+-- Rollback user presence
+
+DROP INDEX IF EXISTS idx_user_presence_status;
+DROP TABLE IF EXISTS user_presence CASCADE;
@@ -0,0 +1,11 @@
+-- Track WebSocket presence so it can be queried over REST
+CREATE TABLE IF NOT EXISTS user_presence (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'offline' CHECK (status IN ('online', 'away', 'offline')),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_presence_status ON user_presence(status);
+
+COMMENT ON TABLE user_presence IS 'Latest presence state reported by each user over WebSocket';
+COMMENT ON COLUMN user_presence.last_seen_at IS 'Last time the user connected, disconnected or changed status';
@@ -0,0 +1,7 @@
+-- Rollback message reactions and pins
+
+DROP INDEX IF EXISTS idx_pinned_messages_conversation;
+DROP INDEX IF EXISTS idx_message_reactions_message;
+
+DROP TABLE IF EXISTS pinned_messages CASCADE;
+DROP TABLE IF EXISTS message_reactions CASCADE;
@@ -0,0 +1,29 @@
+-- Emoji reactions on messages, one row per user per emoji
+CREATE TABLE IF NOT EXISTS message_reactions (
+    reaction_id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_user_message_emoji UNIQUE (message_id, user_id, emoji)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_reactions_message ON message_reactions(message_id);
+
+COMMENT ON TABLE message_reactions IS 'Emoji reactions toggled by conversation participants';
+
+-- Messages pinned to the top of a conversation, e.g. a coach's form cues
+CREATE TABLE IF NOT EXISTS pinned_messages (
+    pin_id BIGSERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(conversation_id) ON DELETE CASCADE,
+    message_id BIGINT NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+    pinned_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pinned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_conversation_pinned_message UNIQUE (conversation_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pinned_messages_conversation ON pinned_messages(conversation_id, pinned_at DESC);
+
+COMMENT ON TABLE pinned_messages IS 'Messages pinned per conversation';
@@ -0,0 +1,6 @@
+-- Rollback message search
+
+DROP INDEX IF EXISTS idx_messages_sent_at_id;
+DROP INDEX IF EXISTS idx_messages_search_vector;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,9 @@
+-- Full-text search over message bodies
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(message_text, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_messages_sent_at_id ON messages(sent_at DESC, message_id DESC);
+
+COMMENT ON COLUMN messages.search_vector IS 'Generated tsvector used by the message search endpoint';
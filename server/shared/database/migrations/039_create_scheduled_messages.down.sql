@@ -0,0 +1,9 @@
+-- Rollback scheduled messages
+
+DROP INDEX IF EXISTS idx_scheduled_recipients_due;
+DROP INDEX IF EXISTS idx_scheduled_messages_coach;
+
+DROP TABLE IF EXISTS scheduled_message_recipients CASCADE;
+DROP TABLE IF EXISTS scheduled_messages CASCADE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,40 @@
+-- Recipient timezone used to evaluate recurring schedules
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+-- One-off and recurring messages written by a coach
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    scheduled_message_id BIGSERIAL PRIMARY KEY,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_text TEXT NOT NULL,
+    send_at TIMESTAMP WITH TIME ZONE,
+    cron_expression VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'cancelled')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT check_message_not_empty CHECK (LENGTH(TRIM(message_text)) > 0),
+    CONSTRAINT check_schedule_defined CHECK (send_at IS NOT NULL OR cron_expression IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_coach ON scheduled_messages(coach_id, status);
+
+-- One row per conversation the scheduled message is delivered to
+CREATE TABLE IF NOT EXISTS scheduled_message_recipients (
+    recipient_id BIGSERIAL PRIMARY KEY,
+    scheduled_message_id BIGINT NOT NULL REFERENCES scheduled_messages(scheduled_message_id) ON DELETE CASCADE,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(conversation_id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    last_message_id BIGINT REFERENCES messages(message_id) ON DELETE SET NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'cancelled')),
+
+    CONSTRAINT unique_scheduled_message_conversation UNIQUE (scheduled_message_id, conversation_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_recipients_due ON scheduled_message_recipients(next_run_at) WHERE status = 'pending';
+
+COMMENT ON TABLE scheduled_messages IS 'Coach messages queued for a specific time or a cron-like recurrence';
+COMMENT ON COLUMN scheduled_messages.cron_expression IS 'Five-field cron expression evaluated in each recipient''s timezone';
+COMMENT ON COLUMN scheduled_message_recipients.claimed_at IS 'Set while a dispatcher is delivering this row, so replicas do not double-send';
@@ -0,0 +1,8 @@
+-- Rollback workout plan cards
+
+DROP INDEX IF EXISTS idx_workout_plan_card_events_card;
+DROP INDEX IF EXISTS idx_workout_plan_cards_client_status;
+DROP INDEX IF EXISTS idx_workout_plan_cards_conversation;
+
+DROP TABLE IF EXISTS workout_plan_card_events CASCADE;
+DROP TABLE IF EXISTS workout_plan_cards CASCADE;
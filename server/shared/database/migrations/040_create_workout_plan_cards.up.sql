@@ -0,0 +1,34 @@
+-- Workout plans a coach sends inside a conversation as a structured card
+CREATE TABLE IF NOT EXISTS workout_plan_cards (
+    card_id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL UNIQUE REFERENCES messages(message_id) ON DELETE CASCADE,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(conversation_id) ON DELETE CASCADE,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_kind VARCHAR(20) NOT NULL CHECK (plan_kind IN ('weekly_schema', 'generated_plan', 'workout')),
+    source_id INTEGER NOT NULL,
+    summary JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    accepted_schema_id INTEGER,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_plan_cards_conversation ON workout_plan_cards(conversation_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_workout_plan_cards_client_status ON workout_plan_cards(client_id, status);
+
+COMMENT ON TABLE workout_plan_cards IS 'Structured workout plan payloads attached to messages';
+
+-- Append-only audit trail of who sent, accepted or declined a plan card
+CREATE TABLE IF NOT EXISTS workout_plan_card_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    card_id BIGINT NOT NULL REFERENCES workout_plan_cards(card_id) ON DELETE CASCADE,
+    actor_id TEXT NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('sent', 'accepted', 'declined')),
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_plan_card_events_card ON workout_plan_card_events(card_id, created_at);
+
+COMMENT ON TABLE workout_plan_card_events IS 'Audit trail for workout plan cards';
@@ -0,0 +1,10 @@
+-- Rollback two-factor authentication
+
+DROP INDEX IF EXISTS idx_two_factor_challenges_expires;
+DROP INDEX IF EXISTS idx_two_factor_recovery_codes_user;
+
+DROP TABLE IF EXISTS two_factor_challenges CASCADE;
+DROP TABLE IF EXISTS two_factor_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_two_factor CASCADE;
+
+UPDATE users SET is_two_factor_enabled = FALSE;
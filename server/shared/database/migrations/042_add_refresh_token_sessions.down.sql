@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_jwt_refresh_tokens_access_token_jti;
+DROP INDEX IF EXISTS idx_jwt_refresh_tokens_family_id;
+
+ALTER TABLE jwt_refresh_tokens DROP COLUMN IF EXISTS device_name;
+ALTER TABLE jwt_refresh_tokens DROP COLUMN IF EXISTS family_id;
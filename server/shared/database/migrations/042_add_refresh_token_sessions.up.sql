@@ -0,0 +1,13 @@
+-- A session is a refresh-token family: every rotation issues a new token with
+-- the same family_id, so reuse of a rotated token can revoke the whole chain.
+ALTER TABLE jwt_refresh_tokens ADD COLUMN IF NOT EXISTS family_id TEXT;
+ALTER TABLE jwt_refresh_tokens ADD COLUMN IF NOT EXISTS device_name TEXT;
+
+UPDATE jwt_refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE jwt_refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jwt_refresh_tokens_family_id ON jwt_refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_jwt_refresh_tokens_access_token_jti ON jwt_refresh_tokens(access_token_jti);
+
+COMMENT ON COLUMN jwt_refresh_tokens.family_id IS 'Stable session ID shared by all tokens produced by rotating one login';
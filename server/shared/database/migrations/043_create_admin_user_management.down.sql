@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS admin_audit_log_append_only ON admin_audit_log;
+DROP FUNCTION IF EXISTS prevent_admin_audit_log_changes();
+DROP TABLE IF EXISTS admin_audit_log;
+
+DROP INDEX IF EXISTS idx_users_suspended_at;
+ALTER TABLE users DROP COLUMN IF EXISTS password_reset_required;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_at;
@@ -0,0 +1,24 @@
+-- Users apply to become coaches; only an admin approval upgrades the role.
+CREATE TABLE IF NOT EXISTS coach_applications (
+    application_id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'withdrawn')),
+    credentials TEXT NOT NULL,
+    experience_years INTEGER NOT NULL DEFAULT 0 CHECK (experience_years >= 0),
+    specialties TEXT[] NOT NULL DEFAULT '{}',
+    certifications JSONB NOT NULL DEFAULT '[]'::jsonb,
+    documents JSONB NOT NULL DEFAULT '[]'::jsonb,
+    review_reason TEXT,
+    reviewed_by TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one open application per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_applications_one_pending
+    ON coach_applications(user_id) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_coach_applications_status ON coach_applications(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_coach_applications_user ON coach_applications(user_id, created_at DESC);
@@ -0,0 +1,10 @@
+-- Shared fixed-window counters for the rate limiter, so limits hold across
+-- replicas and restarts. One row per policy/subject key. UNLOGGED skips the WAL;
+-- counters survive clean restarts and are only lost after a database crash.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_counters (
+    bucket_key TEXT PRIMARY KEY,
+    hits INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_counters_expires_at ON rate_limit_counters(expires_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS security_alert_tokens;
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS login_lockouts;
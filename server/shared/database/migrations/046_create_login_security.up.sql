@@ -0,0 +1,39 @@
+-- Consecutive failed password attempts per account. Each lockout raises
+-- lockout_level so repeat offenders are locked out for longer.
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockout_level INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_failed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Successful logins, used to recognise new devices and countries
+CREATE TABLE IF NOT EXISTS login_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_fingerprint TEXT NOT NULL,
+    device_name TEXT,
+    user_agent TEXT,
+    ip_address INET,
+    country_code CHAR(2),
+    new_device BOOLEAN NOT NULL DEFAULT FALSE,
+    new_country BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_user_device ON login_events(user_id, device_fingerprint);
+CREATE INDEX IF NOT EXISTS idx_login_events_user_country ON login_events(user_id, country_code);
+
+-- One-time "this wasn't me" tokens sent with new-login alerts, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS security_alert_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    login_event_id BIGINT REFERENCES login_events(event_id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_alert_tokens_expires_at ON security_alert_tokens(expires_at);
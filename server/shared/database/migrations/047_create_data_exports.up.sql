@@ -0,0 +1,23 @@
+-- Self-service GDPR exports. A background worker builds the ZIP and the
+-- user downloads it through a signed link until expires_at.
+CREATE TABLE IF NOT EXISTS data_exports (
+    export_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'expired')),
+    file_path TEXT,
+    file_size BIGINT,
+    error_message TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    requested_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One export in flight per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_one_active
+    ON data_exports(user_id) WHERE status IN ('pending', 'processing');
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user ON data_exports(user_id, requested_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_exports_pending ON data_exports(requested_at) WHERE status = 'pending';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS account_deletion_reports;
+
+DROP INDEX IF EXISTS idx_users_deletion_due;
+
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_for;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_requested_at;
@@ -0,0 +1,27 @@
+-- Accounts scheduled for deletion stay usable until deletion_scheduled_for;
+-- signing in again cancels the request. Deleted accounts keep a scrubbed
+-- tombstone row so coach conversations survive as "Deleted user".
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_for TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_due
+    ON users(deletion_scheduled_for)
+    WHERE deletion_scheduled_for IS NOT NULL AND deleted_at IS NULL;
+
+-- One report per executed deletion. user_id has no foreign key on purpose:
+-- the report must outlive everything else the user owned.
+CREATE TABLE IF NOT EXISTS account_deletion_reports (
+    report_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    requested_at TIMESTAMP WITH TIME ZONE,
+    scheduled_for TIMESTAMP WITH TIME ZONE,
+    executed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    steps JSONB NOT NULL,
+    digest TEXT NOT NULL,
+    signature TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_deletion_reports_user ON account_deletion_reports(user_id);
+
+COMMENT ON TABLE account_deletion_reports IS 'Signed record of what was deleted or anonymised for each account deletion';
@@ -0,0 +1,14 @@
+DELETE FROM verification_tokens WHERE purpose <> 'email_verification';
+
+DROP INDEX IF EXISTS idx_verification_tokens_user;
+DROP INDEX IF EXISTS idx_verification_tokens_email_purpose;
+
+ALTER TABLE verification_tokens ADD CONSTRAINT verification_tokens_email_key UNIQUE (email);
+
+ALTER TABLE verification_tokens DROP CONSTRAINT IF EXISTS verification_tokens_purpose_check;
+
+ALTER TABLE verification_tokens
+    DROP COLUMN IF EXISTS code_challenge,
+    DROP COLUMN IF EXISTS new_email,
+    DROP COLUMN IF EXISTS user_id,
+    DROP COLUMN IF EXISTS purpose;
@@ -0,0 +1,19 @@
+-- verification_tokens now backs every emailed one-time link, not only address
+-- verification. Each purpose keeps at most one live token per address.
+ALTER TABLE verification_tokens
+    ADD COLUMN IF NOT EXISTS purpose VARCHAR(32) NOT NULL DEFAULT 'email_verification',
+    ADD COLUMN IF NOT EXISTS user_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS new_email VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS code_challenge TEXT;
+
+ALTER TABLE verification_tokens
+    ADD CONSTRAINT verification_tokens_purpose_check
+    CHECK (purpose IN ('email_verification', 'magic_link', 'email_change', 'email_change_revert'));
+
+ALTER TABLE verification_tokens DROP CONSTRAINT IF EXISTS verification_tokens_email_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_verification_tokens_email_purpose ON verification_tokens(email, purpose);
+CREATE INDEX IF NOT EXISTS idx_verification_tokens_user ON verification_tokens(user_id) WHERE user_id IS NOT NULL;
+
+COMMENT ON COLUMN verification_tokens.token IS 'Raw token for email_verification; SHA-256 hash for every other purpose';
+COMMENT ON COLUMN verification_tokens.code_challenge IS 'PKCE S256 challenge a magic link must be redeemed with, if the requesting client supplied one';
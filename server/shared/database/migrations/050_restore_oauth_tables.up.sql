@@ -0,0 +1,37 @@
+-- 019 dropped these as unused, but the OAuth flow needs them: states bind a
+-- callback to the request that started it, accounts link provider identities
+-- to users. States now also carry the OIDC nonce and the server-side PKCE verifier.
+CREATE TABLE IF NOT EXISTS oauth_states (
+    id TEXT PRIMARY KEY DEFAULT gen_random_uuid(),
+    state TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL,
+    redirect_url TEXT,
+    nonce TEXT,
+    code_verifier TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_states_expires_at ON oauth_states(expires_at);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id TEXT PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    provider TEXT NOT NULL,               -- registry name, e.g. 'google', 'apple' or a configured OIDC provider
+    provider_account_id TEXT NOT NULL,    -- provider's stable subject identifier
+    refresh_token TEXT,
+    access_token TEXT,
+    expires_at INTEGER,
+    token_type TEXT,
+    scope TEXT,
+    id_token TEXT,
+    session_state TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_accounts_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(provider, provider_account_id),
+    UNIQUE(user_id, provider)
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts(user_id);
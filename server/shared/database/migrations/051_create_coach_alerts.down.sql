@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS coach_alerts;
+DROP TABLE IF EXISTS coach_alert_rules;
@@ -0,0 +1,33 @@
+-- Per-coach overrides for the compliance rules; rules without a row use the built-in defaults.
+CREATE TABLE IF NOT EXISTS coach_alert_rules (
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rule_type VARCHAR(40) NOT NULL
+        CHECK (rule_type IN ('no_recent_workout', 'low_completion', 'recovery_drop', 'nutrition_logging_stopped', 'personal_record')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    threshold DOUBLE PRECISION NOT NULL,
+    push BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (coach_id, rule_type)
+);
+
+CREATE TABLE IF NOT EXISTS coach_alerts (
+    alert_id BIGSERIAL PRIMARY KEY,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workout_profile_id INTEGER,
+    rule_type VARCHAR(40) NOT NULL,
+    severity VARCHAR(20) NOT NULL DEFAULT 'warning' CHECK (severity IN ('info', 'warning', 'critical')),
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    -- Identifies the occurrence (e.g. the week or the last workout date) so a
+    -- condition that persists across scans raises a single alert.
+    dedupe_key TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged')),
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (coach_id, client_id, dedupe_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coach_alerts_inbox ON coach_alerts(coach_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_coach_alerts_client ON coach_alerts(client_id);
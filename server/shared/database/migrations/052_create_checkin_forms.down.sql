@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS checkin_answers;
+DROP TABLE IF EXISTS checkin_submissions;
+DROP TABLE IF EXISTS checkin_schedules;
+DROP TABLE IF EXISTS checkin_questions;
+DROP TABLE IF EXISTS checkin_templates;
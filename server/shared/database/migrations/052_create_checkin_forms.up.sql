@@ -0,0 +1,77 @@
+-- Weekly check-in questionnaires. Coaches build templates of typed questions,
+-- schedule them per client, and each week produces one submission per schedule.
+CREATE TABLE IF NOT EXISTS checkin_templates (
+    template_id BIGSERIAL PRIMARY KEY,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(120) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    -- Templates with submissions are archived rather than deleted so past answers keep their questions.
+    archived_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS checkin_questions (
+    question_id BIGSERIAL PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES checkin_templates(template_id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    question_type VARCHAR(10) NOT NULL CHECK (question_type IN ('scale', 'number', 'text', 'choice', 'photo')),
+    prompt TEXT NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT TRUE,
+    min_value DOUBLE PRECISION,
+    max_value DOUBLE PRECISION,
+    unit VARCHAR(20),
+    options JSONB NOT NULL DEFAULT '[]'::jsonb,
+    allow_multiple BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Questions removed from a template are archived so their answer history can still be charted.
+    archived_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS checkin_schedules (
+    schedule_id BIGSERIAL PRIMARY KEY,
+    template_id BIGINT NOT NULL REFERENCES checkin_templates(template_id) ON DELETE CASCADE,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workout_profile_id INTEGER NOT NULL,
+    -- 0 = Sunday, matching Go's time.Weekday.
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (template_id, client_id)
+);
+
+CREATE TABLE IF NOT EXISTS checkin_submissions (
+    submission_id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT REFERENCES checkin_schedules(schedule_id) ON DELETE SET NULL,
+    template_id BIGINT NOT NULL REFERENCES checkin_templates(template_id) ON DELETE CASCADE,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workout_profile_id INTEGER NOT NULL,
+    week_start DATE NOT NULL,
+    due_date DATE NOT NULL,
+    status VARCHAR(12) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'submitted', 'reviewed')),
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    coach_feedback TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (template_id, client_id, week_start)
+);
+
+CREATE TABLE IF NOT EXISTS checkin_answers (
+    answer_id BIGSERIAL PRIMARY KEY,
+    submission_id BIGINT NOT NULL REFERENCES checkin_submissions(submission_id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES checkin_questions(question_id) ON DELETE CASCADE,
+    number_value DOUBLE PRECISION,
+    text_value TEXT,
+    choice_values JSONB,
+    photo_url TEXT,
+    UNIQUE (submission_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkin_templates_coach ON checkin_templates(coach_id) WHERE archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_checkin_questions_template ON checkin_questions(template_id, position);
+CREATE INDEX IF NOT EXISTS idx_checkin_schedules_active ON checkin_schedules(active) WHERE active = TRUE;
+CREATE INDEX IF NOT EXISTS idx_checkin_submissions_coach ON checkin_submissions(coach_id, status, submitted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_checkin_submissions_client ON checkin_submissions(client_id, week_start DESC);
+CREATE INDEX IF NOT EXISTS idx_checkin_answers_question ON checkin_answers(question_id);
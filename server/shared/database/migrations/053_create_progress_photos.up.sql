@@ -0,0 +1,18 @@
+-- Progress photos are stored outside any public directory; file_path is only
+-- read by the server, which checks the owner or their coach before serving it.
+CREATE TABLE IF NOT EXISTS progress_photos (
+    photo_id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pose VARCHAR(10) NOT NULL CHECK (pose IN ('front', 'side', 'back')),
+    taken_on DATE NOT NULL,
+    notes TEXT,
+    file_path TEXT NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_progress_photos_user_date ON progress_photos(user_id, taken_on DESC);
+CREATE INDEX IF NOT EXISTS idx_progress_photos_user_pose_date ON progress_photos(user_id, pose, taken_on);
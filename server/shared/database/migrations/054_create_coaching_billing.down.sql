@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS coaching_billing_events;
+DROP TABLE IF EXISTS coaching_subscriptions;
+DROP TABLE IF EXISTS coaching_packages;
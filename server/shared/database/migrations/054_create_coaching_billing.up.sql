@@ -0,0 +1,48 @@
+-- Coaching packages a coach sells, and client subscriptions to them. Payment
+-- details live with the provider; only its IDs and the subscription state are kept.
+CREATE TABLE IF NOT EXISTS coaching_packages (
+    package_id BIGSERIAL PRIMARY KEY,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(120) NOT NULL,
+    description TEXT,
+    price_cents BIGINT NOT NULL CHECK (price_cents >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'EUR',
+    trial_days INTEGER NOT NULL DEFAULT 0 CHECK (trial_days BETWEEN 0 AND 90),
+    features JSONB NOT NULL DEFAULT '[]'::jsonb,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS coaching_subscriptions (
+    subscription_id BIGSERIAL PRIMARY KEY,
+    package_id BIGINT NOT NULL REFERENCES coaching_packages(package_id),
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(12) NOT NULL CHECK (status IN ('trialing', 'active', 'past_due', 'cancelled')),
+    provider VARCHAR(30) NOT NULL,
+    provider_subscription_id TEXT NOT NULL,
+    trial_ends_at TIMESTAMP WITH TIME ZONE,
+    current_period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    current_period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, provider_subscription_id)
+);
+
+-- A client holds at most one live subscription per coach.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coaching_subscriptions_live
+    ON coaching_subscriptions(coach_id, client_id) WHERE status <> 'cancelled';
+CREATE INDEX IF NOT EXISTS idx_coaching_subscriptions_pair ON coaching_subscriptions(coach_id, client_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_coaching_subscriptions_client ON coaching_subscriptions(client_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_coaching_packages_coach ON coaching_packages(coach_id) WHERE active = TRUE;
+
+-- Provider webhook events already applied, so redelivered events are ignored.
+CREATE TABLE IF NOT EXISTS coaching_billing_events (
+    provider VARCHAR(30) NOT NULL,
+    event_id TEXT NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
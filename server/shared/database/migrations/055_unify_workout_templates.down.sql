@@ -0,0 +1,29 @@
+ALTER TABLE workout_templates ADD COLUMN IF NOT EXISTS is_public BOOLEAN DEFAULT FALSE;
+ALTER TABLE workout_templates ADD COLUMN IF NOT EXISTS exercises JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+UPDATE workout_templates SET is_public = (visibility = 'public');
+
+UPDATE workout_templates t
+SET exercises = COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+        'exercise_name', te.exercise_name,
+        'sets', te.sets,
+        'target_reps', COALESCE(NULLIF(SUBSTRING(te.reps FROM '^[0-9]+'), '')::INTEGER, 0),
+        'target_weight', te.target_weight,
+        'rest_seconds', te.rest_seconds
+    ) ORDER BY te.day_of_week, te.position)
+    FROM workout_template_exercises te
+    WHERE te.template_id = t.template_id
+), '[]'::jsonb);
+
+ALTER TABLE workout_templates ALTER COLUMN exercises DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_workout_templates_public ON workout_templates(is_public) WHERE is_public = TRUE;
+
+DROP INDEX IF EXISTS idx_workout_templates_visibility;
+DROP TABLE IF EXISTS workout_template_exercises;
+
+ALTER TABLE workout_templates
+    DROP COLUMN IF EXISTS source_schema_id,
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,91 @@
+-- workout_templates becomes the single template library. Templates have an
+-- owner and a visibility, and their exercises move out of the JSONB column
+-- into rows that reference the exercise library by ID.
+ALTER TABLE workout_templates
+    ADD COLUMN IF NOT EXISTS visibility VARCHAR(10) NOT NULL DEFAULT 'private'
+        CHECK (visibility IN ('private', 'clients', 'public')),
+    ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'custom'
+        CHECK (source IN ('custom', 'schema')),
+    ADD COLUMN IF NOT EXISTS source_schema_id INTEGER REFERENCES weekly_schemas(schema_id) ON DELETE SET NULL;
+
+UPDATE workout_templates SET visibility = 'public' WHERE is_public;
+
+-- exercise_name keeps the name the template was saved with, so exercises
+-- that could not be matched to the library are still shown.
+CREATE TABLE IF NOT EXISTS workout_template_exercises (
+    template_exercise_id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES workout_templates(template_id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL DEFAULT 1 CHECK (day_of_week BETWEEN 1 AND 7),
+    focus VARCHAR(50) NOT NULL DEFAULT '',
+    position INTEGER NOT NULL,
+    exercise_id INTEGER REFERENCES exercises(exercise_id) ON DELETE SET NULL,
+    exercise_name VARCHAR(255) NOT NULL,
+    sets INTEGER NOT NULL CHECK (sets >= 1),
+    reps VARCHAR(20) NOT NULL,
+    target_weight NUMERIC(7, 2) NOT NULL DEFAULT 0,
+    rest_seconds INTEGER NOT NULL DEFAULT 0 CHECK (rest_seconds >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_template_exercises_template
+    ON workout_template_exercises(template_id, day_of_week, position);
+CREATE INDEX IF NOT EXISTS idx_workout_templates_visibility
+    ON workout_templates(visibility) WHERE visibility <> 'private';
+
+-- User templates: exercise names are matched case-insensitively against the
+-- exercise library; unmatched names are kept without an exercise_id.
+INSERT INTO workout_template_exercises
+    (template_id, day_of_week, position, exercise_id, exercise_name, sets, reps, target_weight, rest_seconds)
+SELECT
+    t.template_id,
+    1,
+    item.ordinality::INTEGER,
+    (SELECT e.exercise_id FROM exercises e
+     WHERE LOWER(e.name) = LOWER(TRIM(item.value->>'exercise_name'))
+     ORDER BY e.exercise_id LIMIT 1),
+    COALESCE(NULLIF(TRIM(item.value->>'exercise_name'), ''), 'Exercise'),
+    GREATEST(COALESCE((item.value->>'sets')::NUMERIC::INTEGER, 1), 1),
+    COALESCE((item.value->>'target_reps')::NUMERIC::INTEGER, 0)::TEXT,
+    GREATEST(COALESCE((item.value->>'target_weight')::NUMERIC, 0), 0),
+    GREATEST(COALESCE((item.value->>'rest_seconds')::NUMERIC::INTEGER, 0), 0)
+FROM workout_templates t
+CROSS JOIN LATERAL jsonb_array_elements(t.exercises) WITH ORDINALITY AS item(value, ordinality)
+WHERE jsonb_typeof(t.exercises) = 'array';
+
+-- Coach templates were written to schema_templates by older builds. Copy any
+-- that exist by snapshotting the workouts of the schema they point at.
+DO $$
+DECLARE
+    legacy RECORD;
+    new_id INTEGER;
+BEGIN
+    IF to_regclass('schema_templates') IS NULL THEN
+        RETURN;
+    END IF;
+
+    FOR legacy IN
+        SELECT st.schema_id, st.template_name, st.created_at,
+               COALESCE(ws.coach_id, ws.user_id) AS owner_id
+        FROM schema_templates st
+        JOIN weekly_schemas ws ON ws.schema_id = st.schema_id
+    LOOP
+        INSERT INTO workout_templates (user_id, name, is_public, exercises, visibility, source, source_schema_id, created_at)
+        VALUES (legacy.owner_id, legacy.template_name, FALSE, '[]'::jsonb, 'private', 'schema', legacy.schema_id, legacy.created_at)
+        RETURNING template_id INTO new_id;
+
+        INSERT INTO workout_template_exercises
+            (template_id, day_of_week, focus, position, exercise_id, exercise_name, sets, reps, rest_seconds)
+        SELECT new_id, w.day_of_week, LEFT(w.focus, 50),
+               ROW_NUMBER() OVER (PARTITION BY w.workout_id ORDER BY we.order_index, we.we_id)::INTEGER,
+               e.exercise_id, e.name, we.sets, we.reps, we.rest_seconds
+        FROM workouts w
+        JOIN workout_exercises we ON we.workout_id = w.workout_id
+        JOIN exercises e ON e.exercise_id = we.exercise_id
+        WHERE w.schema_id = legacy.schema_id AND w.day_of_week BETWEEN 1 AND 7;
+    END LOOP;
+
+    DROP TABLE schema_templates;
+END $$;
+
+ALTER TABLE workout_templates DROP COLUMN IF EXISTS exercises;
+ALTER TABLE workout_templates DROP COLUMN IF EXISTS is_public;
+DROP INDEX IF EXISTS idx_workout_templates_public;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS template_uses;
+DROP TABLE IF EXISTS template_ratings;
+
+DROP INDEX IF EXISTS idx_workout_templates_forked_from;
+
+UPDATE workout_templates SET source = 'custom' WHERE source = 'fork';
+ALTER TABLE workout_templates DROP CONSTRAINT IF EXISTS workout_templates_source_check;
+ALTER TABLE workout_templates ADD CONSTRAINT workout_templates_source_check
+    CHECK (source IN ('custom', 'schema'));
+
+ALTER TABLE workout_templates DROP COLUMN IF EXISTS forked_from_template_id;
@@ -0,0 +1,44 @@
+-- Forks keep a link to the template they were copied from. The link is
+-- cleared when the original is deleted.
+ALTER TABLE workout_templates
+    ADD COLUMN IF NOT EXISTS forked_from_template_id INTEGER
+        REFERENCES workout_templates(template_id) ON DELETE SET NULL;
+
+ALTER TABLE workout_templates DROP CONSTRAINT IF EXISTS workout_templates_source_check;
+ALTER TABLE workout_templates ADD CONSTRAINT workout_templates_source_check
+    CHECK (source IN ('custom', 'schema', 'fork'));
+
+CREATE INDEX IF NOT EXISTS idx_workout_templates_forked_from
+    ON workout_templates(forked_from_template_id) WHERE forked_from_template_id IS NOT NULL;
+
+-- One rating per user and template; updating it replaces the review.
+CREATE TABLE IF NOT EXISTS template_ratings (
+    template_id INTEGER NOT NULL REFERENCES workout_templates(template_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    review TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (template_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_template_ratings_user ON template_ratings(user_id);
+
+-- A use is recorded every time a schema is built from a template. The row
+-- outlives the schema so usage counts do not drop when schemas are deleted.
+CREATE TABLE IF NOT EXISTS template_uses (
+    use_id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES workout_templates(template_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    schema_id INTEGER REFERENCES weekly_schemas(schema_id) ON DELETE SET NULL,
+    used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_template_uses_template ON template_uses(template_id);
+CREATE INDEX IF NOT EXISTS idx_template_uses_user ON template_uses(user_id);
+
+-- Schemas already built from library templates count as uses.
+INSERT INTO template_uses (template_id, user_id, schema_id, used_at)
+SELECT ws.base_template_id, ws.user_id, ws.schema_id, ws.week_start
+FROM weekly_schemas ws
+JOIN workout_templates t ON t.template_id = ws.base_template_id;
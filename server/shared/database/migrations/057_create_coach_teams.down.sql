@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS coach_team_members;
+DROP TABLE IF EXISTS coach_teams;
@@ -0,0 +1,30 @@
+-- A coach team lets several coaches share their clients and templates. The
+-- owner has every permission; staff permissions are set per member.
+CREATE TABLE IF NOT EXISTS coach_teams (
+    team_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_teams_owner ON coach_teams(owner_id);
+
+-- Staff are invited by the owner and only see the team's clients, and share
+-- their own, once they accept.
+CREATE TABLE IF NOT EXISTS coach_team_members (
+    team_id INTEGER NOT NULL REFERENCES coach_teams(team_id) ON DELETE CASCADE,
+    coach_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL DEFAULT 'staff' CHECK (role IN ('owner', 'staff')),
+    status VARCHAR(10) NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'active')),
+    can_edit_schemas BOOLEAN NOT NULL DEFAULT FALSE,
+    can_message BOOLEAN NOT NULL DEFAULT FALSE,
+    invited_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    invited_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    joined_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (team_id, coach_id)
+);
+
+-- A coach is an active member of at most one team.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_team_members_active_coach
+    ON coach_team_members(coach_id) WHERE status = 'active';
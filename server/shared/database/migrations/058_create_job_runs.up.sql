@@ -0,0 +1,19 @@
+-- Run history for the background job scheduler. Each scheduled slot is claimed
+-- by inserting its first attempt, so the unique key also stops two replicas
+-- from running the same slot during a leader handover.
+CREATE TABLE IF NOT EXISTS job_runs (
+    run_id BIGSERIAL PRIMARY KEY,
+    job_name VARCHAR(100) NOT NULL,
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempt INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(10) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    host VARCHAR(255) NOT NULL DEFAULT '',
+    result TEXT,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (job_name, scheduled_for, attempt)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_started ON job_runs(job_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_runs_started ON job_runs(started_at);
@@ -0,0 +1,4 @@
+UPDATE scheduled_message_recipients SET status = 'cancelled' WHERE status = 'skipped';
+ALTER TABLE scheduled_message_recipients DROP CONSTRAINT IF EXISTS scheduled_message_recipients_status_check;
+ALTER TABLE scheduled_message_recipients ADD CONSTRAINT scheduled_message_recipients_status_check
+    CHECK (status IN ('pending', 'sent', 'cancelled'));
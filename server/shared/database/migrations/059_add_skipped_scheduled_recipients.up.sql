@@ -0,0 +1,5 @@
+-- A delivery that can never be sent, such as a one-off message to a client
+-- whose coaching subscription lapsed, is skipped instead of retried forever.
+ALTER TABLE scheduled_message_recipients DROP CONSTRAINT IF EXISTS scheduled_message_recipients_status_check;
+ALTER TABLE scheduled_message_recipients ADD CONSTRAINT scheduled_message_recipients_status_check
+    CHECK (status IN ('pending', 'sent', 'cancelled', 'skipped'));
@@ -0,0 +1,4 @@
+-- Rollback nullable workout plan card event actor
+
+DELETE FROM workout_plan_card_events WHERE actor_id IS NULL;
+ALTER TABLE workout_plan_card_events ALTER COLUMN actor_id SET NOT NULL;
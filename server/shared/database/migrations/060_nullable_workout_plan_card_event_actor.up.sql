@@ -0,0 +1,3 @@
+-- Account deletion clears the actor of plan card events the user took part in,
+-- so the audit trail outlives the account without pointing back at it.
+ALTER TABLE workout_plan_card_events ALTER COLUMN actor_id DROP NOT NULL;
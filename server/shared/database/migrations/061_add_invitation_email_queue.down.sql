@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_coach_invitations_email_queue;
+ALTER TABLE coach_invitations
+    DROP COLUMN IF EXISTS email_attempts,
+    DROP COLUMN IF EXISTS email_next_attempt_at;
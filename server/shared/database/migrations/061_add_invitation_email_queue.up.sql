@@ -0,0 +1,9 @@
+-- Bulk invitations are created straight away and their emails sent in the
+-- background. email_next_attempt_at is set while an email still has to go out.
+ALTER TABLE coach_invitations
+    ADD COLUMN IF NOT EXISTS email_next_attempt_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS email_attempts INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_coach_invitations_email_queue
+    ON coach_invitations(email_next_attempt_at)
+    WHERE email_next_attempt_at IS NOT NULL;
@@ -0,0 +1,156 @@
+// Package geoip maps IP addresses to ISO country codes using a local CSV file
+// of address ranges, so lookups need no network access or paid database.
+package geoip
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+type ipRange struct {
+	start   [16]byte
+	end     [16]byte
+	country string
+}
+
+// DB is an in-memory, read-only country lookup table.
+type DB struct {
+	ranges []ipRange
+}
+
+// Open loads a range file from disk. See Parse for the format.
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	db, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Parse reads comma-separated rows in either "cidr,country" or
+// "start_ip,end_ip,country" form, which covers the free DB-IP and IP2Location
+// country CSVs. Ranges must not overlap. Blank lines, # comments and a header
+// row are skipped.
+func Parse(r io.Reader) (*DB, error) {
+	db := &DB{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		for i := range fields {
+			fields[i] = strings.Trim(strings.TrimSpace(fields[i]), `"`)
+		}
+
+		entry, err := parseRow(fields)
+		if err != nil {
+			if lineNo == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if entry.country == "" || entry.country == "-" || entry.country == "ZZ" {
+			continue
+		}
+		db.ranges = append(db.ranges, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return bytes.Compare(db.ranges[i].start[:], db.ranges[j].start[:]) < 0
+	})
+
+	return db, nil
+}
+
+func parseRow(fields []string) (ipRange, error) {
+	switch {
+	case len(fields) >= 3 && !strings.Contains(fields[0], "/"):
+		start, end := net.ParseIP(fields[0]), net.ParseIP(fields[1])
+		if start == nil || end == nil {
+			return ipRange{}, fmt.Errorf("invalid range %q-%q", fields[0], fields[1])
+		}
+		r := ipRange{start: to16(start), end: to16(end), country: strings.ToUpper(fields[2])}
+		if bytes.Compare(r.start[:], r.end[:]) > 0 {
+			return ipRange{}, fmt.Errorf("range start %s is after end %s", fields[0], fields[1])
+		}
+		return r, nil
+
+	case len(fields) >= 2:
+		_, network, err := net.ParseCIDR(fields[0])
+		if err != nil {
+			return ipRange{}, err
+		}
+		start := to16(network.IP)
+		end := start
+		mask := network.Mask
+		offset := 16 - len(mask)
+		for i := range mask {
+			end[offset+i] |= ^mask[i]
+		}
+		return ipRange{start: start, end: end, country: strings.ToUpper(fields[1])}, nil
+	}
+
+	return ipRange{}, fmt.Errorf("expected cidr,country or start,end,country")
+}
+
+// Country returns the ISO 3166 country code for ip, or "" when unknown.
+// A nil DB always returns "".
+func (db *DB) Country(ip string) string {
+	if db == nil {
+		return ""
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	key := to16(parsed)
+
+	// Last range starting at or before key
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return bytes.Compare(db.ranges[i].start[:], key[:]) > 0
+	}) - 1
+	if i < 0 {
+		return ""
+	}
+
+	if bytes.Compare(key[:], db.ranges[i].end[:]) <= 0 {
+		return db.ranges[i].country
+	}
+	return ""
+}
+
+// Len reports the number of loaded ranges.
+func (db *DB) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.ranges)
+}
+
+func to16(ip net.IP) [16]byte {
+	var out [16]byte
+	copy(out[:], ip.To16())
+	return out
+}
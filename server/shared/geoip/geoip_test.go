@@ -0,0 +1,60 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `network,country
+# comment
+1.0.0.0/24,AU
+"2.16.0.0","2.16.255.255","NL"
+81.3.0.0/16,GB
+81.2.69.0/24,SE
+2001:db8::/32,DE
+10.0.0.0/8,ZZ
+`
+
+func TestCountry(t *testing.T) {
+	db, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"1.0.0.1":       "AU",
+		"1.0.1.1":       "",
+		"2.16.4.2":      "NL",
+		"2.17.0.0":      "",
+		"81.2.69.160":   "SE",
+		"81.3.0.1":      "GB",
+		"2001:db8::1":   "DE",
+		"10.1.2.3":      "",
+		"not-an-ip":     "",
+		"255.255.255.1": "",
+	}
+
+	for ip, want := range tests {
+		if got := db.Country(ip); got != want {
+			t.Errorf("Country(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
+
+func TestParseRejectsBadRows(t *testing.T) {
+	for _, input := range []string{
+		"cidr,country\n1.0.0.0/33,AU\n",
+		"start,end,country\n1.0.0.9,1.0.0.1,AU\n",
+	} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestNilDB(t *testing.T) {
+	var db *DB
+	if db.Country("1.1.1.1") != "" || db.Len() != 0 {
+		t.Fatal("nil DB should return no results")
+	}
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// IPResolver extracts the client IP from a request. Forwarding headers are
+// only honoured when the connection comes from a trusted proxy, so clients
+// can't pick their own rate limit key by sending X-Forwarded-For.
+type IPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewIPResolver parses a list of trusted proxy CIDRs or bare IPs.
+func NewIPResolver(trustedProxies []string) (*IPResolver, error) {
+	resolver := &IPResolver{}
+	for _, entry := range trustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			resolver.trusted = append(resolver.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+// ClientIP returns the client address as a bare IP string. Starting from the
+// TCP peer, it walks X-Forwarded-For right to left past trusted proxies and
+// returns the first untrusted hop.
+func (r *IPResolver) ClientIP(req *http.Request) string {
+	remote := hostOnly(req.RemoteAddr)
+	if !r.isTrusted(remote) {
+		return remote
+	}
+
+	if xff := req.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := hostOnly(strings.TrimSpace(hops[i]))
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !r.isTrusted(hop) || i == 0 {
+				return hop
+			}
+		}
+	}
+
+	if xri := hostOnly(strings.TrimSpace(req.Header.Get("X-Real-IP"))); net.ParseIP(xri) != nil {
+		return xri
+	}
+
+	return remote
+}
+
+func (r *IPResolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range r.trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.Trim(addr, "[]")
+}
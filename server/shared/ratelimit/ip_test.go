@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPResolverClientIP(t *testing.T) {
+	resolver, err := NewIPResolver([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		realIP     string
+		want       string
+	}{
+		{"direct client ignores headers", "203.0.113.7:5000", "1.1.1.1", "2.2.2.2", "203.0.113.7"},
+		{"trusted proxy forwards client", "10.0.0.5:443", "198.51.100.9", "", "198.51.100.9"},
+		{"spoofed leftmost hop is skipped", "10.0.0.5:443", "6.6.6.6, 198.51.100.9", "", "198.51.100.9"},
+		{"chain of trusted proxies", "10.0.0.5:443", "198.51.100.9, 192.168.1.1, 10.1.1.1", "", "198.51.100.9"},
+		{"all hops trusted uses leftmost", "10.0.0.5:443", "10.2.2.2, 10.3.3.3", "", "10.2.2.2"},
+		{"x-real-ip from trusted proxy", "192.168.1.1:80", "", "198.51.100.20", "198.51.100.20"},
+		{"garbage header falls back to peer", "10.0.0.5:443", "not-an-ip", "", "10.0.0.5"},
+		{"ipv6 peer", "[2001:db8::1]:443", "1.1.1.1", "", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := resolver.ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIPResolverRejectsInvalidEntries(t *testing.T) {
+	for _, entry := range []string{"10.0.0.0/33", "proxy.internal"} {
+		if _, err := NewIPResolver([]string{entry}); err == nil {
+			t.Errorf("expected %q to be rejected", entry)
+		}
+	}
+}
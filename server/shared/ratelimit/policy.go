@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsePolicies reads a comma-separated policy list such as
+// "login=5/15m,refresh=10/1m/user". Each entry is name=limit/window with an
+// optional trailing scope of ip or user (default ip).
+func ParsePolicies(spec string) ([]Policy, error) {
+	var policies []Policy
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rule, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid rate limit policy %q: expected name=limit/window", entry)
+		}
+
+		parts := strings.Split(strings.TrimSpace(rule), "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid rate limit policy %q: expected name=limit/window[/scope]", entry)
+		}
+
+		limit, err := strconv.Atoi(parts[0])
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid limit in rate limit policy %q", entry)
+		}
+
+		window, err := time.ParseDuration(parts[1])
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid window in rate limit policy %q", entry)
+		}
+
+		scope := ScopeIP
+		if len(parts) == 3 {
+			scope = Scope(parts[2])
+			if scope != ScopeIP && scope != ScopeUser {
+				return nil, fmt.Errorf("invalid scope in rate limit policy %q: must be ip or user", entry)
+			}
+		}
+
+		policies = append(policies, Policy{Name: name, Limit: limit, Window: window, Scope: scope})
+	}
+
+	return policies, nil
+}
+
+// MergePolicies returns defaults with any same-named overrides applied.
+// Overrides with new names are added.
+func MergePolicies(defaults, overrides []Policy) []Policy {
+	merged := make([]Policy, 0, len(defaults)+len(overrides))
+	index := make(map[string]int, len(defaults))
+
+	for _, p := range defaults {
+		index[p.Name] = len(merged)
+		merged = append(merged, p)
+	}
+	for _, p := range overrides {
+		if i, ok := index[p.Name]; ok {
+			merged[i] = p
+			continue
+		}
+		index[p.Name] = len(merged)
+		merged = append(merged, p)
+	}
+
+	return merged
+}
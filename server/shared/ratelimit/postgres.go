@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps counters in the rate_limit_counters table so every
+// replica sees the same counts.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresStore(db *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Increment upserts the counter in a single statement; an expired window is
+// restarted in place rather than deleted, so concurrent hits never race on insert.
+func (s *PostgresStore) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	query := `
+		INSERT INTO rate_limit_counters (bucket_key, hits, expires_at)
+		VALUES ($1, 1, NOW() + make_interval(secs => $2))
+		ON CONFLICT (bucket_key) DO UPDATE SET
+			hits = CASE WHEN rate_limit_counters.expires_at <= NOW() THEN 1 ELSE rate_limit_counters.hits + 1 END,
+			expires_at = CASE WHEN rate_limit_counters.expires_at <= NOW() THEN EXCLUDED.expires_at ELSE rate_limit_counters.expires_at END
+		RETURNING hits, expires_at
+	`
+
+	var hits int
+	var expiresAt time.Time
+	err := s.db.QueryRow(ctx, query, key, window.Seconds()).Scan(&hits, &expiresAt)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return hits, expiresAt, nil
+}
+
+// DeleteExpired removes counters whose window has ended.
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM rate_limit_counters WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RunCleanup deletes expired counters every interval until ctx is cancelled.
+func (s *PostgresStore) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeleteExpired(ctx); err != nil {
+				log.Printf("Failed to delete expired rate limit counters: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package ratelimit implements fixed-window request limiting with a pluggable
+// counter store, so limits can be shared between replicas and survive restarts.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Scope decides which identity a policy counts requests against.
+type Scope string
+
+const (
+	// ScopeIP counts per client IP.
+	ScopeIP Scope = "ip"
+	// ScopeUser counts per authenticated user and falls back to the client IP
+	// for anonymous requests.
+	ScopeUser Scope = "user"
+)
+
+// Policy is a named limit of Limit requests per Window.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Scope  Scope
+}
+
+// Decision is the outcome of counting one request against a policy.
+type Decision struct {
+	Allowed   bool
+	Policy    Policy
+	Remaining int
+	ResetAt   time.Time
+}
+
+// RetryAfter is how long the caller should wait before the window resets.
+func (d Decision) RetryAfter() time.Duration {
+	wait := time.Until(d.ResetAt)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// Store keeps hit counters. Increment records a hit for key and returns the
+// number of hits in the current window along with when that window ends.
+type Store interface {
+	Increment(ctx context.Context, key string, window time.Duration) (hits int, resetAt time.Time, err error)
+}
+
+// Limiter applies named policies against a Store.
+type Limiter struct {
+	store    Store
+	mutex    sync.RWMutex
+	policies map[string]Policy
+}
+
+func NewLimiter(store Store, policies []Policy) *Limiter {
+	l := &Limiter{
+		store:    store,
+		policies: make(map[string]Policy, len(policies)),
+	}
+	for _, p := range policies {
+		l.policies[p.Name] = p
+	}
+	return l
+}
+
+// Policy returns the named policy.
+func (l *Limiter) Policy(name string) (Policy, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	p, ok := l.policies[name]
+	return p, ok
+}
+
+// Allow counts a request by subject against the named policy. Unknown policies
+// and store failures allow the request; the error is returned so it can be logged.
+func (l *Limiter) Allow(ctx context.Context, policyName, subject string) (Decision, error) {
+	policy, ok := l.Policy(policyName)
+	if !ok {
+		return Decision{Allowed: true}, fmt.Errorf("unknown rate limit policy %q", policyName)
+	}
+
+	key := fmt.Sprintf("%s:%s", policy.Name, subject)
+	hits, resetAt, err := l.store.Increment(ctx, key, policy.Window)
+	if err != nil {
+		return Decision{Allowed: true, Policy: policy, Remaining: policy.Limit}, fmt.Errorf("rate limit store: %w", err)
+	}
+
+	remaining := policy.Limit - hits
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Decision{
+		Allowed:   hits <= policy.Limit,
+		Policy:    policy,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// SetHeaders writes the RateLimit-* headers from the IETF ratelimit-headers
+// draft, plus Retry-After when the request was rejected.
+func SetHeaders(w http.ResponseWriter, d Decision) {
+	if d.Policy.Name == "" {
+		return
+	}
+
+	reset := int(d.RetryAfter().Round(time.Second) / time.Second)
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(d.Policy.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(reset))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", d.Policy.Limit, int(d.Policy.Window/time.Second)))
+
+	if !d.Allowed {
+		h.Set("Retry-After", strconv.Itoa(reset))
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimiterFixedWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limiter := NewLimiter(store, []Policy{{Name: "login", Limit: 2, Window: time.Minute, Scope: ScopeIP}})
+	ctx := context.Background()
+
+	for i, wantAllowed := range []bool{true, true, false} {
+		d, err := limiter.Allow(ctx, "login", "1.2.3.4")
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if d.Allowed != wantAllowed {
+			t.Fatalf("request %d: allowed = %v, want %v", i, d.Allowed, wantAllowed)
+		}
+	}
+
+	if d, _ := limiter.Allow(ctx, "login", "5.6.7.8"); !d.Allowed {
+		t.Fatal("a different subject should have its own counter")
+	}
+
+	now = now.Add(time.Minute)
+	d, _ := limiter.Allow(ctx, "login", "1.2.3.4")
+	if !d.Allowed || d.Remaining != 1 {
+		t.Fatalf("after the window: allowed = %v, remaining = %d", d.Allowed, d.Remaining)
+	}
+}
+
+func TestLimiterUnknownPolicyFailsOpen(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), nil)
+	d, err := limiter.Allow(context.Background(), "missing", "x")
+	if err == nil || !d.Allowed {
+		t.Fatalf("expected an allowed decision with an error, got %+v, %v", d, err)
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	SetHeaders(w, Decision{
+		Allowed:   false,
+		Policy:    Policy{Name: "login", Limit: 5, Window: 15 * time.Minute},
+		Remaining: 0,
+		ResetAt:   time.Now().Add(90 * time.Second),
+	})
+
+	want := map[string]string{
+		"RateLimit-Limit":     "5",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "90",
+		"RateLimit-Policy":    "5;w=900",
+		"Retry-After":         "90",
+	}
+	for header, value := range want {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies(" login=10/15m, refresh=20/1m/user ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Policy{
+		{Name: "login", Limit: 10, Window: 15 * time.Minute, Scope: ScopeIP},
+		{Name: "refresh", Limit: 20, Window: time.Minute, Scope: ScopeUser},
+	}
+	if len(policies) != len(want) {
+		t.Fatalf("got %d policies, want %d", len(policies), len(want))
+	}
+	for i := range want {
+		if policies[i] != want[i] {
+			t.Errorf("policy %d = %+v, want %+v", i, policies[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{"login", "login=5", "login=0/1m", "login=5/soon", "login=5/1m/device"} {
+		if _, err := ParsePolicies(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestMergePolicies(t *testing.T) {
+	merged := MergePolicies(
+		[]Policy{{Name: "login", Limit: 5, Window: time.Minute}, {Name: "register", Limit: 3, Window: time.Hour}},
+		[]Policy{{Name: "login", Limit: 50, Window: time.Minute}, {Name: "search", Limit: 100, Window: time.Minute}},
+	)
+
+	if len(merged) != 3 || merged[0].Limit != 50 || merged[1].Name != "register" || merged[2].Name != "search" {
+		t.Fatalf("unexpected merge result: %+v", merged)
+	}
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronRejectsInvalidExpressions(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+
+	for _, expr := range invalid {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "every monday morning",
+			expr:  "0 9 * * 1",
+			after: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), // Wednesday
+			want:  time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "strictly after the given time",
+			expr:  "0 9 * * 1",
+			after: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+			want:  time.Date(2026, 10, 26, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "step minutes",
+			expr:  "*/15 * * * *",
+			after: time.Date(2026, 1, 1, 10, 7, 30, 0, time.UTC),
+			want:  time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:  "descriptor",
+			expr:  "@monthly",
+			after: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+			want:  time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "sunday as seven",
+			expr:  "30 8 * * 7",
+			after: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), // Monday
+			want:  time.Date(2026, 3, 8, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "day of month or day of week",
+			expr:  "0 0 1 * 5",
+			after: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC), // Saturday
+			want:  time.Date(2026, 5, 8, 0, 0, 0, 0, time.UTC),  // Friday before June 1st
+		},
+		{
+			name:  "evaluated in the recipient's timezone",
+			expr:  "0 9 * * 1",
+			after: time.Date(2026, 10, 14, 12, 0, 0, 0, amsterdam),
+			want:  time.Date(2026, 10, 19, 9, 0, 0, 0, amsterdam),
+		},
+		{
+			name:  "skips the missing hour on DST start",
+			expr:  "30 2 * * *",
+			after: time.Date(2026, 3, 28, 12, 0, 0, 0, amsterdam),
+			want:  time.Date(2026, 3, 30, 2, 30, 0, 0, amsterdam),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) returned error: %v", tt.expr, err)
+			}
+
+			got := schedule.Next(tt.after)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// withExif inserts an APP1 segment carrying the given orientation and a
+// marker string right after the JPEG's SOI marker.
+func withExif(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+
+	var tiff bytes.Buffer
+	tiff.WriteString("MM")
+	binary.Write(&tiff, binary.BigEndian, uint16(42))
+	binary.Write(&tiff, binary.BigEndian, uint32(8))
+	binary.Write(&tiff, binary.BigEndian, uint16(1))
+	binary.Write(&tiff, binary.BigEndian, uint16(0x0112)) // orientation
+	binary.Write(&tiff, binary.BigEndian, uint16(3))      // SHORT
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, orientation)
+	binary.Write(&tiff, binary.BigEndian, uint16(0))
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPS-SECRET")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 8), uint8(y * 8), 0, 255})
+		}
+	}
+	return img
+}
+
+func TestStripImageMetadataJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(30, 20), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withExif(t, buf.Bytes(), 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("orientation = %d, want 6", got)
+	}
+
+	result, err := StripImageMetadata(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bytes.Contains(result.Data, []byte("Exif")) || bytes.Contains(result.Data, []byte("GPS-SECRET")) {
+		t.Error("metadata survived re-encoding")
+	}
+	if result.ContentType != "image/jpeg" || result.Ext != ".jpg" {
+		t.Errorf("unexpected type %s %s", result.ContentType, result.Ext)
+	}
+	// Orientation 6 is a quarter turn, so width and height swap
+	if result.Width != 20 || result.Height != 30 {
+		t.Errorf("size = %dx%d, want 20x30", result.Width, result.Height)
+	}
+}
+
+func TestStripImageMetadataPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(8, 4)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := StripImageMetadata(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ContentType != "image/png" || result.Width != 8 || result.Height != 4 {
+		t.Errorf("unexpected result: %s %dx%d", result.ContentType, result.Width, result.Height)
+	}
+}
+
+func TestStripImageMetadataRejectsOtherFormats(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, testImage(4, 4), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{"gif": buf.Bytes(), "garbage": []byte("not an image")} {
+		if _, err := StripImageMetadata(data); err != ErrUnsupportedImage {
+			t.Errorf("%s: expected ErrUnsupportedImage, got %v", name, err)
+		}
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	src.Set(1, 0, color.RGBA{0, 0, 255, 255})
+
+	// Orientation 6: the left pixel ends up on top
+	rotated := applyOrientation(src, 6)
+	if b := rotated.Bounds(); b.Dx() != 1 || b.Dy() != 2 {
+		t.Fatalf("rotated size = %dx%d", b.Dx(), b.Dy())
+	}
+	if r, _, _, _ := rotated.At(0, 0).RGBA(); r == 0 {
+		t.Error("expected the red pixel at the top after rotating")
+	}
+
+	mirrored := applyOrientation(src, 2)
+	if _, _, b, _ := mirrored.At(0, 0).RGBA(); b == 0 {
+		t.Error("expected the blue pixel on the left after mirroring")
+	}
+}